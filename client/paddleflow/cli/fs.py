@@ -436,6 +436,132 @@ def listlink(ctx, fsname, username=None, maxsize=100):
         sys.exit(1)
 
 
+@fs.command(context_settings=dict(max_content_width=2000), cls=command_required_option_from_option())
+@click.argument('srcfsname')
+@click.argument('srcpath')
+@click.argument('dstfsname')
+@click.argument('dstpath')
+@click.option('-u', '--username', help='Owner of the fs, only useful for root.')
+@click.option('-m', '--mode', type=click.Choice(['copy', 'sync']), default='copy',
+              help='copy new and changed files, or sync which also deletes extra files in destination.')
+@click.option('-c', '--compare', type=click.Choice(['size-mtime', 'checksum']), default='size-mtime',
+              help='How to compare files to skip the unchanged ones.')
+@click.option('-w', '--workers', type=int, default=4, help='Number of parallel workers.')
+@click.option('-b', '--bandwidth', type=int, default=0, help='Max bytes per second, 0 means no limit.')
+@click.option('--dryrun', is_flag=True, help='Only show the differences, nothing is changed.')
+@click.pass_context
+def sync(ctx, srcfsname, srcpath, dstfsname, dstpath, username=None, mode='copy', compare='size-mtime', workers=4,
+         bandwidth=0, dryrun=False):
+    """
+    copy or sync data between fs in background\n
+    SRCFSNAME: source fs name, could be fsname@snapshot\n
+    SRCPATH: source path in fs\n
+    DSTFSNAME: destination fs name\n
+    DSTPATH: destination path in fs
+    """
+    client = ctx.obj['client']
+    options = {
+        'mode': mode,
+        'compare': compare,
+        'workers': workers,
+        'bandwidthLimit': bandwidth,
+        'dryRun': dryrun,
+    }
+    valid, response = client.create_sync_task(srcfsname, srcpath, dstfsname, dstpath, options, username)
+    if valid:
+        click.echo("sync task[%s] create success" % response.taskid)
+    else:
+        click.echo("sync task create failed with message[%s]" % response)
+        sys.exit(1)
+
+
+@fs.command()
+@click.argument('taskid')
+@click.pass_context
+def showsync(ctx, taskid):
+    """
+    show sync task and the differences of dry run\n
+    TASKID: sync task id
+    """
+    client = ctx.obj['client']
+    valid, response = client.show_sync_task(taskid)
+    if valid:
+        _print_sync_task([response], ctx.obj['output'])
+        if response.diff:
+            click.echo("\n".join(response.diff))
+    else:
+        click.echo("show sync task failed with message[%s]" % response)
+        sys.exit(1)
+
+
+@fs.command()
+@click.option('-s', '--status', help='Filter by status, separated by comma.')
+@click.pass_context
+def listsync(ctx, status=None):
+    """list sync tasks """
+    client = ctx.obj['client']
+    valid, response = client.list_sync_task(status)
+    if valid:
+        if len(response):
+            _print_sync_task(response, ctx.obj['output'])
+        else:
+            click.echo("no sync task found ")
+    else:
+        click.echo("list sync task failed with message[%s]" % response)
+        sys.exit(1)
+
+
+@fs.command()
+@click.argument('taskid')
+@click.pass_context
+def stopsync(ctx, taskid):
+    """
+    stop sync task\n
+    TASKID: sync task id
+    """
+    client = ctx.obj['client']
+    valid, response = client.stop_sync_task(taskid)
+    if valid:
+        click.echo("sync task[%s] stop success" % taskid)
+    else:
+        click.echo("stop sync task failed with message[%s]" % response)
+        sys.exit(1)
+
+
+@fs.command()
+@click.argument('taskid')
+@click.pass_context
+def resumesync(ctx, taskid):
+    """
+    resume sync task from checkpoint\n
+    TASKID: sync task id
+    """
+    client = ctx.obj['client']
+    valid, response = client.resume_sync_task(taskid)
+    if valid:
+        click.echo("sync task[%s] resume success" % taskid)
+    else:
+        click.echo("resume sync task failed with message[%s]" % response)
+        sys.exit(1)
+
+
+@fs.command()
+@click.argument('taskid')
+@click.pass_context
+def deletesync(ctx, taskid):
+    """
+    delete sync task\n
+    TASKID: sync task id
+    """
+    client = ctx.obj['client']
+    valid, response = client.delete_sync_task(taskid)
+    if valid:
+        click.echo("sync task[%s] delete success" % taskid)
+    else:
+        click.echo("delete sync task failed with message[%s]" % response)
+        sys.exit(1)
+
+
 def _print_fs(fslist, out_format):
     """print fs """
     headers = ['name', 'owner', 'type', 'server address', 'sub path', 'properties']
@@ -456,3 +582,13 @@ def _print_cache(cacheconfig, out_format):
     headers = ['fsname', 'owner', 'cache dir', 'meta driver', 'block size']
     data = [[cacheconfig.fsname, cacheconfig.username, cacheconfig.cachedir, cacheconfig.metadriver, cacheconfig.blocksize]]
     print_output(data, headers, out_format, table_format='grid')
+
+
+def _print_sync_task(tasklist, out_format):
+    """print fs sync task """
+    headers = ['task id', 'source', 'destination', 'mode', 'dry run', 'status', 'total', 'copied', 'skipped',
+               'deleted', 'failed', 'copied bytes', 'message']
+    data = [[task.taskid, "%s:%s" % (task.srcfsname, task.srcpath), "%s:%s" % (task.dstfsname, task.dstpath),
+             task.mode, task.dryrun, task.status, task.total, task.copied, task.skipped, task.deleted, task.failed,
+             task.copiedbytes, task.message] for task in tasklist]
+    print_output(data, headers, out_format, table_format='grid')
@@ -177,10 +177,11 @@ def _print_job_list(jobs, out_format, fieldlist):
 @job.command(context_settings=dict(max_content_width=2000), cls=command_required_option_from_option())
 @click.argument('jobtype')
 @click.argument('jsonpath')
+@click.option('--dry-run', 'dryrun', is_flag=True, help="Only validate the job and print the rendered kubernetes objects.")
 @click.pass_context
-def create(ctx, jobtype, jsonpath):
+def create(ctx, jobtype, jsonpath, dryrun=False):
     """ create job.\n
-    JOBTYPE: single, distributed or workflow.
+    JOBTYPE: single, distributed, workflow, notebook or serving.
     JSONPATH: relative path of json file under storage volume.
     """
     client = ctx.obj['client']
@@ -190,8 +191,10 @@ def create(ctx, jobtype, jsonpath):
     with open(jsonpath, 'r', encoding='utf8') as read_content:
         job_request_dict = json.load(read_content)
 
-    valid, response = client.create_job(jobtype, job_request_dict)
-    if valid:
+    valid, response = client.create_job(jobtype, job_request_dict, dryrun)
+    if valid and dryrun:
+        click.echo(json.dumps(response, indent=2))
+    elif valid:
         click.echo("job create success, id[%s]" % response)
     else:
         click.echo("job create failed with message[%s]" % response)
@@ -203,9 +206,10 @@ def create(ctx, jobtype, jsonpath):
 @click.option('-p', '--priority', help="Update the priority of job, such as: low, normal, high, e.g. --priority high")
 @click.option('-l', '--labels', help="Update the labels of job, e.g. --labels label1=value1,label2=value2")
 @click.option('-a', '--annotations', help="Update the annotations of job, e.g. --annotations anno1=value1,anno2=value2")
+@click.option('-r', '--replicas', help="Scale the replicas of elastic job members, e.g. --replicas worker=4")
 @click.pass_context
-def update(ctx, jobid, priority, labels, annotations):
-    """update job, including priority, labels, or annotations.\n
+def update(ctx, jobid, priority, labels, annotations, replicas=None):
+    """update job, including priority, labels, annotations, or replicas of elastic job.\n
     JOBID: the id of the specificed job.
     """
     client = ctx.obj['client']
@@ -220,7 +224,11 @@ def update(ctx, jobid, priority, labels, annotations):
     if annotations:
         args = annotations.split(',')
         annotationDict = dict([item.split("=") for item in args])
-    valid, response = client.update_job(jobid, priority, labelDict, annotationDict)
+    replicaDict = None
+    if replicas:
+        args = replicas.split(',')
+        replicaDict = dict([(item.split("=")[0], int(item.split("=")[1])) for item in args])
+    valid, response = client.update_job(jobid, priority, labelDict, annotationDict, replicaDict)
     if valid:
         click.echo("jobid[%s] update success" % jobid)
     else:
@@ -373,6 +373,68 @@ class Client(object):
         userinfo = {'header': self.header, 'name': username, 'host': self.paddleflow_server}
         return FSServiceApi.delete_cache(self.paddleflow_server, fsname, userinfo)
 
+    def create_sync_task(self, srcfsname, srcpath, dstfsname, dstpath, options=None, username=None):
+        """
+        create sync task to copy or sync data between fs, options could be mode, compare, workers,
+        bandwidthLimit and dryRun
+        """
+        self.pre_check()
+        if username and username.strip() == "":
+            raise PaddleFlowSDKException("InvalidUserName", "username should not be none or empty")
+        if not srcfsname or not dstfsname:
+            raise PaddleFlowSDKException("InvalidFsName", "srcfsname and dstfsname should not be none or empty")
+        userinfo = {'header': self.header, 'name': username, 'host': self.paddleflow_server}
+        return FSServiceApi.create_sync_task(self.paddleflow_server, srcfsname, srcpath, dstfsname, dstpath,
+                                             options or {}, userinfo)
+
+    def show_sync_task(self, taskid):
+        """
+        show sync task
+        """
+        self.pre_check()
+        if not taskid:
+            raise PaddleFlowSDKException("InvalidTaskID", "taskid should not be none or empty")
+        userinfo = {'header': self.header, 'name': None, 'host': self.paddleflow_server}
+        return FSServiceApi.show_sync_task(self.paddleflow_server, taskid, userinfo)
+
+    def list_sync_task(self, status=None):
+        """
+        list sync tasks
+        """
+        self.pre_check()
+        userinfo = {'header': self.header, 'name': None, 'host': self.paddleflow_server}
+        return FSServiceApi.list_sync_task(self.paddleflow_server, status, userinfo)
+
+    def stop_sync_task(self, taskid):
+        """
+        stop sync task
+        """
+        self.pre_check()
+        if not taskid:
+            raise PaddleFlowSDKException("InvalidTaskID", "taskid should not be none or empty")
+        userinfo = {'header': self.header, 'name': None, 'host': self.paddleflow_server}
+        return FSServiceApi.update_sync_task(self.paddleflow_server, taskid, "stop", userinfo)
+
+    def resume_sync_task(self, taskid):
+        """
+        resume sync task from checkpoint
+        """
+        self.pre_check()
+        if not taskid:
+            raise PaddleFlowSDKException("InvalidTaskID", "taskid should not be none or empty")
+        userinfo = {'header': self.header, 'name': None, 'host': self.paddleflow_server}
+        return FSServiceApi.update_sync_task(self.paddleflow_server, taskid, "resume", userinfo)
+
+    def delete_sync_task(self, taskid):
+        """
+        delete sync task
+        """
+        self.pre_check()
+        if not taskid:
+            raise PaddleFlowSDKException("InvalidTaskID", "taskid should not be none or empty")
+        userinfo = {'header': self.header, 'name': None, 'host': self.paddleflow_server}
+        return FSServiceApi.delete_sync_task(self.paddleflow_server, taskid, userinfo)
+
     def add_link(self, fsname, fspath, url, username=None, properties=None):
         """
         add link
@@ -727,18 +789,18 @@ class Client(object):
                                                    # job_id=job_id,
                                                    )
 
-    def create_job(self, job_type, job_request):
+    def create_job(self, job_type, job_request, dry_run=False):
         """
-        create_job
+        create_job, if dry_run is true, the rendered objects are returned and job is not created
         """
         self.pre_check()
         queueName = job_request.get('schedulingPolicy', {}).get('queue', None)
         if queueName is None or queueName == '':
             raise PaddleFlowSDKException("InvalidJobRequest",
                                          "job_request {} queue should not be none or empty".format(job_request))
-        if job_type is None or (job_type != 'single' and job_type != 'distributed' and job_type != 'workflow'):
-            raise PaddleFlowSDKException("InvalidJobType",
-                                         "job_type should not be none and should be single, distributed or workflow")
+        if job_type is None or job_type not in ['single', 'distributed', 'workflow', 'notebook', 'serving']:
+            raise PaddleFlowSDKException("InvalidJobType", "job_type should not be none and should be "
+                                                           "single, distributed, workflow, notebook or serving")
 
         job_request_obj = JobRequest(
             job_request.get('schedulingPolicy', {}).get('queue', None),
@@ -752,11 +814,14 @@ class Client(object):
             job_request.get('args', None), job_request.get('port', None),
             job_request.get('extensionTemplate', None),
             job_request.get('framework', None),
-            job_request.get('members', None)
+            job_request.get('members', None),
+            job_request.get('notebook', None),
+            job_request.get('replicas', None),
+            job_request.get('serving', None)
         )
         # if job_request.queue is None or job_request.queue == '':
         #     raise PaddleFlowSDKException("InvalidJobRequest", "job_request queue should not be none or empty")
-        return JobServiceApi.create_job(self.paddleflow_server, job_type, job_request_obj, self.header)
+        return JobServiceApi.create_job(self.paddleflow_server, job_type, job_request_obj, self.header, dry_run)
 
     def show_job(self, jobid):
         """
@@ -776,14 +841,15 @@ class Client(object):
         return JobServiceApi.list_job(self.paddleflow_server, status, timestamp, start_time, queue, labels, maxkeys,
                                       marker, self.header)
 
-    def update_job(self, jobid, priority=None, labels=None, annotations=None):
+    def update_job(self, jobid, priority=None, labels=None, annotations=None, replicas=None):
         """
         update_job
         """
         self.pre_check()
         if jobid is None or jobid == "":
             raise PaddleFlowSDKException("InvalidJobID", "jobid should not be none or empty")
-        return JobServiceApi.update_job(self.paddleflow_server, jobid, priority, labels, annotations, self.header)
+        return JobServiceApi.update_job(self.paddleflow_server, jobid, priority, labels, annotations, self.header,
+                                        replicas)
 
     def stop_job(self, jobid):
         """
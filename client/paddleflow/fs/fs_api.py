@@ -24,7 +24,7 @@ from urllib import parse
 from paddleflow.common.exception.paddleflow_sdk_exception import PaddleFlowSDKException
 from paddleflow.utils import api_client
 from paddleflow.common import api
-from paddleflow.fs.fs_info import FSInfo, LinkInfo, CacheConfigInfo
+from paddleflow.fs.fs_info import FSInfo, LinkInfo, CacheConfigInfo, SyncTaskInfo
 import signal
 
 
@@ -427,6 +427,116 @@ class FSServiceApi(object):
             return False, "no link found"
         return True, linkList
 
+    @classmethod
+    def create_sync_task(self, host, srcfsname, srcpath, dstfsname, dstpath, options,
+                         userinfo={'header': '', 'name': '', 'host': ''}):
+        """
+        create sync task to copy or sync data between fs
+        """
+        if not userinfo['header']:
+            raise PaddleFlowSDKException("Invalid request", "please login paddleflow first")
+        body = dict(options)
+        body['srcFsName'] = srcfsname
+        body['srcPath'] = srcpath
+        body['dstFsName'] = dstfsname
+        body['dstPath'] = dstpath
+        if userinfo['name']:
+            body['username'] = userinfo['name']
+        response = api_client.call_api(method="POST", url=parse.urljoin(host, api.PADDLE_FLOW_FS_SYNC),
+                                       headers=userinfo['header'], json=body)
+        if not response:
+            raise PaddleFlowSDKException("Create sync task error", response.text)
+        data = json.loads(response.text)
+        if 'message' in data and 'id' not in data:
+            return False, data['message']
+        return True, self._sync_task_info(data)
+
+    @classmethod
+    def show_sync_task(self, host, taskid, userinfo={'header': '', 'name': '', 'host': ''}):
+        """
+        show sync task
+        """
+        if not userinfo['header']:
+            raise PaddleFlowSDKException("Invalid request", "please login paddleflow first")
+        response = api_client.call_api(method="GET", url=parse.urljoin(host, api.PADDLE_FLOW_FS_SYNC + "/%s" % taskid),
+                                       headers=userinfo['header'])
+        if not response:
+            raise PaddleFlowSDKException("Show sync task error", response.text)
+        data = json.loads(response.text)
+        if 'message' in data and 'id' not in data:
+            return False, data['message']
+        return True, self._sync_task_info(data)
+
+    @classmethod
+    def list_sync_task(self, host, status=None, userinfo={'header': '', 'name': '', 'host': ''}):
+        """
+        list sync tasks
+        """
+        if not userinfo['header']:
+            raise PaddleFlowSDKException("Invalid request", "please login paddleflow first")
+        params = None
+        if status:
+            params = {
+                'status': status
+            }
+        response = api_client.call_api(method="GET", url=parse.urljoin(host, api.PADDLE_FLOW_FS_SYNC),
+                                       headers=userinfo['header'], params=params)
+        if not response:
+            raise PaddleFlowSDKException("List sync task error", response.text)
+        data = json.loads(response.text)
+        if 'message' in data:
+            return False, data['message']
+        return True, [self._sync_task_info(task) for task in data['taskList']]
+
+    @classmethod
+    def update_sync_task(self, host, taskid, action, userinfo={'header': '', 'name': '', 'host': ''}):
+        """
+        stop or resume sync task
+        """
+        if not userinfo['header']:
+            raise PaddleFlowSDKException("Invalid request", "please login paddleflow first")
+        params = {
+            'action': action
+        }
+        response = api_client.call_api(method="PUT", url=parse.urljoin(host, api.PADDLE_FLOW_FS_SYNC + "/%s" % taskid),
+                                       headers=userinfo['header'], params=params)
+        if not response:
+            raise PaddleFlowSDKException("%s sync task error" % action, response.text)
+        data = json.loads(response.text)
+        if 'message' in data and 'id' not in data:
+            return False, data['message']
+        return True, self._sync_task_info(data)
+
+    @classmethod
+    def delete_sync_task(self, host, taskid, userinfo={'header': '', 'name': '', 'host': ''}):
+        """
+        delete sync task
+        """
+        if not userinfo['header']:
+            raise PaddleFlowSDKException("Invalid request", "please login paddleflow first")
+        response = api_client.call_api(method="DELETE",
+                                       url=parse.urljoin(host, api.PADDLE_FLOW_FS_SYNC + "/%s" % taskid),
+                                       headers=userinfo['header'])
+        if not response:
+            raise PaddleFlowSDKException("Delete sync task error", response.text)
+        if not response.text:
+            return True, None
+        data = json.loads(response.text)
+        if 'message' in data:
+            return False, data['message']
+        return True, None
+
+    @classmethod
+    def _sync_task_info(self, task):
+        """
+        sync task info from response
+        """
+        return SyncTaskInfo(task['id'], task['userName'], task['srcFsName'], task['srcPath'], task['dstFsName'],
+                            task['dstPath'], task['mode'], task['compare'], task['dryRun'], task['status'],
+                            task.get('message', ''), task['total'], task['copied'], task['skipped'],
+                            task['deleted'], task['failed'], task['copiedBytes'], task.get('diff', []),
+                            task['createTime'], task['updateTime'])
+
     @classmethod
     def getMountOptions(self, mount_options):
         """
@@ -50,4 +50,31 @@ class CacheConfigInfo(object):
         self.username = username
         self.cachedir = cachedir
         self.metadriver = metadriver
-        self.blocksize = blocksize
\ No newline at end of file
+        self.blocksize = blocksize
+
+
+class SyncTaskInfo(object):
+    """the class of fs sync task"""
+    def __init__(self, taskid, username, srcfsname, srcpath, dstfsname, dstpath, mode, compare, dryrun, status,
+                 message, total, copied, skipped, deleted, failed, copiedbytes, diff, createtime, updatetime):
+        """init """
+        self.taskid = taskid
+        self.username = username
+        self.srcfsname = srcfsname
+        self.srcpath = srcpath
+        self.dstfsname = dstfsname
+        self.dstpath = dstpath
+        self.mode = mode
+        self.compare = compare
+        self.dryrun = dryrun
+        self.status = status
+        self.message = message
+        self.total = total
+        self.copied = copied
+        self.skipped = skipped
+        self.deleted = deleted
+        self.failed = failed
+        self.copiedbytes = copiedbytes
+        self.diff = diff
+        self.createtime = createtime
+        self.updatetime = updatetime
@@ -36,13 +36,14 @@ class JobServiceApi(object):
         pass
 
     @classmethod
-    def create_job(cls, host, job_type, job_request, header=None):
+    def create_job(cls, host, job_type, job_request, header=None, dry_run=False):
         """
 
         :param host:
         :param job_type:
         :param job_request:
         :param header:
+        :param dry_run: only validate the job, and return the rendered objects without creating job
         :return:
         """
         if not header:
@@ -68,16 +69,19 @@ class JobServiceApi(object):
                                                                  member.get('args', None), member.get('port', None),
                                                                  member.get('extensionTemplate', None)))
                 body['members'].append(member_dict)
+        params = {'dryRun': 'true'} if dry_run else None
         response = api_client.call_api(method="POST",
                                        url=parse.urljoin(
                                            host, api.PADDLE_FLOW_JOB + "/%s" % job_type),
-                                       headers=header,
+                                       headers=header, params=params,
                                        json=body)
         if not response:
             raise PaddleFlowSDKException("Create job error", response.text)
         data = json.loads(response.text)
         if 'message' in data:
             return False, data['message']
+        if dry_run:
+            return True, data['objects']
         return True, data['id']
 
     @classmethod
@@ -139,6 +143,12 @@ class JobServiceApi(object):
             body['port'] = job_request.port
         if job_request.extension_template:
             body['extensionTemplate'] = job_request.extension_template
+        if job_request.notebook:
+            body['notebook'] = job_request.notebook
+        if job_request.replicas:
+            body['replicas'] = job_request.replicas
+        if job_request.serving:
+            body['serving'] = job_request.serving
 
 
 
@@ -253,15 +263,16 @@ class JobServiceApi(object):
 
 
     @classmethod
-    def update_job(cls, host, job_id, priority, labels, annotations, header=None):
+    def update_job(cls, host, job_id, priority, labels, annotations, header=None, replicas=None):
         """
-        update job priority, labels, or annotations
+        update job priority, labels, annotations, or replicas of elastic job
 
         :param host:
         :param job_id:
         :param priority:
         :param labels:
         :param annotations:
+        :param replicas: replicas of members to scale, the key is role of member
         """
         if not header:
             raise PaddleFlowSDKException("InvalidRequest", "paddleflow should login first")
@@ -275,6 +286,8 @@ class JobServiceApi(object):
             body['labels'] = labels
         if annotations is not None:
             body['annotations'] = annotations
+        if replicas is not None:
+            body['replicas'] = replicas
         response = api_client.call_api(method="PUT", url=parse.urljoin(host, api.PADDLE_FLOW_JOB + "/%s" % job_id),
                                                headers=header, params=params, json=body)
         if not response:
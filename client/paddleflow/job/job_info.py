@@ -89,7 +89,8 @@ class JobRequest(object):
 
     def __init__(self, queue, image=None, job_id=None, job_name=None, labels=None, annotations=None, priority=None,
                  flavour=None, fs=None, extra_fs_list=None, env=None, command=None, args_list=None, port=None,
-                 extension_template=None, framework=None, member_list=None):
+                 extension_template=None, framework=None, member_list=None, notebook=None,
+                 replicas=None, serving=None):
         """
 
         :param queue:
@@ -109,6 +110,9 @@ class JobRequest(object):
         :param extension_template:
         :param framework:
         :param member_list:
+        :param notebook:
+        :param replicas:
+        :param serving:
         """
         self.job_id = job_id
         self.job_name = job_name
@@ -127,6 +131,9 @@ class JobRequest(object):
         self.extension_template = extension_template
         self.framework = framework
         self.member_list = member_list
+        self.notebook = notebook
+        self.replicas = replicas
+        self.serving = serving
 
 
 class Member(object):
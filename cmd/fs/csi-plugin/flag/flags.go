@@ -40,6 +40,17 @@ func CsiPluginFlags() []cli.Flag {
 			Value: "",
 			Usage: "password",
 		},
+		&cli.StringFlag{
+			Name:  "mount-share-mode",
+			Value: "pvc",
+			Usage: "share a mount pod among volumes of the same fs, pvc or pod on a node",
+		},
+		&cli.BoolFlag{
+			Name:  "mount-pod-rolling-upgrade",
+			Value: false,
+			Usage: "move volumes of running pods to new mount pods when mount image or cache config changes, " +
+				"running containers use the new mount pods only after restarted, and the outdated ones are kept until the pods are gone",
+		},
 	}
 }
 
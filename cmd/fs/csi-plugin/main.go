@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"fmt"
 	"os"
 	"time"
 
@@ -135,6 +136,14 @@ func act(c *cli.Context) error {
 		return err
 	}
 
+	if !csiconfig.IsValidMountShareMode(c.String("mount-share-mode")) {
+		err := fmt.Errorf("invalid mount share mode: %s", c.String("mount-share-mode"))
+		log.Errorf("csi-plugin act err: %v", err)
+		return err
+	}
+	csiconfig.MountShareMode = c.String("mount-share-mode")
+	csiconfig.MountPodRollingUpgrade = c.Bool("mount-pod-rolling-upgrade")
+
 	stopChan := make(chan struct{})
 	defer close(stopChan)
 	ctrl := controller.GetMountPointController(c.String("node-id"))
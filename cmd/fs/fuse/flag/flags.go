@@ -159,6 +159,11 @@ func MountFlags(fuseConf *meta.FuseConfig) []cli.Flag {
 			Value: true,
 			Usage: "kernel does not issue anyXAttr operations at all",
 		},
+		&cli.BoolFlag{
+			Name:  "enable-locks",
+			Value: false,
+			Usage: "handle flock and fcntl locks by meta instead of kernel, locks are only seen by the processes of this mount",
+		},
 		&cli.IntFlag{
 			Name:        "dir-mode",
 			Value:       ufs.DefaultDirMode,
@@ -191,6 +196,27 @@ func LinkFlags() []cli.Flag {
 			Value: "",
 			Usage: "link meta dir prefix",
 		},
+		&cli.IntFlag{
+			Name:  "quota-update-interval",
+			Value: 15,
+			Usage: "quota update interval, quotas are enforced by each client on its own writes as best-effort",
+		},
+		&cli.Float64Flag{
+			Name:  "audit-sample-rate",
+			Value: 0,
+			Usage: "ratio in (0, 1] of file open and delete events reported to server audit log, 0 disables audit",
+		},
+		&cli.IntFlag{
+			Name:  "audit-report-interval",
+			Value: 10,
+			Usage: "audit events report interval",
+		},
+		&cli.StringFlag{
+			Name:    "audit-job-id",
+			Value:   "",
+			Usage:   "job id recorded in audit events, for clients mounted for a single job",
+			EnvVars: []string{"PF_JOB_ID"},
+		},
 	}
 }
 
@@ -25,6 +25,7 @@ import (
 	_ "net/http/pprof"
 	"os"
 	"os/signal"
+	"path"
 	"runtime"
 	"strings"
 	"syscall"
@@ -43,6 +44,7 @@ import (
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/http/core"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/logger"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/client/audit"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/client/base"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/client/cache"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/client/fuse"
@@ -134,6 +136,7 @@ func setup(c *cli.Context) error {
 
 	opts.IgnoreSecurityLabels = c.Bool("ignore-security-labels")
 	opts.DisableXAttrs = c.Bool("disable-xattrs")
+	opts.EnableLocks = c.Bool("enable-locks")
 	opts.AllowOther = c.Bool("allow-other")
 
 	// Wrap the default registry, all prometheus.MustRegister() calls should be afterwards
@@ -239,6 +242,8 @@ func mount(c *cli.Context) error {
 	}
 
 	if !c.Bool("local") {
+		// the config files of links and quotas are maintained by server, and can not be modified by users
+		vfs.GetVFS().SetReadOnlyDirs(path.Join(c.String("link-meta-dir-prefix"), common.LinkMetaDir))
 		stopChan := make(chan struct{})
 		defer close(stopChan)
 		if !c.Bool("skip-check-links") {
@@ -250,6 +255,16 @@ func mount(c *cli.Context) error {
 			}
 			go f()
 		}
+		go func() {
+			if err := vfs.GetVFS().Meta.QuotaMetaUpdateHandler(stopChan,
+				c.Int("quota-update-interval"), c.String("link-meta-dir-prefix")); err != nil {
+				log.Errorf("mount setup() vfs.GetVFS().Meta.QuotaMetaUpdateHandler err: %v", err)
+			}
+		}()
+		if rate := c.Float64("audit-sample-rate"); rate > 0 && base.Client != nil {
+			auditor := audit.Init(rate, c.String("audit-job-id"), base.Client.ReportAudit)
+			go auditor.Run(stopChan, time.Duration(c.Int("audit-report-interval"))*time.Second)
+		}
 	}
 
 	log.Debugf("start mount service")
@@ -419,6 +434,7 @@ func InitVFS(c *cli.Context, registry *prometheus.Registry) error {
 	vfsOptions := []vfs.Option{
 		vfs.WithDataCacheConfig(d),
 		vfs.WithMetaConfig(m),
+		vfs.WithLocks(c.Bool("enable-locks")),
 	}
 
 	vfsConfig := vfs.InitConfig(vfsOptions...)
@@ -217,6 +217,18 @@ func FilesystemFlags(fsConf *config.FsServerConf) []cli.Flag {
 			Usage:       "api server service port in k8s",
 			Destination: &fsConf.ServicePort,
 		},
+		&cli.BoolFlag{
+			Name:        "scheduler-extender",
+			Value:       fsConf.SchedulerExtender,
+			Usage:       "enable scheduler extender endpoints to score nodes by fs cache locality, which expose fs cache nodes and require scheduler-extender-token",
+			Destination: &fsConf.SchedulerExtender,
+		},
+		&cli.StringFlag{
+			Name:        "scheduler-extender-token",
+			Value:       fsConf.SchedulerExtenderToken,
+			Usage:       "shared token carried in the url path of scheduler extender requests",
+			Destination: &fsConf.SchedulerExtenderToken,
+		},
 	}
 }
 
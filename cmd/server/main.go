@@ -24,17 +24,22 @@ import (
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/config"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/logger"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/tracing"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/job"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/metrics"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/monitor"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/storage/driver"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage/secret"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/trace_logger"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/version"
 )
 
-var ServerConf *config.ServerConfig
+var (
+	ServerConf      *config.ServerConfig
+	tracingShutdown tracing.ShutdownFunc
+)
 
 func main() {
 	if err := Main(os.Args); err != nil {
@@ -72,6 +77,13 @@ func Main(args []string) error {
 		EnableBashCompletion: true,
 		Flags:                flag.ExpandFlags(compoundFlags),
 		Action:               act,
+		Commands: []*cli.Command{
+			{
+				Name:   "reencrypt-secrets",
+				Usage:  "re-encrypt cluster credentials and filesystem secrets by current master key",
+				Action: reEncryptSecrets,
+			},
+		},
 	}
 	return app.Run(args)
 }
@@ -85,6 +97,29 @@ func act(c *cli.Context) error {
 	return err
 }
 
+// reEncryptSecrets re-encrypts secrets in database after master key is rotated or encryption is enabled
+func reEncryptSecrets(c *cli.Context) error {
+	if err := logger.InitStandardFileLogger(&ServerConf.Log); err != nil {
+		log.Errorf("InitStandardFileLogger err: %v", err)
+		return err
+	}
+	if err := secret.Init(&ServerConf.Secret); err != nil {
+		log.Errorf("init secret provider err: %v", err)
+		return err
+	}
+	if err := driver.InitStorage(&ServerConf.Storage, ServerConf.Log.Level); err != nil {
+		log.Errorf("init database err: %v", err)
+		return err
+	}
+	count, err := storage.ReEncryptSecrets(storage.DB)
+	if err != nil {
+		log.Errorf("re-encrypt secrets failed, %d records are updated, err: %v", count, err)
+		return err
+	}
+	fmt.Printf("re-encrypt secrets successfully, %d records are updated\n", count)
+	return nil
+}
+
 func start() error {
 	Router := chi.NewRouter()
 	router.RegisterRouters(Router, false)
@@ -105,6 +140,8 @@ func start() error {
 	stopChan := make(chan struct{})
 	defer close(stopChan)
 	go fs.MountPodController(ServerConf.Fs.MountPodExpire, ServerConf.Fs.MountPodIntervalTime, stopChan)
+	go fs.ResumeFsSyncTasks(stopChan)
+	go fs.FailStaleFsSnapshots(stopChan)
 
 	trace_logger.Start(ServerConf.TraceLog)
 
@@ -128,6 +165,9 @@ func start() error {
 	if err := HttpSvr.Shutdown(ServerCtx); err != nil {
 		log.Infof("Server forced to shutdown:%s", err.Error())
 	}
+	if err := tracingShutdown(ServerCtx); err != nil {
+		log.Errorf("shutdown tracing failed, err: %v", err)
+	}
 	log.Info("PaddleFlow server exiting")
 	return nil
 }
@@ -184,6 +224,16 @@ func setup() {
 
 	log.Infof("The final server config is: %s ", config.PrettyFormat(ServerConf))
 
+	if tracingShutdown, err = tracing.Init(ServerConf.Tracing); err != nil {
+		log.Errorf("init tracing err: %v", err)
+		gracefullyExit(err)
+	}
+
+	if err = secret.Init(&ServerConf.Secret); err != nil {
+		log.Errorf("init secret provider err: %v", err)
+		gracefullyExit(err)
+	}
+
 	dbConf := &ServerConf.Storage
 	if err := driver.InitStorage(&config.StorageConfig{
 		Driver:   dbConf.Driver,
@@ -201,6 +251,9 @@ func setup() {
 		log.Errorf("init cache err: %v", err)
 		gracefullyExit(err)
 	}
+	if len(ServerConf.Job.GPUResourceNames) > 0 {
+		storage.GPUResourceNames = ServerConf.Job.GPUResourceNames
+	}
 
 	if err := newAndStartJobManager(); err != nil {
 		log.Errorf("create pfjob manager failed, err %v", err)
@@ -224,6 +277,10 @@ func setup() {
 		log.Errorf("create prometheus client failed, err %v", err)
 		gracefullyExit(err)
 	}
+	if err := monitor.InitMetricBackend(ServerConf.Monitor.Backend, ServerConf.Monitor.Queries); err != nil {
+		log.Errorf("init metric backend failed, err %v", err)
+		gracefullyExit(err)
+	}
 
 	metrics.InitMetrics()
 }
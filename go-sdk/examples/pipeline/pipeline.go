@@ -220,8 +220,34 @@ func UpdatePipelineByRaw(filepath string, pplID string) (string, string) {
 	return pplID, pplVerID
 }
 
+// BuildPipeline builds pipeline in code instead of run yaml
+func BuildPipeline() (p *pipeline.Pipeline) {
+	preprocess := pipeline.NewStep("preprocess").
+		Command("python preprocess.py --data {{data_path}}").
+		TypedParam("data_path", "path", "./data").
+		OutputArtifact("train_data")
+	train := pipeline.NewStep("train").
+		Command("python train.py --lr {{lr}} --data {{train_data}}").
+		Param("lr", 0.1).
+		InputArtifact("train_data", preprocess.Ref("train_data")).
+		OutputArtifact("model").
+		After(preprocess.Name())
+
+	p, err := pipeline.NewBuilder("example").
+		DockerEnv("python:3.7").
+		FS(schema.FsMount{Name: "ppl"}).
+		Cache(schema.Cache{Enable: true, MaxExpiredTime: "-1"}).
+		Add(preprocess, train).
+		Build()
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
 func main() {
 	GetPipelineFromFile("")
+	BuildPipeline()
 	CreateRunByRunYamlRaw("")
 	CreatePipelineByRaw("")
 	UpdatePipelineByRaw("", "ppl-000096")
@@ -0,0 +1,420 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipeline
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+)
+
+// Node is a step or a dag of pipeline
+type Node interface {
+	Name() string
+	// Ref returns the template which refers to parameter or artifact of node, such as {{step.param}}
+	Ref(field string) string
+	fields() map[string]interface{}
+	err() error
+}
+
+// Builder builds pipeline in code, only the fields set by builder are written to the run yaml,
+// so that the global fields of pipeline, such as docker_env and cache, are inherited by steps as run yaml does.
+type Builder struct {
+	name        string
+	global      map[string]interface{}
+	entryPoints []Node
+	components  []Node
+	postProcess []Node
+}
+
+// NewBuilder returns a pipeline builder with the name of pipeline
+func NewBuilder(name string) *Builder {
+	return &Builder{
+		name:   name,
+		global: map[string]interface{}{},
+	}
+}
+
+// DockerEnv sets the default image of steps
+func (b *Builder) DockerEnv(image string) *Builder {
+	b.global["docker_env"] = image
+	return b
+}
+
+func (b *Builder) Parallelism(parallelism int) *Builder {
+	b.global["parallelism"] = parallelism
+	return b
+}
+
+// Cache sets the default cache of steps
+func (b *Builder) Cache(cache schema.Cache) *Builder {
+	b.global["cache"] = cacheFields(cache)
+	return b
+}
+
+// FailureStrategy sets the strategy when step fails, which is fail_fast or continue
+func (b *Builder) FailureStrategy(strategy string) *Builder {
+	b.global["failure_options"] = map[string]interface{}{"strategy": strategy}
+	return b
+}
+
+// FS sets the main fs and extra fs mounted by steps
+func (b *Builder) FS(mainFS schema.FsMount, extraFS ...schema.FsMount) *Builder {
+	options := map[string]interface{}{"main_fs": fsMountFields(mainFS)}
+	if len(extraFS) != 0 {
+		options["extra_fs"] = fsMountListFields(extraFS)
+	}
+	b.global["fs_options"] = options
+	return b
+}
+
+// Disable disables the components by their absolute names, such as dag.step
+func (b *Builder) Disable(names ...string) *Builder {
+	b.global["disabled"] = strings.Join(names, ",")
+	return b
+}
+
+// Add adds steps or dags to entry points of pipeline
+func (b *Builder) Add(nodes ...Node) *Builder {
+	b.entryPoints = append(b.entryPoints, nodes...)
+	return b
+}
+
+// Component adds reusable components, which are referred by steps with Reference
+func (b *Builder) Component(nodes ...Node) *Builder {
+	b.components = append(b.components, nodes...)
+	return b
+}
+
+// PostProcess sets the step which runs after all entry points finished
+func (b *Builder) PostProcess(step *Step) *Builder {
+	b.postProcess = append(b.postProcess, step)
+	return b
+}
+
+// Yaml returns the run yaml of pipeline without validation
+func (b *Builder) Yaml() ([]byte, error) {
+	runYaml := map[string]interface{}{"name": b.name}
+	for key, value := range b.global {
+		runYaml[key] = value
+	}
+	entryPoints, err := nodeFields(b.entryPoints)
+	if err != nil {
+		return nil, err
+	}
+	runYaml["entry_points"] = entryPoints
+	if len(b.components) != 0 {
+		if runYaml["components"], err = nodeFields(b.components); err != nil {
+			return nil, err
+		}
+	}
+	if len(b.postProcess) != 0 {
+		if runYaml["post_process"], err = nodeFields(b.postProcess); err != nil {
+			return nil, err
+		}
+	}
+	return yaml.Marshal(runYaml)
+}
+
+// Build returns the pipeline parsed from run yaml, and validates it as the server does
+func (b *Builder) Build() (*Pipeline, error) {
+	if len(b.entryPoints) == 0 {
+		return nil, fmt.Errorf("pipeline %s has no entry points", b.name)
+	}
+	content, err := b.Yaml()
+	if err != nil {
+		return nil, err
+	}
+	return NewPipelineFromYamlBytes(content)
+}
+
+func nodeFields(nodes []Node) (map[string]interface{}, error) {
+	result := make(map[string]interface{}, len(nodes))
+	for _, node := range nodes {
+		if err := node.err(); err != nil {
+			return nil, err
+		}
+		if _, ok := result[node.Name()]; ok {
+			return nil, fmt.Errorf("component name [%s] is duplicated", node.Name())
+		}
+		result[node.Name()] = node.fields()
+	}
+	return result, nil
+}
+
+// component holds the fields shared by step and dag
+type component struct {
+	name  string
+	field map[string]interface{}
+	error error
+}
+
+func newComponent(name string) component {
+	return component{
+		name:  name,
+		field: map[string]interface{}{},
+	}
+}
+
+func (c *component) Name() string {
+	return c.name
+}
+
+func (c *component) Ref(field string) string {
+	return fmt.Sprintf("{{%s.%s}}", c.name, field)
+}
+
+func (c *component) fields() map[string]interface{} {
+	return c.field
+}
+
+func (c *component) err() error {
+	return c.error
+}
+
+func (c *component) setMapField(key, name string, value interface{}) {
+	sub, ok := c.field[key].(map[string]interface{})
+	if !ok {
+		sub = map[string]interface{}{}
+		c.field[key] = sub
+	}
+	sub[name] = value
+}
+
+func (c *component) setArtifact(artifactType, name, value string) {
+	artifacts, ok := c.field["artifacts"].(map[string]interface{})
+	if !ok {
+		artifacts = map[string]interface{}{}
+		c.field["artifacts"] = artifacts
+	}
+	sub, ok := artifacts[artifactType].(map[string]interface{})
+	if !ok {
+		sub = map[string]interface{}{}
+		artifacts[artifactType] = sub
+	}
+	sub[name] = value
+}
+
+func (c *component) addDeps(deps []string) {
+	current, _ := c.field["deps"].(string)
+	all := make([]string, 0, len(deps)+1)
+	if current != "" {
+		all = append(all, current)
+	}
+	c.field["deps"] = strings.Join(append(all, deps...), ",")
+}
+
+// Step is a step of pipeline, which runs command in docker env
+type Step struct {
+	component
+}
+
+func NewStep(name string) *Step {
+	return &Step{component: newComponent(name)}
+}
+
+func (s *Step) Command(command string) *Step {
+	s.field["command"] = command
+	return s
+}
+
+func (s *Step) DockerEnv(image string) *Step {
+	s.field["docker_env"] = image
+	return s
+}
+
+// Param sets parameter of step, and the value can be a template which refers to upstream parameter
+func (s *Step) Param(name string, value interface{}) *Step {
+	s.setMapField("parameters", name, value)
+	return s
+}
+
+// TypedParam sets parameter with type, such as string, int, float, path and list, and its default value
+func (s *Step) TypedParam(name, paramType string, defaultValue interface{}) *Step {
+	s.setMapField("parameters", name, map[string]interface{}{"type": paramType, "default": defaultValue})
+	return s
+}
+
+func (s *Step) Env(name, value string) *Step {
+	s.setMapField("env", name, value)
+	return s
+}
+
+// InputArtifact sets input artifact of step, and the value must refer to output artifact of upstream step
+func (s *Step) InputArtifact(name, value string) *Step {
+	s.setArtifact(schema.ArtifactTypeInput, name, value)
+	return s
+}
+
+// OutputArtifact declares output artifact of step, and its path is generated by server
+func (s *Step) OutputArtifact(name string) *Step {
+	// the path of output artifact in step is empty
+	s.setArtifact(schema.ArtifactTypeOutput, name, "")
+	return s
+}
+
+// After sets the upstream components in the same dag
+func (s *Step) After(deps ...string) *Step {
+	s.addDeps(deps)
+	return s
+}
+
+// Condition sets the expression to decide whether to run step, such as "{{num}} > 10"
+func (s *Step) Condition(condition string) *Step {
+	s.field["condition"] = condition
+	return s
+}
+
+// LoopArgument runs step for each item of list, or a template which refers to parameter of step
+func (s *Step) LoopArgument(argument interface{}) *Step {
+	s.field["loop_argument"] = argument
+	return s
+}
+
+func (s *Step) Cache(cache schema.Cache) *Step {
+	s.field["cache"] = cacheFields(cache)
+	return s
+}
+
+// Reference makes the step reuse the component, and the step can only set deps, parameters and input artifacts
+func (s *Step) Reference(component string) *Step {
+	s.field["reference"] = map[string]interface{}{"component": component}
+	return s
+}
+
+func (s *Step) ExtraFS(fs ...schema.FsMount) *Step {
+	s.field["extra_fs"] = fsMountListFields(fs)
+	return s
+}
+
+// Dag is a group of steps and dags, which can be looped or skipped as a whole
+type Dag struct {
+	component
+	children []Node
+}
+
+func NewDag(name string) *Dag {
+	return &Dag{component: newComponent(name)}
+}
+
+func (d *Dag) Param(name string, value interface{}) *Dag {
+	d.setMapField("parameters", name, value)
+	return d
+}
+
+func (d *Dag) TypedParam(name, paramType string, defaultValue interface{}) *Dag {
+	d.setMapField("parameters", name, map[string]interface{}{"type": paramType, "default": defaultValue})
+	return d
+}
+
+func (d *Dag) InputArtifact(name, value string) *Dag {
+	d.setArtifact(schema.ArtifactTypeInput, name, value)
+	return d
+}
+
+// OutputArtifact sets output artifact of dag, and the value must refer to output artifact of its sub component
+func (d *Dag) OutputArtifact(name, value string) *Dag {
+	d.setArtifact(schema.ArtifactTypeOutput, name, value)
+	return d
+}
+
+func (d *Dag) After(deps ...string) *Dag {
+	d.addDeps(deps)
+	return d
+}
+
+func (d *Dag) Condition(condition string) *Dag {
+	d.field["condition"] = condition
+	return d
+}
+
+func (d *Dag) LoopArgument(argument interface{}) *Dag {
+	d.field["loop_argument"] = argument
+	return d
+}
+
+// Add adds steps or dags into dag
+func (d *Dag) Add(nodes ...Node) *Dag {
+	d.children = append(d.children, nodes...)
+	return d
+}
+
+func (d *Dag) fields() map[string]interface{} {
+	entryPoints, err := nodeFields(d.children)
+	if err != nil {
+		d.error = err
+		return nil
+	}
+	d.field["entry_points"] = entryPoints
+	return d.field
+}
+
+func (d *Dag) err() error {
+	if d.error != nil {
+		return d.error
+	}
+	if len(d.children) == 0 {
+		return fmt.Errorf("dag [%s] has no entry points", d.name)
+	}
+	for _, child := range d.children {
+		if err := child.err(); err != nil {
+			return err
+		}
+	}
+	_, err := nodeFields(d.children)
+	return err
+}
+
+func cacheFields(cache schema.Cache) map[string]interface{} {
+	fields := map[string]interface{}{schema.CacheAttributeEnable: cache.Enable}
+	if cache.MaxExpiredTime != "" {
+		fields[schema.CacheAttributeMaxExpiredTime] = cache.MaxExpiredTime
+	}
+	if len(cache.FsScope) != 0 {
+		scopes := make([]interface{}, 0, len(cache.FsScope))
+		for _, scope := range cache.FsScope {
+			scopes = append(scopes, map[string]interface{}{"name": scope.Name, "path": scope.Path})
+		}
+		fields[schema.CacheAttributeFsScope] = scopes
+	}
+	return fields
+}
+
+func fsMountFields(fs schema.FsMount) map[string]interface{} {
+	fields := map[string]interface{}{"name": fs.Name}
+	if fs.MountPath != "" {
+		fields["mount_path"] = fs.MountPath
+	}
+	if fs.SubPath != "" {
+		fields["sub_path"] = fs.SubPath
+	}
+	if fs.ReadOnly {
+		fields["read_only"] = fs.ReadOnly
+	}
+	return fields
+}
+
+func fsMountListFields(fsList []schema.FsMount) []interface{} {
+	result := make([]interface{}, 0, len(fsList))
+	for _, fs := range fsList {
+		result = append(result, fsMountFields(fs))
+	}
+	return result
+}
@@ -0,0 +1,184 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipeline
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+)
+
+func newMockBuilder() *Builder {
+	preprocess := NewStep("preprocess").
+		Command("python preprocess.py --data {{data_path}}").
+		TypedParam("data_path", "path", "./data").
+		OutputArtifact("train_data")
+	train := NewStep("train").
+		Command("python train.py --lr {{lr}} --data {{train_data}}").
+		Param("lr", 0.1).
+		InputArtifact("train_data", preprocess.Ref("train_data")).
+		OutputArtifact("model").
+		Cache(schema.Cache{Enable: true, MaxExpiredTime: "3600"}).
+		After(preprocess.Name())
+	evaluate := NewStep("evaluate").
+		Command("python evaluate.py --model {{model}} --round {{PF_LOOP_ARGUMENT}}").
+		InputArtifact("model", "{{train.model}}").
+		LoopArgument([]interface{}{1, 2, 3})
+	evalDag := NewDag("eval-dag").
+		InputArtifact("model", train.Ref("model")).
+		Add(evaluate).
+		After(train.Name())
+	evaluate.InputArtifact("model", "{{PF_PARENT.model}}")
+
+	return NewBuilder("mock-pipeline").
+		DockerEnv("python:3.7").
+		Parallelism(5).
+		FailureStrategy(schema.FailureStrategyContinue).
+		Cache(schema.Cache{Enable: false, MaxExpiredTime: "-1"}).
+		Add(preprocess, train, evalDag).
+		Component(NewStep("notify").Command("echo {{msg}}").Param("msg", "done")).
+		PostProcess(NewStep("clean").Reference("notify").Param("msg", "finished"))
+}
+
+func TestBuilder_Build(t *testing.T) {
+	pipeline, err := newMockBuilder().Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "mock-pipeline", pipeline.Name)
+	assert.Equal(t, 5, pipeline.Parallelism)
+	assert.Equal(t, schema.FailureStrategyContinue, pipeline.FailureOptions.Strategy)
+
+	train, ok := pipeline.EntryPoints.EntryPoints["train"].(*schema.WorkflowSourceStep)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"preprocess"}, train.GetDeps())
+	assert.Equal(t, "{{preprocess.train_data}}", train.Artifacts.Input["train_data"])
+	// docker env of pipeline is inherited by steps
+	assert.Equal(t, "python:3.7", train.DockerEnv)
+	assert.True(t, train.Cache.Enable)
+
+	preprocess := pipeline.EntryPoints.EntryPoints["preprocess"].(*schema.WorkflowSourceStep)
+	// dict parameter keeps its type and default value for server
+	assert.Equal(t, map[string]interface{}{"type": "path", "default": "./data"}, preprocess.Parameters["data_path"])
+
+	dag, ok := pipeline.EntryPoints.EntryPoints["eval-dag"].(*schema.WorkflowSourceDag)
+	assert.True(t, ok)
+	assert.Contains(t, dag.EntryPoints, "evaluate")
+	assert.Contains(t, pipeline.Components, "notify")
+	assert.Equal(t, "notify", pipeline.PostProcess["clean"].Reference.Component)
+
+	runYamlRaw, err := pipeline.TransToRunYamlRaw()
+	assert.NoError(t, err)
+	content, err := base64.StdEncoding.DecodeString(runYamlRaw)
+	assert.NoError(t, err)
+	parsed, err := NewPipelineFromYamlBytes(content)
+	assert.NoError(t, err)
+	assert.Contains(t, parsed.Components, "notify")
+}
+
+func TestBuilder_BuildInvalid(t *testing.T) {
+	testCases := []struct {
+		name    string
+		builder func() *Builder
+	}{
+		{
+			name: "invalid pipeline name",
+			builder: func() *Builder {
+				return NewBuilder("invalid name").Add(NewStep("main").Command("echo"))
+			},
+		},
+		{
+			name: "invalid step name",
+			builder: func() *Builder {
+				return NewBuilder("ppl").Add(NewStep("main_step").Command("echo"))
+			},
+		},
+		{
+			name: "duplicated step name",
+			builder: func() *Builder {
+				return NewBuilder("ppl").Add(NewStep("main").Command("echo"), NewStep("main").Command("echo"))
+			},
+		},
+		{
+			name: "dep not found",
+			builder: func() *Builder {
+				return NewBuilder("ppl").Add(NewStep("main").Command("echo").After("missing"))
+			},
+		},
+		{
+			name: "cyclic deps",
+			builder: func() *Builder {
+				return NewBuilder("ppl").Add(
+					NewStep("step1").Command("echo").After("step2"),
+					NewStep("step2").Command("echo").After("step1"))
+			},
+		},
+		{
+			name: "reference to missing parameter",
+			builder: func() *Builder {
+				return NewBuilder("ppl").Add(
+					NewStep("step1").Command("echo").Param("p1", 1),
+					NewStep("step2").Command("echo").Param("p2", "{{step1.p3}}").After("step1"))
+			},
+		},
+		{
+			name: "input artifact not from upstream",
+			builder: func() *Builder {
+				return NewBuilder("ppl").Add(NewStep("main").Command("echo").InputArtifact("data", "./data"))
+			},
+		},
+		{
+			name: "invalid max expired time",
+			builder: func() *Builder {
+				return NewBuilder("ppl").Add(NewStep("main").Command("echo").
+					Cache(schema.Cache{Enable: true, MaxExpiredTime: "one hour"}))
+			},
+		},
+		{
+			name: "invalid failure strategy",
+			builder: func() *Builder {
+				return NewBuilder("ppl").FailureStrategy("retry").Add(NewStep("main").Command("echo"))
+			},
+		},
+		{
+			name: "reference to missing component",
+			builder: func() *Builder {
+				return NewBuilder("ppl").Add(NewStep("main").Reference("missing"))
+			},
+		},
+		{
+			name: "empty dag",
+			builder: func() *Builder {
+				return NewBuilder("ppl").Add(NewDag("main"))
+			},
+		},
+		{
+			name: "no entry points",
+			builder: func() *Builder {
+				return NewBuilder("ppl")
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.builder().Build()
+			t.Logf("build pipeline err: %v", err)
+			assert.Error(t, err)
+		})
+	}
+}
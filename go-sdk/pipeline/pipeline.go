@@ -17,11 +17,9 @@ limitations under the License.
 package pipeline
 
 import (
-	"fmt"
 	"io"
 	"os"
 
-	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
 )
 
@@ -63,13 +61,3 @@ func NewPipelineFromYamlBytes(content []byte) (pipeline *Pipeline, err error) {
 
 	return
 }
-
-func validatePipeline(pipeline *Pipeline) (err error) {
-	// TODO: 进行更为详细的校验
-	name := pipeline.Name
-	if name != "" && !schema.CheckReg(name, common.RegPatternPipelineName) {
-		err = fmt.Errorf("validate pipeline name[%s] with pattern[%s] failed", pipeline.Name, common.RegPatternPipelineName)
-	}
-
-	return
-}
@@ -0,0 +1,279 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipeline
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	pplcommon "github.com/PaddlePaddle/PaddleFlow/pkg/pipeline/common"
+)
+
+// validatePipeline checks the pipeline as the server does before creating run, except the checks of fs,
+// which depend on the permission of user.
+func validatePipeline(pipeline *Pipeline) (err error) {
+	name := pipeline.Name
+	if name != "" && !schema.CheckReg(name, common.RegPatternPipelineName) {
+		return fmt.Errorf("validate pipeline name[%s] with pattern[%s] failed", pipeline.Name, common.RegPatternPipelineName)
+	}
+
+	postProcess := map[string]schema.Component{}
+	for name, step := range pipeline.PostProcess {
+		postProcess[name] = step
+	}
+	for _, components := range []map[string]schema.Component{
+		pipeline.EntryPoints.EntryPoints, pipeline.Components, postProcess} {
+		if err = checkComponentNames(components); err != nil {
+			return err
+		}
+		if err = checkComponentDeps(components); err != nil {
+			return err
+		}
+	}
+	if err = checkComponentTemplates(pipeline); err != nil {
+		return err
+	}
+	if err = checkPostProcess(pipeline); err != nil {
+		return err
+	}
+	if err = checkCache(pipeline); err != nil {
+		return err
+	}
+	switch pipeline.FailureOptions.Strategy {
+	case "", schema.FailureStrategyFailFast, schema.FailureStrategyContinue:
+	default:
+		return fmt.Errorf("failure strategy should be [fail_fast] or [continue], setted by [%s]",
+			pipeline.FailureOptions.Strategy)
+	}
+	return checkComponentParams(pipeline)
+}
+
+// checkComponentNames checks the names of components and their sub components recursively
+func checkComponentNames(components map[string]schema.Component) error {
+	variableChecker := pplcommon.VariableChecker{}
+	for name, component := range components {
+		if err := variableChecker.CheckCompName(name); err != nil {
+			return err
+		}
+		if dag, ok := component.(*schema.WorkflowSourceDag); ok {
+			if err := checkComponentNames(dag.EntryPoints); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkComponentDeps checks that deps of components are in the same dag and are acyclic
+func checkComponentDeps(components map[string]schema.Component) error {
+	unsorted := map[string][]string{}
+	for name, component := range components {
+		for _, dep := range component.GetDeps() {
+			if _, ok := components[dep]; !ok {
+				return fmt.Errorf("component [%s] has an wrong dep [%s]", name, dep)
+			}
+		}
+		unsorted[name] = component.GetDeps()
+		if dag, ok := component.(*schema.WorkflowSourceDag); ok {
+			if err := checkComponentDeps(dag.EntryPoints); err != nil {
+				return err
+			}
+		}
+	}
+	// remove components whose deps are all removed, and the rest components are in cycle
+	for len(unsorted) != 0 {
+		acyclic := false
+		for name, deps := range unsorted {
+			depExist := false
+			for _, dep := range deps {
+				if _, ok := unsorted[dep]; ok {
+					depExist = true
+					break
+				}
+			}
+			if !depExist {
+				acyclic = true
+				delete(unsorted, name)
+			}
+		}
+		if !acyclic {
+			return fmt.Errorf("workflow is not acyclic")
+		}
+	}
+	return nil
+}
+
+// checkComponentTemplates checks that components have no deps, and the references are not recursive
+func checkComponentTemplates(pipeline *Pipeline) error {
+	for name, comp := range pipeline.Components {
+		if len(comp.GetDeps()) > 0 {
+			return fmt.Errorf("components can not have deps")
+		}
+		visited := map[string]bool{name: true}
+		if err := checkCyclicRef(pipeline, comp, visited); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkCyclicRef(pipeline *Pipeline, component schema.Component, visited map[string]bool) error {
+	switch comp := component.(type) {
+	case *schema.WorkflowSourceStep:
+		refName := comp.Reference.Component
+		if refName == "" {
+			return nil
+		}
+		refComp, ok := pipeline.Components[refName]
+		if !ok {
+			return fmt.Errorf("no component named %s", refName)
+		}
+		if visited[refName] {
+			return fmt.Errorf("components reference is not acyclic")
+		}
+		visited[refName] = true
+		defer delete(visited, refName)
+		return checkCyclicRef(pipeline, refComp, visited)
+	case *schema.WorkflowSourceDag:
+		for _, sub := range comp.EntryPoints {
+			if err := checkCyclicRef(pipeline, sub, visited); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("component not dag or step")
+	}
+}
+
+func checkPostProcess(pipeline *Pipeline) error {
+	if len(pipeline.PostProcess) > 1 {
+		return fmt.Errorf("post_process can only has 1 step at most")
+	}
+	for name := range pipeline.PostProcess {
+		if _, ok := pipeline.EntryPoints.EntryPoints[name]; ok {
+			return fmt.Errorf("a step in post_process has name [%s], which is same to name of a step in entry_points", name)
+		}
+	}
+	return nil
+}
+
+func checkCache(pipeline *Pipeline) error {
+	if err := checkMaxExpiredTime(pipeline.Cache.MaxExpiredTime); err != nil {
+		return fmt.Errorf("MaxExpiredTime[%s] of cache not correct", pipeline.Cache.MaxExpiredTime)
+	}
+	return checkStepCache(pipeline.EntryPoints.EntryPoints)
+}
+
+func checkStepCache(components map[string]schema.Component) error {
+	for name, component := range components {
+		switch comp := component.(type) {
+		case *schema.WorkflowSourceDag:
+			if err := checkStepCache(comp.EntryPoints); err != nil {
+				return err
+			}
+		case *schema.WorkflowSourceStep:
+			if comp.Reference.Component != "" {
+				continue
+			}
+			if err := checkMaxExpiredTime(comp.Cache.MaxExpiredTime); err != nil {
+				return fmt.Errorf("MaxExpiredTime[%s] of cache in step[%s] not correct", comp.Cache.MaxExpiredTime, name)
+			}
+		}
+	}
+	return nil
+}
+
+func checkMaxExpiredTime(maxExpiredTime string) error {
+	if maxExpiredTime == "" {
+		return nil
+	}
+	_, err := strconv.Atoi(maxExpiredTime)
+	return err
+}
+
+// checkComponentParams checks parameters, artifacts, env and command of components with ComponentParamChecker,
+// and the checker runs on copies of components, for it replaces dict parameters with their default values.
+func checkComponentParams(pipeline *Pipeline) error {
+	sysParams := map[string]string{}
+	for _, name := range pplcommon.SysParamNameList {
+		sysParams[name] = ""
+	}
+
+	runComponents := map[string]schema.Component{}
+	collectComponents(copyComponents(pipeline.EntryPoints.EntryPoints), "", runComponents)
+	for name, step := range pipeline.PostProcess {
+		runComponents[name] = step.DeepCopy()
+	}
+	runChecker := pplcommon.ComponentParamChecker{
+		Components:    runComponents,
+		SysParams:     sysParams,
+		UseFs:         true,
+		CompTempletes: copyComponents(pipeline.Components),
+	}
+	for name := range runComponents {
+		disabled, err := pipeline.IsDisabled(name)
+		if err != nil {
+			return err
+		}
+		if disabled {
+			continue
+		}
+		if err = runChecker.Check(name, false); err != nil {
+			return err
+		}
+	}
+
+	tmplComponents := map[string]schema.Component{}
+	collectComponents(copyComponents(pipeline.Components), "", tmplComponents)
+	tmplChecker := pplcommon.ComponentParamChecker{
+		Components:    tmplComponents,
+		SysParams:     sysParams,
+		UseFs:         true,
+		CompTempletes: copyComponents(pipeline.Components),
+	}
+	for name := range tmplComponents {
+		if err := tmplChecker.Check(name, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyComponents(components map[string]schema.Component) map[string]schema.Component {
+	copied := make(map[string]schema.Component, len(components))
+	for name, component := range components {
+		copied[name] = component.DeepCopy()
+	}
+	return copied
+}
+
+// collectComponents collects components with absolute name, such as dag.step
+func collectComponents(components map[string]schema.Component, prefix string, all map[string]schema.Component) {
+	for name, component := range components {
+		absoluteName := name
+		if prefix != "" {
+			absoluteName = prefix + "." + name
+		}
+		all[absoluteName] = component
+		if dag, ok := component.(*schema.WorkflowSourceDag); ok {
+			collectComponents(dag.EntryPoints, absoluteName, all)
+		}
+	}
+}
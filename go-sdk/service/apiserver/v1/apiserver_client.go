@@ -24,6 +24,11 @@ type APIV1Interface interface {
 	UserGetter
 	FileSystemGetter
 	FileSystemCacheGetter
+	FileSystemFileGetter
+	FileSystemSnapshotGetter
+	FileSystemQuotaGetter
+	FileSystemSyncGetter
+	FileSystemAuditGetter
 	ClusterGetter
 	QueueGetter
 	FlavourGetter
@@ -31,6 +36,12 @@ type APIV1Interface interface {
 	RunGetter
 	PipelineGetter
 	ScheduleGetter
+	JobTemplateGetter
+	GrantGetter
+	LinkGetter
+	LogGetter
+	StatisticsGetter
+	VersionGetter
 }
 
 // APIV1Client is used to interact with features provided by the group.
@@ -50,6 +61,26 @@ func (c *APIV1Client) FileSystemCache() FileSystemCacheInterface {
 	return newFileSystemCache(c)
 }
 
+func (c *APIV1Client) FileSystemFile() FileSystemFileInterface {
+	return newFileSystemFile(c)
+}
+
+func (c *APIV1Client) FileSystemSnapshot() FileSystemSnapshotInterface {
+	return newFileSystemSnapshot(c)
+}
+
+func (c *APIV1Client) FileSystemQuota() FileSystemQuotaInterface {
+	return newFileSystemQuota(c)
+}
+
+func (c *APIV1Client) FileSystemSync() FileSystemSyncInterface {
+	return newFileSystemSync(c)
+}
+
+func (c *APIV1Client) FileSystemAudit() FileSystemAuditInterface {
+	return newFileSystemAudit(c)
+}
+
 func (c *APIV1Client) Cluster() ClusterInterface {
 	return newCluster(c)
 }
@@ -78,6 +109,30 @@ func (c *APIV1Client) Schedule() ScheduleInterface {
 	return newSchedule(c)
 }
 
+func (c *APIV1Client) JobTemplate() JobTemplateInterface {
+	return newJobTemplate(c)
+}
+
+func (c *APIV1Client) Grant() GrantInterface {
+	return newGrant(c)
+}
+
+func (c *APIV1Client) Link() LinkInterface {
+	return newLink(c)
+}
+
+func (c *APIV1Client) Log() LogInterface {
+	return newLog(c)
+}
+
+func (c *APIV1Client) Statistics() StatisticsInterface {
+	return newStatistics(c)
+}
+
+func (c *APIV1Client) Version() VersionInterface {
+	return newVersion(c)
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *APIV1Client) RESTClient() *core.PaddleFlowClient {
@@ -0,0 +1,295 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/http/core"
+)
+
+const mockToken = "mock-token"
+
+// newMockClient returns a client which sends requests to the handler
+func newMockClient(t *testing.T, handler http.HandlerFunc) *APIV1Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	serverURL, err := url.Parse(server.URL)
+	assert.NoError(t, err)
+	port, err := strconv.Atoi(serverURL.Port())
+	assert.NoError(t, err)
+	client, err := NewForConfig(&core.PaddleFlowClientConfiguration{
+		Host:                       serverURL.Hostname(),
+		Port:                       port,
+		ConnectionTimeoutInSeconds: 1,
+	})
+	assert.NoError(t, err)
+	return client
+}
+
+func renderJSON(w http.ResponseWriter, result interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+func TestGrant(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, GrantApi, r.URL.Path)
+		assert.Equal(t, mockToken, r.Header.Get(common.HeaderKeyAuthorization))
+		switch r.Method {
+		case http.MethodDelete:
+			assert.Equal(t, "user1", r.URL.Query().Get(KeyUsername))
+			assert.Equal(t, "queue", r.URL.Query().Get(KeyResourceType))
+			assert.Equal(t, "queue1", r.URL.Query().Get(KeyResourceID))
+		case http.MethodGet:
+			assert.Equal(t, "10", r.URL.Query().Get(KeyMaxKeys))
+			renderJSON(w, map[string]interface{}{
+				"grantList": []map[string]string{{"grantID": "grant-1", "userName": "user1"}},
+			})
+		}
+	})
+
+	err := client.Grant().Delete(context.TODO(), &DeleteGrantRequest{
+		UserName: "user1", ResourceType: "queue", ResourceID: "queue1"}, mockToken)
+	assert.NoError(t, err)
+	result, err := client.Grant().List(context.TODO(), &ListGrantRequest{MaxKeys: 10}, mockToken)
+	assert.NoError(t, err)
+	assert.Equal(t, "grant-1", result.GrantList[0].ID)
+}
+
+func TestStatistics(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, StatisticsApi+"/queueDetail/queue1", r.URL.Path)
+		assert.Equal(t, "100", r.URL.Query().Get(KeyStart))
+		assert.Equal(t, "", r.URL.Query().Get(KeyEnd))
+		renderJSON(w, map[string]interface{}{
+			"result": []map[string]interface{}{{
+				"taskName": "queue1",
+				"taskInfo": []map[string]interface{}{{"metric": "cpu_usage_rate", "values": [][2]float64{{100, 0.5}}}},
+			}},
+		})
+	})
+
+	result, err := client.Statistics().GetQueueDetail(context.TODO(), "queue1",
+		&StatisticsRequest{Start: 100}, mockToken)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.5, result.Result[0].TaskInfo[0].Values[0][1])
+}
+
+func TestClusterObject(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, ClusterApi+"/cluster1/k8s/object", r.URL.Path)
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "delete", r.URL.Query().Get(KeyAction))
+		request := KubernetesObjectRequest{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, "Pod", request.Kind)
+	})
+
+	err := client.Cluster().DeleteObject(context.TODO(), "cluster1", &KubernetesObjectRequest{
+		Name: "pod1", Namespace: "default", Kind: "Pod", APIVersion: "v1"}, mockToken)
+	assert.NoError(t, err)
+}
+
+func TestFileSystemFile(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, mockToken, r.Header.Get(common.HeaderKeyAuthorization))
+		switch r.URL.Path {
+		case filesApi("fs1") + "/upload":
+			assert.Equal(t, "data", r.URL.Query().Get(KeyPath))
+			file, header, err := r.FormFile(FormFieldFiles)
+			assert.NoError(t, err)
+			content, _ := ioutil.ReadAll(file)
+			assert.Equal(t, "hello", string(content))
+			w.WriteHeader(http.StatusCreated)
+			renderJSON(w, UploadFilesResponse{FileList: []*FileInfo{{Name: header.Filename, Size: int64(len(content))}}})
+		case filesApi("fs1") + "/download":
+			assert.Equal(t, "bytes=1-3", r.Header.Get(HeaderRange))
+			_, _ = w.Write([]byte("ell"))
+		case filesApi("fs1"):
+			if r.Method == http.MethodDelete {
+				assert.Equal(t, "true", r.URL.Query().Get(KeyRecursive))
+				return
+			}
+			assert.Equal(t, "1", r.URL.Query().Get(KeyMaxKeys))
+			renderJSON(w, ListFilesResponse{Truncated: true, NextMarker: "a.txt", FileList: []*FileInfo{{Name: "a.txt"}}})
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	fsFile := client.FileSystemFile()
+	uploaded, err := fsFile.Upload(context.TODO(), &UploadFileRequest{
+		FsName: "fs1", Dir: "data", FileName: "a.txt", Content: strings.NewReader("hello")}, mockToken)
+	assert.NoError(t, err)
+	assert.Equal(t, "a.txt", uploaded.FileList[0].Name)
+	assert.Equal(t, int64(5), uploaded.FileList[0].Size)
+
+	reader, err := fsFile.Download(context.TODO(), &DownloadFileRequest{
+		FsName: "fs1", Path: "data/a.txt", Offset: 1, Length: 3}, mockToken)
+	assert.NoError(t, err)
+	content, _ := ioutil.ReadAll(reader)
+	reader.Close()
+	assert.Equal(t, "ell", string(content))
+
+	list, err := fsFile.List(context.TODO(), &ListFilesRequest{FsName: "fs1", MaxKeys: 1}, mockToken)
+	assert.NoError(t, err)
+	assert.Equal(t, "a.txt", list.NextMarker)
+
+	err = fsFile.Delete(context.TODO(), &DeleteFileRequest{FsName: "fs1", Path: "data", Recursive: true}, mockToken)
+	assert.NoError(t, err)
+}
+
+func TestFileSystemSnapshot(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, mockToken, r.Header.Get(common.HeaderKeyAuthorization))
+		switch r.URL.Path {
+		case snapshotsApi("fs1"):
+			if r.Method == http.MethodPost {
+				request := CreateFsSnapshotRequest{}
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+				w.WriteHeader(http.StatusCreated)
+				renderJSON(w, FsSnapshotResponse{Name: request.Name, FsName: "fs1", Status: "creating"})
+				return
+			}
+			renderJSON(w, ListFsSnapshotResponse{SnapshotList: []*FsSnapshotResponse{{Name: "v1"}}})
+		case snapshotsApi("fs1") + "/v1":
+			assert.Equal(t, "user1", r.URL.Query().Get(KeyUsername))
+			if r.Method == http.MethodDelete {
+				return
+			}
+			renderJSON(w, FsSnapshotResponse{Name: "v1", Status: "ready"})
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	snapshot := client.FileSystemSnapshot()
+	created, err := snapshot.Create(context.TODO(), &CreateFsSnapshotRequest{FsName: "fs1", Name: "v1"}, mockToken)
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", created.Name)
+	assert.Equal(t, "creating", created.Status)
+
+	got, err := snapshot.Get(context.TODO(), "fs1", "v1", "user1", mockToken)
+	assert.NoError(t, err)
+	assert.Equal(t, "ready", got.Status)
+
+	list, err := snapshot.List(context.TODO(), "fs1", "", mockToken)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(list.SnapshotList))
+
+	assert.NoError(t, snapshot.Delete(context.TODO(), "fs1", "v1", "user1", mockToken))
+}
+
+func TestFileSystemQuota(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, quotaApi("fs1"), r.URL.Path)
+		switch r.Method {
+		case http.MethodPut:
+			request := SetFsQuotaRequest{}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+			renderJSON(w, FsQuotaResponse{Path: "/" + request.Path, MaxBytes: request.MaxBytes})
+		case http.MethodGet:
+			assert.Equal(t, "true", r.URL.Query().Get(KeyUsage))
+			used := int64(10)
+			renderJSON(w, ListFsQuotaResponse{QuotaList: []*FsQuotaResponse{{Path: "/data", UsedBytes: &used}}})
+		case http.MethodDelete:
+			assert.Equal(t, "data", r.URL.Query().Get(KeyPath))
+		}
+	})
+
+	quota := client.FileSystemQuota()
+	set, err := quota.Set(context.TODO(), &SetFsQuotaRequest{FsName: "fs1", Path: "data", MaxBytes: 100}, mockToken)
+	assert.NoError(t, err)
+	assert.Equal(t, "/data", set.Path)
+	assert.Equal(t, int64(100), set.MaxBytes)
+
+	list, err := quota.List(context.TODO(), &ListFsQuotaRequest{FsName: "fs1", WithUsage: true}, mockToken)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), *list.QuotaList[0].UsedBytes)
+
+	assert.NoError(t, quota.Delete(context.TODO(), &DeleteFsQuotaRequest{FsName: "fs1", Path: "data"}, mockToken))
+}
+
+func TestFileSystemSync(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == FsSyncApi:
+			request := CreateFsSyncTaskRequest{}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+			renderJSON(w, FsSyncTaskResponse{ID: "fssync-1", SrcFsName: request.SrcFsName, DryRun: request.DryRun})
+		case r.Method == http.MethodGet && r.URL.Path == FsSyncApi:
+			assert.Equal(t, "running", r.URL.Query().Get(KeyStatus))
+			renderJSON(w, ListFsSyncTaskResponse{TaskList: []*FsSyncTaskResponse{{ID: "fssync-1"}}})
+		case r.Method == http.MethodGet:
+			assert.Equal(t, FsSyncApi+"/fssync-1", r.URL.Path)
+			renderJSON(w, FsSyncTaskResponse{ID: "fssync-1", Diff: []string{"+ a.txt"}})
+		case r.Method == http.MethodPut:
+			assert.Equal(t, FsSyncActionStop, r.URL.Query().Get(KeyAction))
+			renderJSON(w, FsSyncTaskResponse{ID: "fssync-1", Status: "stopped"})
+		case r.Method == http.MethodDelete:
+			assert.Equal(t, FsSyncApi+"/fssync-1", r.URL.Path)
+		}
+	})
+
+	sync := client.FileSystemSync()
+	task, err := sync.Create(context.TODO(), &CreateFsSyncTaskRequest{SrcFsName: "hdfs", DstFsName: "s3",
+		DryRun: true}, mockToken)
+	assert.NoError(t, err)
+	assert.Equal(t, "hdfs", task.SrcFsName)
+	assert.True(t, task.DryRun)
+
+	list, err := sync.List(context.TODO(), "running", mockToken)
+	assert.NoError(t, err)
+	assert.Equal(t, "fssync-1", list.TaskList[0].ID)
+
+	task, err = sync.Get(context.TODO(), "fssync-1", mockToken)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"+ a.txt"}, task.Diff)
+
+	task, err = sync.Update(context.TODO(), "fssync-1", FsSyncActionStop, mockToken)
+	assert.NoError(t, err)
+	assert.Equal(t, "stopped", task.Status)
+
+	assert.NoError(t, sync.Delete(context.TODO(), "fssync-1", mockToken))
+}
+
+func TestFileSystemAudit(t *testing.T) {
+	client := newMockClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, FsAuditApi, r.URL.Path)
+		assert.Equal(t, "fs1", r.URL.Query().Get(KeyFsName))
+		assert.Equal(t, "createFs,stsToken", r.URL.Query().Get(KeyOperation))
+		assert.Equal(t, "10", r.URL.Query().Get(KeyMaxKeys))
+		renderJSON(w, ListFsAuditLogResponse{MaxKeys: 10, AuditLogList: []*FsAuditLogResponse{
+			{FsID: "fs-root-fs1", Operation: "createFs"}}})
+	})
+
+	list, err := client.FileSystemAudit().List(context.TODO(), &ListFsAuditLogRequest{FsName: "fs1",
+		Operation: "createFs,stsToken", MaxKeys: 10}, mockToken)
+	assert.NoError(t, err)
+	assert.Equal(t, "createFs", list.AuditLogList[0].Operation)
+}
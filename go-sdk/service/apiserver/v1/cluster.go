@@ -25,6 +25,7 @@ import (
 	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/http/core"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/http/util/http"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
 )
 
 const (
@@ -117,6 +118,43 @@ type UpdateClusterResponse struct {
 	ClusterInfo
 }
 
+type GetClusterHealthResponse struct {
+	ClusterName string                 `json:"clusterName"`
+	Status      string                 `json:"status"`
+	Healthy     bool                   `json:"healthy"`
+	History     []schema.ClusterHealth `json:"history"`
+}
+
+type ClusterQuotaResponse struct {
+	NodeQuotaInfoList []schema.NodeQuotaInfo `json:"nodeList"`
+	Summary           schema.QuotaSummary    `json:"summary"`
+	ErrMessage        string                 `json:"errMsg"`
+}
+
+type ListClusterResourcesRequest struct {
+	ClusterNameList []string `json:"clusterNames"` // list resources by cluster
+	QueueName       string   `json:"queueName"`    // list resources by queue
+	Labels          string   `json:"labels"`
+	PageNo          int      `json:"pageNo"`
+	PageSize        int      `json:"pageSize"`
+}
+
+type NodeResourcesResponse struct {
+	Allocatable map[string]map[string]interface{} `json:"allocatable"`
+	Capacity    map[string]map[string]string      `json:"capacity"`
+	Labels      map[string]map[string]string      `json:"labels"`
+	ClusterName string                            `json:"clusterName,omitempty"`
+	QueueName   string                            `json:"queueName,omitempty"`
+}
+
+// KubernetesObjectRequest identifies the kubernetes object in cluster
+type KubernetesObjectRequest struct {
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace"`
+	Kind       string `json:"kind"`
+	APIVersion string `json:"apiVersion"`
+}
+
 func (c *cluster) Create(ctx context.Context, request *CreateClusterRequest,
 	token string) (result *CreateClusterResponse, err error) {
 	result = &CreateClusterResponse{}
@@ -186,6 +224,118 @@ func (c *cluster) Delete(ctx context.Context, clusterName, token string) (err er
 	return
 }
 
+func (c *cluster) Health(ctx context.Context, clusterName,
+	token string) (result *GetClusterHealthResponse, err error) {
+	result = &GetClusterHealthResponse{}
+	err = core.NewRequestBuilder(c.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(ClusterApi + "/" + clusterName + "/health").
+		WithMethod(http.GET).
+		WithResult(result).
+		Do()
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// ListQuota returns the quota of clusters, and all clusters are listed if clusterNames is empty
+func (c *cluster) ListQuota(ctx context.Context, clusterNames []string,
+	token string) (result map[string]ClusterQuotaResponse, err error) {
+	result = map[string]ClusterQuotaResponse{}
+	err = core.NewRequestBuilder(c.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(ClusterApi+"/resource").
+		WithMethod(http.GET).
+		WithQueryParamFilter(KeyClusterNameList, strings.Join(clusterNames, ",")).
+		WithResult(&result).
+		Do()
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// ListResources returns the node resources of clusters, which can be filtered by queue and labels
+func (c *cluster) ListResources(ctx context.Context, request *ListClusterResourcesRequest,
+	token string) (result map[string]*NodeResourcesResponse, err error) {
+	result = map[string]*NodeResourcesResponse{}
+	err = core.NewRequestBuilder(c.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(ClusterApi + "/resource").
+		WithMethod(http.POST).
+		WithBody(request).
+		WithResult(&result).
+		Do()
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (c *cluster) CreateObject(ctx context.Context, clusterName string, object map[string]interface{},
+	token string) (result map[string]interface{}, err error) {
+	result = map[string]interface{}{}
+	err = core.NewRequestBuilder(c.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(ClusterApi + "/" + clusterName + "/k8s/object").
+		WithMethod(http.POST).
+		WithBody(object).
+		WithResult(&result).
+		Do()
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (c *cluster) GetObject(ctx context.Context, clusterName string, request *KubernetesObjectRequest,
+	token string) (result map[string]interface{}, err error) {
+	result = map[string]interface{}{}
+	err = core.NewRequestBuilder(c.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(ClusterApi+"/"+clusterName+"/k8s/object").
+		WithMethod(http.GET).
+		WithQueryParam("name", request.Name).
+		WithQueryParam("namespace", request.Namespace).
+		WithQueryParam("kind", request.Kind).
+		WithQueryParam("apiVersion", request.APIVersion).
+		WithResult(&result).
+		Do()
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (c *cluster) UpdateObject(ctx context.Context, clusterName string, object map[string]interface{},
+	token string) (result map[string]interface{}, err error) {
+	result = map[string]interface{}{}
+	err = core.NewRequestBuilder(c.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(ClusterApi + "/" + clusterName + "/k8s/object").
+		WithMethod(http.PUT).
+		WithBody(object).
+		WithResult(&result).
+		Do()
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (c *cluster) DeleteObject(ctx context.Context, clusterName string, request *KubernetesObjectRequest,
+	token string) (err error) {
+	err = core.NewRequestBuilder(c.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(ClusterApi+"/"+clusterName+"/k8s/object").
+		WithMethod(http.POST).
+		WithQueryParam(KeyAction, "delete").
+		WithBody(request).
+		Do()
+	return
+}
+
 type ClusterGetter interface {
 	Cluster() ClusterInterface
 }
@@ -196,6 +346,13 @@ type ClusterInterface interface {
 	List(ctx context.Context, request *ListClusterRequest, token string) (*ListClusterResponse, error)
 	Update(ctx context.Context, clusterName string, request *UpdateClusterRequest, token string) (*UpdateClusterResponse, error)
 	Delete(ctx context.Context, clusterName string, token string) error
+	Health(ctx context.Context, clusterName string, token string) (*GetClusterHealthResponse, error)
+	ListQuota(ctx context.Context, clusterNames []string, token string) (map[string]ClusterQuotaResponse, error)
+	ListResources(ctx context.Context, request *ListClusterResourcesRequest, token string) (map[string]*NodeResourcesResponse, error)
+	CreateObject(ctx context.Context, clusterName string, object map[string]interface{}, token string) (map[string]interface{}, error)
+	GetObject(ctx context.Context, clusterName string, request *KubernetesObjectRequest, token string) (map[string]interface{}, error)
+	UpdateObject(ctx context.Context, clusterName string, object map[string]interface{}, token string) (map[string]interface{}, error)
+	DeleteObject(ctx context.Context, clusterName string, request *KubernetesObjectRequest, token string) error
 }
 
 // newCluster returns a cluster.
@@ -18,6 +18,7 @@ package v1
 
 import (
 	"context"
+	"strconv"
 
 	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/http/core"
@@ -29,6 +30,7 @@ const (
 	FsCacheApi  = Prefix + "/fsCache"
 	StsApi      = Prefix + "/fsSts"
 	KeyUsername = "username"
+	KeyFsName   = "fsName"
 	StsDuration = "duration"
 )
 
@@ -63,6 +65,20 @@ type GetFileSystemResponse struct {
 	Properties    map[string]string `json:"properties"`
 }
 
+type ListFileSystemRequest struct {
+	Marker   string `json:"marker"`
+	MaxKeys  int    `json:"maxKeys"`
+	Username string `json:"username"`
+	FsName   string `json:"fsName"`
+}
+
+type ListFileSystemResponse struct {
+	Marker     string                   `json:"marker"`
+	Truncated  bool                     `json:"truncated"`
+	NextMarker string                   `json:"nextMarker"`
+	FsList     []*GetFileSystemResponse `json:"fsList"`
+}
+
 type DeleteFileSystemRequest struct {
 	FsName   string `json:"fsName"`
 	Username string `json:"username"`
@@ -116,6 +132,25 @@ func (f *fileSystem) Get(ctx context.Context, request *GetFileSystemRequest,
 	return
 }
 
+func (f *fileSystem) List(ctx context.Context, request *ListFileSystemRequest,
+	token string) (result *ListFileSystemResponse, err error) {
+	result = &ListFileSystemResponse{}
+	err = core.NewRequestBuilder(f.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(FsApi).
+		WithQueryParamFilter(KeyMarker, request.Marker).
+		WithQueryParamFilter(KeyMaxKeys, strconv.Itoa(request.MaxKeys)).
+		WithQueryParamFilter(KeyUsername, request.Username).
+		WithQueryParamFilter(KeyFsName, request.FsName).
+		WithMethod(http.GET).
+		WithResult(result).
+		Do()
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
 func (f *fileSystem) Delete(ctx context.Context, request *DeleteFileSystemRequest, token string) (err error) {
 	err = core.NewRequestBuilder(f.client).
 		WithHeader(common.HeaderKeyAuthorization, token).
@@ -149,6 +184,7 @@ type FileSystemGetter interface {
 type FileSystemInterface interface {
 	Create(ctx context.Context, request *CreateFileSystemRequest, token string) (*CreateFileSystemResponse, error)
 	Get(ctx context.Context, request *GetFileSystemRequest, token string) (*GetFileSystemResponse, error)
+	List(ctx context.Context, request *ListFileSystemRequest, token string) (*ListFileSystemResponse, error)
 	Delete(ctx context.Context, request *DeleteFileSystemRequest, token string) error
 	Sts(ctx context.Context, request *GetStsRequest, token string) (*GetStsResponse, error)
 }
@@ -0,0 +1,110 @@
+/*
+Copyright (c) 2021 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/http/core"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/http/util/http"
+)
+
+const (
+	FsAuditApi   = Prefix + "/fsAudit"
+	KeyOperation = "operation"
+	KeyEndTime   = "endTime"
+)
+
+type fileSystemAudit struct {
+	client *core.PaddleFlowClient
+}
+
+type ListFsAuditLogRequest struct {
+	FsName   string `json:"fsName"`
+	Username string `json:"username"`
+	JobID    string `json:"jobID"`
+	// Operation is operations separated by comma, such as createFs,stsToken
+	Operation string `json:"operation"`
+	// StartTime and EndTime are in format 2006-01-02 15:04:05
+	StartTime string `json:"startTime"`
+	EndTime   string `json:"endTime"`
+	Marker    string `json:"marker"`
+	MaxKeys   int    `json:"maxKeys"`
+}
+
+type FsAuditLogResponse struct {
+	FsID       string `json:"fsID"`
+	UserName   string `json:"userName"`
+	JobID      string `json:"jobID"`
+	Operation  string `json:"operation"`
+	Path       string `json:"path"`
+	Detail     string `json:"detail"`
+	Source     string `json:"source"`
+	Host       string `json:"host"`
+	CreateTime string `json:"createTime"`
+	// OccurTime is reported by fuse client, and empty for server operations
+	OccurTime string `json:"occurTime"`
+}
+
+type ListFsAuditLogResponse struct {
+	MaxKeys      int                   `json:"maxKeys"`
+	IsTruncated  bool                  `json:"isTruncated"`
+	NextMarker   string                `json:"nextMarker"`
+	AuditLogList []*FsAuditLogResponse `json:"auditLogList"`
+}
+
+func (a *fileSystemAudit) List(ctx context.Context, request *ListFsAuditLogRequest,
+	token string) (result *ListFsAuditLogResponse, err error) {
+	result = &ListFsAuditLogResponse{}
+	builder := core.NewRequestBuilder(a.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(FsAuditApi).
+		WithQueryParamFilter(KeyFsName, request.FsName).
+		WithQueryParamFilter(KeyUsername, request.Username).
+		WithQueryParamFilter(KeyJobID, request.JobID).
+		WithQueryParamFilter(KeyOperation, request.Operation).
+		WithQueryParamFilter(KeyStartTime, request.StartTime).
+		WithQueryParamFilter(KeyEndTime, request.EndTime).
+		WithQueryParamFilter(KeyMarker, request.Marker)
+	if request.MaxKeys > 0 {
+		builder = builder.WithQueryParam(KeyMaxKeys, strconv.Itoa(request.MaxKeys))
+	}
+	err = builder.WithMethod(http.GET).
+		WithResult(result).
+		Do()
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+type FileSystemAuditGetter interface {
+	FileSystemAudit() FileSystemAuditInterface
+}
+
+type FileSystemAuditInterface interface {
+	List(ctx context.Context, request *ListFsAuditLogRequest, token string) (*ListFsAuditLogResponse, error)
+}
+
+// newFileSystemAudit returns a fileSystemAudit.
+func newFileSystemAudit(c *APIV1Client) *fileSystemAudit {
+	return &fileSystemAudit{
+		client: c.RESTClient(),
+	}
+}
@@ -0,0 +1,266 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"strconv"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/http/core"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/http/util/http"
+)
+
+const (
+	FsFilesSuffix  = "/files"
+	KeyPath        = "path"
+	KeyRecursive   = "recursive"
+	KeyOverwrite   = "overwrite"
+	HeaderRange    = "Range"
+	HeaderCT       = "Content-Type"
+	FormFieldFiles = "file"
+)
+
+type fileSystemFile struct {
+	client *core.PaddleFlowClient
+}
+
+type FileInfo struct {
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+	IsDir   bool   `json:"isDir"`
+	Mode    string `json:"mode"`
+	ModTime string `json:"modTime"`
+}
+
+type StatFileRequest struct {
+	FsName   string `json:"fsName"`
+	Username string `json:"username"`
+	Path     string `json:"path"`
+}
+
+type ListFilesRequest struct {
+	FsName   string `json:"fsName"`
+	Username string `json:"username"`
+	Path     string `json:"path"`
+	Marker   string `json:"marker"`
+	MaxKeys  int    `json:"maxKeys"`
+}
+
+type ListFilesResponse struct {
+	Path       string      `json:"path"`
+	Marker     string      `json:"marker"`
+	Truncated  bool        `json:"truncated"`
+	NextMarker string      `json:"nextMarker"`
+	FileList   []*FileInfo `json:"fileList"`
+}
+
+type DownloadFileRequest struct {
+	FsName   string `json:"fsName"`
+	Username string `json:"username"`
+	Path     string `json:"path"`
+	// Offset and Length select a range of the file, Length 0 means to the end of file
+	Offset int64 `json:"offset"`
+	Length int64 `json:"length"`
+}
+
+type UploadFileRequest struct {
+	FsName    string    `json:"fsName"`
+	Username  string    `json:"username"`
+	Dir       string    `json:"dir"`
+	FileName  string    `json:"fileName"`
+	Overwrite bool      `json:"overwrite"`
+	Content   io.Reader `json:"-"`
+}
+
+type UploadFilesResponse struct {
+	FileList []*FileInfo `json:"fileList"`
+}
+
+type CreateDirRequest struct {
+	FsName   string `json:"-"`
+	Username string `json:"-"`
+	Path     string `json:"path"`
+}
+
+type RenameFileRequest struct {
+	FsName   string `json:"-"`
+	Username string `json:"-"`
+	SrcPath  string `json:"srcPath"`
+	DstPath  string `json:"dstPath"`
+}
+
+type DeleteFileRequest struct {
+	FsName    string `json:"fsName"`
+	Username  string `json:"username"`
+	Path      string `json:"path"`
+	Recursive bool   `json:"recursive"`
+}
+
+func filesApi(fsName string) string {
+	return FsApi + "/" + fsName + FsFilesSuffix
+}
+
+func (f *fileSystemFile) Stat(ctx context.Context, request *StatFileRequest,
+	token string) (result *FileInfo, err error) {
+	result = &FileInfo{}
+	err = core.NewRequestBuilder(f.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(filesApi(request.FsName)+"/stat").
+		WithQueryParam(KeyPath, request.Path).
+		WithQueryParamFilter(KeyUsername, request.Username).
+		WithMethod(http.GET).
+		WithResult(result).
+		Do()
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (f *fileSystemFile) List(ctx context.Context, request *ListFilesRequest,
+	token string) (result *ListFilesResponse, err error) {
+	result = &ListFilesResponse{}
+	builder := core.NewRequestBuilder(f.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(filesApi(request.FsName)).
+		WithQueryParamFilter(KeyPath, request.Path).
+		WithQueryParamFilter(KeyMarker, request.Marker).
+		WithQueryParamFilter(KeyUsername, request.Username).
+		WithMethod(http.GET).
+		WithResult(result)
+	if request.MaxKeys > 0 {
+		builder.WithQueryParam(KeyMaxKeys, strconv.Itoa(request.MaxKeys))
+	}
+	if err = builder.Do(); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Download returns the content of file, which must be closed by the caller.
+func (f *fileSystemFile) Download(ctx context.Context, request *DownloadFileRequest,
+	token string) (io.ReadCloser, error) {
+	builder := core.NewRequestBuilder(f.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(filesApi(request.FsName)+"/download").
+		WithQueryParam(KeyPath, request.Path).
+		WithQueryParamFilter(KeyUsername, request.Username).
+		WithMethod(http.GET)
+	if request.Offset > 0 || request.Length > 0 {
+		byteRange := fmt.Sprintf("bytes=%d-", request.Offset)
+		if request.Length > 0 {
+			byteRange += strconv.FormatInt(request.Offset+request.Length-1, 10)
+		}
+		builder.WithHeader(HeaderRange, byteRange)
+	}
+	return builder.DoStream()
+}
+
+// Upload streams content into dir/fileName as a multipart form.
+func (f *fileSystemFile) Upload(ctx context.Context, request *UploadFileRequest,
+	token string) (result *UploadFilesResponse, err error) {
+	reader, writer := io.Pipe()
+	form := multipart.NewWriter(writer)
+	go func() {
+		part, err := form.CreateFormFile(FormFieldFiles, request.FileName)
+		if err == nil {
+			_, err = io.Copy(part, request.Content)
+		}
+		if err == nil {
+			err = form.Close()
+		}
+		writer.CloseWithError(err)
+	}()
+	defer reader.Close()
+
+	result = &UploadFilesResponse{}
+	err = core.NewRequestBuilder(f.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithHeader(HeaderCT, form.FormDataContentType()).
+		WithURL(filesApi(request.FsName)+"/upload").
+		WithQueryParamFilter(KeyPath, request.Dir).
+		WithQueryParamFilter(KeyUsername, request.Username).
+		WithQueryParam(KeyOverwrite, strconv.FormatBool(request.Overwrite)).
+		WithMethod(http.POST).
+		WithRawBody(reader).
+		WithResult(result).
+		Do()
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (f *fileSystemFile) CreateDir(ctx context.Context, request *CreateDirRequest, token string) (err error) {
+	err = core.NewRequestBuilder(f.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(filesApi(request.FsName)+"/dir").
+		WithQueryParamFilter(KeyUsername, request.Username).
+		WithMethod(http.POST).
+		WithBody(request).
+		Do()
+	return
+}
+
+func (f *fileSystemFile) Rename(ctx context.Context, request *RenameFileRequest, token string) (err error) {
+	err = core.NewRequestBuilder(f.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(filesApi(request.FsName)+"/rename").
+		WithQueryParamFilter(KeyUsername, request.Username).
+		WithMethod(http.PUT).
+		WithBody(request).
+		Do()
+	return
+}
+
+func (f *fileSystemFile) Delete(ctx context.Context, request *DeleteFileRequest, token string) (err error) {
+	err = core.NewRequestBuilder(f.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(filesApi(request.FsName)).
+		WithQueryParam(KeyPath, request.Path).
+		WithQueryParamFilter(KeyUsername, request.Username).
+		WithQueryParam(KeyRecursive, strconv.FormatBool(request.Recursive)).
+		WithMethod(http.DELETE).
+		Do()
+	return
+}
+
+type FileSystemFileGetter interface {
+	FileSystemFile() FileSystemFileInterface
+}
+
+type FileSystemFileInterface interface {
+	Stat(ctx context.Context, request *StatFileRequest, token string) (*FileInfo, error)
+	List(ctx context.Context, request *ListFilesRequest, token string) (*ListFilesResponse, error)
+	Download(ctx context.Context, request *DownloadFileRequest, token string) (io.ReadCloser, error)
+	Upload(ctx context.Context, request *UploadFileRequest, token string) (*UploadFilesResponse, error)
+	CreateDir(ctx context.Context, request *CreateDirRequest, token string) error
+	Rename(ctx context.Context, request *RenameFileRequest, token string) error
+	Delete(ctx context.Context, request *DeleteFileRequest, token string) error
+}
+
+// newFileSystemFile returns a fileSystemFile.
+func newFileSystemFile(c *APIV1Client) *fileSystemFile {
+	return &fileSystemFile{
+		client: c.RESTClient(),
+	}
+}
@@ -0,0 +1,136 @@
+/*
+Copyright (c) 2021 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/http/core"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/http/util/http"
+)
+
+const (
+	FsQuotaSuffix = "/quota"
+	KeyUsage      = "usage"
+)
+
+type fileSystemQuota struct {
+	client *core.PaddleFlowClient
+}
+
+type SetFsQuotaRequest struct {
+	FsName    string `json:"-"`
+	Username  string `json:"-"`
+	Path      string `json:"path"`
+	MaxBytes  int64  `json:"maxBytes"`
+	MaxInodes int64  `json:"maxInodes"`
+}
+
+type FsQuotaResponse struct {
+	Path       string `json:"path"`
+	MaxBytes   int64  `json:"maxBytes"`
+	MaxInodes  int64  `json:"maxInodes"`
+	UsedBytes  *int64 `json:"usedBytes,omitempty"`
+	UsedInodes *int64 `json:"usedInodes,omitempty"`
+	CreateTime string `json:"createTime"`
+	UpdateTime string `json:"updateTime"`
+}
+
+type ListFsQuotaRequest struct {
+	FsName   string `json:"fsName"`
+	Username string `json:"username"`
+	// WithUsage counts the used bytes and inodes by walking the directories
+	WithUsage bool `json:"withUsage"`
+}
+
+type ListFsQuotaResponse struct {
+	QuotaList []*FsQuotaResponse `json:"quotaList"`
+}
+
+type DeleteFsQuotaRequest struct {
+	FsName   string `json:"fsName"`
+	Username string `json:"username"`
+	Path     string `json:"path"`
+}
+
+func quotaApi(fsName string) string {
+	return FsApi + "/" + fsName + FsQuotaSuffix
+}
+
+func (q *fileSystemQuota) Set(ctx context.Context, request *SetFsQuotaRequest,
+	token string) (result *FsQuotaResponse, err error) {
+	result = &FsQuotaResponse{}
+	err = core.NewRequestBuilder(q.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(quotaApi(request.FsName)).
+		WithQueryParamFilter(KeyUsername, request.Username).
+		WithMethod(http.PUT).
+		WithBody(request).
+		WithResult(result).
+		Do()
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (q *fileSystemQuota) List(ctx context.Context, request *ListFsQuotaRequest,
+	token string) (result *ListFsQuotaResponse, err error) {
+	result = &ListFsQuotaResponse{}
+	err = core.NewRequestBuilder(q.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(quotaApi(request.FsName)).
+		WithQueryParamFilter(KeyUsername, request.Username).
+		WithQueryParam(KeyUsage, strconv.FormatBool(request.WithUsage)).
+		WithMethod(http.GET).
+		WithResult(result).
+		Do()
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (q *fileSystemQuota) Delete(ctx context.Context, request *DeleteFsQuotaRequest, token string) (err error) {
+	err = core.NewRequestBuilder(q.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(quotaApi(request.FsName)).
+		WithQueryParamFilter(KeyPath, request.Path).
+		WithQueryParamFilter(KeyUsername, request.Username).
+		WithMethod(http.DELETE).
+		Do()
+	return
+}
+
+type FileSystemQuotaGetter interface {
+	FileSystemQuota() FileSystemQuotaInterface
+}
+
+type FileSystemQuotaInterface interface {
+	Set(ctx context.Context, request *SetFsQuotaRequest, token string) (*FsQuotaResponse, error)
+	List(ctx context.Context, request *ListFsQuotaRequest, token string) (*ListFsQuotaResponse, error)
+	Delete(ctx context.Context, request *DeleteFsQuotaRequest, token string) error
+}
+
+// newFileSystemQuota returns a fileSystemQuota.
+func newFileSystemQuota(c *APIV1Client) *fileSystemQuota {
+	return &fileSystemQuota{
+		client: c.RESTClient(),
+	}
+}
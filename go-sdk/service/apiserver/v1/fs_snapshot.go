@@ -0,0 +1,138 @@
+/*
+Copyright (c) 2021 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/http/core"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/http/util/http"
+)
+
+const (
+	FsSnapshotsSuffix = "/snapshots"
+)
+
+type fileSystemSnapshot struct {
+	client *core.PaddleFlowClient
+}
+
+type CreateFsSnapshotRequest struct {
+	FsName   string `json:"-"`
+	Username string `json:"-"`
+	Name     string `json:"name"`
+}
+
+type FsSnapshotResponse struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	FsName     string `json:"fsName"`
+	UserName   string `json:"userName"`
+	Mode       string `json:"mode"`
+	Status     string `json:"status"`
+	Message    string `json:"message,omitempty"`
+	FileCount  int64  `json:"fileCount"`
+	Size       int64  `json:"size"`
+	CreateTime string `json:"createTime"`
+	UpdateTime string `json:"updateTime"`
+}
+
+type ListFsSnapshotResponse struct {
+	SnapshotList []*FsSnapshotResponse `json:"snapshotList"`
+}
+
+func snapshotsApi(fsName string) string {
+	return FsApi + "/" + fsName + FsSnapshotsSuffix
+}
+
+func (s *fileSystemSnapshot) Create(ctx context.Context, request *CreateFsSnapshotRequest,
+	token string) (result *FsSnapshotResponse, err error) {
+	result = &FsSnapshotResponse{}
+	err = core.NewRequestBuilder(s.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(snapshotsApi(request.FsName)).
+		WithQueryParamFilter(KeyUsername, request.Username).
+		WithMethod(http.POST).
+		WithBody(request).
+		WithResult(result).
+		Do()
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (s *fileSystemSnapshot) Get(ctx context.Context, fsName, snapshotName, username,
+	token string) (result *FsSnapshotResponse, err error) {
+	result = &FsSnapshotResponse{}
+	err = core.NewRequestBuilder(s.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(snapshotsApi(fsName)+"/"+snapshotName).
+		WithQueryParamFilter(KeyUsername, username).
+		WithMethod(http.GET).
+		WithResult(result).
+		Do()
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (s *fileSystemSnapshot) List(ctx context.Context, fsName, username,
+	token string) (result *ListFsSnapshotResponse, err error) {
+	result = &ListFsSnapshotResponse{}
+	err = core.NewRequestBuilder(s.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(snapshotsApi(fsName)).
+		WithQueryParamFilter(KeyUsername, username).
+		WithMethod(http.GET).
+		WithResult(result).
+		Do()
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (s *fileSystemSnapshot) Delete(ctx context.Context, fsName, snapshotName, username, token string) (err error) {
+	err = core.NewRequestBuilder(s.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(snapshotsApi(fsName)+"/"+snapshotName).
+		WithQueryParamFilter(KeyUsername, username).
+		WithMethod(http.DELETE).
+		Do()
+	return
+}
+
+type FileSystemSnapshotGetter interface {
+	FileSystemSnapshot() FileSystemSnapshotInterface
+}
+
+type FileSystemSnapshotInterface interface {
+	Create(ctx context.Context, request *CreateFsSnapshotRequest, token string) (*FsSnapshotResponse, error)
+	Get(ctx context.Context, fsName, snapshotName, username, token string) (*FsSnapshotResponse, error)
+	List(ctx context.Context, fsName, username, token string) (*ListFsSnapshotResponse, error)
+	Delete(ctx context.Context, fsName, snapshotName, username, token string) error
+}
+
+// newFileSystemSnapshot returns a fileSystemSnapshot.
+func newFileSystemSnapshot(c *APIV1Client) *fileSystemSnapshot {
+	return &fileSystemSnapshot{
+		client: c.RESTClient(),
+	}
+}
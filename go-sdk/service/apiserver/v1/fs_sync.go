@@ -0,0 +1,173 @@
+/*
+Copyright (c) 2021 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/http/core"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/http/util/http"
+)
+
+const (
+	FsSyncApi = FsApi + "/sync"
+
+	FsSyncActionStop   = "stop"
+	FsSyncActionResume = "resume"
+)
+
+type fileSystemSync struct {
+	client *core.PaddleFlowClient
+}
+
+type CreateFsSyncTaskRequest struct {
+	SrcFsName string `json:"srcFsName"`
+	SrcPath   string `json:"srcPath"`
+	DstFsName string `json:"dstFsName"`
+	DstPath   string `json:"dstPath"`
+	Username  string `json:"username"`
+	// Mode is copy or sync, sync deletes the files in destination which are not in source
+	Mode string `json:"mode"`
+	// Compare is size-mtime or checksum
+	Compare        string `json:"compare"`
+	Workers        int    `json:"workers"`
+	BandwidthLimit int64  `json:"bandwidthLimit"`
+	DryRun         bool   `json:"dryRun"`
+}
+
+type FsSyncTaskResponse struct {
+	ID             string   `json:"id"`
+	UserName       string   `json:"userName"`
+	SrcFsName      string   `json:"srcFsName"`
+	SrcPath        string   `json:"srcPath"`
+	DstFsName      string   `json:"dstFsName"`
+	DstPath        string   `json:"dstPath"`
+	Mode           string   `json:"mode"`
+	Compare        string   `json:"compare"`
+	Workers        int      `json:"workers"`
+	BandwidthLimit int64    `json:"bandwidthLimit"`
+	DryRun         bool     `json:"dryRun"`
+	Status         string   `json:"status"`
+	Message        string   `json:"message,omitempty"`
+	Total          int64    `json:"total"`
+	Copied         int64    `json:"copied"`
+	Skipped        int64    `json:"skipped"`
+	Deleted        int64    `json:"deleted"`
+	Failed         int64    `json:"failed"`
+	CopiedBytes    int64    `json:"copiedBytes"`
+	Checkpoint     string   `json:"checkpoint,omitempty"`
+	Diff           []string `json:"diff,omitempty"`
+	CreateTime     string   `json:"createTime"`
+	UpdateTime     string   `json:"updateTime"`
+}
+
+type ListFsSyncTaskResponse struct {
+	TaskList []*FsSyncTaskResponse `json:"taskList"`
+}
+
+func (s *fileSystemSync) Create(ctx context.Context, request *CreateFsSyncTaskRequest,
+	token string) (result *FsSyncTaskResponse, err error) {
+	result = &FsSyncTaskResponse{}
+	err = core.NewRequestBuilder(s.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(FsSyncApi).
+		WithMethod(http.POST).
+		WithBody(request).
+		WithResult(result).
+		Do()
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (s *fileSystemSync) Get(ctx context.Context, taskID, token string) (result *FsSyncTaskResponse, err error) {
+	result = &FsSyncTaskResponse{}
+	err = core.NewRequestBuilder(s.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(FsSyncApi + "/" + taskID).
+		WithMethod(http.GET).
+		WithResult(result).
+		Do()
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// List lists the sync tasks, status is separated by comma and empty means all
+func (s *fileSystemSync) List(ctx context.Context, status, token string) (result *ListFsSyncTaskResponse,
+	err error) {
+	result = &ListFsSyncTaskResponse{}
+	err = core.NewRequestBuilder(s.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(FsSyncApi).
+		WithQueryParamFilter(KeyStatus, status).
+		WithMethod(http.GET).
+		WithResult(result).
+		Do()
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Update stops the running task or resumes the finished task with action stop or resume
+func (s *fileSystemSync) Update(ctx context.Context, taskID, action, token string) (result *FsSyncTaskResponse,
+	err error) {
+	result = &FsSyncTaskResponse{}
+	err = core.NewRequestBuilder(s.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(FsSyncApi+"/"+taskID).
+		WithQueryParam(KeyAction, action).
+		WithMethod(http.PUT).
+		WithResult(result).
+		Do()
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (s *fileSystemSync) Delete(ctx context.Context, taskID, token string) (err error) {
+	err = core.NewRequestBuilder(s.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(FsSyncApi + "/" + taskID).
+		WithMethod(http.DELETE).
+		Do()
+	return
+}
+
+type FileSystemSyncGetter interface {
+	FileSystemSync() FileSystemSyncInterface
+}
+
+type FileSystemSyncInterface interface {
+	Create(ctx context.Context, request *CreateFsSyncTaskRequest, token string) (*FsSyncTaskResponse, error)
+	Get(ctx context.Context, taskID, token string) (*FsSyncTaskResponse, error)
+	List(ctx context.Context, status, token string) (*ListFsSyncTaskResponse, error)
+	Update(ctx context.Context, taskID, action, token string) (*FsSyncTaskResponse, error)
+	Delete(ctx context.Context, taskID, token string) error
+}
+
+// newFileSystemSync returns a fileSystemSync.
+func newFileSystemSync(c *APIV1Client) *fileSystemSync {
+	return &fileSystemSync{
+		client: c.RESTClient(),
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/http/core"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/http/util/http"
+)
+
+const (
+	GrantApi        = Prefix + "/grant"
+	KeyResourceType = "resourceType"
+	KeyResourceID   = "resourceID"
+)
+
+type grant struct {
+	client *core.PaddleFlowClient
+}
+
+type CreateGrantRequest struct {
+	UserName     string `json:"userName"`
+	ResourceType string `json:"resourceType"` // 资源类型，比如queue、fs
+	ResourceID   string `json:"resourceID"`
+}
+
+type CreateGrantResponse struct {
+	GrantID string `json:"grantID"`
+}
+
+type DeleteGrantRequest struct {
+	UserName     string
+	ResourceType string
+	ResourceID   string
+}
+
+type ListGrantRequest struct {
+	Marker   string
+	MaxKeys  int
+	UserName string
+}
+
+type GrantInfo struct {
+	ID           string    `json:"grantID"`
+	UserName     string    `json:"userName"`
+	ResourceType string    `json:"resourceType"`
+	ResourceID   string    `json:"resourceID"`
+	CreatedAt    time.Time `json:"createTime"`
+	UpdatedAt    time.Time `json:"updateTime,omitempty"`
+}
+
+type ListGrantResponse struct {
+	common.MarkerInfo
+	GrantList []GrantInfo `json:"grantList"`
+}
+
+func (g *grant) Create(ctx context.Context, request *CreateGrantRequest,
+	token string) (result *CreateGrantResponse, err error) {
+	result = &CreateGrantResponse{}
+	err = core.NewRequestBuilder(g.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(GrantApi).
+		WithMethod(http.POST).
+		WithBody(request).
+		WithResult(result).
+		Do()
+	return
+}
+
+func (g *grant) Delete(ctx context.Context, request *DeleteGrantRequest, token string) (err error) {
+	err = core.NewRequestBuilder(g.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(GrantApi).
+		WithMethod(http.DELETE).
+		WithQueryParam(KeyUsername, request.UserName).
+		WithQueryParam(KeyResourceType, request.ResourceType).
+		WithQueryParam(KeyResourceID, request.ResourceID).
+		Do()
+	return
+}
+
+func (g *grant) List(ctx context.Context, request *ListGrantRequest,
+	token string) (result *ListGrantResponse, err error) {
+	result = &ListGrantResponse{}
+	err = core.NewRequestBuilder(g.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(GrantApi).
+		WithMethod(http.GET).
+		WithQueryParamFilter(KeyMarker, request.Marker).
+		WithQueryParamFilter(KeyMaxKeys, strconv.Itoa(request.MaxKeys)).
+		WithQueryParamFilter(KeyUsername, request.UserName).
+		WithResult(result).
+		Do()
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+type GrantGetter interface {
+	Grant() GrantInterface
+}
+
+type GrantInterface interface {
+	Create(ctx context.Context, request *CreateGrantRequest, token string) (*CreateGrantResponse, error)
+	Delete(ctx context.Context, request *DeleteGrantRequest, token string) error
+	List(ctx context.Context, request *ListGrantRequest, token string) (*ListGrantResponse, error)
+}
+
+// newGrant returns a grant.
+func newGrant(c *APIV1Client) *grant {
+	return &grant{
+		client: c.RESTClient(),
+	}
+}
@@ -33,12 +33,16 @@ const (
 	TypeSingle      = "single"
 	TypeDistributed = "distributed"
 	TypeWorkflow    = "workflow"
+	TypeNotebook    = "notebook"
+	TypeServing     = "serving"
 	KeyAction       = "action"
 	KeyStatus       = "status"
 	KeyTimestamp    = "timestamp"
 	KeyStartTime    = "startTime"
 	KeyQueue        = "queue"
 	KeyLabels       = "labels"
+	KeyDryRun       = "dryRun"
+	KeyTemplate     = "template"
 )
 
 type job struct {
@@ -51,6 +55,23 @@ type CreateSingleJobRequest struct {
 	JobSpec       `json:",inline"`
 }
 
+// CreateNotebookJobRequest convey request for create notebook job
+type CreateNotebookJobRequest struct {
+	CommonJobInfo `json:",inline"`
+	JobSpec       `json:",inline"`
+	Notebook      schema.NotebookConf `json:"notebook"`
+}
+
+// CreateServingJobRequest convey request for create serving job
+type CreateServingJobRequest struct {
+	CommonJobInfo `json:",inline"`
+	JobSpec       `json:",inline"`
+	// Framework is standalone or kserve
+	Framework schema.Framework   `json:"framework,omitempty"`
+	Replicas  int                `json:"replicas,omitempty"`
+	Serving   schema.ServingConf `json:"serving"`
+}
+
 // CreateDisJobRequest convey request for create distributed job
 type CreateDisJobRequest struct {
 	CommonJobInfo     `json:",inline"`
@@ -75,6 +96,9 @@ type CommonJobInfo struct {
 	Annotations      map[string]string `json:"annotations"`
 	SchedulingPolicy SchedulingPolicy  `json:"schedulingPolicy"`
 	UserName         string            `json:",omitempty"`
+	// DependsOn is the prerequisite job ids, job is held until all of them meet DependencyCondition
+	DependsOn           []string                   `json:"dependsOn,omitempty"`
+	DependencyCondition schema.DependencyCondition `json:"dependencyCondition,omitempty"`
 }
 
 // SchedulingPolicy indicate queueID/priority
@@ -102,6 +126,9 @@ type MemberSpec struct {
 	JobSpec       `json:",inline"`
 	Role          string `json:"role"`
 	Replicas      int    `json:"replicas"`
+	// MinReplicas and MaxReplicas are the bounds of replicas for elastic training
+	MinReplicas int `json:"minReplicas,omitempty"`
+	MaxReplicas int `json:"maxReplicas,omitempty"`
 }
 
 type UpdateJobRequest struct {
@@ -109,13 +136,43 @@ type UpdateJobRequest struct {
 	Priority    string            `json:"priority"`
 	Labels      map[string]string `json:"labels"`
 	Annotations map[string]string `json:"annotations"`
+	// Replicas of members to scale for elastic job, the key is role of member
+	Replicas map[string]int `json:"replicas,omitempty"`
+	// Serving rolls out a new revision or updates autoscaling of serving job
+	Serving *UpdateServingRequest `json:"serving,omitempty"`
+}
+
+// UpdateServingRequest convey request for update serving job
+type UpdateServingRequest struct {
+	Image       string                     `json:"image,omitempty"`
+	Command     string                     `json:"command,omitempty"`
+	Env         map[string]string          `json:"env,omitempty"`
+	Replicas    int                        `json:"replicas,omitempty"`
+	Autoscaling *schema.ServingAutoscaling `json:"autoscaling,omitempty"`
+	// CanaryTrafficPercent is the traffic percent of latest revision, and 100 or empty promotes the latest revision
+	CanaryTrafficPercent *int `json:"canaryTrafficPercent,omitempty"`
 }
 
 // CreateJobResponse convey response for create job
+// CreateJobFromTemplateRequest is the request of creating job by template, and the values of parameters
+// override their defaults
+type CreateJobFromTemplateRequest struct {
+	TemplateVersion string                 `json:"templateVersion"`
+	Parameters      map[string]interface{} `json:"parameters"`
+}
+
 type CreateJobResponse struct {
 	ID string `json:"id"`
 }
 
+// DryRunJobResponse contains the rendered objects which would be submitted to cluster
+type DryRunJobResponse struct {
+	ID        string        `json:"id"`
+	ClusterID string        `json:"clusterID"`
+	QueueID   string        `json:"queueID"`
+	Objects   []interface{} `json:"objects"`
+}
+
 type Member struct {
 	ID          string            `json:"id"`
 	Replicas    int               `json:"replicas"`
@@ -154,6 +211,8 @@ type GetJobResponse struct {
 	Runtime                *RuntimeInfo            `json:"runtime,omitempty"`
 	DistributedRuntime     *DistributedRuntimeInfo `json:"distributedRuntime,omitempty"`
 	WorkflowRuntime        *WorkflowRuntimeInfo    `json:"workflowRuntime,omitempty"`
+	Notebook               *schema.NotebookConf    `json:"notebook,omitempty"`
+	Serving                *schema.ServingConf     `json:"serving,omitempty"`
 	UpdateTime             time.Time               `json:"-"`
 }
 
@@ -162,6 +221,8 @@ type RuntimeInfo struct {
 	Namespace string `json:"namespace,omitempty"`
 	ID        string `json:"id,omitempty"`
 	Status    string `json:"status,omitempty"`
+	// ConnectURL is the url to access notebook job or serving job
+	ConnectURL string `json:"connectURL,omitempty"`
 }
 
 type DistributedRuntimeInfo struct {
@@ -183,6 +244,68 @@ type WorkflowRuntimeInfo struct {
 func (j *job) Create(ctx context.Context, single *CreateSingleJobRequest, distributed *CreateDisJobRequest,
 	wf *CreateWfJobRequest, token string) (result *CreateJobResponse, err error) {
 	result = &CreateJobResponse{}
+	err = j.createRequest(single, distributed, wf, token).
+		WithResult(result).
+		Do()
+	return
+}
+
+// DryRun validates the job request, and returns the rendered objects without creating job
+func (j *job) DryRun(ctx context.Context, single *CreateSingleJobRequest, distributed *CreateDisJobRequest,
+	wf *CreateWfJobRequest, token string) (result *DryRunJobResponse, err error) {
+	result = &DryRunJobResponse{}
+	err = j.createRequest(single, distributed, wf, token).
+		WithQueryParam(KeyDryRun, "true").
+		WithResult(result).
+		Do()
+	return
+}
+
+// CreateNotebook creates notebook job, which runs an interactive development server such as jupyter or vscode
+func (j *job) CreateNotebook(ctx context.Context, request *CreateNotebookJobRequest,
+	token string) (result *CreateJobResponse, err error) {
+	result = &CreateJobResponse{}
+	err = core.NewRequestBuilder(j.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(JobApi + "/" + TypeNotebook).
+		WithMethod(http.POST).
+		WithBody(request).
+		WithResult(result).
+		Do()
+	return
+}
+
+// CreateServing creates serving job, which runs a long-running model server
+func (j *job) CreateServing(ctx context.Context, request *CreateServingJobRequest,
+	token string) (result *CreateJobResponse, err error) {
+	result = &CreateJobResponse{}
+	err = core.NewRequestBuilder(j.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(JobApi + "/" + TypeServing).
+		WithMethod(http.POST).
+		WithBody(request).
+		WithResult(result).
+		Do()
+	return
+}
+
+// CreateFromTemplate creates job by template, template is the name or ID of job template
+func (j *job) CreateFromTemplate(ctx context.Context, template string, request *CreateJobFromTemplateRequest,
+	token string) (result *CreateJobResponse, err error) {
+	result = &CreateJobResponse{}
+	err = core.NewRequestBuilder(j.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(JobApi).
+		WithMethod(http.POST).
+		WithQueryParam(KeyTemplate, template).
+		WithBody(request).
+		WithResult(result).
+		Do()
+	return
+}
+
+func (j *job) createRequest(single *CreateSingleJobRequest, distributed *CreateDisJobRequest,
+	wf *CreateWfJobRequest, token string) *core.RequestBuilder {
 	requestClient := core.NewRequestBuilder(j.client).
 		WithHeader(common.HeaderKeyAuthorization, token).
 		WithMethod(http.POST)
@@ -196,9 +319,7 @@ func (j *job) Create(ctx context.Context, single *CreateSingleJobRequest, distri
 		requestClient.WithURL(JobApi + "/" + TypeWorkflow).
 			WithBody(wf)
 	}
-	err = requestClient.WithResult(result).
-		Do()
-	return
+	return requestClient
 }
 
 func (j *job) Get(ctx context.Context, jobID,
@@ -282,6 +403,11 @@ type JobGetter interface {
 type JobInterface interface {
 	Create(ctx context.Context, single *CreateSingleJobRequest, distributed *CreateDisJobRequest,
 		wf *CreateWfJobRequest, token string) (*CreateJobResponse, error)
+	DryRun(ctx context.Context, single *CreateSingleJobRequest, distributed *CreateDisJobRequest,
+		wf *CreateWfJobRequest, token string) (*DryRunJobResponse, error)
+	CreateNotebook(ctx context.Context, request *CreateNotebookJobRequest, token string) (*CreateJobResponse, error)
+	CreateServing(ctx context.Context, request *CreateServingJobRequest, token string) (*CreateJobResponse, error)
+	CreateFromTemplate(ctx context.Context, template string, request *CreateJobFromTemplateRequest, token string) (*CreateJobResponse, error)
 	Get(ctx context.Context, jobID string, token string) (*GetJobResponse, error)
 	List(ctx context.Context, request *ListJobRequest, token string) (*ListJobResponse, error)
 	Update(ctx context.Context, jobID string, request *UpdateJobRequest, token string) error
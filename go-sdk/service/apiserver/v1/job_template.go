@@ -0,0 +1,221 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/http/core"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/http/util/http"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+)
+
+const (
+	JobTemplateApi = Prefix + "/jobTemplate"
+)
+
+type jobTemplate struct {
+	client *core.PaddleFlowClient
+}
+
+type CreateJobTemplateRequest struct {
+	Name       string                        `json:"name"`
+	Desc       string                        `json:"desc"`
+	JobType    schema.JobType                `json:"jobType"`
+	Parameters []schema.JobTemplateParameter `json:"parameters"`
+	// Template is the request body of creating single, distributed or workflow job,
+	// and parameters are referenced as {{name}} in its string values
+	Template json.RawMessage `json:"template"`
+}
+
+type CreateJobTemplateResponse struct {
+	TemplateID        string `json:"templateID"`
+	TemplateVersionID string `json:"templateVersionID"`
+	Name              string `json:"name"`
+}
+
+type UpdateJobTemplateRequest struct {
+	Desc       string                        `json:"desc"`
+	JobType    schema.JobType                `json:"jobType"`
+	Parameters []schema.JobTemplateParameter `json:"parameters"`
+	Template   json.RawMessage               `json:"template"`
+}
+
+type UpdateJobTemplateResponse struct {
+	TemplateID        string `json:"templateID"`
+	TemplateVersionID string `json:"templateVersionID"`
+}
+
+type ListJobTemplateRequest struct {
+	Marker     string
+	MaxKeys    int
+	UserFilter []string
+	NameFilter []string
+}
+
+type ListJobTemplateResponse struct {
+	common.MarkerInfo
+	TemplateList []JobTemplateBrief `json:"templateList"`
+}
+
+type GetJobTemplateResponse struct {
+	Template JobTemplateBrief          `json:"template"`
+	Versions []JobTemplateVersionBrief `json:"versions"`
+}
+
+type GetJobTemplateVersionResponse struct {
+	Template JobTemplateBrief        `json:"template"`
+	Version  JobTemplateVersionBrief `json:"version"`
+}
+
+type JobTemplateBrief struct {
+	ID         string `json:"templateID"`
+	Name       string `json:"name"`
+	Desc       string `json:"desc"`
+	UserName   string `json:"username"`
+	CreateTime string `json:"createTime"`
+	UpdateTime string `json:"updateTime"`
+}
+
+type JobTemplateVersionBrief struct {
+	ID         string                        `json:"templateVersionID"`
+	TemplateID string                        `json:"templateID"`
+	JobType    string                        `json:"jobType"`
+	Parameters []schema.JobTemplateParameter `json:"parameters"`
+	Template   json.RawMessage               `json:"template"`
+	UserName   string                        `json:"username"`
+	CreateTime string                        `json:"createTime"`
+}
+
+func (t *jobTemplate) Create(ctx context.Context, request *CreateJobTemplateRequest,
+	token string) (result *CreateJobTemplateResponse, err error) {
+	result = &CreateJobTemplateResponse{}
+	err = core.NewRequestBuilder(t.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(JobTemplateApi).
+		WithMethod(http.POST).
+		WithBody(request).
+		WithResult(result).
+		Do()
+	return
+}
+
+// Update creates a new version of job template
+func (t *jobTemplate) Update(ctx context.Context, templateID string, request *UpdateJobTemplateRequest,
+	token string) (result *UpdateJobTemplateResponse, err error) {
+	result = &UpdateJobTemplateResponse{}
+	err = core.NewRequestBuilder(t.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(JobTemplateApi + "/" + templateID).
+		WithMethod(http.PUT).
+		WithBody(request).
+		WithResult(result).
+		Do()
+	return
+}
+
+func (t *jobTemplate) Get(ctx context.Context, templateID,
+	token string) (result *GetJobTemplateResponse, err error) {
+	result = &GetJobTemplateResponse{}
+	err = core.NewRequestBuilder(t.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(JobTemplateApi + "/" + templateID).
+		WithMethod(http.GET).
+		WithResult(result).
+		Do()
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (t *jobTemplate) List(ctx context.Context, request *ListJobTemplateRequest,
+	token string) (result *ListJobTemplateResponse, err error) {
+	result = &ListJobTemplateResponse{}
+	err = core.NewRequestBuilder(t.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(JobTemplateApi).
+		WithMethod(http.GET).
+		WithQueryParamFilter(KeyMarker, request.Marker).
+		WithQueryParamFilter(KeyMaxKeys, strconv.Itoa(request.MaxKeys)).
+		WithQueryParamFilter("userFilter", strings.Join(request.UserFilter, ",")).
+		WithQueryParamFilter("nameFilter", strings.Join(request.NameFilter, ",")).
+		WithResult(result).
+		Do()
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Delete deletes job template and all its versions
+func (t *jobTemplate) Delete(ctx context.Context, templateID, token string) (err error) {
+	err = core.NewRequestBuilder(t.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(JobTemplateApi + "/" + templateID).
+		WithMethod(http.DELETE).
+		Do()
+	return
+}
+
+func (t *jobTemplate) GetVersion(ctx context.Context, templateID, versionID,
+	token string) (result *GetJobTemplateVersionResponse, err error) {
+	result = &GetJobTemplateVersionResponse{}
+	err = core.NewRequestBuilder(t.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(JobTemplateApi + "/" + templateID + "/" + versionID).
+		WithMethod(http.GET).
+		WithResult(result).
+		Do()
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (t *jobTemplate) DeleteVersion(ctx context.Context, templateID, versionID, token string) (err error) {
+	err = core.NewRequestBuilder(t.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(JobTemplateApi + "/" + templateID + "/" + versionID).
+		WithMethod(http.DELETE).
+		Do()
+	return
+}
+
+type JobTemplateGetter interface {
+	JobTemplate() JobTemplateInterface
+}
+
+type JobTemplateInterface interface {
+	Create(ctx context.Context, request *CreateJobTemplateRequest, token string) (*CreateJobTemplateResponse, error)
+	Update(ctx context.Context, templateID string, request *UpdateJobTemplateRequest, token string) (*UpdateJobTemplateResponse, error)
+	Get(ctx context.Context, templateID string, token string) (*GetJobTemplateResponse, error)
+	List(ctx context.Context, request *ListJobTemplateRequest, token string) (*ListJobTemplateResponse, error)
+	Delete(ctx context.Context, templateID string, token string) error
+	GetVersion(ctx context.Context, templateID, versionID string, token string) (*GetJobTemplateVersionResponse, error)
+	DeleteVersion(ctx context.Context, templateID, versionID string, token string) error
+}
+
+// newJobTemplate returns a jobTemplate.
+func newJobTemplate(c *APIV1Client) *jobTemplate {
+	return &jobTemplate{
+		client: c.RESTClient(),
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/http/core"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/http/util/http"
+)
+
+const (
+	LinkApi   = Prefix + "/link"
+	KeyFsPath = "fsPath"
+)
+
+type link struct {
+	client *core.PaddleFlowClient
+}
+
+// CreateLinkRequest links the file system of url to the path of fs
+type CreateLinkRequest struct {
+	FsName     string            `json:"fsName"`
+	Url        string            `json:"url"`
+	Properties map[string]string `json:"properties"`
+	Username   string            `json:"username"`
+	FsPath     string            `json:"fsPath"`
+}
+
+type DeleteLinkRequest struct {
+	FsName   string `json:"fsName"`
+	Username string `json:"username"`
+	FsPath   string `json:"fsPath"`
+}
+
+type GetLinkRequest struct {
+	FsName   string `json:"fsName"`
+	Username string `json:"username"`
+	FsPath   string `json:"fsPath"`
+	Marker   string `json:"marker"`
+	MaxKeys  int    `json:"maxKeys"`
+}
+
+type GetLinkResponse struct {
+	Marker     string          `json:"marker"`
+	Truncated  bool            `json:"truncated"`
+	NextMarker string          `json:"nextMarker"`
+	LinkList   []*LinkResponse `json:"linkList"`
+}
+
+type LinkResponse struct {
+	FsName        string            `json:"fsName"`
+	FsPath        string            `json:"fsPath"`
+	ServerAddress string            `json:"serverAddress"`
+	Type          string            `json:"type"`
+	Username      string            `json:"username"`
+	SubPath       string            `json:"subPath"`
+	Properties    map[string]string `json:"properties"`
+}
+
+func (l *link) Create(ctx context.Context, request *CreateLinkRequest, token string) (err error) {
+	err = core.NewRequestBuilder(l.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(LinkApi).
+		WithMethod(http.POST).
+		WithBody(request).
+		Do()
+	return
+}
+
+func (l *link) Delete(ctx context.Context, request *DeleteLinkRequest, token string) (err error) {
+	err = core.NewRequestBuilder(l.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(LinkApi+"/"+request.FsName).
+		WithMethod(http.DELETE).
+		WithQueryParam(KeyFsPath, request.FsPath).
+		WithQueryParamFilter(KeyUsername, request.Username).
+		Do()
+	return
+}
+
+func (l *link) Get(ctx context.Context, request *GetLinkRequest,
+	token string) (result *GetLinkResponse, err error) {
+	result = &GetLinkResponse{}
+	err = core.NewRequestBuilder(l.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(LinkApi+"/"+request.FsName).
+		WithMethod(http.GET).
+		WithQueryParamFilter(KeyFsPath, request.FsPath).
+		WithQueryParamFilter(KeyUsername, request.Username).
+		WithQueryParamFilter(KeyMarker, request.Marker).
+		WithQueryParamFilter(KeyMaxKeys, strconv.Itoa(request.MaxKeys)).
+		WithResult(result).
+		Do()
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+type LinkGetter interface {
+	Link() LinkInterface
+}
+
+type LinkInterface interface {
+	Create(ctx context.Context, request *CreateLinkRequest, token string) error
+	Delete(ctx context.Context, request *DeleteLinkRequest, token string) error
+	Get(ctx context.Context, request *GetLinkRequest, token string) (*GetLinkResponse, error)
+}
+
+// newLink returns a link.
+func newLink(c *APIV1Client) *link {
+	return &link{
+		client: c.RESTClient(),
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/http/core"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/http/util/http"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+)
+
+const (
+	LogApi             = Prefix + "/log"
+	KeyJobID           = "jobID"
+	KeyPageNo          = "pageNo"
+	KeyPageSize        = "pageSize"
+	KeyLogFilePosition = "logFilePosition"
+	KeyNamespace       = "namespace"
+	KeyClusterName     = "clusterName"
+	KeyReadFromTail    = "readFromTail"
+	KeyLineLimit       = "lineLimit"
+	KeySizeLimit       = "sizeLimit"
+	KeyType            = "type"
+	KeyFramework       = "framework"
+)
+
+type logs struct {
+	client *core.PaddleFlowClient
+}
+
+type GetRunLogRequest struct {
+	JobID           string `json:"jobID"`
+	PageNo          int    `json:"pageNo"`
+	PageSize        int    `json:"pageSize"`
+	LogFilePosition string `json:"logFilePosition"` // begin or end, default is end
+}
+
+type GetRunLogResponse struct {
+	SubmitLog string              `json:"submitLog"`
+	RunLog    []schema.JobLogInfo `json:"runLog"`
+	RunID     string              `json:"runID"`
+}
+
+// GetJobLogRequest gets logs of paddleflow job by JobID, or logs of kubernetes resource by Name, Namespace and Type
+type GetJobLogRequest struct {
+	JobID        string
+	Name         string
+	Namespace    string
+	ClusterName  string
+	Type         string // kubernetes resource type, such as pod and deployment
+	Framework    string
+	ReadFromTail bool
+	LineLimit    int
+	SizeLimit    string // such as 100Mi
+}
+
+func (l *logs) GetRunLog(ctx context.Context, runID string, request *GetRunLogRequest,
+	token string) (result *GetRunLogResponse, err error) {
+	result = &GetRunLogResponse{}
+	builder := core.NewRequestBuilder(l.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(LogApi+"/run/"+runID).
+		WithMethod(http.GET).
+		WithQueryParamFilter(KeyJobID, request.JobID).
+		WithQueryParamFilter(KeyLogFilePosition, request.LogFilePosition).
+		WithResult(result)
+	if request.PageNo > 0 {
+		builder.WithQueryParam(KeyPageNo, strconv.Itoa(request.PageNo))
+	}
+	if request.PageSize > 0 {
+		builder.WithQueryParam(KeyPageSize, strconv.Itoa(request.PageSize))
+	}
+	if err = builder.Do(); err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (l *logs) GetJobLog(ctx context.Context, request *GetJobLogRequest,
+	token string) (result *schema.JobLogInfo, err error) {
+	result = &schema.JobLogInfo{}
+	builder := core.NewRequestBuilder(l.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(LogApi+"/job").
+		WithMethod(http.GET).
+		WithQueryParamFilter(KeyJobID, request.JobID).
+		WithQueryParamFilter(KeyName, request.Name).
+		WithQueryParamFilter(KeyNamespace, request.Namespace).
+		WithQueryParamFilter(KeyClusterName, request.ClusterName).
+		WithQueryParamFilter(KeyType, request.Type).
+		WithQueryParamFilter(KeyFramework, request.Framework).
+		WithQueryParamFilter(KeySizeLimit, request.SizeLimit).
+		WithQueryParam(KeyReadFromTail, strconv.FormatBool(request.ReadFromTail)).
+		WithResult(result)
+	if request.LineLimit > 0 {
+		builder.WithQueryParam(KeyLineLimit, strconv.Itoa(request.LineLimit))
+	}
+	if err = builder.Do(); err != nil {
+		return nil, err
+	}
+	return
+}
+
+type LogGetter interface {
+	Log() LogInterface
+}
+
+type LogInterface interface {
+	GetRunLog(ctx context.Context, runID string, request *GetRunLogRequest, token string) (*GetRunLogResponse, error)
+	GetJobLog(ctx context.Context, request *GetJobLogRequest, token string) (*schema.JobLogInfo, error)
+}
+
+// newLog returns a logs.
+func newLog(c *APIV1Client) *logs {
+	return &logs{
+		client: c.RESTClient(),
+	}
+}
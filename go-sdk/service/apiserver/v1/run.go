@@ -28,6 +28,7 @@ import (
 
 const (
 	runApi         = Prefix + "/run"
+	runJsonApi     = Prefix + "/runjson"
 	runCacheApi    = Prefix + "/runCache"
 	runArtifactApi = Prefix + "/artifact"
 )
@@ -197,6 +198,13 @@ type ListArtifactRequest struct {
 	Marker     string
 }
 
+type DeleteArtifactRequest struct {
+	UserName string // optional, only for root user
+	FsName   string
+	RunID    string
+	Path     string
+}
+
 type ListArtifactResponse struct {
 	common.MarkerInfo
 	ArtifactEventList []ArtifactEventBrief `json:"artifactEventList"`
@@ -236,6 +244,24 @@ func (r *run) Create(ctx context.Context, request *CreateRunRequest,
 	return
 }
 
+// CreateByJson creates run by the workflow in json format, whose keys are in camel case, such as dockerEnv
+func (r *run) CreateByJson(ctx context.Context, request map[string]interface{},
+	token string) (result *CreateRunResponse, err error) {
+	result = &CreateRunResponse{}
+	err = newRequestBuilderWithTokenHeader(r.client, token).
+		WithURL(runJsonApi).
+		WithMethod(http.POST).
+		WithBody(request).
+		WithResult(result).
+		Do()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return
+}
+
 func (r *run) Get(ctx context.Context, runID string, token string) (result *GetRunResponse, err error) {
 	// 由于GetResponse中的Runtime类型为接口，不能在直接传给WithResult，因此先用一个临时Map接收Response信息
 	result = &GetRunResponse{}
@@ -396,8 +422,26 @@ func (r *run) ListArtifact(ctx context.Context, request *ListArtifactRequest,
 	return
 }
 
+func (r *run) DeleteArtifact(ctx context.Context, request *DeleteArtifactRequest, token string) (err error) {
+	err = newRequestBuilderWithTokenHeader(r.client, token).
+		WithMethod(http.DELETE).
+		WithURL(runArtifactApi).
+		WithQueryParamFilter("username", request.UserName).
+		WithQueryParam("fsname", request.FsName).
+		WithQueryParam("runID", request.RunID).
+		WithQueryParam("path", request.Path).
+		Do()
+
+	if err != nil {
+		return err
+	}
+
+	return
+}
+
 type RunInterface interface {
 	Create(ctx context.Context, request *CreateRunRequest, token string) (result *CreateRunResponse, err error)
+	CreateByJson(ctx context.Context, request map[string]interface{}, token string) (result *CreateRunResponse, err error)
 	Get(ctx context.Context, runID string, token string) (result *GetRunResponse, err error)
 	List(ctx context.Context, request *ListRunRequest, token string) (result *ListRunResponse, err error)
 	Stop(ctx context.Context, StopForce bool, runID, token string) (err error)
@@ -409,6 +453,7 @@ type RunInterface interface {
 	DeleteRunCache(ctx context.Context, runCacheID string, token string) (err error)
 
 	ListArtifact(ctx context.Context, request *ListArtifactRequest, token string) (result *ListArtifactResponse, err error)
+	DeleteArtifact(ctx context.Context, request *DeleteArtifactRequest, token string) (err error)
 }
 
 type RunGetter interface {
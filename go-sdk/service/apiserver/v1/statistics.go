@@ -0,0 +1,188 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/http/core"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/http/util/http"
+)
+
+const (
+	StatisticsApi = Prefix + "/statistics"
+	KeyStart      = "start"
+	KeyEnd        = "end"
+	KeyStep       = "step"
+	KeyGroupBy    = "groupBy"
+	KeyStartDate  = "startDate"
+	KeyEndDate    = "endDate"
+)
+
+type statistics struct {
+	client *core.PaddleFlowClient
+}
+
+// StatisticsRequest is the time range of metrics in unix seconds, and Step is the interval of metric sequences
+type StatisticsRequest struct {
+	Start int64
+	End   int64
+	Step  int64
+}
+
+type StatisticsResponse struct {
+	MetricsInfo map[string]string `json:"metricsInfo"`
+}
+
+type DetailStatisticsResponse struct {
+	Result    []TaskStatistics `json:"result"`
+	Truncated bool             `json:"truncated"`
+}
+
+type TaskStatistics struct {
+	TaskName string       `json:"taskName"`
+	TaskInfo []MetricInfo `json:"taskInfo"`
+}
+
+type MetricInfo struct {
+	MetricName string       `json:"metric"`
+	Values     [][2]float64 `json:"values"`
+}
+
+type UsageRequest struct {
+	GroupBy     string // user, queue or cluster
+	StartDate   string // such as 2022-01-01
+	EndDate     string
+	UserName    string
+	QueueName   string
+	ClusterName string
+}
+
+type UsageResponse struct {
+	GroupBy   string         `json:"groupBy"`
+	StartDate string         `json:"startDate"`
+	EndDate   string         `json:"endDate"`
+	Items     []UsageSummary `json:"items"`
+}
+
+type UsageSummary struct {
+	Date          string  `json:"date"`
+	Name          string  `json:"name"`
+	JobCount      int64   `json:"jobCount"`
+	Duration      float64 `json:"duration"`
+	CPUSeconds    float64 `json:"cpuSeconds"`
+	MemorySeconds float64 `json:"memorySeconds"`
+	GPUSeconds    float64 `json:"gpuSeconds"`
+}
+
+func (s *statistics) GetJob(ctx context.Context, jobID string, request *StatisticsRequest,
+	token string) (result *StatisticsResponse, err error) {
+	result = &StatisticsResponse{}
+	if err = s.newRequest(StatisticsApi+"/job/"+jobID, request, token).WithResult(result).Do(); err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (s *statistics) GetJobDetail(ctx context.Context, jobID string, request *StatisticsRequest,
+	token string) (result *DetailStatisticsResponse, err error) {
+	result = &DetailStatisticsResponse{}
+	if err = s.newRequest(StatisticsApi+"/jobDetail/"+jobID, request, token).WithResult(result).Do(); err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (s *statistics) GetQueue(ctx context.Context, queueName string, request *StatisticsRequest,
+	token string) (result *StatisticsResponse, err error) {
+	result = &StatisticsResponse{}
+	if err = s.newRequest(StatisticsApi+"/queue/"+queueName, request, token).WithResult(result).Do(); err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (s *statistics) GetQueueDetail(ctx context.Context, queueName string, request *StatisticsRequest,
+	token string) (result *DetailStatisticsResponse, err error) {
+	result = &DetailStatisticsResponse{}
+	if err = s.newRequest(StatisticsApi+"/queueDetail/"+queueName, request, token).WithResult(result).Do(); err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (s *statistics) GetUsage(ctx context.Context, request *UsageRequest,
+	token string) (result *UsageResponse, err error) {
+	result = &UsageResponse{}
+	err = core.NewRequestBuilder(s.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(StatisticsApi+"/usage").
+		WithMethod(http.GET).
+		WithQueryParamFilter(KeyGroupBy, request.GroupBy).
+		WithQueryParamFilter(KeyStartDate, request.StartDate).
+		WithQueryParamFilter(KeyEndDate, request.EndDate).
+		WithQueryParamFilter(KeyUser, request.UserName).
+		WithQueryParamFilter(KeyQueue, request.QueueName).
+		WithQueryParamFilter(KeyClusterName, request.ClusterName).
+		WithResult(result).
+		Do()
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// newRequest builds the request of metrics, and the zero values of time range are left to server
+func (s *statistics) newRequest(url string, request *StatisticsRequest, token string) *core.RequestBuilder {
+	builder := core.NewRequestBuilder(s.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(url).
+		WithMethod(http.GET)
+	if request == nil {
+		return builder
+	}
+	if request.Start != 0 {
+		builder.WithQueryParam(KeyStart, strconv.FormatInt(request.Start, 10))
+	}
+	if request.End != 0 {
+		builder.WithQueryParam(KeyEnd, strconv.FormatInt(request.End, 10))
+	}
+	if request.Step != 0 {
+		builder.WithQueryParam(KeyStep, strconv.FormatInt(request.Step, 10))
+	}
+	return builder
+}
+
+type StatisticsGetter interface {
+	Statistics() StatisticsInterface
+}
+
+type StatisticsInterface interface {
+	GetJob(ctx context.Context, jobID string, request *StatisticsRequest, token string) (*StatisticsResponse, error)
+	GetJobDetail(ctx context.Context, jobID string, request *StatisticsRequest, token string) (*DetailStatisticsResponse, error)
+	GetQueue(ctx context.Context, queueName string, request *StatisticsRequest, token string) (*StatisticsResponse, error)
+	GetQueueDetail(ctx context.Context, queueName string, request *StatisticsRequest, token string) (*DetailStatisticsResponse, error)
+	GetUsage(ctx context.Context, request *UsageRequest, token string) (*UsageResponse, error)
+}
+
+// newStatistics returns a statistics.
+func newStatistics(c *APIV1Client) *statistics {
+	return &statistics{
+		client: c.RESTClient(),
+	}
+}
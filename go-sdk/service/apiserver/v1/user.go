@@ -18,7 +18,10 @@ package v1
 
 import (
 	"context"
+	"strconv"
+	"time"
 
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/router/util"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/http/core"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/http/util/http"
@@ -27,6 +30,8 @@ import (
 const (
 	Prefix   = util.PaddleflowRouterPrefix + util.PaddleflowRouterVersionV1
 	LoginApi = Prefix + "/login"
+	UserApi  = Prefix + "/user"
+	KeyUser  = "user"
 )
 
 type user struct {
@@ -42,6 +47,29 @@ type LoginResponse struct {
 	Authorization string `json:"authorization"`
 }
 
+type CreateUserResponse struct {
+	UserName string `json:"username"`
+}
+
+type UpdateUserRequest struct {
+	Password string `json:"password"`
+}
+
+type ListUserRequest struct {
+	Marker  string
+	MaxKeys int
+}
+
+type UserInfo struct {
+	Name       string    `json:"name"`
+	CreateTime time.Time `json:"createTime"`
+}
+
+type ListUserResponse struct {
+	common.MarkerInfo
+	Users []UserInfo `json:"userList"`
+}
+
 func (u *user) Login(ctx context.Context, request *LoginInfo) (result *LoginResponse, err error) {
 	result = &LoginResponse{}
 	err = core.NewRequestBuilder(u.client).
@@ -53,12 +81,80 @@ func (u *user) Login(ctx context.Context, request *LoginInfo) (result *LoginResp
 	return
 }
 
+// Create creates user by root user, and LoginInfo holds the name and password of new user
+func (u *user) Create(ctx context.Context, request *LoginInfo, token string) (result *CreateUserResponse, err error) {
+	result = &CreateUserResponse{}
+	err = core.NewRequestBuilder(u.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(UserApi).
+		WithMethod(http.POST).
+		WithBody(request).
+		WithResult(result).
+		Do()
+	return
+}
+
+func (u *user) Get(ctx context.Context, userName, token string) (result *UserInfo, err error) {
+	result = &UserInfo{}
+	err = core.NewRequestBuilder(u.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(UserApi).
+		WithMethod(http.GET).
+		WithQueryParam(KeyUser, userName).
+		WithResult(result).
+		Do()
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (u *user) List(ctx context.Context, request *ListUserRequest, token string) (result *ListUserResponse, err error) {
+	result = &ListUserResponse{}
+	err = core.NewRequestBuilder(u.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(UserApi).
+		WithMethod(http.GET).
+		WithQueryParamFilter(KeyMarker, request.Marker).
+		WithQueryParamFilter(KeyMaxKeys, strconv.Itoa(request.MaxKeys)).
+		WithResult(result).
+		Do()
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (u *user) Update(ctx context.Context, userName string, request *UpdateUserRequest, token string) (err error) {
+	err = core.NewRequestBuilder(u.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(UserApi + "/" + userName).
+		WithMethod(http.PUT).
+		WithBody(request).
+		Do()
+	return
+}
+
+func (u *user) Delete(ctx context.Context, userName, token string) (err error) {
+	err = core.NewRequestBuilder(u.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(UserApi + "/" + userName).
+		WithMethod(http.DELETE).
+		Do()
+	return
+}
+
 type UserGetter interface {
 	User() UserInterface
 }
 
 type UserInterface interface {
 	Login(ctx context.Context, request *LoginInfo) (*LoginResponse, error)
+	Create(ctx context.Context, request *LoginInfo, token string) (*CreateUserResponse, error)
+	Get(ctx context.Context, userName string, token string) (*UserInfo, error)
+	List(ctx context.Context, request *ListUserRequest, token string) (*ListUserResponse, error)
+	Update(ctx context.Context, userName string, request *UpdateUserRequest, token string) error
+	Delete(ctx context.Context, userName string, token string) error
 }
 
 // newUsers returns a Users.
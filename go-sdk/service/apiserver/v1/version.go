@@ -0,0 +1,72 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1
+
+import (
+	"context"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/http/core"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/http/util/http"
+)
+
+const (
+	VersionApi = Prefix + "/version"
+)
+
+type version struct {
+	client *core.PaddleFlowClient
+}
+
+type VersionInfo struct {
+	GitVersion string `json:"gitVersion"`
+	GitCommit  string `json:"gitCommit"`
+	GitBranch  string `json:"gitBranch"`
+	BuildDate  string `json:"buildDate"`
+	GoVersion  string `json:"goVersion"`
+	Compiler   string `json:"compiler"`
+	Platform   string `json:"platform"`
+}
+
+// Get returns the build information of server
+func (v *version) Get(ctx context.Context, token string) (result *VersionInfo, err error) {
+	result = &VersionInfo{}
+	err = core.NewRequestBuilder(v.client).
+		WithHeader(common.HeaderKeyAuthorization, token).
+		WithURL(VersionApi).
+		WithMethod(http.GET).
+		WithResult(result).
+		Do()
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+type VersionGetter interface {
+	Version() VersionInterface
+}
+
+type VersionInterface interface {
+	Get(ctx context.Context, token string) (*VersionInfo, error)
+}
+
+// newVersion returns a version.
+func newVersion(c *APIV1Client) *version {
+	return &version{
+		client: c.RESTClient(),
+	}
+}
@@ -11,6 +11,7 @@ CREATE TABLE IF NOT EXISTS `cluster_info` (
     `cluster_type` varchar(32) NOT NULL DEFAULT '' COMMENT 'cluster type, e.g. Kubernetes/Local',
     `version` varchar(32) DEFAULT NULL COMMENT 'cluster version, e.g. v1.16',
     `status` varchar(32) NOT NULL DEFAULT 'online' COMMENT 'status in {online, offline}',
+    `status_reason` varchar(32) NOT NULL DEFAULT '' COMMENT 'reason of status, unhealthy means set offline by health checker',
     `credential` text DEFAULT NULL COMMENT 'cluster credential, e.g. kube config in k8s',
     `setting` text DEFAULT NULL COMMENT 'extra settings',
     `namespace_list` text DEFAULT NULL COMMENT 'json type，e.g. ["ns1", "ns2"]',
@@ -117,6 +118,27 @@ CREATE TABLE IF NOT EXISTS `job_task` (
     UNIQUE KEY `idx_id` (`id`)
 ) ENGINE=InnoDB DEFAULT CHARACTER SET utf8 COLLATE utf8_bin;
 
+CREATE TABLE IF NOT EXISTS `job_usage` (
+    `pk` bigint(20) NOT NULL AUTO_INCREMENT,
+    `job_id` varchar(60) NOT NULL,
+    `date` varchar(10) NOT NULL,
+    `user_name` varchar(60) NOT NULL,
+    `queue_id` varchar(60) NOT NULL,
+    `queue_name` varchar(255) DEFAULT '',
+    `cluster_id` varchar(60) DEFAULT '',
+    `cluster_name` varchar(255) DEFAULT '',
+    `job_type` varchar(20) DEFAULT '',
+    `status` varchar(32) DEFAULT '',
+    `duration` double NOT NULL DEFAULT 0 COMMENT 'seconds',
+    `cpu_seconds` double NOT NULL DEFAULT 0 COMMENT 'core * seconds',
+    `memory_seconds` double NOT NULL DEFAULT 0 COMMENT 'GiB * seconds',
+    `gpu_seconds` double NOT NULL DEFAULT 0 COMMENT 'card * seconds',
+    `created_at` datetime(3) DEFAULT NULL,
+    PRIMARY KEY (`pk`),
+    UNIQUE KEY `idx_job_date` (`job_id`, `date`),
+    INDEX `idx_date` (`date`)
+) ENGINE=InnoDB DEFAULT CHARACTER SET utf8 COLLATE utf8_bin;
+
 CREATE TABLE IF NOT EXISTS `user` (
     `pk` bigint(20) NOT NULL AUTO_INCREMENT,
     `name` VARCHAR(60) NOT NULL COMMENT 'unique identify',
@@ -274,6 +296,36 @@ CREATE TABLE IF NOT EXISTS `pipeline_version` (
     PRIMARY KEY (`pk`)
 ) ENGINE=InnoDB DEFAULT CHARACTER SET utf8 COLLATE utf8_bin;
 
+CREATE TABLE IF NOT EXISTS `job_template` (
+    `pk` bigint(20) NOT NULL AUTO_INCREMENT,
+    `id` varchar(60) NOT NULL,
+    `name` varchar(128) NOT NULL,
+    `desc` varchar(256) NOT NULL,
+    `user_name` varchar(60) NOT NULL,
+    `created_at` datetime(3) DEFAULT NULL,
+    `updated_at` datetime(3) DEFAULT NULL,
+    `deleted_at` datetime(3) DEFAULT NULL,
+    PRIMARY KEY (`pk`),
+    INDEX (`id`),
+    INDEX idx_template_name (`user_name`, `name`)
+) ENGINE=InnoDB DEFAULT CHARACTER SET utf8 COLLATE utf8_bin;
+
+CREATE TABLE IF NOT EXISTS `job_template_version` (
+    `pk` bigint(20) NOT NULL AUTO_INCREMENT,
+    `id` varchar(60) NOT NULL,
+    `template_id` varchar(60) NOT NULL,
+    `job_type` varchar(20) NOT NULL,
+    `parameters` text,
+    `template` text NOT NULL,
+    `template_md5` varchar(32) NOT NULL,
+    `user_name` varchar(60) NOT NULL,
+    `created_at` datetime(3) DEFAULT NULL,
+    `updated_at` datetime(3) DEFAULT NULL,
+    `deleted_at` datetime(3) DEFAULT NULL,
+    PRIMARY KEY (`pk`),
+    INDEX (`template_id`)
+) ENGINE=InnoDB DEFAULT CHARACTER SET utf8 COLLATE utf8_bin;
+
 CREATE TABLE IF NOT EXISTS `schedule` (
     `pk` bigint(20) NOT NULL AUTO_INCREMENT,
     `id` varchar(60) NOT NULL,
@@ -374,6 +426,88 @@ CREATE TABLE IF NOT EXISTS `link` (
     UNIQUE KEY (`id`)
     )ENGINE=InnoDB DEFAULT CHARACTER SET utf8 COLLATE utf8_bin ROW_FORMAT=COMPRESSED KEY_BLOCK_SIZE=8 COMMENT='file system';
 
+CREATE TABLE IF NOT EXISTS `fs_snapshot` (
+    `pk` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'pk',
+    `id` varchar(260) NOT NULL COMMENT 'snapshot id, fs id and snapshot name joined by dot',
+    `name` varchar(64) NOT NULL COMMENT 'snapshot name',
+    `fs_id` varchar(200) NOT NULL,
+    `fs_type` varchar(50) NOT NULL COMMENT 'file system type',
+    `user_name` varchar(256) NOT NULL,
+    `mode` varchar(32) NOT NULL COMMENT 'manifest, copy or native',
+    `status` varchar(32) NOT NULL,
+    `message` TEXT,
+    `file_count` bigint(20) NOT NULL DEFAULT 0,
+    `size` bigint(20) NOT NULL DEFAULT 0,
+    `created_at` datetime NOT NULL,
+    `updated_at` datetime NOT NULL,
+    PRIMARY KEY (`pk`),
+    UNIQUE KEY (`id`),
+    INDEX idx_fs_id (`fs_id`)
+    )ENGINE=InnoDB DEFAULT CHARACTER SET utf8 COLLATE utf8_bin COMMENT='file system snapshot';
+
+CREATE TABLE IF NOT EXISTS `fs_quota` (
+    `pk` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'pk',
+    `id` varchar(36) NOT NULL COMMENT 'quota id',
+    `fs_id` varchar(200) NOT NULL,
+    `path` varchar(255) NOT NULL COMMENT 'directory limited by quota, / is the whole file system',
+    `max_bytes` bigint(20) NOT NULL DEFAULT 0 COMMENT 'max bytes, 0 means no limit',
+    `max_inodes` bigint(20) NOT NULL DEFAULT 0 COMMENT 'max files and directories, 0 means no limit',
+    `created_at` datetime NOT NULL,
+    `updated_at` datetime NOT NULL,
+    PRIMARY KEY (`pk`),
+    UNIQUE KEY (`id`),
+    UNIQUE KEY idx_fs_path (`fs_id`, `path`)
+    )ENGINE=InnoDB DEFAULT CHARACTER SET utf8 COLLATE utf8_bin COMMENT='file system quota';
+
+CREATE TABLE IF NOT EXISTS `fs_sync_task` (
+    `pk` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'pk',
+    `id` varchar(36) NOT NULL COMMENT 'sync task id',
+    `user_name` varchar(256) NOT NULL,
+    `src_fs_id` varchar(200) NOT NULL,
+    `src_path` varchar(4096) NOT NULL,
+    `dst_fs_id` varchar(200) NOT NULL,
+    `dst_path` varchar(4096) NOT NULL,
+    `mode` varchar(32) NOT NULL COMMENT 'copy or sync',
+    `compare` varchar(32) NOT NULL COMMENT 'size-mtime or checksum',
+    `workers` int(11) NOT NULL DEFAULT 0,
+    `bandwidth_limit` bigint(20) NOT NULL DEFAULT 0 COMMENT 'bytes per second, 0 means no limit',
+    `dry_run` tinyint(1) NOT NULL DEFAULT 0,
+    `status` varchar(32) NOT NULL,
+    `message` TEXT,
+    `total` bigint(20) NOT NULL DEFAULT 0,
+    `copied` bigint(20) NOT NULL DEFAULT 0,
+    `skipped` bigint(20) NOT NULL DEFAULT 0,
+    `deleted` bigint(20) NOT NULL DEFAULT 0,
+    `failed` bigint(20) NOT NULL DEFAULT 0,
+    `copied_bytes` bigint(20) NOT NULL DEFAULT 0,
+    `checkpoint` TEXT COMMENT 'last transferred path, before which all paths are done',
+    `diff` MEDIUMTEXT COMMENT 'differences of dry run',
+    `created_at` datetime NOT NULL,
+    `updated_at` datetime NOT NULL,
+    PRIMARY KEY (`pk`),
+    UNIQUE KEY (`id`),
+    INDEX idx_user_name (`user_name`),
+    INDEX idx_status (`status`)
+    )ENGINE=InnoDB DEFAULT CHARACTER SET utf8 COLLATE utf8_bin COMMENT='file system data sync task';
+
+CREATE TABLE IF NOT EXISTS `fs_audit_log` (
+    `pk` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'pk',
+    `fs_id` varchar(200) NOT NULL COMMENT 'file system id',
+    `user_name` varchar(60) NOT NULL COMMENT 'user who performed the operation',
+    `job_id` varchar(60) DEFAULT NULL COMMENT 'job which accessed files through fuse client',
+    `operation` varchar(32) NOT NULL COMMENT 'e.g. createFs/grant/stsToken/open/unlink',
+    `path` varchar(4096) DEFAULT NULL COMMENT 'file or link path',
+    `detail` varchar(1024) DEFAULT NULL,
+    `source` varchar(32) NOT NULL COMMENT 'server or fuse',
+    `host` varchar(256) DEFAULT NULL COMMENT 'node of fuse client',
+    `created_at` datetime NOT NULL COMMENT 'time when the log is saved by server',
+    `occurred_at` datetime DEFAULT NULL COMMENT 'time reported by fuse client when the access happened',
+    PRIMARY KEY (`pk`),
+    INDEX idx_fs_id (`fs_id`),
+    INDEX idx_user_name (`user_name`),
+    INDEX idx_created_at (`created_at`)
+    )ENGINE=InnoDB DEFAULT CHARACTER SET utf8 COLLATE utf8_bin COMMENT='append-only file system audit log';
+
 CREATE TABLE IF NOT EXISTS `fs_cache_config` (
     `pk` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'pk',
     `fs_id` varchar(200) NOT NULL COMMENT 'file system id',
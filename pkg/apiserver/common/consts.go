@@ -22,12 +22,14 @@ const (
 	PrefixSchedule   = "schedule-"
 	PrefixRun        = "run-"
 	PrefixPipeline   = "ppl-"
+	PrefixTemplate   = "jobtpl-"
 	PrefixCache      = "cch-"
 	PrefixGrant      = "grant"
 	PrefixQueue      = "queue"
 	PrefixCluster    = "cluster"
 	PrefixFlavour    = "flavour"
 	PrefixConnection = "conn"
+	PrefixFsSync     = "fssync"
 
 	ResourceTypeSchedule      = "schedule"
 	ResourceTypeRun           = "run"
@@ -41,6 +43,7 @@ const (
 	ResourceTypePipeline      = "pipeline"
 	ResourceTypeCluster       = "cluster"
 	ResourceTypeJob           = "job"
+	ResourceTypeJobTemplate   = "job_template"
 
 	HeaderKeyRequestID     = "x-pf-request-id"
 	HeaderKeyUserName      = "x-pf-user-name"
@@ -63,7 +66,8 @@ const (
 	LogPageSizeDefault = 100
 	LogPageNoDefault   = 1
 
-	Pod = "pod"
+	Pod   = "pod"
+	Queue = "queue"
 
 	StsMaxSeqData = 1000
 
@@ -83,6 +83,7 @@ const (
 	RunNameDuplicated     = "RunNameDuplicated"
 	RunNotFound           = "RunNotFound"
 	PipelineNotFound      = "PipelineNotFound"
+	JobTemplateNotFound   = "JobTemplateNotFound"
 	RunCacheNotFound      = "RunCacheNotFound"
 	ArtifactEventNotFound = "ArtifactEventNotFound"
 	ReadYamlFileFailed    = "ReadYamlFileFailed"
@@ -128,6 +129,18 @@ const (
 	InvalidPVClaimsParams       = "InvalidPVClaimsParams"
 	GetNamespaceFail            = "GetNamespaceFail"
 	LinkMetaPersistError        = "LinkMetaPersistError"
+	InvalidFsFilePath           = "InvalidFsFilePath"
+	FsFileNotFound              = "FsFileNotFound"
+	FsFileAlreadyExist          = "FsFileAlreadyExist"
+	FsFileOperationFailed       = "FsFileOperationFailed"
+	InvalidFsSnapshotName       = "InvalidFsSnapshotName"
+	FsSnapshotNotSupported      = "FsSnapshotNotSupported"
+	FsSnapshotNotFound          = "FsSnapshotNotFound"
+	FsSnapshotAlreadyExist      = "FsSnapshotAlreadyExist"
+	InvalidFsQuota              = "InvalidFsQuota"
+	FsQuotaNotFound             = "FsQuotaNotFound"
+	InvalidFsSyncTask           = "InvalidFsSyncTask"
+	FsSyncTaskNotFound          = "FsSyncTaskNotFound"
 )
 
 var errorHTTPStatus = map[string]int{
@@ -183,6 +196,7 @@ var errorHTTPStatus = map[string]int{
 	RunNameDuplicated:     http.StatusBadRequest,
 	RunNotFound:           http.StatusNotFound,
 	PipelineNotFound:      http.StatusNotFound,
+	JobTemplateNotFound:   http.StatusNotFound,
 	RunCacheNotFound:      http.StatusNotFound,
 	ScheduleNotFound:      http.StatusNotFound,
 	ArtifactEventNotFound: http.StatusNotFound,
@@ -231,6 +245,18 @@ var errorHTTPStatus = map[string]int{
 	InvalidPVClaimsParams:       http.StatusBadRequest,
 	GetNamespaceFail:            http.StatusInternalServerError,
 	LinkMetaPersistError:        http.StatusBadRequest,
+	InvalidFsFilePath:           http.StatusBadRequest,
+	FsFileNotFound:              http.StatusNotFound,
+	FsFileAlreadyExist:          http.StatusConflict,
+	FsFileOperationFailed:       http.StatusInternalServerError,
+	InvalidFsSnapshotName:       http.StatusBadRequest,
+	FsSnapshotNotSupported:      http.StatusBadRequest,
+	FsSnapshotNotFound:          http.StatusNotFound,
+	FsSnapshotAlreadyExist:      http.StatusConflict,
+	InvalidFsQuota:              http.StatusBadRequest,
+	FsQuotaNotFound:             http.StatusNotFound,
+	InvalidFsSyncTask:           http.StatusBadRequest,
+	FsSyncTaskNotFound:          http.StatusNotFound,
 }
 
 var errorMessage = map[string]string{
@@ -285,6 +311,7 @@ var errorMessage = map[string]string{
 	RunNameDuplicated:     "Run name already exists",
 	RunNotFound:           "RunID not found",
 	PipelineNotFound:      "Pipeline not found",
+	JobTemplateNotFound:   "Job template not found",
 	RunCacheNotFound:      "RunCache not found",
 	ArtifactEventNotFound: "ArtifactEvent not found",
 	ReadYamlFileFailed:    "Read yaml file failed",
@@ -326,6 +353,18 @@ var errorMessage = map[string]string{
 	ConnectivityFailed:         "Connectivity failed",
 	InvalidPVClaimsParams:      "Invalid persistent volume claims params",
 	GetNamespaceFail:           "Get namespace fail",
+	InvalidFsFilePath:          "File path in file system is invalid",
+	FsFileNotFound:             "File not found in file system",
+	FsFileAlreadyExist:         "File already exists in file system",
+	FsFileOperationFailed:      "File operation in file system failed",
+	InvalidFsSnapshotName:      "Snapshot name must be a DNS-1123 label",
+	FsSnapshotNotSupported:     "Snapshot is not supported by the type of file system",
+	FsSnapshotNotFound:         "Snapshot of file system not found",
+	FsSnapshotAlreadyExist:     "Snapshot of file system already exists",
+	InvalidFsQuota:             "Quota of file system is invalid",
+	FsQuotaNotFound:            "Quota of file system not found",
+	InvalidFsSyncTask:          "Sync task of file system is invalid",
+	FsSyncTaskNotFound:         "Sync task of file system not found",
 }
 
 type ErrorResponse struct {
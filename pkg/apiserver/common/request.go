@@ -24,6 +24,7 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/logger"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/tracing"
 )
 
 func GetRequestContext(r *http.Request) logger.RequestContext {
@@ -31,8 +32,9 @@ func GetRequestContext(r *http.Request) logger.RequestContext {
 	userName := r.Header.Get(HeaderKeyUserName)
 	log.Debugf("GetRequestContext requestID:[%s] userName:[%s]", requestID, userName)
 	return logger.RequestContext{
-		RequestID: requestID,
-		UserName:  userName,
+		RequestID:   requestID,
+		UserName:    userName,
+		TraceParent: r.Header.Get(tracing.HeaderTraceParent),
 	}
 }
 
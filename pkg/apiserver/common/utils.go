@@ -120,6 +120,15 @@ func ID(userName, fsName string) string {
 	return FsPrefix + userName + "-" + fsName
 }
 
+// FsOrSnapshotID returns the id of fs, or the id of snapshot if name is like fsName@snapshot
+func FsOrSnapshotID(userName, name string) string {
+	fsName, snapshot := schema.ParseFsSnapshotName(name)
+	if snapshot == "" {
+		return ID(userName, fsName)
+	}
+	return schema.FsSnapshotID(ID(userName, fsName), snapshot)
+}
+
 // InformationFromURL get fs system information from url
 func InformationFromURL(url string, properties map[string]string) (fileSystemType, serverAddress, subPath string) {
 	fileSystemType = strings.Split(url, ":")[TypeSplit]
@@ -31,6 +31,7 @@ import (
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/logger"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/uuid"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/health"
 	runtime "github.com/PaddlePaddle/PaddleFlow/pkg/job/runtime_v2"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
@@ -61,6 +62,13 @@ type GetClusterResponse struct {
 	model.ClusterInfo
 }
 
+type GetClusterHealthResponse struct {
+	ClusterName string                 `json:"clusterName"`
+	Status      string                 `json:"status"`
+	Healthy     bool                   `json:"healthy"`
+	History     []schema.ClusterHealth `json:"history"`
+}
+
 type ListClusterRequest struct {
 	Marker          string   `json:"marker"`
 	MaxKeys         int      `json:"maxKeys"`
@@ -378,6 +386,29 @@ func GetCluster(ctx *logger.RequestContext, clusterName string) (*GetClusterResp
 	return &GetClusterResponse{clusterInfo}, nil
 }
 
+// GetClusterHealth returns the health history of cluster probed by cluster health checker
+func GetClusterHealth(ctx *logger.RequestContext, clusterName string) (*GetClusterHealthResponse, error) {
+	if !common.IsRootUser(ctx.UserName) {
+		ctx.ErrorCode = common.OnlyRootAllowed
+		ctx.Logging().Errorln("get cluster health failed. error: admin is needed.")
+		return nil, errors.New("get cluster health failed")
+	}
+
+	clusterInfo, err := storage.Cluster.GetClusterByName(clusterName)
+	if err != nil {
+		ctx.ErrorCode = common.ClusterNotFound
+		ctx.ErrorMessage = err.Error()
+		ctx.Logging().Errorf("get cluster health failed. clusterName:[%s]", clusterName)
+		return nil, err
+	}
+	return &GetClusterHealthResponse{
+		ClusterName: clusterInfo.Name,
+		Status:      clusterInfo.Status,
+		Healthy:     health.Checker.IsHealthy(clusterInfo.ID),
+		History:     health.Checker.History(clusterInfo.ID),
+	}, nil
+}
+
 func DeleteCluster(ctx *logger.RequestContext, clusterName string) error {
 	if !common.IsRootUser(ctx.UserName) {
 		ctx.ErrorCode = common.OnlyRootAllowed
@@ -450,6 +481,15 @@ func UpdateCluster(ctx *logger.RequestContext,
 		ctx.Logging().Errorf("delete cluster failed. clusterName:[%s]", clusterName)
 		return nil, err
 	}
+	// status set by administrator is not switched by health checker any more
+	if request.Status != "" && clusterInfo.StatusReason != "" {
+		clusterInfo.StatusReason = ""
+		if err := storage.Cluster.UpdateClusterStatus(clusterInfo.ID, clusterInfo.Status, ""); err != nil {
+			ctx.ErrorMessage = err.Error()
+			ctx.Logging().Errorf("update cluster status failed. clusterName:[%s]", clusterName)
+			return nil, err
+		}
+	}
 	response := UpdateClusterReponse{clusterInfo}
 	return &response, nil
 }
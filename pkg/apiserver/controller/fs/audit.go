@@ -0,0 +1,185 @@
+/*
+Copyright (c) 2021 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fs
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/logger"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
+)
+
+const (
+	auditTimeLayout = "2006-01-02 15:04:05"
+	// maxAuditEventsPerReport limits the events in one report of fuse client
+	maxAuditEventsPerReport = 1000
+)
+
+type FsAuditEvent struct {
+	Operation string `json:"operation"`
+	Path      string `json:"path"`
+	JobID     string `json:"jobID"`
+	Uid       uint32 `json:"uid"`
+	Host      string `json:"host"`
+	// Timestamp is the unix seconds when the event happened on client, which is kept as reference only
+	Timestamp int64 `json:"timestamp"`
+}
+
+type ReportFsAuditRequest struct {
+	FsName   string         `json:"fsName"`
+	Username string         `json:"username"`
+	Events   []FsAuditEvent `json:"events"`
+}
+
+type ListFsAuditLogRequest struct {
+	FsName    string `json:"fsName"`
+	Username  string `json:"username"`
+	JobID     string `json:"jobID"`
+	Operation string `json:"operation"`
+	StartTime string `json:"startTime"`
+	EndTime   string `json:"endTime"`
+	Marker    string `json:"marker"`
+	MaxKeys   int    `json:"maxKeys"`
+}
+
+type ListFsAuditLogResponse struct {
+	common.MarkerInfo
+	AuditLogList []model.FsAuditLog `json:"auditLogList"`
+}
+
+// RecordFsAudit appends an audit log of the operation done by request user on server. Failure of recording does
+// not fail the operation, which has been done.
+func RecordFsAudit(ctx *logger.RequestContext, fsID, operation, path, detail string) {
+	auditLog := model.FsAuditLog{
+		FsID:      fsID,
+		UserName:  ctx.UserName,
+		Operation: operation,
+		Path:      path,
+		Detail:    detail,
+		Source:    schema.FsAuditSourceServer,
+	}
+	if err := storage.Filesystem.CreateFsAuditLogs([]model.FsAuditLog{auditLog}); err != nil {
+		ctx.Logging().Errorf("record fs[%s] audit log of operation[%s] failed: %v", fsID, operation, err)
+	}
+}
+
+// ReportFsAudit saves the sampled file access events reported by fuse client. The logs are timed by server, as
+// the clock of client is not trusted.
+func ReportFsAudit(ctx *logger.RequestContext, req *ReportFsAuditRequest) error {
+	if len(req.Events) > maxAuditEventsPerReport {
+		ctx.ErrorCode = common.InvalidArguments
+		return fmt.Errorf("report at most %d audit events once, got %d", maxAuditEventsPerReport, len(req.Events))
+	}
+	// accesses of snapshot are recorded under its file system
+	fsName, _ := schema.ParseFsSnapshotName(req.FsName)
+	fsID := common.ID(req.Username, fsName)
+	logs := make([]model.FsAuditLog, 0, len(req.Events))
+	for _, event := range req.Events {
+		if !schema.IsFuseAuditOp(event.Operation) {
+			ctx.ErrorCode = common.InvalidArguments
+			return fmt.Errorf("audit operation[%s] can not be reported by fuse client", event.Operation)
+		}
+		auditLog := model.FsAuditLog{
+			FsID:      fsID,
+			UserName:  ctx.UserName,
+			JobID:     event.JobID,
+			Operation: event.Operation,
+			Path:      event.Path,
+			Detail:    fmt.Sprintf("uid=%d", event.Uid),
+			Source:    schema.FsAuditSourceFuse,
+			Host:      event.Host,
+		}
+		if event.Timestamp > 0 {
+			occurredAt := time.Unix(event.Timestamp, 0)
+			auditLog.OccurredAt = &occurredAt
+		}
+		logs = append(logs, auditLog)
+	}
+	if err := storage.Filesystem.CreateFsAuditLogs(logs); err != nil {
+		ctx.Logging().Errorf("save fs[%s] audit logs failed: %v", fsID, err)
+		ctx.ErrorCode = common.FileSystemDataBaseError
+		return err
+	}
+	return nil
+}
+
+// ListFsAuditLog lists audit logs in order of occurrence, root can list all and others can only list the file
+// systems owned by themselves
+func ListFsAuditLog(ctx *logger.RequestContext, req *ListFsAuditLogRequest) (*ListFsAuditLogResponse, error) {
+	filter := model.FsAuditLogFilter{JobID: req.JobID}
+	if req.FsName != "" {
+		filter.FsID = common.ID(req.Username, req.FsName)
+	} else if !common.IsRootUser(ctx.UserName) {
+		ctx.ErrorCode = common.InvalidArguments
+		return nil, fmt.Errorf("fsName is required for non-root user")
+	}
+	if req.Operation != "" {
+		filter.Operations = strings.Split(req.Operation, common.SeparatorComma)
+	}
+	var err error
+	if filter.StartTime, err = parseAuditTime(req.StartTime); err != nil {
+		ctx.ErrorCode = common.InvalidArguments
+		return nil, err
+	}
+	if filter.EndTime, err = parseAuditTime(req.EndTime); err != nil {
+		ctx.ErrorCode = common.InvalidArguments
+		return nil, err
+	}
+	var pk int64
+	if req.Marker != "" {
+		if pk, err = common.DecryptPk(req.Marker); err != nil {
+			ctx.ErrorCode = common.InvalidMarker
+			return nil, fmt.Errorf("invalid marker[%s]: %v", req.Marker, err)
+		}
+	}
+
+	// list one more to know whether there are more logs
+	logs, err := storage.Filesystem.ListFsAuditLog(pk, req.MaxKeys+1, filter)
+	if err != nil {
+		ctx.Logging().Errorf("list audit logs with filter[%+v] failed: %v", filter, err)
+		ctx.ErrorCode = common.FileSystemDataBaseError
+		return nil, err
+	}
+	response := &ListFsAuditLogResponse{AuditLogList: logs}
+	response.MaxKeys = req.MaxKeys
+	if len(logs) > req.MaxKeys {
+		response.AuditLogList = logs[:req.MaxKeys]
+		response.IsTruncated = true
+		response.NextMarker, err = common.EncryptPk(logs[req.MaxKeys-1].Pk)
+		if err != nil {
+			ctx.ErrorCode = common.InternalError
+			return nil, err
+		}
+	}
+	return response, nil
+}
+
+func parseAuditTime(t string) (time.Time, error) {
+	if t == "" {
+		return time.Time{}, nil
+	}
+	parsed, err := time.ParseInLocation(auditTimeLayout, t, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time[%s], format should be %s", t, auditTimeLayout)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,484 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/bluele/gcache"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/logger"
+	fuse "github.com/PaddlePaddle/PaddleFlow/pkg/fs/client/fs"
+	fsUtils "github.com/PaddlePaddle/PaddleFlow/pkg/fs/client/utils"
+	fsCommon "github.com/PaddlePaddle/PaddleFlow/pkg/fs/common"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/utils"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
+)
+
+type FileInfo struct {
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+	IsDir   bool   `json:"isDir"`
+	Mode    string `json:"mode"`
+	ModTime string `json:"modTime"`
+}
+
+type ListFilesRequest struct {
+	Path    string `json:"path"`
+	Marker  string `json:"marker"`
+	MaxKeys int32  `json:"maxKeys"`
+}
+
+type ListFilesResponse struct {
+	Path       string      `json:"path"`
+	Marker     string      `json:"marker"`
+	Truncated  bool        `json:"truncated"`
+	NextMarker string      `json:"nextMarker"`
+	FileList   []*FileInfo `json:"fileList"`
+}
+
+type CreateDirRequest struct {
+	Path string `json:"path"`
+}
+
+type RenameFileRequest struct {
+	SrcPath string `json:"srcPath"`
+	DstPath string `json:"dstPath"`
+}
+
+type UploadFilesResponse struct {
+	FileList []*FileInfo `json:"fileList"`
+}
+
+const fsClientCacheSize = 100
+
+// fsClients caches the clients of fs, so that file apis do not build a client for every request
+var fsClients = newFsClientCache(fsClientCacheSize, fuse.NewFSClient)
+
+// NewFsClient returns a client to the files of fs, may be replaced in unit tests.
+// The client is shared by requests of the fs, and Close only releases it for the caller.
+var NewFsClient = func(fsID string) (fuse.FSClient, error) {
+	fs, err := storage.Filesystem.GetFileSystemOrSnapshot(fsID)
+	if err != nil {
+		log.Errorf("get filesystem[%s] err: %v", fsID, err)
+		return nil, err
+	}
+	links, err := storage.Filesystem.FsNameLinks(fsID)
+	if err != nil {
+		log.Errorf("get links of filesystem[%s] err: %v", fsID, err)
+		return nil, err
+	}
+	linksMeta, err := linksMetaFromModel(links)
+	if err != nil {
+		return nil, err
+	}
+	return fsClients.get(fsMetaFromModel(fs), linksMeta)
+}
+
+// fsClientCache keeps one client for each fs. The client is rebuilt when the meta of fs or its links changes,
+// and the replaced or evicted client is closed after all of its callers release it.
+type fsClientCache struct {
+	lock      sync.Mutex
+	clients   gcache.Cache
+	newClient func(fsMeta fsCommon.FSMeta, links map[string]fsCommon.FSMeta) (fuse.FSClient, error)
+}
+
+type cachedFsClient struct {
+	fuse.FSClient
+	// version is the digest of fs meta and links, which the client is built with
+	version string
+	refs    int
+	evicted bool
+}
+
+// fsClientRef is the client returned to caller, closing it releases the reference to cached client
+type fsClientRef struct {
+	fuse.FSClient
+	cache  *fsClientCache
+	client *cachedFsClient
+	once   sync.Once
+}
+
+func newFsClientCache(size int, newClient func(fsCommon.FSMeta, map[string]fsCommon.FSMeta) (fuse.FSClient, error)) *fsClientCache {
+	c := &fsClientCache{newClient: newClient}
+	// evicted func is called with the lock of cache held
+	c.clients = gcache.New(size).LRU().EvictedFunc(func(key, value interface{}) {
+		client := value.(*cachedFsClient)
+		client.evicted = true
+		c.closeIfReleased(client)
+	}).Build()
+	return c
+}
+
+func (c *fsClientCache) get(fsMeta fsCommon.FSMeta, links map[string]fsCommon.FSMeta) (fuse.FSClient, error) {
+	version, err := fsMetaVersion(fsMeta, links)
+	if err != nil {
+		return nil, err
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	var client *cachedFsClient
+	if value, err := c.clients.Get(fsMeta.ID); err == nil && value.(*cachedFsClient).version == version {
+		client = value.(*cachedFsClient)
+	} else {
+		fsClient, err := c.newClient(fsMeta, links)
+		if err != nil {
+			return nil, err
+		}
+		log.Infof("build client of fs[%s]", fsMeta.ID)
+		client = &cachedFsClient{FSClient: fsClient, version: version}
+		// gcache replaces the value of key without calling evicted func, so the old client is removed first
+		c.clients.Remove(fsMeta.ID)
+		if err = c.clients.Set(fsMeta.ID, client); err != nil {
+			return nil, err
+		}
+	}
+	client.refs++
+	return &fsClientRef{FSClient: client.FSClient, cache: c, client: client}, nil
+}
+
+func (c *fsClientCache) release(client *cachedFsClient) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	client.refs--
+	c.closeIfReleased(client)
+}
+
+func (c *fsClientCache) closeIfReleased(client *cachedFsClient) {
+	if !client.evicted || client.refs > 0 {
+		return
+	}
+	if err := client.FSClient.Close(); err != nil {
+		log.Errorf("close client of fs err: %v", err)
+	}
+}
+
+func (r *fsClientRef) Close() error {
+	r.once.Do(func() {
+		r.cache.release(r.client)
+	})
+	return nil
+}
+
+// fsMetaVersion returns the digest of fs meta and links, which changes when any of them is updated
+func fsMetaVersion(fsMeta fsCommon.FSMeta, links map[string]fsCommon.FSMeta) (string, error) {
+	data, err := json.Marshal([]interface{}{fsMeta, links})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func fsMetaFromModel(fs model.FileSystem) fsCommon.FSMeta {
+	return fsCommon.FSMeta{
+		ID:            fs.ID,
+		Name:          fs.Name,
+		UfsType:       fs.Type,
+		ServerAddress: fs.ServerAddress,
+		SubPath:       fs.SubPath,
+		Properties:    fs.PropertiesMap,
+		Type:          fsCommon.FSType,
+	}
+}
+
+func linksMetaFromModel(links []model.Link) (map[string]fsCommon.FSMeta, error) {
+	linksMeta := make(map[string]fsCommon.FSMeta)
+	for _, link := range links {
+		fsName, _, err := utils.GetFsNameAndUserNameByFsID(link.FsID)
+		if err != nil {
+			return nil, err
+		}
+		linksMeta[link.FsPath] = fsCommon.FSMeta{
+			ID:            link.ID,
+			Name:          fsName,
+			UfsType:       link.Type,
+			ServerAddress: link.ServerAddress,
+			SubPath:       link.SubPath,
+			Properties:    link.PropertiesMap,
+			Type:          fsCommon.LinkType,
+		}
+	}
+	return linksMeta, nil
+}
+
+// GetFsClient checks that the request user can access fsID and returns a client to its files
+func (s *FileSystemService) GetFsClient(ctx *logger.RequestContext, fsID string) (fuse.FSClient, error) {
+	if err := s.checkFsAccess(ctx, fsID); err != nil {
+		return nil, err
+	}
+	client, err := NewFsClient(fsID)
+	if err != nil {
+		ctx.Logging().Errorf("new client of fs[%s] err: %v", fsID, err)
+		ctx.ErrorCode = common.FuseClientError
+		return nil, err
+	}
+	return client, nil
+}
+
+// checkFsAccess checks the user of request has permission to the fs
+func (s *FileSystemService) checkFsAccess(ctx *logger.RequestContext, fsID string) error {
+	hasPermission, err := s.HasFsPermission(ctx.UserName, fsID)
+	if err != nil {
+		ctx.Logging().Errorf("check permission of user[%s] to fs[%s] err: %v", ctx.UserName, fsID, err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.ErrorCode = common.RecordNotFound
+			return fmt.Errorf("fs[%s] not exist", fsID)
+		}
+		ctx.ErrorCode = common.FileSystemDataBaseError
+		return err
+	}
+	if !hasPermission {
+		ctx.ErrorCode = common.AccessDenied
+		return fmt.Errorf("user[%s] has no permission to fs[%s]", ctx.UserName, fsID)
+	}
+	return nil
+}
+
+// CleanFilePath normalizes a file path of fs, paths escaping the fs root are rejected
+func CleanFilePath(ctx *logger.RequestContext, filePath string) (string, error) {
+	if strings.Contains(filePath, "\x00") {
+		ctx.ErrorCode = common.InvalidFsFilePath
+		return "", fmt.Errorf("path[%s] is invalid", filePath)
+	}
+	for _, elem := range strings.Split(filePath, "/") {
+		if elem == ".." {
+			ctx.ErrorCode = common.InvalidFsFilePath
+			return "", fmt.Errorf("path[%s] must not contain '..'", filePath)
+		}
+	}
+	return strings.TrimPrefix(path.Clean("/"+filePath), "/"), nil
+}
+
+func (s *FileSystemService) StatFile(ctx *logger.RequestContext, client fuse.FSClient, filePath string) (*FileInfo, error) {
+	info, err := client.Stat(filePath)
+	if err != nil {
+		ctx.Logging().Errorf("stat file[%s] err: %v", filePath, err)
+		setFileErrorCode(ctx, err)
+		return nil, err
+	}
+	return fileInfoFromOS(filePath, info), nil
+}
+
+// ListFiles lists the entries of a directory ordered by name, marker is the last name of the previous page
+func (s *FileSystemService) ListFiles(ctx *logger.RequestContext, client fuse.FSClient, req *ListFilesRequest) (*ListFilesResponse, error) {
+	isDir, err := client.IsDir(req.Path)
+	if err != nil {
+		ctx.Logging().Errorf("stat dir[%s] err: %v", req.Path, err)
+		setFileErrorCode(ctx, err)
+		return nil, err
+	}
+	if !isDir {
+		ctx.ErrorCode = common.InvalidFsFilePath
+		return nil, fmt.Errorf("path[%s] is not a directory", req.Path)
+	}
+	infos, err := client.ListDir(req.Path)
+	if err != nil {
+		ctx.Logging().Errorf("list dir[%s] err: %v", req.Path, err)
+		setFileErrorCode(ctx, err)
+		return nil, err
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name() < infos[j].Name()
+	})
+	start := sort.Search(len(infos), func(i int) bool {
+		return infos[i].Name() > req.Marker
+	})
+	infos = infos[start:]
+
+	resp := &ListFilesResponse{
+		Path:     req.Path,
+		Marker:   req.Marker,
+		FileList: []*FileInfo{},
+	}
+	if req.MaxKeys > 0 && len(infos) > int(req.MaxKeys) {
+		infos = infos[:req.MaxKeys]
+		resp.Truncated = true
+		resp.NextMarker = infos[len(infos)-1].Name()
+	}
+	for _, info := range infos {
+		resp.FileList = append(resp.FileList, fileInfoFromOS(path.Join(req.Path, info.Name()), info))
+	}
+	return resp, nil
+}
+
+// OpenFile opens a regular file for download
+func (s *FileSystemService) OpenFile(ctx *logger.RequestContext, client fuse.FSClient, filePath string) (io.ReadSeekCloser, *FileInfo, error) {
+	info, err := s.StatFile(ctx, client, filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	if info.IsDir {
+		ctx.ErrorCode = common.InvalidFsFilePath
+		return nil, nil, fmt.Errorf("path[%s] is a directory", filePath)
+	}
+	reader, err := client.Open(filePath)
+	if err != nil {
+		ctx.Logging().Errorf("open file[%s] err: %v", filePath, err)
+		setFileErrorCode(ctx, err)
+		return nil, nil, err
+	}
+	file, ok := reader.(io.ReadSeekCloser)
+	if !ok {
+		reader.Close()
+		ctx.ErrorCode = common.FsFileOperationFailed
+		return nil, nil, fmt.Errorf("file[%s] is not seekable", filePath)
+	}
+	return file, info, nil
+}
+
+// UploadFile writes content to dir/fileName, an existing file is replaced only if overwrite is set
+func (s *FileSystemService) UploadFile(ctx *logger.RequestContext, client fuse.FSClient, dir, fileName string,
+	content io.Reader, overwrite bool) (*FileInfo, error) {
+	if fileName == "" || fileName == "." || fileName == ".." || strings.ContainsAny(fileName, "/\x00") {
+		ctx.ErrorCode = common.InvalidFsFilePath
+		return nil, fmt.Errorf("file name[%s] is invalid", fileName)
+	}
+	filePath := path.Join(dir, fileName)
+	exist, err := client.Exist(filePath)
+	if err != nil {
+		ctx.Logging().Errorf("check file[%s] exist err: %v", filePath, err)
+		setFileErrorCode(ctx, err)
+		return nil, err
+	}
+	if exist && !overwrite {
+		ctx.ErrorCode = common.FsFileAlreadyExist
+		return nil, fmt.Errorf("file[%s] already exists", filePath)
+	}
+	if err = client.MkdirAll(dir, os.ModePerm); err != nil {
+		ctx.Logging().Errorf("mkdir[%s] err: %v", dir, err)
+		setFileErrorCode(ctx, err)
+		return nil, err
+	}
+	if err = client.SaveFile(content, dir, fileName); err != nil {
+		ctx.Logging().Errorf("save file[%s] err: %v", filePath, err)
+		setFileErrorCode(ctx, err)
+		return nil, err
+	}
+	return s.StatFile(ctx, client, filePath)
+}
+
+func (s *FileSystemService) CreateDir(ctx *logger.RequestContext, client fuse.FSClient, dir string) error {
+	if dir == "" {
+		ctx.ErrorCode = common.InvalidFsFilePath
+		return fmt.Errorf("root directory already exists")
+	}
+	if err := client.MkdirAll(dir, os.ModePerm); err != nil {
+		ctx.Logging().Errorf("mkdir[%s] err: %v", dir, err)
+		setFileErrorCode(ctx, err)
+		return err
+	}
+	return nil
+}
+
+func (s *FileSystemService) RenameFile(ctx *logger.RequestContext, client fuse.FSClient, srcPath, dstPath string) error {
+	if srcPath == "" || dstPath == "" {
+		ctx.ErrorCode = common.InvalidFsFilePath
+		return fmt.Errorf("root directory can not be renamed")
+	}
+	if _, err := client.Stat(srcPath); err != nil {
+		ctx.Logging().Errorf("stat file[%s] err: %v", srcPath, err)
+		setFileErrorCode(ctx, err)
+		return err
+	}
+	exist, err := client.Exist(dstPath)
+	if err != nil {
+		setFileErrorCode(ctx, err)
+		return err
+	}
+	if exist {
+		ctx.ErrorCode = common.FsFileAlreadyExist
+		return fmt.Errorf("file[%s] already exists", dstPath)
+	}
+	if err = client.Rename(srcPath, dstPath); err != nil {
+		ctx.Logging().Errorf("rename file[%s] to [%s] err: %v", srcPath, dstPath, err)
+		setFileErrorCode(ctx, err)
+		return err
+	}
+	return nil
+}
+
+// DeleteFile removes a file or an empty directory, non-empty directories need recursive
+func (s *FileSystemService) DeleteFile(ctx *logger.RequestContext, client fuse.FSClient, filePath string, recursive bool) error {
+	if filePath == "" {
+		ctx.ErrorCode = common.InvalidFsFilePath
+		return fmt.Errorf("root directory can not be deleted")
+	}
+	if _, err := client.Stat(filePath); err != nil {
+		ctx.Logging().Errorf("stat file[%s] err: %v", filePath, err)
+		setFileErrorCode(ctx, err)
+		return err
+	}
+	var err error
+	if recursive {
+		err = client.RemoveAll(filePath)
+	} else {
+		err = client.Remove(filePath)
+	}
+	if err != nil {
+		ctx.Logging().Errorf("delete file[%s] recursive[%v] err: %v", filePath, recursive, err)
+		setFileErrorCode(ctx, err)
+		return err
+	}
+	return nil
+}
+
+func fileInfoFromOS(filePath string, info os.FileInfo) *FileInfo {
+	return &FileInfo{
+		Name:    path.Base("/" + filePath),
+		Path:    filePath,
+		Size:    info.Size(),
+		IsDir:   info.IsDir(),
+		Mode:    info.Mode().String(),
+		ModTime: info.ModTime().Format(TimeFormat),
+	}
+}
+
+func setFileErrorCode(ctx *logger.RequestContext, err error) {
+	if errors.Is(err, os.ErrNotExist) {
+		ctx.ErrorCode = common.FsFileNotFound
+		return
+	}
+	switch fsUtils.ToSyscallErrno(err) {
+	case syscall.ENOENT:
+		ctx.ErrorCode = common.FsFileNotFound
+	case syscall.EEXIST:
+		ctx.ErrorCode = common.FsFileAlreadyExist
+	case syscall.ENOTDIR, syscall.EISDIR, syscall.ENOTEMPTY, syscall.EINVAL:
+		ctx.ErrorCode = common.InvalidFsFilePath
+	case syscall.EACCES, syscall.EPERM:
+		ctx.ErrorCode = common.AccessDenied
+	default:
+		ctx.ErrorCode = common.FsFileOperationFailed
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/logger"
+	fuse "github.com/PaddlePaddle/PaddleFlow/pkg/fs/client/fs"
+	fsCommon "github.com/PaddlePaddle/PaddleFlow/pkg/fs/common"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage/driver"
+)
+
+func TestCleanFilePath(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{path: "", want: ""},
+		{path: "/", want: ""},
+		{path: "/a//b/./c/", want: "a/b/c"},
+		{path: "a/b", want: "a/b"},
+		{path: "../a", wantErr: true},
+		{path: "a/../../b", wantErr: true},
+		{path: "a\x00b", wantErr: true},
+	}
+	for _, tt := range tests {
+		ctx := &logger.RequestContext{}
+		got, err := CleanFilePath(ctx, tt.path)
+		if tt.wantErr {
+			assert.Error(t, err, tt.path)
+			assert.Equal(t, common.InvalidFsFilePath, ctx.ErrorCode)
+			continue
+		}
+		assert.NoError(t, err, tt.path)
+		assert.Equal(t, tt.want, got)
+	}
+}
+
+func TestHasFsPermission(t *testing.T) {
+	driver.InitMockDB()
+	fsID := common.ID("user1", mockFSName)
+	fs := model.FileSystem{
+		Model:    model.Model{ID: fsID},
+		Name:     mockFSName,
+		UserName: "user1",
+	}
+	assert.NoError(t, storage.Filesystem.CreatFileSystem(&fs))
+
+	service := GetFileSystemService()
+	for user, want := range map[string]bool{"user1": true, mockRootName: true, "user2": false} {
+		has, err := service.HasFsPermission(user, fsID)
+		assert.NoError(t, err)
+		assert.Equal(t, want, has, user)
+	}
+
+	ctx := &logger.RequestContext{UserName: mockRootName}
+	grant := &model.Grant{ID: "grant-1", UserName: "user2", ResourceType: common.ResourceTypeFs, ResourceID: fsID}
+	assert.NoError(t, storage.Auth.CreateGrant(ctx, grant))
+	has, err := service.HasFsPermission("user2", fsID)
+	assert.NoError(t, err)
+	assert.True(t, has)
+
+	_, err = service.HasFsPermission("user2", common.ID("user1", "notexist"))
+	assert.Error(t, err)
+}
+
+type closeCountClient struct {
+	fuse.FSClient
+	closed int
+}
+
+func (c *closeCountClient) Close() error {
+	c.closed++
+	return nil
+}
+
+func TestFsClientCache(t *testing.T) {
+	var built []*closeCountClient
+	cache := newFsClientCache(1, func(fsMeta fsCommon.FSMeta, links map[string]fsCommon.FSMeta) (fuse.FSClient, error) {
+		client := &closeCountClient{}
+		built = append(built, client)
+		return client, nil
+	})
+	fsMeta := fsCommon.FSMeta{ID: "fs-root-a", UfsType: fsCommon.LocalType, SubPath: "/data"}
+
+	// requests of the same fs share one client, and closing it only releases the reference
+	client1, err := cache.get(fsMeta, nil)
+	assert.NoError(t, err)
+	client2, err := cache.get(fsMeta, nil)
+	assert.NoError(t, err)
+	assert.Len(t, built, 1)
+	assert.NoError(t, client1.Close())
+	assert.NoError(t, client1.Close())
+	assert.Equal(t, 0, built[0].closed)
+
+	// the client is rebuilt when fs meta changes, and the old one is closed after released
+	fsMeta.SubPath = "/data2"
+	client3, err := cache.get(fsMeta, nil)
+	assert.NoError(t, err)
+	assert.Len(t, built, 2)
+	assert.Equal(t, 0, built[0].closed)
+	assert.NoError(t, client2.Close())
+	assert.Equal(t, 1, built[0].closed)
+
+	// the least recently used client is evicted and closed
+	_, err = cache.get(fsCommon.FSMeta{ID: "fs-root-b"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, built[1].closed)
+	assert.NoError(t, client3.Close())
+	assert.Equal(t, 1, built[1].closed)
+}
@@ -176,6 +176,8 @@ func MountPodController(mountPodExpire, interval time.Duration,
 }
 
 func (s *FileSystemService) HasFsPermission(username, fsID string) (bool, error) {
+	// users with permission of fs can access its snapshots
+	fsID, _ = schema.ParseFsSnapshotID(fsID)
 	fsName, owner, err := utils.GetFsNameAndUserNameByFsID(fsID)
 	if err != nil {
 		return false, err
@@ -186,9 +188,10 @@ func (s *FileSystemService) HasFsPermission(username, fsID string) (bool, error)
 	}
 	if common.IsRootUser(username) || fs.UserName == username {
 		return true, nil
-	} else {
-		return false, nil
 	}
+	// users granted with the fs by root can also access it
+	ctx := &logger.RequestContext{UserName: username}
+	return storage.Auth.HasAccessToResource(ctx, common.ResourceTypeFs, fsID), nil
 }
 
 // CreateFileSystem the function which performs the operation of creating FileSystem
@@ -211,12 +214,14 @@ func (s *FileSystemService) CreateFileSystem(ctx *logger.RequestContext, req *Cr
 		ctx.ErrorCode = common.FileSystemDataBaseError
 		return model.FileSystem{}, err
 	}
+	RecordFsAudit(ctx, fs.ID, schema.FsAuditOpCreateFs, "", "")
 	return fs, nil
 }
 
-// GetFileSystem the function which performs the operation of getting file system detail
+// GetFileSystem the function which performs the operation of getting file system detail, fsName@snapshot gets
+// the read-only file system of snapshot
 func (s *FileSystemService) GetFileSystem(username, fsName string) (model.FileSystem, error) {
-	modelsFs, err := storage.Filesystem.GetFileSystemWithFsID(common.ID(username, fsName))
+	modelsFs, err := storage.Filesystem.GetFileSystemOrSnapshot(common.FsOrSnapshotID(username, fsName))
 	if err != nil {
 		log.Errorf("get filesystem[%s] under username[%s] err[%v]", fsName, username, err)
 		return model.FileSystem{}, err
@@ -246,7 +251,7 @@ func (s *FileSystemService) DeleteFileSystem(ctx *logger.RequestContext, fsID st
 	}
 
 	// delete filesystem, links, cache config in DB
-	return storage.WithTransaction(storage.DB, func(tx *gorm.DB) error {
+	err = storage.WithTransaction(storage.DB, func(tx *gorm.DB) error {
 		// delete filesystem
 		if err := storage.Filesystem.DeleteFileSystem(tx, fsID); err != nil {
 			ctx.Logging().Errorf("delete fs[%s] err: %v", fsID, err)
@@ -259,6 +264,17 @@ func (s *FileSystemService) DeleteFileSystem(ctx *logger.RequestContext, fsID st
 			ctx.ErrorCode = common.FileSystemDataBaseError
 			return err
 		}
+		// delete snapshot records, data of snapshots are kept in storage
+		if err := storage.Filesystem.DeleteFsSnapshotWithFsID(tx, fsID); err != nil {
+			ctx.Logging().Errorf("delete snapshots with fsID[%s] err: %v", fsID, err)
+			ctx.ErrorCode = common.FileSystemDataBaseError
+			return err
+		}
+		if err := storage.Filesystem.DeleteFsQuotaWithFsID(tx, fsID); err != nil {
+			ctx.Logging().Errorf("delete quotas with fsID[%s] err: %v", fsID, err)
+			ctx.ErrorCode = common.FileSystemDataBaseError
+			return err
+		}
 		// delete cache config if exists
 		if err := storage.Filesystem.DeleteFSCacheConfig(tx, fsID); err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -270,6 +286,11 @@ func (s *FileSystemService) DeleteFileSystem(ctx *logger.RequestContext, fsID st
 		}
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+	RecordFsAudit(ctx, fsID, schema.FsAuditOpDeleteFs, "", "")
+	return nil
 }
 
 func (s *FileSystemService) checkFsMountedAllClustersAndScheduledJobs(fsID string) (bool, map[*runtime.KubeRuntime][]k8sCore.Pod, error) {
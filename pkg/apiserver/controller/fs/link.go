@@ -30,9 +30,9 @@ import (
 	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/config"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/logger"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
 	fuse "github.com/PaddlePaddle/PaddleFlow/pkg/fs/client/fs"
 	fsCommon "github.com/PaddlePaddle/PaddleFlow/pkg/fs/common"
-	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/utils"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
 )
@@ -110,19 +110,21 @@ func (s *LinkService) CreateLink(ctx *logger.RequestContext, req *CreateLinkRequ
 		ctx.ErrorCode = common.LinkModelError
 		return model.Link{}, err
 	}
+	RecordFsAudit(ctx, fsID, schema.FsAuditOpCreateLink, link.FsPath, "")
 	return link, nil
 }
 
 // DeleteLink the function which performs the operation of delete file system link
 func (s *LinkService) DeleteLink(ctx *logger.RequestContext, req *DeleteLinkRequest) error {
-	err := storage.Filesystem.DeleteLinkWithFsIDAndFsPath(common.ID(req.Username, req.FsName), req.FsPath)
+	fsID := common.ID(req.Username, req.FsName)
+	err := storage.Filesystem.DeleteLinkWithFsIDAndFsPath(fsID, req.FsPath)
 	if err != nil {
 		ctx.Logging().Errorf("delete link failed error[%v]", err)
 		ctx.ErrorCode = common.FileSystemDataBaseError
 		return err
 	}
-
-	return err
+	RecordFsAudit(ctx, fsID, schema.FsAuditOpDeleteLink, req.FsPath, "")
+	return nil
 }
 
 // GetLink the function which performs the operation of list file system links
@@ -171,21 +173,9 @@ func (s *LinkService) PersistLinksMeta(fsID string) error {
 		return err
 	}
 
-	linksMeta := make(map[string]fsCommon.FSMeta)
-	for _, link := range links {
-		fsName, _, err := utils.GetFsNameAndUserNameByFsID(link.FsID)
-		if err != nil {
-			return err
-		}
-		linksMeta[link.FsPath] = fsCommon.FSMeta{
-			ID:            link.ID,
-			Name:          fsName,
-			UfsType:       link.Type,
-			ServerAddress: link.ServerAddress,
-			SubPath:       link.SubPath,
-			Properties:    link.PropertiesMap,
-			Type:          fsCommon.LinkType,
-		}
+	linksMeta, err := linksMetaFromModel(links)
+	if err != nil {
+		return err
 	}
 
 	linksMetaJson, err := json.Marshal(linksMeta)
@@ -208,24 +198,21 @@ func (s *LinkService) PersistLinksMeta(fsID string) error {
 }
 
 func writeLinksMeta(encodedLinksMeta string, fsID string) error {
+	return writeFsConfigFile(fsID, fsCommon.LinkMetaFile, encodedLinksMeta)
+}
+
+// writeFsConfigFile replaces the config file in fs, which is loaded by clients of fs periodically
+func writeFsConfigFile(fsID, fileName, content string) error {
 	fs, err := storage.Filesystem.GetFileSystemWithFsID(fsID)
 	if err != nil {
 		log.Errorf("GetFileSystemWithFsID error[%v]", err)
 		return err
 	}
-	fsMeta := fsCommon.FSMeta{
-		ID:            fsID,
-		Name:          fs.Name,
-		UfsType:       fs.Type,
-		ServerAddress: fs.ServerAddress,
-		SubPath:       fs.SubPath,
-		Properties:    fs.PropertiesMap,
-		Type:          fsCommon.FSType,
-	}
-	client, err := fuse.NewFSClient(fsMeta, nil)
+	client, err := fuse.NewFSClient(fsMetaFromModel(fs), nil)
 	if err != nil {
 		return err
 	}
+	defer client.Close()
 
 	dirPath := filepath.Join(config.GlobalServerConfig.Fs.LinkMetaDirPrefix, fsCommon.LinkMetaDir)
 	if err := client.MkdirAll(dirPath, os.ModePerm); err != nil {
@@ -234,13 +221,13 @@ func writeLinksMeta(encodedLinksMeta string, fsID string) error {
 	}
 
 	tempSrcFile := uuid.NewString()
-	if err := client.SaveFile(strings.NewReader(encodedLinksMeta), dirPath, tempSrcFile); err != nil {
+	if err := client.SaveFile(strings.NewReader(content), dirPath, tempSrcFile); err != nil {
 		log.Errorf("client save file err:%v", err)
 		return err
 	}
 
 	srcPath := filepath.Join(dirPath, tempSrcFile)
-	dstPath := filepath.Join(dirPath, fsCommon.LinkMetaFile)
+	dstPath := filepath.Join(dirPath, fileName)
 	dstExit, _ := client.Exist(dstPath)
 	var tempDstPath string
 	if dstExit {
@@ -0,0 +1,232 @@
+/*
+Copyright (c) 2021 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"gorm.io/gorm"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/logger"
+	fsCommon "github.com/PaddlePaddle/PaddleFlow/pkg/fs/common"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
+)
+
+// fsQuotaPathMaxLength is the max length of quota path in db
+const fsQuotaPathMaxLength = 255
+
+// fsQuotaMutex keeps the quota meta file in fs consistent with db
+var fsQuotaMutex sync.Mutex
+
+type SetFsQuotaRequest struct {
+	Path      string `json:"path"`
+	MaxBytes  int64  `json:"maxBytes"`
+	MaxInodes int64  `json:"maxInodes"`
+}
+
+type FsQuotaResponse struct {
+	Path       string `json:"path"`
+	MaxBytes   int64  `json:"maxBytes"`
+	MaxInodes  int64  `json:"maxInodes"`
+	UsedBytes  *int64 `json:"usedBytes,omitempty"`
+	UsedInodes *int64 `json:"usedInodes,omitempty"`
+	CreateTime string `json:"createTime"`
+	UpdateTime string `json:"updateTime"`
+}
+
+type ListFsQuotaResponse struct {
+	QuotaList []*FsQuotaResponse `json:"quotaList"`
+}
+
+func FsQuotaResponseFromModel(quota model.FsQuota) *FsQuotaResponse {
+	return &FsQuotaResponse{
+		Path:       quota.Path,
+		MaxBytes:   quota.MaxBytes,
+		MaxInodes:  quota.MaxInodes,
+		CreateTime: quota.CreateTime,
+		UpdateTime: quota.UpdateTime,
+	}
+}
+
+// quotaPath returns the absolute path of quota in fs
+func quotaPath(ctx *logger.RequestContext, path string) (string, error) {
+	cleanPath, err := CleanFilePath(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	cleanPath = "/" + cleanPath
+	if len(cleanPath) > fsQuotaPathMaxLength {
+		ctx.ErrorCode = common.InvalidFsQuota
+		return "", fmt.Errorf("quota path is longer than %d", fsQuotaPathMaxLength)
+	}
+	return cleanPath, nil
+}
+
+// SetFsQuota creates or updates the quota on a directory of fs, which is enforced by the clients of fs
+func (s *FileSystemService) SetFsQuota(ctx *logger.RequestContext, fsID string,
+	req *SetFsQuotaRequest) (*model.FsQuota, error) {
+	if !common.IsRootUser(ctx.UserName) {
+		ctx.ErrorCode = common.OnlyRootAllowed
+		return nil, fmt.Errorf("only root is allowed to set quota of fs")
+	}
+	if req.MaxBytes < 0 || req.MaxInodes < 0 || req.MaxBytes == 0 && req.MaxInodes == 0 {
+		ctx.ErrorCode = common.InvalidFsQuota
+		return nil, fmt.Errorf("maxBytes[%d] and maxInodes[%d] must not be negative, and at least one is positive",
+			req.MaxBytes, req.MaxInodes)
+	}
+	path, err := quotaPath(ctx, req.Path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = storage.Filesystem.GetFileSystemWithFsID(fsID); err != nil {
+		ctx.Logging().Errorf("get filesystem[%s] err: %v", fsID, err)
+		ctx.ErrorCode = common.FileSystemNotExist
+		return nil, err
+	}
+
+	fsQuotaMutex.Lock()
+	defer fsQuotaMutex.Unlock()
+	quota, err := storage.Filesystem.GetFsQuota(fsID, path)
+	switch {
+	case err == nil:
+		quota.MaxBytes, quota.MaxInodes = req.MaxBytes, req.MaxInodes
+		err = storage.Filesystem.UpdateFsQuota(&quota)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		quota = model.FsQuota{FsID: fsID, Path: path, MaxBytes: req.MaxBytes, MaxInodes: req.MaxInodes}
+		err = storage.Filesystem.CreateFsQuota(&quota)
+	}
+	if err != nil {
+		ctx.Logging().Errorf("set quota of fs[%s] path[%s] err: %v", fsID, path, err)
+		ctx.ErrorCode = common.FileSystemDataBaseError
+		return nil, err
+	}
+	if err = persistFsQuotas(ctx, fsID); err != nil {
+		return nil, err
+	}
+	return &quota, nil
+}
+
+// ListFsQuota returns the quotas of fs, the usage is counted by walking the directories if withUsage is set
+func (s *FileSystemService) ListFsQuota(ctx *logger.RequestContext, fsID string,
+	withUsage bool) ([]*FsQuotaResponse, error) {
+	if err := s.checkFsAccess(ctx, fsID); err != nil {
+		return nil, err
+	}
+	quotas, err := storage.Filesystem.ListFsQuota(fsID)
+	if err != nil {
+		ctx.Logging().Errorf("list quotas of fs[%s] err: %v", fsID, err)
+		ctx.ErrorCode = common.FileSystemDataBaseError
+		return nil, err
+	}
+	responses := make([]*FsQuotaResponse, 0, len(quotas))
+	for _, quota := range quotas {
+		responses = append(responses, FsQuotaResponseFromModel(quota))
+	}
+	if !withUsage || len(quotas) == 0 {
+		return responses, nil
+	}
+
+	client, err := NewFsClient(fsID)
+	if err != nil {
+		ctx.Logging().Errorf("new client of fs[%s] err: %v", fsID, err)
+		ctx.ErrorCode = common.FsFileOperationFailed
+		return nil, err
+	}
+	defer client.Close()
+	for _, response := range responses {
+		var bytes, inodes int64
+		err = client.Walk(response.Path, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if filepath.Clean(path) == filepath.Clean(response.Path) {
+				return nil
+			}
+			inodes++
+			if !info.IsDir() {
+				bytes += info.Size()
+			}
+			return nil
+		})
+		if err != nil {
+			ctx.Logging().Errorf("count usage of fs[%s] path[%s] err: %v", fsID, response.Path, err)
+			ctx.ErrorCode = common.FsFileOperationFailed
+			return nil, err
+		}
+		response.UsedBytes, response.UsedInodes = &bytes, &inodes
+	}
+	return responses, nil
+}
+
+func (s *FileSystemService) DeleteFsQuota(ctx *logger.RequestContext, fsID, path string) error {
+	if !common.IsRootUser(ctx.UserName) {
+		ctx.ErrorCode = common.OnlyRootAllowed
+		return fmt.Errorf("only root is allowed to delete quota of fs")
+	}
+	path, err := quotaPath(ctx, path)
+	if err != nil {
+		return err
+	}
+
+	fsQuotaMutex.Lock()
+	defer fsQuotaMutex.Unlock()
+	if _, err = storage.Filesystem.GetFsQuota(fsID, path); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.ErrorCode = common.FsQuotaNotFound
+			return fmt.Errorf("quota of fs[%s] path[%s] not found", fsID, path)
+		}
+		ctx.ErrorCode = common.FileSystemDataBaseError
+		return err
+	}
+	if err = storage.Filesystem.DeleteFsQuota(fsID, path); err != nil {
+		ctx.Logging().Errorf("delete quota of fs[%s] path[%s] err: %v", fsID, path, err)
+		ctx.ErrorCode = common.FileSystemDataBaseError
+		return err
+	}
+	return persistFsQuotas(ctx, fsID)
+}
+
+// persistFsQuotas writes all quotas of fs to the quota meta file, which is loaded by clients with links meta
+func persistFsQuotas(ctx *logger.RequestContext, fsID string) error {
+	quotas, err := storage.Filesystem.ListFsQuota(fsID)
+	if err != nil {
+		ctx.Logging().Errorf("list quotas of fs[%s] err: %v", fsID, err)
+		ctx.ErrorCode = common.FileSystemDataBaseError
+		return err
+	}
+	content, err := json.Marshal(model.FsQuotasMeta(quotas))
+	if err != nil {
+		ctx.ErrorCode = common.InternalError
+		return err
+	}
+	if err = writeFsConfigFile(fsID, fsCommon.QuotaMetaFile, string(content)); err != nil {
+		ctx.Logging().Errorf("write quota meta of fs[%s] err: %v", fsID, err)
+		ctx.ErrorCode = common.FsFileOperationFailed
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+/*
+Copyright (c) 2021 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/logger"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/client/meta"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/client/ufs"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/utils"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
+)
+
+const (
+	// fsSnapshotHeartbeatInterval is how often the snapshot being taken is touched in db
+	fsSnapshotHeartbeatInterval = 30 * time.Second
+	// fsSnapshotStaleTimeout is how long a creating snapshot is not touched before it is marked failed,
+	// since taking it is interrupted by restart of server
+	fsSnapshotStaleTimeout = 3 * fsSnapshotHeartbeatInterval
+)
+
+type CreateFsSnapshotRequest struct {
+	Name string `json:"name"`
+}
+
+type FsSnapshotResponse struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	FsName     string `json:"fsName"`
+	UserName   string `json:"userName"`
+	Mode       string `json:"mode"`
+	Status     string `json:"status"`
+	Message    string `json:"message,omitempty"`
+	FileCount  int64  `json:"fileCount"`
+	Size       int64  `json:"size"`
+	CreateTime string `json:"createTime"`
+	UpdateTime string `json:"updateTime"`
+}
+
+type ListFsSnapshotResponse struct {
+	SnapshotList []*FsSnapshotResponse `json:"snapshotList"`
+}
+
+// NewFsSnapshotter returns the snapshotter of fs, may be replaced in unit tests
+var NewFsSnapshotter = func(fs model.FileSystem) (ufs.Snapshotter, error) {
+	u, err := meta.NewUFS(fsMetaFromModel(fs))
+	if err != nil {
+		return nil, err
+	}
+	snapshotter, ok := u.(ufs.Snapshotter)
+	if !ok {
+		return nil, fmt.Errorf("ufs[%s] of fs[%s] does not support snapshot", u.String(), fs.ID)
+	}
+	return snapshotter, nil
+}
+
+func FsSnapshotResponseFromModel(snapshot model.FsSnapshot) *FsSnapshotResponse {
+	fsName, _, _ := utils.GetFsNameAndUserNameByFsID(snapshot.FsID)
+	return &FsSnapshotResponse{
+		ID:         snapshot.ID,
+		Name:       snapshot.Name,
+		FsName:     fsName,
+		UserName:   snapshot.UserName,
+		Mode:       snapshot.Mode,
+		Status:     snapshot.Status,
+		Message:    snapshot.Message,
+		FileCount:  snapshot.FileCount,
+		Size:       snapshot.Size,
+		CreateTime: snapshot.CreateTime,
+		UpdateTime: snapshot.UpdateTime,
+	}
+}
+
+// CreateFsSnapshot records the snapshot and takes it in background, the snapshot can be mounted when it is ready
+func (s *FileSystemService) CreateFsSnapshot(ctx *logger.RequestContext, fsID string,
+	req *CreateFsSnapshotRequest) (*model.FsSnapshot, error) {
+	if errs := common.IsDNS1123Label(req.Name); len(errs) != 0 {
+		ctx.ErrorCode = common.InvalidFsSnapshotName
+		return nil, fmt.Errorf("snapshot name[%s] is invalid: %s", req.Name, strings.Join(errs, ","))
+	}
+	if err := s.checkFsAccess(ctx, fsID); err != nil {
+		return nil, err
+	}
+	fs, err := storage.Filesystem.GetFileSystemWithFsID(fsID)
+	if err != nil {
+		ctx.Logging().Errorf("get filesystem[%s] err: %v", fsID, err)
+		ctx.ErrorCode = common.FileSystemNotExist
+		return nil, err
+	}
+	mode := ufs.SnapshotMode(fs.Type)
+	if mode == "" {
+		ctx.ErrorCode = common.FsSnapshotNotSupported
+		return nil, fmt.Errorf("snapshot is not supported by fs type[%s]", fs.Type)
+	}
+	id := schema.FsSnapshotID(fsID, req.Name)
+	if _, err = storage.Filesystem.GetFsSnapshot(id); err == nil {
+		ctx.ErrorCode = common.FsSnapshotAlreadyExist
+		return nil, fmt.Errorf("snapshot[%s] of fs[%s] already exists", req.Name, fsID)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.ErrorCode = common.FileSystemDataBaseError
+		return nil, err
+	}
+	snapshotter, err := NewFsSnapshotter(fs)
+	if err != nil {
+		ctx.Logging().Errorf("new snapshotter of fs[%s] err: %v", fsID, err)
+		ctx.ErrorCode = common.FsFileOperationFailed
+		return nil, err
+	}
+
+	snapshot := &model.FsSnapshot{
+		Model:    model.Model{ID: id},
+		Name:     req.Name,
+		FsID:     fsID,
+		FsType:   fs.Type,
+		UserName: fs.UserName,
+		Mode:     mode,
+		Status:   model.FsSnapshotStatusCreating,
+	}
+	if err = storage.Filesystem.CreateFsSnapshot(snapshot); err != nil {
+		ctx.Logging().Errorf("create snapshot[%s] in db err: %v", id, err)
+		ctx.ErrorCode = common.FileSystemDataBaseError
+		return nil, err
+	}
+	go takeFsSnapshot(snapshotter, *snapshot)
+	return snapshot, nil
+}
+
+// takeFsSnapshot takes the snapshot and touches it in db periodically, so that it is not regarded as stale
+func takeFsSnapshot(snapshotter ufs.Snapshotter, snapshot model.FsSnapshot) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(fsSnapshotHeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := storage.Filesystem.TouchFsSnapshot(snapshot.ID); err != nil {
+					log.Errorf("touch snapshot[%s] in db err: %v", snapshot.ID, err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	info, err := snapshotter.CreateSnapshot(snapshot.Name)
+	close(done)
+	if err != nil {
+		log.Errorf("take snapshot[%s] err: %v", snapshot.ID, err)
+		snapshot.Status = model.FsSnapshotStatusFailed
+		snapshot.Message = err.Error()
+	} else {
+		snapshot.Status = model.FsSnapshotStatusReady
+		snapshot.FileCount = info.FileCount
+		snapshot.Size = info.Size
+	}
+	if err = storage.Filesystem.UpdateFsSnapshot(&snapshot); err != nil {
+		log.Errorf("update snapshot[%s] in db err: %v", snapshot.ID, err)
+	}
+}
+
+// FailStaleFsSnapshots periodically marks the creating snapshots as failed, which are not touched for
+// fsSnapshotStaleTimeout since interrupted by restart of server. The failed snapshots can be deleted and taken again.
+func FailStaleFsSnapshots(stopChan chan struct{}) {
+	ticker := time.NewTicker(fsSnapshotStaleTimeout)
+	defer ticker.Stop()
+	for {
+		failStaleFsSnapshots(time.Now().Add(-fsSnapshotStaleTimeout))
+		select {
+		case <-ticker.C:
+		case <-stopChan:
+			return
+		}
+	}
+}
+
+func failStaleFsSnapshots(staleBefore time.Time) {
+	count, err := storage.Filesystem.FailStaleFsSnapshots(staleBefore, "taking snapshot is interrupted")
+	if err != nil {
+		log.Errorf("fail stale snapshots err: %v", err)
+		return
+	}
+	if count > 0 {
+		log.Infof("%d stale snapshots are marked failed", count)
+	}
+}
+
+func (s *FileSystemService) GetFsSnapshot(ctx *logger.RequestContext, fsID, name string) (model.FsSnapshot, error) {
+	if err := s.checkFsAccess(ctx, fsID); err != nil {
+		return model.FsSnapshot{}, err
+	}
+	snapshot, err := storage.Filesystem.GetFsSnapshot(schema.FsSnapshotID(fsID, name))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.ErrorCode = common.FsSnapshotNotFound
+		} else {
+			ctx.ErrorCode = common.FileSystemDataBaseError
+		}
+		return model.FsSnapshot{}, err
+	}
+	return snapshot, nil
+}
+
+func (s *FileSystemService) ListFsSnapshot(ctx *logger.RequestContext, fsID string) ([]model.FsSnapshot, error) {
+	if err := s.checkFsAccess(ctx, fsID); err != nil {
+		return nil, err
+	}
+	snapshots, err := storage.Filesystem.ListFsSnapshot(fsID)
+	if err != nil {
+		ctx.Logging().Errorf("list snapshots of fs[%s] err: %v", fsID, err)
+		ctx.ErrorCode = common.FileSystemDataBaseError
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// DeleteFsSnapshot deletes the snapshot which is not mounted, as well as the data of it in storage.
+// The snapshot being created can not be deleted unless it is stale.
+func (s *FileSystemService) DeleteFsSnapshot(ctx *logger.RequestContext, fsID, name string) error {
+	snapshot, err := s.GetFsSnapshot(ctx, fsID, name)
+	if err != nil {
+		return err
+	}
+	if snapshot.Status == model.FsSnapshotStatusCreating &&
+		snapshot.UpdatedAt.After(time.Now().Add(-fsSnapshotStaleTimeout)) {
+		ctx.ErrorCode = common.ActionNotAllowed
+		return fmt.Errorf("snapshot[%s] is being created", snapshot.ID)
+	}
+	isMounted, cleanPodMap, err := s.checkFsMountedAllClustersAndScheduledJobs(snapshot.ID)
+	if err != nil {
+		ctx.ErrorCode = common.InternalError
+		return err
+	}
+	if isMounted {
+		ctx.ErrorCode = common.ActionNotAllowed
+		return fmt.Errorf("snapshot[%s] is mounted. deletion is not allowed", snapshot.ID)
+	}
+	if err = s.cleanFsResources(cleanPodMap, snapshot.ID); err != nil {
+		ctx.Logging().Errorf("clean resources of snapshot[%s] err: %v", snapshot.ID, err)
+		ctx.ErrorCode = common.InternalError
+		return err
+	}
+
+	fs, err := storage.Filesystem.GetFileSystemWithFsID(fsID)
+	if err != nil {
+		ctx.ErrorCode = common.FileSystemNotExist
+		return err
+	}
+	snapshotter, err := NewFsSnapshotter(fs)
+	if err == nil {
+		err = snapshotter.DeleteSnapshot(name)
+	}
+	// failed or stale snapshot may have no data in storage
+	if err != nil && snapshot.Status == model.FsSnapshotStatusReady {
+		ctx.Logging().Errorf("delete snapshot[%s] in storage err: %v", snapshot.ID, err)
+		ctx.ErrorCode = common.FsFileOperationFailed
+		return err
+	}
+	if err = storage.Filesystem.DeleteFsSnapshot(snapshot.ID); err != nil {
+		ctx.ErrorCode = common.FileSystemDataBaseError
+		return err
+	}
+	return nil
+}
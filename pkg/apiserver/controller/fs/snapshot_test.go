@@ -0,0 +1,59 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage/driver"
+)
+
+func TestFailStaleFsSnapshots(t *testing.T) {
+	driver.InitMockDB()
+	fsID := "fs-root-snapshot"
+	stale := &model.FsSnapshot{
+		Model:  model.Model{ID: schema.FsSnapshotID(fsID, "stale"), UpdatedAt: time.Now().Add(-time.Hour)},
+		Name:   "stale",
+		FsID:   fsID,
+		Status: model.FsSnapshotStatusCreating,
+	}
+	creating := &model.FsSnapshot{
+		Model:  model.Model{ID: schema.FsSnapshotID(fsID, "creating"), UpdatedAt: time.Now().Add(-time.Hour)},
+		Name:   "creating",
+		FsID:   fsID,
+		Status: model.FsSnapshotStatusCreating,
+	}
+	assert.NoError(t, storage.Filesystem.CreateFsSnapshot(stale))
+	assert.NoError(t, storage.Filesystem.CreateFsSnapshot(creating))
+	// the snapshot being taken is touched and not regarded as stale
+	assert.NoError(t, storage.Filesystem.TouchFsSnapshot(creating.ID))
+
+	failStaleFsSnapshots(time.Now().Add(-fsSnapshotStaleTimeout))
+	snapshot, err := storage.Filesystem.GetFsSnapshot(stale.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.FsSnapshotStatusFailed, snapshot.Status)
+	assert.NotEmpty(t, snapshot.Message)
+	snapshot, err = storage.Filesystem.GetFsSnapshot(creating.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.FsSnapshotStatusCreating, snapshot.Status)
+}
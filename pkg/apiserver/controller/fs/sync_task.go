@@ -0,0 +1,526 @@
+/*
+Copyright (c) 2021 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/logger"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/uuid"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/transfer"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/utils"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
+)
+
+const (
+	FsSyncTaskActionStop   = "stop"
+	FsSyncTaskActionResume = "resume"
+
+	// fsSyncTaskStaleTimeout is how long an unfinished task is not updated before it is resumed, the progress of
+	// running task is updated every transfer.DefaultReportInterval
+	fsSyncTaskStaleTimeout = 1 * time.Minute
+)
+
+var (
+	// fsSyncMutex protects fsSyncCancels, which holds the cancel functions of tasks running in this server
+	fsSyncMutex   sync.Mutex
+	fsSyncCancels = make(map[string]context.CancelFunc)
+)
+
+type CreateFsSyncTaskRequest struct {
+	SrcFsName string `json:"srcFsName"`
+	SrcPath   string `json:"srcPath"`
+	DstFsName string `json:"dstFsName"`
+	DstPath   string `json:"dstPath"`
+	// Username is the owner of file systems, default is the request user
+	Username       string `json:"username"`
+	Mode           string `json:"mode"`
+	Compare        string `json:"compare"`
+	Workers        int    `json:"workers"`
+	BandwidthLimit int64  `json:"bandwidthLimit"`
+	DryRun         bool   `json:"dryRun"`
+}
+
+type FsSyncTaskResponse struct {
+	ID             string   `json:"id"`
+	UserName       string   `json:"userName"`
+	SrcFsName      string   `json:"srcFsName"`
+	SrcPath        string   `json:"srcPath"`
+	DstFsName      string   `json:"dstFsName"`
+	DstPath        string   `json:"dstPath"`
+	Mode           string   `json:"mode"`
+	Compare        string   `json:"compare"`
+	Workers        int      `json:"workers"`
+	BandwidthLimit int64    `json:"bandwidthLimit"`
+	DryRun         bool     `json:"dryRun"`
+	Status         string   `json:"status"`
+	Message        string   `json:"message,omitempty"`
+	Total          int64    `json:"total"`
+	Copied         int64    `json:"copied"`
+	Skipped        int64    `json:"skipped"`
+	Deleted        int64    `json:"deleted"`
+	Failed         int64    `json:"failed"`
+	CopiedBytes    int64    `json:"copiedBytes"`
+	Checkpoint     string   `json:"checkpoint,omitempty"`
+	Diff           []string `json:"diff,omitempty"`
+	CreateTime     string   `json:"createTime"`
+	UpdateTime     string   `json:"updateTime"`
+}
+
+type ListFsSyncTaskResponse struct {
+	TaskList []*FsSyncTaskResponse `json:"taskList"`
+}
+
+func FsSyncTaskResponseFromModel(task model.FsSyncTask) *FsSyncTaskResponse {
+	srcFsName := fsNameOfID(task.SrcFsID)
+	dstFsName := fsNameOfID(task.DstFsID)
+	response := &FsSyncTaskResponse{
+		ID:             task.ID,
+		UserName:       task.UserName,
+		SrcFsName:      srcFsName,
+		SrcPath:        task.SrcPath,
+		DstFsName:      dstFsName,
+		DstPath:        task.DstPath,
+		Mode:           task.Mode,
+		Compare:        task.Compare,
+		Workers:        task.Workers,
+		BandwidthLimit: task.BandwidthLimit,
+		DryRun:         task.DryRun,
+		Status:         task.Status,
+		Message:        task.Message,
+		Total:          task.Total,
+		Copied:         task.Copied,
+		Skipped:        task.Skipped,
+		Deleted:        task.Deleted,
+		Failed:         task.Failed,
+		CopiedBytes:    task.CopiedBytes,
+		Checkpoint:     task.Checkpoint,
+		CreateTime:     task.CreateTime,
+		UpdateTime:     task.UpdateTime,
+	}
+	if task.Diff != "" {
+		response.Diff = strings.Split(task.Diff, "\n")
+	}
+	return response
+}
+
+// fsNameOfID returns the name of fs or snapshot like fsName@snapshot
+func fsNameOfID(id string) string {
+	fsID, snapshot := schema.ParseFsSnapshotID(id)
+	fsName, _, _ := utils.GetFsNameAndUserNameByFsID(fsID)
+	if snapshot == "" {
+		return fsName
+	}
+	return schema.FsSnapshotName(fsName, snapshot)
+}
+
+func (req *CreateFsSyncTaskRequest) validate(ctx *logger.RequestContext) error {
+	if req.SrcFsName == "" || req.DstFsName == "" {
+		ctx.ErrorCode = common.InvalidFsSyncTask
+		return fmt.Errorf("srcFsName and dstFsName must be set")
+	}
+	if _, snapshot := schema.ParseFsSnapshotName(req.DstFsName); snapshot != "" {
+		ctx.ErrorCode = common.InvalidFsSyncTask
+		return fmt.Errorf("destination fs[%s] is a read-only snapshot", req.DstFsName)
+	}
+	switch req.Mode {
+	case "":
+		req.Mode = transfer.ModeCopy
+	case transfer.ModeCopy, transfer.ModeSync:
+	default:
+		ctx.ErrorCode = common.InvalidFsSyncTask
+		return fmt.Errorf("mode[%s] must be %s or %s", req.Mode, transfer.ModeCopy, transfer.ModeSync)
+	}
+	switch req.Compare {
+	case "":
+		req.Compare = transfer.CompareSizeMtime
+	case transfer.CompareSizeMtime, transfer.CompareChecksum:
+	default:
+		ctx.ErrorCode = common.InvalidFsSyncTask
+		return fmt.Errorf("compare[%s] must be %s or %s", req.Compare, transfer.CompareSizeMtime,
+			transfer.CompareChecksum)
+	}
+	if req.Workers == 0 {
+		req.Workers = transfer.DefaultWorkers
+	}
+	if req.Workers < 0 || req.Workers > transfer.MaxWorkers || req.BandwidthLimit < 0 {
+		ctx.ErrorCode = common.InvalidFsSyncTask
+		return fmt.Errorf("workers[%d] must be in [1, %d] and bandwidthLimit[%d] must not be negative",
+			req.Workers, transfer.MaxWorkers, req.BandwidthLimit)
+	}
+
+	var err error
+	if req.SrcPath, err = CleanFilePath(ctx, req.SrcPath); err != nil {
+		return err
+	}
+	if req.DstPath, err = CleanFilePath(ctx, req.DstPath); err != nil {
+		return err
+	}
+	req.SrcPath, req.DstPath = "/"+req.SrcPath, "/"+req.DstPath
+	return nil
+}
+
+// CreateFsSyncTask creates the task which copies or syncs data between file systems, and runs it in background
+func (s *FileSystemService) CreateFsSyncTask(ctx *logger.RequestContext,
+	req *CreateFsSyncTaskRequest) (*model.FsSyncTask, error) {
+	if err := req.validate(ctx); err != nil {
+		return nil, err
+	}
+	owner := req.Username
+	if owner == "" {
+		owner = ctx.UserName
+	}
+	srcFsID, dstFsID := common.FsOrSnapshotID(owner, req.SrcFsName), common.ID(owner, req.DstFsName)
+	for _, fsID := range []string{srcFsID, dstFsID} {
+		if err := s.checkFsAccess(ctx, fsID); err != nil {
+			return nil, err
+		}
+	}
+	if srcFsID == dstFsID && (isSubPath(req.SrcPath, req.DstPath) || isSubPath(req.DstPath, req.SrcPath)) {
+		ctx.ErrorCode = common.InvalidFsSyncTask
+		return nil, fmt.Errorf("srcPath[%s] and dstPath[%s] of the same fs must not overlap", req.SrcPath,
+			req.DstPath)
+	}
+
+	task := &model.FsSyncTask{
+		Model:          model.Model{ID: uuid.GenerateID(common.PrefixFsSync)},
+		UserName:       ctx.UserName,
+		SrcFsID:        srcFsID,
+		SrcPath:        req.SrcPath,
+		DstFsID:        dstFsID,
+		DstPath:        req.DstPath,
+		Mode:           req.Mode,
+		Compare:        req.Compare,
+		Workers:        req.Workers,
+		BandwidthLimit: req.BandwidthLimit,
+		DryRun:         req.DryRun,
+		Status:         model.FsSyncTaskStatusPending,
+	}
+	if err := storage.Filesystem.CreateFsSyncTask(task); err != nil {
+		ctx.Logging().Errorf("create sync task in db err: %v", err)
+		ctx.ErrorCode = common.FileSystemDataBaseError
+		return nil, err
+	}
+	if err := startFsSyncTask(task); err != nil {
+		ctx.ErrorCode = common.FileSystemDataBaseError
+		return nil, err
+	}
+	return task, nil
+}
+
+func isSubPath(parent, child string) bool {
+	return parent == "/" || child == parent || strings.HasPrefix(child, parent+"/")
+}
+
+// GetFsSyncTask returns the task created by the request user, root can get all tasks
+func (s *FileSystemService) GetFsSyncTask(ctx *logger.RequestContext, id string) (model.FsSyncTask, error) {
+	task, err := storage.Filesystem.GetFsSyncTask(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.ErrorCode = common.FsSyncTaskNotFound
+			return task, fmt.Errorf("sync task[%s] not found", id)
+		}
+		ctx.ErrorCode = common.FileSystemDataBaseError
+		return task, err
+	}
+	if !common.IsRootUser(ctx.UserName) && task.UserName != ctx.UserName {
+		ctx.ErrorCode = common.AccessDenied
+		return model.FsSyncTask{}, fmt.Errorf("user[%s] has no permission to sync task[%s]", ctx.UserName, id)
+	}
+	return task, nil
+}
+
+// ListFsSyncTask lists the tasks created by the request user, or all tasks for root
+func (s *FileSystemService) ListFsSyncTask(ctx *logger.RequestContext, status string) ([]model.FsSyncTask, error) {
+	userName := ctx.UserName
+	if common.IsRootUser(userName) {
+		userName = ""
+	}
+	var statusList []string
+	if status != "" {
+		statusList = strings.Split(status, common.SeparatorComma)
+	}
+	tasks, err := storage.Filesystem.ListFsSyncTask(userName, statusList)
+	if err != nil {
+		ctx.Logging().Errorf("list sync tasks err: %v", err)
+		ctx.ErrorCode = common.FileSystemDataBaseError
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// UpdateFsSyncTask stops the running task, or resumes the finished task from its checkpoint
+func (s *FileSystemService) UpdateFsSyncTask(ctx *logger.RequestContext, id, action string) (model.FsSyncTask,
+	error) {
+	task, err := s.GetFsSyncTask(ctx, id)
+	if err != nil {
+		return task, err
+	}
+	switch action {
+	case FsSyncTaskActionStop:
+		if task.IsFinal() {
+			ctx.ErrorCode = common.ActionNotAllowed
+			return task, fmt.Errorf("sync task[%s] is %s", id, task.Status)
+		}
+		// the task not running in this server is stopped in db directly, and the server running it cancels
+		// the task when it finds the status on the next progress report
+		if !cancelFsSyncTask(id) {
+			task.Status = model.FsSyncTaskStatusStopped
+			err = storage.Filesystem.UpdateFsSyncTask(&task)
+		}
+	case FsSyncTaskActionResume:
+		if !task.IsFinal() {
+			ctx.ErrorCode = common.ActionNotAllowed
+			return task, fmt.Errorf("sync task[%s] is %s", id, task.Status)
+		}
+		err = startFsSyncTask(&task)
+	default:
+		ctx.ErrorCode = common.InvalidFsSyncTask
+		return task, fmt.Errorf("action[%s] must be %s or %s", action, FsSyncTaskActionStop,
+			FsSyncTaskActionResume)
+	}
+	if err != nil {
+		ctx.Logging().Errorf("%s sync task[%s] err: %v", action, id, err)
+		ctx.ErrorCode = common.FileSystemDataBaseError
+		return task, err
+	}
+	return s.GetFsSyncTask(ctx, id)
+}
+
+// DeleteFsSyncTask deletes the record of task which is not running, the transferred data is kept
+func (s *FileSystemService) DeleteFsSyncTask(ctx *logger.RequestContext, id string) error {
+	task, err := s.GetFsSyncTask(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !task.IsFinal() {
+		ctx.ErrorCode = common.ActionNotAllowed
+		return fmt.Errorf("sync task[%s] is %s, stop it before deletion", id, task.Status)
+	}
+	if err = storage.Filesystem.DeleteFsSyncTask(id); err != nil {
+		ctx.ErrorCode = common.FileSystemDataBaseError
+		return err
+	}
+	return nil
+}
+
+// ResumeFsSyncTasks periodically resumes the unfinished tasks from their checkpoints, which are not updated for
+// fsSyncTaskStaleTimeout since interrupted by restart of server. Tasks are claimed in db before resumed, so that
+// each task is resumed by only one of the server replicas.
+func ResumeFsSyncTasks(stopChan chan struct{}) {
+	ticker := time.NewTicker(fsSyncTaskStaleTimeout)
+	defer ticker.Stop()
+	for {
+		resumeStaleFsSyncTasks(time.Now().Add(-fsSyncTaskStaleTimeout))
+		select {
+		case <-ticker.C:
+		case <-stopChan:
+			return
+		}
+	}
+}
+
+func resumeStaleFsSyncTasks(staleBefore time.Time) {
+	tasks, err := storage.Filesystem.ListFsSyncTask("", []string{model.FsSyncTaskStatusPending,
+		model.FsSyncTaskStatusRunning})
+	if err != nil {
+		log.Errorf("list unfinished sync tasks err: %v", err)
+		return
+	}
+	for i := range tasks {
+		if !tasks[i].UpdatedAt.Before(staleBefore) {
+			continue
+		}
+		if err = resumeFsSyncTask(&tasks[i], staleBefore); err != nil {
+			log.Errorf("resume sync task[%s] err: %v", tasks[i].ID, err)
+		}
+	}
+}
+
+// resumeFsSyncTask runs the stale task in background if it is claimed by this server
+func resumeFsSyncTask(task *model.FsSyncTask, staleBefore time.Time) error {
+	fsSyncMutex.Lock()
+	defer fsSyncMutex.Unlock()
+	if _, ok := fsSyncCancels[task.ID]; ok {
+		return nil
+	}
+	claimed, err := storage.Filesystem.ClaimFsSyncTask(task.ID, staleBefore)
+	if err != nil || !claimed {
+		return err
+	}
+	log.Infof("resume sync task[%s] from checkpoint[%s]", task.ID, task.Checkpoint)
+	resetFsSyncTask(task)
+	runFsSyncTaskInBackground(task)
+	return nil
+}
+
+// startFsSyncTask runs the task in background, the progress is kept if it has a checkpoint, otherwise the task
+// runs from beginning
+func startFsSyncTask(task *model.FsSyncTask) error {
+	fsSyncMutex.Lock()
+	defer fsSyncMutex.Unlock()
+	if _, ok := fsSyncCancels[task.ID]; ok {
+		return nil
+	}
+	resetFsSyncTask(task)
+	if err := storage.Filesystem.UpdateFsSyncTask(task); err != nil {
+		return err
+	}
+	runFsSyncTaskInBackground(task)
+	return nil
+}
+
+func resetFsSyncTask(task *model.FsSyncTask) {
+	if task.Checkpoint == "" {
+		task.Total, task.Copied, task.Skipped, task.Deleted, task.Failed, task.CopiedBytes = 0, 0, 0, 0, 0, 0
+		task.Diff = ""
+	}
+	task.Status, task.Message = model.FsSyncTaskStatusRunning, ""
+}
+
+// runFsSyncTaskInBackground runs the task, fsSyncMutex must be held by caller
+func runFsSyncTaskInBackground(task *model.FsSyncTask) {
+	ctx, cancel := context.WithCancel(context.Background())
+	fsSyncCancels[task.ID] = cancel
+	go runFsSyncTask(ctx, *task)
+}
+
+// cancelFsSyncTask cancels the task running in this server, returns false if not found
+func cancelFsSyncTask(id string) bool {
+	fsSyncMutex.Lock()
+	defer fsSyncMutex.Unlock()
+	cancel, ok := fsSyncCancels[id]
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
+func runFsSyncTask(ctx context.Context, task model.FsSyncTask) {
+	defer func() {
+		fsSyncMutex.Lock()
+		delete(fsSyncCancels, task.ID)
+		fsSyncMutex.Unlock()
+	}()
+
+	progress, err := transferFsSyncTask(ctx, &task)
+	setFsSyncTaskProgress(&task, progress)
+	switch {
+	case ctx.Err() != nil:
+		task.Status = model.FsSyncTaskStatusStopped
+	case err != nil:
+		log.Errorf("sync task[%s] err: %v", task.ID, err)
+		task.Status, task.Message = model.FsSyncTaskStatusFailed, err.Error()
+	case progress.Failed > 0:
+		task.Status = model.FsSyncTaskStatusFailed
+		task.Message = fmt.Sprintf("%d paths failed, last error: %s", progress.Failed, progress.LastError)
+	default:
+		task.Status = model.FsSyncTaskStatusSucceeded
+	}
+	// checkpoint is cleared when all paths are done, so that the task runs from beginning if resumed,
+	// otherwise it is resumed from the first failed path
+	if err == nil && ctx.Err() == nil && progress.Failed == 0 {
+		task.Checkpoint = ""
+	}
+	// the task stopped through other servers keeps its status in db, and only the progress is saved
+	finished, err := storage.Filesystem.FinishFsSyncTask(&task)
+	if err == nil && !finished {
+		log.Infof("sync task[%s] is not running in db, keep its status", task.ID)
+		err = storage.Filesystem.UpdateFsSyncTaskProgress(&task)
+	}
+	if err != nil {
+		log.Errorf("update sync task[%s] in db err: %v", task.ID, err)
+	}
+}
+
+func transferFsSyncTask(ctx context.Context, task *model.FsSyncTask) (transfer.Progress, error) {
+	progress := transfer.Progress{
+		Total:       task.Total,
+		Copied:      task.Copied,
+		Skipped:     task.Skipped,
+		Deleted:     task.Deleted,
+		Failed:      task.Failed,
+		CopiedBytes: task.CopiedBytes,
+		Checkpoint:  task.Checkpoint,
+	}
+	if task.Diff != "" {
+		progress.Diff = strings.Split(task.Diff, "\n")
+	}
+	src, err := NewFsClient(task.SrcFsID)
+	if err != nil {
+		return progress, fmt.Errorf("new client of source fs[%s] failed: %v", task.SrcFsID, err)
+	}
+	defer src.Close()
+	dst, err := NewFsClient(task.DstFsID)
+	if err != nil {
+		return progress, fmt.Errorf("new client of destination fs[%s] failed: %v", task.DstFsID, err)
+	}
+	defer dst.Close()
+
+	syncer := transfer.NewSyncer(src, task.SrcPath, dst, task.DstPath, transfer.Options{
+		Mode:           task.Mode,
+		Compare:        task.Compare,
+		Workers:        task.Workers,
+		BandwidthLimit: task.BandwidthLimit,
+		DryRun:         task.DryRun,
+	})
+	syncer.Resume(progress)
+	return syncer.Run(ctx, func(progress transfer.Progress) {
+		running := *task
+		setFsSyncTaskProgress(&running, progress)
+		if err := storage.Filesystem.UpdateFsSyncTaskProgress(&running); err != nil {
+			log.Errorf("update progress of sync task[%s] err: %v", task.ID, err)
+		}
+		checkFsSyncTaskRunning(task.ID)
+	})
+}
+
+// checkFsSyncTaskRunning cancels the task running in this server if it is stopped or deleted through other servers
+func checkFsSyncTaskRunning(id string) {
+	current, err := storage.Filesystem.GetFsSyncTask(id)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Errorf("get sync task[%s] err: %v", id, err)
+		return
+	}
+	if err == nil && current.Status == model.FsSyncTaskStatusRunning {
+		return
+	}
+	log.Infof("sync task[%s] is not running in db, cancel it", id)
+	cancelFsSyncTask(id)
+}
+
+func setFsSyncTaskProgress(task *model.FsSyncTask, progress transfer.Progress) {
+	task.Total = progress.Total
+	task.Copied = progress.Copied
+	task.Skipped = progress.Skipped
+	task.Deleted = progress.Deleted
+	task.Failed = progress.Failed
+	task.CopiedBytes = progress.CopiedBytes
+	task.Checkpoint = progress.Checkpoint
+	task.Diff = strings.Join(progress.Diff, "\n")
+}
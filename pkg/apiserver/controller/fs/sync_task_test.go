@@ -0,0 +1,73 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fs
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	fuse "github.com/PaddlePaddle/PaddleFlow/pkg/fs/client/fs"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage/driver"
+)
+
+func TestFsSyncTaskStoppedByOthers(t *testing.T) {
+	driver.InitMockDB()
+	newFsClient := NewFsClient
+	defer func() { NewFsClient = newFsClient }()
+	NewFsClient = func(fsID string) (fuse.FSClient, error) {
+		return nil, fmt.Errorf("fs[%s] is unavailable", fsID)
+	}
+
+	task := &model.FsSyncTask{
+		Model:  model.Model{ID: "fssync-stopped"},
+		Status: model.FsSyncTaskStatusRunning,
+	}
+	assert.NoError(t, storage.Filesystem.CreateFsSyncTask(task))
+	ctx, cancel := context.WithCancel(context.Background())
+	fsSyncMutex.Lock()
+	fsSyncCancels[task.ID] = cancel
+	fsSyncMutex.Unlock()
+
+	// the running task is kept
+	checkFsSyncTaskRunning(task.ID)
+	assert.NoError(t, ctx.Err())
+
+	// the task stopped through other servers is canceled, and its status is not overwritten by the runner
+	stopped := *task
+	stopped.Status = model.FsSyncTaskStatusStopped
+	assert.NoError(t, storage.Filesystem.UpdateFsSyncTask(&stopped))
+	checkFsSyncTaskRunning(task.ID)
+	assert.Error(t, ctx.Err())
+	runFsSyncTask(context.Background(), *task)
+	current, err := storage.Filesystem.GetFsSyncTask(task.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.FsSyncTaskStatusStopped, current.Status)
+	assert.Empty(t, current.Message)
+
+	// the runner finishes the task which is still running in db
+	task.ID = "fssync-failed"
+	assert.NoError(t, storage.Filesystem.CreateFsSyncTask(task))
+	runFsSyncTask(context.Background(), *task)
+	current, err = storage.Filesystem.GetFsSyncTask(task.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.FsSyncTaskStatusFailed, current.Status)
+}
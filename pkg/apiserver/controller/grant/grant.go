@@ -21,8 +21,10 @@ import (
 	"fmt"
 
 	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/controller/fs"
 	gormErrors "github.com/PaddlePaddle/PaddleFlow/pkg/common/errors"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/logger"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
 )
@@ -136,6 +138,9 @@ func CreateGrant(ctx *logger.RequestContext, grantInfo CreateGrantRequest) (*Cre
 		}
 		return nil, err
 	}
+	if grant.ResourceType == common.ResourceTypeFs {
+		fs.RecordFsAudit(ctx, grant.ResourceID, schema.FsAuditOpGrant, "", fmt.Sprintf("grantee=%s", grant.UserName))
+	}
 	response := &CreateGrantResponse{
 		GrantID: grant.ID,
 	}
@@ -184,6 +189,9 @@ func DeleteGrant(ctx *logger.RequestContext, userName, resourceID, resourceType
 			userName, resourceID)
 		return err
 	}
+	if resourceType == common.ResourceTypeFs {
+		fs.RecordFsAudit(ctx, resourceID, schema.FsAuditOpRevoke, "", fmt.Sprintf("grantee=%s", userName))
+	}
 	return nil
 }
 
@@ -35,8 +35,12 @@ import (
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/logger"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/resources"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/tracing"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/utils"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/uuid"
+	fsCommon "github.com/PaddlePaddle/PaddleFlow/pkg/fs/common"
+	locationAwareness "github.com/PaddlePaddle/PaddleFlow/pkg/fs/location-awareness"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/placement"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/metrics"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
@@ -54,6 +58,10 @@ type CreateJobInfo struct {
 	Mode              string                 `json:"mode,omitempty"`
 	Members           []MemberSpec           `json:"members"`
 	ExtensionTemplate map[string]interface{} `json:"extensionTemplate,omitempty"`
+	// Notebook is the interactive development server of notebook job
+	Notebook *schema.NotebookConf `json:"notebook,omitempty"`
+	// Serving is the model server config of serving job
+	Serving *schema.ServingConf `json:"serving,omitempty"`
 }
 
 func init() {
@@ -63,7 +71,26 @@ func init() {
 
 // CreatePFJob handler for creating job
 func CreatePFJob(ctx *logger.RequestContext, request *CreateJobInfo) (*CreateJobResponse, error) {
-	log.Debugf("Create PF job with request: %#v", request)
+	jobInfo, err := newPFJob(ctx, request, false)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.Logging().Debugf("create distributed job %#v", jobInfo)
+	if err = storage.Job.CreateJob(jobInfo); err != nil {
+		ctx.Logging().Errorf("create job[%s] in database faield, err: %v", jobInfo.Config.GetName(), err)
+		return nil, fmt.Errorf("create job[%s] in database faield, err: %v", jobInfo.Config.GetName(), err)
+	}
+
+	ctx.Logging().Infof("create job[%s] successful.", jobInfo.ID)
+	return &CreateJobResponse{
+		ID: jobInfo.ID,
+	}, nil
+}
+
+// newPFJob validates the request and builds job from it, the job is not stored in database
+func newPFJob(ctx *logger.RequestContext, request *CreateJobInfo, dryRun bool) (*model.Job, error) {
+	log.Debugf("Create PF job with request: %#v, dry run: %v", request, dryRun)
 	request.UserName = ctx.UserName
 	// validate Job
 	// gen jobID if not presented in request
@@ -76,7 +103,14 @@ func CreatePFJob(ctx *logger.RequestContext, request *CreateJobInfo) (*CreateJob
 		return nil, err
 	}
 	// add time point for job create request
-	metrics.Job.AddTimestamp(request.ID, metrics.T1, time.Now())
+	if !dryRun {
+		metrics.Job.AddTimestamp(request.ID, metrics.T1, time.Now())
+	}
+	// place job submitted to virtual queue into one of its member queues
+	if err := placeVirtualQueue(ctx, &request.CommonJobInfo, request.Members); err != nil {
+		ctx.Logging().Errorf("place job %s failed, err: %v", request.ID, err)
+		return nil, err
+	}
 	if err := validateJob(ctx, request); err != nil {
 		ctx.Logging().Errorf("validate job request failed. request:%v error:%s", request, err.Error())
 		return nil, err
@@ -88,17 +122,11 @@ func CreatePFJob(ctx *logger.RequestContext, request *CreateJobInfo) (*CreateJob
 		ctx.Logging().Errorf("patch envs when creating job %s failed, err=%v", request.CommonJobInfo.Name, err)
 		return nil, err
 	}
-
-	ctx.Logging().Debugf("create distributed job %#v", jobInfo)
-	if err = storage.Job.CreateJob(jobInfo); err != nil {
-		ctx.Logging().Errorf("create job[%s] in database faield, err: %v", jobInfo.Config.GetName(), err)
-		return nil, fmt.Errorf("create job[%s] in database faield, err: %v", jobInfo.Config.GetName(), err)
+	// propagate trace context of request to job, which is the parent of job submission
+	if ctx.TraceParent != "" && jobInfo.Config.GetEnvValue(tracing.EnvTraceParent) == "" {
+		jobInfo.Config.SetEnv(tracing.EnvTraceParent, ctx.TraceParent)
 	}
-
-	ctx.Logging().Infof("create job[%s] successful.", jobInfo.ID)
-	return &CreateJobResponse{
-		ID: jobInfo.ID,
-	}, nil
+	return jobInfo, nil
 }
 
 func validateJob(ctx *logger.RequestContext, request *CreateJobInfo) error {
@@ -113,6 +141,18 @@ func validateJob(ctx *logger.RequestContext, request *CreateJobInfo) error {
 		ctx.Logging().Errorf("validate job framework failed, err: %v", err)
 		return err
 	}
+	if request.Type == schema.TypeNotebook {
+		if err := validateNotebook(ctx, request); err != nil {
+			ctx.Logging().Errorf("validate notebook failed, err: %v", err)
+			return err
+		}
+	}
+	if request.Type == schema.TypeServing {
+		if err := validateServing(ctx, request); err != nil {
+			ctx.Logging().Errorf("validate serving failed, err: %v", err)
+			return err
+		}
+	}
 
 	if len(request.ExtensionTemplate) != 0 {
 		// validate extension template from user
@@ -279,10 +319,60 @@ func validateCommonJobInfo(ctx *logger.RequestContext, requestCommonJobInfo *Com
 		ctx.ErrorCode = common.JobInvalidField
 		return err
 	}
+	if err := validateJobDependencies(ctx, requestCommonJobInfo); err != nil {
+		ctx.Logging().Errorf("validate job dependencies failed, err: %v", err)
+		return err
+	}
 
 	return nil
 }
 
+// validateJobDependencies check that prerequisite jobs exist and are accessible by user
+func validateJobDependencies(ctx *logger.RequestContext, commonJobInfo *CommonJobInfo) error {
+	if len(commonJobInfo.DependsOn) == 0 {
+		if commonJobInfo.DependencyCondition != "" {
+			ctx.ErrorCode = common.JobInvalidField
+			return fmt.Errorf("dependencyCondition is set, but dependsOn is empty")
+		}
+		return nil
+	}
+	switch commonJobInfo.DependencyCondition {
+	case "":
+		commonJobInfo.DependencyCondition = schema.DependencySucceeded
+	case schema.DependencySucceeded, schema.DependencyFinished:
+	default:
+		ctx.ErrorCode = common.JobInvalidField
+		return fmt.Errorf("dependencyCondition %s is not supported, only %s and %s are supported",
+			commonJobInfo.DependencyCondition, schema.DependencySucceeded, schema.DependencyFinished)
+	}
+
+	dependsOn := make([]string, 0, len(commonJobInfo.DependsOn))
+	visited := make(map[string]bool)
+	for _, jobID := range commonJobInfo.DependsOn {
+		if jobID == "" || visited[jobID] {
+			continue
+		}
+		visited[jobID] = true
+		if jobID == commonJobInfo.ID {
+			ctx.ErrorCode = common.JobInvalidField
+			return fmt.Errorf("job %s cannot depend on itself", jobID)
+		}
+		// prerequisite jobs must exist before dependent job, so that there is no cycle in dependencies
+		job, err := storage.Job.GetJobByID(jobID)
+		if err != nil {
+			ctx.ErrorCode = common.JobNotFound
+			return fmt.Errorf("prerequisite job %s is not found, err: %v", jobID, err)
+		}
+		if err = common.CheckPermission(ctx.UserName, job.UserName, common.ResourceTypeJob, jobID); err != nil {
+			ctx.ErrorCode = common.ActionNotAllowed
+			return err
+		}
+		dependsOn = append(dependsOn, jobID)
+	}
+	commonJobInfo.DependsOn = dependsOn
+	return nil
+}
+
 func validateMembersRole(ctx *logger.RequestContext, request *CreateJobInfo) error {
 	log.Infof("validate job %s MembersRole", request.Name)
 	frameworkRoles := getFrameworkRoles(request.Framework)
@@ -295,6 +385,14 @@ func validateMembersRole(ctx *logger.RequestContext, request *CreateJobInfo) err
 			return err
 		}
 		frameworkRoles[memberRole] = frameworkRoles[memberRole] + member.Replicas
+		if err := validateMemberElastic(&member, request.Framework); err != nil {
+			ctx.Logging().Errorf("Failed to check Members' elastic replicas, err: %v", err)
+			return err
+		}
+	}
+	if request.Type == schema.TypeServing {
+		// replicas of serving job is checked by validateServing
+		return nil
 	}
 	var err error
 	request.Mode, err = checkMemberRole(request.Framework, frameworkRoles)
@@ -330,6 +428,10 @@ func validateJobMembers(ctx *logger.RequestContext, request *CreateJobInfo) erro
 			return err
 		}
 	}
+	if request.Type == schema.TypeServing {
+		// replicas of serving job is checked by validateServing
+		return nil
+	}
 	// validate queue and total-member-resource
 	var err error
 	request.Mode, err = checkMemberRole(request.Framework, frameworkRoles)
@@ -357,6 +459,10 @@ func validateMember(ctx *logger.RequestContext, member *MemberSpec, framework sc
 		return err
 	}
 	frameworkRoles[memberRole] = frameworkRoles[memberRole] + member.Replicas
+	if err := validateMemberElastic(member, framework); err != nil {
+		ctx.Logging().Errorf("Failed to check Members' elastic replicas, err: %v", err)
+		return err
+	}
 	// TODO: move more check to checkJobSpec
 	err := checkJobSpec(ctx, &member.JobSpec)
 	if err != nil {
@@ -376,6 +482,28 @@ func validateMember(ctx *logger.RequestContext, member *MemberSpec, framework sc
 	return nil
 }
 
+// validateMemberElastic checks the bounds of replicas for elastic member
+func validateMemberElastic(member *MemberSpec, framework schema.Framework) error {
+	if member.MaxReplicas == 0 && member.MinReplicas == 0 {
+		return nil
+	}
+	if !isElasticFramework(framework) {
+		return fmt.Errorf("elastic replicas is not supported by framework %s", framework)
+	}
+	if member.MinReplicas < 1 || member.MaxReplicas < member.MinReplicas {
+		return fmt.Errorf("the bounds [%d, %d] of replicas is invalid", member.MinReplicas, member.MaxReplicas)
+	}
+	if member.Replicas < member.MinReplicas || member.Replicas > member.MaxReplicas {
+		return fmt.Errorf("the replicas %d is out of range [%d, %d]", member.Replicas, member.MinReplicas, member.MaxReplicas)
+	}
+	return nil
+}
+
+// isElasticFramework returns true if replicas of job can be scaled at runtime
+func isElasticFramework(framework schema.Framework) bool {
+	return framework == schema.FrameworkPaddle || framework == schema.FrameworkPytorch
+}
+
 func checkJobSpec(ctx *logger.RequestContext, jobSpec *JobSpec) error {
 	port := jobSpec.Port
 	if port != 0 && !(port > 0 && port < common.JobPortMaximums) {
@@ -397,6 +525,11 @@ func checkJobSpec(ctx *logger.RequestContext, jobSpec *JobSpec) error {
 		ctx.Logging().Errorf("validateFileSystem failed, requestJobSpec[%v], err: %v", jobSpec, err)
 		return err
 	}
+	if _, err := locationAwareness.ParseLocalityMode(jobSpec.Env[schema.EnvJobFsLocality]); err != nil {
+		ctx.Logging().Errorf("validate fs locality failed, err: %v", err)
+		ctx.ErrorCode = common.InvalidArguments
+		return err
+	}
 	return nil
 }
 
@@ -437,6 +570,41 @@ func validateQueue(ctx *logger.RequestContext, schedulingPolicy *SchedulingPolic
 	return nil
 }
 
+// placeVirtualQueue replaces the virtual queue of job with the member queue picked by its placement policy,
+// and records the virtual queue in job annotations, which is used to resubmit job when its cluster is offline
+func placeVirtualQueue(ctx *logger.RequestContext, request *CommonJobInfo, members []MemberSpec) error {
+	vq, find := placement.GetVirtualQueue(request.SchedulingPolicy.Queue)
+	if !find {
+		return nil
+	}
+	var fsIDs []string
+	for _, member := range members {
+		for _, fs := range append([]schema.FileSystem{member.FileSystem}, member.ExtraFileSystems...) {
+			if fs.ID != "" {
+				fsIDs = append(fsIDs, fs.ID)
+			} else if fs.Name != "" {
+				fsIDs = append(fsIDs, common.FsOrSnapshotID(ctx.UserName, fs.Name))
+			}
+		}
+	}
+	queue, err := placement.SelectQueue(vq, fsIDs)
+	if err != nil {
+		ctx.ErrorCode = common.JobInvalidField
+		return err
+	}
+	request.SchedulingPolicy.Queue = queue.Name
+	for idx := range members {
+		if members[idx].SchedulingPolicy.Queue == vq.Name {
+			members[idx].SchedulingPolicy.Queue = queue.Name
+		}
+	}
+	if request.Annotations == nil {
+		request.Annotations = make(map[string]string)
+	}
+	request.Annotations[placement.VirtualQueueAnnotation] = vq.Name
+	return nil
+}
+
 // checkPriority check priority and fill parent's priority if schedulingPolicy.Priority is empty
 func checkPriority(schedulingPolicy, parentSP *SchedulingPolicy) error {
 	priority := strings.ToUpper(schedulingPolicy.Priority)
@@ -497,7 +665,7 @@ func validateFileSystem(userName string, fs *schema.FileSystem) error {
 	fsName := fs.Name
 	if fs.ID == "" {
 		// generate fsID by fsName if fsID is nil
-		fs.ID = common.ID(userName, fsName)
+		fs.ID = common.FsOrSnapshotID(userName, fsName)
 	}
 	if fs.MountPath == "" {
 		log.Debugf("mountPath is %s, changes to .", fs.MountPath)
@@ -510,7 +678,7 @@ func validateFileSystem(userName string, fs *schema.FileSystem) error {
 		return err
 	}
 
-	fileSystem, err := storage.Filesystem.GetFileSystemWithFsID(fs.ID)
+	fileSystem, err := storage.Filesystem.GetFileSystemOrSnapshot(fs.ID)
 	if err != nil {
 		log.Errorf("get filesystem by userName[%s] fsName[%s] fsID[%s] failed, err: %v", userName, fsName, fs.ID, err)
 		return fmt.Errorf("find file system %s failed, err: %v", fsName, err)
@@ -518,8 +686,16 @@ func validateFileSystem(userName string, fs *schema.FileSystem) error {
 	// fill back
 	fs.Name = fileSystem.Name
 	fs.Type = fileSystem.Type
+	snapshot := fileSystem.PropertiesMap[fsCommon.Snapshot]
+	if snapshot != "" {
+		// snapshots are always mounted read-only
+		fs.ReadOnly = true
+	}
 	if fileSystem.Type == schema.PFSTypeLocal {
 		fs.HostPath = fileSystem.SubPath
+		if snapshot != "" {
+			fs.HostPath = filepath.Join(fileSystem.SubPath, fsCommon.SnapshotDir, snapshot)
+		}
 	}
 
 	return nil
@@ -549,6 +725,14 @@ func validateJobFramework(ctx *logger.RequestContext, jobType schema.JobType, fr
 		default:
 			err = fmt.Errorf("invalid framework %s for distributed job", framework)
 		}
+	case schema.TypeNotebook:
+		if framework != schema.FrameworkStandalone {
+			err = fmt.Errorf("framework for notebook job must be standalone")
+		}
+	case schema.TypeServing:
+		if framework != schema.FrameworkStandalone && framework != schema.FrameworkKServe {
+			err = fmt.Errorf("framework for serving job must be standalone or kserve")
+		}
 	case schema.TypeWorkflow:
 		// TODO: add check for workflow
 	default:
@@ -561,6 +745,78 @@ func validateJobFramework(ctx *logger.RequestContext, jobType schema.JobType, fr
 	return err
 }
 
+// validateNotebook validates the kind, idle timeout and ingress of notebook, and fills the default values
+func validateNotebook(ctx *logger.RequestContext, request *CreateJobInfo) error {
+	if request.Notebook == nil {
+		request.Notebook = &schema.NotebookConf{}
+	}
+	notebook := request.Notebook
+	var err error
+	switch notebook.Kind {
+	case "":
+		notebook.Kind = schema.NotebookJupyter
+	case schema.NotebookJupyter, schema.NotebookVSCode:
+	default:
+		err = fmt.Errorf("notebook kind %s is not supported, only %s and %s are supported",
+			notebook.Kind, schema.NotebookJupyter, schema.NotebookVSCode)
+	}
+	if notebook.IdleTimeout < 0 {
+		err = fmt.Errorf("idleTimeout of notebook must be greater than or equal to 0")
+	} else if notebook.IdleTimeout == 0 {
+		notebook.IdleTimeout = config.GlobalServerConfig.Job.Notebook.IdleTimeoutSeconds
+	}
+	if notebook.Ingress && config.GlobalServerConfig.Job.Notebook.IngressDomain == "" {
+		err = fmt.Errorf("notebook ingress is not supported, ingress domain is not configured")
+	}
+	if err == nil && len(request.Members) == 1 && request.Members[0].Port == 0 {
+		request.Members[0].Port = notebook.DefaultPort()
+	}
+	if err != nil {
+		ctx.ErrorCode = common.JobInvalidField
+	}
+	return err
+}
+
+// validateServing validates the members, port and autoscaling of serving job, and fills the default values
+func validateServing(ctx *logger.RequestContext, request *CreateJobInfo) error {
+	if request.Serving == nil {
+		request.Serving = &schema.ServingConf{}
+	}
+	serving := request.Serving
+	var err error
+	if len(request.Members) != 1 {
+		err = fmt.Errorf("serving job must have only one member")
+	} else if request.Members[0].Replicas < 1 {
+		err = fmt.Errorf("replicas of serving job must be greater than 0")
+	}
+	if err == nil && serving.Autoscaling != nil {
+		err = validateServingAutoscaling(serving.Autoscaling)
+		if err == nil && request.Members[0].Replicas != serving.Autoscaling.MinReplicas {
+			err = fmt.Errorf("replicas of serving job must be equal to minReplicas of autoscaling")
+		}
+	}
+	if err != nil {
+		ctx.ErrorCode = common.JobInvalidField
+		return err
+	}
+	if request.Members[0].Port == 0 {
+		request.Members[0].Port = schema.DefaultServingPort
+	}
+	// the canary rollout only takes effect on update
+	serving.CanaryTrafficPercent = nil
+	return nil
+}
+
+func validateServingAutoscaling(autoscaling *schema.ServingAutoscaling) error {
+	if autoscaling.MinReplicas < 1 || autoscaling.MaxReplicas < autoscaling.MinReplicas {
+		return fmt.Errorf("autoscaling of serving job must satisfy 1 <= minReplicas <= maxReplicas")
+	}
+	if autoscaling.TargetCPUUtilization < 0 || autoscaling.TargetCPUUtilization > 100 {
+		return fmt.Errorf("targetCPUUtilization of serving job must be between 0 and 100")
+	}
+	return nil
+}
+
 func checkMemberRole(framework schema.Framework, roles map[schema.MemberRole]int) (string, error) {
 	var err error
 	var jobMode string
@@ -609,7 +865,7 @@ func getFrameworkRoles(framework schema.Framework) map[schema.MemberRole]int {
 	case schema.FrameworkMPI, schema.FrameworkRay:
 		roles[schema.RoleMaster] = 0
 		roles[schema.RoleWorker] = 0
-	case schema.FrameworkStandalone:
+	case schema.FrameworkStandalone, schema.FrameworkKServe:
 		roles[schema.RoleWorker] = 0
 	}
 	return roles
@@ -656,8 +912,9 @@ func buildMainConf(request *CreateJobInfo) *schema.Conf {
 	var conf = &schema.Conf{
 		Name: request.Name,
 	}
-	if request.Type == schema.TypeSingle && len(request.Members) == 1 {
-		// build conf for single job
+	if (request.Type == schema.TypeSingle || request.Type == schema.TypeNotebook ||
+		request.Type == schema.TypeServing) && len(request.Members) == 1 {
+		// build conf for single job, notebook job and serving job
 		conf = &schema.Conf{
 			Name:            request.Name,
 			FileSystem:      request.Members[0].FileSystem,
@@ -676,6 +933,11 @@ func buildMainConf(request *CreateJobInfo) *schema.Conf {
 	if request.SchedulingPolicy.Priority != "" {
 		conf.Priority = request.SchedulingPolicy.Priority
 	}
+	// dependencies only take effect on main job config
+	conf.DependsOn = request.DependsOn
+	conf.DependencyCondition = request.DependencyCondition
+	conf.Notebook = request.Notebook
+	conf.Serving = request.Serving
 	// TODO: remove job mode
 	conf.SetEnv(schema.EnvJobMode, request.Mode)
 	return conf
@@ -732,10 +994,12 @@ func newMember(member MemberSpec, role schema.MemberRole) schema.Member {
 	}
 
 	return schema.Member{
-		ID:       member.ID,
-		Role:     role,
-		Replicas: member.Replicas,
-		Conf:     conf,
+		ID:          member.ID,
+		Role:        role,
+		Replicas:    member.Replicas,
+		MinReplicas: member.MinReplicas,
+		MaxReplicas: member.MaxReplicas,
+		Conf:        conf,
 	}
 }
 
@@ -756,11 +1020,30 @@ func newExtensionTemplateJson(extensionTemplate map[string]interface{}) (string,
 
 // CreateWorkflowJob handler for creating job
 func CreateWorkflowJob(ctx *logger.RequestContext, request *CreateWfJobRequest) (*CreateJobResponse, error) {
+	jobInfo, err := newWorkflowJob(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if err := storage.Job.CreateJob(jobInfo); err != nil {
+		log.Errorf("create job[%s] in database faield, err: %v", jobInfo.Config.GetName(), err)
+		return nil, fmt.Errorf("create job[%s] in database faield, err: %v", jobInfo.Config.GetName(), err)
+	}
+	log.Infof("create job[%s] successful.", jobInfo.ID)
+	return &CreateJobResponse{ID: jobInfo.ID}, nil
+}
+
+// newWorkflowJob validates the request and builds workflow job from it, the job is not stored in database
+func newWorkflowJob(ctx *logger.RequestContext, request *CreateWfJobRequest) (*model.Job, error) {
 	if err := common.CheckPermission(ctx.UserName, ctx.UserName, common.ResourceTypeJob, request.ID); err != nil {
 		ctx.ErrorCode = common.ActionNotAllowed
 		ctx.Logging().Errorln(err.Error())
 		return nil, err
 	}
+	// place workflow job submitted to virtual queue into one of its member queues
+	if err := placeVirtualQueue(ctx, &request.CommonJobInfo, request.Members); err != nil {
+		ctx.Logging().Errorf("place workflow job %s failed, err: %v", request.ID, err)
+		return nil, err
+	}
 	if err := validateWorkflowJob(ctx, request); err != nil {
 		ctx.Logging().Errorf("validate job request failed. request:%v error:%s", request, err.Error())
 		return nil, err
@@ -780,6 +1063,9 @@ func CreateWorkflowJob(ctx *logger.RequestContext, request *CreateWfJobRequest)
 		Labels:      request.Labels,
 		Annotations: request.Annotations,
 		Priority:    request.SchedulingPolicy.Priority,
+		// job dependencies
+		DependsOn:           request.DependsOn,
+		DependencyCondition: request.DependencyCondition,
 	}
 	// validate queue
 	if err := validateQueue(ctx, &request.SchedulingPolicy); err != nil {
@@ -803,13 +1089,7 @@ func CreateWorkflowJob(ctx *logger.RequestContext, request *CreateWfJobRequest)
 		Config:            &conf,
 		ExtensionTemplate: templateJson,
 	}
-
-	if err := storage.Job.CreateJob(jobInfo); err != nil {
-		log.Errorf("create job[%s] in database faield, err: %v", conf.GetName(), err)
-		return nil, fmt.Errorf("create job[%s] in database faield, err: %v", conf.GetName(), err)
-	}
-	log.Infof("create job[%s] successful.", jobInfo.ID)
-	return &CreateJobResponse{ID: jobInfo.ID}, nil
+	return jobInfo, nil
 }
 
 func validateWorkflowJob(ctx *logger.RequestContext, request *CreateWfJobRequest) error {
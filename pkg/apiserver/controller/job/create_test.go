@@ -12,6 +12,7 @@ import (
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/resources"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/uuid"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/placement"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/storage/driver"
@@ -1181,6 +1182,32 @@ func TestCreateWorkflowJob(t *testing.T) {
 
 }
 
+func TestCreateWorkflowJobInVirtualQueue(t *testing.T) {
+	initTestData(t)
+	config.GlobalServerConfig.Job.VirtualQueues = []config.VirtualQueueConfig{
+		{Name: "vq", Queues: []string{MockQueueName}},
+	}
+	assert.NoError(t, storage.Cluster.UpdateClusterStatus(MockClusterName, model.ClusterStatusOnLine, ""))
+	placement.ClusterIdleResource = func(cluster model.ClusterInfo) (*resources.Resource, error) {
+		return resources.NewResourceFromMap(map[string]string{"cpu": "8", "memory": "16Gi"})
+	}
+
+	req := &CreateWfJobRequest{
+		CommonJobInfo: CommonJobInfo{
+			Name:             "test-wf",
+			SchedulingPolicy: SchedulingPolicy{Queue: "vq"},
+		},
+		ExtensionTemplate: map[string]interface{}{"a": "b"},
+	}
+	res, err := CreateWorkflowJob(&logger.RequestContext{UserName: mockRootUser}, req)
+	assert.NoError(t, err)
+	job, err := storage.Job.GetJobByID(res.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, MockQueueID, job.QueueID)
+	assert.Equal(t, MockQueueName, job.Config.GetQueueName())
+	assert.Equal(t, "vq", job.Config.GetAnnotations()[placement.VirtualQueueAnnotation])
+}
+
 func TestCreatePPLJob(t *testing.T) {
 	driver.InitMockDB()
 	config.GlobalServerConfig = &config.ServerConfig{}
@@ -1369,3 +1396,213 @@ func initTestData(t *testing.T) {
 	assert.NoError(t, err)
 	return
 }
+
+func TestValidateJobDependencies(t *testing.T) {
+	driver.InitMockDB()
+	ctx := &logger.RequestContext{UserName: "user1"}
+	assert.NoError(t, storage.Job.CreateJob(&model.Job{
+		ID: "job-pre-1", UserName: "user1", Status: schema.StatusJobRunning, Config: &schema.Conf{}}))
+	assert.NoError(t, storage.Job.CreateJob(&model.Job{
+		ID: "job-pre-2", UserName: "user2", Status: schema.StatusJobRunning, Config: &schema.Conf{}}))
+
+	testCases := []struct {
+		name          string
+		info          CommonJobInfo
+		wantErr       bool
+		wantDependsOn []string
+		wantCondition schema.DependencyCondition
+	}{
+		{
+			name: "no dependencies",
+			info: CommonJobInfo{ID: "job-1"},
+		},
+		{
+			name:    "condition without dependencies",
+			info:    CommonJobInfo{ID: "job-1", DependencyCondition: schema.DependencyFinished},
+			wantErr: true,
+		},
+		{
+			name:          "duplicated dependencies and default condition",
+			info:          CommonJobInfo{ID: "job-1", DependsOn: []string{"job-pre-1", "job-pre-1"}},
+			wantDependsOn: []string{"job-pre-1"},
+			wantCondition: schema.DependencySucceeded,
+		},
+		{
+			name:    "invalid condition",
+			info:    CommonJobInfo{ID: "job-1", DependsOn: []string{"job-pre-1"}, DependencyCondition: "started"},
+			wantErr: true,
+		},
+		{
+			name:    "depends on itself",
+			info:    CommonJobInfo{ID: "job-1", DependsOn: []string{"job-1"}},
+			wantErr: true,
+		},
+		{
+			name:    "prerequisite not found",
+			info:    CommonJobInfo{ID: "job-1", DependsOn: []string{"job-not-exist"}},
+			wantErr: true,
+		},
+		{
+			name:    "prerequisite of other user",
+			info:    CommonJobInfo{ID: "job-1", DependsOn: []string{"job-pre-2"}},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateJobDependencies(ctx, &tc.info)
+			if tc.wantErr {
+				assert.Error(t, err)
+				t.Logf("validate job dependencies failed, err: %v", err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantDependsOn, tc.info.DependsOn)
+			assert.Equal(t, tc.wantCondition, tc.info.DependencyCondition)
+		})
+	}
+}
+
+func TestValidateNotebook(t *testing.T) {
+	config.GlobalServerConfig = &config.ServerConfig{}
+	config.GlobalServerConfig.Job.Notebook.IdleTimeoutSeconds = 3600
+	ctx := &logger.RequestContext{UserName: mockRootUser}
+
+	testCases := []struct {
+		name          string
+		notebook      *schema.NotebookConf
+		ingressDomain string
+		wantErr       bool
+		wantNotebook  *schema.NotebookConf
+		wantPort      int
+	}{
+		{
+			name:         "default notebook",
+			wantNotebook: &schema.NotebookConf{Kind: schema.NotebookJupyter, IdleTimeout: 3600},
+			wantPort:     schema.DefaultJupyterPort,
+		},
+		{
+			name:          "vscode with ingress",
+			notebook:      &schema.NotebookConf{Kind: schema.NotebookVSCode, IdleTimeout: 60, Ingress: true},
+			ingressDomain: "notebook.example.com",
+			wantNotebook:  &schema.NotebookConf{Kind: schema.NotebookVSCode, IdleTimeout: 60, Ingress: true},
+			wantPort:      schema.DefaultVSCodePort,
+		},
+		{
+			name:     "unsupported kind",
+			notebook: &schema.NotebookConf{Kind: "rstudio"},
+			wantErr:  true,
+		},
+		{
+			name:     "negative idle timeout",
+			notebook: &schema.NotebookConf{IdleTimeout: -1},
+			wantErr:  true,
+		},
+		{
+			name:     "ingress domain not configured",
+			notebook: &schema.NotebookConf{Ingress: true},
+			wantErr:  true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config.GlobalServerConfig.Job.Notebook.IngressDomain = tc.ingressDomain
+			request := &CreateJobInfo{
+				Type:      schema.TypeNotebook,
+				Framework: schema.FrameworkStandalone,
+				Notebook:  tc.notebook,
+				Members:   []MemberSpec{{Role: string(schema.RoleWorker), Replicas: 1}},
+			}
+			err := validateNotebook(ctx, request)
+			if tc.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, common.JobInvalidField, ctx.ErrorCode)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantNotebook, request.Notebook)
+			assert.Equal(t, tc.wantPort, request.Members[0].Port)
+		})
+	}
+}
+
+func TestValidateServing(t *testing.T) {
+	ctx := &logger.RequestContext{UserName: mockRootUser}
+	percent := 50
+
+	testCases := []struct {
+		name        string
+		serving     *schema.ServingConf
+		members     []MemberSpec
+		wantErr     bool
+		wantServing *schema.ServingConf
+	}{
+		{
+			name:        "default serving",
+			members:     []MemberSpec{{Role: string(schema.RoleWorker), Replicas: 2}},
+			wantServing: &schema.ServingConf{},
+		},
+		{
+			name: "autoscaling and canary cleared",
+			serving: &schema.ServingConf{
+				Autoscaling:          &schema.ServingAutoscaling{MinReplicas: 2, MaxReplicas: 4},
+				CanaryTrafficPercent: &percent,
+			},
+			members: []MemberSpec{{Role: string(schema.RoleWorker), Replicas: 2}},
+			wantServing: &schema.ServingConf{
+				Autoscaling: &schema.ServingAutoscaling{MinReplicas: 2, MaxReplicas: 4},
+			},
+		},
+		{
+			name: "max replicas less than min replicas",
+			serving: &schema.ServingConf{
+				Autoscaling: &schema.ServingAutoscaling{MinReplicas: 3, MaxReplicas: 2},
+			},
+			members: []MemberSpec{{Role: string(schema.RoleWorker), Replicas: 3}},
+			wantErr: true,
+		},
+		{
+			name: "invalid target cpu utilization",
+			serving: &schema.ServingConf{
+				Autoscaling: &schema.ServingAutoscaling{MinReplicas: 1, MaxReplicas: 2, TargetCPUUtilization: 120},
+			},
+			members: []MemberSpec{{Role: string(schema.RoleWorker), Replicas: 1}},
+			wantErr: true,
+		},
+		{
+			name: "replicas not equal to min replicas",
+			serving: &schema.ServingConf{
+				Autoscaling: &schema.ServingAutoscaling{MinReplicas: 1, MaxReplicas: 2},
+			},
+			members: []MemberSpec{{Role: string(schema.RoleWorker), Replicas: 2}},
+			wantErr: true,
+		},
+		{
+			name: "multiple members",
+			members: []MemberSpec{
+				{Role: string(schema.RoleWorker), Replicas: 1},
+				{Role: string(schema.RoleWorker), Replicas: 1},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := &CreateJobInfo{
+				Type:      schema.TypeServing,
+				Framework: schema.FrameworkStandalone,
+				Serving:   tc.serving,
+				Members:   tc.members,
+			}
+			err := validateServing(ctx, request)
+			if tc.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, common.JobInvalidField, ctx.ErrorCode)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantServing, request.Serving)
+			assert.Equal(t, schema.DefaultServingPort, request.Members[0].Port)
+		})
+	}
+}
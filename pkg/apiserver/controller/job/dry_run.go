@@ -0,0 +1,82 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"fmt"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/logger"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/api"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
+)
+
+// DryRunJobResponse is the response of creating job in dry run mode
+type DryRunJobResponse struct {
+	ID        string `json:"id"`
+	ClusterID string `json:"clusterID"`
+	QueueID   string `json:"queueID"`
+	// Objects are the rendered objects which would be submitted to cluster, such as Pod, PaddleJob and Workflow
+	Objects []interface{} `json:"objects"`
+}
+
+// DryRunPFJob runs all validations of single or distributed job, and renders the objects which would be
+// submitted to cluster, nothing is stored in database or created on cluster.
+func DryRunPFJob(ctx *logger.RequestContext, request *CreateJobInfo) (*DryRunJobResponse, error) {
+	jobInfo, err := newPFJob(ctx, request, true)
+	if err != nil {
+		return nil, err
+	}
+	return renderJob(ctx, jobInfo)
+}
+
+// DryRunWorkflowJob runs all validations of workflow job, and renders the workflow which would be submitted to cluster
+func DryRunWorkflowJob(ctx *logger.RequestContext, request *CreateWfJobRequest) (*DryRunJobResponse, error) {
+	jobInfo, err := newWorkflowJob(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return renderJob(ctx, jobInfo)
+}
+
+func renderJob(ctx *logger.RequestContext, jobInfo *model.Job) (*DryRunJobResponse, error) {
+	runtimeSvc, err := getRuntimeByQueue(ctx, jobInfo.QueueID)
+	if err != nil {
+		ctx.ErrorCode = common.InternalError
+		ctx.Logging().Errorf("get runtime of job[%s] failed, err: %v", jobInfo.ID, err)
+		return nil, fmt.Errorf("get runtime of job[%s] failed, err: %v", jobInfo.ID, err)
+	}
+	pfJob, err := api.NewJobInfo(jobInfo)
+	if err != nil {
+		ctx.ErrorCode = common.InternalError
+		ctx.Logging().Errorf("convert job[%s] failed, err: %v", jobInfo.ID, err)
+		return nil, err
+	}
+	objects, err := runtimeSvc.RenderJob(pfJob)
+	if err != nil {
+		ctx.ErrorCode = common.JobCreateFailed
+		ctx.Logging().Errorf("render job[%s] failed, err: %v", jobInfo.ID, err)
+		return nil, fmt.Errorf("render job[%s] failed, err: %v", jobInfo.ID, err)
+	}
+	ctx.Logging().Infof("render job[%s] successful, %d objects are rendered", jobInfo.ID, len(objects))
+	return &DryRunJobResponse{
+		ID:        jobInfo.ID,
+		ClusterID: jobInfo.Config.GetClusterID(),
+		QueueID:   jobInfo.QueueID,
+		Objects:   objects,
+	}, nil
+}
@@ -0,0 +1,132 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/config"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/logger"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/resources"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/api"
+	runtime "github.com/PaddlePaddle/PaddleFlow/pkg/job/runtime_v2"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage/driver"
+)
+
+func TestDryRunJob(t *testing.T) {
+	driver.InitMockDB()
+	config.GlobalServerConfig = &config.ServerConfig{}
+	config.GlobalServerConfig.Job.IsSingleCluster = true
+	ctx := &logger.RequestContext{UserName: mockRootUser}
+
+	assert.NoError(t, storage.Cluster.CreateCluster(&model.ClusterInfo{
+		Model:       model.Model{ID: MockClusterName},
+		Name:        MockClusterName,
+		ClusterType: schema.KubernetesType,
+	}))
+	maxRes, err := resources.NewResourceFromMap(map[string]string{
+		resources.ResCPU:    "10",
+		resources.ResMemory: "20Gi",
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, storage.Queue.CreateQueue(&model.Queue{
+		Model:        model.Model{ID: MockQueueID},
+		Name:         MockQueueName,
+		Namespace:    "default",
+		MaxResources: maxRes,
+		MinResources: maxRes,
+		QuotaType:    schema.TypeVolcanoCapabilityQuota,
+		ClusterId:    MockClusterName,
+		ClusterName:  MockClusterName,
+		Status:       "open",
+	}))
+
+	kubeRuntime := runtime.NewKubeRuntime(schema.Cluster{})
+	p1 := gomonkey.ApplyFunc(runtime.GetOrCreateRuntime, func(clusterInfo model.ClusterInfo) (runtime.RuntimeService, error) {
+		return kubeRuntime, nil
+	})
+	defer p1.Reset()
+	var renderedJob *api.PFJob
+	p2 := gomonkey.ApplyMethod(reflect.TypeOf(kubeRuntime), "RenderJob", func(_ *runtime.KubeRuntime, job *api.PFJob) ([]interface{}, error) {
+		renderedJob = job
+		return []interface{}{map[string]interface{}{"kind": "Pod", "apiVersion": "v1"}}, nil
+	})
+	defer p2.Reset()
+
+	// dry run single job
+	resp, err := DryRunPFJob(ctx, &CreateJobInfo{
+		CommonJobInfo: CommonJobInfo{
+			Name:             "dry-run",
+			SchedulingPolicy: SchedulingPolicy{Queue: MockQueueName},
+		},
+		Type:      schema.TypeSingle,
+		Framework: schema.FrameworkStandalone,
+		Members: []MemberSpec{
+			{
+				Role:     string(schema.RoleWorker),
+				Replicas: 1,
+				JobSpec: JobSpec{
+					Image:   "busybox",
+					Command: "sleep 60",
+					Flavour: schema.Flavour{ResourceInfo: schema.ResourceInfo{CPU: "1", Mem: "1Gi"}},
+				},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(resp.Objects))
+	assert.Equal(t, MockQueueID, resp.QueueID)
+	assert.Equal(t, resp.ID, renderedJob.ID)
+	assert.Equal(t, "default", renderedJob.Namespace)
+	// nothing is stored in database
+	_, err = storage.Job.GetJobByID(resp.ID)
+	assert.Error(t, err)
+
+	// validation failed
+	_, err = DryRunPFJob(ctx, &CreateJobInfo{
+		CommonJobInfo: CommonJobInfo{
+			Name:             "dry-run",
+			SchedulingPolicy: SchedulingPolicy{Queue: "not-exist"},
+		},
+		Type:      schema.TypeSingle,
+		Framework: schema.FrameworkStandalone,
+	})
+	assert.Error(t, err)
+
+	// dry run workflow job
+	resp, err = DryRunWorkflowJob(ctx, &CreateWfJobRequest{
+		CommonJobInfo: CommonJobInfo{
+			ID:               "wf-dry-run",
+			Name:             "wf-dry-run",
+			UserName:         mockRootUser,
+			SchedulingPolicy: SchedulingPolicy{Queue: MockQueueName},
+		},
+		ExtensionTemplate: map[string]interface{}{"kind": "Workflow"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "wf-dry-run", resp.ID)
+	assert.Equal(t, schema.TypeWorkflow, renderedJob.JobType)
+	_, err = storage.Job.GetJobByID("wf-dry-run")
+	assert.Error(t, err)
+}
@@ -87,6 +87,8 @@ type GetJobResponse struct {
 	Runtime                *RuntimeInfo            `json:"runtime,omitempty"`
 	DistributedRuntime     *DistributedRuntimeInfo `json:"distributedRuntime,omitempty"`
 	WorkflowRuntime        *WorkflowRuntimeInfo    `json:"workflowRuntime,omitempty"`
+	Notebook               *schema.NotebookConf    `json:"notebook,omitempty"`
+	Serving                *schema.ServingConf     `json:"serving,omitempty"`
 	UpdateTime             time.Time               `json:"-"`
 }
 
@@ -97,6 +99,8 @@ type RuntimeInfo struct {
 	Status    string `json:"status,omitempty"`
 	NodeName  string `json:"nodeName"`
 	LogURL    string `json:"logURL,omitempty"`
+	// ConnectURL is the url to access interactive job or serving job, such as notebook
+	ConnectURL string `json:"connectURL,omitempty"`
 }
 
 type DistributedRuntimeInfo struct {
@@ -248,16 +252,23 @@ func convertJobToResponse(job model.Job, runtimeFlag bool) (GetJobResponse, erro
 	if job.Config != nil {
 		response.Labels = job.Config.Labels
 		response.Annotations = job.Config.Annotations
+		response.DependsOn = job.Config.DependsOn
+		response.DependencyCondition = job.Config.DependencyCondition
 	}
 	// process runtime info && member
 	switch job.Type {
-	case string(schema.TypeSingle):
+	case string(schema.TypeSingle), string(schema.TypeNotebook), string(schema.TypeServing):
+		if job.Config != nil {
+			response.Notebook = job.Config.Notebook
+			response.Serving = job.Config.Serving
+		}
 		if runtimeFlag && job.RuntimeInfo != nil {
 			runtimes, err := getTaskRuntime(job.ID)
 			if err != nil || len(runtimes) < 1 {
 				return response, err
 			}
 			response.Runtime = &runtimes[0]
+			response.Runtime.ConnectURL = getConnectURL(job.RuntimeInfo)
 		}
 		var jobSpec JobSpec
 		if err := json.Unmarshal([]byte(job.ConfigJson), &jobSpec); err != nil {
@@ -344,6 +355,16 @@ func parseK8sMeta(runtimeInfo interface{}) (metav1.ObjectMeta, error) {
 	return k8sMeta, nil
 }
 
+// getConnectURL returns the connect url of interactive job from its runtime info
+func getConnectURL(runtimeInfo interface{}) string {
+	info, ok := runtimeInfo.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	connectURL, _ := info[schema.JobRuntimeConnectURLKey].(string)
+	return connectURL
+}
+
 func getTaskRuntime(jobID string) ([]RuntimeInfo, error) {
 	tasks, err := storage.Job.ListByJobID(jobID)
 	if err != nil {
@@ -54,6 +54,71 @@ func (sj CreateSingleJobRequest) ToJobInfo() *CreateJobInfo {
 	}
 }
 
+// CreateNotebookJobRequest convey request for create notebook job, which runs an interactive development server
+type CreateNotebookJobRequest struct {
+	CommonJobInfo `json:",inline"`
+	JobSpec       `json:",inline"`
+	Notebook      schema.NotebookConf `json:"notebook"`
+}
+
+func (nj CreateNotebookJobRequest) ToJobInfo() *CreateJobInfo {
+	notebook := nj.Notebook
+	return &CreateJobInfo{
+		CommonJobInfo: nj.CommonJobInfo,
+		Framework:     schema.FrameworkStandalone,
+		Type:          schema.TypeNotebook,
+		Members: []MemberSpec{
+			{
+				CommonJobInfo: nj.CommonJobInfo,
+				JobSpec:       nj.JobSpec,
+				Role:          string(schema.RoleWorker),
+				Replicas:      1,
+			},
+		},
+		ExtensionTemplate: nj.JobSpec.ExtensionTemplate,
+		Notebook:          &notebook,
+	}
+}
+
+// CreateServingJobRequest convey request for create serving job, which runs a long-running model server
+type CreateServingJobRequest struct {
+	CommonJobInfo `json:",inline"`
+	JobSpec       `json:",inline"`
+	// Framework is standalone or kserve, and standalone serving job runs with deployment
+	Framework schema.Framework   `json:"framework,omitempty"`
+	Replicas  int                `json:"replicas,omitempty"`
+	Serving   schema.ServingConf `json:"serving"`
+}
+
+func (sj CreateServingJobRequest) ToJobInfo() *CreateJobInfo {
+	serving := sj.Serving
+	framework := sj.Framework
+	if framework == "" {
+		framework = schema.FrameworkStandalone
+	}
+	replicas := sj.Replicas
+	if serving.Autoscaling != nil {
+		replicas = serving.Autoscaling.MinReplicas
+	} else if replicas == 0 {
+		replicas = 1
+	}
+	return &CreateJobInfo{
+		CommonJobInfo: sj.CommonJobInfo,
+		Framework:     framework,
+		Type:          schema.TypeServing,
+		Members: []MemberSpec{
+			{
+				CommonJobInfo: sj.CommonJobInfo,
+				JobSpec:       sj.JobSpec,
+				Role:          string(schema.RoleWorker),
+				Replicas:      replicas,
+			},
+		},
+		ExtensionTemplate: sj.JobSpec.ExtensionTemplate,
+		Serving:           &serving,
+	}
+}
+
 // CreateDisJobRequest convey request for create distributed job
 type CreateDisJobRequest struct {
 	CommonJobInfo     `json:",inline"`
@@ -88,6 +153,9 @@ type CommonJobInfo struct {
 	Annotations      map[string]string `json:"annotations"`
 	SchedulingPolicy SchedulingPolicy  `json:"schedulingPolicy"`
 	UserName         string            `json:",omitempty"`
+	// DependsOn is the prerequisite job ids, job is held until all of them meet DependencyCondition
+	DependsOn           []string                   `json:"dependsOn,omitempty"`
+	DependencyCondition schema.DependencyCondition `json:"dependencyCondition,omitempty"`
 }
 
 // SchedulingPolicy indicate queueID/priority
@@ -120,6 +188,9 @@ type MemberSpec struct {
 	JobSpec       `json:",inline"`
 	Role          string `json:"role"`
 	Replicas      int    `json:"replicas"`
+	// MinReplicas and MaxReplicas are the bounds of replicas for elastic training
+	MinReplicas int `json:"minReplicas,omitempty"`
+	MaxReplicas int `json:"maxReplicas,omitempty"`
 }
 
 type UpdateJobRequest struct {
@@ -127,6 +198,22 @@ type UpdateJobRequest struct {
 	Priority    string            `json:"priority"`
 	Labels      map[string]string `json:"labels"`
 	Annotations map[string]string `json:"annotations"`
+	// Replicas of members to scale for elastic job, the key is role of member
+	Replicas map[schema.MemberRole]int `json:"replicas,omitempty"`
+	// Serving rolls out a new revision or updates autoscaling of serving job
+	Serving *UpdateServingRequest `json:"serving,omitempty"`
+}
+
+// UpdateServingRequest convey request for update serving job, the changed image, command or env is rolled out
+// as the latest revision, and canaryTrafficPercent routes part of traffic to it before promoting
+type UpdateServingRequest struct {
+	Image       string                     `json:"image,omitempty"`
+	Command     string                     `json:"command,omitempty"`
+	Env         map[string]string          `json:"env,omitempty"`
+	Replicas    int                        `json:"replicas,omitempty"`
+	Autoscaling *schema.ServingAutoscaling `json:"autoscaling,omitempty"`
+	// CanaryTrafficPercent is the traffic percent of latest revision, and 100 or empty promotes the latest revision
+	CanaryTrafficPercent *int `json:"canaryTrafficPercent,omitempty"`
 }
 
 // CreateJobResponse convey response for create job
@@ -229,6 +316,12 @@ func UpdateJob(ctx *logger.RequestContext, request *UpdateJobRequest) error {
 
 	// check job status when update job on cluster
 	needUpdateCluster := false
+	if request.Priority != "" && job.Type == string(schema.TypeServing) {
+		ctx.ErrorCode = common.ActionNotAllowed
+		err = fmt.Errorf("the priority of serving job %s cannot be updated", job.ID)
+		log.Errorln(err)
+		return err
+	}
 	if request.Priority != "" {
 		// need to update job priority
 		if job.Status != schema.StatusJobPending && job.Status != schema.StatusJobInit {
@@ -244,6 +337,22 @@ func UpdateJob(ctx *logger.RequestContext, request *UpdateJobRequest) error {
 			needUpdateCluster = true
 		}
 	}
+	if len(request.Replicas) != 0 {
+		// scale replicas of elastic job, and the job is pending or running
+		if err = scaleJobMembers(ctx, &job, request.Replicas); err != nil {
+			log.Errorf("scale job %s failed, err: %v", job.ID, err)
+			return err
+		}
+		needUpdateCluster = true
+	}
+	if request.Serving != nil {
+		// roll out serving job, and the job is pending or running
+		if err = updateServing(ctx, &job, request.Serving); err != nil {
+			log.Errorf("update serving job %s failed, err: %v", job.ID, err)
+			return err
+		}
+		needUpdateCluster = true
+	}
 
 	if needUpdateCluster {
 		// update job on cluster
@@ -270,10 +379,183 @@ func UpdateJob(ctx *logger.RequestContext, request *UpdateJobRequest) error {
 	if err != nil {
 		log.Errorf("update job %s on database failed, err: %v", job.ID, err)
 		ctx.ErrorCode = common.DBUpdateFailed
+		return err
+	}
+	if len(request.Replicas) != 0 || request.Serving != nil {
+		if err = storage.Job.UpdateJobMembers(job.ID, job.Members); err != nil {
+			log.Errorf("update members of job %s on database failed, err: %v", job.ID, err)
+			ctx.ErrorCode = common.DBUpdateFailed
+		}
 	}
 	return err
 }
 
+// scaleJobMembers validates the replicas of elastic members and the quota of queue, and then updates members of job
+func scaleJobMembers(ctx *logger.RequestContext, job *model.Job, replicas map[schema.MemberRole]int) error {
+	if job.Status != schema.StatusJobPending && job.Status != schema.StatusJobRunning {
+		ctx.ErrorCode = common.ActionNotAllowed
+		return fmt.Errorf("the status of job %s is %s, job replicas cannot be scaled", job.ID, job.Status)
+	}
+	if job.Type != string(schema.TypeDistributed) || !isElasticFramework(job.Framework) {
+		ctx.ErrorCode = common.ActionNotAllowed
+		return fmt.Errorf("the replicas of %s job with framework %s cannot be scaled", job.Type, job.Framework)
+	}
+	members := make([]schema.Member, len(job.Members))
+	copy(members, job.Members)
+	for role, value := range replicas {
+		found := false
+		for index := range members {
+			if members[index].Role != role {
+				continue
+			}
+			if err := members[index].ValidateReplicas(value); err != nil {
+				ctx.ErrorCode = common.JobInvalidField
+				return err
+			}
+			members[index].Replicas = value
+			found = true
+		}
+		if !found {
+			ctx.ErrorCode = common.JobInvalidField
+			return fmt.Errorf("member with role %s is not found in job %s", role, job.ID)
+		}
+	}
+	scaledJob := *job
+	scaledJob.Members = members
+	if err := checkScaledJobQuota(ctx, &scaledJob); err != nil {
+		return err
+	}
+	job.Members = members
+	return nil
+}
+
+// updateServing validates the rollout of serving job, and then updates the member and serving config of job
+func updateServing(ctx *logger.RequestContext, job *model.Job, request *UpdateServingRequest) error {
+	if job.Type != string(schema.TypeServing) || len(job.Members) != 1 {
+		ctx.ErrorCode = common.ActionNotAllowed
+		return fmt.Errorf("job %s is not a serving job, serving config cannot be updated", job.ID)
+	}
+	if job.Status != schema.StatusJobPending && job.Status != schema.StatusJobRunning {
+		ctx.ErrorCode = common.ActionNotAllowed
+		return fmt.Errorf("the status of job %s is %s, serving job cannot be updated", job.ID, job.Status)
+	}
+	serving := schema.ServingConf{}
+	if job.Config.Serving != nil {
+		serving = *job.Config.Serving
+	}
+	member := job.Members[0]
+	specChanged := false
+	if request.Image != "" && request.Image != member.Image {
+		member.Image = request.Image
+		specChanged = true
+	}
+	if request.Command != "" && request.Command != member.Command {
+		member.Command = request.Command
+		specChanged = true
+	}
+	if len(request.Env) != 0 {
+		env := make(map[string]string)
+		for key, value := range member.Env {
+			env[key] = value
+		}
+		for key, value := range request.Env {
+			if env[key] != value {
+				env[key] = value
+				specChanged = true
+			}
+		}
+		member.Env = env
+	}
+	if request.Autoscaling != nil {
+		if err := validateServingAutoscaling(request.Autoscaling); err != nil {
+			ctx.ErrorCode = common.JobInvalidField
+			return err
+		}
+		autoscaling := *request.Autoscaling
+		serving.Autoscaling = &autoscaling
+		member.Replicas = autoscaling.MinReplicas
+	} else if request.Replicas != 0 {
+		if request.Replicas < 1 {
+			ctx.ErrorCode = common.JobInvalidField
+			return fmt.Errorf("replicas of serving job must be greater than 0")
+		}
+		// set replicas disables the autoscaling of serving job
+		serving.Autoscaling = nil
+		member.Replicas = request.Replicas
+	}
+
+	percent := request.CanaryTrafficPercent
+	if percent != nil && (*percent < 0 || *percent > 100) {
+		ctx.ErrorCode = common.JobInvalidField
+		return fmt.Errorf("canaryTrafficPercent of serving job must be between 0 and 100")
+	}
+	if percent != nil && *percent == 100 {
+		// promote the latest revision
+		percent = nil
+	}
+	if percent != nil && !specChanged && !serving.IsCanary() {
+		ctx.ErrorCode = common.JobInvalidField
+		return fmt.Errorf("serving job %s has no new revision to roll out with canaryTrafficPercent", job.ID)
+	}
+	serving.CanaryTrafficPercent = percent
+	// KServe routes traffic by weight, and standalone serving job splits traffic by the ratio of replicas
+	if job.Framework != schema.FrameworkKServe {
+		if err := serving.ValidateCanaryReplicas(member.Replicas); err != nil {
+			ctx.ErrorCode = common.JobInvalidField
+			return err
+		}
+	}
+
+	if member.Replicas != job.Members[0].Replicas {
+		scaledJob := *job
+		scaledJob.Members = []schema.Member{member}
+		if err := checkScaledJobQuota(ctx, &scaledJob); err != nil {
+			return err
+		}
+	}
+	job.Members = []schema.Member{member}
+	job.Config.Serving = &serving
+	return nil
+}
+
+// checkScaledJobQuota rechecks the max resources of queue with the scaled job and other running jobs in queue
+func checkScaledJobQuota(ctx *logger.RequestContext, job *model.Job) error {
+	if IsSkipResourceValidate {
+		return nil
+	}
+	queue, err := storage.Queue.GetQueueByID(job.QueueID)
+	if err != nil {
+		ctx.ErrorCode = common.QueueNameNotFound
+		return fmt.Errorf("get queue %s of job %s failed, err: %v", job.QueueID, job.ID, err)
+	}
+	if queue.MaxResources == nil ||
+		queue.QuotaType == schema.TypeVolcanoCapabilityQuota && queue.MaxResources.IsZero() {
+		return nil
+	}
+	used, err := storage.JobRequestResource(*job)
+	if err != nil {
+		ctx.ErrorCode = common.InternalError
+		return err
+	}
+	for _, queueJob := range storage.Job.ListQueueJob(queue.ID, []schema.JobStatus{schema.StatusJobRunning}) {
+		if queueJob.ID == job.ID {
+			continue
+		}
+		res, err := storage.JobRequestResource(queueJob)
+		if err != nil {
+			log.Warningf("get request resource of job %s failed, err: %v", queueJob.ID, err)
+			continue
+		}
+		used.Add(res)
+	}
+	if !used.LessEqual(queue.MaxResources) {
+		ctx.ErrorCode = common.QueueResourceNotMatch
+		return fmt.Errorf("the resources %v of queue %s after scaling exceed its max resources %v",
+			used, queue.Name, queue.MaxResources)
+	}
+	return nil
+}
+
 func updateRuntimeJob(ctx *logger.RequestContext, job *model.Job, request *UpdateJobRequest) error {
 	// update labels and annotations
 	runtimeSvc, err := getRuntimeByQueue(ctx, job.QueueID)
@@ -295,6 +577,9 @@ func updateRuntimeJob(ctx *logger.RequestContext, job *model.Job, request *Updat
 	if request.Priority != "" {
 		pfjob.UpdateJobPriority(request.Priority)
 	}
+	if len(request.Replicas) != 0 {
+		pfjob.UpdateReplicas(request.Replicas)
+	}
 	return runtimeSvc.UpdateJob(pfjob)
 }
 
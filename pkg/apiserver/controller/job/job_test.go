@@ -76,3 +76,285 @@ func TestDeleteJob(t *testing.T) {
 		})
 	}
 }
+
+func TestScaleJobMembers(t *testing.T) {
+	maxRes, err := resources.NewResourceFromMap(map[string]string{
+		resources.ResCPU:    "10",
+		resources.ResMemory: "20Gi",
+	})
+	assert.Equal(t, nil, err)
+
+	driver.InitMockDB()
+	config.GlobalServerConfig = &config.ServerConfig{}
+	config.GlobalServerConfig.Job.IsSingleCluster = true
+	err = storage.Cluster.CreateCluster(&model.ClusterInfo{
+		Model:       model.Model{ID: "MockClusterID"},
+		Name:        "MockClusterName",
+		ClusterType: schema.KubernetesType,
+	})
+	assert.Equal(t, nil, err)
+	err = storage.Queue.CreateQueue(&model.Queue{
+		Name:         MockQueueName,
+		Model:        model.Model{ID: MockQueueID},
+		Namespace:    "paddleflow",
+		ClusterId:    "MockClusterID",
+		ClusterName:  "MockClusterName",
+		QuotaType:    schema.TypeElasticQuota,
+		MaxResources: maxRes,
+		Status:       schema.StatusQueueOpen,
+	})
+	assert.Equal(t, nil, err)
+
+	newJob := func() *model.Job {
+		return &model.Job{
+			ID:        "job-elastic",
+			Type:      string(schema.TypeDistributed),
+			Framework: schema.FrameworkPaddle,
+			QueueID:   MockQueueID,
+			Status:    schema.StatusJobRunning,
+			Members: []schema.Member{
+				{
+					Role:        schema.RoleWorker,
+					Replicas:    2,
+					MinReplicas: 1,
+					MaxReplicas: 8,
+					Conf: schema.Conf{
+						Flavour: schema.Flavour{ResourceInfo: schema.ResourceInfo{CPU: "2", Mem: "2Gi"}},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		job      func() *model.Job
+		replicas map[schema.MemberRole]int
+		wantErr  bool
+	}{
+		{
+			name:     "scale out worker",
+			job:      newJob,
+			replicas: map[schema.MemberRole]int{schema.RoleWorker: 4},
+		},
+		{
+			name:     "replicas exceed max replicas",
+			job:      newJob,
+			replicas: map[schema.MemberRole]int{schema.RoleWorker: 9},
+			wantErr:  true,
+		},
+		{
+			name:     "resources exceed queue quota",
+			job:      newJob,
+			replicas: map[schema.MemberRole]int{schema.RoleWorker: 6},
+			wantErr:  true,
+		},
+		{
+			name:     "role not found",
+			job:      newJob,
+			replicas: map[schema.MemberRole]int{schema.RolePServer: 2},
+			wantErr:  true,
+		},
+		{
+			name: "job is not running",
+			job: func() *model.Job {
+				job := newJob()
+				job.Status = schema.StatusJobSucceeded
+				return job
+			},
+			replicas: map[schema.MemberRole]int{schema.RoleWorker: 4},
+			wantErr:  true,
+		},
+		{
+			name: "member is not elastic",
+			job: func() *model.Job {
+				job := newJob()
+				job.Members[0].MaxReplicas = 0
+				return job
+			},
+			replicas: map[schema.MemberRole]int{schema.RoleWorker: 4},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &logger.RequestContext{UserName: mockRootUser}
+			job := tt.job()
+			err := scaleJobMembers(ctx, job, tt.replicas)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			for role, replicas := range tt.replicas {
+				for _, member := range job.Members {
+					if member.Role == role {
+						assert.Equal(t, replicas, member.Replicas)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateServing(t *testing.T) {
+	maxRes, err := resources.NewResourceFromMap(map[string]string{
+		resources.ResCPU:    "10",
+		resources.ResMemory: "20Gi",
+	})
+	assert.Equal(t, nil, err)
+
+	driver.InitMockDB()
+	config.GlobalServerConfig = &config.ServerConfig{}
+	err = storage.Cluster.CreateCluster(&model.ClusterInfo{
+		Model:       model.Model{ID: "MockClusterID"},
+		Name:        "MockClusterName",
+		ClusterType: schema.KubernetesType,
+	})
+	assert.Equal(t, nil, err)
+	err = storage.Queue.CreateQueue(&model.Queue{
+		Name:         MockQueueName,
+		Model:        model.Model{ID: MockQueueID},
+		Namespace:    "paddleflow",
+		ClusterId:    "MockClusterID",
+		ClusterName:  "MockClusterName",
+		QuotaType:    schema.TypeElasticQuota,
+		MaxResources: maxRes,
+		Status:       schema.StatusQueueOpen,
+	})
+	assert.Equal(t, nil, err)
+
+	percent := func(value int) *int {
+		return &value
+	}
+	newJob := func() *model.Job {
+		return &model.Job{
+			ID:      "job-serving",
+			Type:    string(schema.TypeServing),
+			QueueID: MockQueueID,
+			Status:  schema.StatusJobRunning,
+			Config:  &schema.Conf{Serving: &schema.ServingConf{}},
+			Members: []schema.Member{
+				{
+					Role:     schema.RoleWorker,
+					Replicas: 2,
+					Conf: schema.Conf{
+						Image:   "model-server:v1",
+						Flavour: schema.Flavour{ResourceInfo: schema.ResourceInfo{CPU: "1", Mem: "1Gi"}},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		job          func() *model.Job
+		request      *UpdateServingRequest
+		wantErr      bool
+		wantImage    string
+		wantReplicas int
+		wantPercent  *int
+	}{
+		{
+			name:         "canary rollout",
+			job:          newJob,
+			request:      &UpdateServingRequest{Image: "model-server:v2", CanaryTrafficPercent: percent(50)},
+			wantImage:    "model-server:v2",
+			wantReplicas: 2,
+			wantPercent:  percent(50),
+		},
+		{
+			name: "kserve canary rollout",
+			job: func() *model.Job {
+				job := newJob()
+				job.Framework = schema.FrameworkKServe
+				return job
+			},
+			request:      &UpdateServingRequest{Image: "model-server:v2", CanaryTrafficPercent: percent(30)},
+			wantImage:    "model-server:v2",
+			wantReplicas: 2,
+			wantPercent:  percent(30),
+		},
+		{
+			name:    "canary percent cannot be represented by replicas",
+			job:     newJob,
+			request: &UpdateServingRequest{Image: "model-server:v2", CanaryTrafficPercent: percent(30)},
+			wantErr: true,
+		},
+		{
+			name: "canary percent cannot be represented by max replicas",
+			job:  newJob,
+			request: &UpdateServingRequest{Image: "model-server:v2", CanaryTrafficPercent: percent(50),
+				Autoscaling: &schema.ServingAutoscaling{MinReplicas: 2, MaxReplicas: 5}},
+			wantErr: true,
+		},
+		{
+			name: "promote latest revision",
+			job: func() *model.Job {
+				job := newJob()
+				job.Config.Serving.CanaryTrafficPercent = percent(30)
+				return job
+			},
+			request:      &UpdateServingRequest{CanaryTrafficPercent: percent(100)},
+			wantImage:    "model-server:v1",
+			wantReplicas: 2,
+		},
+		{
+			name:         "enable autoscaling",
+			job:          newJob,
+			request:      &UpdateServingRequest{Autoscaling: &schema.ServingAutoscaling{MinReplicas: 3, MaxReplicas: 5}},
+			wantImage:    "model-server:v1",
+			wantReplicas: 3,
+		},
+		{
+			name:    "canary without new revision",
+			job:     newJob,
+			request: &UpdateServingRequest{CanaryTrafficPercent: percent(30)},
+			wantErr: true,
+		},
+		{
+			name:    "invalid canary traffic percent",
+			job:     newJob,
+			request: &UpdateServingRequest{Image: "model-server:v2", CanaryTrafficPercent: percent(120)},
+			wantErr: true,
+		},
+		{
+			name: "job is not serving",
+			job: func() *model.Job {
+				job := newJob()
+				job.Type = string(schema.TypeSingle)
+				return job
+			},
+			request: &UpdateServingRequest{Image: "model-server:v2"},
+			wantErr: true,
+		},
+		{
+			name: "job is not running",
+			job: func() *model.Job {
+				job := newJob()
+				job.Status = schema.StatusJobTerminated
+				return job
+			},
+			request: &UpdateServingRequest{Image: "model-server:v2"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &logger.RequestContext{UserName: mockRootUser}
+			job := tt.job()
+			err := updateServing(ctx, job, tt.request)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantImage, job.Members[0].Image)
+			assert.Equal(t, tt.wantReplicas, job.Members[0].Replicas)
+			assert.Equal(t, tt.wantPercent, job.Config.Serving.CanaryTrafficPercent)
+		})
+	}
+}
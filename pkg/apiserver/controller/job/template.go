@@ -0,0 +1,614 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/router/util"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/logger"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
+)
+
+// templateParamRegexp matches the parameter reference {{name}} in job template
+var templateParamRegexp = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+type CreateJobTemplateRequest struct {
+	Name       string                        `json:"name"`
+	Desc       string                        `json:"desc"`
+	JobType    schema.JobType                `json:"jobType"`
+	Parameters []schema.JobTemplateParameter `json:"parameters"`
+	// Template is the request body of creating single, distributed or workflow job,
+	// and parameters are referenced as {{name}} in its string values
+	Template json.RawMessage `json:"template"`
+}
+
+type CreateJobTemplateResponse struct {
+	TemplateID        string `json:"templateID"`
+	TemplateVersionID string `json:"templateVersionID"`
+	Name              string `json:"name"`
+}
+
+type UpdateJobTemplateRequest struct {
+	Desc       string                        `json:"desc"`
+	JobType    schema.JobType                `json:"jobType"`
+	Parameters []schema.JobTemplateParameter `json:"parameters"`
+	Template   json.RawMessage               `json:"template"`
+}
+
+type UpdateJobTemplateResponse struct {
+	TemplateID        string `json:"templateID"`
+	TemplateVersionID string `json:"templateVersionID"`
+}
+
+type ListJobTemplateResponse struct {
+	common.MarkerInfo
+	TemplateList []JobTemplateBrief `json:"templateList"`
+}
+
+type GetJobTemplateResponse struct {
+	Template JobTemplateBrief          `json:"template"`
+	Versions []JobTemplateVersionBrief `json:"versions"`
+}
+
+type GetJobTemplateVersionResponse struct {
+	Template JobTemplateBrief        `json:"template"`
+	Version  JobTemplateVersionBrief `json:"version"`
+}
+
+// CreateJobFromTemplateRequest is the request of creating job by template, and the values of parameters
+// override their defaults
+type CreateJobFromTemplateRequest struct {
+	TemplateVersion string                 `json:"templateVersion"`
+	Parameters      map[string]interface{} `json:"parameters"`
+}
+
+type JobTemplateBrief struct {
+	ID         string `json:"templateID"`
+	Name       string `json:"name"`
+	Desc       string `json:"desc"`
+	UserName   string `json:"username"`
+	CreateTime string `json:"createTime"`
+	UpdateTime string `json:"updateTime"`
+}
+
+func (tb *JobTemplateBrief) updateFromModel(template model.JobTemplate) {
+	tb.ID = template.ID
+	tb.Name = template.Name
+	tb.Desc = template.Desc
+	tb.UserName = template.UserName
+	tb.CreateTime = template.CreatedAt.Format("2006-01-02 15:04:05")
+	tb.UpdateTime = template.UpdatedAt.Format("2006-01-02 15:04:05")
+}
+
+type JobTemplateVersionBrief struct {
+	ID         string                        `json:"templateVersionID"`
+	TemplateID string                        `json:"templateID"`
+	JobType    string                        `json:"jobType"`
+	Parameters []schema.JobTemplateParameter `json:"parameters"`
+	Template   json.RawMessage               `json:"template"`
+	UserName   string                        `json:"username"`
+	CreateTime string                        `json:"createTime"`
+}
+
+func (vb *JobTemplateVersionBrief) updateFromModel(version model.JobTemplateVersion) {
+	vb.ID = version.ID
+	vb.TemplateID = version.TemplateID
+	vb.JobType = version.JobType
+	vb.Parameters = version.Parameters
+	vb.Template = json.RawMessage(version.Template)
+	vb.UserName = version.UserName
+	vb.CreateTime = version.CreatedAt.Format("2006-01-02 15:04:05")
+}
+
+func CreateJobTemplate(ctx *logger.RequestContext, request *CreateJobTemplateRequest) (*CreateJobTemplateResponse, error) {
+	if errStr := common.IsDNS1123Label(request.Name); len(errStr) != 0 {
+		ctx.ErrorCode = common.InvalidArguments
+		err := fmt.Errorf("name[%s] of job template is invalid, err: %s", request.Name, strings.Join(errStr, ","))
+		ctx.Logging().Errorln(err)
+		return nil, err
+	}
+	version, err := newJobTemplateVersion(ctx, request.Desc, request.JobType, request.Parameters, request.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	// one user cannot create job templates with the same name
+	_, err = storage.JobTemplate.GetJobTemplate(request.Name, ctx.UserName)
+	if err == nil {
+		ctx.ErrorCode = common.DuplicatedName
+		err = fmt.Errorf("user[%s] already has job template[%s], use update instead", ctx.UserName, request.Name)
+		ctx.Logging().Errorln(err)
+		return nil, err
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.ErrorCode = common.InternalError
+		ctx.Logging().Errorf("get job template[%s] failed, err: %v", request.Name, err)
+		return nil, err
+	}
+
+	template := &model.JobTemplate{
+		Name:     request.Name,
+		Desc:     request.Desc,
+		UserName: ctx.UserName,
+	}
+	templateID, versionID, err := storage.JobTemplate.CreateJobTemplate(ctx.Logging(), template, version)
+	if err != nil {
+		ctx.ErrorCode = common.InternalError
+		ctx.Logging().Errorf("create job template[%s] failed, err: %v", request.Name, err)
+		return nil, err
+	}
+	ctx.Logging().Infof("create job template[%s] version[%s] successful", templateID, versionID)
+	return &CreateJobTemplateResponse{
+		TemplateID:        templateID,
+		TemplateVersionID: versionID,
+		Name:              template.Name,
+	}, nil
+}
+
+// UpdateJobTemplate creates a new version of job template, and the old versions are kept for jobs using them
+func UpdateJobTemplate(ctx *logger.RequestContext, templateID string, request *UpdateJobTemplateRequest) (*UpdateJobTemplateResponse, error) {
+	template, err := CheckJobTemplatePermission(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	version, err := newJobTemplateVersion(ctx, request.Desc, request.JobType, request.Parameters, request.Template)
+	if err != nil {
+		return nil, err
+	}
+	template.Desc = request.Desc
+	_, versionID, err := storage.JobTemplate.UpdateJobTemplate(ctx.Logging(), &template, version)
+	if err != nil {
+		ctx.ErrorCode = common.InternalError
+		ctx.Logging().Errorf("update job template[%s] failed, err: %v", templateID, err)
+		return nil, err
+	}
+	ctx.Logging().Infof("update job template[%s] with version[%s] successful", templateID, versionID)
+	return &UpdateJobTemplateResponse{
+		TemplateID:        templateID,
+		TemplateVersionID: versionID,
+	}, nil
+}
+
+// newJobTemplateVersion validates job template and its parameters
+func newJobTemplateVersion(ctx *logger.RequestContext, desc string, jobType schema.JobType,
+	parameters []schema.JobTemplateParameter, template json.RawMessage) (*model.JobTemplateVersion, error) {
+	if len(desc) > util.MaxDescLength {
+		ctx.ErrorCode = common.InvalidArguments
+		err := fmt.Errorf("desc too long, should be less than %d", util.MaxDescLength)
+		ctx.Logging().Errorln(err)
+		return nil, err
+	}
+	switch jobType {
+	case schema.TypeSingle, schema.TypeDistributed, schema.TypeWorkflow:
+	default:
+		ctx.ErrorCode = common.InvalidArguments
+		err := fmt.Errorf("jobType %s of job template is not supported, only single, distributed and workflow are supported", jobType)
+		ctx.Logging().Errorln(err)
+		return nil, err
+	}
+	version := &model.JobTemplateVersion{
+		JobType:    string(jobType),
+		Parameters: parameters,
+		Template:   string(template),
+		UserName:   ctx.UserName,
+	}
+	if version.Parameters == nil {
+		version.Parameters = []schema.JobTemplateParameter{}
+	}
+	if err := validateTemplateParameters(version.Parameters); err != nil {
+		ctx.ErrorCode = common.InvalidArguments
+		ctx.Logging().Errorf("validate parameters of job template failed, err: %v", err)
+		return nil, err
+	}
+	// render template by defaults to check references and request format, and required parameters without
+	// default are filled with zero values
+	values := make(map[string]interface{})
+	for _, param := range version.Parameters {
+		if param.Default == nil {
+			values[param.Name] = zeroParameterValue(param.Type)
+		}
+	}
+	jobRequest, err := renderJobTemplate(version, values)
+	if err == nil {
+		_, _, err = parseTemplateJob(jobType, jobRequest)
+	}
+	if err != nil {
+		ctx.ErrorCode = common.InvalidArguments
+		ctx.Logging().Errorf("validate job template failed, err: %v", err)
+		return nil, err
+	}
+	version.TemplateMd5 = common.GetMD5Hash(template)
+	return version, nil
+}
+
+func validateTemplateParameters(parameters []schema.JobTemplateParameter) error {
+	names := make(map[string]bool)
+	for idx, param := range parameters {
+		if !templateParamRegexp.MatchString("{{" + param.Name + "}}") {
+			return fmt.Errorf("name[%s] of parameter is invalid", param.Name)
+		}
+		if names[param.Name] {
+			return fmt.Errorf("parameter %s is duplicated", param.Name)
+		}
+		names[param.Name] = true
+		if param.Type == "" {
+			parameters[idx].Type = schema.TemplateParameterString
+		}
+		if param.Default != nil {
+			value, err := parameters[idx].Convert(param.Default)
+			if err != nil {
+				return err
+			}
+			parameters[idx].Default = value
+		}
+	}
+	return nil
+}
+
+func zeroParameterValue(paramType schema.TemplateParameterType) interface{} {
+	switch paramType {
+	case schema.TemplateParameterInt:
+		return int64(0)
+	case schema.TemplateParameterFloat:
+		return float64(0)
+	case schema.TemplateParameterBool:
+		return false
+	default:
+		return ""
+	}
+}
+
+// renderJobTemplate replaces parameter references in template with the values or defaults of parameters.
+// A string which only references one parameter is replaced by the typed value, otherwise the value is
+// formatted into the string.
+func renderJobTemplate(version *model.JobTemplateVersion, values map[string]interface{}) ([]byte, error) {
+	params := make(map[string]interface{})
+	declared := make(map[string]bool)
+	for _, param := range version.Parameters {
+		declared[param.Name] = true
+		value, find := values[param.Name]
+		if !find || value == nil {
+			value = param.Default
+		}
+		if value == nil {
+			if param.Required {
+				return nil, fmt.Errorf("parameter %s is required", param.Name)
+			}
+			continue
+		}
+		converted, err := param.Convert(value)
+		if err != nil {
+			return nil, err
+		}
+		params[param.Name] = converted
+	}
+	for name := range values {
+		if !declared[name] {
+			return nil, fmt.Errorf("parameter %s is not declared in job template", name)
+		}
+	}
+
+	var template interface{}
+	if err := json.Unmarshal([]byte(version.Template), &template); err != nil {
+		return nil, fmt.Errorf("template should be a json object, err: %v", err)
+	}
+	if _, ok := template.(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("template should be a json object")
+	}
+	rendered, err := renderTemplateValue(template, params)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(rendered)
+}
+
+func renderTemplateValue(value interface{}, params map[string]interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			rendered, err := renderTemplateValue(item, params)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = rendered
+		}
+		return v, nil
+	case []interface{}:
+		for idx, item := range v {
+			rendered, err := renderTemplateValue(item, params)
+			if err != nil {
+				return nil, err
+			}
+			v[idx] = rendered
+		}
+		return v, nil
+	case string:
+		return renderTemplateString(v, params)
+	default:
+		return v, nil
+	}
+}
+
+func renderTemplateString(value string, params map[string]interface{}) (interface{}, error) {
+	// the whole string is a reference, such as "{{replicas}}"
+	if match := templateParamRegexp.FindStringSubmatch(value); match != nil && match[0] == value {
+		paramValue, find := params[match[1]]
+		if !find {
+			return nil, fmt.Errorf("parameter %s referenced in template has no value", match[1])
+		}
+		return paramValue, nil
+	}
+	var err error
+	result := templateParamRegexp.ReplaceAllStringFunc(value, func(ref string) string {
+		name := templateParamRegexp.FindStringSubmatch(ref)[1]
+		paramValue, find := params[name]
+		if !find {
+			err = fmt.Errorf("parameter %s referenced in template has no value", name)
+			return ref
+		}
+		return fmt.Sprintf("%v", paramValue)
+	})
+	return result, err
+}
+
+func ListJobTemplate(ctx *logger.RequestContext, marker string, maxKeys int, userFilter, nameFilter []string) (*ListJobTemplateResponse, error) {
+	var pk int64
+	var err error
+	if marker != "" {
+		pk, err = common.DecryptPk(marker)
+		if err != nil {
+			ctx.ErrorCode = common.InvalidMarker
+			ctx.Logging().Errorf("DecryptPk marker[%s] failed. err: %v", marker, err)
+			return nil, err
+		}
+	}
+	// only root user can set userFilter, and the other users can only list their own templates
+	if !common.IsRootUser(ctx.UserName) {
+		if len(userFilter) != 0 {
+			ctx.ErrorCode = common.AccessDenied
+			err = fmt.Errorf("only root user can set userFilter")
+			ctx.Logging().Errorln(err)
+			return nil, err
+		}
+		userFilter = []string{ctx.UserName}
+	}
+
+	templates, err := storage.JobTemplate.ListJobTemplate(pk, maxKeys, userFilter, nameFilter)
+	if err != nil {
+		ctx.ErrorCode = common.InternalError
+		ctx.Logging().Errorf("list job template failed, err: %v", err)
+		return nil, err
+	}
+	response := &ListJobTemplateResponse{
+		TemplateList: []JobTemplateBrief{},
+	}
+	if len(templates) > 0 {
+		last := templates[len(templates)-1]
+		isLastPk, err := storage.JobTemplate.IsLastJobTemplatePk(last.Pk, userFilter, nameFilter)
+		if err != nil {
+			ctx.ErrorCode = common.InternalError
+			ctx.Logging().Errorf("get last job template pk failed, err: %v", err)
+			return nil, err
+		}
+		if !isLastPk {
+			nextMarker, err := common.EncryptPk(last.Pk)
+			if err != nil {
+				ctx.ErrorCode = common.InternalError
+				ctx.Logging().Errorf("EncryptPk error. pk:[%d] error: %v", last.Pk, err)
+				return nil, err
+			}
+			response.NextMarker = nextMarker
+			response.IsTruncated = true
+		}
+	}
+	response.MaxKeys = maxKeys
+	for _, template := range templates {
+		brief := JobTemplateBrief{}
+		brief.updateFromModel(template)
+		response.TemplateList = append(response.TemplateList, brief)
+	}
+	return response, nil
+}
+
+func GetJobTemplate(ctx *logger.RequestContext, templateID string) (*GetJobTemplateResponse, error) {
+	template, err := CheckJobTemplatePermission(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := storage.JobTemplate.ListJobTemplateVersion(templateID)
+	if err != nil {
+		ctx.ErrorCode = common.InternalError
+		ctx.Logging().Errorf("list versions of job template[%s] failed, err: %v", templateID, err)
+		return nil, err
+	}
+	response := &GetJobTemplateResponse{
+		Versions: []JobTemplateVersionBrief{},
+	}
+	response.Template.updateFromModel(template)
+	for _, version := range versions {
+		brief := JobTemplateVersionBrief{}
+		brief.updateFromModel(version)
+		response.Versions = append(response.Versions, brief)
+	}
+	return response, nil
+}
+
+func GetJobTemplateVersion(ctx *logger.RequestContext, templateID, versionID string) (*GetJobTemplateVersionResponse, error) {
+	template, version, err := CheckJobTemplateVersionPermission(ctx, templateID, versionID)
+	if err != nil {
+		return nil, err
+	}
+	response := &GetJobTemplateVersionResponse{}
+	response.Template.updateFromModel(template)
+	response.Version.updateFromModel(version)
+	return response, nil
+}
+
+func DeleteJobTemplate(ctx *logger.RequestContext, templateID string) error {
+	if _, err := CheckJobTemplatePermission(ctx, templateID); err != nil {
+		return err
+	}
+	if err := storage.JobTemplate.DeleteJobTemplate(ctx.Logging(), templateID); err != nil {
+		ctx.ErrorCode = common.InternalError
+		ctx.Logging().Errorf("delete job template[%s] failed, err: %v", templateID, err)
+		return err
+	}
+	return nil
+}
+
+func DeleteJobTemplateVersion(ctx *logger.RequestContext, templateID, versionID string) error {
+	if _, _, err := CheckJobTemplateVersionPermission(ctx, templateID, versionID); err != nil {
+		return err
+	}
+	// the last version cannot be deleted, delete job template instead
+	count, err := storage.JobTemplate.CountJobTemplateVersion(templateID)
+	if err != nil {
+		ctx.ErrorCode = common.InternalError
+		ctx.Logging().Errorf("count versions of job template[%s] failed, err: %v", templateID, err)
+		return err
+	} else if count == 1 {
+		ctx.ErrorCode = common.ActionNotAllowed
+		err = fmt.Errorf("only one version of job template[%s] left, delete job template instead", templateID)
+		ctx.Logging().Errorln(err)
+		return err
+	}
+	if err = storage.JobTemplate.DeleteJobTemplateVersion(ctx.Logging(), templateID, versionID); err != nil {
+		ctx.ErrorCode = common.InternalError
+		ctx.Logging().Errorf("delete job template[%s] version[%s] failed, err: %v", templateID, versionID, err)
+		return err
+	}
+	return nil
+}
+
+// CreateJobFromTemplate renders job template by parameters and creates job, the template is referenced by
+// its id, or by its name if it belongs to the request user
+func CreateJobFromTemplate(ctx *logger.RequestContext, templateRef string, request *CreateJobFromTemplateRequest) (*CreateJobResponse, error) {
+	templateID := templateRef
+	if !strings.HasPrefix(templateRef, common.PrefixTemplate) {
+		template, err := storage.JobTemplate.GetJobTemplate(templateRef, ctx.UserName)
+		if err != nil {
+			setJobTemplateErrorCode(ctx, err)
+			ctx.Logging().Errorf("get job template[%s] of user[%s] failed, err: %v", templateRef, ctx.UserName, err)
+			return nil, err
+		}
+		templateID = template.ID
+	}
+	_, version, err := CheckJobTemplateVersionPermission(ctx, templateID, request.TemplateVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	jobRequest, err := renderJobTemplate(&version, request.Parameters)
+	if err != nil {
+		ctx.ErrorCode = common.InvalidArguments
+		ctx.Logging().Errorf("render job template[%s] version[%s] failed, err: %v", templateID, version.ID, err)
+		return nil, err
+	}
+	ctx.Logging().Debugf("render job template[%s] version[%s]: %s", templateID, version.ID, string(jobRequest))
+
+	jobInfo, wfJob, err := parseTemplateJob(schema.JobType(version.JobType), jobRequest)
+	if err != nil {
+		ctx.ErrorCode = common.InvalidArguments
+		ctx.Logging().Errorf("create job by template[%s] failed, err: %v", templateID, err)
+		return nil, err
+	}
+	if wfJob != nil {
+		wfJob.UserName = ctx.UserName
+		return CreateWorkflowJob(ctx, wfJob)
+	}
+	return CreatePFJob(ctx, jobInfo)
+}
+
+// parseTemplateJob parses rendered template into the request of creating job, workflow job is returned
+// as CreateWfJobRequest and the other jobs are returned as CreateJobInfo
+func parseTemplateJob(jobType schema.JobType, data []byte) (*CreateJobInfo, *CreateWfJobRequest, error) {
+	var err error
+	switch jobType {
+	case schema.TypeSingle:
+		var singleJob CreateSingleJobRequest
+		if err = json.Unmarshal(data, &singleJob); err == nil {
+			return singleJob.ToJobInfo(), nil, nil
+		}
+	case schema.TypeDistributed:
+		var disJob CreateDisJobRequest
+		if err = json.Unmarshal(data, &disJob); err == nil {
+			return disJob.ToJobInfo(), nil, nil
+		}
+	case schema.TypeWorkflow:
+		var wfJob CreateWfJobRequest
+		if err = json.Unmarshal(data, &wfJob); err == nil {
+			return nil, &wfJob, nil
+		}
+	default:
+		err = fmt.Errorf("jobType %s of job template is not supported", jobType)
+	}
+	return nil, nil, fmt.Errorf("parse %s job from template failed, err: %v", jobType, err)
+}
+
+// CheckJobTemplatePermission gets job template, only root user and the owner of template have the permission
+func CheckJobTemplatePermission(ctx *logger.RequestContext, templateID string) (model.JobTemplate, error) {
+	template, err := storage.JobTemplate.GetJobTemplateByID(templateID)
+	if err != nil {
+		setJobTemplateErrorCode(ctx, err)
+		ctx.Logging().Errorf("get job template[%s] failed, err: %v", templateID, err)
+		return model.JobTemplate{}, err
+	}
+	if err = common.CheckPermission(ctx.UserName, template.UserName, common.ResourceTypeJobTemplate, templateID); err != nil {
+		ctx.ErrorCode = common.AccessDenied
+		ctx.Logging().Errorln(err)
+		return model.JobTemplate{}, err
+	}
+	return template, nil
+}
+
+// CheckJobTemplateVersionPermission gets job template and its version, and the latest version is returned if
+// versionID is empty
+func CheckJobTemplateVersionPermission(ctx *logger.RequestContext, templateID, versionID string) (model.JobTemplate, model.JobTemplateVersion, error) {
+	template, err := CheckJobTemplatePermission(ctx, templateID)
+	if err != nil {
+		return model.JobTemplate{}, model.JobTemplateVersion{}, err
+	}
+	var version model.JobTemplateVersion
+	if versionID != "" {
+		version, err = storage.JobTemplate.GetJobTemplateVersion(templateID, versionID)
+	} else {
+		version, err = storage.JobTemplate.GetLastJobTemplateVersion(templateID)
+	}
+	if err != nil {
+		setJobTemplateErrorCode(ctx, err)
+		ctx.Logging().Errorf("get job template[%s] version[%s] failed, err: %v", templateID, versionID, err)
+		return model.JobTemplate{}, model.JobTemplateVersion{}, err
+	}
+	return template, version, nil
+}
+
+func setJobTemplateErrorCode(ctx *logger.RequestContext, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.ErrorCode = common.JobTemplateNotFound
+	} else {
+		ctx.ErrorCode = common.InternalError
+	}
+}
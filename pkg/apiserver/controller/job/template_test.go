@@ -0,0 +1,258 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/config"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/logger"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/resources"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage/driver"
+)
+
+const mockSingleJobTemplate = `{
+	"name": "{{jobName}}-job",
+	"schedulingPolicy": {"queue": "{{queue}}"},
+	"flavour": {"name": "{{flavour}}"},
+	"image": "paddle:{{version}}",
+	"command": "python train.py --epochs {{epochs}} --lr {{lr}}",
+	"port": "{{port}}"
+}`
+
+func mockTemplateParameters() []schema.JobTemplateParameter {
+	return []schema.JobTemplateParameter{
+		{Name: "jobName", Type: schema.TemplateParameterString, Required: true},
+		{Name: "queue", Type: schema.TemplateParameterString, Default: MockQueueName},
+		{Name: "flavour", Default: MockFlavour1},
+		{Name: "version", Default: "2.4.0"},
+		{Name: "epochs", Type: schema.TemplateParameterInt, Default: 10},
+		{Name: "lr", Type: schema.TemplateParameterFloat, Default: 0.01},
+		{Name: "port", Type: schema.TemplateParameterInt, Default: 8080},
+	}
+}
+
+func TestRenderJobTemplate(t *testing.T) {
+	version := &model.JobTemplateVersion{
+		JobType:    string(schema.TypeSingle),
+		Parameters: mockTemplateParameters(),
+		Template:   mockSingleJobTemplate,
+	}
+	assert.NoError(t, validateTemplateParameters(version.Parameters))
+
+	testCases := []struct {
+		name        string
+		values      map[string]interface{}
+		expectErr   bool
+		expectName  string
+		expectPort  int
+		expectCmd   string
+		expectImage string
+	}{
+		{
+			name:        "render with defaults",
+			values:      map[string]interface{}{"jobName": "test"},
+			expectName:  "test-job",
+			expectPort:  8080,
+			expectCmd:   "python train.py --epochs 10 --lr 0.01",
+			expectImage: "paddle:2.4.0",
+		},
+		{
+			name:        "override defaults",
+			values:      map[string]interface{}{"jobName": "abc", "epochs": "20", "port": float64(9090), "version": 2.5},
+			expectName:  "abc-job",
+			expectPort:  9090,
+			expectCmd:   "python train.py --epochs 20 --lr 0.01",
+			expectImage: "paddle:2.5",
+		},
+		{
+			name:      "required parameter is missing",
+			values:    map[string]interface{}{},
+			expectErr: true,
+		},
+		{
+			name:      "value type mismatch",
+			values:    map[string]interface{}{"jobName": "test", "epochs": 1.5},
+			expectErr: true,
+		},
+		{
+			name:      "parameter is not declared",
+			values:    map[string]interface{}{"jobName": "test", "unknown": "x"},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := renderJobTemplate(version, tc.values)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			jobInfo, wfJob, err := parseTemplateJob(schema.TypeSingle, data)
+			assert.NoError(t, err)
+			assert.Nil(t, wfJob)
+			assert.Equal(t, tc.expectName, jobInfo.Name)
+			assert.Equal(t, MockQueueName, jobInfo.SchedulingPolicy.Queue)
+			assert.Equal(t, schema.TypeSingle, jobInfo.Type)
+			assert.Equal(t, tc.expectPort, jobInfo.Members[0].Port)
+			assert.Equal(t, tc.expectCmd, jobInfo.Members[0].Command)
+			assert.Equal(t, tc.expectImage, jobInfo.Members[0].Image)
+		})
+	}
+}
+
+func TestJobTemplate(t *testing.T) {
+	driver.InitMockDB()
+	config.GlobalServerConfig = &config.ServerConfig{}
+	config.GlobalServerConfig.Job.IsSingleCluster = true
+	rootCtx := &logger.RequestContext{UserName: mockRootUser}
+	userCtx := &logger.RequestContext{UserName: "user1"}
+
+	// create template
+	request := &CreateJobTemplateRequest{
+		Name:       "train-template",
+		JobType:    schema.TypeSingle,
+		Parameters: mockTemplateParameters(),
+		Template:   json.RawMessage(mockSingleJobTemplate),
+	}
+	resp, err := CreateJobTemplate(rootCtx, request)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", resp.TemplateVersionID)
+	templateID := resp.TemplateID
+
+	_, err = CreateJobTemplate(rootCtx, request)
+	assert.Error(t, err)
+
+	// invalid templates
+	invalidRequests := []*CreateJobTemplateRequest{
+		{Name: "Invalid_Name", JobType: schema.TypeSingle, Template: json.RawMessage(`{}`)},
+		{Name: "tpl1", JobType: schema.TypeSingle, Template: json.RawMessage(`{"image": "{{image}}"}`)},
+		{Name: "tpl2", JobType: schema.TypeSingle, Template: json.RawMessage(`[]`)},
+		{Name: "tpl3", JobType: "other", Template: json.RawMessage(`{}`)},
+		{Name: "tpl4", JobType: schema.TypeSingle, Template: json.RawMessage(`{"port": "{{port}}"}`),
+			Parameters: []schema.JobTemplateParameter{{Name: "port", Type: schema.TemplateParameterBool}}},
+	}
+	for _, req := range invalidRequests {
+		_, err = CreateJobTemplate(rootCtx, req)
+		assert.Error(t, err, req.Name)
+	}
+
+	// update template creates new version
+	updateResp, err := UpdateJobTemplate(rootCtx, templateID, &UpdateJobTemplateRequest{
+		Desc:       "new version",
+		JobType:    schema.TypeSingle,
+		Parameters: mockTemplateParameters(),
+		Template:   json.RawMessage(mockSingleJobTemplate),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "2", updateResp.TemplateVersionID)
+	getResp, err := GetJobTemplate(rootCtx, templateID)
+	assert.NoError(t, err)
+	assert.Equal(t, "new version", getResp.Template.Desc)
+	assert.Equal(t, 2, len(getResp.Versions))
+
+	// permission check
+	_, err = GetJobTemplate(userCtx, templateID)
+	assert.Error(t, err)
+	_, err = UpdateJobTemplate(userCtx, templateID, &UpdateJobTemplateRequest{})
+	assert.Error(t, err)
+	listResp, err := ListJobTemplate(userCtx, "", 50, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(listResp.TemplateList))
+	listResp, err = ListJobTemplate(rootCtx, "", 50, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(listResp.TemplateList))
+
+	// delete version
+	assert.NoError(t, DeleteJobTemplateVersion(rootCtx, templateID, "1"))
+	assert.Error(t, DeleteJobTemplateVersion(rootCtx, templateID, "2"))
+	versionResp, err := GetJobTemplateVersion(rootCtx, templateID, "2")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), int64(versionResp.Version.Parameters[4].Default.(float64)))
+
+	// delete template
+	assert.NoError(t, DeleteJobTemplate(rootCtx, templateID))
+	_, err = GetJobTemplate(rootCtx, templateID)
+	assert.Error(t, err)
+}
+
+func TestCreateJobFromTemplate(t *testing.T) {
+	driver.InitMockDB()
+	config.GlobalServerConfig = &config.ServerConfig{}
+	config.GlobalServerConfig.Job.IsSingleCluster = true
+	ctx := &logger.RequestContext{UserName: mockRootUser}
+
+	assert.NoError(t, storage.Cluster.CreateCluster(&model.ClusterInfo{
+		Model:       model.Model{ID: MockClusterName},
+		Name:        MockClusterName,
+		ClusterType: schema.KubernetesType,
+	}))
+	assert.NoError(t, storage.Flavour.CreateFlavour(&model.Flavour{
+		Model: model.Model{ID: MockFlavour1},
+		Name:  MockFlavour1,
+		CPU:   "1",
+		Mem:   "1",
+	}))
+	maxRes, err := resources.NewResourceFromMap(map[string]string{
+		resources.ResCPU:    "10",
+		resources.ResMemory: "20Gi",
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, storage.Queue.CreateQueue(&model.Queue{
+		Model:        model.Model{ID: MockQueueID},
+		Name:         MockQueueName,
+		Namespace:    "default",
+		MaxResources: maxRes,
+		MinResources: maxRes,
+		QuotaType:    schema.TypeVolcanoCapabilityQuota,
+		ClusterId:    MockClusterName,
+		ClusterName:  MockClusterName,
+		Status:       "open",
+	}))
+
+	_, err = CreateJobTemplate(ctx, &CreateJobTemplateRequest{
+		Name:       "train",
+		JobType:    schema.TypeSingle,
+		Parameters: mockTemplateParameters(),
+		Template:   json.RawMessage(mockSingleJobTemplate),
+	})
+	assert.NoError(t, err)
+
+	resp, err := CreateJobFromTemplate(ctx, "train", &CreateJobFromTemplateRequest{
+		Parameters: map[string]interface{}{"jobName": "tpl", "epochs": 5},
+	})
+	assert.NoError(t, err)
+	job, err := storage.Job.GetJobByID(resp.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "tpl-job", job.Name)
+	assert.Equal(t, "python train.py --epochs 5 --lr 0.01", job.Config.GetCommand())
+
+	// template not found
+	_, err = CreateJobFromTemplate(ctx, "not-exist", &CreateJobFromTemplateRequest{})
+	assert.Error(t, err)
+	// required parameter is missing
+	_, err = CreateJobFromTemplate(ctx, "train", &CreateJobFromTemplateRequest{})
+	assert.Error(t, err)
+}
@@ -168,9 +168,9 @@ func CheckFsAndGetID(ctx *logger.RequestContext, fsUserName, fsName string) (fsI
 	userName := ctx.UserName
 
 	if fsUserName != "" {
-		fsID = common.ID(fsUserName, fsName)
+		fsID = common.FsOrSnapshotID(fsUserName, fsName)
 	} else {
-		fsID = common.ID(userName, fsName)
+		fsID = common.FsOrSnapshotID(userName, fsName)
 	}
 
 	fsService := fs.GetFileSystemService()
@@ -187,6 +187,13 @@ func CheckFsAndGetID(ctx *logger.RequestContext, fsUserName, fsName string) (fsI
 		return fsID, err
 	}
 
+	if _, snapshot := schema.ParseFsSnapshotName(fsName); snapshot != "" {
+		if _, err := storage.Filesystem.GetFileSystemOrSnapshot(fsID); err != nil {
+			ctx.ErrorCode = common.InvalidArguments
+			return fsID, fmt.Errorf("snapshot of fsName[%s] is not available, err: %v", fsName, err)
+		}
+	}
+
 	return fsID, nil
 }
 
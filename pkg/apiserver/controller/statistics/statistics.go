@@ -58,23 +58,24 @@ type MetricInfo struct {
 	Values     [][2]float64 `json:"values"`
 }
 
-func GetJobStatistics(ctx *logger.RequestContext, jobID string) (*JobStatisticsResponse, error) {
+// GetJobStatistics get the average metrics of job over [start, end], the running time of job is used if they are zero
+func GetJobStatistics(ctx *logger.RequestContext, jobID string, start, end int64) (*JobStatisticsResponse, error) {
 	response := &JobStatisticsResponse{
 		MetricsInfo: make(map[string]string),
 	}
-	clusterType, _, err := getClusterTypeByJob(ctx, jobID)
+	cluster, _, err := getClusterByJob(ctx, jobID)
 	if err != nil {
 		ctx.Logging().Errorf("get metric type failed, error: %s", err.Error())
 		return nil, err
 	}
-	metric, err := getMetricByType(clusterType)
+	metric, err := getMetricByCluster(cluster)
 	if err != nil {
-		ctx.Logging().Errorf("get metric by type[%s] failed, error: %s", clusterType, err.Error())
+		ctx.Logging().Errorf("get metric by type[%s] failed, error: %s", cluster.ClusterType, err.Error())
 		return nil, err
 	}
 
 	for _, value := range metricNameList {
-		result, err := metric.GetJobAvgMetrics(value, jobID)
+		result, err := metric.GetJobAvgMetrics(value, jobID, start, end)
 		if err != nil {
 			ctx.Logging().Errorf("query metric[%s] failed, error: %s", value, err.Error())
 			return nil, err
@@ -91,7 +92,7 @@ func GetJobDetailStatistics(ctx *logger.RequestContext, jobID string, start, end
 		TaskNameMap: make(map[string]int),
 	}
 
-	clusterType, job, err := getClusterTypeByJob(ctx, jobID)
+	cluster, job, err := getClusterByJob(ctx, jobID)
 	if err != nil {
 		ctx.Logging().Errorf("get metric type failed, error: %s", err.Error())
 		return nil, err
@@ -112,9 +113,9 @@ func GetJobDetailStatistics(ctx *logger.RequestContext, jobID string, start, end
 		}
 	}
 
-	metric, err := getMetricByType(clusterType)
+	metric, err := getMetricByCluster(cluster)
 	if err != nil {
-		ctx.Logging().Errorf("get metric by type[%s] failed, error: %s", clusterType, err.Error())
+		ctx.Logging().Errorf("get metric by type[%s] failed, error: %s", cluster.ClusterType, err.Error())
 		return nil, err
 	}
 
@@ -124,7 +125,7 @@ func GetJobDetailStatistics(ctx *logger.RequestContext, jobID string, start, end
 			ctx.Logging().Errorf("query range metric[%s] failed, error: %s", value, err.Error())
 			return nil, err
 		}
-		err = convertResultToDetailResponse(ctx, result, response, value)
+		err = convertResultToDetailResponse(ctx, result, response, value, common.Pod)
 		if err != nil {
 			ctx.Logging().Errorf("convert metric[%s] result to detail response failed, error: %s", value, err.Error())
 			return nil, err
@@ -133,46 +134,139 @@ func GetJobDetailStatistics(ctx *logger.RequestContext, jobID string, start, end
 	return response, nil
 }
 
-func getMetricByType(metricType string) (monitor.MetricInterface, error) {
+// GetQueueStatistics get the average metrics of jobs which were running in queue during [start, end]
+func GetQueueStatistics(ctx *logger.RequestContext, queueName string, start, end int64) (*JobStatisticsResponse, error) {
+	response := &JobStatisticsResponse{
+		MetricsInfo: make(map[string]string),
+	}
+	cluster, queue, err := getClusterByQueue(ctx, queueName)
+	if err != nil {
+		ctx.Logging().Errorf("get cluster of queue[%s] failed, error: %s", queueName, err.Error())
+		return nil, err
+	}
+	metric, err := getMetricByCluster(cluster)
+	if err != nil {
+		ctx.Logging().Errorf("get metric by type[%s] failed, error: %s", cluster.ClusterType, err.Error())
+		return nil, err
+	}
+
+	for _, value := range metricNameList {
+		result, err := metric.GetQueueAvgMetrics(value, queue.ID, start, end)
+		if err != nil {
+			ctx.Logging().Errorf("query queue metric[%s] failed, error: %s", value, err.Error())
+			return nil, err
+		}
+		convertResultToResponse(response, result, value)
+	}
+	return response, nil
+}
+
+// GetQueueDetailStatistics get the metric sequences of queue during [start, end], which are aggregated from
+// the pods of jobs in queue
+func GetQueueDetailStatistics(ctx *logger.RequestContext, queueName string, start, end, step int64) (*JobDetailStatisticsResponse, error) {
+	response := &JobDetailStatisticsResponse{
+		Result:      make([]TaskStatistics, 0),
+		TaskNameMap: make(map[string]int),
+	}
+	cluster, queue, err := getClusterByQueue(ctx, queueName)
+	if err != nil {
+		ctx.Logging().Errorf("get cluster of queue[%s] failed, error: %s", queueName, err.Error())
+		return nil, err
+	}
+	metric, err := getMetricByCluster(cluster)
+	if err != nil {
+		ctx.Logging().Errorf("get metric by type[%s] failed, error: %s", cluster.ClusterType, err.Error())
+		return nil, err
+	}
+
+	for _, value := range metricNameList {
+		result, err := metric.GetQueueSequenceMetrics(value, queue.ID, start, end, step)
+		if err != nil {
+			ctx.Logging().Errorf("query range queue metric[%s] failed, error: %s", value, err.Error())
+			return nil, err
+		}
+		result, err = monitor.AggregateQueueMatrix(result, value, queueName)
+		if err != nil {
+			ctx.Logging().Errorf("aggregate queue metric[%s] failed, error: %s", value, err.Error())
+			return nil, err
+		}
+		err = convertResultToDetailResponse(ctx, result, response, value, common.Queue)
+		if err != nil {
+			ctx.Logging().Errorf("convert metric[%s] result to detail response failed, error: %s", value, err.Error())
+			return nil, err
+		}
+	}
+	return response, nil
+}
+
+func getMetricByCluster(cluster model.ClusterInfo) (monitor.MetricInterface, error) {
 	var metric monitor.MetricInterface
-	switch metricType {
+	switch cluster.ClusterType {
 	case schema.KubernetesType:
-		metric = monitor.NewKubernetesMetric(monitor.PrometheusClientAPI)
+		if monitor.MetricBackend() != monitor.MetricBackendMetricsServer {
+			metric = monitor.NewKubernetesMetric(monitor.PrometheusClientAPI)
+			break
+		}
+		var err error
+		if metric, err = monitor.GetMetricsServerMetric(cluster.ID); err != nil {
+			return nil, err
+		}
 	default:
-		return nil, fmt.Errorf("metric type[%s] is not support", metricType)
+		return nil, fmt.Errorf("metric type[%s] is not support", cluster.ClusterType)
 	}
 	return metric, nil
 }
 
-func getClusterTypeByJob(ctx *logger.RequestContext, jobID string) (string, *model.Job, error) {
+func getClusterByJob(ctx *logger.RequestContext, jobID string) (model.ClusterInfo, *model.Job, error) {
 	job, err := storage.Job.GetJobByID(jobID)
 	if err != nil {
 		ctx.ErrorCode = common.JobNotFound
 		ctx.Logging().Errorln(err.Error())
-		return "", nil, common.NotFoundError(common.ResourceTypeJob, jobID)
+		return model.ClusterInfo{}, nil, common.NotFoundError(common.ResourceTypeJob, jobID)
 	}
 	if ok := checkJobPermission(ctx, &job); !ok {
 		ctx.ErrorCode = common.AccessDenied
 		ctx.Logging().Errorf("get the job[%s] auth failed.", jobID)
-		return "", nil, common.NoAccessError(ctx.UserName, common.ResourceTypeJob, jobID)
+		return model.ClusterInfo{}, nil, common.NoAccessError(ctx.UserName, common.ResourceTypeJob, jobID)
 	}
 
 	queue, err := storage.Queue.GetQueueByID(job.QueueID)
 	if err != nil {
 		ctx.ErrorCode = common.QueueNameNotFound
 		ctx.Logging().Errorln(err.Error())
-		return "", nil, common.NotFoundError(common.ResourceTypeQueue, job.QueueID)
+		return model.ClusterInfo{}, nil, common.NotFoundError(common.ResourceTypeQueue, job.QueueID)
+	}
+	cluster, err := storage.Cluster.GetClusterById(queue.ClusterId)
+	if err != nil {
+		ctx.ErrorCode = common.ClusterNotFound
+		ctx.Logging().Errorln(err.Error())
+		return model.ClusterInfo{}, nil, common.NotFoundError(common.ResourceTypeCluster, queue.ClusterId)
+	}
+	return cluster, &job, nil
+}
+
+func getClusterByQueue(ctx *logger.RequestContext, queueName string) (model.ClusterInfo, *model.Queue, error) {
+	if !storage.Auth.HasAccessToResource(ctx, common.ResourceTypeQueue, queueName) {
+		ctx.ErrorCode = common.AccessDenied
+		ctx.Logging().Errorf("get the queue[%s] auth failed.", queueName)
+		return model.ClusterInfo{}, nil, common.NoAccessError(ctx.UserName, common.ResourceTypeQueue, queueName)
+	}
+	queue, err := storage.Queue.GetQueueByName(queueName)
+	if err != nil {
+		ctx.ErrorCode = common.QueueNameNotFound
+		ctx.Logging().Errorln(err.Error())
+		return model.ClusterInfo{}, nil, common.NotFoundError(common.ResourceTypeQueue, queueName)
 	}
 	cluster, err := storage.Cluster.GetClusterById(queue.ClusterId)
 	if err != nil {
 		ctx.ErrorCode = common.ClusterNotFound
 		ctx.Logging().Errorln(err.Error())
-		return "", nil, common.NotFoundError(common.ResourceTypeCluster, queue.ClusterId)
+		return model.ClusterInfo{}, nil, common.NotFoundError(common.ResourceTypeCluster, queue.ClusterId)
 	}
-	return cluster.ClusterType, &job, nil
+	return cluster, &queue, nil
 }
 
-func convertResultToDetailResponse(ctx *logger.RequestContext, result prometheusModel.Value, response *JobDetailStatisticsResponse, metricName string) error {
+func convertResultToDetailResponse(ctx *logger.RequestContext, result prometheusModel.Value, response *JobDetailStatisticsResponse, metricName, nameLabel string) error {
 	data, ok := result.(prometheusModel.Matrix)
 	if !ok {
 		ctx.Logging().Errorf("convert result to matrix failed")
@@ -187,7 +281,7 @@ func convertResultToDetailResponse(ctx *logger.RequestContext, result prometheus
 		for _, rangeValue := range value.Values {
 			taskValues = append(taskValues, [2]float64{float64(rangeValue.Timestamp.Unix()), float64(rangeValue.Value)})
 		}
-		taskName := string(value.Metric[common.Pod])
+		taskName := string(value.Metric[prometheusModel.LabelName(nameLabel)])
 		metricInfo := MetricInfo{
 			MetricName: metricName,
 			Values:     taskValues,
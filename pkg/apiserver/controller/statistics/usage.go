@@ -0,0 +1,138 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statistics
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/logger"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
+)
+
+// defaultUsageDays is the default date range of usage report, in days
+const defaultUsageDays = 30
+
+var usageCSVHeader = []string{"date", "name", "jobCount", "duration", "cpuSeconds", "memorySeconds", "gpuSeconds"}
+
+type UsageRequest struct {
+	GroupBy     string `json:"groupBy"`
+	StartDate   string `json:"startDate"`
+	EndDate     string `json:"endDate"`
+	UserName    string `json:"userName"`
+	QueueName   string `json:"queueName"`
+	ClusterName string `json:"clusterName"`
+}
+
+type UsageResponse struct {
+	GroupBy   string               `json:"groupBy"`
+	StartDate string               `json:"startDate"`
+	EndDate   string               `json:"endDate"`
+	Items     []model.UsageSummary `json:"items"`
+}
+
+// GetUsageStatistics get the usage of jobs rolled up by day and user, queue or cluster, users except root
+// can only get their own usage
+func GetUsageStatistics(ctx *logger.RequestContext, request UsageRequest) (*UsageResponse, error) {
+	if err := validateUsageRequest(ctx, &request); err != nil {
+		ctx.Logging().Errorf("validate usage request failed, error: %s", err.Error())
+		return nil, err
+	}
+	filter := storage.UsageFilter{
+		UserName:    request.UserName,
+		QueueName:   request.QueueName,
+		ClusterName: request.ClusterName,
+	}
+	items, err := storage.JobUsage.SummarizeUsage(request.GroupBy, request.StartDate, request.EndDate, filter)
+	if err != nil {
+		ctx.ErrorCode = common.InternalError
+		ctx.Logging().Errorf("summarize usage failed, error: %s", err.Error())
+		return nil, err
+	}
+	return &UsageResponse{
+		GroupBy:   request.GroupBy,
+		StartDate: request.StartDate,
+		EndDate:   request.EndDate,
+		Items:     items,
+	}, nil
+}
+
+func validateUsageRequest(ctx *logger.RequestContext, request *UsageRequest) error {
+	if !common.IsRootUser(ctx.UserName) {
+		if request.UserName != "" && request.UserName != ctx.UserName {
+			ctx.ErrorCode = common.AccessDenied
+			return common.NoAccessError(ctx.UserName, common.ResourceTypeUser, request.UserName)
+		}
+		request.UserName = ctx.UserName
+	}
+	if request.GroupBy == "" {
+		request.GroupBy = storage.UsageGroupByUser
+	}
+	if request.EndDate == "" {
+		request.EndDate = time.Now().Format(storage.UsageDateLayout)
+	}
+	endDate, err := time.Parse(storage.UsageDateLayout, request.EndDate)
+	if err != nil {
+		ctx.ErrorCode = common.InvalidURI
+		return fmt.Errorf("endDate[%s] is invalid, the layout of date is %s", request.EndDate, storage.UsageDateLayout)
+	}
+	if request.StartDate == "" {
+		request.StartDate = endDate.AddDate(0, 0, 1-defaultUsageDays).Format(storage.UsageDateLayout)
+	}
+	startDate, err := time.Parse(storage.UsageDateLayout, request.StartDate)
+	if err != nil {
+		ctx.ErrorCode = common.InvalidURI
+		return fmt.Errorf("startDate[%s] is invalid, the layout of date is %s", request.StartDate, storage.UsageDateLayout)
+	}
+	if startDate.After(endDate) {
+		ctx.ErrorCode = common.InvalidURI
+		return common.InvalidStartEndParams()
+	}
+	switch request.GroupBy {
+	case storage.UsageGroupByUser, storage.UsageGroupByQueue, storage.UsageGroupByCluster:
+	default:
+		ctx.ErrorCode = common.InvalidURI
+		return fmt.Errorf("groupBy[%s] is invalid, only support %s, %s and %s", request.GroupBy,
+			storage.UsageGroupByUser, storage.UsageGroupByQueue, storage.UsageGroupByCluster)
+	}
+	return nil
+}
+
+// WriteCSV write the usage items as csv, with a header line
+func (r *UsageResponse) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(usageCSVHeader); err != nil {
+		return err
+	}
+	formatFloat := func(f float64) string {
+		return strconv.FormatFloat(f, 'f', 2, 64)
+	}
+	for _, item := range r.Items {
+		record := []string{item.Date, item.Name, strconv.FormatInt(item.JobCount, 10), formatFloat(item.Duration),
+			formatFloat(item.CPUSeconds), formatFloat(item.MemorySeconds), formatFloat(item.GPUSeconds)}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -18,6 +18,7 @@ package middleware
 
 import (
 	"bytes"
+	"crypto/subtle"
 	"encoding/json"
 	"errors"
 	"io/ioutil"
@@ -26,6 +27,7 @@ import (
 	"time"
 
 	jwtgo "github.com/dgrijalva/jwt-go"
+	"github.com/go-chi/chi"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
@@ -182,3 +184,20 @@ func checkUserPermission(r *http.Request, userName string) bool {
 	}
 	return true
 }
+
+// ExtenderTokenAuth checks the shared token carried in the url path of scheduler extender requests, since
+// kube-scheduler and volcano can only be configured with an url prefix but not with auth headers.
+func ExtenderTokenAuth(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			requestID := req.Header.Get(common.HeaderKeyRequestID)
+			reqToken := chi.URLParam(req, TokenURLParam)
+			if subtle.ConstantTimeCompare([]byte(reqToken), []byte(token)) != 1 {
+				log.Errorf("ExtenderTokenAuth invalid token. requestID:[%s]", requestID)
+				common.RenderErr(res, requestID, common.AuthInvalidToken)
+				return
+			}
+			next.ServeHTTP(res, req)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi"
+	chimiddleware "github.com/go-chi/chi/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/tracing"
+)
+
+const (
+	// TokenURLParam is the url param of shared token, which is hidden in span attributes
+	TokenURLParam = "token"
+
+	redactedValue = "***"
+)
+
+// Tracing starts a server span for each request. The trace context of client is extracted from
+// request headers, and traceparent header of request is replaced by the server span, so that
+// controllers can propagate it through RequestContext.
+func Tracing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := propagation.TraceContext{}.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		// http.target is set after routing, since it may carry the token in url path
+		var attrs []attribute.KeyValue
+		for _, attr := range semconv.HTTPServerAttributesFromHTTPRequest("paddleflow", "", r) {
+			if attr.Key != semconv.HTTPTargetKey {
+				attrs = append(attrs, attr)
+			}
+		}
+		ctx, span := tracing.Tracer().Start(ctx, fmt.Sprintf("HTTP %s", r.Method),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(attrs...),
+			trace.WithAttributes(attribute.String("paddleflow.request_id", r.Header.Get(common.HeaderKeyRequestID))))
+		defer span.End()
+
+		if traceParent := tracing.TraceParent(ctx); traceParent != "" {
+			r.Header.Set(tracing.HeaderTraceParent, traceParent)
+		}
+		ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r.WithContext(ctx))
+
+		// route pattern and url params are available only after routing
+		rctx := chi.RouteContext(r.Context())
+		if rctx != nil && rctx.RoutePattern() != "" {
+			span.SetName(fmt.Sprintf("HTTP %s %s", r.Method, rctx.RoutePattern()))
+			span.SetAttributes(semconv.HTTPRouteKey.String(rctx.RoutePattern()))
+		}
+		span.SetAttributes(semconv.HTTPTargetKey.String(redactTarget(r.RequestURI, rctx)))
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(status)...)
+		span.SetStatus(semconv.SpanStatusFromHTTPStatusCode(status))
+	})
+}
+
+// redactTarget hides the token in url path of request
+func redactTarget(target string, rctx *chi.Context) string {
+	if rctx == nil {
+		return target
+	}
+	for i, key := range rctx.URLParams.Keys {
+		if key != TokenURLParam || rctx.URLParams.Values[i] == "" {
+			continue
+		}
+		token := "/" + rctx.URLParams.Values[i]
+		if idx := strings.Index(target, token+"/"); idx >= 0 {
+			return target[:idx] + "/" + redactedValue + target[idx+len(token):]
+		}
+		if strings.HasSuffix(target, token) {
+			return strings.TrimSuffix(target, token) + "/" + redactedValue
+		}
+	}
+	return target
+}
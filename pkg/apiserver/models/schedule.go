@@ -406,8 +406,9 @@ func ScheduleUsedFsIDs() (map[string]bool, error) {
 		}
 
 		for _, mount := range mounts {
-			mount.ID = common.ID(username, mount.Name)
-			fsIDMap[mount.ID] = true
+			// snapshots keep their fs in use
+			fsName, _ := schema.ParseFsSnapshotName(mount.Name)
+			fsIDMap[common.ID(username, fsName)] = true
 		}
 		if wfs.FsOptions.MainFS.Name != "" {
 			mainFSID := common.ID(username, wfs.FsOptions.MainFS.Name)
@@ -41,6 +41,8 @@ const (
 	ParamKeyPipelineID        = "pipelineID"
 	ParamKeyPipelineVersionID = "pipelineVersionID"
 	ParamKeyScheduleID        = "scheduleID"
+	ParamKeyTemplateID        = "templateID"
+	ParamKeyTemplateVersionID = "templateVersionID"
 
 	QueryKeyAction    = "action"
 	QueryActionStop   = "stop"
@@ -80,6 +82,14 @@ const (
 	QueryKeyLineLimit        = "lineLimit"
 	QueryKeyType             = "type"
 	QueryKeyFramework        = "framework"
+	QueryKeyGroupBy          = "groupBy"
+	QueryKeyStartDate        = "startDate"
+	QueryKeyEndDate          = "endDate"
+	QueryKeyFormat           = "format"
+	QueryKeyTemplate         = "template"
+	QueryKeyDryRun           = "dryRun"
+
+	FormatCSV = "csv"
 
 	ParamKeyClusterName   = "clusterName"
 	ParamKeyClusterNames  = "clusterNames"
@@ -92,6 +102,13 @@ const (
 	QueryClusterID  = "clusterID"
 	QueryNodeName   = "nodename"
 	QueryMountPoint = "mountpoint"
+	QueryRecursive  = "recursive"
+	QueryOverwrite  = "overwrite"
+	QuerySnapshot   = "snapshotName"
+	QueryUsage      = "usage"
+	QuerySyncTaskID = "taskID"
+	QueryOperation  = "operation"
+	QueryEndTime    = "endTime"
 
 	ParamFlavourName = "flavourName"
 
@@ -44,6 +44,7 @@ func (cr *ClusterRouter) AddRouter(r chi.Router) {
 	r.Post("/cluster", cr.createCluster)
 	r.Get("/cluster", cr.listCluster)
 	r.Get("/cluster/{clusterName}", cr.getClusterDetail)
+	r.Get("/cluster/{clusterName}/health", cr.getClusterHealth)
 	r.Delete("/cluster/{clusterName}", cr.deleteCluster)
 	r.Put("/cluster/{clusterName}", cr.updateCluster)
 	r.Get("/cluster/resource", cr.listClusterQuota)
@@ -131,6 +132,20 @@ func (cr *ClusterRouter) getClusterDetail(w http.ResponseWriter, r *http.Request
 	common.Render(w, http.StatusOK, response)
 }
 
+// 获取集群健康状态
+func (cr *ClusterRouter) getClusterHealth(w http.ResponseWriter, r *http.Request) {
+	ctx := common.GetRequestContext(r)
+	clusterName := strings.TrimSpace(chi.URLParam(r, util.ParamKeyClusterName))
+
+	response, err := cluster.GetClusterHealth(&ctx, clusterName)
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, ctx.ErrorMessage)
+		return
+	}
+
+	common.Render(w, http.StatusOK, response)
+}
+
 // 删除集群
 func (cr *ClusterRouter) deleteCluster(w http.ResponseWriter, r *http.Request) {
 	ctx := common.GetRequestContext(r)
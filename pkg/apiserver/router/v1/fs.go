@@ -34,6 +34,7 @@ import (
 	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/router/util"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/config"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/logger"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
 	fuse "github.com/PaddlePaddle/PaddleFlow/pkg/fs/client/fs"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/client/ufs/object"
 	fsCommon "github.com/PaddlePaddle/PaddleFlow/pkg/fs/common"
@@ -53,14 +54,37 @@ func (pr *PFSRouter) AddRouter(r chi.Router) {
 	// fs
 	r.Post("/fs", pr.createFileSystem)
 	r.Get("/fs", pr.listFileSystem)
+	r.Post("/fs/sync", pr.createFsSyncTask)
+	r.Get("/fs/sync", pr.listFsSyncTask)
+	r.Get("/fs/sync/{taskID}", pr.getFsSyncTask)
+	r.Put("/fs/sync/{taskID}", pr.updateFsSyncTask)
+	r.Delete("/fs/sync/{taskID}", pr.deleteFsSyncTask)
 	r.Get("/fs/{fsName}", pr.getFileSystem)
 	r.Delete("/fs/{fsName}", pr.deleteFileSystem)
+	// fs files
+	r.Get("/fs/{fsName}/files", pr.listFiles)
+	r.Delete("/fs/{fsName}/files", pr.deleteFile)
+	r.Get("/fs/{fsName}/files/stat", pr.statFile)
+	r.Get("/fs/{fsName}/files/download", pr.downloadFile)
+	r.Post("/fs/{fsName}/files/upload", pr.uploadFiles)
+	r.Post("/fs/{fsName}/files/dir", pr.createDir)
+	r.Put("/fs/{fsName}/files/rename", pr.renameFile)
+	r.Post("/fs/{fsName}/snapshots", pr.createFsSnapshot)
+	r.Get("/fs/{fsName}/snapshots", pr.listFsSnapshot)
+	r.Get("/fs/{fsName}/snapshots/{snapshotName}", pr.getFsSnapshot)
+	r.Delete("/fs/{fsName}/snapshots/{snapshotName}", pr.deleteFsSnapshot)
+	r.Put("/fs/{fsName}/quota", pr.setFsQuota)
+	r.Get("/fs/{fsName}/quota", pr.listFsQuota)
+	r.Delete("/fs/{fsName}/quota", pr.deleteFsQuota)
 	// fs cache config
 	r.Post("/fsCache", pr.createFSCacheConfig)
 	r.Get("/fsCache/{fsName}", pr.getFSCacheConfig)
 	r.Delete("/fsCache/{fsName}", pr.deleteFSCacheConfig)
 
 	r.Get("/fsSts/{fsName}", pr.getStsSessionToken)
+	// fs audit log
+	r.Post("/fsAudit", pr.reportFsAudit)
+	r.Get("/fsAudit", pr.listFsAudit)
 }
 
 var URLPrefix = map[string]bool{
@@ -80,6 +104,15 @@ var FsnamePlusUsernameMaxLen int = 63 - len("fs-") - len("-")
 
 // obsoleted funcs: create PVC code can be found in commit 23e7038cecd7bfa9acdc80bbe1d62d904dbe1568
 
+// ownerFsID returns the id of fs in request, the fs owner can be assigned by query username
+func ownerFsID(ctx *logger.RequestContext, r *http.Request) string {
+	owner := r.URL.Query().Get(util.QueryKeyUserName)
+	if owner == "" {
+		owner = ctx.UserName
+	}
+	return common.ID(owner, chi.URLParam(r, util.QueryFsName))
+}
+
 // createFileSystem the function that handle the create file system request
 // @Summary createFileSystem
 // @Description 创建文件系统
@@ -148,6 +181,10 @@ func validateCreateFileSystem(ctx *logger.RequestContext, req *api.CreateFileSys
 		ctx.ErrorMessage = common.InvalidField("name", fmt.Sprintf("fsName[%s] must be letters or numbers and fsName maximum length is %d", req.Name, FsNameMaxLen)).Error()
 		return common.InvalidField("name", fmt.Sprintf("fsName[%s] must be letters or numbers and fsName maximum length is %d", req.Name, FsNameMaxLen))
 	}
+	if req.Name == fsNameSync {
+		ctx.ErrorCode = common.FileSystemNameFormatError
+		return common.InvalidField("name", fmt.Sprintf("fsName[%s] is reserved", req.Name))
+	}
 	if len(req.Username)+len(req.Name) > FsnamePlusUsernameMaxLen {
 		ctx.Logging().Errorf("The sum of the lengths of username[%s] and fsName[%s] should be less than %d", req.Username, req.Name, FsnamePlusUsernameMaxLen)
 		ctx.ErrorCode = common.FileSystemNameFormatError
@@ -646,5 +683,6 @@ func (pr *PFSRouter) getStsSessionToken(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	result.SecretAccessKey = sk
+	api.RecordFsAudit(&ctx, common.ID(realUserName, fsName), schema.FsAuditOpStsToken, "", "")
 	common.Render(w, http.StatusOK, result)
 }
@@ -0,0 +1,107 @@
+/*
+Copyright (c) 2021 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
+	api "github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/controller/fs"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/router/util"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+)
+
+// reportFsAudit the function that handle the report fs audit events request
+// @Summary reportFsAudit
+// @Description 挂载客户端上报采样的文件打开、删除事件，记录到文件系统审计日志
+// @tag fs
+// @Accept   json
+// @Produce  json
+// @Param request body fs.ReportFsAuditRequest true "request body"
+// @Success 200
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 403 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Router /fsAudit [post]
+func (pr *PFSRouter) reportFsAudit(w http.ResponseWriter, r *http.Request) {
+	ctx := common.GetRequestContext(r)
+	var reportRequest api.ReportFsAuditRequest
+	if err := common.BindJSON(r, &reportRequest); err != nil {
+		ctx.ErrorCode = common.MalformedJSON
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	reportRequest.Username = getRealUserName(&ctx, reportRequest.Username)
+	fsName, _ := schema.ParseFsSnapshotName(reportRequest.FsName)
+	fsID := common.ID(reportRequest.Username, fsName)
+	if ok, err := api.GetFileSystemService().HasFsPermission(ctx.UserName, fsID); err != nil || !ok {
+		ctx.ErrorCode = common.AccessDenied
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode,
+			fmt.Sprintf("user[%s] has no permission of fs[%s]", ctx.UserName, fsID))
+		return
+	}
+	if err := api.ReportFsAudit(&ctx, &reportRequest); err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	common.RenderStatus(w, http.StatusOK)
+}
+
+// listFsAudit the function that handle the list fs audit logs request
+// @Summary listFsAudit
+// @Description 按发生顺序列出文件系统审计日志，普通用户只能查询自己的文件系统，管理员可查询全部
+// @tag fs
+// @Accept   json
+// @Produce  json
+// @Param fsName query string false "文件系统名称，普通用户必填"
+// @Param username query string false "文件系统所属用户"
+// @Param jobID query string false "作业ID"
+// @Param operation query string false "操作类型，多个以逗号分隔"
+// @Param startTime query string false "开始时间，格式为2006-01-02 15:04:05"
+// @Param endTime query string false "结束时间，格式为2006-01-02 15:04:05"
+// @Param marker query string false "查询起始条目加密条码"
+// @Param maxKeys query int false "每页条数"
+// @Success 200 {object} fs.ListFsAuditLogResponse
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Router /fsAudit [get]
+func (pr *PFSRouter) listFsAudit(w http.ResponseWriter, r *http.Request) {
+	ctx := common.GetRequestContext(r)
+	maxKeys, err := util.GetQueryMaxKeys(&ctx, r)
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	query := r.URL.Query()
+	listRequest := api.ListFsAuditLogRequest{
+		FsName:    query.Get(util.QueryFsName),
+		Username:  getRealUserName(&ctx, query.Get(util.QueryKeyUserName)),
+		JobID:     query.Get(util.ParamKeyJobID),
+		Operation: query.Get(util.QueryOperation),
+		StartTime: query.Get(util.QueryKeyStartTime),
+		EndTime:   query.Get(util.QueryEndTime),
+		Marker:    query.Get(util.QueryKeyMarker),
+		MaxKeys:   maxKeys,
+	}
+	response, err := api.ListFsAuditLog(&ctx, &listRequest)
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	common.Render(w, http.StatusOK, response)
+}
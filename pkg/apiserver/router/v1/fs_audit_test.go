@@ -0,0 +1,100 @@
+/*
+Copyright (c) 2021 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	api "github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/controller/fs"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/logger"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
+)
+
+func TestFsAudit(t *testing.T) {
+	router, baseUrl := prepareDBAndAPI(t)
+	fsModel := mockFS()
+	assert.NoError(t, storage.Filesystem.CreatFileSystem(&fsModel))
+	rootCtx := &logger.RequestContext{UserName: MockRootUser}
+	api.RecordFsAudit(rootCtx, mockFsID, schema.FsAuditOpStsToken, "", "")
+	auditUrl := baseUrl + "/fsAudit"
+
+	// fuse client reports events
+	report := api.ReportFsAuditRequest{
+		FsName: mockFsName,
+		Events: []api.FsAuditEvent{
+			{Operation: schema.FsAuditOpOpen, Path: "/a.txt", JobID: "job-1", Uid: 1000, Timestamp: 1},
+			{Operation: schema.FsAuditOpUnlink, Path: "/b.txt", JobID: "job-1"},
+		},
+	}
+	result, err := PerformPostRequest(router, auditUrl, report)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, result.Code, result.Body.String())
+	// server operations can not be reported
+	result, err = PerformPostRequest(router, auditUrl, api.ReportFsAuditRequest{FsName: mockFsName,
+		Events: []api.FsAuditEvent{{Operation: schema.FsAuditOpCreateFs}}})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, result.Code)
+	result, err = PerformPostRequest(router, auditUrl, api.ReportFsAuditRequest{FsName: "notexist"})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, result.Code)
+
+	// list by pages
+	result, err = PerformGetRequest(router, auditUrl+"?fsName="+mockFsName+"&maxKeys=2")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, result.Code, result.Body.String())
+	list := api.ListFsAuditLogResponse{}
+	assert.NoError(t, ParseBody(result.Body, &list))
+	assert.Equal(t, 2, len(list.AuditLogList))
+	assert.Equal(t, schema.FsAuditOpStsToken, list.AuditLogList[0].Operation)
+	assert.Equal(t, schema.FsAuditSourceServer, list.AuditLogList[0].Source)
+	assert.Equal(t, "uid=1000", list.AuditLogList[1].Detail)
+	// logs are timed by server, and the client time is kept separately
+	assert.Equal(t, time.Unix(1, 0).Format("2006-01-02 15:04:05"), list.AuditLogList[1].OccurTime)
+	assert.NotEqual(t, list.AuditLogList[1].OccurTime, list.AuditLogList[1].CreateTime)
+	assert.Empty(t, list.AuditLogList[0].OccurTime)
+	assert.True(t, list.IsTruncated)
+	result, err = PerformGetRequest(router, auditUrl+"?fsName="+mockFsName+"&marker="+list.NextMarker)
+	assert.NoError(t, err)
+	list = api.ListFsAuditLogResponse{}
+	assert.NoError(t, ParseBody(result.Body, &list))
+	assert.Equal(t, 1, len(list.AuditLogList))
+	assert.Equal(t, "/b.txt", list.AuditLogList[0].Path)
+	assert.False(t, list.IsTruncated)
+
+	// list by filters
+	result, err = PerformGetRequest(router, auditUrl+"?operation=open,unlink&jobID=job-1")
+	assert.NoError(t, err)
+	list = api.ListFsAuditLogResponse{}
+	assert.NoError(t, ParseBody(result.Body, &list))
+	assert.Equal(t, 2, len(list.AuditLogList))
+	result, err = PerformGetRequest(router, auditUrl+"?startTime=2000-01-01")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, result.Code)
+
+	// non-root users can only list their own file systems
+	_, err = api.ListFsAuditLog(&logger.RequestContext{UserName: mockUserName}, &api.ListFsAuditLogRequest{MaxKeys: 10})
+	assert.Error(t, err)
+	logs, err := storage.Filesystem.ListFsAuditLog(0, 0, model.FsAuditLogFilter{FsID: mockFsID})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(logs))
+}
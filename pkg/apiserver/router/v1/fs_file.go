@@ -0,0 +1,375 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
+	api "github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/controller/fs"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/router/util"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/logger"
+	fuse "github.com/PaddlePaddle/PaddleFlow/pkg/fs/client/fs"
+)
+
+// fsFileClient resolves the fs of request, the fs owner can be assigned by query username, and returns a client to it
+func fsFileClient(ctx *logger.RequestContext, r *http.Request) (fuse.FSClient, error) {
+	fsName := chi.URLParam(r, util.QueryFsName)
+	owner := r.URL.Query().Get(util.QueryKeyUserName)
+	if owner == "" {
+		owner = ctx.UserName
+	}
+	return api.GetFileSystemService().GetFsClient(ctx, common.FsOrSnapshotID(owner, fsName))
+}
+
+func getBoolQuery(ctx *logger.RequestContext, r *http.Request, key string) (bool, error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		ctx.ErrorCode = common.InvalidURI
+		return false, fmt.Errorf("query %s[%s] must be a bool", key, value)
+	}
+	return b, nil
+}
+
+// statFile the function that handle the stat file request
+// @Summary statFile
+// @Description 获取文件系统中文件或目录的信息
+// @tag fs
+// @Accept   json
+// @Produce  json
+// @Param fsName path string true "文件系统名称"
+// @Param path query string true "文件路径"
+// @Param username query string false "文件系统所属用户"
+// @Success 200 {object} fs.FileInfo
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 404 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Router /fs/{fsName}/files/stat [get]
+func (pr *PFSRouter) statFile(w http.ResponseWriter, r *http.Request) {
+	ctx := common.GetRequestContext(r)
+	filePath, err := api.CleanFilePath(&ctx, r.URL.Query().Get(util.QueryPath))
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	client, err := fsFileClient(&ctx, r)
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	defer client.Close()
+	info, err := api.GetFileSystemService().StatFile(&ctx, client, filePath)
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	common.Render(w, http.StatusOK, info)
+}
+
+// listFiles the function that handle the list files request
+// @Summary listFiles
+// @Description 分页列出文件系统目录下的文件
+// @tag fs
+// @Accept   json
+// @Produce  json
+// @Param fsName path string true "文件系统名称"
+// @Param path query string false "目录路径，默认为根目录"
+// @Param marker query string false "上一页最后一个文件名"
+// @Param maxKeys query int false "每页条数"
+// @Param username query string false "文件系统所属用户"
+// @Success 200 {object} fs.ListFilesResponse
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 404 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Router /fs/{fsName}/files [get]
+func (pr *PFSRouter) listFiles(w http.ResponseWriter, r *http.Request) {
+	ctx := common.GetRequestContext(r)
+	maxKeys, err := util.GetQueryMaxKeys(&ctx, r)
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	dir, err := api.CleanFilePath(&ctx, r.URL.Query().Get(util.QueryPath))
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	client, err := fsFileClient(&ctx, r)
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	defer client.Close()
+	listRequest := &api.ListFilesRequest{
+		Path:    dir,
+		Marker:  r.URL.Query().Get(util.QueryKeyMarker),
+		MaxKeys: int32(maxKeys),
+	}
+	response, err := api.GetFileSystemService().ListFiles(&ctx, client, listRequest)
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	common.Render(w, http.StatusOK, response)
+}
+
+// downloadFile the function that handle the download file request, Range header is supported
+// @Summary downloadFile
+// @Description 下载文件系统中的文件，支持Range请求
+// @tag fs
+// @Produce  octet-stream
+// @Param fsName path string true "文件系统名称"
+// @Param path query string true "文件路径"
+// @Param username query string false "文件系统所属用户"
+// @Success 200 {file} file
+// @Success 206 {file} file
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 404 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Router /fs/{fsName}/files/download [get]
+func (pr *PFSRouter) downloadFile(w http.ResponseWriter, r *http.Request) {
+	ctx := common.GetRequestContext(r)
+	filePath, err := api.CleanFilePath(&ctx, r.URL.Query().Get(util.QueryPath))
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	client, err := fsFileClient(&ctx, r)
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	defer client.Close()
+	file, info, err := api.GetFileSystemService().OpenFile(&ctx, client, filePath)
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	defer file.Close()
+
+	modTime, _ := time.ParseInLocation(api.TimeFormat, info.ModTime, time.Local)
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": info.Name}))
+	http.ServeContent(w, r, info.Name, modTime, file)
+}
+
+// uploadFiles the function that handle the multipart upload files request
+// @Summary uploadFiles
+// @Description 上传文件到文件系统的指定目录
+// @tag fs
+// @Accept   multipart/form-data
+// @Produce  json
+// @Param fsName path string true "文件系统名称"
+// @Param path query string false "目标目录，默认为根目录"
+// @Param overwrite query bool false "是否覆盖已存在的文件"
+// @Param username query string false "文件系统所属用户"
+// @Param file formData file true "上传的文件"
+// @Success 201 {object} fs.UploadFilesResponse
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 409 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Router /fs/{fsName}/files/upload [post]
+func (pr *PFSRouter) uploadFiles(w http.ResponseWriter, r *http.Request) {
+	ctx := common.GetRequestContext(r)
+	dir, err := api.CleanFilePath(&ctx, r.URL.Query().Get(util.QueryPath))
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	overwrite, err := getBoolQuery(&ctx, r, util.QueryOverwrite)
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	reader, err := r.MultipartReader()
+	if err != nil {
+		ctx.Logging().Errorf("upload files read multipart err: %v", err)
+		common.RenderErrWithMessage(w, ctx.RequestID, common.InvalidHTTPRequest, err.Error())
+		return
+	}
+	client, err := fsFileClient(&ctx, r)
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	defer client.Close()
+
+	response := api.UploadFilesResponse{FileList: []*api.FileInfo{}}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			ctx.Logging().Errorf("upload files read part err: %v", err)
+			common.RenderErrWithMessage(w, ctx.RequestID, common.InvalidHTTPRequest, err.Error())
+			return
+		}
+		if part.FileName() == "" {
+			part.Close()
+			continue
+		}
+		info, err := api.GetFileSystemService().UploadFile(&ctx, client, dir, part.FileName(), part, overwrite)
+		part.Close()
+		if err != nil {
+			common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+			return
+		}
+		response.FileList = append(response.FileList, info)
+	}
+	if len(response.FileList) == 0 {
+		common.RenderErrWithMessage(w, ctx.RequestID, common.InvalidHTTPRequest, "no file found in multipart form")
+		return
+	}
+	common.Render(w, http.StatusCreated, response)
+}
+
+// createDir the function that handle the create directory request
+// @Summary createDir
+// @Description 在文件系统中创建目录，父目录不存在时一并创建
+// @tag fs
+// @Accept   json
+// @Produce  json
+// @Param fsName path string true "文件系统名称"
+// @Param username query string false "文件系统所属用户"
+// @Param request body fs.CreateDirRequest true "request body"
+// @Success 201 {string} string Created
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Router /fs/{fsName}/files/dir [post]
+func (pr *PFSRouter) createDir(w http.ResponseWriter, r *http.Request) {
+	ctx := common.GetRequestContext(r)
+	var request api.CreateDirRequest
+	if err := common.BindJSON(r, &request); err != nil {
+		ctx.Logging().Errorf("CreateDir bindjson failed. err:%s", err.Error())
+		common.RenderErrWithMessage(w, ctx.RequestID, common.MalformedJSON, err.Error())
+		return
+	}
+	dir, err := api.CleanFilePath(&ctx, request.Path)
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	client, err := fsFileClient(&ctx, r)
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	defer client.Close()
+	if err = api.GetFileSystemService().CreateDir(&ctx, client, dir); err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	common.RenderStatus(w, http.StatusCreated)
+}
+
+// renameFile the function that handle the rename file request
+// @Summary renameFile
+// @Description 重命名或移动文件系统中的文件或目录
+// @tag fs
+// @Accept   json
+// @Produce  json
+// @Param fsName path string true "文件系统名称"
+// @Param username query string false "文件系统所属用户"
+// @Param request body fs.RenameFileRequest true "request body"
+// @Success 200
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 404 {object} common.ErrorResponse
+// @Failure 409 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Router /fs/{fsName}/files/rename [put]
+func (pr *PFSRouter) renameFile(w http.ResponseWriter, r *http.Request) {
+	ctx := common.GetRequestContext(r)
+	var request api.RenameFileRequest
+	if err := common.BindJSON(r, &request); err != nil {
+		ctx.Logging().Errorf("RenameFile bindjson failed. err:%s", err.Error())
+		common.RenderErrWithMessage(w, ctx.RequestID, common.MalformedJSON, err.Error())
+		return
+	}
+	srcPath, err := api.CleanFilePath(&ctx, request.SrcPath)
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	dstPath, err := api.CleanFilePath(&ctx, request.DstPath)
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	client, err := fsFileClient(&ctx, r)
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	defer client.Close()
+	if err = api.GetFileSystemService().RenameFile(&ctx, client, srcPath, dstPath); err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	common.RenderStatus(w, http.StatusOK)
+}
+
+// deleteFile the function that handle the delete file request
+// @Summary deleteFile
+// @Description 删除文件系统中的文件或目录，非空目录需指定recursive
+// @tag fs
+// @Accept   json
+// @Produce  json
+// @Param fsName path string true "文件系统名称"
+// @Param path query string true "文件路径"
+// @Param recursive query bool false "是否递归删除目录"
+// @Param username query string false "文件系统所属用户"
+// @Success 200
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 404 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Router /fs/{fsName}/files [delete]
+func (pr *PFSRouter) deleteFile(w http.ResponseWriter, r *http.Request) {
+	ctx := common.GetRequestContext(r)
+	filePath, err := api.CleanFilePath(&ctx, r.URL.Query().Get(util.QueryPath))
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	recursive, err := getBoolQuery(&ctx, r, util.QueryRecursive)
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	client, err := fsFileClient(&ctx, r)
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	defer client.Close()
+	if err = api.GetFileSystemService().DeleteFile(&ctx, client, filePath, recursive); err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	common.RenderStatus(w, http.StatusOK)
+}
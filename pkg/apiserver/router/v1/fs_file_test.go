@@ -0,0 +1,158 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"bytes"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	api "github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/controller/fs"
+	fuse "github.com/PaddlePaddle/PaddleFlow/pkg/fs/client/fs"
+	fsCommon "github.com/PaddlePaddle/PaddleFlow/pkg/fs/common"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
+)
+
+func performUploadRequest(handler http.Handler, path, fileName string, content []byte) *httptest.ResponseRecorder {
+	buf := &bytes.Buffer{}
+	form := multipart.NewWriter(buf)
+	part, _ := form.CreateFormFile("file", fileName)
+	_, _ = part.Write(content)
+	_ = form.Close()
+	req, _ := http.NewRequest(http.MethodPost, path, buf)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestFsFiles(t *testing.T) {
+	router, baseUrl := prepareDBAndAPI(t)
+	mockDir := "./mock_fs_files"
+	os.RemoveAll(mockDir)
+	assert.NoError(t, os.MkdirAll(mockDir, 0755))
+	defer os.RemoveAll(mockDir)
+
+	fsModel := model.FileSystem{
+		Model:    model.Model{ID: mockFsID},
+		Name:     mockFsName,
+		UserName: MockRootUser,
+		Type:     fsCommon.LocalType,
+		SubPath:  mockDir,
+	}
+	assert.NoError(t, storage.Filesystem.CreatFileSystem(&fsModel))
+
+	newFsClient := api.NewFsClient
+	defer func() { api.NewFsClient = newFsClient }()
+	api.NewFsClient = func(fsID string) (fuse.FSClient, error) {
+		assert.Equal(t, mockFsID, fsID)
+		return fuse.NewFSClientForTest(fsCommon.FSMeta{
+			UfsType:    fsCommon.LocalType,
+			SubPath:    mockDir,
+			Properties: map[string]string{fsCommon.RootKey: mockDir},
+		})
+	}
+	filesUrl := baseUrl + "/fs/" + mockFsName + "/files"
+
+	// mkdir and upload
+	result, err := PerformPostRequest(router, filesUrl+"/dir", api.CreateDirRequest{Path: "data/train"})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, result.Code)
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		result = performUploadRequest(router, filesUrl+"/upload?path=data/train", name, []byte("0123456789"))
+		assert.Equal(t, http.StatusCreated, result.Code, result.Body.String())
+	}
+	result = performUploadRequest(router, filesUrl+"/upload?path=data/train", "a.txt", []byte("new"))
+	assert.Equal(t, http.StatusConflict, result.Code)
+
+	// stat and list with pagination
+	result, err = PerformGetRequest(router, filesUrl+"/stat?path=/data/train/a.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, result.Code)
+	info := api.FileInfo{}
+	assert.NoError(t, ParseBody(result.Body, &info))
+	assert.Equal(t, "a.txt", info.Name)
+	assert.Equal(t, int64(10), info.Size)
+
+	result, err = PerformGetRequest(router, filesUrl+"?path=data/train&maxKeys=2")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, result.Code)
+	list := api.ListFilesResponse{}
+	assert.NoError(t, ParseBody(result.Body, &list))
+	assert.True(t, list.Truncated)
+	assert.Equal(t, "b.txt", list.NextMarker)
+	assert.Equal(t, 2, len(list.FileList))
+	assert.Equal(t, "data/train/a.txt", list.FileList[0].Path)
+
+	result, err = PerformGetRequest(router, filesUrl+"?path=data/train&maxKeys=2&marker=b.txt")
+	assert.NoError(t, err)
+	list = api.ListFilesResponse{}
+	assert.NoError(t, ParseBody(result.Body, &list))
+	assert.False(t, list.Truncated)
+	assert.Equal(t, 1, len(list.FileList))
+	assert.Equal(t, "c.txt", list.FileList[0].Name)
+
+	// download with range, served by a real server as chi wraps the writer as io.ReaderFrom
+	server := httptest.NewServer(router)
+	defer server.Close()
+	req, _ := http.NewRequest(http.MethodGet, server.URL+filesUrl+"/download?path=data/train/b.txt", nil)
+	req.Header.Set("Range", "bytes=2-5")
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	content, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusPartialContent, resp.StatusCode)
+	assert.Equal(t, "2345", string(content))
+	resp, err = http.Get(server.URL + filesUrl + "/download?path=data/train/b.txt")
+	assert.NoError(t, err)
+	content, _ = ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "0123456789", string(content))
+	assert.Equal(t, `attachment; filename=b.txt`, resp.Header.Get("Content-Disposition"))
+
+	// rename
+	result, err = PerformPutRequest(router, filesUrl+"/rename",
+		api.RenameFileRequest{SrcPath: "data/train/c.txt", DstPath: "data/c.txt"})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, result.Code)
+	result, err = PerformGetRequest(router, filesUrl+"/stat?path=data/train/c.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, result.Code)
+
+	// invalid path
+	result, err = PerformGetRequest(router, filesUrl+"/stat?path=../etc/passwd")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, result.Code)
+
+	// delete
+	result, err = PerformDeleteRequest(router, filesUrl+"?path=data/train")
+	assert.NoError(t, err)
+	assert.NotEqual(t, http.StatusOK, result.Code)
+	result, err = PerformDeleteRequest(router, filesUrl+"?path=data&recursive=true")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, result.Code)
+	_, err = os.Stat(mockDir + "/data")
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,108 @@
+/*
+Copyright (c) 2021 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"net/http"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
+	api "github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/controller/fs"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/router/util"
+)
+
+// setFsQuota the function that handle the set fs quota request
+// @Summary setFsQuota
+// @Description 设置文件系统或其子目录的容量和文件数配额，由挂载客户端执行，超出配额时写入返回 EDQUOT，仅限管理员。
+// @Description 配额由每个挂载客户端各自统计和限制（尽力而为），多个客户端可以分别用满配额，用量每10分钟重新扫描一次
+// @tag fs
+// @Accept   json
+// @Produce  json
+// @Param fsName path string true "文件系统名称"
+// @Param username query string false "文件系统所属用户"
+// @Param request body fs.SetFsQuotaRequest true "request body"
+// @Success 200 {object} fs.FsQuotaResponse
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 403 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Router /fs/{fsName}/quota [put]
+func (pr *PFSRouter) setFsQuota(w http.ResponseWriter, r *http.Request) {
+	ctx := common.GetRequestContext(r)
+	var setRequest api.SetFsQuotaRequest
+	if err := common.BindJSON(r, &setRequest); err != nil {
+		ctx.ErrorCode = common.MalformedJSON
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	quota, err := api.GetFileSystemService().SetFsQuota(&ctx, ownerFsID(&ctx, r), &setRequest)
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	common.Render(w, http.StatusOK, api.FsQuotaResponseFromModel(*quota))
+}
+
+// listFsQuota the function that handle the list fs quota request
+// @Summary listFsQuota
+// @Description 列出文件系统的配额，usage为true时遍历目录统计已用容量和文件数
+// @tag fs
+// @Accept   json
+// @Produce  json
+// @Param fsName path string true "文件系统名称"
+// @Param username query string false "文件系统所属用户"
+// @Param usage query bool false "是否统计用量"
+// @Success 200 {object} fs.ListFsQuotaResponse
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Router /fs/{fsName}/quota [get]
+func (pr *PFSRouter) listFsQuota(w http.ResponseWriter, r *http.Request) {
+	ctx := common.GetRequestContext(r)
+	withUsage, err := getBoolQuery(&ctx, r, util.QueryUsage)
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	quotas, err := api.GetFileSystemService().ListFsQuota(&ctx, ownerFsID(&ctx, r), withUsage)
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	common.Render(w, http.StatusOK, api.ListFsQuotaResponse{QuotaList: quotas})
+}
+
+// deleteFsQuota the function that handle the delete fs quota request
+// @Summary deleteFsQuota
+// @Description 删除文件系统目录的配额，仅限管理员
+// @tag fs
+// @Accept   json
+// @Produce  json
+// @Param fsName path string true "文件系统名称"
+// @Param path query string false "配额目录，默认为根目录"
+// @Param username query string false "文件系统所属用户"
+// @Success 200
+// @Failure 403 {object} common.ErrorResponse
+// @Failure 404 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Router /fs/{fsName}/quota [delete]
+func (pr *PFSRouter) deleteFsQuota(w http.ResponseWriter, r *http.Request) {
+	ctx := common.GetRequestContext(r)
+	err := api.GetFileSystemService().DeleteFsQuota(&ctx, ownerFsID(&ctx, r), r.URL.Query().Get(util.QueryPath))
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	common.RenderStatus(w, http.StatusOK)
+}
@@ -0,0 +1,110 @@
+/*
+Copyright (c) 2021 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	api "github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/controller/fs"
+	fuse "github.com/PaddlePaddle/PaddleFlow/pkg/fs/client/fs"
+	fsCommon "github.com/PaddlePaddle/PaddleFlow/pkg/fs/common"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
+)
+
+func TestFsQuota(t *testing.T) {
+	router, baseUrl := prepareDBAndAPI(t)
+	mockDir, err := filepath.Abs("./mock_fs_quota")
+	assert.NoError(t, err)
+	os.RemoveAll(mockDir)
+	assert.NoError(t, os.MkdirAll(mockDir+"/data", 0755))
+	defer os.RemoveAll(mockDir)
+	assert.NoError(t, ioutil.WriteFile(mockDir+"/data/a.txt", []byte("0123456789"), 0644))
+
+	fsModel := model.FileSystem{
+		Model:    model.Model{ID: mockFsID},
+		Name:     mockFsName,
+		UserName: MockRootUser,
+		Type:     fsCommon.LocalType,
+		SubPath:  mockDir,
+		PropertiesMap: map[string]string{
+			fsCommon.RootKey: mockDir,
+		},
+	}
+	assert.NoError(t, storage.Filesystem.CreatFileSystem(&fsModel))
+	quotaUrl := baseUrl + "/fs/" + mockFsName + "/quota"
+
+	result, err := PerformPutRequest(router, quotaUrl, api.SetFsQuotaRequest{Path: "data", MaxBytes: -1})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, result.Code)
+	result, err = PerformPutRequest(router, quotaUrl, api.SetFsQuotaRequest{Path: "../data", MaxBytes: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, result.Code)
+
+	result, err = PerformPutRequest(router, quotaUrl, api.SetFsQuotaRequest{Path: "data", MaxBytes: 100})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, result.Code, result.Body.String())
+	result, err = PerformPutRequest(router, quotaUrl, api.SetFsQuotaRequest{Path: "/data/", MaxBytes: 1024, MaxInodes: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, result.Code, result.Body.String())
+	quota := api.FsQuotaResponse{}
+	assert.NoError(t, ParseBody(result.Body, &quota))
+	assert.Equal(t, "/data", quota.Path)
+	assert.Equal(t, int64(1024), quota.MaxBytes)
+
+	// quotas are persisted in fs for clients
+	content, err := ioutil.ReadFile(filepath.Join(mockDir, fsCommon.LinkMetaDir, fsCommon.QuotaMetaFile))
+	assert.NoError(t, err)
+	var quotasMeta []fsCommon.FsQuota
+	assert.NoError(t, json.Unmarshal(content, &quotasMeta))
+	assert.Equal(t, []fsCommon.FsQuota{{Path: "/data", MaxBytes: 1024, MaxInodes: 10}}, quotasMeta)
+
+	newFsClient := api.NewFsClient
+	defer func() { api.NewFsClient = newFsClient }()
+	api.NewFsClient = func(fsID string) (fuse.FSClient, error) {
+		return fuse.NewFSClientForTest(fsCommon.FSMeta{
+			UfsType:    fsCommon.LocalType,
+			SubPath:    mockDir,
+			Properties: map[string]string{fsCommon.RootKey: mockDir},
+		})
+	}
+	result, err = PerformGetRequest(router, quotaUrl+"?usage=true")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, result.Code, result.Body.String())
+	list := api.ListFsQuotaResponse{}
+	assert.NoError(t, ParseBody(result.Body, &list))
+	assert.Equal(t, 1, len(list.QuotaList))
+	assert.Equal(t, int64(10), *list.QuotaList[0].UsedBytes)
+	assert.Equal(t, int64(1), *list.QuotaList[0].UsedInodes)
+
+	result, err = PerformDeleteRequest(router, quotaUrl+"?path=other")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, result.Code)
+	result, err = PerformDeleteRequest(router, quotaUrl+"?path=data")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, result.Code, result.Body.String())
+	content, err = ioutil.ReadFile(filepath.Join(mockDir, fsCommon.LinkMetaDir, fsCommon.QuotaMetaFile))
+	assert.NoError(t, err)
+	assert.Equal(t, "[]", string(content))
+}
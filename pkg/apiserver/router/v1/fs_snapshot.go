@@ -0,0 +1,131 @@
+/*
+Copyright (c) 2021 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
+	api "github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/controller/fs"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/router/util"
+)
+
+// createFsSnapshot the function that handle the create fs snapshot request
+// @Summary createFsSnapshot
+// @Description 创建文件系统快照，对象存储记录对象清单，本地和hdfs使用拷贝或原生快照，快照可通过 fsName@snapshotName 只读挂载
+// @tag fs
+// @Accept   json
+// @Produce  json
+// @Param fsName path string true "文件系统名称"
+// @Param username query string false "文件系统所属用户"
+// @Param request body fs.CreateFsSnapshotRequest true "request body"
+// @Success 201 {object} fs.FsSnapshotResponse
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 409 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Router /fs/{fsName}/snapshots [post]
+func (pr *PFSRouter) createFsSnapshot(w http.ResponseWriter, r *http.Request) {
+	ctx := common.GetRequestContext(r)
+	var createRequest api.CreateFsSnapshotRequest
+	if err := common.BindJSON(r, &createRequest); err != nil {
+		ctx.ErrorCode = common.MalformedJSON
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	snapshot, err := api.GetFileSystemService().CreateFsSnapshot(&ctx, ownerFsID(&ctx, r), &createRequest)
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	common.Render(w, http.StatusCreated, api.FsSnapshotResponseFromModel(*snapshot))
+}
+
+// listFsSnapshot the function that handle the list fs snapshots request
+// @Summary listFsSnapshot
+// @Description 列出文件系统的快照
+// @tag fs
+// @Accept   json
+// @Produce  json
+// @Param fsName path string true "文件系统名称"
+// @Param username query string false "文件系统所属用户"
+// @Success 200 {object} fs.ListFsSnapshotResponse
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Router /fs/{fsName}/snapshots [get]
+func (pr *PFSRouter) listFsSnapshot(w http.ResponseWriter, r *http.Request) {
+	ctx := common.GetRequestContext(r)
+	snapshots, err := api.GetFileSystemService().ListFsSnapshot(&ctx, ownerFsID(&ctx, r))
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	response := api.ListFsSnapshotResponse{SnapshotList: make([]*api.FsSnapshotResponse, 0, len(snapshots))}
+	for _, snapshot := range snapshots {
+		response.SnapshotList = append(response.SnapshotList, api.FsSnapshotResponseFromModel(snapshot))
+	}
+	common.Render(w, http.StatusOK, response)
+}
+
+// getFsSnapshot the function that handle the get fs snapshot request
+// @Summary getFsSnapshot
+// @Description 获取文件系统快照的状态
+// @tag fs
+// @Accept   json
+// @Produce  json
+// @Param fsName path string true "文件系统名称"
+// @Param snapshotName path string true "快照名称"
+// @Param username query string false "文件系统所属用户"
+// @Success 200 {object} fs.FsSnapshotResponse
+// @Failure 404 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Router /fs/{fsName}/snapshots/{snapshotName} [get]
+func (pr *PFSRouter) getFsSnapshot(w http.ResponseWriter, r *http.Request) {
+	ctx := common.GetRequestContext(r)
+	snapshot, err := api.GetFileSystemService().GetFsSnapshot(&ctx, ownerFsID(&ctx, r),
+		chi.URLParam(r, util.QuerySnapshot))
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	common.Render(w, http.StatusOK, api.FsSnapshotResponseFromModel(snapshot))
+}
+
+// deleteFsSnapshot the function that handle the delete fs snapshot request
+// @Summary deleteFsSnapshot
+// @Description 删除未被挂载的文件系统快照及其存储数据
+// @tag fs
+// @Accept   json
+// @Produce  json
+// @Param fsName path string true "文件系统名称"
+// @Param snapshotName path string true "快照名称"
+// @Param username query string false "文件系统所属用户"
+// @Success 200
+// @Failure 403 {object} common.ErrorResponse
+// @Failure 404 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Router /fs/{fsName}/snapshots/{snapshotName} [delete]
+func (pr *PFSRouter) deleteFsSnapshot(w http.ResponseWriter, r *http.Request) {
+	ctx := common.GetRequestContext(r)
+	err := api.GetFileSystemService().DeleteFsSnapshot(&ctx, ownerFsID(&ctx, r), chi.URLParam(r, util.QuerySnapshot))
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	common.RenderStatus(w, http.StatusOK)
+}
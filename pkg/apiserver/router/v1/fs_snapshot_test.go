@@ -0,0 +1,142 @@
+/*
+Copyright (c) 2021 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	api "github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/controller/fs"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	fuse "github.com/PaddlePaddle/PaddleFlow/pkg/fs/client/fs"
+	fsCommon "github.com/PaddlePaddle/PaddleFlow/pkg/fs/common"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
+)
+
+func TestFsSnapshot(t *testing.T) {
+	router, baseUrl := prepareDBAndAPI(t)
+	mockDir := "./mock_fs_snapshot"
+	os.RemoveAll(mockDir)
+	assert.NoError(t, os.MkdirAll(mockDir+"/data", 0755))
+	defer os.RemoveAll(mockDir)
+	assert.NoError(t, ioutil.WriteFile(mockDir+"/data/a.txt", []byte("v1"), 0644))
+
+	fsModel := model.FileSystem{
+		Model:    model.Model{ID: mockFsID},
+		Name:     mockFsName,
+		UserName: MockRootUser,
+		Type:     fsCommon.LocalType,
+		SubPath:  mockDir,
+	}
+	assert.NoError(t, storage.Filesystem.CreatFileSystem(&fsModel))
+	snapshotsUrl := baseUrl + "/fs/" + mockFsName + "/snapshots"
+
+	// invalid name and unsupported fs type
+	result, err := PerformPostRequest(router, snapshotsUrl, api.CreateFsSnapshotRequest{Name: "V_1"})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, result.Code)
+
+	result, err = PerformPostRequest(router, snapshotsUrl, api.CreateFsSnapshotRequest{Name: "v1"})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, result.Code, result.Body.String())
+	snapshot := api.FsSnapshotResponse{}
+	assert.NoError(t, ParseBody(result.Body, &snapshot))
+	assert.Equal(t, schema.FsSnapshotID(mockFsID, "v1"), snapshot.ID)
+	assert.Equal(t, mockFsName, snapshot.FsName)
+	assert.Equal(t, "copy", snapshot.Mode)
+
+	assert.Eventually(t, func() bool {
+		result, err = PerformGetRequest(router, snapshotsUrl+"/v1")
+		snapshot = api.FsSnapshotResponse{}
+		return err == nil && ParseBody(result.Body, &snapshot) == nil && snapshot.Status != model.FsSnapshotStatusCreating
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, model.FsSnapshotStatusReady, snapshot.Status, snapshot.Message)
+	assert.Equal(t, int64(1), snapshot.FileCount)
+	assert.Equal(t, int64(2), snapshot.Size)
+
+	result, err = PerformPostRequest(router, snapshotsUrl, api.CreateFsSnapshotRequest{Name: "v1"})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, result.Code)
+
+	result, err = PerformGetRequest(router, snapshotsUrl)
+	assert.NoError(t, err)
+	list := api.ListFsSnapshotResponse{}
+	assert.NoError(t, ParseBody(result.Body, &list))
+	assert.Equal(t, 1, len(list.SnapshotList))
+
+	// the snapshot is read-only and not changed by writes to fs
+	assert.NoError(t, ioutil.WriteFile(mockDir+"/data/a.txt", []byte("v2-changed"), 0644))
+	snapshotFs, err := storage.Filesystem.GetFileSystemOrSnapshot(snapshot.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, schema.FsSnapshotName(mockFsName, "v1"), snapshotFs.Name)
+	newFsClient := api.NewFsClient
+	defer func() { api.NewFsClient = newFsClient }()
+	api.NewFsClient = func(fsID string) (fuse.FSClient, error) {
+		assert.Equal(t, snapshot.ID, fsID)
+		return fuse.NewFSClientForTest(fsCommon.FSMeta{
+			UfsType:    fsCommon.LocalType,
+			SubPath:    mockDir,
+			Properties: snapshotFs.PropertiesMap,
+		})
+	}
+	filesUrl := baseUrl + "/fs/" + schema.FsSnapshotName(mockFsName, "v1") + "/files"
+	result, err = PerformGetRequest(router, filesUrl+"/stat?path=data/a.txt")
+	assert.NoError(t, err)
+	info := api.FileInfo{}
+	assert.NoError(t, ParseBody(result.Body, &info))
+	assert.Equal(t, int64(2), info.Size)
+	result = performUploadRequest(router, filesUrl+"/upload?path=data", "b.txt", []byte("new"))
+	assert.NotEqual(t, http.StatusCreated, result.Code)
+
+	// delete
+	result, err = PerformDeleteRequest(router, snapshotsUrl+"/v1")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, result.Code, result.Body.String())
+	_, err = os.Stat(mockDir + "/.snapshot/v1")
+	assert.True(t, os.IsNotExist(err))
+	result, err = PerformGetRequest(router, snapshotsUrl+"/v1")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, result.Code)
+
+	// the snapshot being created can not be deleted unless it is stale
+	creating := &model.FsSnapshot{
+		Model:  model.Model{ID: schema.FsSnapshotID(mockFsID, "v2")},
+		Name:   "v2",
+		FsID:   mockFsID,
+		Status: model.FsSnapshotStatusCreating,
+	}
+	assert.NoError(t, storage.Filesystem.CreateFsSnapshot(creating))
+	result, err = PerformDeleteRequest(router, snapshotsUrl+"/v2")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, result.Code)
+	stale := &model.FsSnapshot{
+		Model:  model.Model{ID: schema.FsSnapshotID(mockFsID, "v3"), UpdatedAt: time.Now().Add(-time.Hour)},
+		Name:   "v3",
+		FsID:   mockFsID,
+		Status: model.FsSnapshotStatusCreating,
+	}
+	assert.NoError(t, storage.Filesystem.CreateFsSnapshot(stale))
+	result, err = PerformDeleteRequest(router, snapshotsUrl+"/v3")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, result.Code, result.Body.String())
+}
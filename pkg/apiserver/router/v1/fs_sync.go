@@ -0,0 +1,148 @@
+/*
+Copyright (c) 2021 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
+	api "github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/controller/fs"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/router/util"
+)
+
+// fsNameSync is reserved for the sync task api under /fs
+const fsNameSync = "sync"
+
+// createFsSyncTask the function that handle the create fs sync task request
+// @Summary createFsSyncTask
+// @Description 创建数据同步任务，在文件系统间拷贝或同步数据，任务在后台执行并定期保存进度，可从断点恢复
+// @tag fs
+// @Accept   json
+// @Produce  json
+// @Param request body fs.CreateFsSyncTaskRequest true "request body"
+// @Success 201 {object} fs.FsSyncTaskResponse
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 403 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Router /fs/sync [post]
+func (pr *PFSRouter) createFsSyncTask(w http.ResponseWriter, r *http.Request) {
+	ctx := common.GetRequestContext(r)
+	var createRequest api.CreateFsSyncTaskRequest
+	if err := common.BindJSON(r, &createRequest); err != nil {
+		ctx.ErrorCode = common.MalformedJSON
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	task, err := api.GetFileSystemService().CreateFsSyncTask(&ctx, &createRequest)
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	common.Render(w, http.StatusCreated, api.FsSyncTaskResponseFromModel(*task))
+}
+
+// listFsSyncTask the function that handle the list fs sync task request
+// @Summary listFsSyncTask
+// @Description 列出当前用户的数据同步任务，管理员可列出所有任务
+// @tag fs
+// @Accept   json
+// @Produce  json
+// @Param status query string false "任务状态，多个以逗号分隔"
+// @Success 200 {object} fs.ListFsSyncTaskResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Router /fs/sync [get]
+func (pr *PFSRouter) listFsSyncTask(w http.ResponseWriter, r *http.Request) {
+	ctx := common.GetRequestContext(r)
+	tasks, err := api.GetFileSystemService().ListFsSyncTask(&ctx, r.URL.Query().Get(util.QueryKeyStatus))
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	response := api.ListFsSyncTaskResponse{TaskList: make([]*api.FsSyncTaskResponse, 0, len(tasks))}
+	for _, task := range tasks {
+		response.TaskList = append(response.TaskList, api.FsSyncTaskResponseFromModel(task))
+	}
+	common.Render(w, http.StatusOK, response)
+}
+
+// getFsSyncTask the function that handle the get fs sync task request
+// @Summary getFsSyncTask
+// @Description 获取数据同步任务的状态和进度，dryRun任务返回差异列表
+// @tag fs
+// @Accept   json
+// @Produce  json
+// @Param taskID path string true "任务ID"
+// @Success 200 {object} fs.FsSyncTaskResponse
+// @Failure 404 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Router /fs/sync/{taskID} [get]
+func (pr *PFSRouter) getFsSyncTask(w http.ResponseWriter, r *http.Request) {
+	ctx := common.GetRequestContext(r)
+	task, err := api.GetFileSystemService().GetFsSyncTask(&ctx, chi.URLParam(r, util.QuerySyncTaskID))
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	common.Render(w, http.StatusOK, api.FsSyncTaskResponseFromModel(task))
+}
+
+// updateFsSyncTask the function that handle the stop or resume fs sync task request
+// @Summary updateFsSyncTask
+// @Description 停止运行中的数据同步任务，或从断点继续执行已结束的任务
+// @tag fs
+// @Accept   json
+// @Produce  json
+// @Param taskID path string true "任务ID"
+// @Param action query string true "stop或resume"
+// @Success 200 {object} fs.FsSyncTaskResponse
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 404 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Router /fs/sync/{taskID} [put]
+func (pr *PFSRouter) updateFsSyncTask(w http.ResponseWriter, r *http.Request) {
+	ctx := common.GetRequestContext(r)
+	task, err := api.GetFileSystemService().UpdateFsSyncTask(&ctx, chi.URLParam(r, util.QuerySyncTaskID),
+		r.URL.Query().Get(util.QueryKeyAction))
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	common.Render(w, http.StatusOK, api.FsSyncTaskResponseFromModel(task))
+}
+
+// deleteFsSyncTask the function that handle the delete fs sync task request
+// @Summary deleteFsSyncTask
+// @Description 删除未运行的数据同步任务记录，已传输的数据不受影响
+// @tag fs
+// @Accept   json
+// @Produce  json
+// @Param taskID path string true "任务ID"
+// @Success 200
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 404 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Router /fs/sync/{taskID} [delete]
+func (pr *PFSRouter) deleteFsSyncTask(w http.ResponseWriter, r *http.Request) {
+	ctx := common.GetRequestContext(r)
+	if err := api.GetFileSystemService().DeleteFsSyncTask(&ctx, chi.URLParam(r, util.QuerySyncTaskID)); err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	common.RenderStatus(w, http.StatusOK)
+}
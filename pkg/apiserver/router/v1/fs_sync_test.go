@@ -0,0 +1,182 @@
+/*
+Copyright (c) 2021 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	api "github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/controller/fs"
+	fuse "github.com/PaddlePaddle/PaddleFlow/pkg/fs/client/fs"
+	fsCommon "github.com/PaddlePaddle/PaddleFlow/pkg/fs/common"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/transfer"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
+)
+
+func TestFsSyncTask(t *testing.T) {
+	router, baseUrl := prepareDBAndAPI(t)
+	mockDir, err := filepath.Abs("./mock_fs_sync")
+	assert.NoError(t, err)
+	os.RemoveAll(mockDir)
+	defer os.RemoveAll(mockDir)
+	dirs := map[string]string{
+		mockFsID:        filepath.Join(mockDir, "src"),
+		"fs-root-dstfs": filepath.Join(mockDir, "dst"),
+	}
+	assert.NoError(t, os.MkdirAll(dirs[mockFsID]+"/data/d", 0755))
+	assert.NoError(t, os.MkdirAll(dirs["fs-root-dstfs"]+"/backup", 0755))
+	assert.NoError(t, ioutil.WriteFile(dirs[mockFsID]+"/data/a.txt", []byte("0123456789"), 0644))
+	assert.NoError(t, ioutil.WriteFile(dirs[mockFsID]+"/data/d/b.txt", []byte("abc"), 0644))
+	assert.NoError(t, ioutil.WriteFile(dirs["fs-root-dstfs"]+"/backup/extra.txt", []byte("x"), 0644))
+
+	for fsID, name := range map[string]string{mockFsID: mockFsName, "fs-root-dstfs": "dstfs"} {
+		fsModel := model.FileSystem{
+			Model:         model.Model{ID: fsID},
+			Name:          name,
+			UserName:      MockRootUser,
+			Type:          fsCommon.LocalType,
+			SubPath:       dirs[fsID],
+			PropertiesMap: map[string]string{fsCommon.RootKey: dirs[fsID]},
+		}
+		assert.NoError(t, storage.Filesystem.CreatFileSystem(&fsModel))
+	}
+	newFsClient := api.NewFsClient
+	defer func() { api.NewFsClient = newFsClient }()
+	api.NewFsClient = func(fsID string) (fuse.FSClient, error) {
+		return fuse.NewFSClientForTest(fsCommon.FSMeta{
+			UfsType:    fsCommon.LocalType,
+			SubPath:    dirs[fsID],
+			Properties: map[string]string{fsCommon.RootKey: dirs[fsID]},
+		})
+	}
+	syncUrl := baseUrl + "/fs/sync"
+	waitTask := func(id string) api.FsSyncTaskResponse {
+		task := api.FsSyncTaskResponse{}
+		for i := 0; i < 100; i++ {
+			result, err := PerformGetRequest(router, syncUrl+"/"+id)
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, result.Code, result.Body.String())
+			assert.NoError(t, ParseBody(result.Body, &task))
+			if task.Status != model.FsSyncTaskStatusPending && task.Status != model.FsSyncTaskStatusRunning {
+				break
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		return task
+	}
+
+	request := api.CreateFsSyncTaskRequest{
+		SrcFsName: mockFsName,
+		SrcPath:   "data",
+		DstFsName: "dstfs",
+		DstPath:   "backup",
+		Mode:      "move",
+	}
+	result, err := PerformPostRequest(router, syncUrl, request)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, result.Code)
+	request.Mode, request.DstFsName = transfer.ModeSync, mockFsName+"@snap"
+	result, err = PerformPostRequest(router, syncUrl, request)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, result.Code)
+	request.DstFsName, request.DstPath = mockFsName, "data/d"
+	result, err = PerformPostRequest(router, syncUrl, request)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, result.Code)
+
+	// dry run only records the differences
+	request.DstFsName, request.DstPath, request.DryRun = "dstfs", "backup", true
+	result, err = PerformPostRequest(router, syncUrl, request)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, result.Code, result.Body.String())
+	task := api.FsSyncTaskResponse{}
+	assert.NoError(t, ParseBody(result.Body, &task))
+	assert.Equal(t, "/data", task.SrcPath)
+	task = waitTask(task.ID)
+	assert.Equal(t, model.FsSyncTaskStatusSucceeded, task.Status, task.Message)
+	assert.ElementsMatch(t, []string{"+ a.txt", "+ d/", "+ d/b.txt", "- extra.txt"}, task.Diff)
+	_, err = os.Stat(dirs["fs-root-dstfs"] + "/backup/a.txt")
+	assert.True(t, os.IsNotExist(err))
+
+	request.DryRun = false
+	result, err = PerformPostRequest(router, syncUrl, request)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, result.Code, result.Body.String())
+	assert.NoError(t, ParseBody(result.Body, &task))
+	task = waitTask(task.ID)
+	assert.Equal(t, model.FsSyncTaskStatusSucceeded, task.Status, task.Message)
+	assert.Equal(t, int64(2), task.Copied)
+	assert.Equal(t, int64(13), task.CopiedBytes)
+	assert.Equal(t, int64(1), task.Deleted)
+	content, err := ioutil.ReadFile(dirs["fs-root-dstfs"] + "/backup/d/b.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "abc", string(content))
+	_, err = os.Stat(dirs["fs-root-dstfs"] + "/backup/extra.txt")
+	assert.True(t, os.IsNotExist(err))
+
+	// finished task can not be stopped, but can run again
+	result, err = PerformPutRequest(router, syncUrl+"/"+task.ID+"?action=stop", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, result.Code)
+	result, err = PerformPutRequest(router, syncUrl+"/"+task.ID+"?action=resume", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, result.Code, result.Body.String())
+	task = waitTask(task.ID)
+	assert.Equal(t, model.FsSyncTaskStatusSucceeded, task.Status, task.Message)
+	assert.Equal(t, int64(2), task.Skipped)
+	assert.Equal(t, int64(0), task.Copied)
+
+	result, err = PerformGetRequest(router, syncUrl+"?status=succeeded")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, result.Code)
+	list := api.ListFsSyncTaskResponse{}
+	assert.NoError(t, ParseBody(result.Body, &list))
+	assert.Equal(t, 2, len(list.TaskList))
+	assert.Equal(t, "dstfs", list.TaskList[0].DstFsName)
+
+	// unfinished task is claimed by only one server after it is not updated for a while
+	running := model.FsSyncTask{Model: model.Model{ID: "fssync-running"}, UserName: MockRootUser,
+		Status: model.FsSyncTaskStatusRunning}
+	assert.NoError(t, storage.Filesystem.CreateFsSyncTask(&running))
+	staleBefore := time.Now().Add(-time.Minute)
+	claimed, err := storage.Filesystem.ClaimFsSyncTask(running.ID, staleBefore)
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+	assert.NoError(t, storage.DB.Model(&model.FsSyncTask{}).Where("id = ?", running.ID).
+		UpdateColumn("updated_at", staleBefore.Add(-time.Minute)).Error)
+	claimed, err = storage.Filesystem.ClaimFsSyncTask(running.ID, staleBefore)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+	claimed, err = storage.Filesystem.ClaimFsSyncTask(running.ID, staleBefore)
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+	assert.NoError(t, storage.Filesystem.DeleteFsSyncTask(running.ID))
+
+	result, err = PerformDeleteRequest(router, syncUrl+"/"+task.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, result.Code)
+	result, err = PerformGetRequest(router, syncUrl+"/"+task.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, result.Code)
+}
@@ -60,6 +60,9 @@ func (jr *JobRouter) AddRouter(r chi.Router) {
 	r.Post("/job/single", jr.CreateSingleJob)
 	r.Post("/job/distributed", jr.CreateDistributedJob)
 	r.Post("/job/workflow", jr.CreateWorkflowJob)
+	r.Post("/job/notebook", jr.CreateNotebookJob)
+	r.Post("/job/serving", jr.CreateServingJob)
+	r.Post("/job", jr.CreateJobFromTemplate)
 
 	r.Delete("/job/{jobID}", jr.DeleteJob)
 	r.Put("/job/{jobID}", func(w http.ResponseWriter, r *http.Request) {
@@ -87,11 +90,18 @@ func (jr *JobRouter) AddRouter(r chi.Router) {
 // @tags Job
 // @Accept  json
 // @Produce json
+// @Param dryRun query bool false "为true时只校验并返回渲染后的集群对象，不创建作业"
 // @Success 200 {object} job.CreateJobResponse "创建single类型作业的响应"
 // @Failure 400 {object} common.ErrorResponse "400"
 // @Router /job/single [POST]
 func (jr *JobRouter) CreateSingleJob(w http.ResponseWriter, r *http.Request) {
 	ctx := common.GetRequestContext(r)
+	dryRun, err := getDryRun(r)
+	if err != nil {
+		ctx.ErrorCode = common.InvalidURI
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
 
 	var request job.CreateSingleJobRequest
 	if err := common.BindJSON(r, &request); err != nil {
@@ -104,6 +114,108 @@ func (jr *JobRouter) CreateSingleJob(w http.ResponseWriter, r *http.Request) {
 
 	request.CommonJobInfo.UserName = ctx.UserName
 
+	if dryRun {
+		renderDryRunJob(w, &ctx, func() (*job.DryRunJobResponse, error) {
+			return job.DryRunPFJob(&ctx, request.ToJobInfo())
+		})
+		return
+	}
+	response, err := job.CreatePFJob(&ctx, request.ToJobInfo())
+	if err != nil {
+		ctx.ErrorCode = common.JobCreateFailed
+		ctx.Logging().Errorf("create job failed. job request:%v error:%s", request, err.Error())
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	ctx.Logging().Debugf("CreateJob job:%v", string(config.PrettyFormat(response)))
+	common.Render(w, http.StatusOK, response)
+}
+
+// CreateNotebookJob create notebook job
+// @Summary 创建notebook类型作业
+// @Description 创建notebook类型作业，启动jupyter或vscode交互式开发环境，通过service和可选的ingress暴露，空闲超时后自动停止
+// @Id createNotebookJob
+// @tags Job
+// @Accept  json
+// @Produce json
+// @Param dryRun query bool false "为true时只校验并返回渲染后的集群对象，不创建作业"
+// @Success 200 {object} job.CreateJobResponse "创建notebook类型作业的响应"
+// @Failure 400 {object} common.ErrorResponse "400"
+// @Router /job/notebook [POST]
+func (jr *JobRouter) CreateNotebookJob(w http.ResponseWriter, r *http.Request) {
+	ctx := common.GetRequestContext(r)
+	dryRun, err := getDryRun(r)
+	if err != nil {
+		ctx.ErrorCode = common.InvalidURI
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+
+	var request job.CreateNotebookJobRequest
+	if err := common.BindJSON(r, &request); err != nil {
+		ctx.ErrorCode = common.MalformedJSON
+		logger.LoggerForRequest(&ctx).Errorf("parsing request body failed:%+v. error:%s", r.Body, err.Error())
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	log.Debugf("create notebook job request:%#v", request)
+
+	request.CommonJobInfo.UserName = ctx.UserName
+
+	if dryRun {
+		renderDryRunJob(w, &ctx, func() (*job.DryRunJobResponse, error) {
+			return job.DryRunPFJob(&ctx, request.ToJobInfo())
+		})
+		return
+	}
+	response, err := job.CreatePFJob(&ctx, request.ToJobInfo())
+	if err != nil {
+		ctx.ErrorCode = common.JobCreateFailed
+		ctx.Logging().Errorf("create job failed. job request:%v error:%s", request, err.Error())
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	ctx.Logging().Debugf("CreateJob job:%v", string(config.PrettyFormat(response)))
+	common.Render(w, http.StatusOK, response)
+}
+
+// CreateServingJob create serving job
+// @Summary 创建serving类型作业
+// @Description 创建serving类型作业，以deployment或kserve方式长期运行模型服务，通过service暴露，支持自动扩缩容和灰度发布
+// @Id createServingJob
+// @tags Job
+// @Accept  json
+// @Produce json
+// @Param dryRun query bool false "为true时只校验并返回渲染后的集群对象，不创建作业"
+// @Success 200 {object} job.CreateJobResponse "创建serving类型作业的响应"
+// @Failure 400 {object} common.ErrorResponse "400"
+// @Router /job/serving [POST]
+func (jr *JobRouter) CreateServingJob(w http.ResponseWriter, r *http.Request) {
+	ctx := common.GetRequestContext(r)
+	dryRun, err := getDryRun(r)
+	if err != nil {
+		ctx.ErrorCode = common.InvalidURI
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+
+	var request job.CreateServingJobRequest
+	if err := common.BindJSON(r, &request); err != nil {
+		ctx.ErrorCode = common.MalformedJSON
+		logger.LoggerForRequest(&ctx).Errorf("parsing request body failed:%+v. error:%s", r.Body, err.Error())
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	log.Debugf("create serving job request:%#v", request)
+
+	request.CommonJobInfo.UserName = ctx.UserName
+
+	if dryRun {
+		renderDryRunJob(w, &ctx, func() (*job.DryRunJobResponse, error) {
+			return job.DryRunPFJob(&ctx, request.ToJobInfo())
+		})
+		return
+	}
 	response, err := job.CreatePFJob(&ctx, request.ToJobInfo())
 	if err != nil {
 		ctx.ErrorCode = common.JobCreateFailed
@@ -122,11 +234,18 @@ func (jr *JobRouter) CreateSingleJob(w http.ResponseWriter, r *http.Request) {
 // @tags Job
 // @Accept  json
 // @Produce json
+// @Param dryRun query bool false "为true时只校验并返回渲染后的集群对象，不创建作业"
 // @Success 200 {object} job.CreateJobResponse "创建distributed类型作业的响应"
 // @Failure 400 {object} common.ErrorResponse "400"
 // @Router /job/distributed [POST]
 func (jr *JobRouter) CreateDistributedJob(w http.ResponseWriter, r *http.Request) {
 	ctx := common.GetRequestContext(r)
+	dryRun, err := getDryRun(r)
+	if err != nil {
+		ctx.ErrorCode = common.InvalidURI
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
 
 	var request job.CreateDisJobRequest
 	if err := common.BindJSON(r, &request); err != nil {
@@ -137,6 +256,12 @@ func (jr *JobRouter) CreateDistributedJob(w http.ResponseWriter, r *http.Request
 	}
 	log.Debugf("create distributed job request:%+v", request)
 
+	if dryRun {
+		renderDryRunJob(w, &ctx, func() (*job.DryRunJobResponse, error) {
+			return job.DryRunPFJob(&ctx, request.ToJobInfo())
+		})
+		return
+	}
 	response, err := job.CreatePFJob(&ctx, request.ToJobInfo())
 	if err != nil {
 		ctx.ErrorCode = common.JobCreateFailed
@@ -155,11 +280,18 @@ func (jr *JobRouter) CreateDistributedJob(w http.ResponseWriter, r *http.Request
 // @tags Job
 // @Accept  json
 // @Produce json
+// @Param dryRun query bool false "为true时只校验并返回渲染后的集群对象，不创建作业"
 // @Success 200 {object} job.CreateJobResponse "创建Workflow类型作业的响应"
 // @Failure 400 {object} common.ErrorResponse "400"
 // @Router /job/workflow [POST]
 func (jr *JobRouter) CreateWorkflowJob(w http.ResponseWriter, r *http.Request) {
 	ctx := common.GetRequestContext(r)
+	dryRun, err := getDryRun(r)
+	if err != nil {
+		ctx.ErrorCode = common.InvalidURI
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
 
 	var request job.CreateWfJobRequest
 	if err := common.BindJSON(r, &request); err != nil {
@@ -171,6 +303,12 @@ func (jr *JobRouter) CreateWorkflowJob(w http.ResponseWriter, r *http.Request) {
 	request.CommonJobInfo.UserName = ctx.UserName
 	log.Debugf("create workflow job request:%+v", request)
 
+	if dryRun {
+		renderDryRunJob(w, &ctx, func() (*job.DryRunJobResponse, error) {
+			return job.DryRunWorkflowJob(&ctx, &request)
+		})
+		return
+	}
 	response, err := job.CreateWorkflowJob(&ctx, &request)
 	if err != nil {
 		ctx.ErrorCode = common.JobCreateFailed
@@ -182,6 +320,75 @@ func (jr *JobRouter) CreateWorkflowJob(w http.ResponseWriter, r *http.Request) {
 	common.Render(w, http.StatusOK, response)
 }
 
+func getDryRun(r *http.Request) (bool, error) {
+	dryRun := r.URL.Query().Get(util.QueryKeyDryRun)
+	if dryRun == "" {
+		return false, nil
+	}
+	value, err := strconv.ParseBool(dryRun)
+	if err != nil {
+		return false, fmt.Errorf("invalid query parameter %s: %s", util.QueryKeyDryRun, dryRun)
+	}
+	return value, nil
+}
+
+// renderDryRunJob renders the objects of job in dry run mode, which would be submitted to cluster
+func renderDryRunJob(w http.ResponseWriter, ctx *logger.RequestContext, dryRunFunc func() (*job.DryRunJobResponse, error)) {
+	response, err := dryRunFunc()
+	if err != nil {
+		if ctx.ErrorCode == "" {
+			ctx.ErrorCode = common.JobCreateFailed
+		}
+		ctx.Logging().Errorf("dry run job failed. error:%s", err.Error())
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	common.Render(w, http.StatusOK, response)
+}
+
+// CreateJobFromTemplate create job by job template
+// @Summary 通过作业模板创建作业
+// @Description 通过作业模板创建作业，请求中的参数覆盖模板参数的默认值
+// @Id createJobFromTemplate
+// @tags Job
+// @Accept  json
+// @Produce json
+// @Param template query string true "作业模板名称或ID"
+// @Param request body job.CreateJobFromTemplateRequest true "模板参数"
+// @Success 200 {object} job.CreateJobResponse "创建作业的响应"
+// @Failure 400 {object} common.ErrorResponse "400"
+// @Router /job [POST]
+func (jr *JobRouter) CreateJobFromTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := common.GetRequestContext(r)
+	template := r.URL.Query().Get(util.QueryKeyTemplate)
+	if template == "" {
+		ctx.ErrorCode = common.InvalidURI
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, "query parameter template is required")
+		return
+	}
+
+	var request job.CreateJobFromTemplateRequest
+	if err := common.BindJSON(r, &request); err != nil {
+		ctx.ErrorCode = common.MalformedJSON
+		logger.LoggerForRequest(&ctx).Errorf("parsing request body failed:%+v. error:%s", r.Body, err.Error())
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	log.Debugf("create job by template %s with request:%+v", template, request)
+
+	response, err := job.CreateJobFromTemplate(&ctx, template, &request)
+	if err != nil {
+		if ctx.ErrorCode == "" {
+			ctx.ErrorCode = common.JobCreateFailed
+		}
+		ctx.Logging().Errorf("create job by template %s failed. error:%s", template, err.Error())
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	ctx.Logging().Debugf("CreateJob job:%v", string(config.PrettyFormat(response)))
+	common.Render(w, http.StatusOK, response)
+}
+
 // DeleteJob delete job
 // @Summary 删除作业
 // @Description 删除作业
@@ -247,7 +454,7 @@ func (jr *JobRouter) StopJob(w http.ResponseWriter, r *http.Request) {
 
 // UpdateJob update job
 // @Summary 更新作业
-// @Description 更新作业
+// @Description 更新作业，支持修改优先级、标签、注解，以及弹性作业成员的副本数
 // @Id UpdateJob
 // @tags Job
 // @Accept  json
@@ -0,0 +1,229 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/controller/job"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/router/util"
+)
+
+// JobTemplateRouter is job template api router
+type JobTemplateRouter struct{}
+
+func (tr *JobTemplateRouter) Name() string {
+	return "JobTemplateRouter"
+}
+
+func (tr *JobTemplateRouter) AddRouter(r chi.Router) {
+	log.Info("add job template router")
+	r.Post("/jobTemplate", tr.createJobTemplate)
+	r.Get("/jobTemplate", tr.listJobTemplate)
+	r.Put("/jobTemplate/{templateID}", tr.updateJobTemplate)
+	r.Get("/jobTemplate/{templateID}", tr.getJobTemplate)
+	r.Delete("/jobTemplate/{templateID}", tr.deleteJobTemplate)
+	r.Get("/jobTemplate/{templateID}/{templateVersionID}", tr.getJobTemplateVersion)
+	r.Delete("/jobTemplate/{templateID}/{templateVersionID}", tr.deleteJobTemplateVersion)
+}
+
+// createJobTemplate
+// @Summary 创建作业模板
+// @Description 创建作业模板
+// @Id createJobTemplate
+// @tags JobTemplate
+// @Accept  json
+// @Produce json
+// @Param request body job.CreateJobTemplateRequest true "创建作业模板请求"
+// @Success 201 {object} job.CreateJobTemplateResponse "创建作业模板响应"
+// @Failure 400 {object} common.ErrorResponse "400"
+// @Router /jobTemplate [POST]
+func (tr *JobTemplateRouter) createJobTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := common.GetRequestContext(r)
+	var request job.CreateJobTemplateRequest
+	if err := common.BindJSON(r, &request); err != nil {
+		ctx.Logging().Errorf("create job template failed parsing request body:%+v. error:%v", r.Body, err)
+		common.RenderErrWithMessage(w, ctx.RequestID, common.MalformedJSON, err.Error())
+		return
+	}
+	response, err := job.CreateJobTemplate(&ctx, &request)
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	common.Render(w, http.StatusCreated, response)
+}
+
+// listJobTemplate
+// @Summary 获取作业模板列表
+// @Description 获取作业模板列表
+// @Id listJobTemplate
+// @tags JobTemplate
+// @Accept  json
+// @Produce json
+// @Param userFilter query string false "(root用户)username过滤"
+// @Param nameFilter query string false "作业模板名称过滤"
+// @Param maxKeys query int false "每页包含的最大数量，缺省值为50"
+// @Param marker query string false "批量获取列表的查询的起始位置，是一个由系统生成的字符串"
+// @Success 200 {object} job.ListJobTemplateResponse "获取作业模板列表的响应"
+// @Failure 400 {object} common.ErrorResponse "400"
+// @Router /jobTemplate [GET]
+func (tr *JobTemplateRouter) listJobTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := common.GetRequestContext(r)
+	marker := r.URL.Query().Get(util.QueryKeyMarker)
+	maxKeys, err := util.GetQueryMaxKeys(&ctx, r)
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, common.InvalidURI, err.Error())
+		return
+	}
+	userFilter, nameFilter := make([]string, 0), make([]string, 0)
+	if userNames := r.URL.Query().Get(util.QueryKeyUserFilter); userNames != "" {
+		userFilter = util.SplitFilter(userNames, common.SeparatorComma, true)
+	}
+	if names := r.URL.Query().Get(util.QueryKeyNameFilter); names != "" {
+		nameFilter = util.SplitFilter(names, common.SeparatorComma, true)
+	}
+	response, err := job.ListJobTemplate(&ctx, marker, maxKeys, userFilter, nameFilter)
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	common.Render(w, http.StatusOK, response)
+}
+
+// updateJobTemplate
+// @Summary 更新作业模板，生成新的版本
+// @Description 更新作业模板，生成新的版本
+// @Id updateJobTemplate
+// @tags JobTemplate
+// @Accept  json
+// @Produce json
+// @Param templateID path string true "作业模板ID"
+// @Param request body job.UpdateJobTemplateRequest true "更新作业模板请求"
+// @Success 201 {object} job.UpdateJobTemplateResponse "更新作业模板响应"
+// @Failure 400 {object} common.ErrorResponse "400"
+// @Router /jobTemplate/{templateID} [PUT]
+func (tr *JobTemplateRouter) updateJobTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := common.GetRequestContext(r)
+	templateID := chi.URLParam(r, util.ParamKeyTemplateID)
+	var request job.UpdateJobTemplateRequest
+	if err := common.BindJSON(r, &request); err != nil {
+		ctx.Logging().Errorf("update job template failed parsing request body:%+v. error:%v", r.Body, err)
+		common.RenderErrWithMessage(w, ctx.RequestID, common.MalformedJSON, err.Error())
+		return
+	}
+	response, err := job.UpdateJobTemplate(&ctx, templateID, &request)
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	common.Render(w, http.StatusCreated, response)
+}
+
+// getJobTemplate
+// @Summary 获取作业模板及其版本
+// @Description 获取作业模板及其版本
+// @Id getJobTemplate
+// @tags JobTemplate
+// @Accept  json
+// @Produce json
+// @Param templateID path string true "作业模板ID"
+// @Success 200 {object} job.GetJobTemplateResponse "作业模板详情"
+// @Failure 400 {object} common.ErrorResponse "400"
+// @Router /jobTemplate/{templateID} [GET]
+func (tr *JobTemplateRouter) getJobTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := common.GetRequestContext(r)
+	templateID := chi.URLParam(r, util.ParamKeyTemplateID)
+	response, err := job.GetJobTemplate(&ctx, templateID)
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	common.Render(w, http.StatusOK, response)
+}
+
+// deleteJobTemplate
+// @Summary 删除作业模板及其所有版本
+// @Description 删除作业模板及其所有版本
+// @Id deleteJobTemplate
+// @tags JobTemplate
+// @Accept  json
+// @Produce json
+// @Param templateID path string true "作业模板ID"
+// @Success 200
+// @Failure 400 {object} common.ErrorResponse "400"
+// @Router /jobTemplate/{templateID} [DELETE]
+func (tr *JobTemplateRouter) deleteJobTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := common.GetRequestContext(r)
+	templateID := chi.URLParam(r, util.ParamKeyTemplateID)
+	if err := job.DeleteJobTemplate(&ctx, templateID); err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	common.RenderStatus(w, http.StatusOK)
+}
+
+// getJobTemplateVersion
+// @Summary 获取作业模板版本
+// @Description 获取作业模板版本
+// @Id getJobTemplateVersion
+// @tags JobTemplate
+// @Accept  json
+// @Produce json
+// @Param templateID path string true "作业模板ID"
+// @Param templateVersionID path string true "作业模板版本ID"
+// @Success 200 {object} job.GetJobTemplateVersionResponse "作业模板版本详情"
+// @Failure 400 {object} common.ErrorResponse "400"
+// @Router /jobTemplate/{templateID}/{templateVersionID} [GET]
+func (tr *JobTemplateRouter) getJobTemplateVersion(w http.ResponseWriter, r *http.Request) {
+	ctx := common.GetRequestContext(r)
+	templateID := chi.URLParam(r, util.ParamKeyTemplateID)
+	versionID := chi.URLParam(r, util.ParamKeyTemplateVersionID)
+	response, err := job.GetJobTemplateVersion(&ctx, templateID, versionID)
+	if err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	common.Render(w, http.StatusOK, response)
+}
+
+// deleteJobTemplateVersion
+// @Summary 删除作业模板版本
+// @Description 删除作业模板版本，最后一个版本不能删除
+// @Id deleteJobTemplateVersion
+// @tags JobTemplate
+// @Accept  json
+// @Produce json
+// @Param templateID path string true "作业模板ID"
+// @Param templateVersionID path string true "作业模板版本ID"
+// @Success 200
+// @Failure 400 {object} common.ErrorResponse "400"
+// @Router /jobTemplate/{templateID}/{templateVersionID} [DELETE]
+func (tr *JobTemplateRouter) deleteJobTemplateVersion(w http.ResponseWriter, r *http.Request) {
+	ctx := common.GetRequestContext(r)
+	templateID := chi.URLParam(r, util.ParamKeyTemplateID)
+	versionID := chi.URLParam(r, util.ParamKeyTemplateVersionID)
+	if err := job.DeleteJobTemplateVersion(&ctx, templateID, versionID); err != nil {
+		common.RenderErrWithMessage(w, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	common.RenderStatus(w, http.StatusOK)
+}
@@ -239,6 +239,7 @@ func checkFsPathIsExist(ctx *logger.RequestContext, fsMeta fsCommon.FSMeta, fsPa
 		ctx.ErrorCode = common.FuseClientError
 		return err
 	}
+	defer client.Close()
 	isDir, err := client.IsDir(filepath.Dir(fsPath))
 	if err != nil {
 		ctx.Logging().Errorf("fuse client path[%s] exist err[%v]", fsPath, err)
@@ -22,6 +22,7 @@ import (
 
 	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/middleware"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/router/util"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/config"
 )
 
 type IRouter interface {
@@ -45,6 +46,7 @@ type IRouter interface {
 
 func RegisterRouters(r *chi.Mux, debugMode bool) {
 	r.Use(middleware.CheckRequestID)
+	r.Use(middleware.Tracing)
 	r.NotFound(middleware.NotFound)
 	r.MethodNotAllowed(middleware.MethodNotAllowed)
 	r.Use(middleware.Recoverer)
@@ -67,9 +69,24 @@ func RegisterRouters(r *chi.Mux, debugMode bool) {
 		AddRouter(apiV1Router, &TrackRouter{})
 		AddRouter(apiV1Router, &LogRouter{})
 		AddRouter(apiV1Router, &JobRouter{})
+		AddRouter(apiV1Router, &JobTemplateRouter{})
 		AddRouter(apiV1Router, &StatisticsRouter{})
 		AddRouter(apiV1Router, &VersionRouter{})
 	})
+	// scheduler extender is called by kube-scheduler or volcano, which can not carry the auth token,
+	// so a shared token is carried in the url path instead, e.g. urlPrefix: /api/paddleflow/v1/scheduler/<token>.
+	// The token is hidden in the traces of requests.
+	if config.GlobalServerConfig != nil && config.GlobalServerConfig.Fs.SchedulerExtender {
+		extenderToken := config.GlobalServerConfig.Fs.SchedulerExtenderToken
+		if extenderToken == "" {
+			logrus.Errorf("scheduler extender is not registered, because schedulerExtenderToken is not set")
+			return
+		}
+		r.Route(pathPrefix+"/scheduler/{"+middleware.TokenURLParam+"}", func(extenderRouter chi.Router) {
+			extenderRouter.Use(middleware.ExtenderTokenAuth(extenderToken))
+			AddRouter(extenderRouter, &SchedulerExtenderRouter{})
+		})
+	}
 }
 
 func AddRouter(r chi.Router, router IRouter) {
@@ -0,0 +1,93 @@
+/*
+Copyright (c) 2021 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
+	locationAwareness "github.com/PaddlePaddle/PaddleFlow/pkg/fs/location-awareness"
+)
+
+// SchedulerExtenderRouter serves kube-scheduler/volcano extender calls, scoring nodes by fs cache locality
+type SchedulerExtenderRouter struct{}
+
+func (sr *SchedulerExtenderRouter) Name() string {
+	return "SchedulerExtender"
+}
+
+func (sr *SchedulerExtenderRouter) AddRouter(r chi.Router) {
+	r.Post("/filter", sr.filter)
+	r.Post("/prioritize", sr.prioritize)
+}
+
+// filter the router of scheduler extender filter
+// @Summary 过滤没有文件系统缓存的节点，仅对required模式的作业生效
+// @Description 过滤没有文件系统缓存的节点，仅对required模式的作业生效
+// @Id filter
+// @tags SchedulerExtender
+// @Accept  json
+// @Produce json
+// @Param request body locationAwareness.ExtenderArgs true "调度器扩展请求"
+// @Success 200 {object} locationAwareness.ExtenderFilterResult "过滤结果"
+// @Failure 400 {object} common.ErrorResponse "400"
+// @Router /scheduler/filter [POST]
+func (sr *SchedulerExtenderRouter) filter(w http.ResponseWriter, r *http.Request) {
+	ctx := common.GetRequestContext(r)
+	var args locationAwareness.ExtenderArgs
+	if err := common.BindJSON(r, &args); err != nil {
+		ctx.Logging().Errorf("scheduler extender filter bindjson failed. err:%s", err.Error())
+		common.RenderErrWithMessage(w, ctx.RequestID, common.MalformedJSON, err.Error())
+		return
+	}
+	result := locationAwareness.Filter(args)
+	if result.Error != "" {
+		ctx.Logging().Errorf("scheduler extender filter failed. err:%s", result.Error)
+	}
+	common.Render(w, http.StatusOK, result)
+}
+
+// prioritize the router of scheduler extender prioritize
+// @Summary 根据文件系统缓存分布为节点打分
+// @Description 根据文件系统缓存分布为节点打分
+// @Id prioritize
+// @tags SchedulerExtender
+// @Accept  json
+// @Produce json
+// @Param request body locationAwareness.ExtenderArgs true "调度器扩展请求"
+// @Success 200 {object} []locationAwareness.HostPriority "节点得分"
+// @Failure 400 {object} common.ErrorResponse "400"
+// @Failure 500 {object} common.ErrorResponse "500"
+// @Router /scheduler/prioritize [POST]
+func (sr *SchedulerExtenderRouter) prioritize(w http.ResponseWriter, r *http.Request) {
+	ctx := common.GetRequestContext(r)
+	var args locationAwareness.ExtenderArgs
+	if err := common.BindJSON(r, &args); err != nil {
+		ctx.Logging().Errorf("scheduler extender prioritize bindjson failed. err:%s", err.Error())
+		common.RenderErrWithMessage(w, ctx.RequestID, common.MalformedJSON, err.Error())
+		return
+	}
+	priorities, err := locationAwareness.Prioritize(args)
+	if err != nil {
+		ctx.Logging().Errorf("scheduler extender prioritize failed. err:%s", err.Error())
+		common.RenderErrWithMessage(w, ctx.RequestID, common.InternalError, err.Error())
+		return
+	}
+	common.Render(w, http.StatusOK, priorities)
+}
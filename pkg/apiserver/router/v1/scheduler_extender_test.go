@@ -0,0 +1,110 @@
+/*
+Copyright (c) 2021 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/middleware"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/router/util"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/config"
+	locationAwareness "github.com/PaddlePaddle/PaddleFlow/pkg/fs/location-awareness"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage/driver"
+)
+
+func TestSchedulerExtenderRouter(t *testing.T) {
+	config.GlobalServerConfig = &config.ServerConfig{
+		Fs: config.FsServerConf{SchedulerExtender: true, SchedulerExtenderToken: "extender-token"},
+	}
+	driver.InitMockDB()
+	recorder := tracetest.NewSpanRecorder()
+	oldProvider := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(oldProvider)
+	router := chi.NewRouter()
+	router.Use(middleware.CheckRequestID)
+	RegisterRouters(router, false)
+	baseUrl := util.PaddleflowRouterPrefix + util.PaddleflowRouterVersionV1 + "/scheduler/extender-token"
+
+	nodeNames := []string{"node1"}
+	args := locationAwareness.ExtenderArgs{Pod: &corev1.Pod{}, NodeNames: &nodeNames}
+	// request with a wrong token is rejected
+	result, err := PerformPostRequest(router,
+		util.PaddleflowRouterPrefix+util.PaddleflowRouterVersionV1+"/scheduler/wrong-token/filter", args)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, result.Code)
+
+	result, err = PerformPostRequest(router, baseUrl+"/prioritize", args)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, result.Code)
+	var priorities []locationAwareness.HostPriority
+	assert.Nil(t, json.Unmarshal(result.Body.Bytes(), &priorities))
+	assert.Equal(t, []locationAwareness.HostPriority{{Host: "node1", Score: 0}}, priorities)
+	// the token is hidden in the trace of request
+	body, err := json.Marshal(args)
+	assert.Nil(t, err)
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, baseUrl+"/prioritize",
+		bytes.NewReader(body)))
+	spans := recorder.Ended()
+	var target string
+	for _, attr := range spans[len(spans)-1].Attributes() {
+		if attr.Key == semconv.HTTPTargetKey {
+			target = attr.Value.AsString()
+		}
+	}
+	assert.Equal(t, util.PaddleflowRouterPrefix+util.PaddleflowRouterVersionV1+"/scheduler/***/prioritize", target)
+
+	result, err = PerformPostRequest(router, baseUrl+"/filter", args)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, result.Code)
+	var filterResult locationAwareness.ExtenderFilterResult
+	assert.Nil(t, json.Unmarshal(result.Body.Bytes(), &filterResult))
+	assert.Equal(t, nodeNames, *filterResult.NodeNames)
+
+	result, err = PerformPostRequest(router, baseUrl+"/filter", "bad request")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, result.Code)
+}
+
+func TestSchedulerExtenderRouterWithoutToken(t *testing.T) {
+	config.GlobalServerConfig = &config.ServerConfig{
+		Fs: config.FsServerConf{SchedulerExtender: true},
+	}
+	driver.InitMockDB()
+	router := chi.NewRouter()
+	router.Use(middleware.CheckRequestID)
+	RegisterRouters(router, false)
+
+	nodeNames := []string{"node1"}
+	args := locationAwareness.ExtenderArgs{Pod: &corev1.Pod{}, NodeNames: &nodeNames}
+	result, err := PerformPostRequest(router,
+		util.PaddleflowRouterPrefix+util.PaddleflowRouterVersionV1+"/scheduler//filter", args)
+	assert.Nil(t, err)
+	assert.NotEqual(t, http.StatusOK, result.Code)
+}
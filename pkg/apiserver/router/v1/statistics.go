@@ -17,8 +17,10 @@ limitations under the License.
 package v1
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi"
 	log "github.com/sirupsen/logrus"
@@ -28,6 +30,9 @@ import (
 	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/router/util"
 )
 
+// defaultQueueStatisticsWindow is the default time window of queue statistics, in seconds
+const defaultQueueStatisticsWindow = 3600
+
 type StatisticsRouter struct{}
 
 func (sr *StatisticsRouter) Name() string {
@@ -39,13 +44,25 @@ func (sr *StatisticsRouter) AddRouter(r chi.Router) {
 
 	r.Get("/statistics/job/{jobID}", sr.getJobStatistics)
 	r.Get("/statistics/jobDetail/{jobID}", sr.getJobDetailStatistics)
+	r.Get("/statistics/queue/{queueName}", sr.getQueueStatistics)
+	r.Get("/statistics/queueDetail/{queueName}", sr.getQueueDetailStatistics)
+	r.Get("/statistics/usage", sr.getUsageStatistics)
 
 }
 
 func (sr *StatisticsRouter) getJobStatistics(writer http.ResponseWriter, request *http.Request) {
 	ctx := common.GetRequestContext(request)
 	jobID := chi.URLParam(request, util.ParamKeyJobID)
-	response, err := statistics.GetJobStatistics(&ctx, jobID)
+	start, end, step, err := parseStatisticsParams(request)
+	if err == nil && end != 0 {
+		err = validateStatisticsParam(start, end, step)
+	}
+	if err != nil {
+		ctx.Logging().Errorf("invalid request param, error:%s.", err.Error())
+		common.RenderErrWithMessage(writer, ctx.RequestID, common.InvalidURI, err.Error())
+		return
+	}
+	response, err := statistics.GetJobStatistics(&ctx, jobID, start, end)
 	if err != nil {
 		ctx.Logging().Errorf("jobID[%s] get statistics data failed. error:%s.", jobID, err.Error())
 		common.RenderErrWithMessage(writer, ctx.RequestID, ctx.ErrorCode, err.Error())
@@ -57,42 +74,10 @@ func (sr *StatisticsRouter) getJobStatistics(writer http.ResponseWriter, request
 func (sr *StatisticsRouter) getJobDetailStatistics(writer http.ResponseWriter, request *http.Request) {
 	ctx := common.GetRequestContext(request)
 	jobID := chi.URLParam(request, util.ParamKeyJobID)
-	startStr := request.URL.Query().Get(util.ParamKeyStart)
-	endStr := request.URL.Query().Get(util.ParamKeyEnd)
-	stepStr := request.URL.Query().Get(util.ParamKeyStep)
-	var start, end, step int64
-	var err error
-	if startStr == "" {
-		start = 0
-	} else {
-		start, err = strconv.ParseInt(startStr, 10, 64)
-		if err != nil {
-			ctx.Logging().Errorf("invalid request param start, error:%s.", err.Error())
-			common.RenderErrWithMessage(writer, ctx.RequestID, common.InvalidURI, err.Error())
-			return
-		}
+	start, end, step, err := parseStatisticsParams(request)
+	if err == nil {
+		err = validateStatisticsParam(start, end, step)
 	}
-	if endStr == "" {
-		end = 0
-	} else {
-		end, err = strconv.ParseInt(endStr, 10, 64)
-		if err != nil {
-			ctx.Logging().Errorf("invalid request param end, error:%s.", err.Error())
-			common.RenderErrWithMessage(writer, ctx.RequestID, common.InvalidURI, err.Error())
-			return
-		}
-	}
-	if stepStr == "" {
-		step = 60
-	} else {
-		step, err = strconv.ParseInt(stepStr, 10, 64)
-		if err != nil {
-			ctx.Logging().Errorf("invalid request param step, error:%s.", err.Error())
-			common.RenderErrWithMessage(writer, ctx.RequestID, common.InvalidURI, err.Error())
-			return
-		}
-	}
-	err = validateStatisticsParam(start, end, step)
 	if err != nil {
 		ctx.Logging().Errorf("invalid request param, error:%s.", err.Error())
 		common.RenderErrWithMessage(writer, ctx.RequestID, common.InvalidURI, err.Error())
@@ -107,6 +92,126 @@ func (sr *StatisticsRouter) getJobDetailStatistics(writer http.ResponseWriter, r
 	common.Render(writer, http.StatusOK, response)
 }
 
+func (sr *StatisticsRouter) getQueueStatistics(writer http.ResponseWriter, request *http.Request) {
+	ctx := common.GetRequestContext(request)
+	queueName := chi.URLParam(request, util.ParamKeyQueueName)
+	start, end, _, err := parseQueueStatisticsParams(request)
+	if err != nil {
+		ctx.Logging().Errorf("invalid request param, error:%s.", err.Error())
+		common.RenderErrWithMessage(writer, ctx.RequestID, common.InvalidURI, err.Error())
+		return
+	}
+	response, err := statistics.GetQueueStatistics(&ctx, queueName, start, end)
+	if err != nil {
+		ctx.Logging().Errorf("queue[%s] get statistics data failed. error:%s.", queueName, err.Error())
+		common.RenderErrWithMessage(writer, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	common.Render(writer, http.StatusOK, response)
+}
+
+func (sr *StatisticsRouter) getQueueDetailStatistics(writer http.ResponseWriter, request *http.Request) {
+	ctx := common.GetRequestContext(request)
+	queueName := chi.URLParam(request, util.ParamKeyQueueName)
+	start, end, step, err := parseQueueStatisticsParams(request)
+	if err != nil {
+		ctx.Logging().Errorf("invalid request param, error:%s.", err.Error())
+		common.RenderErrWithMessage(writer, ctx.RequestID, common.InvalidURI, err.Error())
+		return
+	}
+	response, err := statistics.GetQueueDetailStatistics(&ctx, queueName, start, end, step)
+	if err != nil {
+		ctx.Logging().Errorf("queue[%s] get detail statistics data failed. error:%s.", queueName, err.Error())
+		common.RenderErrWithMessage(writer, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	common.Render(writer, http.StatusOK, response)
+}
+
+// getUsageStatistics get the usage report of jobs
+// @Summary 获取资源用量报表
+// @Description 按天汇总用户、队列或集群的资源用量, format为csv时导出csv文件
+// @Id getUsageStatistics
+// @tags Statistics
+// @Produce json
+// @Param groupBy query string false "user, queue or cluster"
+// @Param startDate query string false "起始日期, 如2022-01-01"
+// @Param endDate query string false "结束日期, 如2022-01-31"
+// @Param format query string false "json or csv"
+// @Success 200 {object} statistics.UsageResponse "资源用量报表"
+// @Failure 400 {object} common.ErrorResponse "400"
+// @Router /statistics/usage [GET]
+func (sr *StatisticsRouter) getUsageStatistics(writer http.ResponseWriter, request *http.Request) {
+	ctx := common.GetRequestContext(request)
+	query := request.URL.Query()
+	usageRequest := statistics.UsageRequest{
+		GroupBy:     query.Get(util.QueryKeyGroupBy),
+		StartDate:   query.Get(util.QueryKeyStartDate),
+		EndDate:     query.Get(util.QueryKeyEndDate),
+		UserName:    query.Get(util.QueryKeyUser),
+		QueueName:   query.Get(util.QueryKeyQueue),
+		ClusterName: query.Get(util.QueryKeyClusterName),
+	}
+	response, err := statistics.GetUsageStatistics(&ctx, usageRequest)
+	if err != nil {
+		ctx.Logging().Errorf("get usage statistics failed. error:%s.", err.Error())
+		common.RenderErrWithMessage(writer, ctx.RequestID, ctx.ErrorCode, err.Error())
+		return
+	}
+	if query.Get(util.QueryKeyFormat) != util.FormatCSV {
+		common.Render(writer, http.StatusOK, response)
+		return
+	}
+	writer.Header().Set("Content-Type", "text/csv")
+	writer.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=usage_%s_%s_%s.csv",
+		response.GroupBy, response.StartDate, response.EndDate))
+	writer.WriteHeader(http.StatusOK)
+	if err = response.WriteCSV(writer); err != nil {
+		ctx.Logging().Errorf("write usage csv failed. error:%s.", err.Error())
+	}
+}
+
+// parseQueueStatisticsParams parse the time window of queue statistics, which is the last hour by default
+func parseQueueStatisticsParams(request *http.Request) (int64, int64, int64, error) {
+	start, end, step, err := parseStatisticsParams(request)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	if end == 0 {
+		end = time.Now().Unix()
+	}
+	if start == 0 {
+		start = end - defaultQueueStatisticsWindow
+	}
+	if err = validateStatisticsParam(start, end, step); err != nil {
+		return 0, 0, 0, err
+	}
+	return start, end, step, nil
+}
+
+func parseStatisticsParams(request *http.Request) (int64, int64, int64, error) {
+	parse := func(key string, defaultValue int64) (int64, error) {
+		valueStr := request.URL.Query().Get(key)
+		if valueStr == "" {
+			return defaultValue, nil
+		}
+		return strconv.ParseInt(valueStr, 10, 64)
+	}
+	start, err := parse(util.ParamKeyStart, 0)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	end, err := parse(util.ParamKeyEnd, 0)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	step, err := parse(util.ParamKeyStep, 60)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	return start, end, step, nil
+}
+
 func validateStatisticsParam(start, end, step int64) error {
 	if start > end {
 		return common.InvalidStartEndParams()
@@ -22,6 +22,8 @@ import (
 	apiv1 "k8s.io/api/core/v1"
 
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/logger"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/tracing"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage/secret"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/trace_logger"
 )
 
@@ -53,6 +55,8 @@ type ServerConfig struct {
 	ImageConf ImageConfig                    `yaml:"imageRepository"`
 	Monitor   PrometheusConfig               `yaml:"monitor"`
 	Metrics   MetricsConfig                  `yaml:"metrics"`
+	Tracing   tracing.TracingConfig          `yaml:"tracing"`
+	Secret    secret.Config                  `yaml:"secret"`
 }
 
 type StorageConfig struct {
@@ -91,6 +95,67 @@ type JobConfig struct {
 	DefaultJobYamlPath string       `yaml:"defaultJobYamlPath"`
 	IsSingleCluster    bool         `yaml:"isSingleCluster"`
 	Log                JobLogConfig `yaml:"log"`
+	// Preemption defines how pending jobs with high priority preempt running jobs with low priority
+	Preemption PreemptionConfig `yaml:"preemption"`
+	// VirtualQueues defines queues spanning clusters, jobs submitted to them are placed into one of member queues
+	VirtualQueues []VirtualQueueConfig `yaml:"virtualQueues"`
+	// ClusterHealth defines how to probe clusters and switch their status automatically
+	ClusterHealth ClusterHealthConfig `yaml:"clusterHealth"`
+	// Notebook defines how to expose interactive notebook jobs
+	Notebook NotebookConfig `yaml:"notebook"`
+	// GPUResourceNames are names of scalar resources accounted as gpu in job usage, such as nvidia.com/gpu
+	GPUResourceNames []string `yaml:"gpuResourceNames"`
+}
+
+type NotebookConfig struct {
+	// IngressDomain is the wildcard domain of notebook ingress, notebook is accessed by <jobID>.<IngressDomain>,
+	// and ingress is not supported if it is empty
+	IngressDomain    string `yaml:"ingressDomain"`
+	IngressClassName string `yaml:"ingressClassName"`
+	// IngressScheme is the scheme of connect url when notebook is exposed through ingress, default is http
+	IngressScheme string `yaml:"ingressScheme"`
+	// IdleTimeoutSeconds is the default idle timeout of notebook, 0 means notebook is never stopped automatically
+	IdleTimeoutSeconds int `yaml:"idleTimeoutSeconds"`
+}
+
+type ClusterHealthConfig struct {
+	Enable bool `yaml:"enable"`
+	// PeriodSeconds defines how often to probe clusters
+	PeriodSeconds int `yaml:"periodSeconds"`
+	// TimeoutSeconds defines the timeout of each probe
+	TimeoutSeconds int `yaml:"timeoutSeconds"`
+	// FailureThreshold is the number of consecutive failed probes before cluster is set offline
+	FailureThreshold int `yaml:"failureThreshold"`
+	// SuccessThreshold is the number of consecutive succeeded probes before cluster is set online again
+	SuccessThreshold int `yaml:"successThreshold"`
+	// HistorySize is the number of probe results kept for each cluster
+	HistorySize int `yaml:"historySize"`
+}
+
+type VirtualQueueConfig struct {
+	Name string `yaml:"name"`
+	// Queues is the names of member queues, which are usually bound to different clusters
+	Queues []string `yaml:"queues"`
+	// Policy is the placement policy to pick member queue, including idle, weight and locality
+	Policy string `yaml:"policy"`
+	// ClusterWeights is the weights of clusters keyed by cluster name, used by weight policy
+	ClusterWeights map[string]int `yaml:"clusterWeights"`
+	// Failover defines whether to resubmit jobs stuck on offline cluster to other member queues
+	Failover bool `yaml:"failover"`
+}
+
+type PreemptionConfig struct {
+	Enable bool `yaml:"enable"`
+	// PeriodSeconds defines how often to check preemption
+	PeriodSeconds int `yaml:"periodSeconds"`
+	// PendingSeconds defines how long a job has been pending before it can preempt others
+	PendingSeconds int `yaml:"pendingSeconds"`
+	// GracePeriodSeconds defines how long victims can checkpoint before they are stopped
+	GracePeriodSeconds int `yaml:"gracePeriodSeconds"`
+	// SignalAnnotation is the pod annotation key to notify victims the deadline of preemption
+	SignalAnnotation string `yaml:"signalAnnotation"`
+	// MinPreemptorPriority is the minimum priority of job which can preempt others
+	MinPreemptorPriority string `yaml:"minPreemptorPriority"`
 }
 
 type FsServerConf struct {
@@ -101,6 +166,13 @@ type FsServerConf struct {
 	MountPodIntervalTime time.Duration `yaml:"mountPodIntervalTime"`
 	// ServicePort is used to call paddleflow api-server in k8s, the default is the same as ApiServerConfig.Port
 	ServicePort int `yaml:"servicePort"`
+	// SchedulerExtender enables the scheduler extender endpoints scoring nodes by fs cache locality.
+	// The endpoints tell which nodes cache which filesystems and are not behind the user auth,
+	// they only answer requests carrying SchedulerExtenderToken in the url path,
+	// so keep the token secret and prefer serving them through tls inside the cluster network.
+	SchedulerExtender bool `yaml:"schedulerExtender"`
+	// SchedulerExtenderToken is the shared token required by the scheduler extender endpoints
+	SchedulerExtenderToken string `yaml:"schedulerExtenderToken"`
 }
 
 type ReclaimConfig struct {
@@ -130,6 +202,10 @@ type ImageConfig struct {
 type PrometheusConfig struct {
 	Server              string `yaml:"server"`
 	ExporterServicePort int    `yaml:"exporterServicePort"`
+	// Backend is the metric backend of job statistics, prometheus or metrics-server
+	Backend string `yaml:"backend"`
+	// Queries overrides the built-in query templates, keyed by metric name
+	Queries map[string]string `yaml:"queries"`
 }
 
 type MetricsConfig struct {
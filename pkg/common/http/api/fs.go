@@ -31,6 +31,7 @@ const (
 	GetFsApi          = Prefix + "/fs"
 	GetLinksApis      = Prefix + "/link"
 	CacheReportConfig = Prefix + "/fsCache/report"
+	FsAuditApi        = Prefix + "/fsAudit"
 	KeyUsername       = "username"
 )
 
@@ -56,6 +57,20 @@ type LinksParams struct {
 	FsParams
 }
 
+type FsAuditEvent struct {
+	Operation string `json:"operation"`
+	Path      string `json:"path"`
+	JobID     string `json:"jobID"`
+	Uid       uint32 `json:"uid"`
+	Host      string `json:"host"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+type FsAuditParams struct {
+	FsParams
+	Events []FsAuditEvent `json:"events"`
+}
+
 type FsResponse struct {
 	Id            string            `json:"id"`
 	Name          string            `json:"name"`
@@ -127,3 +142,12 @@ func LinksRequest(params LinksParams, c *core.PaddleFlowClient) (*LinksResponse,
 	}
 	return resp, nil
 }
+
+func FsAuditRequest(params FsAuditParams, c *core.PaddleFlowClient) error {
+	return core.NewRequestBuilder(c).
+		WithHeader(common.HeaderKeyAuthorization, params.Token).
+		WithURL(FsAuditApi).
+		WithMethod(http.POST).
+		WithBody(params).
+		Do()
+}
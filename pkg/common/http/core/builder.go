@@ -18,6 +18,8 @@ package core
 
 import (
 	"fmt"
+	"io"
+	"io/ioutil"
 )
 
 // RequestBuilder holds config data for bce request.
@@ -31,6 +33,7 @@ type RequestBuilder struct {
 	queryParams map[string][]string // optional
 	headers     map[string]string   // optional
 	body        interface{}         // optional
+	rawBody     io.Reader           // optional
 	result      interface{}         // optional
 }
 
@@ -107,6 +110,12 @@ func (b *RequestBuilder) WithBody(body interface{}) *RequestBuilder {
 	return b
 }
 
+// set body which is sent as it is, rather than encoded as json.
+func (b *RequestBuilder) WithRawBody(body io.Reader) *RequestBuilder {
+	b.rawBody = body
+	return b
+}
+
 func (b *RequestBuilder) WithResult(result interface{}) *RequestBuilder {
 	b.result = result
 	return b
@@ -132,6 +141,27 @@ func (b *RequestBuilder) Do() error {
 	return nil
 }
 
+// DoStream will send request like Do, and return the response body which must be closed by the caller.
+func (b *RequestBuilder) DoStream() (io.ReadCloser, error) {
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+
+	req, err := b.buildPFRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := b.client.SendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.IsFail() {
+		return nil, resp.ServiceError()
+	}
+	return resp.Body(), nil
+}
+
 // Validate if the required fields are providered.
 func (b *RequestBuilder) validate() error {
 	if len(b.url) == 0 {
@@ -158,7 +188,9 @@ func (b *RequestBuilder) buildPFRequest() (*PFRequest, error) {
 	if b.queryParams != nil {
 		req.SetParams(b.queryParams)
 	}
-	if b.body != nil {
+	if b.rawBody != nil {
+		req.SetBody(ioutil.NopCloser(b.rawBody))
+	} else if b.body != nil {
 		body, err := NewRequestBodyWithStruct(b.body)
 		if err != nil {
 			return nil, err
@@ -40,6 +40,14 @@ var (
 	RayJobGVK     = schema.GroupVersionKind{Group: "ray.io", Version: "v1alpha1", Kind: "RayJob"}
 	// ArgoWorkflowGVK defines GVK for argo Workflow
 	ArgoWorkflowGVK = schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Workflow"}
+	// BatchJobGVK defines GVK for kubernetes Job, which runs notebook server until it is idle
+	BatchJobGVK = schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "Job"}
+	ServiceGVK  = schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Service"}
+	IngressGVK  = schema.GroupVersionKind{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"}
+	// DeploymentGVK and InferenceServiceGVK define GVK for serving job, which runs model server until it is stopped
+	DeploymentGVK       = schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+	InferenceServiceGVK = schema.GroupVersionKind{Group: "serving.kserve.io", Version: "v1beta1", Kind: "InferenceService"}
+	HPAGVK              = schema.GroupVersionKind{Group: "autoscaling", Version: "v1", Kind: "HorizontalPodAutoscaler"}
 
 	// PodGVR TODO:// add gvr to process and get rid of all gvks in future
 	PodGVR          = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
@@ -55,15 +63,18 @@ var (
 
 	// GVKJobStatusMap contains GroupVersionKind and convertStatus function to sync job status
 	GVKJobStatusMap = map[schema.GroupVersionKind]bool{
-		SparkAppGVK:     true,
-		PaddleJobGVK:    true,
-		PodGVK:          true,
-		ArgoWorkflowGVK: true,
-		PyTorchJobGVK:   true,
-		TFJobGVK:        true,
-		MXNetJobGVK:     true,
-		MPIJobGVK:       true,
-		RayJobGVK:       true,
+		SparkAppGVK:         true,
+		PaddleJobGVK:        true,
+		PodGVK:              true,
+		ArgoWorkflowGVK:     true,
+		PyTorchJobGVK:       true,
+		TFJobGVK:            true,
+		MXNetJobGVK:         true,
+		MPIJobGVK:           true,
+		RayJobGVK:           true,
+		BatchJobGVK:         true,
+		DeploymentGVK:       true,
+		InferenceServiceGVK: true,
 	}
 )
 
@@ -95,6 +106,15 @@ func GetJobFrameworkVersion(jobType commomschema.JobType, framework commomschema
 	if jobType == commomschema.TypeWorkflow {
 		return commomschema.NewFrameworkVersion(ArgoWorkflowGVK.Kind, ArgoWorkflowGVK.GroupVersion().String())
 	}
+	if jobType == commomschema.TypeNotebook {
+		return commomschema.NewFrameworkVersion(BatchJobGVK.Kind, BatchJobGVK.GroupVersion().String())
+	}
+	if jobType == commomschema.TypeServing {
+		if framework == commomschema.FrameworkKServe {
+			return commomschema.NewFrameworkVersion(InferenceServiceGVK.Kind, InferenceServiceGVK.GroupVersion().String())
+		}
+		return commomschema.NewFrameworkVersion(DeploymentGVK.Kind, DeploymentGVK.GroupVersion().String())
+	}
 	var gvk schema.GroupVersionKind
 	switch framework {
 	case commomschema.FrameworkStandalone:
@@ -135,6 +155,12 @@ func GetJobTypeAndFramework(gvk schema.GroupVersionKind) (commomschema.JobType,
 		return commomschema.TypeDistributed, commomschema.FrameworkMPI
 	case RayJobGVK:
 		return commomschema.TypeDistributed, commomschema.FrameworkRay
+	case BatchJobGVK:
+		return commomschema.TypeNotebook, commomschema.FrameworkStandalone
+	case DeploymentGVK:
+		return commomschema.TypeServing, commomschema.FrameworkStandalone
+	case InferenceServiceGVK:
+		return commomschema.TypeServing, commomschema.FrameworkKServe
 	default:
 		log.Errorf("GroupVersionKind %s is not support", gvk)
 		return "", ""
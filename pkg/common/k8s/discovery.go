@@ -71,6 +71,42 @@ var DiscoveryHandlerFunc = http.HandlerFunc(func(w http.ResponseWriter, req *htt
 				{Name: "pods", Namespaced: true, Kind: "Pod"},
 				{Name: "namespaces", Namespaced: false, Kind: "Namespace"},
 				{Name: "configmaps", Namespaced: true, Kind: "ConfigMap"},
+				{Name: "services", Namespaced: true, Kind: "Service"},
+			},
+		}
+	case "/apis/batch/v1":
+		obj = &metav1.APIResourceList{
+			GroupVersion: "batch/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "jobs", Namespaced: true, Kind: "Job"},
+			},
+		}
+	case "/apis/networking.k8s.io/v1":
+		obj = &metav1.APIResourceList{
+			GroupVersion: "networking.k8s.io/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "ingresses", Namespaced: true, Kind: "Ingress"},
+			},
+		}
+	case "/apis/apps/v1":
+		obj = &metav1.APIResourceList{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "deployments", Namespaced: true, Kind: "Deployment"},
+			},
+		}
+	case "/apis/autoscaling/v1":
+		obj = &metav1.APIResourceList{
+			GroupVersion: "autoscaling/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "horizontalpodautoscalers", Namespaced: true, Kind: "HorizontalPodAutoscaler"},
+			},
+		}
+	case "/apis/serving.kserve.io/v1beta1":
+		obj = &metav1.APIResourceList{
+			GroupVersion: "serving.kserve.io/v1beta1",
+			APIResources: []metav1.APIResource{
+				{Name: "inferenceservices", Namespaced: true, Kind: "InferenceService"},
 			},
 		}
 	case "/api":
@@ -124,6 +160,36 @@ var DiscoveryHandlerFunc = http.HandlerFunc(func(w http.ResponseWriter, req *htt
 						{GroupVersion: "ray.io/v1alpha1", Version: "v1alpha1"},
 					},
 				},
+				{
+					Name: "batch",
+					Versions: []metav1.GroupVersionForDiscovery{
+						{GroupVersion: "batch/v1", Version: "v1"},
+					},
+				},
+				{
+					Name: "networking.k8s.io",
+					Versions: []metav1.GroupVersionForDiscovery{
+						{GroupVersion: "networking.k8s.io/v1", Version: "v1"},
+					},
+				},
+				{
+					Name: "apps",
+					Versions: []metav1.GroupVersionForDiscovery{
+						{GroupVersion: "apps/v1", Version: "v1"},
+					},
+				},
+				{
+					Name: "autoscaling",
+					Versions: []metav1.GroupVersionForDiscovery{
+						{GroupVersion: "autoscaling/v1", Version: "v1"},
+					},
+				},
+				{
+					Name: "serving.kserve.io",
+					Versions: []metav1.GroupVersionForDiscovery{
+						{GroupVersion: "serving.kserve.io/v1beta1", Version: "v1beta1"},
+					},
+				},
 			},
 		}
 	default:
@@ -28,6 +28,8 @@ type RequestContext struct {
 	GrpcCode     codes.Code
 	ErrorCode    string
 	ErrorMessage string
+	// TraceParent is the w3c traceparent of the request span, empty if tracing is disabled
+	TraceParent string
 }
 
 func (ctx *RequestContext) Logging() *log.Entry {
@@ -17,6 +17,8 @@ limitations under the License.
 package schema
 
 import (
+	"time"
+
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/resources"
 )
 
@@ -54,3 +56,19 @@ type QuotaSummary struct {
 	TotalQuota resources.Resource `json:"total"`
 	IdleQuota  resources.Resource `json:"idle"`
 }
+
+// ClusterHealth is the result of probing cluster health
+type ClusterHealth struct {
+	ProbeTime time.Time `json:"probeTime"`
+	Healthy   bool      `json:"healthy"`
+	// APIServerReachable and APIServerLatency is the result of requesting api server
+	APIServerReachable bool  `json:"apiServerReachable"`
+	APIServerLatencyMs int64 `json:"apiServerLatencyMs"`
+	// InformersSynced is false if any started informer has not synced
+	InformersSynced bool `json:"informersSynced"`
+	// InformerSyncLag is the number of resource versions that node informer is behind api server
+	InformerSyncLag int64  `json:"informerSyncLag"`
+	ReadyNodes      int    `json:"readyNodes"`
+	TotalNodes      int    `json:"totalNodes"`
+	Message         string `json:"message,omitempty"`
+}
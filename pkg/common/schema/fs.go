@@ -49,13 +49,51 @@ const (
 	AnnotationKeyCacheDir    = "cacheDir"
 	AnnotationKeyMTime       = "modifiedTime"
 	AnnotationKeyMountPrefix = "mount-"
+	// AnnotationKeyMountKey records the sharing key of mount pod, mount pods with the same key serve the same volumes
+	AnnotationKeyMountKey = "mountKey"
+	// AnnotationKeyMountDir records the dir under host mnt dir where mount pod mounts the filesystem
+	AnnotationKeyMountDir = "mountDir"
+	// AnnotationKeyDraining marks a mount pod outdated, which accepts no new mounts and is deleted when unreferenced
+	AnnotationKeyDraining = "draining"
 
 	EnvKeyMountPodName = "POD_NAME"
 	EnvKeyNamespace    = "NAMESPACE"
 
 	MountPodNamespace = "paddleflow"
+
+	// FsSnapshotSeparator joins fs name and snapshot name, such as data@v1
+	FsSnapshotSeparator = "@"
+	// FsSnapshotIDSeparator joins fs id and snapshot name, '.' is valid in pv and pvc names but not in fs names
+	FsSnapshotIDSeparator = "."
+)
+
+// operations recorded in fs audit log, the last three are reported by fuse clients
+const (
+	FsAuditOpCreateFs   = "createFs"
+	FsAuditOpDeleteFs   = "deleteFs"
+	FsAuditOpCreateLink = "createLink"
+	FsAuditOpDeleteLink = "deleteLink"
+	FsAuditOpGrant      = "grant"
+	FsAuditOpRevoke     = "revoke"
+	FsAuditOpStsToken   = "stsToken"
+	FsAuditOpOpen       = "open"
+	FsAuditOpUnlink     = "unlink"
+	FsAuditOpRmdir      = "rmdir"
+
+	FsAuditSourceServer = "server"
+	FsAuditSourceFuse   = "fuse"
 )
 
+// IsFuseAuditOp returns whether the operation can be reported by fuse clients
+func IsFuseAuditOp(op string) bool {
+	switch op {
+	case FsAuditOpOpen, FsAuditOpUnlink, FsAuditOpRmdir:
+		return true
+	default:
+		return false
+	}
+}
+
 func IsValidFsMetaDriver(metaDriver string) bool {
 	switch metaDriver {
 	case FsMetaDisk, FsMetaMemory:
@@ -78,3 +116,37 @@ func ConcatenatePVName(namespace, fsID string) string {
 func ConcatenatePVCName(fsID string) string {
 	return strings.Replace(PVCNameTemplate, FSIDFormat, fsID, -1)
 }
+
+// FsIDOfPVCName returns the fs id of pvc named by ConcatenatePVCName, or empty if not
+func FsIDOfPVCName(pvcName string) string {
+	prefix, suffix := strings.Split(PVCNameTemplate, FSIDFormat)[0], strings.Split(PVCNameTemplate, FSIDFormat)[1]
+	if len(pvcName) <= len(prefix)+len(suffix) || !strings.HasPrefix(pvcName, prefix) || !strings.HasSuffix(pvcName, suffix) {
+		return ""
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(pvcName, prefix), suffix)
+}
+
+// ParseFsSnapshotName splits name like fsName@snapshot, snapshot is empty if name does not reference one
+func ParseFsSnapshotName(name string) (fsName, snapshot string) {
+	if i := strings.LastIndex(name, FsSnapshotSeparator); i >= 0 {
+		return name[:i], name[i+1:]
+	}
+	return name, ""
+}
+
+func FsSnapshotName(fsName, snapshot string) string {
+	return fsName + FsSnapshotSeparator + snapshot
+}
+
+// FsSnapshotID returns the id of snapshot, which is used as fs id when mounting the snapshot
+func FsSnapshotID(fsID, snapshot string) string {
+	return fsID + FsSnapshotIDSeparator + snapshot
+}
+
+// ParseFsSnapshotID splits the snapshot id into fs id and snapshot name
+func ParseFsSnapshotID(id string) (fsID, snapshot string) {
+	if i := strings.LastIndex(id, FsSnapshotIDSeparator); i >= 0 {
+		return id[:i], id[i+1:]
+	}
+	return id, ""
+}
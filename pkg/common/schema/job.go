@@ -19,6 +19,8 @@ package schema
 import (
 	"fmt"
 	"strings"
+
+	"github.com/jinzhu/copier"
 )
 
 type JobType string
@@ -41,6 +43,8 @@ const (
 	EnvJobPriority    = "PF_JOB_PRIORITY"
 	EnvJobMode        = "PF_JOB_MODE"
 	EnvJobFramework   = "PF_JOB_FRAMEWORK"
+	// EnvJobFsLocality is the cache locality mode of job, which is preferred, required or none
+	EnvJobFsLocality = "PF_FS_LOCALITY"
 	// EnvJobYamlPath Additional configuration for a specific job
 	EnvJobYamlPath  = "PF_JOB_YAML_PATH"
 	EnvIsCustomYaml = "PF_IS_CUSTOM_YAML"
@@ -51,6 +55,10 @@ const (
 	EnvJobRestartPolicy = "PF_JOB_RESTART_POLICY"
 
 	EnvEnableJobQueueSync = "PF_JOB_QUEUE_SYNC"
+	// EnvLocalRuntimeDir defines the directory which stores the work dirs and logs of jobs on local runtime
+	EnvLocalRuntimeDir = "PF_LOCAL_RUNTIME_DIR"
+	// EnvLocalRuntimeContainer defines whether run jobs with image in containers on local runtime, default is true
+	EnvLocalRuntimeContainer = "PF_LOCAL_RUNTIME_CONTAINER"
 
 	// EnvJobModePS env
 	EnvJobModePS          = "PS"
@@ -112,6 +120,8 @@ const (
 	TypeSingle      JobType = "single"
 	TypeDistributed JobType = "distributed"
 	TypeWorkflow    JobType = "workflow"
+	TypeNotebook    JobType = "notebook"
+	TypeServing     JobType = "serving"
 
 	FrameworkSpark      Framework = "spark"
 	FrameworkMPI        Framework = "mpi"
@@ -121,6 +131,7 @@ const (
 	FrameworkMXNet      Framework = "mxnet"
 	FrameworkRay        Framework = "ray"
 	FrameworkStandalone Framework = "standalone"
+	FrameworkKServe     Framework = "kserve"
 
 	ListenerTypeJob      = "job"
 	ListenerTypeTask     = "task"
@@ -152,6 +163,11 @@ const (
 	JobIDLabel        = "paddleflow-job-id"
 	JobTTLSeconds     = "padleflow/job-ttl-seconds"
 	JobLabelFramework = "paddleflow-job-framework"
+	// JobConnectURLAnnotation and JobConnectTokenAnnotation record how to connect to interactive job, such as notebook
+	JobConnectURLAnnotation   = "paddleflow/connect-url"
+	JobConnectTokenAnnotation = "paddleflow/connect-token"
+	// JobRuntimeConnectURLKey is the key of connect url in runtime info of interactive job
+	JobRuntimeConnectURLKey = "connectURL"
 
 	VolcanoJobNameLabel  = "volcano.sh/job-name"
 	QueueLabelKey        = "volcano.sh/queue-name"
@@ -268,6 +284,137 @@ type Conf struct {
 	Image       string            `json:"image"`
 	Port        int               `json:"port,omitempty"`
 	Args        []string          `json:"args,omitempty"`
+	// 作业依赖，前置作业满足条件后才会提交
+	DependsOn           []string            `json:"dependsOn,omitempty"`
+	DependencyCondition DependencyCondition `json:"dependencyCondition,omitempty"`
+	// 交互式开发环境配置，仅notebook作业有效
+	Notebook *NotebookConf `json:"notebook,omitempty"`
+	// 模型服务配置，仅serving作业有效
+	Serving *ServingConf `json:"serving,omitempty"`
+}
+
+// NotebookKind is the kind of interactive development server
+type NotebookKind string
+
+const (
+	NotebookJupyter NotebookKind = "jupyter"
+	NotebookVSCode  NotebookKind = "vscode"
+
+	DefaultJupyterPort = 8888
+	DefaultVSCodePort  = 8080
+)
+
+// NotebookConf defines the interactive development server of notebook job
+type NotebookConf struct {
+	Kind NotebookKind `json:"kind"`
+	// IdleTimeout is the seconds that notebook is stopped after no activity, 0 means never stop
+	IdleTimeout int `json:"idleTimeout,omitempty"`
+	// Ingress defines whether expose notebook through ingress, or only through service in cluster
+	Ingress bool `json:"ingress,omitempty"`
+}
+
+// DefaultPort returns the default listening port of notebook server
+func (nc *NotebookConf) DefaultPort() int {
+	if nc.Kind == NotebookVSCode {
+		return DefaultVSCodePort
+	}
+	return DefaultJupyterPort
+}
+
+const (
+	DefaultServingPort = 8080
+	// DefaultTargetCPUUtilization is the default target average cpu utilization percent of serving autoscaling
+	DefaultTargetCPUUtilization = 80
+)
+
+// ServingConf defines the model server of serving job
+type ServingConf struct {
+	// ReadinessPath is the http path to probe the readiness of model server, tcp port is probed when it is empty
+	ReadinessPath string `json:"readinessPath,omitempty"`
+	// Autoscaling defines the replicas range of model server, and the replicas of member is used when it is nil
+	Autoscaling *ServingAutoscaling `json:"autoscaling,omitempty"`
+	// CanaryTrafficPercent is the percent of traffic routed to the latest revision during canary rollout,
+	// and the rest is routed to the previous revision. nil means the latest revision is fully rolled out
+	CanaryTrafficPercent *int `json:"canaryTrafficPercent,omitempty"`
+}
+
+// ServingAutoscaling defines the horizontal autoscaling of model server
+type ServingAutoscaling struct {
+	MinReplicas int `json:"minReplicas"`
+	MaxReplicas int `json:"maxReplicas"`
+	// TargetCPUUtilization is the target average cpu utilization percent of model server
+	TargetCPUUtilization int `json:"targetCPUUtilization,omitempty"`
+}
+
+// IsCanary returns whether the latest revision of serving is in canary rollout
+func (sc *ServingConf) IsCanary() bool {
+	return sc != nil && sc.CanaryTrafficPercent != nil && *sc.CanaryTrafficPercent < 100
+}
+
+// ValidateCanaryReplicas checks that the canary traffic percent can be represented by the ratio of replicas, which is
+// how the traffic is split for standalone serving job. Both min and max replicas are checked when autoscaling is enabled
+func (sc *ServingConf) ValidateCanaryReplicas(replicas int) error {
+	if !sc.IsCanary() {
+		return nil
+	}
+	totals := []int{replicas}
+	if sc.Autoscaling != nil {
+		totals = []int{sc.Autoscaling.MinReplicas, sc.Autoscaling.MaxReplicas}
+	}
+	for _, total := range totals {
+		if _, _, err := SplitServingReplicas(total, *sc.CanaryTrafficPercent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SplitServingReplicas splits replicas between stable and canary revision by canary traffic percent,
+// and the percent must be represented exactly by the replicas of both revisions
+func SplitServingReplicas(replicas, canaryPercent int) (int, int, error) {
+	if canaryPercent <= 0 {
+		return replicas, 0, nil
+	}
+	if canaryPercent >= 100 || replicas*canaryPercent%100 != 0 {
+		return 0, 0, fmt.Errorf("canaryTrafficPercent %d cannot be represented by the ratio of %d replicas",
+			canaryPercent, replicas)
+	}
+	canary := replicas * canaryPercent / 100
+	return replicas - canary, canary, nil
+}
+
+// DependencyCondition is the condition that prerequisite jobs must meet before dependent job is submitted
+type DependencyCondition string
+
+const (
+	// DependencySucceeded requires all prerequisite jobs succeeded
+	DependencySucceeded DependencyCondition = "succeeded"
+	// DependencyFinished requires all prerequisite jobs are in final status
+	DependencyFinished DependencyCondition = "finished"
+)
+
+// IsDependencyMet returns whether prerequisite job with status meets the condition
+func IsDependencyMet(condition DependencyCondition, status JobStatus) bool {
+	if condition == DependencyFinished {
+		return IsImmutableJobStatus(status)
+	}
+	return status == StatusJobSucceeded
+}
+
+// PriorityRank returns the rank of job priority, the higher rank means the higher priority, default is NORMAL
+func PriorityRank(priority string) int {
+	switch priority {
+	case EnvJobVeryLowPriority:
+		return 0
+	case EnvJobLowPriority:
+		return 1
+	case EnvJobHighPriority:
+		return 3
+	case EnvJobVeryHighPriority:
+		return 4
+	default:
+		return 2
+	}
 }
 
 // FileSystem indicate PaddleFlow
@@ -405,6 +552,18 @@ func (c *Conf) SetQueueID(id string) {
 	c.QueueID = id
 }
 
+func (c *Conf) GetDependsOn() []string {
+	return c.DependsOn
+}
+
+// GetDependencyCondition returns condition of dependencies, default is succeeded
+func (c *Conf) GetDependencyCondition() DependencyCondition {
+	if c.DependencyCondition == "" {
+		return DependencySucceeded
+	}
+	return c.DependencyCondition
+}
+
 func (c *Conf) GetClusterID() string {
 	return c.ClusterID
 }
@@ -484,6 +643,19 @@ func (c *Conf) GetProcessedFileSystem() []FileSystem {
 	return c.processedFS
 }
 
+// DeepCopy returns a copy of conf, which can be modified without changing conf
+func (c *Conf) DeepCopy() (*Conf, error) {
+	if c == nil {
+		return nil, nil
+	}
+	conf := &Conf{}
+	if err := copier.CopyWithOption(conf, c, copier.Option{DeepCopy: true}); err != nil {
+		return nil, err
+	}
+	conf.processedFS = append([]FileSystem(nil), c.processedFS...)
+	return conf, nil
+}
+
 /**
 // Scan for gorm
 func (s *Conf) Scan(value interface{}) error {
@@ -508,8 +680,37 @@ func (s Conf) Value() (driver.Value, error) {
 }*/
 
 type Member struct {
-	ID       string     `json:"id"`
-	Replicas int        `json:"replicas"`
-	Role     MemberRole `json:"role"`
-	Conf     `json:",inline"`
+	ID       string `json:"id"`
+	Replicas int    `json:"replicas"`
+	// MinReplicas and MaxReplicas are the bounds for elastic training, and replicas of member can be scaled
+	// within the bounds at runtime when MaxReplicas is set
+	MinReplicas int        `json:"minReplicas,omitempty"`
+	MaxReplicas int        `json:"maxReplicas,omitempty"`
+	Role        MemberRole `json:"role"`
+	Conf        `json:",inline"`
+}
+
+// IsElastic returns true if replicas of member can be scaled at runtime
+func (m *Member) IsElastic() bool {
+	return m.MaxReplicas > 0
+}
+
+// ElasticMinReplicas returns the lower bound of replicas for elastic member, which is 1 at least
+func (m *Member) ElasticMinReplicas() int {
+	if m.MinReplicas < 1 {
+		return 1
+	}
+	return m.MinReplicas
+}
+
+// ValidateReplicas checks whether replicas is within the elastic bounds of member
+func (m *Member) ValidateReplicas(replicas int) error {
+	if !m.IsElastic() {
+		return fmt.Errorf("member %s is not elastic, maxReplicas is not set", m.Role)
+	}
+	if replicas < m.ElasticMinReplicas() || replicas > m.MaxReplicas {
+		return fmt.Errorf("replicas %d of member %s is out of range [%d, %d]", replicas, m.Role,
+			m.ElasticMinReplicas(), m.MaxReplicas)
+	}
+	return nil
 }
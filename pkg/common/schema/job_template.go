@@ -0,0 +1,108 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// TemplateParameterType is the value type of job template parameter
+type TemplateParameterType string
+
+const (
+	TemplateParameterString TemplateParameterType = "string"
+	TemplateParameterInt    TemplateParameterType = "int"
+	TemplateParameterFloat  TemplateParameterType = "float"
+	TemplateParameterBool   TemplateParameterType = "bool"
+)
+
+// JobTemplateParameter is a typed parameter of job template, which is referenced as {{name}} in template
+type JobTemplateParameter struct {
+	Name        string                `json:"name"`
+	Type        TemplateParameterType `json:"type"`
+	Default     interface{}           `json:"default,omitempty"`
+	Required    bool                  `json:"required,omitempty"`
+	Description string                `json:"description,omitempty"`
+}
+
+// Convert converts value to the type of parameter, value can be json value or string
+func (p JobTemplateParameter) Convert(value interface{}) (interface{}, error) {
+	var err error
+	var result interface{}
+	switch p.Type {
+	case TemplateParameterString, "":
+		switch v := value.(type) {
+		case string:
+			result = v
+		case float64, bool, json.Number:
+			result = fmt.Sprintf("%v", v)
+		default:
+			err = fmt.Errorf("unsupported value %v", value)
+		}
+	case TemplateParameterInt:
+		switch v := value.(type) {
+		case float64:
+			if v != math.Trunc(v) {
+				err = fmt.Errorf("value %v is not an integer", v)
+			}
+			result = int64(v)
+		case int:
+			result = int64(v)
+		case int64:
+			result = v
+		case json.Number:
+			result, err = v.Int64()
+		case string:
+			result, err = strconv.ParseInt(v, 10, 64)
+		default:
+			err = fmt.Errorf("unsupported value %v", value)
+		}
+	case TemplateParameterFloat:
+		switch v := value.(type) {
+		case float64:
+			result = v
+		case int:
+			result = float64(v)
+		case int64:
+			result = float64(v)
+		case json.Number:
+			result, err = v.Float64()
+		case string:
+			result, err = strconv.ParseFloat(v, 64)
+		default:
+			err = fmt.Errorf("unsupported value %v", value)
+		}
+	case TemplateParameterBool:
+		switch v := value.(type) {
+		case bool:
+			result = v
+		case string:
+			result, err = strconv.ParseBool(v)
+		default:
+			err = fmt.Errorf("unsupported value %v", value)
+		}
+	default:
+		return nil, fmt.Errorf("the type %s of parameter %s is not supported", p.Type, p.Name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("the value of parameter %s should be %s, err: %v", p.Name, p.Type, err)
+	}
+	return result, nil
+}
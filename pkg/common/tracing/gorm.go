@@ -0,0 +1,116 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const (
+	gormPluginName   = "paddleflow:tracing"
+	gormSpanKey      = "paddleflow:tracing_span"
+	gormCallbackName = "paddleflow:tracing"
+)
+
+// GormPlugin creates a span for each statement executed by gorm with a context carrying a span, which is set by
+// db.WithContext, statements without parent span such as those of background loops are not traced
+type GormPlugin struct {
+	DBSystem string
+}
+
+// NewGormPlugin returns gorm plugin, dbSystem is the database driver such as mysql or sqlite
+func NewGormPlugin(dbSystem string) *GormPlugin {
+	return &GormPlugin{DBSystem: dbSystem}
+}
+
+func (p *GormPlugin) Name() string {
+	return gormPluginName
+}
+
+// gormRegisterer is implemented by the callback returned by Before/After of gorm processors
+type gormRegisterer interface {
+	Register(name string, fn func(*gorm.DB)) error
+}
+
+func (p *GormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	hooks := []struct {
+		operation string
+		before    gormRegisterer
+		after     gormRegisterer
+	}{
+		{"create", cb.Create().Before("gorm:create"), cb.Create().After("gorm:create")},
+		{"query", cb.Query().Before("gorm:query"), cb.Query().After("gorm:query")},
+		{"update", cb.Update().Before("gorm:update"), cb.Update().After("gorm:update")},
+		{"delete", cb.Delete().Before("gorm:delete"), cb.Delete().After("gorm:delete")},
+		{"row", cb.Row().Before("gorm:row"), cb.Row().After("gorm:row")},
+		{"raw", cb.Raw().Before("gorm:raw"), cb.Raw().After("gorm:raw")},
+	}
+	for _, h := range hooks {
+		if err := h.before.Register(gormCallbackName+"_before_"+h.operation, p.before(h.operation)); err != nil {
+			return err
+		}
+		if err := h.after.Register(gormCallbackName+"_after_"+h.operation, p.after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *GormPlugin) before(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		if ctx == nil || !trace.SpanContextFromContext(ctx).IsValid() {
+			return
+		}
+		spanName := "gorm." + operation
+		if db.Statement.Table != "" {
+			spanName += " " + db.Statement.Table
+		}
+		_, span := Tracer().Start(ctx, spanName, trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemKey.String(p.DBSystem),
+				semconv.DBOperationKey.String(operation),
+				semconv.DBSQLTableKey.String(db.Statement.Table)))
+		db.InstanceSet(gormSpanKey, span)
+	}
+}
+
+func (p *GormPlugin) after(db *gorm.DB) {
+	value, ok := db.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	if span.IsRecording() {
+		span.SetAttributes(semconv.DBStatementKey.String(db.Statement.SQL.String()),
+			attribute.Int64("db.rows_affected", db.RowsAffected))
+	}
+	err := db.Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// record not found is a normal result of query
+		err = nil
+	}
+	End(span, err)
+}
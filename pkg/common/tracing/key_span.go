@@ -0,0 +1,92 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// keySpans holds long-running spans keyed by the key of trace_logger, such as run id,
+// so that logs and status transitions of the key can be bridged into span events
+var keySpans = struct {
+	sync.RWMutex
+	spans map[string]trace.Span
+}{spans: make(map[string]trace.Span)}
+
+// StartKeySpan starts a span bound to key, a span already bound to key is ended first
+func StartKeySpan(ctx context.Context, key, spanName string, attrs ...attribute.KeyValue) context.Context {
+	ctx, span := Start(ctx, spanName, attrs...)
+	if !span.SpanContext().IsValid() {
+		// tracing is disabled, no need to keep the noop span
+		return ctx
+	}
+	keySpans.Lock()
+	old, found := keySpans.spans[key]
+	keySpans.spans[key] = span
+	keySpans.Unlock()
+	if found {
+		old.End()
+	}
+	return ctx
+}
+
+// EndKeySpan ends the span bound to key and unbinds it
+func EndKeySpan(key string, attrs ...attribute.KeyValue) {
+	keySpans.Lock()
+	span, found := keySpans.spans[key]
+	delete(keySpans.spans, key)
+	keySpans.Unlock()
+	if found {
+		span.SetAttributes(attrs...)
+		span.End()
+	}
+}
+
+// ContextForKey returns a context containing the span bound to key
+func ContextForKey(ctx context.Context, key string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if span, found := loadKeySpan(key); found {
+		return trace.ContextWithSpan(ctx, span)
+	}
+	return ctx
+}
+
+// AddKeyEvent adds an event to the span bound to key, it does nothing if there is no span bound to key
+func AddKeyEvent(key, name string, t time.Time, attrs ...attribute.KeyValue) {
+	if key == "" {
+		return
+	}
+	span, found := loadKeySpan(key)
+	if !found || !span.IsRecording() {
+		return
+	}
+	span.AddEvent(name, trace.WithTimestamp(t), trace.WithAttributes(attrs...))
+}
+
+func loadKeySpan(key string) (trace.Span, bool) {
+	keySpans.RLock()
+	defer keySpans.RUnlock()
+	span, found := keySpans.spans[key]
+	return span, found
+}
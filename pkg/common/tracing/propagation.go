@@ -0,0 +1,94 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/propagation"
+)
+
+const (
+	// HeaderTraceParent is the w3c trace context header
+	HeaderTraceParent = "traceparent"
+	// EnvTraceParent and EnvTraceState are injected into containers of submitted jobs,
+	// so the processes in job can continue the trace
+	EnvTraceParent = "TRACEPARENT"
+	EnvTraceState  = "TRACESTATE"
+)
+
+// envCarrier adapts env map to propagation.TextMapCarrier, keys of env are upper case
+type envCarrier map[string]string
+
+func (c envCarrier) Get(key string) string {
+	return c[strings.ToUpper(key)]
+}
+
+func (c envCarrier) Set(key, value string) {
+	c[strings.ToUpper(key)] = value
+}
+
+func (c envCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, strings.ToLower(k))
+	}
+	return keys
+}
+
+// EnvFromContext returns TRACEPARENT/TRACESTATE envs of the span in ctx, empty map is returned
+// when there is no valid span in ctx
+func EnvFromContext(ctx context.Context) map[string]string {
+	env := envCarrier{}
+	if ctx == nil {
+		return env
+	}
+	propagation.TraceContext{}.Inject(ctx, env)
+	return env
+}
+
+// InjectEnv sets trace envs of the span in ctx into env, env must not be nil
+func InjectEnv(ctx context.Context, env map[string]string) {
+	for k, v := range EnvFromContext(ctx) {
+		env[k] = v
+	}
+}
+
+// ContextFromEnv extracts remote span context from TRACEPARENT/TRACESTATE envs
+func ContextFromEnv(ctx context.Context, env map[string]string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	carrier := envCarrier{}
+	for _, key := range []string{EnvTraceParent, EnvTraceState} {
+		if v, ok := env[key]; ok {
+			carrier[key] = v
+		}
+	}
+	return propagation.TraceContext{}.Extract(ctx, carrier)
+}
+
+// ContextFromTraceParent extracts remote span context from traceparent header value
+func ContextFromTraceParent(ctx context.Context, traceParent string) context.Context {
+	return ContextFromEnv(ctx, map[string]string{EnvTraceParent: traceParent})
+}
+
+// TraceParent returns the traceparent value of span in ctx, or empty string if ctx has no valid span
+func TraceParent(ctx context.Context) string {
+	return EnvFromContext(ctx)[EnvTraceParent]
+}
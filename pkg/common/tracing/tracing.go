@@ -0,0 +1,144 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+
+	DefaultServiceName  = "paddleflow-server"
+	instrumentationName = "github.com/PaddlePaddle/PaddleFlow"
+)
+
+type TracingConfig struct {
+	Enable bool `yaml:"enable"`
+	// Exporter is the span exporter, otlp or stdout
+	Exporter string `yaml:"exporter"`
+	// Endpoint is the host:port of otlp http receiver, only used by otlp exporter
+	Endpoint string `yaml:"endpoint"`
+	Insecure bool   `yaml:"insecure"`
+	// SampleRatio is the ratio of root spans to be sampled, in range (0, 1]
+	SampleRatio float64 `yaml:"sampleRatio"`
+	ServiceName string  `yaml:"serviceName"`
+}
+
+// ShutdownFunc flushes and stops the tracer provider
+type ShutdownFunc func(ctx context.Context) error
+
+// Init set global tracer provider and propagator according to conf. When tracing is disabled,
+// the global noop tracer provider is kept, so spans created by this package cost nearly nothing.
+func Init(conf TracingConfig) (ShutdownFunc, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if !conf.Enable {
+		log.Infof("tracing is disabled")
+		return func(ctx context.Context) error { return nil }, nil
+	}
+	fillDefaultValue(&conf)
+
+	exporter, err := newExporter(conf)
+	if err != nil {
+		log.Errorf("create tracing exporter[%s] failed, err: %v", conf.Exporter, err)
+		return nil, err
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceNameKey.String(conf.ServiceName)))
+	if err != nil {
+		log.Errorf("create tracing resource failed, err: %v", err)
+		return nil, err
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(conf.SampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+	log.Infof("tracing is enabled, exporter: %s, sample ratio: %v", conf.Exporter, conf.SampleRatio)
+	return tp.Shutdown, nil
+}
+
+func fillDefaultValue(conf *TracingConfig) {
+	conf.Exporter = strings.ToLower(conf.Exporter)
+	if conf.Exporter == "" {
+		conf.Exporter = ExporterStdout
+	}
+	if conf.SampleRatio <= 0 || conf.SampleRatio > 1 {
+		conf.SampleRatio = 1
+	}
+	if conf.ServiceName == "" {
+		conf.ServiceName = DefaultServiceName
+	}
+}
+
+func newExporter(conf TracingConfig) (sdktrace.SpanExporter, error) {
+	switch conf.Exporter {
+	case ExporterOTLP:
+		var opts []otlptracehttp.Option
+		if conf.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(conf.Endpoint))
+		}
+		if conf.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(context.Background(), opts...)
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	default:
+		return nil, fmt.Errorf("tracing exporter[%s] is not supported, only %s and %s are supported",
+			conf.Exporter, ExporterOTLP, ExporterStdout)
+	}
+}
+
+// Tracer returns the tracer of PaddleFlow from global tracer provider
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Start creates a span and a context containing the newly-created span
+func Start(ctx context.Context, spanName string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return Tracer().Start(ctx, spanName, trace.WithAttributes(attrs...))
+}
+
+// End records err on span if it is not nil, and then ends the span
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,146 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func initTestTracer(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	old := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(old)
+	})
+	return recorder
+}
+
+func TestInit(t *testing.T) {
+	shutdown, err := Init(TracingConfig{Enable: false})
+	assert.NoError(t, err)
+	assert.NoError(t, shutdown(context.TODO()))
+
+	_, err = Init(TracingConfig{Enable: true, Exporter: "zipkin"})
+	assert.Error(t, err)
+
+	conf := TracingConfig{Exporter: "STDOUT", SampleRatio: 2}
+	fillDefaultValue(&conf)
+	assert.Equal(t, ExporterStdout, conf.Exporter)
+	assert.Equal(t, float64(1), conf.SampleRatio)
+	assert.Equal(t, DefaultServiceName, conf.ServiceName)
+}
+
+func TestEnvPropagation(t *testing.T) {
+	initTestTracer(t)
+
+	// no span in context
+	assert.Empty(t, EnvFromContext(context.Background()))
+
+	ctx, span := Start(context.Background(), "parent")
+	defer span.End()
+	env := map[string]string{"PF_JOB_QUEUE_NAME": "default-queue"}
+	InjectEnv(ctx, env)
+	assert.Equal(t, "default-queue", env["PF_JOB_QUEUE_NAME"])
+	assert.NotEmpty(t, env[EnvTraceParent])
+	assert.Equal(t, env[EnvTraceParent], TraceParent(ctx))
+
+	remoteCtx := ContextFromEnv(context.Background(), env)
+	remote := trace.SpanContextFromContext(remoteCtx)
+	assert.True(t, remote.IsRemote())
+	assert.Equal(t, span.SpanContext().TraceID(), remote.TraceID())
+	assert.Equal(t, span.SpanContext().SpanID(), remote.SpanID())
+
+	headerCtx := ContextFromTraceParent(context.Background(), env[EnvTraceParent])
+	assert.Equal(t, span.SpanContext().TraceID(), trace.SpanContextFromContext(headerCtx).TraceID())
+}
+
+func TestKeySpan(t *testing.T) {
+	recorder := initTestTracer(t)
+
+	runID := "run-000001"
+	// events without bound span are dropped
+	AddKeyEvent(runID, "dropped", time.Now())
+
+	ctx := StartKeySpan(context.Background(), runID, "WorkflowRuntime.start")
+	AddKeyEvent(runID, "component status transition", time.Now())
+	AddKeyEvent(runID, "trace log", time.Now())
+
+	// child spans of key span share the same trace
+	keyCtx := ContextForKey(context.Background(), runID)
+	assert.Equal(t, trace.SpanContextFromContext(ctx), trace.SpanContextFromContext(keyCtx))
+
+	EndKeySpan(runID)
+	// end twice is ok
+	EndKeySpan(runID)
+	assert.False(t, trace.SpanContextFromContext(ContextForKey(context.Background(), runID)).IsValid())
+
+	ended := recorder.Ended()
+	assert.Equal(t, 1, len(ended))
+	assert.Equal(t, "WorkflowRuntime.start", ended[0].Name())
+	assert.Equal(t, 2, len(ended[0].Events()))
+	assert.Equal(t, "component status transition", ended[0].Events()[0].Name)
+}
+
+type tracingTestRecord struct {
+	ID   int64 `gorm:"primaryKey;autoIncrement"`
+	Name string
+}
+
+func TestGormPlugin(t *testing.T) {
+	recorder := initTestTracer(t)
+
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.AutoMigrate(&tracingTestRecord{}))
+	assert.NoError(t, db.Use(NewGormPlugin("sqlite")))
+
+	// statements without parent span are not traced
+	assert.NoError(t, db.Create(&tracingTestRecord{Name: "a"}).Error)
+	assert.Equal(t, 0, len(recorder.Ended()))
+
+	ctx, parent := Start(context.Background(), "parent")
+	tx := db.WithContext(ctx)
+	assert.NoError(t, tx.Create(&tracingTestRecord{Name: "b"}).Error)
+	record := tracingTestRecord{}
+	assert.NoError(t, tx.Where("name = ?", "a").First(&record).Error)
+	// record not found is not an error of span
+	err = tx.Where("name = ?", "c").First(&record).Error
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	parent.End()
+
+	ended := recorder.Ended()
+	assert.Equal(t, 4, len(ended))
+	assert.Equal(t, "gorm.create tracing_test_records", ended[0].Name())
+	assert.Equal(t, "gorm.query tracing_test_records", ended[1].Name())
+	for _, span := range ended[:3] {
+		assert.Equal(t, trace.SpanKindClient, span.SpanKind())
+		assert.NotEqual(t, "Error", span.Status().Code.String())
+		assert.Equal(t, parent.SpanContext().TraceID(), span.SpanContext().TraceID())
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright (c) 2021 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"math/rand"
+	"os"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/http/api"
+)
+
+const (
+	// queueSize bounds the events waiting to be reported, events are dropped when server is too slow
+	queueSize = 10000
+	// batchSize is the most events reported once, which must not exceed the limit of server
+	batchSize = 500
+)
+
+// Reporter sends a batch of events to server
+type Reporter func(events []api.FsAuditEvent) error
+
+// Auditor samples file access events of fuse client and reports them to server in batches
+type Auditor struct {
+	sampleRate float64
+	jobID      string
+	host       string
+	report     Reporter
+	events     chan api.FsAuditEvent
+	dropped    uint64
+}
+
+var auditor *Auditor
+
+// Init enables audit of fuse client, sampleRate in (0, 1] is the ratio of events recorded
+func Init(sampleRate float64, jobID string, report Reporter) *Auditor {
+	host, _ := os.Hostname()
+	auditor = &Auditor{
+		sampleRate: sampleRate,
+		jobID:      jobID,
+		host:       host,
+		report:     report,
+		events:     make(chan api.FsAuditEvent, queueSize),
+	}
+	return auditor
+}
+
+// Record samples the event, path is only resolved for the sampled events since resolving is not free
+func Record(operation string, uid uint32, path func() string) {
+	a := auditor
+	if a == nil || (a.sampleRate < 1 && rand.Float64() >= a.sampleRate) {
+		return
+	}
+	event := api.FsAuditEvent{
+		Operation: operation,
+		Path:      path(),
+		JobID:     a.jobID,
+		Uid:       uid,
+		Host:      a.host,
+		Timestamp: time.Now().Unix(),
+	}
+	select {
+	case a.events <- event:
+	default:
+		atomic.AddUint64(&a.dropped, 1)
+	}
+}
+
+// Run reports events every interval or when a batch is full, the remaining events are reported when stopped
+func (a *Auditor) Run(stopChan <-chan struct{}, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	batch := make([]api.FsAuditEvent, 0, batchSize)
+	flush := func() {
+		if dropped := atomic.SwapUint64(&a.dropped, 0); dropped > 0 {
+			log.Warnf("audit dropped %d events since queue is full", dropped)
+		}
+		if len(batch) == 0 {
+			return
+		}
+		if err := a.report(batch); err != nil {
+			log.Errorf("report %d audit events failed: %v", len(batch), err)
+		}
+		batch = make([]api.FsAuditEvent, 0, batchSize)
+	}
+	for {
+		select {
+		case event := <-a.events:
+			batch = append(batch, event)
+			if len(batch) >= batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-stopChan:
+			for len(a.events) > 0 && len(batch) < batchSize {
+				batch = append(batch, <-a.events)
+			}
+			flush()
+			return
+		}
+	}
+}
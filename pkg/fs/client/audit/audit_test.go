@@ -0,0 +1,72 @@
+/*
+Copyright (c) 2021 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/http/api"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+)
+
+func TestAuditor(t *testing.T) {
+	resolved := 0
+	path := func() string {
+		resolved++
+		return "/a.txt"
+	}
+	// disabled audit records nothing and does not resolve path
+	auditor = nil
+	Record(schema.FsAuditOpOpen, 0, path)
+	assert.Equal(t, 0, resolved)
+
+	reported := make([]api.FsAuditEvent, 0)
+	a := Init(1, "job-1", func(events []api.FsAuditEvent) error {
+		reported = append(reported, events...)
+		return nil
+	})
+	defer func() { auditor = nil }()
+	for i := 0; i < batchSize+1; i++ {
+		Record(schema.FsAuditOpOpen, 1000, path)
+	}
+	assert.Equal(t, batchSize+1, resolved)
+	stopChan := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		a.Run(stopChan, time.Hour)
+		close(done)
+	}()
+	// a full batch is reported without waiting for the interval
+	assert.Eventually(t, func() bool { return len(a.events) == 0 }, time.Second, 10*time.Millisecond)
+	close(stopChan)
+	<-done
+	assert.Equal(t, batchSize+1, len(reported))
+	assert.Equal(t, "job-1", reported[0].JobID)
+	assert.Equal(t, uint32(1000), reported[0].Uid)
+
+	// only part of events are sampled
+	Init(0.1, "", func(events []api.FsAuditEvent) error { return nil })
+	resolved = 0
+	for i := 0; i < 1000; i++ {
+		Record(schema.FsAuditOpUnlink, 0, path)
+	}
+	assert.Greater(t, resolved, 0)
+	assert.Less(t, resolved, 500)
+}
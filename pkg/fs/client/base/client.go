@@ -22,6 +22,7 @@ import (
 
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/http/api"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/http/core"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/common"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/utils"
 )
@@ -38,10 +39,15 @@ type _Client struct {
 }
 
 func NewClient(fsID string, c *core.PaddleFlowClient, token string) (*_Client, error) {
-	fsName, userName, err := utils.GetFsNameAndUserNameByFsID(fsID)
+	baseFsID, snapshot := schema.ParseFsSnapshotID(fsID)
+	fsName, userName, err := utils.GetFsNameAndUserNameByFsID(baseFsID)
 	if err != nil {
 		return nil, err
 	}
+	if snapshot != "" {
+		// server returns the read-only file system of snapshot with name fsName@snapshot
+		fsName = schema.FsSnapshotName(fsName, snapshot)
+	}
 	_client := _Client{
 		Uuid:       uuid.NewString(),
 		FsID:       fsID,
@@ -110,3 +116,20 @@ func (c *_Client) GetLinks() (map[string]common.FSMeta, error) {
 	}
 	return result, nil
 }
+
+// ReportAudit sends the sampled file access events to server
+func (c *_Client) ReportAudit(events []api.FsAuditEvent) error {
+	params := api.FsAuditParams{
+		FsParams: api.FsParams{
+			FsName:   c.FsName,
+			UserName: c.UserName,
+			Token:    c.Token,
+		},
+		Events: events,
+	}
+	if err := api.FsAuditRequest(params, c.httpClient); err != nil {
+		log.Errorf("fs audit request failed: %v", err)
+		return err
+	}
+	return nil
+}
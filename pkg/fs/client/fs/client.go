@@ -92,6 +92,8 @@ type FSClient interface {
 	Chown(name string, uid, gid int) error
 	Walk(root string, walkFn filepath.WalkFunc) error
 	Stat(path string) (os.FileInfo, error)
+	// Close releases the resources of client, client can not be used after closed
+	Close() error
 }
 
 func NewFSClientWithServer(server, fsID string) (FSClient, error) {
@@ -301,7 +301,21 @@ func (f *File) Readdirnames(n int) ([]string, error) {
 // relative to the current offset, and 2 means relative to the end.
 // It returns the new offset and an error, if any.
 func (f *File) Seek(offset int64, whence int) (ret int64, err error) {
-	return -1, syscall.ENOSYS
+	switch whence {
+	case io.SeekStart:
+		ret = offset
+	case io.SeekCurrent:
+		ret = f.readOffset + offset
+	case io.SeekEnd:
+		ret = f.attr.size + offset
+	default:
+		return 0, syscall.EINVAL
+	}
+	if ret < 0 {
+		return 0, syscall.EINVAL
+	}
+	f.readOffset = ret
+	return ret, nil
 }
 
 func (f *File) SetDeadline(t time.Time) error {
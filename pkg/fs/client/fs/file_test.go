@@ -18,10 +18,13 @@ package fs
 
 import (
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"syscall"
 	"testing"
 	"time"
 
@@ -165,6 +168,48 @@ func TestFsStat(t *testing.T) {
 	assert.Equal(t, info2.ModTime(), info.ModTime())
 }
 
+func TestFileSeek(t *testing.T) {
+	os.RemoveAll("./mock")
+	os.RemoveAll("./mock-cache")
+	defer func() {
+		os.RemoveAll("./mock")
+		os.RemoveAll("./mock-cache")
+	}()
+	client, err := newPfsTest()
+	assert.Equal(t, nil, err)
+
+	path := "test_seek"
+	writer, err := client.Create(path, uint32(os.O_RDWR|os.O_CREATE|os.O_TRUNC), 0644)
+	assert.Equal(t, nil, err)
+	_, err = writer.Write([]byte("0123456789"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, writer.Close())
+
+	reader, err := client.Open(path)
+	assert.Equal(t, nil, err)
+	defer reader.Close()
+	off, err := reader.Seek(6, io.SeekStart)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, int64(6), off)
+	buf, err := ioutil.ReadAll(reader)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "6789", string(buf))
+
+	off, err = reader.Seek(-3, io.SeekEnd)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, int64(7), off)
+	off, err = reader.Seek(-2, io.SeekCurrent)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, int64(5), off)
+	buf = make([]byte, 2)
+	n, err := reader.Read(buf)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "56", string(buf[:n]))
+
+	_, err = reader.Seek(-1, io.SeekStart)
+	assert.Equal(t, syscall.EINVAL, err)
+}
+
 func TestFS_Readdir_Expire(t *testing.T) {
 	os.RemoveAll("./mock")
 	os.RemoveAll("./mock-cache")
@@ -20,7 +20,6 @@ import (
 	"os"
 	"path"
 	"path/filepath"
-	"runtime"
 	"sync"
 	"syscall"
 
@@ -39,6 +38,10 @@ type FileSystem struct {
 	vfs    *vfs.VFS
 	stop   chan struct{}
 	cache  *metaCache
+
+	// wg waits for the meta update goroutines to stop before vfs is shutdown
+	wg        sync.WaitGroup
+	closeOnce sync.Once
 }
 
 var collectorOnce sync.Once
@@ -62,7 +65,7 @@ func wrapRegister(fsMeta common.FSMeta) *prometheus.Registry {
 // attrExpire 记录ino对应节点的attr属性，包括mode、uid、gid和mtime等信息，文件修改时会改变。
 func NewFileSystem(fsMeta common.FSMeta, links map[string]common.FSMeta, skipSub bool, hasCache bool,
 	linkMetaDirPrefix string, config *vfs.Config) (*FileSystem, error) {
-	fs := FileSystem{fsMeta: fsMeta, stop: make(chan struct{})}
+	fs := &FileSystem{fsMeta: fsMeta, stop: make(chan struct{})}
 	// todo:: 客户端增加配置，填充到这里
 	// config := &vfs.Config{Cache: cache}
 	registry := wrapRegister(fsMeta)
@@ -72,20 +75,34 @@ func NewFileSystem(fsMeta common.FSMeta, links map[string]common.FSMeta, skipSub
 		return nil, err
 	}
 	fs.vfs = vfs
-	// 释放fs的时候会结束协程
-	runtime.SetFinalizer(&fs, func(fs *FileSystem) {
-		close(fs.stop)
-	})
 	if !skipSub {
+		// 协程在Close时结束
+		fs.wg.Add(2)
 		go func() {
+			defer fs.wg.Done()
 			_ = vfs.Meta.LinksMetaUpdateHandler(fs.stop, meta.DefaultLinkUpdateInterval, linkMetaDirPrefix)
 		}()
+		go func() {
+			defer fs.wg.Done()
+			_ = vfs.Meta.QuotaMetaUpdateHandler(fs.stop, meta.DefaultLinkUpdateInterval, linkMetaDirPrefix)
+		}()
 	}
 
 	if hasCache {
 		fs.setCache(defaultEntryCacheSize, defaultAttrCacheSize, defaultEntryExpire, defaultAttrExpire)
 	}
-	return &fs, nil
+	return fs, nil
+}
+
+// Close stops the meta update goroutines and releases the meta of fs, fs can not be used after closed.
+func (fs *FileSystem) Close() error {
+	var err error
+	fs.closeOnce.Do(func() {
+		close(fs.stop)
+		fs.wg.Wait()
+		err = fs.vfs.Shutdown()
+	})
+	return err
 }
 
 func (fs *FileSystem) setCache(entrySize, attrSize, entryExpire, attrExpire int) {
@@ -268,6 +285,88 @@ func (fs *FileSystem) Unlink(name string) error {
 	return nil
 }
 
+func (fs *FileSystem) Symlink(target string, name string) error {
+	name = path.Clean(name)
+	ctx := meta.NewEmptyContext()
+	_, ino, err := fs.lookup(ctx, path.Dir(name), true)
+	if utils.IsError(err) {
+		return err
+	}
+	_, err = fs.vfs.Symlink(ctx, target, ino, path.Base(name))
+	if utils.IsError(err) {
+		log.Errorf("symlink path[%s] to target[%s] failed: %v", name, target, err)
+		return err
+	}
+	return nil
+}
+
+func (fs *FileSystem) Readlink(name string) (string, error) {
+	name = path.Clean(name)
+	ctx := meta.NewEmptyContext()
+	_, ino, err := fs.lookup(ctx, name, true)
+	if utils.IsError(err) {
+		return "", err
+	}
+	target, err := fs.vfs.Readlink(ctx, ino)
+	if utils.IsError(err) {
+		log.Errorf("readlink path[%s] failed: %v", name, err)
+		return "", err
+	}
+	return string(target), nil
+}
+
+func (fs *FileSystem) Link(oldPath string, newPath string) error {
+	oldPath = path.Clean(oldPath)
+	newPath = path.Clean(newPath)
+	ctx := meta.NewEmptyContext()
+	_, oldIno, err := fs.lookup(ctx, oldPath, true)
+	if utils.IsError(err) {
+		return err
+	}
+	_, newParent, err := fs.lookup(ctx, path.Dir(newPath), true)
+	if utils.IsError(err) {
+		return err
+	}
+	_, err = fs.vfs.Link(ctx, oldIno, newParent, path.Base(newPath))
+	if utils.IsError(err) {
+		log.Errorf("link path[%s] to path[%s] failed: %v", newPath, oldPath, err)
+		return err
+	}
+	if fs.cache != nil {
+		// nlink of the old path is changed
+		fs.cache.RemoveAttrItem(oldIno)
+	}
+	return nil
+}
+
+func (fs *FileSystem) GetXAttr(name string, attr string) ([]byte, error) {
+	name = path.Clean(name)
+	ctx := meta.NewEmptyContext()
+	_, ino, err := fs.lookup(ctx, name, true)
+	if utils.IsError(err) {
+		return nil, err
+	}
+	value, err := fs.vfs.GetXAttr(ctx, ino, attr, 0)
+	if utils.IsError(err) {
+		return nil, err
+	}
+	return value, nil
+}
+
+func (fs *FileSystem) SetXAttr(name string, attr string, value []byte, flags uint32) error {
+	name = path.Clean(name)
+	ctx := meta.NewEmptyContext()
+	_, ino, err := fs.lookup(ctx, name, true)
+	if utils.IsError(err) {
+		return err
+	}
+	err = fs.vfs.SetXAttr(ctx, ino, attr, value, flags)
+	if utils.IsError(err) {
+		return err
+	}
+	return nil
+}
+
 func (fs *FileSystem) Create(name string, flags uint32, mode uint32) (*File, error) {
 	name = path.Clean(name)
 	ctx := meta.NewEmptyContext()
@@ -230,6 +230,10 @@ func (c *MockClient) Walk(root string, walkFn filepath.WalkFunc) error {
 	return nil
 }
 
+func (c *MockClient) Close() error {
+	return nil
+}
+
 func (c *MockClient) Stat(path string) (os.FileInfo, error) {
 	attr, err := os.Stat(filepath.Join(c.pathPrefix, path))
 	if err != nil {
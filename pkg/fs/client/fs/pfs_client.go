@@ -79,6 +79,7 @@ func NewFSClientForTest(fsMeta fsCommon.FSMeta) (*PFSClient, error) {
 				CachePath: MetaCachePath,
 			},
 		}),
+		vfs.WithLocks(true),
 	)
 	pfs, err := NewFileSystem(fsMeta, nil, true, false, "", vfsConfig)
 	if err != nil {
@@ -504,6 +505,13 @@ func (c *PFSClient) walk(path string, info fs.FileInfo, walkFn filepath.WalkFunc
 	return nil
 }
 
+func (c *PFSClient) Close() error {
+	if c.pfs == nil {
+		return nil
+	}
+	return c.pfs.Close()
+}
+
 func (c *PFSClient) Stat(path string) (os.FileInfo, error) {
 	attr, err := c.pfs.Stat(path)
 	if err != nil {
@@ -158,6 +158,62 @@ func TestNewFSClientForTest(t *testing.T) {
 	assert.Equal(t, nil, err)
 }
 
+func TestPFSClientClose(t *testing.T) {
+	os.MkdirAll("./mock", 0755)
+	testFsMeta := common.FSMeta{
+		UfsType: common.LocalType,
+		Properties: map[string]string{
+			common.RootKey: "./mock",
+		},
+		SubPath: "./mock",
+	}
+	client, err := NewPFSClient(testFsMeta, nil)
+	assert.Equal(t, nil, err)
+	_, err = client.Exist("/")
+	assert.Equal(t, nil, err)
+
+	// meta update goroutines stop without waiting for the update interval
+	start := time.Now()
+	assert.Equal(t, nil, client.Close())
+	assert.Less(t, time.Since(start), time.Duration(meta.DefaultLinkUpdateInterval)*time.Second)
+	assert.Equal(t, nil, client.Close())
+}
+
+func TestReadOnlyDirs(t *testing.T) {
+	mockDir := t.TempDir()
+	assert.Equal(t, nil, os.MkdirAll(filepath.Join(mockDir, ".config"), 0755))
+	assert.Equal(t, nil, os.WriteFile(filepath.Join(mockDir, ".config/quota_meta"), []byte("[]"), 0644))
+	client, err := NewFSClientForTest(common.FSMeta{
+		UfsType:    common.LocalType,
+		Properties: map[string]string{common.RootKey: mockDir},
+		SubPath:    mockDir,
+	})
+	assert.Equal(t, nil, err)
+	client.pfs.vfs.SetReadOnlyDirs(".config")
+
+	_, err = client.Create("/.config/new")
+	assert.Equal(t, syscall.EPERM, err)
+	assert.Equal(t, syscall.EPERM, client.Mkdir("/.config/dir", 0755))
+	assert.Equal(t, syscall.EPERM, client.Remove("/.config/quota_meta"))
+	assert.Equal(t, syscall.EPERM, client.Rename("/.config/quota_meta", "/quota_meta"))
+	assert.Equal(t, syscall.EPERM, client.Rename("/.config", "/config"))
+	assert.Equal(t, syscall.EPERM, client.Chmod("/.config/quota_meta", 0777))
+	// snapshots are read only by default
+	assert.Equal(t, syscall.EPERM, client.Mkdir("/.snapshot", 0755))
+
+	reader, err := client.Open("/.config/quota_meta")
+	assert.Equal(t, nil, err)
+	content, err := io.ReadAll(reader)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "[]", string(content))
+	reader.Close()
+
+	// paths out of read only dirs are writable
+	_, err = client.CreateFile("/config", []byte("x"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, client.Rename("/config", "/config2"))
+}
+
 func TestFSClient_bigBuf(t *testing.T) {
 	clean()
 	defer clean()
@@ -1566,7 +1622,124 @@ func TestConcurrentCreateAndDelete(t *testing.T) {
 	wg.Wait()
 }
 
+func TestFSClient_SymlinkAndLink(t *testing.T) {
+	clean()
+	defer clean()
+	SetDataCache(cache.Config{
+		Config: kv.Config{
+			Driver:    kv.MemType,
+			CachePath: "./mock-cache",
+		},
+	})
+	client := getTestFSClient(t)
+	pfs := client.(*PFSClient).pfs
+
+	err := client.Mkdir("dir", 0755)
+	assert.Equal(t, nil, err)
+	_, err = client.CreateFile("dir/file", []byte("content"))
+	assert.Equal(t, nil, err)
+
+	// relative target is kept as it is
+	err = pfs.Symlink("dir/file", "symlink")
+	assert.Equal(t, nil, err)
+	target, err := pfs.Readlink("symlink")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "dir/file", target)
+	info, err := client.Stat("symlink")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, os.ModeSymlink, info.Mode()&os.ModeSymlink)
+	target, err = os.Readlink("./mock/symlink")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "dir/file", target)
+	err = pfs.Symlink("dir/file", "symlink")
+	assert.Equal(t, syscall.EEXIST, err)
+	_, err = pfs.Readlink("dir/file")
+	assert.Equal(t, syscall.EINVAL, err)
+
+	err = pfs.Link("dir/file", "hardlink")
+	assert.Equal(t, nil, err)
+	buf := make([]byte, 100)
+	n, err := openAndRead(client, "hardlink", buf)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "content", string(buf[:n]))
+	st := syscall.Stat_t{}
+	assert.Equal(t, nil, syscall.Stat("./mock/dir/file", &st))
+	assert.Equal(t, uint64(2), uint64(st.Nlink))
+	err = pfs.Link("dir", "dirlink")
+	assert.Equal(t, syscall.EPERM, err)
+
+	// the filesystem of test dir may not support user xattrs
+	err = pfs.SetXAttr("dir/file", "user.pfs", []byte("value"), 0)
+	if err == syscall.ENOTSUP {
+		return
+	}
+	assert.Equal(t, nil, err)
+	value, err := pfs.GetXAttr("dir/file", "user.pfs")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "value", string(value))
+	_, err = pfs.GetXAttr("dir/file", "user.none")
+	assert.Equal(t, syscall.ENODATA, err)
+}
+
 func clean() {
 	_ = os.RemoveAll("./mock")
 	_ = os.RemoveAll("./mock-cache")
 }
+
+func TestQuotaExceeded(t *testing.T) {
+	root, err := ioutil.TempDir("", "pfs_quota")
+	assert.NoError(t, err)
+	defer os.RemoveAll(root)
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, common.LinkMetaDir), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(root, common.LinkMetaDir, common.QuotaMetaFile),
+		[]byte(`[{"path":"/data","maxBytes":10,"maxInodes":2}]`), 0644))
+
+	client, err := NewFSClientForTest(common.FSMeta{
+		UfsType:    common.LocalType,
+		SubPath:    root,
+		Properties: map[string]string{common.RootKey: root},
+	})
+	assert.NoError(t, err)
+	stop := make(chan struct{})
+	close(stop)
+	assert.NoError(t, client.pfs.vfs.Meta.QuotaMetaUpdateHandler(stop, 0, ""))
+	assert.True(t, client.pfs.vfs.Meta.HasQuota())
+
+	assert.NoError(t, client.Mkdir("data", 0755))
+	_, err = client.CreateFile("data/a", []byte("0123456789"))
+	assert.NoError(t, err)
+	_, err = client.CreateFile("data/a2", []byte("0"))
+	assert.Equal(t, syscall.EDQUOT, err)
+	_, err = client.CreateFile("outside", []byte("0123456789"))
+	assert.NoError(t, err)
+	assert.NoError(t, client.Remove("data/a"))
+	_, err = client.CreateFile("data/b", []byte("0123456789012"))
+	assert.Equal(t, syscall.EDQUOT, err)
+}
+
+func TestFlushReleasePosixLocks(t *testing.T) {
+	mockDir := t.TempDir()
+	client, err := NewFSClientForTest(common.FSMeta{
+		UfsType:    common.LocalType,
+		Properties: map[string]string{common.RootKey: mockDir},
+		SubPath:    mockDir,
+	})
+	assert.Equal(t, nil, err)
+	_, err = client.CreateFile("/lock", []byte("x"))
+	assert.Equal(t, nil, err)
+
+	v := client.pfs.vfs
+	ctx := meta.NewEmptyContext()
+	entry, errno := v.Lookup(ctx, 1, "lock")
+	assert.Equal(t, syscall.Errno(0), errno)
+	_, fh1, errno := v.Open(ctx, entry.Ino, syscall.O_RDWR)
+	assert.Equal(t, syscall.Errno(0), errno)
+	_, fh2, errno := v.Open(ctx, entry.Ino, syscall.O_RDONLY)
+	assert.Equal(t, syscall.Errno(0), errno)
+	assert.Equal(t, syscall.Errno(0), v.SetLk(ctx, entry.Ino, fh1, 1, 0, 10, syscall.F_WRLCK, 1, false))
+	assert.Equal(t, syscall.EAGAIN, v.SetLk(ctx, entry.Ino, fh1, 2, 0, 10, syscall.F_WRLCK, 2, false))
+
+	// closing another handle of the owner releases the locks too
+	assert.Equal(t, syscall.Errno(0), v.Flush(ctx, entry.Ino, fh2, 1))
+	assert.Equal(t, syscall.Errno(0), v.SetLk(ctx, entry.Ino, fh1, 2, 0, 10, syscall.F_WRLCK, 2, false))
+}
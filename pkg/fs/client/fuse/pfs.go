@@ -17,11 +17,14 @@ limitations under the License.
 package fuse
 
 import (
+	"path"
 	"time"
 
 	"github.com/hanwen/go-fuse/v2/fuse"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/client/audit"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/client/meta"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/client/vfs"
 )
@@ -123,6 +126,9 @@ func (fs *PFS) Unlink(cancel <-chan struct{}, header *fuse.InHeader, name string
 	log.Infof("pfs POSIX Unlink: header[%+v], name[%s]", *header, name)
 	ctx := meta.NewContext(cancel, header.Uid, header.Pid, header.Gid)
 	code := vfs.GetVFS().Unlink(ctx, vfs.Ino(header.NodeId), name)
+	if code == 0 {
+		auditEntry(schema.FsAuditOpUnlink, header, name)
+	}
 	return fuse.Status(code)
 }
 
@@ -130,6 +136,9 @@ func (fs *PFS) Rmdir(cancel <-chan struct{}, header *fuse.InHeader, name string)
 	log.Infof("pfs POSIX Rmdir: header[%+v] name[%s]", *header, name)
 	ctx := meta.NewContext(cancel, header.Uid, header.Pid, header.Gid)
 	code := vfs.GetVFS().Rmdir(ctx, vfs.Ino(header.NodeId), name)
+	if code == 0 {
+		auditEntry(schema.FsAuditOpRmdir, header, name)
+	}
 	return fuse.Status(code)
 }
 
@@ -141,15 +150,32 @@ func (fs *PFS) Rename(cancel <-chan struct{}, input *fuse.RenameIn, oldName stri
 }
 
 func (fs *PFS) Link(cancel <-chan struct{}, input *fuse.LinkIn, filename string, out *fuse.EntryOut) fuse.Status {
-	return fuse.ENOSYS
+	log.Debugf("pfs POSIX Link: input[%+v] filename[%s]", *input, filename)
+	ctx := meta.NewContext(cancel, input.Uid, input.Pid, input.Gid)
+	entry, code := vfs.GetVFS().Link(ctx, vfs.Ino(input.Oldnodeid), vfs.Ino(input.NodeId), filename)
+	if code != 0 {
+		return fuse.Status(code)
+	}
+	fs.replyEntry(entry, out)
+	return fuse.OK
 }
 
 func (fs *PFS) Symlink(cancel <-chan struct{}, header *fuse.InHeader, pointedTo string, linkName string, out *fuse.EntryOut) fuse.Status {
-	return fuse.ENOSYS
+	log.Debugf("pfs POSIX Symlink: header[%+v] pointedTo[%s] linkName[%s]", *header, pointedTo, linkName)
+	ctx := meta.NewContext(cancel, header.Uid, header.Pid, header.Gid)
+	entry, code := vfs.GetVFS().Symlink(ctx, pointedTo, vfs.Ino(header.NodeId), linkName)
+	if code != 0 {
+		return fuse.Status(code)
+	}
+	fs.replyEntry(entry, out)
+	return fuse.OK
 }
 
 func (fs *PFS) Readlink(cancel <-chan struct{}, header *fuse.InHeader) (out []byte, code fuse.Status) {
-	return out, fuse.ENOSYS
+	log.Debugf("pfs POSIX Readlink: header[%+v]", *header)
+	ctx := meta.NewContext(cancel, header.Uid, header.Pid, header.Gid)
+	out, errno := vfs.GetVFS().Readlink(ctx, vfs.Ino(header.NodeId))
+	return out, fuse.Status(errno)
 }
 
 func (fs *PFS) Access(cancel <-chan struct{}, input *fuse.AccessIn) fuse.Status {
@@ -168,11 +194,12 @@ func (fs *PFS) GetXAttr(cancel <-chan struct{}, header *fuse.InHeader, attr stri
 	log.Debugf("pfs POSIX GetXAttr: header[%+v] attr[%s] dest[%s]", *header, attr, string(dest))
 	ctx := meta.NewContext(cancel, header.Uid, header.Pid, header.Gid)
 	value, code := vfs.GetVFS().GetXAttr(ctx, vfs.Ino(header.NodeId), attr, uint32(len(dest)))
+	// the size of value is needed by ERANGE and the query with empty dest
 	if code != 0 {
-		return 0, fuse.Status(code)
+		return uint32(len(value)), fuse.Status(code)
 	}
 	copy(dest, value)
-	return uint32(len(dest)), fuse.Status(code)
+	return uint32(len(value)), fuse.Status(code)
 }
 
 // ListXAttr lists extended attributes as '\0' delimited byte
@@ -182,11 +209,12 @@ func (fs *PFS) ListXAttr(cancel <-chan struct{}, header *fuse.InHeader, dest []b
 	log.Debugf("pfs POSIX ListXAttr: header[%+v] dest[%s]", *header, string(dest))
 	ctx := meta.NewContext(cancel, header.Uid, header.Pid, header.Gid)
 	value, code := vfs.GetVFS().ListXAttr(ctx, vfs.Ino(header.NodeId), uint32(len(dest)))
+	// the size of value is needed by ERANGE and the query with empty dest
 	if code != 0 {
-		return 0, fuse.Status(code)
+		return uint32(len(value)), fuse.Status(code)
 	}
 	copy(dest, value)
-	return uint32(len(dest)), fuse.Status(code)
+	return uint32(len(value)), fuse.Status(code)
 }
 
 // SetAttr writes an extended attribute.
@@ -215,6 +243,10 @@ func (fs *PFS) Create(cancel <-chan struct{}, input *fuse.CreateIn, name string,
 	}
 	out.Fh = fh
 	fs.replyEntry(entry, &out.EntryOut)
+	// open with O_CREAT comes as create, which is audited as open too
+	if !vfs.IsSpecialNode(entry.Ino) {
+		auditEntry(schema.FsAuditOpOpen, &input.InHeader, name)
+	}
 	log.Debugf("pfs POSIX Create out %+v", *out)
 	return fuse.Status(code)
 }
@@ -229,6 +261,10 @@ func (fs *PFS) Open(cancel <-chan struct{}, input *fuse.OpenIn, out *fuse.OpenOu
 	out.Fh = fh
 	if vfs.IsSpecialNode(meta.Ino(input.NodeId)) {
 		out.OpenFlags |= fuse.FOPEN_DIRECT_IO
+	} else {
+		audit.Record(schema.FsAuditOpOpen, input.Uid, func() string {
+			return vfs.GetVFS().Meta.InoToPath(meta.Ino(input.NodeId))
+		})
 	}
 	log.Debugf("pfs POSIX Open out %+v", *out)
 	return fuse.Status(code)
@@ -246,15 +282,36 @@ func (fs *PFS) Read(cancel <-chan struct{}, input *fuse.ReadIn, buf []byte) (fus
 
 // File locking
 func (fs *PFS) GetLk(cancel <-chan struct{}, input *fuse.LkIn, out *fuse.LkOut) (code fuse.Status) {
-	return fuse.ENOSYS
+	log.Debugf("pfs POSIX GetLk: input[%+v]", *input)
+	ctx := meta.NewContext(cancel, input.Uid, input.Pid, input.Gid)
+	lk := input.Lk
+	errno := vfs.GetVFS().GetLk(ctx, vfs.Ino(input.NodeId), input.Fh, input.Owner, &lk.Start, &lk.End, &lk.Typ, &lk.Pid)
+	if errno == 0 {
+		out.Lk = lk
+	}
+	return fuse.Status(errno)
 }
 
 func (fs *PFS) SetLk(cancel <-chan struct{}, input *fuse.LkIn) (code fuse.Status) {
-	return fuse.ENOSYS
+	log.Debugf("pfs POSIX SetLk: input[%+v]", *input)
+	return fs.setLk(cancel, input, false)
 }
 
 func (fs *PFS) SetLkw(cancel <-chan struct{}, input *fuse.LkIn) (code fuse.Status) {
-	return fuse.ENOSYS
+	log.Debugf("pfs POSIX SetLkw: input[%+v]", *input)
+	return fs.setLk(cancel, input, true)
+}
+
+// setLk sets BSD lock if the request comes from flock, otherwise sets POSIX range lock
+func (fs *PFS) setLk(cancel <-chan struct{}, input *fuse.LkIn, block bool) fuse.Status {
+	ctx := meta.NewContext(cancel, input.Uid, input.Pid, input.Gid)
+	if input.LkFlags&fuse.FUSE_LK_FLOCK != 0 {
+		errno := vfs.GetVFS().Flock(ctx, vfs.Ino(input.NodeId), input.Fh, input.Owner, input.Lk.Typ, block)
+		return fuse.Status(errno)
+	}
+	errno := vfs.GetVFS().SetLk(ctx, vfs.Ino(input.NodeId), input.Fh, input.Owner, input.Lk.Start, input.Lk.End,
+		input.Lk.Typ, input.Lk.Pid, block)
+	return fuse.Status(errno)
 }
 
 func (fs *PFS) Write(cancel <-chan struct{}, input *fuse.WriteIn, data []byte) (uint32, fuse.Status) {
@@ -376,6 +433,13 @@ func (fs *PFS) StatFs(cancel <-chan struct{}, input *fuse.InHeader, out *fuse.St
 	return fuse.OK
 }
 
+// auditEntry records the operation on entry name under directory header.NodeId
+func auditEntry(operation string, header *fuse.InHeader, name string) {
+	audit.Record(operation, header.Uid, func() string {
+		return path.Join(vfs.GetVFS().Meta.InoToPath(meta.Ino(header.NodeId)), name)
+	})
+}
+
 func Server(mountpoint string, opt fuse.MountOptions) (*fuse.Server, error) {
 	pfs := NewPaddleFlowFileSystem(false)
 	opt.SingleThreaded = false
@@ -36,4 +36,5 @@ type KvTxn interface {
 type KvClient interface {
 	Name() string
 	Txn(f func(KvTxn) error) error
+	Close() error
 }
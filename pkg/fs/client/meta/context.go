@@ -46,3 +46,16 @@ func NewEmptyContext() *Context {
 	ctx.Gid = uint32(os.Getgid())
 	return ctx
 }
+
+// Canceled returns whether the request is interrupted
+func (c *Context) Canceled() bool {
+	if c.cancel == nil {
+		return false
+	}
+	select {
+	case <-c.cancel:
+		return true
+	default:
+		return false
+	}
+}
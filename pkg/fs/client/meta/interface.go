@@ -40,6 +40,11 @@ const (
 	FATTR_CTIME     = (1 << 10)
 )
 
+const (
+	FALLOC_FL_KEEP_SIZE  = 0x01 // size of file is not changed by fallocate
+	FALLOC_FL_PUNCH_HOLE = 0x02 // deallocates the range, must be used with FALLOC_FL_KEEP_SIZE
+)
+
 const (
 	TypeFile      = 1 // type for regular file
 	TypeDirectory = 2 // type for directory
@@ -98,6 +103,8 @@ type Meta interface {
 
 	// Name of database
 	Name() string
+	// Shutdown releases the database of meta, and meta can not be used after shutdown.
+	Shutdown() error
 	InoToPath(inode Ino) string
 
 	SetOwner(uid, gid uint32)
@@ -137,7 +144,7 @@ type Meta interface {
 	// The targeted entry will be overwrited if it's a file or empty directory.
 	Rename(ctx *Context, parentSrc Ino, nameSrc string, parentDst Ino, nameDst string, flags uint32, inode *Ino, attr *Attr) (string, string, syscall.Errno)
 	// Link creates an entry for node.
-	Link(ctx *Context, inodeSrc, parent Ino, name string, attr *Attr) syscall.Errno
+	Link(ctx *Context, inodeSrc, parent Ino, name string, inode *Ino, attr *Attr) syscall.Errno
 	// Readdir returns all entries for given directory, which include attributes if plus is true.
 	Readdir(ctx *Context, inode Ino, entries *[]*Entry) syscall.Errno
 	// Create creates a file in a directory with given name.
@@ -169,7 +176,13 @@ type Meta interface {
 	// Setlk sets a file range lock on given file.
 	Setlk(ctx *Context, inode Ino, owner uint64, block bool, ltype uint32, start, end uint64, pid uint32) syscall.Errno
 
+	// HasQuota returns whether any quota is set on the file system.
+	HasQuota() bool
+	// CheckQuota returns EDQUOT if the quotas covering inode are exceeded after adding space bytes and inodes.
+	CheckQuota(ctx *Context, inode Ino, space, inodes int64) syscall.Errno
+
 	LinksMetaUpdateHandler(stopChan chan struct{}, interval int, linkMetaDirPrefix string) error
+	QuotaMetaUpdateHandler(stopChan chan struct{}, interval int, linkMetaDirPrefix string) error
 }
 
 func (a *Attr) IsDir() bool {
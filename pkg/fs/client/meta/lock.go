@@ -0,0 +1,333 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package meta
+
+import (
+	"sort"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/client/kv"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/client/utils"
+)
+
+// Locks are kept in the kv of meta. The kv drivers are all local badger(mem or disk), which is owned by one
+// client, so the locks are only seen by the processes using the same mount, and processes on other mounts of
+// the fs are not excluded by them. Sharing locks between clients needs a shared kv driver, which meta does not
+// support yet. The locks are read and written only in kv transactions, so they are shared once such driver is added,
+// and newSession keeps the locks of other sessions in it.
+
+const (
+	nextSessionKey = "nextSession"
+	// flockSize is the size of sid, owner and type of flock
+	flockSize = 17
+	// plockSize is the size of type, pid, start and end of posix lock range
+	plockSize = 24
+	// lockWaitMax is the max interval of retrying to get a blocking lock
+	lockWaitMax = time.Second
+)
+
+// lockOwner identifies the owner of lock, sid is the session of meta which got the lock, and owner is the
+// lock owner passed by fuse.
+type lockOwner struct {
+	sid   uint64
+	owner uint64
+}
+
+// plockRecord is a posix lock on range [start, end] of file
+type plockRecord struct {
+	ltype uint32
+	pid   uint32
+	start uint64
+	end   uint64
+}
+
+func (m *kvMeta) flockKey(ino Ino) []byte {
+	return m.fmtKey("F", ino)
+}
+
+func (m *kvMeta) plockKey(ino Ino) []byte {
+	return m.fmtKey("L", ino)
+}
+
+// newSession gets the id of session, which is unique in the kv.
+// The local kv is only used by this client, so the locks left by previous sessions are stale and removed.
+func (m *kvMeta) newSession(driver string) error {
+	return m.txn(func(tx kv.KvTxn) error {
+		m.sid = uint64(tx.IncrBy(m.counterKey(nextSessionKey), 1))
+		if driver != kv.MemType && driver != kv.DiskType {
+			return nil
+		}
+		for _, prefix := range []string{"F", "L"} {
+			stale, err := tx.ScanValues([]byte(prefix))
+			if err != nil {
+				return err
+			}
+			for key := range stale {
+				if err = tx.Dels([]byte(key)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func parseFlocks(buf []byte) map[lockOwner]byte {
+	locks := make(map[lockOwner]byte)
+	rb := utils.FromBuffer(buf)
+	for rb.Left() >= flockSize {
+		owner := lockOwner{sid: rb.Get64(), owner: rb.Get64()}
+		locks[owner] = rb.Get8()
+	}
+	return locks
+}
+
+func marshalFlocks(locks map[lockOwner]byte) []byte {
+	w := utils.NewBuffer(uint32(len(locks) * flockSize))
+	for owner, ltype := range locks {
+		w.Put64(owner.sid)
+		w.Put64(owner.owner)
+		w.Put8(ltype)
+	}
+	return w.Bytes()
+}
+
+func parsePlocks(buf []byte) map[lockOwner][]plockRecord {
+	locks := make(map[lockOwner][]plockRecord)
+	rb := utils.FromBuffer(buf)
+	for rb.Left() >= 20 {
+		owner := lockOwner{sid: rb.Get64(), owner: rb.Get64()}
+		count := int(rb.Get32())
+		records := make([]plockRecord, 0, count)
+		for i := 0; i < count && rb.Left() >= plockSize; i++ {
+			records = append(records, plockRecord{
+				ltype: rb.Get32(),
+				pid:   rb.Get32(),
+				start: rb.Get64(),
+				end:   rb.Get64(),
+			})
+		}
+		locks[owner] = records
+	}
+	return locks
+}
+
+func marshalPlocks(locks map[lockOwner][]plockRecord) []byte {
+	size := 0
+	for _, records := range locks {
+		size += 20 + len(records)*plockSize
+	}
+	w := utils.NewBuffer(uint32(size))
+	for owner, records := range locks {
+		w.Put64(owner.sid)
+		w.Put64(owner.owner)
+		w.Put32(uint32(len(records)))
+		for _, r := range records {
+			w.Put32(r.ltype)
+			w.Put32(r.pid)
+			w.Put64(r.start)
+			w.Put64(r.end)
+		}
+	}
+	return w.Bytes()
+}
+
+// updatePlocks sets the range of lock to its type, and the overlapped parts of old locks are replaced.
+// Unlocking a range splits the old locks, and the adjacent locks of the same type are merged.
+func updatePlocks(records []plockRecord, lock plockRecord) []plockRecord {
+	result := make([]plockRecord, 0, len(records)+2)
+	for _, r := range records {
+		if r.end < lock.start || r.start > lock.end {
+			result = append(result, r)
+			continue
+		}
+		if r.start < lock.start {
+			left := r
+			left.end = lock.start - 1
+			result = append(result, left)
+		}
+		if r.end > lock.end {
+			right := r
+			right.start = lock.end + 1
+			result = append(result, right)
+		}
+	}
+	if lock.ltype != syscall.F_UNLCK {
+		result = append(result, lock)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].start < result[j].start
+	})
+	merged := make([]plockRecord, 0, len(result))
+	for _, r := range result {
+		if n := len(merged); n > 0 {
+			last := &merged[n-1]
+			if last.ltype == r.ltype && last.pid == r.pid && last.end+1 == r.start {
+				last.end = r.end
+				continue
+			}
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// plockConflict returns whether the lock is conflicted with the lock of other owner
+func plockConflict(r, lock plockRecord) bool {
+	if r.end < lock.start || r.start > lock.end {
+		return false
+	}
+	return r.ltype == syscall.F_WRLCK || lock.ltype == syscall.F_WRLCK
+}
+
+// waitLock retries f until the lock is got or the request is interrupted, if block is false, f runs only once
+func (m *kvMeta) waitLock(ctx *Context, block bool, f func() syscall.Errno) syscall.Errno {
+	wait := time.Millisecond
+	for {
+		err := f()
+		if err != syscall.EAGAIN || !block {
+			return err
+		}
+		if ctx.Canceled() {
+			return syscall.EINTR
+		}
+		time.Sleep(wait)
+		if wait *= 2; wait > lockWaitMax {
+			wait = lockWaitMax
+		}
+	}
+}
+
+func (m *kvMeta) Flock(ctx *Context, inode Ino, owner uint64, ltype uint32, block bool) syscall.Errno {
+	switch ltype {
+	case syscall.F_UNLCK, syscall.F_RDLCK, syscall.F_WRLCK:
+	default:
+		return syscall.EINVAL
+	}
+	self := lockOwner{sid: m.sid, owner: owner}
+	return m.waitLock(ctx, block && ltype != syscall.F_UNLCK, func() syscall.Errno {
+		err := m.txn(func(tx kv.KvTxn) error {
+			locks := parseFlocks(tx.Get(m.flockKey(inode)))
+			if ltype == syscall.F_UNLCK {
+				if _, ok := locks[self]; !ok {
+					return nil
+				}
+				delete(locks, self)
+				if len(locks) == 0 {
+					return tx.Dels(m.flockKey(inode))
+				}
+				return tx.Set(m.flockKey(inode), marshalFlocks(locks))
+			}
+			for o, t := range locks {
+				if o == self {
+					continue
+				}
+				if ltype == syscall.F_WRLCK || t == 'W' {
+					return syscall.EAGAIN
+				}
+			}
+			locks[self] = 'R'
+			if ltype == syscall.F_WRLCK {
+				locks[self] = 'W'
+			}
+			return tx.Set(m.flockKey(inode), marshalFlocks(locks))
+		})
+		log.Debugf("flock inode[%d] owner[%d] type[%d] block[%v]: %v", inode, owner, ltype, block, err)
+		return utils.ToSyscallErrno(err)
+	})
+}
+
+func (m *kvMeta) Getlk(ctx *Context, inode Ino, owner uint64, ltype *uint32, start, end *uint64, pid *uint32) syscall.Errno {
+	if *ltype == syscall.F_UNLCK {
+		*start, *end, *pid = 0, 0, 0
+		return syscall.F_OK
+	}
+	buf, err := m.get(m.plockKey(inode))
+	if err != nil {
+		return utils.ToSyscallErrno(err)
+	}
+	self := lockOwner{sid: m.sid, owner: owner}
+	lock := plockRecord{ltype: *ltype, start: *start, end: *end}
+	for o, records := range parsePlocks(buf) {
+		if o == self {
+			continue
+		}
+		for _, r := range records {
+			if !plockConflict(r, lock) {
+				continue
+			}
+			*ltype, *start, *end = r.ltype, r.start, r.end
+			// the pid of lock on other client is meaningless to local process
+			*pid = 0
+			if o.sid == m.sid {
+				*pid = r.pid
+			}
+			return syscall.F_OK
+		}
+	}
+	*ltype, *start, *end, *pid = syscall.F_UNLCK, 0, 0, 0
+	return syscall.F_OK
+}
+
+func (m *kvMeta) Setlk(ctx *Context, inode Ino, owner uint64, block bool, ltype uint32, start, end uint64, pid uint32) syscall.Errno {
+	switch ltype {
+	case syscall.F_UNLCK, syscall.F_RDLCK, syscall.F_WRLCK:
+	default:
+		return syscall.EINVAL
+	}
+	if start > end {
+		return syscall.EINVAL
+	}
+	self := lockOwner{sid: m.sid, owner: owner}
+	lock := plockRecord{ltype: ltype, pid: pid, start: start, end: end}
+	return m.waitLock(ctx, block && ltype != syscall.F_UNLCK, func() syscall.Errno {
+		err := m.txn(func(tx kv.KvTxn) error {
+			locks := parsePlocks(tx.Get(m.plockKey(inode)))
+			if ltype == syscall.F_UNLCK {
+				if _, ok := locks[self]; !ok {
+					return nil
+				}
+			} else {
+				for o, records := range locks {
+					if o == self {
+						continue
+					}
+					for _, r := range records {
+						if plockConflict(r, lock) {
+							return syscall.EAGAIN
+						}
+					}
+				}
+			}
+			if records := updatePlocks(locks[self], lock); len(records) != 0 {
+				locks[self] = records
+			} else {
+				delete(locks, self)
+			}
+			if len(locks) == 0 {
+				return tx.Dels(m.plockKey(inode))
+			}
+			return tx.Set(m.plockKey(inode), marshalPlocks(locks))
+		})
+		log.Debugf("setlk inode[%d] owner[%d] type[%d] range[%d, %d] block[%v]: %v",
+			inode, owner, ltype, start, end, block, err)
+		return utils.ToSyscallErrno(err)
+	})
+}
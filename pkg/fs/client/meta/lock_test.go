@@ -0,0 +1,125 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package meta
+
+import (
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/client/kv"
+)
+
+func newLockTestMetas(t *testing.T) (*kvMeta, *kvMeta) {
+	client, err := kv.NewBadgerClient(kv.Config{Driver: kv.MemType})
+	assert.Equal(t, nil, err)
+	m1, m2 := &kvMeta{client: client}, &kvMeta{client: client}
+	assert.Equal(t, nil, m1.newSession(kv.MemType))
+	assert.Equal(t, nil, m2.newSession(""))
+	assert.NotEqual(t, m1.sid, m2.sid)
+	return m1, m2
+}
+
+func TestUpdatePlocks(t *testing.T) {
+	var records []plockRecord
+	records = updatePlocks(records, plockRecord{ltype: syscall.F_WRLCK, pid: 1, start: 0, end: 99})
+	records = updatePlocks(records, plockRecord{ltype: syscall.F_UNLCK, start: 10, end: 19})
+	assert.Equal(t, []plockRecord{
+		{ltype: syscall.F_WRLCK, pid: 1, start: 0, end: 9},
+		{ltype: syscall.F_WRLCK, pid: 1, start: 20, end: 99},
+	}, records)
+
+	records = updatePlocks(records, plockRecord{ltype: syscall.F_WRLCK, pid: 1, start: 10, end: 19})
+	assert.Equal(t, []plockRecord{{ltype: syscall.F_WRLCK, pid: 1, start: 0, end: 99}}, records)
+
+	records = updatePlocks(records, plockRecord{ltype: syscall.F_RDLCK, pid: 1, start: 50, end: 199})
+	assert.Equal(t, []plockRecord{
+		{ltype: syscall.F_WRLCK, pid: 1, start: 0, end: 49},
+		{ltype: syscall.F_RDLCK, pid: 1, start: 50, end: 199},
+	}, records)
+
+	records = updatePlocks(records, plockRecord{ltype: syscall.F_UNLCK, start: 0, end: 1000})
+	assert.Equal(t, 0, len(records))
+
+	locks := map[lockOwner][]plockRecord{
+		{sid: 1, owner: 2}: {{ltype: syscall.F_RDLCK, pid: 3, start: 4, end: 5}},
+	}
+	assert.Equal(t, locks, parsePlocks(marshalPlocks(locks)))
+}
+
+func TestFlock(t *testing.T) {
+	m1, m2 := newLockTestMetas(t)
+	ctx := NewEmptyContext()
+	ino := Ino(10)
+
+	assert.Equal(t, syscall.Errno(0), m1.Flock(ctx, ino, 1, syscall.F_RDLCK, false))
+	assert.Equal(t, syscall.Errno(0), m2.Flock(ctx, ino, 1, syscall.F_RDLCK, false))
+	assert.Equal(t, syscall.EAGAIN, m2.Flock(ctx, ino, 1, syscall.F_WRLCK, false))
+	assert.Equal(t, syscall.Errno(0), m1.Flock(ctx, ino, 1, syscall.F_UNLCK, false))
+	assert.Equal(t, syscall.Errno(0), m2.Flock(ctx, ino, 1, syscall.F_WRLCK, false))
+	assert.Equal(t, syscall.EAGAIN, m1.Flock(ctx, ino, 2, syscall.F_RDLCK, false))
+	assert.Equal(t, syscall.EINVAL, m1.Flock(ctx, ino, 1, 100, false))
+
+	cancel := make(chan struct{})
+	close(cancel)
+	assert.Equal(t, syscall.EINTR, m1.Flock(NewContext(cancel, 0, 0, 0), ino, 1, syscall.F_WRLCK, true))
+
+	done := make(chan syscall.Errno)
+	go func() {
+		done <- m1.Flock(ctx, ino, 1, syscall.F_WRLCK, true)
+	}()
+	assert.Equal(t, syscall.Errno(0), m2.Flock(ctx, ino, 1, syscall.F_UNLCK, false))
+	assert.Equal(t, syscall.Errno(0), <-done)
+}
+
+func TestPlock(t *testing.T) {
+	m1, m2 := newLockTestMetas(t)
+	ctx := NewEmptyContext()
+	ino := Ino(10)
+
+	assert.Equal(t, syscall.Errno(0), m1.Setlk(ctx, ino, 1, false, syscall.F_WRLCK, 0, 99, 10))
+	assert.Equal(t, syscall.Errno(0), m2.Setlk(ctx, ino, 1, false, syscall.F_WRLCK, 100, 199, 20))
+	assert.Equal(t, syscall.EAGAIN, m2.Setlk(ctx, ino, 1, false, syscall.F_RDLCK, 50, 149, 20))
+	assert.Equal(t, syscall.EINVAL, m2.Setlk(ctx, ino, 1, false, syscall.F_RDLCK, 10, 9, 20))
+
+	ltype, start, end, pid := uint32(syscall.F_RDLCK), uint64(50), uint64(149), uint32(20)
+	assert.Equal(t, syscall.Errno(0), m2.Getlk(ctx, ino, 1, &ltype, &start, &end, &pid))
+	assert.Equal(t, uint32(syscall.F_WRLCK), ltype)
+	assert.Equal(t, uint64(0), start)
+	assert.Equal(t, uint64(99), end)
+	assert.Equal(t, uint32(0), pid)
+
+	// another owner in the same session sees the pid of the holder
+	ltype, start, end = syscall.F_WRLCK, 0, 10
+	assert.Equal(t, syscall.Errno(0), m1.Getlk(ctx, ino, 2, &ltype, &start, &end, &pid))
+	assert.Equal(t, uint32(10), pid)
+
+	assert.Equal(t, syscall.Errno(0), m1.Setlk(ctx, ino, 1, false, syscall.F_UNLCK, 50, 99, 10))
+	assert.Equal(t, syscall.Errno(0), m2.Setlk(ctx, ino, 1, false, syscall.F_RDLCK, 50, 149, 20))
+
+	ltype, start, end = syscall.F_WRLCK, 0, 199
+	assert.Equal(t, syscall.Errno(0), m1.Getlk(ctx, ino, 1, &ltype, &start, &end, &pid))
+	assert.Equal(t, uint32(syscall.F_RDLCK), ltype)
+	assert.Equal(t, uint64(50), start)
+
+	assert.Equal(t, syscall.Errno(0), m1.Setlk(ctx, ino, 1, false, syscall.F_UNLCK, 0, 199, 10))
+	assert.Equal(t, syscall.Errno(0), m2.Setlk(ctx, ino, 1, false, syscall.F_UNLCK, 0, 199, 20))
+	buf, err := m1.get(m1.plockKey(ino))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(buf))
+}
@@ -99,6 +99,11 @@ type kvMeta struct {
 
 	pathCache   *ristretto.Cache
 	pathTimeOut time.Duration
+
+	// sid is the session id of meta in kv, locks are local to the client since kv is not shared by clients
+	sid uint64
+
+	quota quotaSet
 }
 
 type entryItem struct {
@@ -132,7 +137,10 @@ func newKvMeta(fsMeta common.FSMeta, links map[string]common.FSMeta, config Conf
 		attrTimeOut:  config.AttrCacheExpire,
 		entryTimeOut: config.EntryCacheExpire,
 	}
-	ufs, err := newUFS(fsMeta)
+	if err = m.newSession(config.Driver); err != nil {
+		return nil, err
+	}
+	ufs, err := NewUFS(fsMeta)
 	if err != nil {
 		return nil, err
 	}
@@ -157,10 +165,17 @@ func newKvMeta(fsMeta common.FSMeta, links map[string]common.FSMeta, config Conf
 	return m, nil
 }
 
+func (m *kvMeta) Shutdown() error {
+	if m.pathCache != nil {
+		m.pathCache.Close()
+	}
+	return m.client.Close()
+}
+
 func (m *kvMeta) UpdateUFSMap(fsMetas map[string]common.FSMeta) error {
 	var ufsMap sync.Map
 	for key, value := range fsMetas {
-		linkUfs, err := newUFS(value)
+		linkUfs, err := NewUFS(value)
 		if err != nil {
 			log.Errorf("new ufs for fsMeta[%+v] failed: %v", value, err)
 			return err
@@ -173,7 +188,8 @@ func (m *kvMeta) UpdateUFSMap(fsMetas map[string]common.FSMeta) error {
 	return nil
 }
 
-func newUFS(fsMeta common.FSMeta) (ufslib.UnderFileStorage, error) {
+// NewUFS creates the under file storage of fs meta
+func NewUFS(fsMeta common.FSMeta) (ufslib.UnderFileStorage, error) {
 	log.Debugf("begin to new UFS: fsMeta[%+v]", fsMeta)
 	properties := make(map[string]interface{})
 	for k, v := range fsMeta.Properties {
@@ -566,7 +582,11 @@ func (m *kvMeta) SetOwner(uid, gid uint32) {
 func (m *kvMeta) StatFS(ctx *Context) (*base.StatfsOut, syscall.Errno) {
 	log.Debugf("defaultMeta, StatFs: name[%s]", DefaultRootPath)
 	ufs_ := m.defaultUfs
-	return ufs_.StatFs(DefaultRootPath), syscall.F_OK
+	statfs := ufs_.StatFs(DefaultRootPath)
+	if statfs != nil && m.HasQuota() {
+		m.statQuota(statfs)
+	}
+	return statfs, syscall.F_OK
 }
 
 func (m *kvMeta) Access(ctx *Context, inode Ino, mask uint32, attr *Attr) syscall.Errno {
@@ -638,11 +658,7 @@ func (m *kvMeta) Lookup(ctx *Context, parent Ino, name string) (Ino, *Attr, sysc
 		} else {
 			attr.Uid = uint32(FuseConf.Uid)
 			attr.Gid = uint32(FuseConf.Gid)
-			if attr.Type == TypeDirectory {
-				attr.Mode = syscall.S_IFDIR | uint32(FuseConf.DirMode)
-			} else {
-				attr.Mode = syscall.S_IFREG | uint32(FuseConf.FileMode)
-			}
+			attr.Mode = defaultMode(attr.Type)
 		}
 
 		if entry == nil {
@@ -680,6 +696,18 @@ func (m *kvMeta) Resolve(ctx *Context, parent Ino, path string, inode *Ino, attr
 
 var rootTime = time.Now()
 
+// defaultMode returns the mode of node whose mode is not set by user, symlinks are always 0777 as posix.
+func defaultMode(_type uint8) uint32 {
+	switch _type {
+	case TypeDirectory:
+		return syscall.S_IFDIR | uint32(FuseConf.DirMode)
+	case TypeSymlink:
+		return syscall.S_IFLNK | 0777
+	default:
+		return syscall.S_IFREG | uint32(FuseConf.FileMode)
+	}
+}
+
 func (m *kvMeta) GetAttr(ctx *Context, inode Ino, attr *Attr) syscall.Errno {
 	log.Debugf("kv GetAttr inode[%v]", inode)
 	inodeItem_ := &inodeItem{}
@@ -746,11 +774,7 @@ func (m *kvMeta) GetAttr(ctx *Context, inode Ino, attr *Attr) syscall.Errno {
 		} else {
 			attr.Uid = uint32(FuseConf.Uid)
 			attr.Gid = uint32(FuseConf.Gid)
-			if attr.Type == TypeDirectory {
-				attr.Mode = syscall.S_IFDIR | uint32(FuseConf.DirMode)
-			} else {
-				attr.Mode = syscall.S_IFREG | uint32(FuseConf.FileMode)
-			}
+			attr.Mode = defaultMode(attr.Type)
 		}
 
 		m.modifyTime(&(inodeItem_.attr), attr)
@@ -781,6 +805,7 @@ func (m *kvMeta) SetAttr(ctx *Context, inode Ino, set uint32, attr *Attr) (strin
 	ctime := attr.Ctime
 	ctimensec := attr.Ctimensec
 	size := attr.Size
+	var growth int64
 	err := m.txn(func(tx kv.KvTxn) error {
 		absolutePath = m.absolutePath(inode, tx)
 		ufs_, isLink, prefix, path = m.GetUFS(absolutePath)
@@ -793,11 +818,7 @@ func (m *kvMeta) SetAttr(ctx *Context, inode Ino, set uint32, attr *Attr) (strin
 				ufsAttr.FixLinkPrefix(prefix)
 			}
 			attr.FromFileInfo(ufsAttr)
-			if attr.Type == TypeDirectory {
-				attr.Mode = syscall.S_IFDIR | uint32(FuseConf.DirMode)
-			} else {
-				attr.Mode = syscall.S_IFREG | uint32(FuseConf.FileMode)
-			}
+			attr.Mode = defaultMode(attr.Type)
 			cur.attr = *attr
 		} else {
 			*attr = cur.attr
@@ -820,6 +841,9 @@ func (m *kvMeta) SetAttr(ctx *Context, inode Ino, set uint32, attr *Attr) (strin
 		}
 		if set&FATTR_SIZE != 0 {
 			log.Debugf("set size %+v size %+v", set, size)
+			if cur.attr.Type == TypeFile {
+				growth = int64(size) - int64(cur.attr.Size)
+			}
 			cur.attr.Size = size
 		}
 		log.Debugf("set attr info is inode[%v] %+v", inode, cur.attr)
@@ -853,6 +877,7 @@ func (m *kvMeta) SetAttr(ctx *Context, inode Ino, set uint32, attr *Attr) (strin
 		return "", utils.ToSyscallErrno(err)
 	}
 	m.setPathCache(inode, &cur)
+	m.updateQuota(absolutePath, growth, 0)
 	return absolutePath, syscall.F_OK
 }
 
@@ -860,16 +885,175 @@ func (m *kvMeta) Truncate(ctx *Context, inode Ino, size uint64) syscall.Errno {
 	return syscall.F_OK
 }
 
+// Fallocate only updates the size of inode, the space is allocated by the file handle of ufs.
 func (m *kvMeta) Fallocate(ctx *Context, inode Ino, mode uint8, off uint64, size uint64) syscall.Errno {
-	return syscall.ENOSYS
+	log.Debugf("kv meta fallocate inode[%v] mode[%d] off[%d] size[%d]", inode, mode, off, size)
+	if mode&FALLOC_FL_KEEP_SIZE != 0 {
+		return syscall.F_OK
+	}
+	var path string
+	var growth int64
+	err := m.txn(func(tx kv.KvTxn) error {
+		buf := tx.Get(m.inodeKey(inode))
+		if buf == nil {
+			return syscall.ENOENT
+		}
+		var item inodeItem
+		m.parseInode(buf, &item)
+		if item.attr.Type != TypeFile {
+			return syscall.EPERM
+		}
+		if off+size <= item.attr.Size {
+			return nil
+		}
+		if m.HasQuota() {
+			growth = int64(off + size - item.attr.Size)
+			path = m.absolutePath(inode, tx)
+		}
+		now := time.Now()
+		item.attr.Size = off + size
+		item.attr.Mtime = now.Unix()
+		item.attr.Mtimensec = uint32(now.Nanosecond())
+		item.attr.Ctime = now.Unix()
+		item.attr.Ctimensec = uint32(now.Nanosecond())
+		return tx.Set(m.inodeKey(inode), m.marshalInode(&item))
+	})
+	if err == nil {
+		m.updateQuota(path, growth, 0)
+	}
+	return utils.ToSyscallErrno(err)
 }
 
 func (m *kvMeta) ReadLink(ctx *Context, inode Ino, path *[]byte) syscall.Errno {
-	return syscall.ENOSYS
+	log.Debugf("kv meta readlink inode[%v]", inode)
+	attr := &Attr{}
+	if errno := m.GetAttr(ctx, inode, attr); errno != syscall.F_OK {
+		return errno
+	}
+	if attr.Type != TypeSymlink {
+		return syscall.EINVAL
+	}
+	ufs_, ufsPath, err := m.ufsPath(inode)
+	if err != nil {
+		return utils.ToSyscallErrno(err)
+	}
+	target, err := ufs_.Readlink(ufsPath)
+	if err != nil {
+		log.Errorf("kv meta readlink inode[%v] path[%s] err %v", inode, ufsPath, err)
+		return utils.ToSyscallErrno(err)
+	}
+	*path = []byte(target)
+	return syscall.F_OK
 }
 
 func (m *kvMeta) Symlink(ctx *Context, parent Ino, name string, path string, inode *Ino, attr *Attr) syscall.Errno {
-	return syscall.ENOSYS
+	log.Debugf("kv meta symlink parent[%v] name[%s] target[%s]", parent, name, path)
+	if attr == nil {
+		attr = &Attr{}
+	}
+	now := time.Now()
+	attr.Type = TypeSymlink
+	attr.Uid = ctx.Uid
+	attr.Gid = ctx.Gid
+	attr.Mode = syscall.S_IFLNK | 0777
+	attr.Nlink = 1
+	attr.Size = uint64(len(path))
+	attr.Atime = now.Unix()
+	attr.Atimensec = uint32(now.Nanosecond())
+	attr.Mtime = now.Unix()
+	attr.Mtimensec = uint32(now.Nanosecond())
+	attr.Ctime = now.Unix()
+	attr.Ctimensec = uint32(now.Nanosecond())
+
+	ino, err := m.nextInode()
+	*inode = ino
+	if err != nil {
+		return utils.ToSyscallErrno(err)
+	}
+	insertInodeItem_ := &inodeItem{
+		attr:      *attr,
+		parentIno: parent,
+		name:      []byte(name),
+		expire:    now.Add(m.attrTimeOut).Unix(),
+	}
+	absolutePath, err := m.createEntry(parent, name, ino, insertInodeItem_)
+	if err != nil {
+		return utils.ToSyscallErrno(err)
+	}
+	ufs_, _, _, newPath := m.GetUFS(absolutePath)
+	if err = ufs_.Symlink(path, newPath); err != nil {
+		log.Errorf("kv meta symlink parent %v name %s err %v", parent, name, err)
+		m.removeEntry(parent, name, ino)
+		return utils.ToSyscallErrno(err)
+	}
+	m.setPathCache(ino, insertInodeItem_)
+	m.updateQuota(absolutePath, quotaSize(*attr), 1)
+	return syscall.F_OK
+}
+
+// createEntry inserts the entry and inode of a new node in parent, and returns the absolute path of the node.
+func (m *kvMeta) createEntry(parent Ino, name string, ino Ino, item *inodeItem) (string, error) {
+	var absolutePath string
+	err := m.txn(func(tx kv.KvTxn) error {
+		a := tx.Get(m.inodeKey(parent))
+		if a == nil {
+			return syscall.ENOENT
+		}
+		var pInodeItem inodeItem
+		m.parseInode(a, &pInodeItem)
+		if pInodeItem.attr.Type != TypeDirectory {
+			return syscall.ENOTDIR
+		}
+		if tx.Get(m.entryKey(parent, name)) != nil {
+			return syscall.EEXIST
+		}
+		now := time.Now()
+		pInodeItem.attr.Mtime = now.Unix()
+		pInodeItem.attr.Mtimensec = uint32(now.Nanosecond())
+		pInodeItem.attr.Ctime = now.Unix()
+		pInodeItem.attr.Ctimensec = uint32(now.Nanosecond())
+		absolutePath = filepath.Join(m.absolutePath(parent, tx), name)
+
+		insertEntryItem_ := &entryItem{
+			ino:  ino,
+			mode: item.attr.Mode,
+		}
+		if err := tx.Set(m.entryKey(parent, name), m.marshalEntry(insertEntryItem_)); err != nil {
+			return err
+		}
+		if err := tx.Set(m.inodeKey(parent), m.marshalInode(&pInodeItem)); err != nil {
+			return err
+		}
+		return tx.Set(m.inodeKey(ino), m.marshalInode(item))
+	})
+	return absolutePath, err
+}
+
+// removeEntry rolls back the entry inserted by createEntry when the operation of ufs failed.
+func (m *kvMeta) removeEntry(parent Ino, name string, ino Ino) {
+	err := m.txn(func(tx kv.KvTxn) error {
+		return tx.Dels(m.entryKey(parent, name), m.inodeKey(ino))
+	})
+	if err != nil {
+		log.Errorf("kv meta remove entry parent %v name %s err %v", parent, name, err)
+	}
+}
+
+// ufsPath returns the ufs and the path in ufs of inode.
+func (m *kvMeta) ufsPath(inode Ino) (ufslib.UnderFileStorage, string, error) {
+	var absolutePath string
+	err := m.txn(func(tx kv.KvTxn) error {
+		if inode != rootInodeID && tx.Get(m.inodeKey(inode)) == nil {
+			return syscall.ENOENT
+		}
+		absolutePath = m.absolutePath(inode, tx)
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	ufs_, _, _, path := m.GetUFS(absolutePath)
+	return ufs_, path, nil
 }
 
 func (m *kvMeta) Mknod(ctx *Context, parent Ino, name string, _type uint8, mode, cumask uint32, rdev uint32, inode *Ino, attr *Attr) syscall.Errno {
@@ -948,6 +1132,7 @@ func (m *kvMeta) Mknod(ctx *Context, parent Ino, name string, _type uint8, mode,
 		return utils.ToSyscallErrno(err)
 	}
 	m.setPathCache(ino, insertInodeItem_)
+	m.updateQuota(absolutePath, 0, 1)
 	return syscall.F_OK
 }
 
@@ -1031,6 +1216,7 @@ func (m *kvMeta) Mkdir(ctx *Context, parent Ino, name string, mode uint32, cumas
 		return utils.ToSyscallErrno(err)
 	}
 	m.setPathCache(ino, insertInodeItem_)
+	m.updateQuota(absolutePath, 0, 1)
 	return syscall.F_OK
 }
 
@@ -1038,6 +1224,7 @@ func (m *kvMeta) Unlink(ctx *Context, parent Ino, name string) syscall.Errno {
 	log.Debugf("kv meta Unlink parent[%v] name[%s]", parent, name)
 	var absolutePath string
 	entryItem_ := &entryItem{}
+	var unlinked inodeItem
 	err := m.txn(func(tx kv.KvTxn) error {
 		entry, err := m.get(m.entryKey(parent, name))
 		if err != nil {
@@ -1060,6 +1247,9 @@ func (m *kvMeta) Unlink(ctx *Context, parent Ino, name string) syscall.Errno {
 		pinodeItem.attr.Ctime = now.Unix()
 		pinodeItem.attr.Ctimensec = uint32(now.Nanosecond())
 		absolutePath = filepath.Join(m.absolutePath(parent, tx), name)
+		if buf := tx.Get(m.inodeKey(entryItem_.ino)); buf != nil {
+			m.parseInode(buf, &unlinked)
+		}
 		if err = tx.Set(m.inodeKey(parent), m.marshalInode(pinodeItem)); err != nil {
 			return err
 		}
@@ -1078,6 +1268,7 @@ func (m *kvMeta) Unlink(ctx *Context, parent Ino, name string) syscall.Errno {
 		return utils.ToSyscallErrno(err)
 	}
 	m.delsPathCache(entryItem_.ino)
+	m.updateQuota(absolutePath, -quotaSize(unlinked.attr), -1)
 	return syscall.F_OK
 
 }
@@ -1143,6 +1334,7 @@ func (m *kvMeta) Rmdir(ctx *Context, parent Ino, name string) syscall.Errno {
 		return utils.ToSyscallErrno(err)
 	}
 	m.delsPathCache(inodeEntry.ino)
+	m.updateQuota(absolutePath, 0, -1)
 	return syscall.F_OK
 }
 
@@ -1227,11 +1419,77 @@ func (m *kvMeta) Rename(ctx *Context, parentSrc Ino, nameSrc string, parentDst I
 		return "", "", utils.ToSyscallErrno(err)
 	}
 	m.setPathCache(srcEntryItem_.ino, srcAttr)
+	m.moveQuota(pathSrc, pathDst, srcAttr.attr)
 	return pathSrc, pathDst, syscall.F_OK
 }
 
-func (m *kvMeta) Link(ctx *Context, inodeSrc, parent Ino, name string, attr *Attr) syscall.Errno {
-	return syscall.ENOSYS
+// Link creates a hard link in ufs. As inodes of kv meta are indexed by path, the new name gets a new inode,
+// and the nlink of both inodes are refreshed from ufs.
+func (m *kvMeta) Link(ctx *Context, inodeSrc, parent Ino, name string, inode *Ino, attr *Attr) syscall.Errno {
+	log.Debugf("kv meta link inode[%v] to parent[%v] name[%s]", inodeSrc, parent, name)
+	var srcItem inodeItem
+	var srcPath string
+	err := m.txn(func(tx kv.KvTxn) error {
+		buf := tx.Get(m.inodeKey(inodeSrc))
+		if buf == nil {
+			return syscall.ENOENT
+		}
+		m.parseInode(buf, &srcItem)
+		if srcItem.attr.Type == TypeDirectory {
+			return syscall.EPERM
+		}
+		srcPath = m.absolutePath(inodeSrc, tx)
+		return nil
+	})
+	if err != nil {
+		return utils.ToSyscallErrno(err)
+	}
+
+	ino, err := m.nextInode()
+	*inode = ino
+	if err != nil {
+		return utils.ToSyscallErrno(err)
+	}
+	// the attr is expired at once, so that it will be reloaded from ufs
+	insertInodeItem_ := &inodeItem{
+		attr:      srcItem.attr,
+		parentIno: parent,
+		name:      []byte(name),
+	}
+	dstPath, err := m.createEntry(parent, name, ino, insertInodeItem_)
+	if err != nil {
+		return utils.ToSyscallErrno(err)
+	}
+	ufsSrc, _, _, oldPath := m.GetUFS(srcPath)
+	ufsDst, _, _, newPath := m.GetUFS(dstPath)
+	if ufsSrc != ufsDst {
+		log.Errorf("Link between two ufs is not supported")
+		m.removeEntry(parent, name, ino)
+		return syscall.EXDEV
+	}
+	if err = ufsSrc.Link(oldPath, newPath); err != nil {
+		log.Errorf("kv meta link inode %v to parent %v name %s err %v", inodeSrc, parent, name, err)
+		m.removeEntry(parent, name, ino)
+		return utils.ToSyscallErrno(err)
+	}
+	m.updateQuota(dstPath, quotaSize(srcItem.attr), 1)
+
+	err = m.txn(func(tx kv.KvTxn) error {
+		buf := tx.Get(m.inodeKey(inodeSrc))
+		if buf == nil {
+			return nil
+		}
+		m.parseInode(buf, &srcItem)
+		srcItem.expire = 0
+		return tx.Set(m.inodeKey(inodeSrc), m.marshalInode(&srcItem))
+	})
+	if err != nil {
+		log.Errorf("kv meta link expire inode %v err %v", inodeSrc, err)
+	}
+	if attr == nil {
+		attr = &Attr{}
+	}
+	return m.GetAttr(ctx, ino, attr)
 }
 
 // badger do not allow a Tnx which is too big
@@ -1407,11 +1665,7 @@ func (m *kvMeta) Readdir(ctx *Context, inode Ino, entries *[]*Entry) syscall.Err
 				insertChildEntry := &entryItem{
 					ino: newInode,
 				}
-				if dir.Attr.Type == TypeDirectory {
-					insertChildEntry.mode = uint32(utils.StatModeToFileMode(int(syscall.S_IFDIR | uint32(FuseConf.DirMode))))
-				} else {
-					insertChildEntry.mode = uint32(utils.StatModeToFileMode(int(syscall.S_IFREG | uint32(FuseConf.FileMode))))
-				}
+				insertChildEntry.mode = uint32(utils.StatModeToFileMode(int(defaultMode(dir.Attr.Type))))
 				entrySlice = append(entrySlice, entrySliceItem{
 					dir.Name,
 					insertChildEntry,
@@ -1439,11 +1693,7 @@ func (m *kvMeta) Readdir(ctx *Context, inode Ino, entries *[]*Entry) syscall.Err
 			} else {
 				insertChildInode.attr.Uid = uint32(FuseConf.Uid)
 				insertChildInode.attr.Gid = uint32(FuseConf.Gid)
-				if insertChildInode.attr.Type == TypeDirectory {
-					insertChildInode.attr.Mode = syscall.S_IFDIR | uint32(FuseConf.DirMode)
-				} else {
-					insertChildInode.attr.Mode = syscall.S_IFREG | uint32(FuseConf.FileMode)
-				}
+				insertChildInode.attr.Mode = defaultMode(insertChildInode.attr.Type)
 			}
 
 			childEntryItem.Attr.Uid = insertChildInode.attr.Uid
@@ -1569,6 +1819,7 @@ func (m *kvMeta) Create(ctx *Context, parent Ino, name string, mode uint32, cuma
 		return nil, "", utils.ToSyscallErrno(err)
 	} else {
 		m.setPathCache(ino, insertInodeItem_)
+		m.updateQuota(absolutePath, 0, 1)
 	}
 	defer fh.Release()
 	return ufs_, newPath, utils.ToSyscallErrno(err)
@@ -1665,6 +1916,8 @@ func (m *kvMeta) Read(ctx *Context, inode Ino, indx uint32, buf []byte) syscall.
 
 func (m *kvMeta) Write(ctx *Context, inode Ino, off uint32, length int) syscall.Errno {
 	updateInodeItem := &inodeItem{}
+	var path string
+	var growth int64
 
 	err := m.txn(func(tx kv.KvTxn) error {
 		tmp := tx.Get(m.inodeKey(inode))
@@ -1676,6 +1929,10 @@ func (m *kvMeta) Write(ctx *Context, inode Ino, off uint32, length int) syscall.
 			now := time.Now()
 			newLength := uint64(int(off) + length)
 			if newLength > updateInodeItem.attr.Size {
+				if m.HasQuota() {
+					growth = int64(newLength - updateInodeItem.attr.Size)
+					path = m.absolutePath(inode, tx)
+				}
 				updateInodeItem.attr.Size = newLength
 			}
 			updateInodeItem.attr.Ctime = now.Unix()
@@ -1688,6 +1945,9 @@ func (m *kvMeta) Write(ctx *Context, inode Ino, off uint32, length int) syscall.
 
 		return tx.Set(m.inodeKey(inode), m.marshalInode(updateInodeItem))
 	})
+	if err == nil {
+		m.updateQuota(path, growth, 0)
+	}
 	return utils.ToSyscallErrno(err)
 }
 
@@ -1696,31 +1956,55 @@ func (m *kvMeta) CopyFileRange(ctx *Context, fin Ino, offIn uint64, fout Ino, of
 }
 
 func (m *kvMeta) GetXattr(ctx *Context, inode Ino, attribute string, vbuff *[]byte) syscall.Errno {
-	return syscall.ENOSYS
+	ufs_, path, err := m.ufsPath(inode)
+	if err != nil {
+		return utils.ToSyscallErrno(err)
+	}
+	value, err := ufs_.GetXAttr(path, attribute)
+	if err != nil {
+		log.Debugf("kv meta getxattr inode[%v] name[%s] err %v", inode, attribute, err)
+		return utils.ToSyscallErrno(err)
+	}
+	*vbuff = value
+	return syscall.F_OK
 }
 
 func (m *kvMeta) ListXattr(ctx *Context, inode Ino, dbuff *[]string) syscall.Errno {
-	return syscall.ENOSYS
+	ufs_, path, err := m.ufsPath(inode)
+	if err != nil {
+		return utils.ToSyscallErrno(err)
+	}
+	names, err := ufs_.ListXAttr(path)
+	if err != nil {
+		log.Debugf("kv meta listxattr inode[%v] err %v", inode, err)
+		return utils.ToSyscallErrno(err)
+	}
+	*dbuff = names
+	return syscall.F_OK
 }
 
 func (m *kvMeta) SetXattr(ctx *Context, inode Ino, name string, value []byte, flags uint32) syscall.Errno {
-	return syscall.ENOSYS
+	ufs_, path, err := m.ufsPath(inode)
+	if err != nil {
+		return utils.ToSyscallErrno(err)
+	}
+	if err = ufs_.SetXAttr(path, name, value, int(flags)); err != nil {
+		log.Debugf("kv meta setxattr inode[%v] name[%s] err %v", inode, name, err)
+		return utils.ToSyscallErrno(err)
+	}
+	return syscall.F_OK
 }
 
 func (m *kvMeta) RemoveXattr(ctx *Context, inode Ino, name string) syscall.Errno {
-	return syscall.ENOSYS
-}
-
-func (m *kvMeta) Flock(ctx *Context, inode Ino, owner uint64, ltype uint32, block bool) syscall.Errno {
-	return syscall.ENOSYS
-}
-
-func (m *kvMeta) Getlk(ctx *Context, inode Ino, owner uint64, ltype *uint32, start, end *uint64, pid *uint32) syscall.Errno {
-	return syscall.ENOSYS
-}
-
-func (m *kvMeta) Setlk(ctx *Context, inode Ino, owner uint64, block bool, ltype uint32, start, end uint64, pid uint32) syscall.Errno {
-	return syscall.ENOSYS
+	ufs_, path, err := m.ufsPath(inode)
+	if err != nil {
+		return utils.ToSyscallErrno(err)
+	}
+	if err = ufs_.RemoveXAttr(path, name); err != nil {
+		log.Debugf("kv meta removexattr inode[%v] name[%s] err %v", inode, name, err)
+		return utils.ToSyscallErrno(err)
+	}
+	return syscall.F_OK
 }
 
 func (m *kvMeta) LinksMetaUpdateHandler(stopChan chan struct{}, interval int, linkMetaDirPrefix string) error {
@@ -1733,8 +2017,7 @@ func (m *kvMeta) LinksMetaUpdateHandler(stopChan chan struct{}, interval int, li
 		case <-stopChan:
 			log.Info("links meta update handler stopped")
 			return nil
-		default:
-			time.Sleep(time.Duration(interval) * time.Second)
+		case <-time.After(time.Duration(interval) * time.Second):
 		}
 	}
 }
@@ -1796,19 +2079,10 @@ func (m *kvMeta) linksMetaUpdate(linkMetaDirPrefix string) error {
 		return nil
 	}
 
-	flags := uint32(syscall.O_RDONLY)
-	fileHandle, err := m.defaultUfs.Open(filePath, flags, attr.Size)
+	content, err := m.readUfsFile(filePath, attr.Size)
 	if err != nil {
-		log.Errorf("open file[%s] failed: %v", filePath, err)
 		return err
 	}
-	buf := make([]byte, attr.Size)
-	_, err = fileHandle.Read(buf, 0)
-	if err != nil {
-		log.Errorf("fileHandle Read err[%v]", err)
-		return err
-	}
-	content := buf
 
 	var result map[string]common.FSMeta
 	if len(content) != 0 {
@@ -1831,3 +2105,19 @@ func (m *kvMeta) linksMetaUpdate(linkMetaDirPrefix string) error {
 	m.ufsMapUT = attr.Mtime
 	return nil
 }
+
+// readUfsFile reads the whole file of default ufs
+func (m *kvMeta) readUfsFile(filePath string, size uint64) ([]byte, error) {
+	fileHandle, err := m.defaultUfs.Open(filePath, uint32(syscall.O_RDONLY), size)
+	if err != nil {
+		log.Errorf("open file[%s] failed: %v", filePath, err)
+		return nil, err
+	}
+	defer fileHandle.Release()
+	buf := make([]byte, size)
+	if _, err = fileHandle.Read(buf, 0); err != nil {
+		log.Errorf("fileHandle Read err[%v]", err)
+		return nil, err
+	}
+	return buf, nil
+}
@@ -0,0 +1,314 @@
+/*
+Copyright (c) 2021 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package meta
+
+import (
+	"encoding/json"
+	pathlib "path"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/client/base"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/client/kv"
+	ufslib "github.com/PaddlePaddle/PaddleFlow/pkg/fs/client/ufs"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/client/utils"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/common"
+)
+
+// Quotas are enforced by each client on its own writes, which is best-effort: the usage written by other clients is
+// only seen after rescan, so that N clients may each use up the whole quota between rescans.
+
+// quotaRescanInterval is the interval to rescan the usage of quotas, as the fs may be changed by other clients
+const quotaRescanInterval = 10 * time.Minute
+
+// dirQuota is a quota with the bytes and inodes used under its path
+type dirQuota struct {
+	common.FsQuota
+	usedBytes  int64
+	usedInodes int64
+}
+
+type quotaSet struct {
+	sync.RWMutex
+	quotas []*dirQuota
+	// updateTime is the mtime of quota meta file loaded
+	updateTime int64
+	scanTime   time.Time
+}
+
+// quotaCovers returns whether the quota on dir covers path
+func quotaCovers(dir, path string) bool {
+	return dir == "/" || path == dir || strings.HasPrefix(path, dir+"/")
+}
+
+func (m *kvMeta) HasQuota() bool {
+	m.quota.RLock()
+	defer m.quota.RUnlock()
+	return len(m.quota.quotas) != 0
+}
+
+// quotaPath returns the absolute path of inode, or empty if inode is unknown
+func (m *kvMeta) quotaPath(inode Ino) string {
+	var path string
+	_ = m.txn(func(tx kv.KvTxn) error {
+		if inode != rootInodeID && tx.Get(m.inodeKey(inode)) == nil {
+			return nil
+		}
+		path = m.absolutePath(inode, tx)
+		return nil
+	})
+	return path
+}
+
+func (m *kvMeta) CheckQuota(ctx *Context, inode Ino, space, inodes int64) syscall.Errno {
+	if !m.HasQuota() {
+		return syscall.F_OK
+	}
+	path := m.quotaPath(inode)
+	if path == "" {
+		return syscall.F_OK
+	}
+	m.quota.RLock()
+	defer m.quota.RUnlock()
+	for _, q := range m.quota.quotas {
+		if !quotaCovers(q.Path, path) {
+			continue
+		}
+		if space > 0 && q.MaxBytes > 0 && q.usedBytes+space > q.MaxBytes ||
+			inodes > 0 && q.MaxInodes > 0 && q.usedInodes+inodes > q.MaxInodes {
+			log.Debugf("quota of [%s] exceeded by path[%s] space[%d] inodes[%d]", q.Path, path, space, inodes)
+			return syscall.EDQUOT
+		}
+	}
+	return syscall.F_OK
+}
+
+// updateQuota adds space bytes and inodes to the usage of quotas covering path
+func (m *kvMeta) updateQuota(path string, space, inodes int64) {
+	if path == "" || space == 0 && inodes == 0 {
+		return
+	}
+	m.quota.Lock()
+	defer m.quota.Unlock()
+	for _, q := range m.quota.quotas {
+		if quotaCovers(q.Path, path) {
+			q.usedBytes += space
+			q.usedInodes += inodes
+		}
+	}
+}
+
+// moveQuota moves the usage of a renamed node between the quotas covering its old and new path.
+// The usage of a directory is unknown, so that the quotas are rescanned at next update.
+func (m *kvMeta) moveQuota(src, dst string, attr Attr) {
+	if !m.HasQuota() {
+		return
+	}
+	if attr.Type == TypeDirectory {
+		m.quota.Lock()
+		defer m.quota.Unlock()
+		for _, q := range m.quota.quotas {
+			if quotaCovers(q.Path, src) != quotaCovers(q.Path, dst) {
+				m.quota.scanTime = time.Time{}
+				return
+			}
+		}
+		return
+	}
+	space := quotaSize(attr)
+	m.updateQuota(src, -space, -1)
+	m.updateQuota(dst, space, 1)
+}
+
+// quotaSize is the bytes counted by quota for a node
+func quotaSize(attr Attr) int64 {
+	if attr.Type == TypeFile || attr.Type == TypeSymlink {
+		return int64(attr.Size)
+	}
+	return 0
+}
+
+// statQuota limits the statistics of volume by the quota on root, so that df shows the quota
+func (m *kvMeta) statQuota(statfs *base.StatfsOut) {
+	m.quota.RLock()
+	defer m.quota.RUnlock()
+	for _, q := range m.quota.quotas {
+		if q.Path != "/" {
+			continue
+		}
+		if q.MaxBytes > 0 {
+			if statfs.Bsize == 0 {
+				statfs.Bsize = 4096
+			}
+			bsize := int64(statfs.Bsize)
+			free := q.MaxBytes - q.usedBytes
+			if free < 0 {
+				free = 0
+			}
+			statfs.Blocks = uint64((q.MaxBytes + bsize - 1) / bsize)
+			statfs.Bfree = uint64(free / bsize)
+			statfs.Bavail = statfs.Bfree
+		}
+		if q.MaxInodes > 0 {
+			free := q.MaxInodes - q.usedInodes
+			if free < 0 {
+				free = 0
+			}
+			statfs.Files = uint64(q.MaxInodes)
+			statfs.Ffree = uint64(free)
+		}
+	}
+}
+
+// setQuotas replaces the quotas, the usage of new quotas are scanned from ufs
+func (m *kvMeta) setQuotas(quotas []common.FsQuota) {
+	m.quota.RLock()
+	old := make(map[string]*dirQuota, len(m.quota.quotas))
+	for _, q := range m.quota.quotas {
+		old[q.Path] = q
+	}
+	m.quota.RUnlock()
+
+	dirQuotas := make([]*dirQuota, 0, len(quotas))
+	for _, quota := range quotas {
+		quota.Path = pathlib.Clean("/" + quota.Path)
+		q := &dirQuota{FsQuota: quota}
+		if o, ok := old[quota.Path]; ok {
+			q.usedBytes, q.usedInodes = o.usedBytes, o.usedInodes
+		} else if err := m.scanQuota(q); err != nil {
+			log.Errorf("scan usage of quota[%s] err: %v", q.Path, err)
+		}
+		dirQuotas = append(dirQuotas, q)
+	}
+	m.quota.Lock()
+	m.quota.quotas = dirQuotas
+	m.quota.Unlock()
+}
+
+// rescanQuotas refreshes the usage of all quotas from ufs
+func (m *kvMeta) rescanQuotas() {
+	m.quota.RLock()
+	quotas := m.quota.quotas
+	m.quota.RUnlock()
+	for _, q := range quotas {
+		scanned := &dirQuota{FsQuota: q.FsQuota}
+		if err := m.scanQuota(scanned); err != nil {
+			log.Errorf("rescan usage of quota[%s] err: %v", q.Path, err)
+			continue
+		}
+		m.quota.Lock()
+		q.usedBytes, q.usedInodes = scanned.usedBytes, scanned.usedInodes
+		m.quota.Unlock()
+	}
+	m.quota.Lock()
+	m.quota.scanTime = time.Now()
+	m.quota.Unlock()
+}
+
+// scanQuota counts the bytes and inodes under the path of quota, the directory of quota itself is not counted
+func (m *kvMeta) scanQuota(q *dirQuota) error {
+	var walk func(dir string) error
+	walk = func(dir string) error {
+		ufs_, _, _, path := m.GetUFS(dir)
+		entries, err := ufs_.ReadDir(path)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if entry.Name == "." || entry.Name == ".." {
+				continue
+			}
+			q.usedInodes++
+			if entry.Attr == nil {
+				continue
+			}
+			if entry.Attr.Type == ufslib.TypeDirectory {
+				if err = walk(pathlib.Join(dir, entry.Name)); err != nil && !utils.IfNotExist(err) {
+					return err
+				}
+				continue
+			}
+			if entry.Attr.Type == ufslib.TypeFile || entry.Attr.Type == ufslib.TypeSymlink {
+				q.usedBytes += int64(entry.Attr.Size)
+			}
+		}
+		return nil
+	}
+	err := walk(q.Path)
+	if utils.IfNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// QuotaMetaUpdateHandler loads the quotas of fs periodically until stopChan is closed
+func (m *kvMeta) QuotaMetaUpdateHandler(stopChan chan struct{}, interval int, linkMetaDirPrefix string) error {
+	for {
+		if err := m.quotaMetaUpdate(linkMetaDirPrefix); err != nil {
+			log.Debugf("quota meta update failed, err[%v]", err)
+		}
+		select {
+		case <-stopChan:
+			log.Info("quota meta update handler stopped")
+			return nil
+		case <-time.After(time.Duration(interval) * time.Second):
+		}
+	}
+}
+
+// quotaMetaUpdate loads the quotas persisted in fs by server, and rescans their usage periodically
+func (m *kvMeta) quotaMetaUpdate(linkMetaDirPrefix string) error {
+	filePath := pathlib.Join(linkMetaDirPrefix, common.LinkMetaDir, common.QuotaMetaFile)
+	attr := &Attr{}
+	if errno := m.getAttr(filePath, attr); utils.IsError(errno) {
+		if errno == syscall.ENOENT && m.HasQuota() {
+			m.setQuotas(nil)
+		}
+		return errno
+	}
+
+	m.quota.RLock()
+	updateTime, scanTime := m.quota.updateTime, m.quota.scanTime
+	m.quota.RUnlock()
+	if attr.Mtime > updateTime {
+		content, err := m.readUfsFile(filePath, attr.Size)
+		if err != nil {
+			return err
+		}
+		var quotas []common.FsQuota
+		if len(content) != 0 {
+			if err = json.Unmarshal(content, &quotas); err != nil {
+				log.Errorf("json unmarshal quota meta err[%v]", err)
+				return err
+			}
+		}
+		m.setQuotas(quotas)
+		m.quota.Lock()
+		m.quota.updateTime = attr.Mtime
+		m.quota.scanTime = time.Now()
+		m.quota.Unlock()
+		return nil
+	}
+	if time.Since(scanTime) > quotaRescanInterval {
+		m.rescanQuotas()
+	}
+	return nil
+}
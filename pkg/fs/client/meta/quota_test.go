@@ -0,0 +1,105 @@
+/*
+Copyright (c) 2021 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package meta
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/client/kv"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/common"
+)
+
+func TestQuota(t *testing.T) {
+	root, err := ioutil.TempDir("", "quota")
+	assert.NoError(t, err)
+	defer os.RemoveAll(root)
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, "data"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(root, "data", "a"), make([]byte, 100), 0644))
+
+	fsMeta := common.FSMeta{
+		UfsType:    common.LocalType,
+		SubPath:    root,
+		Properties: map[string]string{common.RootKey: root},
+	}
+	mi, err := NewMeta(fsMeta, nil, &Config{Config: kv.Config{Driver: kv.MemType}})
+	assert.NoError(t, err)
+	m := mi.(*kvMeta)
+	ctx := NewEmptyContext()
+	assert.False(t, m.HasQuota())
+
+	// quotas are loaded from the quota meta file
+	quotas := []common.FsQuota{{Path: "/", MaxBytes: 1 << 20, MaxInodes: 10}, {Path: "data", MaxBytes: 200}}
+	content, _ := json.Marshal(quotas)
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, common.LinkMetaDir), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(root, common.LinkMetaDir, common.QuotaMetaFile), content, 0644))
+	assert.NoError(t, m.quotaMetaUpdate(""))
+	assert.True(t, m.HasQuota())
+	assert.Equal(t, "/data", m.quota.quotas[1].Path)
+	assert.Equal(t, int64(100), m.quota.quotas[1].usedBytes)
+	assert.Equal(t, int64(1), m.quota.quotas[1].usedInodes)
+	// data, data/a, .config and quota_meta
+	assert.Equal(t, int64(4), m.quota.quotas[0].usedInodes)
+
+	assert.Equal(t, syscall.Errno(0), m.GetAttr(ctx, rootInodeID, &Attr{}))
+	dataIno, _, errno := m.Lookup(ctx, rootInodeID, "data")
+	assert.Equal(t, syscall.Errno(0), errno)
+	assert.Equal(t, syscall.Errno(0), m.CheckQuota(ctx, dataIno, 100, 1))
+	assert.Equal(t, syscall.EDQUOT, m.CheckQuota(ctx, dataIno, 101, 0))
+	assert.Equal(t, syscall.Errno(0), m.CheckQuota(ctx, rootInodeID, 101, 0))
+
+	// usage is tracked by create and write
+	var ino Ino
+	attr := &Attr{}
+	_, _, errno = m.Create(ctx, dataIno, "b", 0644, 0, uint32(os.O_WRONLY), &ino, attr)
+	assert.Equal(t, syscall.Errno(0), errno)
+	assert.Equal(t, syscall.Errno(0), m.Write(ctx, ino, 0, 100))
+	assert.Equal(t, int64(200), m.quota.quotas[1].usedBytes)
+	assert.Equal(t, syscall.EDQUOT, m.CheckQuota(ctx, ino, 1, 0))
+	assert.Equal(t, int64(5), m.quota.quotas[0].usedInodes)
+
+	// df shows the quota on root
+	statfs, errno := m.StatFS(ctx)
+	assert.Equal(t, syscall.Errno(0), errno)
+	assert.Equal(t, uint64(1<<20)/uint64(statfs.Bsize), statfs.Blocks)
+	assert.Equal(t, uint64(10), statfs.Files)
+	assert.Equal(t, uint64(5), statfs.Ffree)
+
+	// unlink and rename release the usage
+	assert.Equal(t, syscall.Errno(0), m.Unlink(ctx, dataIno, "b"))
+	assert.Equal(t, int64(100), m.quota.quotas[1].usedBytes)
+	rootUsed := m.quota.quotas[0].usedBytes
+	_, _, errno = m.Lookup(ctx, dataIno, "a")
+	assert.Equal(t, syscall.Errno(0), errno)
+	_, _, errno = m.Rename(ctx, dataIno, "a", rootInodeID, "a", 0, &ino, attr)
+	assert.Equal(t, syscall.Errno(0), errno)
+	assert.Equal(t, int64(0), m.quota.quotas[1].usedBytes)
+	assert.Equal(t, int64(0), m.quota.quotas[1].usedInodes)
+	assert.Equal(t, rootUsed, m.quota.quotas[0].usedBytes)
+
+	// quotas are removed with the quota meta file
+	assert.NoError(t, os.Remove(filepath.Join(root, common.LinkMetaDir, common.QuotaMetaFile)))
+	assert.Error(t, m.quotaMetaUpdate(""))
+	assert.False(t, m.HasQuota())
+	assert.Equal(t, syscall.Errno(0), m.CheckQuota(ctx, dataIno, 1<<30, 1))
+}
@@ -40,6 +40,8 @@ func (fs *hdfsFileSystem) sysHdfsToAttr(info os.FileInfo) (a Attr) {
 	st := fs.statFromFileInfo(info)
 	if info.IsDir() {
 		a.Type = TypeDirectory
+	} else if info.Mode()&os.ModeSymlink != 0 {
+		a.Type = TypeSymlink
 	} else {
 		a.Type = TypeFile
 	}
@@ -64,6 +66,8 @@ func (fs *sftpFileSystem) sysSFTPToAttr(info os.FileInfo) (a Attr) {
 	st := fs.statFromFileInfo(info)
 	if info.IsDir() {
 		a.Type = TypeDirectory
+	} else if info.Mode()&os.ModeSymlink != 0 {
+		a.Type = TypeSymlink
 	} else {
 		a.Type = TypeFile
 	}
@@ -88,6 +92,8 @@ func sysToAttr(info os.FileInfo) (a Attr) {
 	st := info.Sys().(*syscall.Stat_t)
 	if info.IsDir() {
 		a.Type = TypeDirectory
+	} else if info.Mode()&os.ModeSymlink != 0 {
+		a.Type = TypeSymlink
 	} else {
 		a.Type = TypeFile
 	}
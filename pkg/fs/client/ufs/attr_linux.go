@@ -43,6 +43,8 @@ func sysToAttr(info os.FileInfo) (a Attr) {
 	st := info.Sys().(*syscall.Stat_t)
 	if info.IsDir() {
 		a.Type = TypeDirectory
+	} else if info.Mode()&os.ModeSymlink != 0 {
+		a.Type = TypeSymlink
 	} else {
 		a.Type = TypeFile
 	}
@@ -67,6 +69,8 @@ func (fs *sftpFileSystem) sysSFTPToAttr(info os.FileInfo) (a Attr) {
 	st := fs.statFromFileInfo(info)
 	if info.IsDir() {
 		a.Type = TypeDirectory
+	} else if info.Mode()&os.ModeSymlink != 0 {
+		a.Type = TypeSymlink
 	} else {
 		a.Type = TypeFile
 	}
@@ -91,6 +95,8 @@ func (fs *hdfsFileSystem) sysHdfsToAttr(info os.FileInfo) (a Attr) {
 	st := fs.statFromFileInfo(info)
 	if info.IsDir() {
 		a.Type = TypeDirectory
+	} else if info.Mode()&os.ModeSymlink != 0 {
+		a.Type = TypeSymlink
 	} else {
 		a.Type = TypeFile
 	}
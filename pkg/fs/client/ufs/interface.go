@@ -22,11 +22,13 @@ import (
 	"time"
 
 	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/client/base"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/common"
 )
 
 const (
 	TypeFile      = 1 // type for regular file
 	TypeDirectory = 2 // type for directory
+	TypeSymlink   = 3 // type for symlink
 )
 
 // under file storage interface, copy from pathfs.FileSystem,
@@ -150,8 +152,11 @@ func RegisterUFS(_type string, creator Creator) {
 
 func NewUFS(_type string, properties map[string]interface{}) (UnderFileStorage, error) {
 	fs, ok := ufs[_type]
-	if ok {
-		return fs(properties)
+	if !ok {
+		return nil, fmt.Errorf("unknow ufs")
 	}
-	return nil, fmt.Errorf("unknow ufs")
+	if snapshot, _ := properties[common.Snapshot].(string); snapshot != "" {
+		return newSnapshotUFS(fs, properties, snapshot)
+	}
+	return fs(properties)
 }
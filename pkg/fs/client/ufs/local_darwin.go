@@ -17,6 +17,7 @@ limitations under the License.
 package ufs
 
 import (
+	"os"
 	"syscall"
 	"time"
 	"unsafe"
@@ -77,9 +78,9 @@ func (fs *LocalFileSystem) Access(name string, mode, callerUid, callerGid uint32
 
 // Extended attributes.
 func (fs *LocalFileSystem) GetXAttr(name string, attribute string) (data []byte, err error) {
-	var dest []byte
-	if _, err := GetXAttr(fs.GetPath(name), attribute, dest); err != syscall.F_OK {
-		return nil, syscall.Errno(err)
+	dest, errno := GetXAttr(fs.GetPath(name), attribute, nil)
+	if errno != syscall.F_OK {
+		return nil, syscall.Errno(errno)
 	}
 	return dest, nil
 }
@@ -167,3 +168,7 @@ func (f *localFileHandle) Allocate(off uint64, sz uint64, mode uint32) error {
 	}
 	return nil
 }
+
+func cloneFile(dst, src *os.File) error {
+	return syscall.ENOTSUP
+}
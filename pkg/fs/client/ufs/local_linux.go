@@ -17,7 +17,8 @@ limitations under the License.
 package ufs
 
 import (
-	"fmt"
+	"bytes"
+	"os"
 	"syscall"
 	"time"
 
@@ -77,25 +78,51 @@ func (fs *LocalFileSystem) Access(name string, mode, callerUid, callerGid uint32
 
 // Extended attributes.
 func (fs *LocalFileSystem) GetXAttr(name string, attribute string) (data []byte, err error) {
-	var dest []byte
-	if _, err := syscall.Getxattr(fs.GetPath(name), attribute, dest); err != nil {
-		return nil, err
+	path := fs.GetPath(name)
+	for {
+		// query the size of value first, and retry if the value is changed between the two calls
+		sz, err := syscall.Getxattr(path, attribute, nil)
+		if err != nil {
+			return nil, err
+		}
+		dest := make([]byte, sz)
+		sz, err = syscall.Getxattr(path, attribute, dest)
+		if err == syscall.ERANGE {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return dest[:sz], nil
 	}
-	return dest, nil
 }
 
 func (fs *LocalFileSystem) ListXAttr(name string) (attributes []string, err error) {
+	path := fs.GetPath(name)
 	var dest []byte
-	if _, err := syscall.Listxattr(fs.GetPath(name), dest); err != nil {
-		return nil, err
+	for {
+		sz, err := syscall.Listxattr(path, nil)
+		if err != nil {
+			return nil, err
+		}
+		dest = make([]byte, sz)
+		sz, err = syscall.Listxattr(path, dest)
+		if err == syscall.ERANGE {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		dest = dest[:sz]
+		break
 	}
-
-	for d := range dest {
-		attributes = append(attributes, fmt.Sprint(d))
+	// names are '\0' terminated
+	for _, attr := range bytes.Split(dest, []byte{0}) {
+		if len(attr) > 0 {
+			attributes = append(attributes, string(attr))
+		}
 	}
-
-	err = nil
-	return
+	return attributes, nil
 }
 
 func (fs *LocalFileSystem) RemoveXAttr(name string, attr string) error {
@@ -126,3 +153,14 @@ func (f *localFileHandle) Allocate(off uint64, sz uint64, mode uint32) error {
 	defer f.lock.Unlock()
 	return syscall.Fallocate(int(f.File.Fd()), mode, int64(off), int64(sz))
 }
+
+// FICLONE shares the extents of src with dst on file systems supporting reflink, such as btrfs and xfs
+const ficlone = 0x40049409
+
+func cloneFile(dst, src *os.File) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, dst.Fd(), ficlone, src.Fd())
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -3,12 +3,15 @@ package ufs
 import (
 	"context"
 	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -44,6 +47,20 @@ func init() {
 const MiB int64 = 1024 * 1024
 const GiB int64 = 1024 * 1024 * 1024
 
+const (
+	// object with symlinkMetaKey in user metadata is a symlink, and its content is the target path
+	symlinkMetaKey = "pfs-symlink"
+	// extended attributes are kept in user metadata with key xattrMetaPrefix + hex(name) and base64 value,
+	// because keys of user metadata are case-insensitive and values are limited to ascii
+	xattrMetaPrefix = "pfs-xattr-"
+	// object storage limits the total size of user metadata to 2KB
+	maxObjectMetaSize = 2048
+
+	// flags of setxattr
+	xattrCreate  = 0x1
+	xattrReplace = 0x2
+)
+
 var chunkPool = &sync.Pool{New: func() interface{} { return make([]byte, MPUChunkSize) }}
 
 type objectFileSystem struct {
@@ -251,7 +268,13 @@ func (fs *objectFileSystem) GetAttr(name string) (*base.FileInfo, error) {
 			} else {
 				aTime := fuse.UtimeToTimespec(&response.LastModified)
 				size := int64(response.Size)
-				st := fillStat(1, 0, 0, 0, size, 4096, size/512, aTime, aTime, aTime)
+				var mode uint32
+				var fMode os.FileMode
+				if isSymlinkObject(response.Metadata) {
+					mode = syscall.S_IFLNK | 0777
+					fMode = os.ModeSymlink | 0777
+				}
+				st := fillStat(1, mode, 0, 0, size, 4096, size/512, aTime, aTime, aTime)
 				mtime := uint64((response.LastModified).Unix())
 				return &base.FileInfo{
 					Name:  name,
@@ -261,6 +284,7 @@ func (fs *objectFileSystem) GetAttr(name string) (*base.FileInfo, error) {
 					IsDir: false,
 					Owner: Owner,
 					Group: Group,
+					Mode:  fMode,
 					Sys:   st,
 				}, nil
 			}
@@ -411,19 +435,74 @@ func (fs *objectFileSystem) Unlink(name string) error {
 }
 
 func (fs *objectFileSystem) GetXAttr(name string, attribute string) (data []byte, err error) {
-	return nil, syscall.ENOSYS
+	_, meta, _, err := fs.objectMeta(name)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := meta[xattrMetaKey(attribute)]
+	if !ok {
+		return nil, syscall.ENODATA
+	}
+	return base64.StdEncoding.DecodeString(value)
 }
 
 func (fs *objectFileSystem) ListXAttr(name string) (attributes []string, err error) {
-	return nil, syscall.ENOSYS
+	_, meta, _, err := fs.objectMeta(name)
+	if err != nil {
+		return nil, err
+	}
+	for k := range meta {
+		if !strings.HasPrefix(k, xattrMetaPrefix) {
+			continue
+		}
+		attr, err := hex.DecodeString(strings.TrimPrefix(k, xattrMetaPrefix))
+		if err != nil {
+			log.Warnf("invalid xattr key[%s] of object[%s]", k, name)
+			continue
+		}
+		attributes = append(attributes, string(attr))
+	}
+	sort.Strings(attributes)
+	return attributes, nil
 }
 
 func (fs *objectFileSystem) RemoveXAttr(name string, attr string) error {
-	return syscall.ENOSYS
+	key, meta, _, err := fs.objectMeta(name)
+	if err != nil {
+		return err
+	}
+	if _, ok := meta[xattrMetaKey(attr)]; !ok {
+		return syscall.ENODATA
+	}
+	delete(meta, xattrMetaKey(attr))
+	return fs.storage.UpdateMeta(key, meta)
 }
 
 func (fs *objectFileSystem) SetXAttr(name string, attr string, data []byte, flags int) error {
-	return syscall.ENOSYS
+	key, meta, exist, err := fs.objectMeta(name)
+	if err != nil {
+		return err
+	}
+	_, ok := meta[xattrMetaKey(attr)]
+	if ok && flags&xattrCreate != 0 {
+		return syscall.EEXIST
+	}
+	if !ok && flags&xattrReplace != 0 {
+		return syscall.ENODATA
+	}
+	meta[xattrMetaKey(attr)] = base64.StdEncoding.EncodeToString(data)
+	size := 0
+	for k, v := range meta {
+		size += len(k) + len(v)
+	}
+	if size > maxObjectMetaSize {
+		return syscall.ENOSPC
+	}
+	if !exist {
+		// the directory has no object of itself, create one to keep the metadata
+		return fs.storage.PutWithMeta(key, nil, meta)
+	}
+	return fs.storage.UpdateMeta(key, meta)
 }
 
 func (fs *objectFileSystem) Open(name string, flags uint32, size uint64) (FileHandle, error) {
@@ -520,11 +599,37 @@ func (fs *objectFileSystem) ReadDir(name string) (stream []DirEntry, err error)
 }
 
 func (fs *objectFileSystem) Symlink(value string, linkName string) error {
-	return syscall.ENOSYS
+	key := fs.objectKeyName(linkName)
+	err := fs.storage.PutWithMeta(key, strings.NewReader(value), map[string]string{symlinkMetaKey: "1"})
+	if err != nil {
+		log.Errorf("fs.storage.PutWithMeta: key[%s] err[%v]", key, err)
+	}
+	return err
 }
 
 func (fs *objectFileSystem) Readlink(name string) (string, error) {
-	return "", syscall.ENOSYS
+	key := fs.objectKeyName(name)
+	response, err := fs.storage.Head(key)
+	if err != nil {
+		if isNotExistErr(err) {
+			return "", syscall.ENOENT
+		}
+		return "", err
+	}
+	if !isSymlinkObject(response.Metadata) {
+		return "", syscall.EINVAL
+	}
+	in, err := fs.storage.Get(key, 0, int64(response.Size))
+	if err != nil {
+		log.Errorf("fs.storage.Get: key[%s] err[%v]", key, err)
+		return "", err
+	}
+	defer in.Close()
+	target, err := ioutil.ReadAll(in)
+	if err != nil {
+		return "", err
+	}
+	return string(target), nil
 }
 
 func (fs *objectFileSystem) StatFs(name string) *base.StatfsOut {
@@ -566,6 +671,43 @@ func (fs *objectFileSystem) objectKeyName(name string) string {
 	return path
 }
 
+// objectMeta returns the key and user metadata of the object of file or directory, exist is false when the
+// directory has no object of itself.
+func (fs *objectFileSystem) objectMeta(name string) (key string, meta map[string]string, exist bool, err error) {
+	if name == "" || name == Delimiter {
+		return "", nil, false, syscall.ENOTSUP
+	}
+	meta = make(map[string]string)
+	for _, key = range []string{fs.objectKeyName(name), fs.objectKeyName(toDirPath(name))} {
+		response, err := fs.storage.Head(key)
+		if err == nil {
+			for k, v := range response.Metadata {
+				if v != nil {
+					meta[k] = *v
+				}
+			}
+			return key, meta, true, nil
+		}
+		if !isNotExistErr(err) {
+			log.Errorf("fs.storage.Head: key[%s] err[%v]", key, err)
+			return "", nil, false, err
+		}
+	}
+	if err = fs.isDirExist(name); err != nil {
+		return "", nil, false, err
+	}
+	return key, meta, false, nil
+}
+
+func isSymlinkObject(meta map[string]*string) bool {
+	_, ok := meta[symlinkMetaKey]
+	return ok
+}
+
+func xattrMetaKey(name string) string {
+	return xattrMetaPrefix + hex.EncodeToString([]byte(name))
+}
+
 // getRootDirAttr return root dir info of filesystem
 func (fs *objectFileSystem) getRootDirAttr() *base.FileInfo {
 	// 参考bosfs的做法，启动时记录一个默认时间，目录时间属性频繁变化会导致tar压缩目录失败。
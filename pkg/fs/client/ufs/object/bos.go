@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/baidubce/bce-sdk-go/bce"
@@ -58,7 +59,11 @@ func (storage Bos) Get(key string, off, limit int64) (io.ReadCloser, error) {
 }
 
 func (storage Bos) Put(key string, in io.Reader) error {
-	log.Tracef("bos.Put key[%s]", key)
+	return storage.PutWithMeta(key, in, nil)
+}
+
+func (storage Bos) PutWithMeta(key string, in io.Reader, meta map[string]string) error {
+	log.Tracef("bos.Put key[%s] meta[%v]", key, meta)
 	body := &bce.Body{}
 	var err error
 	if in != nil {
@@ -71,7 +76,11 @@ func (storage Bos) Put(key string, in io.Reader) error {
 			return err
 		}
 	}
-	_, err = storage.bosClient.BasicPutObject(storage.bucket, key, body)
+	var args *api.PutObjectArgs
+	if len(meta) > 0 {
+		args = &api.PutObjectArgs{UserMeta: meta}
+	}
+	_, err = storage.bosClient.PutObject(storage.bucket, key, body, args)
 	return err
 }
 
@@ -108,6 +117,40 @@ func (storage Bos) Copy(newKey, copySource string) error {
 	return nil
 }
 
+// UpdateMeta copies the object onto itself with the user metadata replaced, the system metadata have to be
+// copied explicitly, or they are reset by the replace directive.
+func (storage Bos) UpdateMeta(key string, meta map[string]string) error {
+	log.Tracef("bos.UpdateMeta key[%s] meta[%v]", key, meta)
+	head, err := storage.bosClient.GetObjectMeta(storage.bucket, key)
+	if err != nil {
+		log.Errorf("bos.UpdateMeta head key[%s] err: %v", key, err)
+		return err
+	}
+	if head.ContentLength > MaxCopyObjectSize {
+		log.Errorf("bos.UpdateMeta key[%s] size[%d] exceeds the limit of copy", key, head.ContentLength)
+		return syscall.ENOTSUP
+	}
+	args := &api.CopyObjectArgs{
+		ObjectMeta: api.ObjectMeta{
+			CacheControl:       head.CacheControl,
+			ContentDisposition: head.ContentDisposition,
+			ContentEncoding:    head.ContentEncoding,
+			ContentType:        head.ContentType,
+			Expires:            head.Expires,
+			StorageClass:       head.StorageClass,
+			UserMeta:           meta,
+		},
+		MetadataDirective: api.METADATA_DIRECTIVE_REPLACE,
+		// the object is not copied if it is overwritten since head
+		IfMatch: head.ETag,
+	}
+	_, err = storage.bosClient.CopyObject(storage.bucket, key, storage.bucket, key, args)
+	if err != nil {
+		log.Errorf("bos.UpdateMeta key[%s] err: %v", key, err)
+	}
+	return err
+}
+
 func (storage Bos) Head(key string) (*HeadObjectOutput, error) {
 	log.Tracef("bos.Head key[%s]", key)
 	response, err := storage.bosClient.GetObjectMeta(storage.bucket, key)
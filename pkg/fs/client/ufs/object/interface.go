@@ -9,12 +9,18 @@ import (
 	"time"
 )
 
+// MaxCopyObjectSize is the max size of object which can be copied in one request, larger ones have to be copied
+// by multipart upload
+const MaxCopyObjectSize = 5 << 30
+
 type ItemOutput struct {
 	Key          string
 	ETag         string
 	LastModified time.Time
 	Size         uint64
 	StorageClass string
+	// VersionID is only set by ListLatestVersions of VersionedStorage
+	VersionID string
 }
 
 type HeadObjectOutput struct {
@@ -110,6 +116,11 @@ type ObjectStorage interface {
 	Get(key string, off, limit int64) (io.ReadCloser, error)
 	// Put data read from a reader to an object specified by key.
 	Put(key string, in io.Reader) error
+	// PutWithMeta puts data read from a reader to an object with user metadata.
+	PutWithMeta(key string, in io.Reader, meta map[string]string) error
+	// UpdateMeta replaces all of the user metadata of an existing object, and the system metadata like
+	// content type and storage class are kept. ENOTSUP is returned if the object is larger than MaxCopyObjectSize.
+	UpdateMeta(key string, meta map[string]string) error
 	// Delete a object.
 	Deletes(key []string) error
 	// Copy Object
@@ -128,3 +139,13 @@ type ObjectStorage interface {
 	// CompleteUpload finish an multipart upload.
 	CompleteUpload(key string, uploadID string, parts []*Part) error
 }
+
+// VersionedStorage is implemented by the object storages which are able to read the history versions of objects.
+type VersionedStorage interface {
+	// VersioningEnabled returns whether versioning of the bucket is enabled.
+	VersioningEnabled() (bool, error)
+	// ListLatestVersions returns the latest versions of objects, delete markers are skipped.
+	ListLatestVersions(input *ListInput) (*ListBlobsOutput, error)
+	// GetVersion gets the data of the given version of object.
+	GetVersion(key, versionID string, off, limit int64) (io.ReadCloser, error)
+}
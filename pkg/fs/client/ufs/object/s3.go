@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/url"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -53,7 +55,11 @@ func (storage S3Storage) Get(key string, off, limit int64) (io.ReadCloser, error
 }
 
 func (storage S3Storage) Put(key string, in io.Reader) error {
-	log.Tracef("s3.PutObject[%s]", key)
+	return storage.PutWithMeta(key, in, nil)
+}
+
+func (storage S3Storage) PutWithMeta(key string, in io.Reader, meta map[string]string) error {
+	log.Tracef("s3.PutObject[%s] meta[%v]", key, meta)
 	var body io.ReadSeeker
 	if in == nil {
 		body = nil
@@ -72,6 +78,9 @@ func (storage S3Storage) Put(key string, in io.Reader) error {
 		Key:    aws.String(key),
 		Body:   body,
 	}
+	if len(meta) > 0 {
+		request.Metadata = aws.StringMap(meta)
+	}
 	_, err := storage.s3.PutObject(request)
 	if err != nil {
 		log.Errorf("s3.PutObject[%s] err: %v", key, err)
@@ -122,6 +131,45 @@ func (storage S3Storage) Copy(newKey, copySource string) error {
 	return nil
 }
 
+// UpdateMeta copies the object onto itself with the user metadata replaced, the system metadata have to be
+// copied explicitly, or they are reset by the replace directive.
+func (storage S3Storage) UpdateMeta(key string, meta map[string]string) error {
+	log.Tracef("s3.UpdateMeta key[%s] meta[%v]", key, meta)
+	head, err := storage.s3.HeadObject(&s3.HeadObjectInput{Bucket: &storage.bucket, Key: &key})
+	if err != nil {
+		log.Errorf("s3.UpdateMeta head key[%s] err: %v", key, err)
+		return err
+	}
+	if aws.Int64Value(head.ContentLength) > MaxCopyObjectSize {
+		log.Errorf("s3.UpdateMeta key[%s] size[%d] exceeds the limit of copy", key, aws.Int64Value(head.ContentLength))
+		return syscall.ENOTSUP
+	}
+	copySource := storage.bucket + "/" + key
+	request := &s3.CopyObjectInput{
+		Bucket:            &storage.bucket,
+		Key:               &key,
+		CopySource:        &copySource,
+		Metadata:          aws.StringMap(meta),
+		MetadataDirective: aws.String(s3.MetadataDirectiveReplace),
+		// the object is not copied if it is overwritten since head
+		CopySourceIfMatch:       head.ETag,
+		CacheControl:            head.CacheControl,
+		ContentDisposition:      head.ContentDisposition,
+		ContentEncoding:         head.ContentEncoding,
+		ContentLanguage:         head.ContentLanguage,
+		ContentType:             head.ContentType,
+		StorageClass:            head.StorageClass,
+		ServerSideEncryption:    head.ServerSideEncryption,
+		SSEKMSKeyId:             head.SSEKMSKeyId,
+		WebsiteRedirectLocation: head.WebsiteRedirectLocation,
+	}
+	_, err = storage.s3.CopyObject(request)
+	if err != nil {
+		log.Errorf("s3.UpdateMeta key[%s] err: %v", key, err)
+	}
+	return err
+}
+
 func (storage S3Storage) Head(key string) (*HeadObjectOutput, error) {
 	log.Tracef("s3.Head key[%s]", key)
 	input := &s3.HeadObjectInput{
@@ -230,6 +278,95 @@ func (storage S3Storage) List(input *ListInput) (*ListBlobsOutput, error) {
 	}, nil
 }
 
+func (storage S3Storage) VersioningEnabled() (bool, error) {
+	resp, err := storage.s3.GetBucketVersioning(&s3.GetBucketVersioningInput{Bucket: &storage.bucket})
+	if err != nil {
+		log.Errorf("s3.GetBucketVersioning bucket[%s] err: %v", storage.bucket, err)
+		return false, err
+	}
+	return aws.StringValue(resp.Status) == s3.BucketVersioningStatusEnabled, nil
+}
+
+// ListLatestVersions lists with ListObjectVersions, the key and version markers are encoded in the
+// continuation token.
+func (storage S3Storage) ListLatestVersions(input *ListInput) (*ListBlobsOutput, error) {
+	log.Tracef("s3.ListLatestVersions param[%+v]", input)
+	request := &s3.ListObjectVersionsInput{
+		Bucket:    &storage.bucket,
+		Prefix:    &input.Prefix,
+		MaxKeys:   &input.MaxKeys,
+		Delimiter: &input.Delimiter,
+	}
+	if input.ContinuationToken != "" {
+		markers, err := url.ParseQuery(input.ContinuationToken)
+		if err != nil {
+			return nil, fmt.Errorf("invalid continuation token[%s]: %v", input.ContinuationToken, err)
+		}
+		request.KeyMarker = aws.String(markers.Get("key"))
+		request.VersionIdMarker = aws.String(markers.Get("version"))
+	}
+
+	resp, err := storage.s3.ListObjectVersions(request)
+	if err != nil {
+		log.Errorf("s3.ListObjectVersions input[%+v] err: %v", input, err)
+		return nil, err
+	}
+	prefixes := make([]PrefixOutput, 0)
+	items := make([]ItemOutput, 0)
+	for _, p := range resp.CommonPrefixes {
+		prefixes = append(prefixes, PrefixOutput{Prefix: aws.StringValue(p.Prefix)})
+	}
+	for _, v := range resp.Versions {
+		if !aws.BoolValue(v.IsLatest) {
+			continue
+		}
+		items = append(items, ItemOutput{
+			Key:          aws.StringValue(v.Key),
+			ETag:         aws.StringValue(v.ETag),
+			LastModified: aws.TimeValue(v.LastModified),
+			Size:         uint64(aws.Int64Value(v.Size)),
+			StorageClass: aws.StringValue(v.StorageClass),
+			VersionID:    aws.StringValue(v.VersionId),
+		})
+	}
+	var nextContinuationToken string
+	if aws.BoolValue(resp.IsTruncated) {
+		nextContinuationToken = url.Values{
+			"key":     {aws.StringValue(resp.NextKeyMarker)},
+			"version": {aws.StringValue(resp.NextVersionIdMarker)},
+		}.Encode()
+	}
+	return &ListBlobsOutput{
+		Prefixes:              prefixes,
+		Items:                 items,
+		NextContinuationToken: nextContinuationToken,
+		IsTruncated:           aws.BoolValue(resp.IsTruncated),
+	}, nil
+}
+
+func (storage S3Storage) GetVersion(key, versionID string, off, limit int64) (io.ReadCloser, error) {
+	log.Tracef("s3.GetObject[%s] version[%s] off[%d] limit[%d]", key, versionID, off, limit)
+	request := &s3.GetObjectInput{
+		Bucket:    &storage.bucket,
+		Key:       &key,
+		VersionId: &versionID,
+	}
+	if limit > 0 {
+		r := fmt.Sprintf("bytes=%d-%d", off, off+limit-1)
+		request.Range = &r
+	} else if off > 0 {
+		r := fmt.Sprintf("bytes=%d-", off)
+		request.Range = &r
+	}
+
+	response, err := storage.s3.GetObject(request)
+	if err != nil {
+		log.Debugf("s3.GetObject[%s] version[%s] err: %v ", key, versionID, err)
+		return nil, err
+	}
+	return response.Body, nil
+}
+
 func (storage S3Storage) CreateMultipartUpload(key string) (*MultipartCommitOutPut, error) {
 	log.Tracef("s3.CreateMultipartUpload key[%s]", key)
 	mpu := s3.CreateMultipartUploadInput{
@@ -323,19 +460,10 @@ func metadataToLower(m map[string]*string) map[string]*string {
 }
 
 func metadataToLowerBos(m map[string]string) map[string]*string {
-	result := make(map[string]*string)
-	if m != nil {
-		var toDelete []string
-		for k, v := range m {
-			lower := strings.ToLower(k)
-			if lower != k {
-				result[lower] = &v
-				toDelete = append(toDelete, k)
-			}
-		}
-		for _, k := range toDelete {
-			delete(m, k)
-		}
+	result := make(map[string]*string, len(m))
+	for k, v := range m {
+		value := v
+		result[strings.ToLower(k)] = &value
 	}
 	return result
 }
@@ -3,9 +3,11 @@ package object
 import (
 	"fmt"
 	"reflect"
+	"syscall"
 	"testing"
 
 	"github.com/agiledragon/gomonkey/v2"
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3"
 )
 
@@ -61,6 +63,19 @@ func Test_metadataToLowerBos(t *testing.T) {
 				"abc": &a,
 			},
 		},
+		{
+			name: "lower keys are kept",
+			args: args{
+				m: map[string]string{
+					"abc": a,
+					"DEF": a,
+				},
+			},
+			want: map[string]*string{
+				"abc": &a,
+				"def": &a,
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -422,3 +437,64 @@ func TestS3Storage_AbortUpload(t *testing.T) {
 		})
 	}
 }
+
+func TestS3Storage_UpdateMeta(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int64
+		wantErr error
+	}{
+		{
+			name: "system metadata kept",
+			size: 1024,
+		},
+		{
+			name:    "exceed copy limit",
+			size:    MaxCopyObjectSize + 1,
+			wantErr: syscall.ENOTSUP,
+		},
+	}
+
+	a := &s3.S3{}
+	var size int64
+	var copied *s3.CopyObjectInput
+	var p1 = gomonkey.ApplyMethod(reflect.TypeOf(a), "HeadObject",
+		func(a *s3.S3, input *s3.HeadObjectInput) (*s3.HeadObjectOutput, error) {
+			return &s3.HeadObjectOutput{
+				ContentLength: aws.Int64(size),
+				ContentType:   aws.String("image/png"),
+				ETag:          aws.String("etag"),
+				StorageClass:  aws.String("STANDARD_IA"),
+			}, nil
+		})
+	defer p1.Reset()
+	var p2 = gomonkey.ApplyMethod(reflect.TypeOf(a), "CopyObject",
+		func(a *s3.S3, input *s3.CopyObjectInput) (*s3.CopyObjectOutput, error) {
+			copied = input
+			return &s3.CopyObjectOutput{}, nil
+		})
+	defer p2.Reset()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			size, copied = tt.size, nil
+			storage := S3Storage{bucket: "a", s3: a}
+			err := storage.UpdateMeta("b", map[string]string{"k": "v"})
+			if err != tt.wantErr {
+				t.Fatalf("UpdateMeta() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if copied != nil {
+					t.Errorf("UpdateMeta() copied object exceeding the limit")
+				}
+				return
+			}
+			if aws.StringValue(copied.ContentType) != "image/png" ||
+				aws.StringValue(copied.StorageClass) != "STANDARD_IA" ||
+				aws.StringValue(copied.CopySourceIfMatch) != "etag" ||
+				aws.StringValue(copied.Metadata["k"]) != "v" {
+				t.Errorf("UpdateMeta() copy input = %v", copied)
+			}
+		})
+	}
+}
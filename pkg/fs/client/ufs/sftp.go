@@ -147,8 +147,14 @@ func (fs *sftpFileSystem) statFromFileInfo(finfo os.FileInfo) *syscall.Stat_t {
 // hardlinks incurs a performance hit.
 func (fs *sftpFileSystem) GetAttr(name string) (*base.FileInfo, error) {
 	log.Debugf("the path is %v", fs.GetPath(name))
-	info, err := fs.sc.sftpClient.Stat(fs.GetPath(name))
-
+	var info os.FileInfo
+	var err error
+	if name == "" {
+		// the toplevel directory is always looked through symlinks, same as local
+		info, err = fs.sc.sftpClient.Stat(fs.GetPath(name))
+	} else {
+		info, err = fs.sc.sftpClient.Lstat(fs.GetPath(name))
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -162,6 +168,7 @@ func (fs *sftpFileSystem) GetAttr(name string) (*base.FileInfo, error) {
 		IsDir: info.IsDir(),
 		Owner: owner,
 		Group: group,
+		Mode:  info.Mode(),
 		Sys:   *fs.statFromFileInfo(info),
 	}, nil
 }
@@ -296,7 +303,8 @@ func (fs *sftpFileSystem) ReadDir(name string) (stream []DirEntry, err error) {
 
 // Symlinks.
 func (fs *sftpFileSystem) Symlink(value string, linkName string) error {
-	return fs.sc.sftpClient.Symlink(fs.GetPath(value), fs.GetPath(linkName))
+	// the target is kept as it is, so relative symlinks still work when the directory is moved
+	return fs.sc.sftpClient.Symlink(value, fs.GetPath(linkName))
 }
 
 func (fs *sftpFileSystem) Readlink(name string) (string, error) {
@@ -0,0 +1,605 @@
+/*
+Copyright (c) 2021 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ufs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/client/base"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/client/ufs/object"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/client/utils"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/common"
+)
+
+const (
+	// SnapshotModeManifest records keys, etags and versions of objects in a manifest
+	SnapshotModeManifest = "manifest"
+	// SnapshotModeCopy copies the files, with reflink if supported
+	SnapshotModeCopy = "copy"
+	// SnapshotModeNative uses the snapshot of hdfs
+	SnapshotModeNative = "native"
+
+	manifestSuffix = ".manifest"
+)
+
+// Snapshotter is implemented by the under file storages which are able to take point-in-time snapshots,
+// snapshot with name is kept in .snapshot/name under the root of fs.
+type Snapshotter interface {
+	CreateSnapshot(name string) (*SnapshotInfo, error)
+	DeleteSnapshot(name string) error
+}
+
+type SnapshotInfo struct {
+	FileCount int64
+	Size      int64
+}
+
+type snapshotManifest struct {
+	Name       string          `json:"name"`
+	CreateTime int64           `json:"createTime"`
+	Entries    []manifestEntry `json:"entries"`
+}
+
+// manifestEntry is an object at the time of snapshot, key is relative to the root of fs. VersionID is set
+// only if versioning of the bucket is enabled.
+type manifestEntry struct {
+	Key       string `json:"key"`
+	ETag      string `json:"etag"`
+	Size      uint64 `json:"size"`
+	Mtime     int64  `json:"mtime"`
+	VersionID string `json:"versionId,omitempty"`
+}
+
+// SnapshotMode returns how snapshots of the fs type are taken, empty if not supported
+func SnapshotMode(fsType string) string {
+	switch fsType {
+	case common.S3Type, common.BosType:
+		return SnapshotModeManifest
+	case common.LocalType:
+		return SnapshotModeCopy
+	case common.HDFSType, common.HDFSWithKerberosType:
+		return SnapshotModeNative
+	default:
+		return ""
+	}
+}
+
+func snapshotPath(name string) string {
+	return path.Join(common.SnapshotDir, name)
+}
+
+func trimETag(etag string) string {
+	return strings.Trim(etag, `"`)
+}
+
+// newSnapshotUFS creates the read-only under file storage of snapshot
+func newSnapshotUFS(creator Creator, properties map[string]interface{}, snapshot string) (UnderFileStorage, error) {
+	props := make(map[string]interface{}, len(properties))
+	for k, v := range properties {
+		if k != common.Snapshot {
+			props[k] = v
+		}
+	}
+	subPath, _ := props[common.SubPath].(string)
+	fsType, _ := props[common.Type].(string)
+
+	switch SnapshotMode(fsType) {
+	case SnapshotModeManifest:
+		fs, err := creator(props)
+		if err != nil {
+			return nil, err
+		}
+		objectFs, ok := fs.(*objectFileSystem)
+		if !ok {
+			return nil, fmt.Errorf("ufs %s is not object storage", fs.String())
+		}
+		return newManifestFileSystem(objectFs, snapshot)
+	case SnapshotModeCopy:
+		props[common.SubPath] = filepath.Join(subPath, snapshotPath(snapshot))
+		// local file system creates the root if not exist
+		if _, err := os.Stat(props[common.SubPath].(string)); err != nil {
+			return nil, fmt.Errorf("snapshot[%s] not found: %v", snapshot, err)
+		}
+	case SnapshotModeNative:
+		if subPath == "" {
+			subPath = Delimiter
+		}
+		props[common.SubPath] = path.Join(subPath, snapshotPath(snapshot))
+	default:
+		return nil, fmt.Errorf("snapshot is not supported by fs type[%s]", fsType)
+	}
+	fs, err := creator(props)
+	if err != nil {
+		return nil, err
+	}
+	return &readOnlyFileSystem{UnderFileStorage: fs}, nil
+}
+
+// ============================================================= object storage ============================================================= //
+
+// versionedStorage returns the storage if objects can be read by version, nil if versioning is not enabled.
+func (fs *objectFileSystem) versionedStorage() object.VersionedStorage {
+	versioned, ok := fs.storage.(object.VersionedStorage)
+	if !ok {
+		return nil
+	}
+	enabled, err := versioned.VersioningEnabled()
+	if err != nil {
+		log.Warningf("get versioning of %s failed, objects are kept by etag in snapshot: %v", fs.storage.String(), err)
+		return nil
+	}
+	if !enabled {
+		return nil
+	}
+	return versioned
+}
+
+// CreateSnapshot writes the keys and etags of all objects into .snapshot/name.manifest, objects are not copied.
+// If versioning of the bucket is enabled, the versions of objects are recorded too, so the snapshot is still
+// readable after the objects are overwritten or deleted.
+func (fs *objectFileSystem) CreateSnapshot(name string) (*SnapshotInfo, error) {
+	manifestKey := fs.objectKeyName(snapshotPath(name) + manifestSuffix)
+	if _, err := fs.storage.Head(manifestKey); err == nil {
+		return nil, syscall.EEXIST
+	} else if !isNotExistErr(err) {
+		return nil, err
+	}
+
+	prefix := fs.objectKeyName(Delimiter)
+	if prefix == Delimiter {
+		prefix = ""
+	}
+	manifest := snapshotManifest{Name: name, CreateTime: time.Now().Unix()}
+	info := &SnapshotInfo{}
+	list := fs.storage.List
+	if versioned := fs.versionedStorage(); versioned != nil {
+		list = versioned.ListLatestVersions
+	}
+	input := &object.ListInput{Prefix: prefix, MaxKeys: MaxKeys}
+	for {
+		res, err := list(input)
+		if err != nil {
+			log.Errorf("fs.storage.List: prefix[%s] err[%v]", prefix, err)
+			return nil, err
+		}
+		for _, item := range res.Items {
+			key := strings.TrimPrefix(item.Key, prefix)
+			if key == "" || strings.HasPrefix(key, common.SnapshotDir+Delimiter) {
+				continue
+			}
+			manifest.Entries = append(manifest.Entries, manifestEntry{
+				Key:       key,
+				ETag:      trimETag(item.ETag),
+				Size:      item.Size,
+				Mtime:     item.LastModified.Unix(),
+				VersionID: item.VersionID,
+			})
+			if !strings.HasSuffix(key, Delimiter) {
+				info.FileCount++
+				info.Size += int64(item.Size)
+			}
+		}
+		if !res.IsTruncated || res.NextContinuationToken == "" {
+			break
+		}
+		input.ContinuationToken = res.NextContinuationToken
+	}
+
+	data, err := json.Marshal(&manifest)
+	if err != nil {
+		return nil, err
+	}
+	if err = fs.storage.Put(manifestKey, bytes.NewReader(data)); err != nil {
+		log.Errorf("fs.storage.Put: key[%s] err[%v]", manifestKey, err)
+		return nil, err
+	}
+	return info, nil
+}
+
+func (fs *objectFileSystem) DeleteSnapshot(name string) error {
+	return fs.storage.Deletes([]string{fs.objectKeyName(snapshotPath(name) + manifestSuffix)})
+}
+
+// manifestFileSystem is the read-only view of an object storage snapshot. The directory tree comes from the
+// manifest, objects with version are read by version, and the others changed or deleted after the snapshot
+// can not be read with ESTALE.
+type manifestFileSystem struct {
+	readOnlyFileSystem
+	object  *objectFileSystem
+	entries map[string]*manifestEntry
+	// children of directories, entry is nil for sub directory
+	dirs map[string]map[string]*manifestEntry
+}
+
+func newManifestFileSystem(fs *objectFileSystem, name string) (*manifestFileSystem, error) {
+	key := fs.objectKeyName(snapshotPath(name) + manifestSuffix)
+	head, err := fs.storage.Head(key)
+	if err != nil {
+		if isNotExistErr(err) {
+			return nil, fmt.Errorf("snapshot[%s] not found", name)
+		}
+		return nil, err
+	}
+	in, err := fs.storage.Get(key, 0, int64(head.Size))
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+	manifest := snapshotManifest{}
+	if err = json.NewDecoder(in).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("decode manifest of snapshot[%s] failed: %v", name, err)
+	}
+
+	m := &manifestFileSystem{
+		readOnlyFileSystem: readOnlyFileSystem{UnderFileStorage: fs},
+		object:             fs,
+		entries:            make(map[string]*manifestEntry, len(manifest.Entries)),
+		dirs:               make(map[string]map[string]*manifestEntry),
+	}
+	m.addDir("")
+	for i := range manifest.Entries {
+		entry := &manifest.Entries[i]
+		if strings.HasSuffix(entry.Key, Delimiter) {
+			m.addDir(manifestName(entry.Key))
+			continue
+		}
+		m.entries[entry.Key] = entry
+		m.addChild(entry.Key, entry)
+	}
+	return m, nil
+}
+
+func manifestName(name string) string {
+	return strings.Trim(path.Clean(Delimiter+name), Delimiter)
+}
+
+func (m *manifestFileSystem) addDir(dir string) {
+	if _, ok := m.dirs[dir]; ok {
+		return
+	}
+	m.dirs[dir] = make(map[string]*manifestEntry)
+	if dir != "" {
+		m.addChild(dir, nil)
+	}
+}
+
+func (m *manifestFileSystem) addChild(name string, entry *manifestEntry) {
+	parent := path.Dir(name)
+	if parent == "." {
+		parent = ""
+	}
+	m.addDir(parent)
+	m.dirs[parent][path.Base(name)] = entry
+}
+
+func (m *manifestFileSystem) GetAttr(name string) (*base.FileInfo, error) {
+	name = manifestName(name)
+	if _, ok := m.dirs[name]; ok {
+		fInfo := m.object.getRootDirAttr()
+		fInfo.Name = name
+		fInfo.Path = m.object.objectKeyName(name)
+		return fInfo, nil
+	}
+	entry, ok := m.entries[name]
+	if !ok {
+		return nil, syscall.ENOENT
+	}
+	mtime := time.Unix(entry.Mtime, 0)
+	aTime := fuse.UtimeToTimespec(&mtime)
+	size := int64(entry.Size)
+	return &base.FileInfo{
+		Name:  name,
+		Path:  m.object.objectKeyName(name),
+		Size:  size,
+		Mtime: uint64(entry.Mtime),
+		Owner: Owner,
+		Group: Group,
+		Sys:   fillStat(1, 0, 0, 0, size, 4096, size/512, aTime, aTime, aTime),
+	}, nil
+}
+
+func (m *manifestFileSystem) ReadDir(name string) (stream []DirEntry, err error) {
+	children, ok := m.dirs[manifestName(name)]
+	if !ok {
+		return nil, syscall.ENOENT
+	}
+	uid := uint32(utils.LookupUser(Owner))
+	gid := uint32(utils.LookupGroup(Group))
+	dirMtime := m.object.defaultTime.Unix()
+	stream = make([]DirEntry, 0, len(children))
+	for child, entry := range children {
+		attr := &Attr{Type: TypeDirectory, Size: 4096, Mtime: dirMtime, Uid: uid, Gid: gid}
+		if entry != nil {
+			attr = &Attr{Type: TypeFile, Size: entry.Size, Mtime: entry.Mtime, Uid: uid, Gid: gid}
+		}
+		stream = append(stream, DirEntry{Attr: attr, Name: child})
+	}
+	sort.Slice(stream, func(i, j int) bool { return stream[i].Name < stream[j].Name })
+	return stream, nil
+}
+
+// checkObject returns the entry of file if the object is not changed since the snapshot, or the version of
+// object is recorded.
+func (m *manifestFileSystem) checkObject(name string) (*manifestEntry, error) {
+	entry, ok := m.entries[manifestName(name)]
+	if !ok {
+		return nil, syscall.ENOENT
+	}
+	if entry.VersionID != "" {
+		if _, ok := m.object.storage.(object.VersionedStorage); !ok {
+			log.Errorf("object[%s] of snapshot has version, but %s is not versioned", entry.Key, m.object.storage.String())
+			return nil, syscall.ENOTSUP
+		}
+		return entry, nil
+	}
+	key := m.object.objectKeyName(entry.Key)
+	head, err := m.object.storage.Head(key)
+	if err != nil {
+		if isNotExistErr(err) {
+			log.Errorf("object[%s] of snapshot is deleted", key)
+			return nil, syscall.ESTALE
+		}
+		return nil, err
+	}
+	if trimETag(head.ETag) != entry.ETag {
+		log.Errorf("object[%s] of snapshot is changed, etag[%s] expected[%s]", key, head.ETag, entry.ETag)
+		return nil, syscall.ESTALE
+	}
+	return entry, nil
+}
+
+func (m *manifestFileSystem) Open(name string, flags uint32, size uint64) (FileHandle, error) {
+	if isWriteFlags(flags) {
+		return nil, syscall.EROFS
+	}
+	entry, err := m.checkObject(name)
+	if err != nil {
+		return nil, err
+	}
+	fh, err := m.object.Open(name, flags, entry.Size)
+	if err != nil || entry.VersionID == "" {
+		return fh, err
+	}
+	if objectFh, ok := fh.(*objectFileHandle); ok {
+		objectFh.storage = &versionStorage{
+			ObjectStorage: objectFh.storage,
+			versioned:     m.object.storage.(object.VersionedStorage),
+			versionID:     entry.VersionID,
+		}
+	}
+	return fh, nil
+}
+
+func (m *manifestFileSystem) Get(name string, flags uint32, off, limit int64) (io.ReadCloser, error) {
+	entry, err := m.checkObject(name)
+	if err != nil {
+		return nil, err
+	}
+	if entry.VersionID == "" {
+		return m.object.Get(name, flags, off, limit)
+	}
+	versioned := m.object.storage.(object.VersionedStorage)
+	return versioned.GetVersion(m.object.objectKeyName(entry.Key), entry.VersionID, off, limit)
+}
+
+// versionStorage reads the given version of objects
+type versionStorage struct {
+	object.ObjectStorage
+	versioned object.VersionedStorage
+	versionID string
+}
+
+func (s *versionStorage) Get(key string, off, limit int64) (io.ReadCloser, error) {
+	return s.versioned.GetVersion(key, s.versionID, off, limit)
+}
+
+// ============================================================= local ============================================================= //
+
+// CreateSnapshot copies the fs into .snapshot/name, files are cloned with copy-on-write if the underlying
+// file system supports reflink.
+func (fs *LocalFileSystem) CreateSnapshot(name string) (*SnapshotInfo, error) {
+	dst := fs.GetPath(snapshotPath(name))
+	if _, err := os.Lstat(dst); err == nil {
+		return nil, syscall.EEXIST
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return nil, err
+	}
+
+	info := &SnapshotInfo{}
+	err := filepath.Walk(fs.subpath, func(src string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(fs.subpath, src)
+		if err != nil {
+			return err
+		}
+		if rel == common.SnapshotDir {
+			return filepath.SkipDir
+		}
+		target := filepath.Join(dst, rel)
+		switch {
+		case fi.IsDir():
+			// keep the owner writable, or the children can not be copied
+			return os.MkdirAll(target, fi.Mode().Perm()|0700)
+		case fi.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(src)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case fi.Mode().IsRegular():
+			if err := copyFile(src, target, fi.Mode().Perm()); err != nil {
+				return err
+			}
+			info.FileCount++
+			info.Size += fi.Size()
+			return os.Chtimes(target, fi.ModTime(), fi.ModTime())
+		default:
+			// devices, pipes and sockets are not kept in snapshot
+			return nil
+		}
+	})
+	if err != nil {
+		os.RemoveAll(dst)
+		return nil, err
+	}
+	return info, nil
+}
+
+func (fs *LocalFileSystem) DeleteSnapshot(name string) error {
+	return os.RemoveAll(fs.GetPath(snapshotPath(name)))
+}
+
+func copyFile(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if err = cloneFile(out, in); err != nil {
+		_, err = io.Copy(out, in)
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// ============================================================= hdfs ============================================================= //
+
+// CreateSnapshot takes a native hdfs snapshot, the root of fs is made snapshottable first if needed, which
+// requires the superuser of hdfs.
+func (fs *hdfsFileSystem) CreateSnapshot(name string) (*SnapshotInfo, error) {
+	if _, err := fs.client.CreateSnapshot(fs.subpath, name); err != nil {
+		if allowErr := fs.client.AllowSnapshots(fs.subpath); allowErr != nil {
+			return nil, fmt.Errorf("create snapshot failed: %v, and allow snapshots failed: %v", err, allowErr)
+		}
+		if _, err = fs.client.CreateSnapshot(fs.subpath, name); err != nil {
+			return nil, err
+		}
+	}
+	info := &SnapshotInfo{}
+	summary, err := fs.client.GetContentSummary(fs.GetPath(snapshotPath(name)))
+	if err != nil {
+		log.Warningf("get content summary of snapshot[%s] failed: %v", name, err)
+		return info, nil
+	}
+	info.FileCount = int64(summary.FileCount())
+	info.Size = summary.Size()
+	return info, nil
+}
+
+func (fs *hdfsFileSystem) DeleteSnapshot(name string) error {
+	return fs.client.DeleteSnapshot(fs.subpath, name)
+}
+
+// ============================================================= read-only ============================================================= //
+
+// readOnlyFileSystem rejects all modifications to the under file storage with EROFS
+type readOnlyFileSystem struct {
+	UnderFileStorage
+}
+
+func isWriteFlags(flags uint32) bool {
+	return flags&syscall.O_ACCMODE != syscall.O_RDONLY || flags&syscall.O_TRUNC != 0
+}
+
+func (fs *readOnlyFileSystem) Chmod(name string, mode uint32) error {
+	return syscall.EROFS
+}
+
+func (fs *readOnlyFileSystem) Chown(name string, uid uint32, gid uint32) error {
+	return syscall.EROFS
+}
+
+func (fs *readOnlyFileSystem) Utimens(name string, Atime *time.Time, Mtime *time.Time) error {
+	return syscall.EROFS
+}
+
+func (fs *readOnlyFileSystem) Truncate(name string, size uint64) error {
+	return syscall.EROFS
+}
+
+func (fs *readOnlyFileSystem) Link(oldName string, newName string) error {
+	return syscall.EROFS
+}
+
+func (fs *readOnlyFileSystem) Mkdir(name string, mode uint32) error {
+	return syscall.EROFS
+}
+
+func (fs *readOnlyFileSystem) Mknod(name string, mode uint32, dev uint32) error {
+	return syscall.EROFS
+}
+
+func (fs *readOnlyFileSystem) Rename(oldName string, newName string) error {
+	return syscall.EROFS
+}
+
+func (fs *readOnlyFileSystem) Rmdir(name string) error {
+	return syscall.EROFS
+}
+
+func (fs *readOnlyFileSystem) Unlink(name string) error {
+	return syscall.EROFS
+}
+
+func (fs *readOnlyFileSystem) RemoveXAttr(name string, attr string) error {
+	return syscall.EROFS
+}
+
+func (fs *readOnlyFileSystem) SetXAttr(name string, attr string, data []byte, flags int) error {
+	return syscall.EROFS
+}
+
+func (fs *readOnlyFileSystem) Open(name string, flags uint32, size uint64) (FileHandle, error) {
+	if isWriteFlags(flags) {
+		return nil, syscall.EROFS
+	}
+	return fs.UnderFileStorage.Open(name, flags, size)
+}
+
+func (fs *readOnlyFileSystem) Create(name string, flags uint32, mode uint32) (FileHandle, error) {
+	return nil, syscall.EROFS
+}
+
+func (fs *readOnlyFileSystem) Symlink(value string, linkName string) error {
+	return syscall.EROFS
+}
+
+func (fs *readOnlyFileSystem) Put(name string, reader io.Reader) error {
+	return syscall.EROFS
+}
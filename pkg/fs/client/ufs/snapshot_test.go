@@ -0,0 +1,281 @@
+/*
+Copyright (c) 2021 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ufs
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/client/ufs/object"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/common"
+)
+
+func readAll(t *testing.T, fs UnderFileStorage, name string) string {
+	in, err := fs.Get(name, syscall.O_RDONLY, 0, 0)
+	assert.NoError(t, err)
+	defer in.Close()
+	data, err := ioutil.ReadAll(in)
+	assert.NoError(t, err)
+	return string(data)
+}
+
+func TestLocalSnapshot(t *testing.T) {
+	root := "/tmp/ufs/snapshot"
+	os.RemoveAll(root)
+	defer os.RemoveAll(root)
+	assert.NoError(t, os.MkdirAll(root+"/data", 0755))
+	assert.NoError(t, ioutil.WriteFile(root+"/data/a.txt", []byte("v1"), 0644))
+	assert.NoError(t, os.Symlink("data/a.txt", root+"/link"))
+
+	properties := map[string]interface{}{common.Type: common.LocalType, common.SubPath: root}
+	fs, err := NewUFS(common.LocalType, properties)
+	assert.NoError(t, err)
+	info, err := fs.(Snapshotter).CreateSnapshot("v1")
+	assert.NoError(t, err)
+	assert.Equal(t, SnapshotModeCopy, SnapshotMode(common.LocalType))
+	assert.Equal(t, int64(1), info.FileCount)
+	assert.Equal(t, int64(2), info.Size)
+	_, err = fs.(Snapshotter).CreateSnapshot("v1")
+	assert.Equal(t, syscall.EEXIST, err)
+
+	// snapshot is not changed by writes after it, and the next snapshot does not contain it
+	assert.NoError(t, ioutil.WriteFile(root+"/data/a.txt", []byte("v2"), 0644))
+	info, err = fs.(Snapshotter).CreateSnapshot("v2")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), info.FileCount)
+
+	properties[common.Snapshot] = "v1"
+	snapshotFs, err := NewUFS(common.LocalType, properties)
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", readAll(t, snapshotFs, "data/a.txt"))
+	target, err := snapshotFs.Readlink("link")
+	assert.NoError(t, err)
+	assert.Equal(t, "data/a.txt", target)
+	_, err = snapshotFs.Open("data/a.txt", syscall.O_RDWR, 0)
+	assert.Equal(t, syscall.EROFS, err)
+	assert.Equal(t, syscall.EROFS, snapshotFs.Unlink("data/a.txt"))
+	assert.Equal(t, syscall.EROFS, snapshotFs.Mkdir("new", 0755))
+	fh, err := snapshotFs.Open("data/a.txt", syscall.O_RDONLY, 0)
+	assert.NoError(t, err)
+	fh.Release()
+
+	properties[common.Snapshot] = "notexist"
+	_, err = NewUFS(common.LocalType, properties)
+	assert.Error(t, err)
+
+	assert.NoError(t, fs.(Snapshotter).DeleteSnapshot("v1"))
+	_, err = os.Stat(root + "/.snapshot/v1")
+	assert.True(t, os.IsNotExist(err))
+}
+
+type memObject struct {
+	data  []byte
+	mtime time.Time
+}
+
+// memStorage is an object storage in memory, only the methods used by snapshot are implemented
+type memStorage struct {
+	object.ObjectStorage
+	objects map[string]memObject
+}
+
+func etagOf(data []byte) string {
+	sum := md5.Sum(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+func (s *memStorage) Put(key string, in io.Reader) error {
+	data, err := ioutil.ReadAll(in)
+	if err != nil {
+		return err
+	}
+	s.objects[key] = memObject{data: data, mtime: time.Now()}
+	return nil
+}
+
+func (s *memStorage) Get(key string, off, limit int64) (io.ReadCloser, error) {
+	obj, ok := s.objects[key]
+	if !ok {
+		return nil, syscall.ENOENT
+	}
+	data := obj.data[off:]
+	if limit > 0 && limit < int64(len(data)) {
+		data = data[:limit]
+	}
+	return ioutil.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *memStorage) Head(key string) (*object.HeadObjectOutput, error) {
+	obj, ok := s.objects[key]
+	if !ok {
+		return nil, errors.New("NotFound")
+	}
+	return &object.HeadObjectOutput{ItemOutput: object.ItemOutput{
+		Key: key, ETag: etagOf(obj.data), Size: uint64(len(obj.data)), LastModified: obj.mtime}}, nil
+}
+
+func (s *memStorage) Deletes(keys []string) error {
+	for _, key := range keys {
+		delete(s.objects, key)
+	}
+	return nil
+}
+
+func (s *memStorage) List(input *object.ListInput) (*object.ListBlobsOutput, error) {
+	output := &object.ListBlobsOutput{}
+	for key, obj := range s.objects {
+		if strings.HasPrefix(key, input.Prefix) {
+			output.Items = append(output.Items, object.ItemOutput{
+				Key: key, ETag: etagOf(obj.data), Size: uint64(len(obj.data)), LastModified: obj.mtime})
+		}
+	}
+	sort.Slice(output.Items, func(i, j int) bool { return output.Items[i].Key < output.Items[j].Key })
+	return output, nil
+}
+
+func TestObjectSnapshot(t *testing.T) {
+	storage := &memStorage{objects: map[string]memObject{}}
+	fs := &objectFileSystem{subPath: "sub", storage: storage, defaultTime: time.Now()}
+	for key, content := range map[string]string{"sub/a.txt": "a", "sub/data/b.txt": "bb", "sub/empty/": "", "other/c": "c"} {
+		assert.NoError(t, storage.Put(key, strings.NewReader(content)))
+	}
+
+	info, err := fs.CreateSnapshot("v1")
+	assert.NoError(t, err)
+	assert.Equal(t, SnapshotModeManifest, SnapshotMode(common.BosType))
+	assert.Equal(t, int64(2), info.FileCount)
+	assert.Equal(t, int64(3), info.Size)
+	_, ok := storage.objects["sub/.snapshot/v1.manifest"]
+	assert.True(t, ok)
+	_, err = fs.CreateSnapshot("v1")
+	assert.Equal(t, syscall.EEXIST, err)
+
+	// files created after the snapshot are not in it
+	assert.NoError(t, storage.Put("sub/new.txt", strings.NewReader("new")))
+	m, err := newManifestFileSystem(fs, "v1")
+	assert.NoError(t, err)
+	entries, err := m.ReadDir("/")
+	assert.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name)
+	}
+	assert.Equal(t, []string{"a.txt", "data", "empty"}, names)
+	finfo, err := m.GetAttr("data/b.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), finfo.Size)
+	finfo, err = m.GetAttr("/data")
+	assert.NoError(t, err)
+	assert.True(t, finfo.IsDir)
+	_, err = m.GetAttr("new.txt")
+	assert.Equal(t, syscall.ENOENT, err)
+	assert.Equal(t, "bb", readAll(t, m, "data/b.txt"))
+
+	// objects changed after the snapshot can not be read
+	assert.NoError(t, storage.Put("sub/data/b.txt", strings.NewReader("changed")))
+	_, err = m.Get("data/b.txt", syscall.O_RDONLY, 0, 0)
+	assert.Equal(t, syscall.ESTALE, err)
+	_, err = m.Open("a.txt", syscall.O_WRONLY, 0)
+	assert.Equal(t, syscall.EROFS, err)
+	assert.Equal(t, syscall.EROFS, m.Unlink("a.txt"))
+
+	assert.NoError(t, fs.DeleteSnapshot("v1"))
+	_, err = newManifestFileSystem(fs, "v1")
+	assert.Error(t, err)
+}
+
+// versionedMemStorage keeps all versions of objects in memory
+type versionedMemStorage struct {
+	*memStorage
+	versions map[string][]memObject
+}
+
+func (s *versionedMemStorage) Put(key string, in io.Reader) error {
+	if err := s.memStorage.Put(key, in); err != nil {
+		return err
+	}
+	s.versions[key] = append(s.versions[key], s.objects[key])
+	return nil
+}
+
+func (s *versionedMemStorage) VersioningEnabled() (bool, error) {
+	return true, nil
+}
+
+func (s *versionedMemStorage) ListLatestVersions(input *object.ListInput) (*object.ListBlobsOutput, error) {
+	output, err := s.memStorage.List(input)
+	if err != nil {
+		return nil, err
+	}
+	for i := range output.Items {
+		output.Items[i].VersionID = strconv.Itoa(len(s.versions[output.Items[i].Key]) - 1)
+	}
+	return output, nil
+}
+
+func (s *versionedMemStorage) GetVersion(key, versionID string, off, limit int64) (io.ReadCloser, error) {
+	v, err := strconv.Atoi(versionID)
+	if err != nil || v >= len(s.versions[key]) {
+		return nil, syscall.ENOENT
+	}
+	data := s.versions[key][v].data[off:]
+	if limit > 0 && limit < int64(len(data)) {
+		data = data[:limit]
+	}
+	return ioutil.NopCloser(bytes.NewReader(data)), nil
+}
+
+func TestObjectSnapshotWithVersion(t *testing.T) {
+	storage := &versionedMemStorage{
+		memStorage: &memStorage{objects: map[string]memObject{}},
+		versions:   map[string][]memObject{},
+	}
+	fs := &objectFileSystem{subPath: "sub", storage: storage, defaultTime: time.Now()}
+	assert.NoError(t, storage.Put("sub/a.txt", strings.NewReader("a")))
+	assert.NoError(t, storage.Put("sub/data/b.txt", strings.NewReader("bb")))
+
+	_, err := fs.CreateSnapshot("v1")
+	assert.NoError(t, err)
+	m, err := newManifestFileSystem(fs, "v1")
+	assert.NoError(t, err)
+
+	// objects overwritten or deleted after the snapshot are read by version
+	assert.NoError(t, storage.Put("sub/data/b.txt", strings.NewReader("changed")))
+	assert.NoError(t, storage.Deletes([]string{"sub/a.txt"}))
+	assert.Equal(t, "bb", readAll(t, m, "data/b.txt"))
+	assert.Equal(t, "a", readAll(t, m, "a.txt"))
+
+	fh, err := m.Open("data/b.txt", syscall.O_RDONLY, 0)
+	assert.NoError(t, err)
+	buf := make([]byte, 2)
+	n, err := fh.Read(buf, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "bb", string(buf[:n]))
+}
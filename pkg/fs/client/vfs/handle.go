@@ -35,8 +35,16 @@ type handle struct {
 	// internal files
 	off  uint64
 	data []byte
+
+	// locks records the kinds of locks got by the handle, which are released when the handle is released
+	locks      uint8
+	flockOwner uint64
 }
 
+const (
+	handleFlock uint8 = 1 << iota
+)
+
 func (v *VFS) newHandle(inode Ino) *handle {
 	v.handleLock.Lock()
 	defer v.handleLock.Unlock()
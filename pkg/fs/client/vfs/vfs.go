@@ -17,7 +17,10 @@ limitations under the License.
 package vfs
 
 import (
+	"math"
 	"os"
+	pathlib "path"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -51,12 +54,17 @@ type VFS struct {
 	Meta       meta.Meta
 	Store      cache.Store
 	registry   *prometheus.Registry
+	// readOnlyDirs can not be modified through vfs, such as the config dir maintained by server
+	readOnlyDirs []string
+	// enableLocks is whether flock and fcntl locks are handled by meta, otherwise they are handled by kernel
+	enableLocks bool
 }
 
 type Config struct {
-	Cache *cache.Config
-	owner *Owner
-	Meta  *meta.Config
+	Cache       *cache.Config
+	owner       *Owner
+	Meta        *meta.Config
+	enableLocks bool
 }
 
 type Owner struct {
@@ -101,6 +109,12 @@ func WithMetaConfig(m meta.Config) Option {
 	}
 }
 
+func WithLocks(enable bool) Option {
+	return func(config *Config) {
+		config.enableLocks = enable
+	}
+}
+
 func WithDataCacheConfig(data cache.Config) Option {
 	return func(config *Config) {
 		config.Cache = &data
@@ -113,9 +127,12 @@ func InitVFS(fsMeta common.FSMeta, links map[string]common.FSMeta, global bool,
 		fsMeta:   fsMeta,
 		registry: registry,
 	}
+	// snapshots are only created and deleted by server
+	vfs.SetReadOnlyDirs(common.SnapshotDir)
 	if config == nil {
 		config = &Config{}
 	}
+	vfs.enableLocks = config.enableLocks
 	vfsMeta, err := meta.NewMeta(fsMeta, links, config.Meta)
 
 	if err != nil {
@@ -159,6 +176,33 @@ func GetVFS() *VFS {
 	return vfsop
 }
 
+// Shutdown releases the meta of vfs, vfs can not be used after shutdown.
+func (v *VFS) Shutdown() error {
+	return v.Meta.Shutdown()
+}
+
+// SetReadOnlyDirs rejects the modification of dirs and the paths under them
+func (v *VFS) SetReadOnlyDirs(dirs ...string) {
+	for _, dir := range dirs {
+		v.readOnlyDirs = append(v.readOnlyDirs, pathlib.Clean("/"+dir))
+	}
+}
+
+// checkWritable returns EPERM if entry name under parent is read only, or parent itself if name is empty
+func (v *VFS) checkWritable(parent Ino, name string) syscall.Errno {
+	if len(v.readOnlyDirs) == 0 || IsSpecialNode(parent) {
+		return syscall.F_OK
+	}
+	path := pathlib.Join(v.Meta.InoToPath(parent), name)
+	for _, dir := range v.readOnlyDirs {
+		if path == dir || strings.HasPrefix(path, dir+"/") {
+			log.Debugf("vfs path[%s] is read only", path)
+			return syscall.EPERM
+		}
+	}
+	return syscall.F_OK
+}
+
 func (v *VFS) getUFS(name string) (ufslib.UnderFileStorage, bool, string, string) {
 	return v.Meta.GetUFS(name)
 }
@@ -213,9 +257,15 @@ func (v *VFS) GetAttr(ctx *meta.Context, ino Ino) (entry *meta.Entry, err syscal
 
 func (v *VFS) SetAttr(ctx *meta.Context, ino Ino, set, mode, uid, gid uint32, atime, mtime int64, atimensec, mtimensec uint32, size uint64) (entry *meta.Entry, err syscall.Errno) {
 	log.Debugf("vfs setAttr: ino[%d], set[%d], mode[%d], uid[%d], gid[%d], size[%d]", ino, set, mode, uid, gid, size)
+	if err = v.checkWritable(ino, ""); utils.IsError(err) {
+		return
+	}
 
 	// only truncate opened files
 	if set&meta.FATTR_SIZE != 0 {
+		if err = v.checkFileQuota(ctx, ino, size); utils.IsError(err) {
+			return entry, err
+		}
 		fhs := v.findAllHandle(ino)
 		if fhs != nil {
 			for _, h := range fhs {
@@ -282,6 +332,12 @@ func (v *VFS) Mknod(ctx *meta.Context, parent Ino, name string, mode uint32, rde
 		err = syscall.EPERM
 		return
 	}
+	if err = v.checkWritable(parent, name); utils.IsError(err) {
+		return
+	}
+	if err = v.Meta.CheckQuota(ctx, parent, 0, 1); utils.IsError(err) {
+		return
+	}
 	err = v.Meta.Mknod(ctx, parent, name, _type, mode&07777, 0, rdev, &ino, attr)
 	entry = &meta.Entry{Ino: ino, Attr: attr}
 	return
@@ -290,17 +346,29 @@ func (v *VFS) Mknod(ctx *meta.Context, parent Ino, name string, mode uint32, rde
 func (v *VFS) Mkdir(ctx *meta.Context, parent Ino, name string, mode uint32, cumask uint16) (entry *meta.Entry, err syscall.Errno) {
 	var ino Ino
 	attr := &Attr{}
+	if err = v.checkWritable(parent, name); utils.IsError(err) {
+		return
+	}
+	if err = v.Meta.CheckQuota(ctx, parent, 0, 1); utils.IsError(err) {
+		return
+	}
 	err = v.Meta.Mkdir(ctx, parent, name, mode, cumask, &ino, attr)
 	entry = &meta.Entry{Ino: ino, Attr: attr}
 	return
 }
 
 func (v *VFS) Unlink(ctx *meta.Context, parent Ino, name string) (err syscall.Errno) {
+	if err = v.checkWritable(parent, name); utils.IsError(err) {
+		return err
+	}
 	err = v.Meta.Unlink(ctx, parent, name)
 	return err
 }
 
 func (v *VFS) Rmdir(ctx *meta.Context, parent Ino, name string) (err syscall.Errno) {
+	if err = v.checkWritable(parent, name); utils.IsError(err) {
+		return err
+	}
 	err = v.Meta.Rmdir(ctx, parent, name)
 	return err
 }
@@ -316,6 +384,12 @@ func (v *VFS) Rmdir(ctx *meta.Context, parent Ino, name string) (err syscall.Err
 func (v *VFS) Rename(ctx *meta.Context, parent Ino, name string, newparent Ino, newname string, flags uint32) (err syscall.Errno) {
 	var ino Ino
 	attr := &Attr{}
+	if err = v.checkWritable(parent, name); utils.IsError(err) {
+		return err
+	}
+	if err = v.checkWritable(newparent, newname); utils.IsError(err) {
+		return err
+	}
 	src, dst, err := v.Meta.Rename(ctx, parent, name, newparent, newname, flags, &ino, attr)
 	if utils.IsError(err) {
 		return err
@@ -341,15 +415,44 @@ func (v *VFS) Link(ctx *meta.Context, ino Ino, newparent Ino, newname string) (e
 		err = syscall.EPERM
 		return
 	}
-	return nil, syscall.ENOSYS
+	var inode Ino
+	attr := &Attr{}
+	// the link to a read only file is not allowed either, as the file can be modified through the link
+	if err = v.checkWritable(ino, ""); utils.IsError(err) {
+		return
+	}
+	if err = v.checkWritable(newparent, newname); utils.IsError(err) {
+		return
+	}
+	if err = v.Meta.CheckQuota(ctx, newparent, 0, 1); utils.IsError(err) {
+		return
+	}
+	err = v.Meta.Link(ctx, ino, newparent, newname, &inode, attr)
+	entry = &meta.Entry{Ino: inode, Attr: attr}
+	return
 }
 
 func (v *VFS) Symlink(ctx *meta.Context, path string, parent Ino, name string) (entry *meta.Entry, err syscall.Errno) {
-	return nil, syscall.ENOSYS
+	var ino Ino
+	attr := &Attr{}
+	if err = v.checkWritable(parent, name); utils.IsError(err) {
+		return
+	}
+	if err = v.Meta.CheckQuota(ctx, parent, int64(len(path)), 1); utils.IsError(err) {
+		return
+	}
+	err = v.Meta.Symlink(ctx, parent, name, path, &ino, attr)
+	entry = &meta.Entry{Ino: ino, Attr: attr}
+	return
 }
 
 func (v *VFS) Readlink(ctx *meta.Context, ino Ino) (path []byte, err syscall.Errno) {
-	return nil, syscall.ENOSYS
+	if IsSpecialNode(ino) {
+		err = syscall.EINVAL
+		return
+	}
+	err = v.Meta.ReadLink(ctx, ino, &path)
+	return
 }
 
 func (v *VFS) Access(ctx *meta.Context, ino Ino, mask uint32) (err syscall.Errno) {
@@ -401,6 +504,9 @@ func (v *VFS) SetXAttr(ctx *meta.Context, ino Ino, name string, value []byte, fl
 		err = syscall.EPERM
 		return
 	}
+	if err = v.checkWritable(ino, ""); utils.IsError(err) {
+		return
+	}
 	err = v.Meta.SetXattr(ctx, ino, name, value, flags)
 	return
 }
@@ -411,6 +517,9 @@ func (v *VFS) RemoveXAttr(ctx *meta.Context, ino Ino, name string) (err syscall.
 		err = syscall.EPERM
 		return
 	}
+	if err = v.checkWritable(ino, ""); utils.IsError(err) {
+		return
+	}
 	err = v.Meta.RemoveXattr(ctx, ino, name)
 	return
 }
@@ -419,6 +528,12 @@ func (v *VFS) RemoveXAttr(ctx *meta.Context, ino Ino, name string) (err syscall.
 func (v *VFS) Create(ctx *meta.Context, parent Ino, name string, mode uint32, cumask uint16, flags uint32) (entry *meta.Entry, fh uint64, err syscall.Errno) {
 	var ino Ino
 	attr := &Attr{}
+	if err = v.checkWritable(parent, name); utils.IsError(err) {
+		return
+	}
+	if err = v.Meta.CheckQuota(ctx, parent, 0, 1); utils.IsError(err) {
+		return
+	}
 	ufs, path, err := v.Meta.Create(ctx, parent, name, mode, cumask, flags, &ino, attr)
 	if utils.IsError(err) {
 		return
@@ -462,6 +577,11 @@ func (v *VFS) Open(ctx *meta.Context, ino Ino, flags uint32) (entry *meta.Entry,
 			return
 		}
 	}
+	if (flags&syscall.O_ACCMODE) != syscall.O_RDONLY || flags&syscall.O_TRUNC != 0 {
+		if err = v.checkWritable(ino, ""); utils.IsError(err) {
+			return
+		}
+	}
 	ufs, path, err := v.Meta.Open(ctx, ino, flags, attr)
 	if utils.IsError(err) {
 		return
@@ -523,16 +643,54 @@ func (v *VFS) Read(ctx *meta.Context, ino Ino, buf []byte, off uint64, fh uint64
 }
 
 // File locking
-func (v *VFS) GetLk(ctx *meta.Context, ino Ino, fh uint64, owner uint64, start, len *uint64, typ *uint32, pid *uint32) (err syscall.Errno) {
-	return syscall.ENOSYS
+func (v *VFS) GetLk(ctx *meta.Context, ino Ino, fh uint64, owner uint64, start, end *uint64, typ *uint32, pid *uint32) (err syscall.Errno) {
+	if IsSpecialNode(ino) {
+		return syscall.EPERM
+	}
+	if v.findHandle(ino, fh) == nil {
+		return syscall.EBADF
+	}
+	return v.Meta.Getlk(ctx, ino, owner, typ, start, end, pid)
 }
 
+// SetLk sets posix lock on range [start, end] of file, and waits for the conflicting locks released if block is true
 func (v *VFS) SetLk(ctx *meta.Context, ino Ino, fh uint64, owner uint64, start, end uint64, typ uint32, pid uint32, block bool) (err syscall.Errno) {
-	return syscall.ENOSYS
+	if IsSpecialNode(ino) {
+		return syscall.EPERM
+	}
+	h := v.findHandle(ino, fh)
+	if h == nil {
+		return syscall.EBADF
+	}
+	return v.Meta.Setlk(ctx, ino, owner, block, typ, start, end, pid)
 }
 
 func (v *VFS) SetLkw(ctx *meta.Context, ino Ino, fh uint64, owner uint64, start, end uint64, typ uint32, pid uint32, block bool) (err syscall.Errno) {
-	return syscall.ENOSYS
+	return v.SetLk(ctx, ino, fh, owner, start, end, typ, pid, true)
+}
+
+// Flock sets BSD lock on the whole file, and the lock is released when the handle is released
+func (v *VFS) Flock(ctx *meta.Context, ino Ino, fh uint64, owner uint64, typ uint32, block bool) (err syscall.Errno) {
+	if IsSpecialNode(ino) {
+		return syscall.EPERM
+	}
+	h := v.findHandle(ino, fh)
+	if h == nil {
+		return syscall.EBADF
+	}
+	err = v.Meta.Flock(ctx, ino, owner, typ, block)
+	if err != syscall.F_OK {
+		return err
+	}
+	h.lock.Lock()
+	if typ == syscall.F_UNLCK {
+		h.locks &^= handleFlock
+	} else {
+		h.locks |= handleFlock
+		h.flockOwner = owner
+	}
+	h.lock.Unlock()
+	return err
 }
 
 func (v *VFS) Write(ctx *meta.Context, ino Ino, buf []byte, off, fh uint64) (err syscall.Errno) {
@@ -545,6 +703,9 @@ func (v *VFS) Write(ctx *meta.Context, ino Ino, buf []byte, off, fh uint64) (err
 		err = syscall.EACCES
 		return
 	}
+	if err = v.checkFileQuota(ctx, ino, off+uint64(len(buf))); utils.IsError(err) {
+		return err
+	}
 	h.lock.Lock()
 	defer h.lock.Unlock()
 	err = h.writer.Write(buf, off)
@@ -579,6 +740,14 @@ func (v *VFS) Flush(ctx *meta.Context, ino Ino, fh uint64, lockOwner uint64) (er
 	if h.writer != nil {
 		err = h.writer.Flush()
 	}
+	// posix locks of the owner are released when any handle of the file is closed, no matter which handle
+	// the locks are got by. There is no lock in meta when locks are handled by kernel.
+	if !v.enableLocks {
+		return err
+	}
+	if errLk := v.Meta.Setlk(ctx, ino, lockOwner, false, syscall.F_UNLCK, 0, math.MaxUint64, 0); errLk != syscall.F_OK {
+		log.Errorf("release posix locks of inode[%d] owner[%d] failed: %v", ino, lockOwner, errLk)
+	}
 	return err
 }
 
@@ -601,7 +770,28 @@ func (v *VFS) Fallocate(ctx *meta.Context, ino Ino, mode uint8, off, length int6
 	if IsSpecialNode(ino) {
 		return syscall.EPERM
 	}
-	return syscall.ENOSYS
+	if off < 0 || length <= 0 {
+		return syscall.EINVAL
+	}
+	h := v.findHandle(ino, fh)
+	if h == nil {
+		return syscall.EBADF
+	}
+	if h.writer == nil {
+		return syscall.EBADF
+	}
+	if mode&meta.FALLOC_FL_KEEP_SIZE == 0 {
+		if err := v.checkFileQuota(ctx, ino, uint64(off+length)); utils.IsError(err) {
+			return err
+		}
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	err := h.writer.Fallocate(length, off, uint32(mode))
+	if utils.IsError(err) {
+		return err
+	}
+	return v.Meta.Fallocate(ctx, ino, mode, uint64(off), uint64(length))
 }
 
 // Directory handling
@@ -651,12 +841,30 @@ func (v *VFS) Release(ctx *meta.Context, ino Ino, fh uint64) {
 		return
 	}
 	if fh > 0 {
+		v.releaseLocks(ctx, ino, fh)
 		v.releaseFileHandle(ino, fh)
 		log.Debugf("release inode %v", ino)
 	}
 	_ = v.Meta.Close(ctx, ino)
 }
 
+// releaseLocks releases the BSD lock of handle, which is shared by the duplicated file descriptors
+func (v *VFS) releaseLocks(ctx *meta.Context, ino Ino, fh uint64) {
+	h := v.findHandle(ino, fh)
+	if h == nil {
+		return
+	}
+	h.lock.Lock()
+	locks, owner := h.locks, h.flockOwner
+	h.locks = 0
+	h.lock.Unlock()
+	if locks&handleFlock != 0 {
+		if err := v.Meta.Flock(ctx, ino, owner, syscall.F_UNLCK, false); err != syscall.F_OK {
+			log.Errorf("release flock of inode[%d] owner[%d] failed: %v", ino, owner, err)
+		}
+	}
+}
+
 func (v *VFS) StatFs(ctx *meta.Context) (*base.StatfsOut, syscall.Errno) {
 	statFs, err := v.Meta.StatFS(ctx)
 	if utils.IsError(err) {
@@ -665,6 +873,21 @@ func (v *VFS) StatFs(ctx *meta.Context) (*base.StatfsOut, syscall.Errno) {
 	return statFs, syscall.F_OK
 }
 
+// checkFileQuota returns EDQUOT if the file exceeds the quotas covering it after growing to size.
+func (v *VFS) checkFileQuota(ctx *meta.Context, ino Ino, size uint64) syscall.Errno {
+	if !v.Meta.HasQuota() {
+		return syscall.F_OK
+	}
+	attr := &Attr{}
+	if err := v.Meta.GetAttr(ctx, ino, attr); utils.IsError(err) {
+		return err
+	}
+	if attr.Type != meta.TypeFile || size <= attr.Size {
+		return syscall.F_OK
+	}
+	return v.Meta.CheckQuota(ctx, ino, int64(size-attr.Size), 0)
+}
+
 func (v *VFS) Truncate(ctx *meta.Context, ino Ino, size, fh uint64) (err syscall.Errno) {
 	log.Tracef("vfs truncate: ino[%d], size[%d], fh[%d]", ino, size, fh)
 	if IsSpecialNode(ino) {
@@ -682,6 +905,9 @@ func (v *VFS) Truncate(ctx *meta.Context, ino Ino, size, fh uint64) (err syscall
 		log.Errorf("vfs truncate: no file writer")
 		return
 	}
+	if err = v.checkFileQuota(ctx, ino, size); utils.IsError(err) {
+		return err
+	}
 
 	err = h.writer.Truncate(size)
 	if utils.IsError(err) {
@@ -94,8 +94,22 @@ const (
 	// Link Meta
 	LinkMetaDir  = ".config"
 	LinkMetaFile = "links_meta"
+	// QuotaMetaFile keeps the quotas of fs in LinkMetaDir
+	QuotaMetaFile = "quota_meta"
+
+	// Snapshot is the property of a read-only fs mounted from the snapshot with this name
+	Snapshot = "snapshot"
+	// SnapshotDir keeps the snapshots under the root of fs, the same as hdfs
+	SnapshotDir = ".snapshot"
 )
 
+// FsQuota limits the bytes and inodes used under Path of fs, 0 means no limit
+type FsQuota struct {
+	Path      string `json:"path"`
+	MaxBytes  int64  `json:"maxBytes"`
+	MaxInodes int64  `json:"maxInodes"`
+}
+
 type FSMeta struct {
 	ID            string
 	Name          string
@@ -33,11 +33,14 @@ import (
 	"k8s.io/client-go/util/workqueue"
 
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
-	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/common"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/csiplugin/csiconfig"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/csiplugin/mount"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/utils"
 )
 
+// upgradeBindRetryTimes is how many times the volume is bound to new mount pod during upgrade
+const upgradeBindRetryTimes = 3
+
 var mountPointController *MountPointController
 
 // checkerStopChan informs stop commands
@@ -260,7 +263,28 @@ func (m *MountPointController) CheckAndRemountVolumeMount(volumeMount volumeMoun
 		return err
 	}
 
-	if checkIfNeedRemount(mountPath) {
+	needRemount := checkIfNeedRemount(mountPath)
+	if mountInfo.IsPodMount() {
+		if mountInfo.K8sClient, err = utils.GetK8sClient(); err != nil {
+			log.Errorf("get k8s client failed: %v", err)
+			return err
+		}
+		mountPod, err := mount.GetMountPodByTargetPath(mountInfo.K8sClient, mountPath)
+		if err != nil {
+			return err
+		}
+		if mountPod == nil {
+			mountInfo.SourcePath = mount.PodBindSource(volumeMount.VolumeName, mountInfo)
+		} else {
+			mountInfo.SourcePath = mount.BindSourceOfPod(mountPod)
+			if !needRemount && csiconfig.MountPodRollingUpgrade &&
+				mount.IsMountPodOutdated(mountPod, volumeMount.VolumeName, mountInfo) {
+				return upgradeVolumeMount(volumeMount, mountInfo, mountPod.Name)
+			}
+		}
+	}
+
+	if needRemount {
 		log.Infof("pvParams %v volumeMount %s mountInfo %v", pvParams_, mountPath, mountInfo)
 		if err := remount(volumeMount, mountInfo); err != nil {
 			err := fmt.Errorf("remount info: %+v failed: %v", mountInfo, err)
@@ -291,20 +315,15 @@ func waitForBindSourceReady(bindSource string) bool {
 // CheckIfNeedRemount The conditions for remount: the path is the mount point and the error message returned by the `mountpoint` command
 // contains "Transport endpoint is not connected"
 func checkIfNeedRemount(path string) bool {
-	isMountPoint, err := utils.IsMountPoint(path)
-	log.Tracef("mountpoint path[%s] : isMountPoint[%t], err:%v", path, isMountPoint, err)
-	if err != nil && isMountPoint {
-		return true
-	}
-	return false
+	return utils.IsStaleMountPoint(path)
 }
 
 func remount(volumeMount volumeMountInfo, mountInfo mount.Info) error {
 	log.Tracef("remount: mountInfo %+v", mountInfo)
 
-	if !mountInfo.FS.IndependentMountProcess && mountInfo.FS.Type != common.GlusterFSType && mountInfo.FS.Type != common.CFSType && mountInfo.FS.Type != common.AFSType {
+	if mountInfo.IsPodMount() {
 		// wait for source path ready
-		if !waitForBindSourceReady(schema.GetBindSource(mountInfo.FS.ID)) {
+		if !waitForBindSourceReady(mountInfo.SourcePath) {
 			return nil
 		}
 	} else {
@@ -362,6 +381,66 @@ func remount(volumeMount volumeMountInfo, mountInfo mount.Info) error {
 	return nil
 }
 
+// upgradeVolumeMount moves the volume of a running pod from an outdated mount pod to the one built with current
+// image and cache config. Only the target path on host is switched, the running containers keep the mount of
+// outdated pod in their mount namespaces, and they only pick up the new mount after restarted. So the outdated
+// pod keeps the reference of work pod, and is deleted when all the work pods referencing it are gone.
+func upgradeVolumeMount(volumeMount volumeMountInfo, mountInfo mount.Info, outdatedPodName string) error {
+	log.Infof("upgrade volume[%s] of pod[%s] from outdated mount pod[%s]", volumeMount.VolumeName,
+		volumeMount.PodUID, outdatedPodName)
+	if err := mount.PFSMount(volumeMount.VolumeName, mountInfo); err != nil {
+		return err
+	}
+	mountInfo.SourcePath = mount.PodBindSource(volumeMount.VolumeName, mountInfo)
+	if !waitForBindSourceReady(mountInfo.SourcePath) {
+		return fmt.Errorf("bind source[%s] of new mount pod not ready", mountInfo.SourcePath)
+	}
+	// detach the volume from outdated mount pod, and bind the new source in place
+	for _, subPath := range volumeMount.SubPaths {
+		if err := utils.ManualUnmount(subPath.TargetPath); err != nil {
+			return err
+		}
+	}
+	if err := utils.ManualUnmount(mountInfo.TargetPath); err != nil {
+		return err
+	}
+	// the unmounted target path is not a stale mount point, which is not recovered by the next check,
+	// so binding is retried to close the gap between unmount and remount
+	var err error
+	for i := 0; i < upgradeBindRetryTimes; i++ {
+		if i > 0 {
+			time.Sleep(time.Second)
+		}
+		if err = bindVolumeMount(volumeMount, mountInfo); err == nil {
+			return nil
+		}
+		log.Warnf("bind volume[%s] of pod[%s] to new mount pod failed, retry: %v", volumeMount.VolumeName,
+			volumeMount.PodUID, err)
+	}
+	return err
+}
+
+// bindVolumeMount binds the source path to target path and sub paths, the paths already mounted are skipped,
+// so it can be retried without stacking mounts
+func bindVolumeMount(volumeMount volumeMountInfo, mountInfo mount.Info) error {
+	bindPaths := append([]SubPath{{
+		ReadOnly:   mountInfo.ReadOnly,
+		SourcePath: mountInfo.SourcePath,
+		TargetPath: mountInfo.TargetPath,
+	}}, volumeMount.SubPaths...)
+	for _, bindPath := range bindPaths {
+		if isMountPoint, err := utils.IsMountPoint(bindPath.TargetPath); err == nil && isMountPoint {
+			continue
+		}
+		output, err := utils.ExecMountBind(bindPath.SourcePath, bindPath.TargetPath, bindPath.ReadOnly)
+		if err != nil {
+			log.Errorf("exec mount bind cmd failed: %v, output[%s]", err, string(output))
+			return err
+		}
+	}
+	return nil
+}
+
 // UpdateMounts update mount
 func (m *MountPointController) UpdateMounts(volumeMount volumeMountInfo) error {
 	// TODO(dongzezhao): update mounts
@@ -33,12 +33,26 @@ var (
 	AESKey     = ""
 
 	CSIPod = corev1.Pod{}
+
+	// MountShareMode decides which volumes share a mount pod on the node
+	MountShareMode = MountShareModePVC
+	// MountPodRollingUpgrade enables switching the volumes of running workloads to the mount pods with current
+	// config. Running containers keep using the outdated mount pods, which are kept until the workloads are gone,
+	// so a node may run both the pods meanwhile.
+	MountPodRollingUpgrade = false
 )
 
 const (
 	PodTypeKey = "app.kubernetes.io/name"
 	PodMount   = "pfs-mount"
 
+	// MountShareModeFS shares one mount pod among all volumes of a filesystem
+	MountShareModeFS = "fs"
+	// MountShareModePVC starts a mount pod for each volume, so volumes of a filesystem can use different cache configs
+	MountShareModePVC = "pvc"
+	// MountShareModePod starts a mount pod for each volume of each workload pod
+	MountShareModePod = "pod"
+
 	// default value
 	defaultMountPodCpuLimit   = "2"
 	defaultMountPodMemLimit   = "1Gi"
@@ -98,3 +112,12 @@ func ParsePodResources(cpuLimit, memoryLimit string) (corev1.ResourceRequirement
 	}
 	return podResource, nil
 }
+
+func IsValidMountShareMode(mode string) bool {
+	switch mode {
+	case MountShareModeFS, MountShareModePVC, MountShareModePod:
+		return true
+	default:
+		return false
+	}
+}
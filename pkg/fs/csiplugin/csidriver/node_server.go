@@ -17,9 +17,11 @@ limitations under the License.
 package csidriver
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"syscall"
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	"github.com/kubernetes-csi/drivers/pkg/csi-common"
@@ -42,14 +44,22 @@ type nodeServer struct {
 }
 
 func (ns *nodeServer) NodeGetCapabilities(ctx context.Context, req *csi.NodeGetCapabilitiesRequest) (*csi.NodeGetCapabilitiesResponse, error) {
-	nscap := &csi.NodeServiceCapability{
-		Type: &csi.NodeServiceCapability_Rpc{
-			Rpc: &csi.NodeServiceCapability_RPC{
-				Type: csi.NodeServiceCapability_RPC_STAGE_UNSTAGE_VOLUME,
+	rpcTypes := []csi.NodeServiceCapability_RPC_Type{
+		csi.NodeServiceCapability_RPC_STAGE_UNSTAGE_VOLUME,
+		csi.NodeServiceCapability_RPC_GET_VOLUME_STATS,
+		csi.NodeServiceCapability_RPC_VOLUME_CONDITION,
+	}
+	nscaps := make([]*csi.NodeServiceCapability, 0, len(rpcTypes))
+	for _, rpcType := range rpcTypes {
+		nscaps = append(nscaps, &csi.NodeServiceCapability{
+			Type: &csi.NodeServiceCapability_Rpc{
+				Rpc: &csi.NodeServiceCapability_RPC{
+					Type: rpcType,
+				},
 			},
-		},
+		})
 	}
-	return &csi.NodeGetCapabilitiesResponse{Capabilities: []*csi.NodeServiceCapability{nscap}}, nil
+	return &csi.NodeGetCapabilitiesResponse{Capabilities: nscaps}, nil
 }
 
 func (ns *nodeServer) NodePublishVolume(ctx context.Context,
@@ -133,10 +143,94 @@ func (ns *nodeServer) NodeExpandVolume(ctx context.Context,
 	return nil, status.Error(codes.Unimplemented, "NodeExpandVolume is not implemented")
 }
 
+// NodeGetVolumeStats reports the capacity and inode usage of the fuse mount, which honours the fs quota, together
+// with the volume condition, which is abnormal when the mountpoint is stale or the mount pod is not ready
+func (ns *nodeServer) NodeGetVolumeStats(ctx context.Context,
+	req *csi.NodeGetVolumeStatsRequest) (*csi.NodeGetVolumeStatsResponse, error) {
+	volumeID, volumePath := req.GetVolumeId(), req.GetVolumePath()
+	if volumeID == "" || volumePath == "" {
+		return nil, status.Error(codes.InvalidArgument, "volume id and volume path must be provided")
+	}
+	if _, err := os.Lstat(volumePath); err != nil {
+		if os.IsNotExist(err) {
+			return nil, status.Errorf(codes.NotFound, "volume path[%s] not found", volumePath)
+		}
+		// stat of a stale fuse mountpoint fails with ENOTCONN
+		if errors.Is(err, syscall.ENOTCONN) || utils.IsStaleMountPoint(volumePath) {
+			log.Warningf("volume[%s] on path[%s] is stale: %v", volumeID, volumePath, err)
+			return &csi.NodeGetVolumeStatsResponse{VolumeCondition: staleCondition(volumePath)}, nil
+		}
+		log.Errorf("stat volume path[%s] failed: %v", volumePath, err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	condition, err := volumeCondition(volumePath)
+	if err != nil {
+		log.Errorf("check condition of volume[%s] failed: %v", volumeID, err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if condition.Abnormal {
+		log.Warningf("volume[%s] on path[%s] is abnormal: %s", volumeID, volumePath, condition.Message)
+		return &csi.NodeGetVolumeStatsResponse{VolumeCondition: condition}, nil
+	}
+
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(volumePath, &st); err != nil {
+		log.Errorf("statfs volume path[%s] failed: %v", volumePath, err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	bsize := int64(st.Bsize)
+	blocks, bfree, bavail := int64(st.Blocks), int64(st.Bfree), int64(st.Bavail)
+	files, ffree := int64(st.Files), int64(st.Ffree)
+	return &csi.NodeGetVolumeStatsResponse{
+		Usage: []*csi.VolumeUsage{
+			{
+				Unit:      csi.VolumeUsage_BYTES,
+				Total:     blocks * bsize,
+				Available: bavail * bsize,
+				Used:      (blocks - bfree) * bsize,
+			},
+			{
+				Unit:      csi.VolumeUsage_INODES,
+				Total:     files,
+				Available: ffree,
+				Used:      files - ffree,
+			},
+		},
+		VolumeCondition: condition,
+	}, nil
+}
+
+func volumeCondition(volumePath string) (*csi.VolumeCondition, error) {
+	if utils.IsStaleMountPoint(volumePath) {
+		return staleCondition(volumePath), nil
+	}
+	k8sClient, err := utils.GetK8sClient()
+	if err != nil {
+		return nil, err
+	}
+	reason, err := mount.CheckMountPod(k8sClient, volumePath)
+	if err != nil {
+		return nil, err
+	}
+	if reason != "" {
+		return &csi.VolumeCondition{Abnormal: true, Message: reason}, nil
+	}
+	return &csi.VolumeCondition{Abnormal: false, Message: "volume is healthy"}, nil
+}
+
+func staleCondition(volumePath string) *csi.VolumeCondition {
+	return &csi.VolumeCondition{
+		Abnormal: true,
+		Message:  fmt.Sprintf("mountpoint %s is stale, fuse client may have exited", volumePath),
+	}
+}
+
 func mountVolume(volumeID string, mountInfo mount.Info) error {
 	log.Infof("mountVolume: indepedentMp:%t, readOnly:%t", mountInfo.FS.IndependentMountProcess, mountInfo.ReadOnly)
-	if !mountInfo.FS.IndependentMountProcess && mountInfo.FS.Type != common.GlusterFSType && mountInfo.FS.Type != common.CFSType && mountInfo.FS.Type != common.AFSType {
+	if mountInfo.IsPodMount() {
 		// business pods use a separate source path
+		mountInfo.SourcePath = mount.PodBindSource(volumeID, mountInfo)
 		if err := mount.PFSMount(volumeID, mountInfo); err != nil {
 			log.Errorf("MountThroughPod err: %v", err)
 			return err
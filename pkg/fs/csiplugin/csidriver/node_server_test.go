@@ -1,10 +1,26 @@
 package csidriver
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
 	"testing"
 
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/common"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/csiplugin/csiconfig"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/csiplugin/mount"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/utils"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
 )
 
@@ -43,3 +59,97 @@ func Test_mountVolume(t *testing.T) {
 		})
 	}
 }
+
+func TestNodeGetCapabilities(t *testing.T) {
+	ns := &nodeServer{}
+	resp, err := ns.NodeGetCapabilities(context.Background(), &csi.NodeGetCapabilitiesRequest{})
+	assert.Nil(t, err)
+	var types []csi.NodeServiceCapability_RPC_Type
+	for _, c := range resp.Capabilities {
+		types = append(types, c.GetRpc().Type)
+	}
+	assert.Contains(t, types, csi.NodeServiceCapability_RPC_GET_VOLUME_STATS)
+	assert.Contains(t, types, csi.NodeServiceCapability_RPC_VOLUME_CONDITION)
+}
+
+func TestNodeGetVolumeStats(t *testing.T) {
+	ns := &nodeServer{}
+	volumePath := t.TempDir()
+	k8sClient := utils.GetFakeK8sClient()
+
+	// invalid argument and not found
+	_, err := ns.NodeGetVolumeStats(context.Background(), &csi.NodeGetVolumeStatsRequest{VolumeId: "pfs-fs-root-test-default-pv"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = ns.NodeGetVolumeStats(context.Background(), &csi.NodeGetVolumeStatsRequest{
+		VolumeId: "pfs-fs-root-test-default-pv", VolumePath: volumePath + "/notexist"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// healthy volume mounted by process
+	patches := gomonkey.ApplyFunc(utils.IsMountPoint, func(path string) (bool, error) {
+		return true, nil
+	})
+	resp, err := ns.NodeGetVolumeStats(context.Background(), &csi.NodeGetVolumeStatsRequest{
+		VolumeId: "pfs-fs-root-test-default-pv", VolumePath: volumePath})
+	assert.Nil(t, err)
+	assert.False(t, resp.VolumeCondition.Abnormal)
+	assert.Len(t, resp.Usage, 2)
+	assert.Equal(t, csi.VolumeUsage_BYTES, resp.Usage[0].Unit)
+	assert.True(t, resp.Usage[0].Total > 0)
+	assert.Equal(t, csi.VolumeUsage_INODES, resp.Usage[1].Unit)
+	patches.Reset()
+
+	// mount pod not ready
+	t.Setenv(utils.KubeletDataPathEnv, volumePath)
+	volumeID := "pfs-fs-root-pod-default-pv"
+	targetPath := filepath.Join(volumePath, "pods", "abc", "volumes", utils.VolumePluginName, volumeID, "mount")
+	assert.Nil(t, os.MkdirAll(targetPath, 0750))
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pfs-" + csiconfig.NodeName + "-" + volumeID + "-12345678",
+			Namespace: csiconfig.Namespace,
+			Labels: map[string]string{
+				csiconfig.PodTypeKey:    csiconfig.PodMount,
+				schema.LabelKeyNodeName: csiconfig.NodeName,
+			},
+			Annotations: map[string]string{
+				schema.AnnotationKeyMountPrefix + "abc": targetPath,
+			},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodPending},
+	}
+	_, err = k8sClient.CreatePod(pod)
+	assert.Nil(t, err)
+	patches = gomonkey.ApplyFunc(utils.IsMountPoint, func(path string) (bool, error) {
+		return true, nil
+	})
+	resp, err = ns.NodeGetVolumeStats(context.Background(), &csi.NodeGetVolumeStatsRequest{
+		VolumeId: volumeID, VolumePath: targetPath})
+	assert.Nil(t, err)
+	assert.True(t, resp.VolumeCondition.Abnormal)
+	assert.Contains(t, resp.VolumeCondition.Message, "not ready")
+	assert.Empty(t, resp.Usage)
+	patches.Reset()
+
+	// stale mountpoint
+	patches = gomonkey.ApplyFunc(utils.IsMountPoint, func(path string) (bool, error) {
+		return true, errors.New("transport endpoint is not connected")
+	})
+	defer patches.Reset()
+	resp, err = ns.NodeGetVolumeStats(context.Background(), &csi.NodeGetVolumeStatsRequest{
+		VolumeId: "pfs-fs-root-test-default-pv", VolumePath: volumePath})
+	assert.Nil(t, err)
+	assert.True(t, resp.VolumeCondition.Abnormal)
+	assert.Contains(t, resp.VolumeCondition.Message, "stale")
+	patches.Reset()
+
+	// stat of stale fuse mountpoint fails
+	patches = gomonkey.ApplyFunc(os.Lstat, func(name string) (os.FileInfo, error) {
+		return nil, &os.PathError{Op: "lstat", Path: name, Err: syscall.ENOTCONN}
+	})
+	resp, err = ns.NodeGetVolumeStats(context.Background(), &csi.NodeGetVolumeStatsRequest{
+		VolumeId: "pfs-fs-root-test-default-pv", VolumePath: volumePath})
+	assert.Nil(t, err)
+	assert.True(t, resp.VolumeCondition.Abnormal)
+	assert.Contains(t, resp.VolumeCondition.Message, "stale")
+	assert.Empty(t, resp.Usage)
+}
@@ -91,8 +91,8 @@ func ConstructMountInfo(serverAddress, fsInfoBase64, fsCacheBase64, targetPath s
 		return Info{}, fmt.Errorf("csi paddleflow server token not set")
 	}
 
-	if !fs.IndependentMountProcess && fs.Type != common.GlusterFSType && fs.Type != common.CFSType && fs.Type != common.AFSType {
-		info.SourcePath = schema.GetBindSource(info.FS.ID)
+	if info.IsPodMount() {
+		// source path of pod mount depends on the mount pod, see PodBindSource
 		info.PodResource, err = csiconfig.ParsePodResources(cacheConfig.Resource.CpuLimit, cacheConfig.Resource.MemoryLimit)
 		if err != nil {
 			err := fmt.Errorf("ParsePodResources: %+v err: %v", cacheConfig.Resource, err)
@@ -106,6 +106,12 @@ func ConstructMountInfo(serverAddress, fsInfoBase64, fsCacheBase64, targetPath s
 	return info, nil
 }
 
+// IsPodMount returns whether the filesystem is mounted by mount pod, others are mounted by process in csi plugin
+func (mountInfo *Info) IsPodMount() bool {
+	fs := mountInfo.FS
+	return !fs.IndependentMountProcess && fs.Type != common.GlusterFSType && fs.Type != common.CFSType && fs.Type != common.AFSType
+}
+
 func (mountInfo *Info) cmdAndArgs() (string, []string) {
 	if mountInfo.FS.Type == common.GlusterFSType {
 		return mountName, mountInfo.glusterArgs()
@@ -53,8 +53,6 @@ const (
 var umountLock sync.RWMutex
 
 func PodUnmount(volumeID string, mountInfo Info) error {
-	podName := GeneratePodNameByVolumeID(volumeID)
-	log.Infof("PodUnmount pod name is %s", podName)
 	umountLock.Lock()
 	defer umountLock.Unlock()
 
@@ -63,20 +61,19 @@ func PodUnmount(volumeID string, mountInfo Info) error {
 		log.Errorf("PodUnmount: Get k8s client failed: %v", err)
 		return err
 	}
-	pod, err := k8sClient.GetPod(csiconfig.Namespace, podName)
-	if err != nil && !k8sErrors.IsNotFound(err) {
-		log.Errorf("PodUnmount: Get pod %s err: %v", podName, err)
+	// the outdated mount pods of upgraded volume are released together
+	pods, err := getMountPodsByTargetPath(k8sClient, mountInfo.TargetPath)
+	if err != nil {
+		log.Errorf("PodUnmount: Get mount pod of volume %s err: %v", volumeID, err)
 		return err
 	}
-	// if mount pod not exists. might be process mount
-	if pod == nil {
-		// log.Infof("PodUnmount: Mount pod %s not exists.", podName)
-		return nil
-	}
-
+	// no mount pod for the volume mounted by process
 	workPodUID := utils.GetPodUIDFromTargetPath(mountInfo.TargetPath)
-	if workPodUID != "" {
-		return removeRef(k8sClient, pod, workPodUID)
+	for _, pod := range pods {
+		log.Infof("PodUnmount pod name is %s", pod.Name)
+		if err = ReleaseMountPod(k8sClient, pod.Name, workPodUID); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -87,11 +84,19 @@ func PFSMount(volumeID string, mountInfo Info) error {
 		log.Errorf("PodMount: info: %+v err: %v", mountInfo, err)
 		return err
 	}
-	return waitUtilPodReady(mountInfo.K8sClient, GeneratePodNameByVolumeID(volumeID))
+	podName := GenerateMountPodName(volumeID, mountInfo)
+	if err := waitUtilPodReady(mountInfo.K8sClient, podName); err != nil {
+		return err
+	}
+	// mount pods of the same key with outdated config serve their existing mounts until released
+	if err := drainOutdatedPods(mountInfo.K8sClient, MountKey(volumeID, mountInfo), podName); err != nil {
+		log.Warnf("PodMount: drain mount pods outdated by %s err: %v", podName, err)
+	}
+	return nil
 }
 
 func createOrUpdatePod(volumeID string, mountInfo Info) error {
-	podName := GeneratePodNameByVolumeID(volumeID)
+	podName := GenerateMountPodName(volumeID, mountInfo)
 	log.Infof("pod name is %s", podName)
 	for i := 0; i < 120; i++ {
 		// wait for old pod deleted
@@ -101,6 +106,10 @@ func createOrUpdatePod(volumeID string, mountInfo Info) error {
 				// mount pod not exist, create
 				log.Infof("createOrAddRef: Need to create pod %s.", podName)
 				if createPodErr := createMountPod(mountInfo.K8sClient, volumeID, mountInfo); createPodErr != nil {
+					if k8sErrors.IsAlreadyExists(createPodErr) {
+						// created by another volume sharing the mount pod, add ref to it
+						continue
+					}
 					return createPodErr
 				}
 			} else {
@@ -114,7 +123,8 @@ func createOrUpdatePod(volumeID string, mountInfo Info) error {
 			time.Sleep(time.Millisecond * 500)
 			continue
 		} else {
-			// mount pod exist, update annotation
+			// mount pod exist, update annotation. it takes new mounts again if config changes back
+			delete(oldPod.Annotations, schema.AnnotationKeyDraining)
 			return addRef(mountInfo.K8sClient, oldPod, mountInfo.TargetPath)
 		}
 	}
@@ -205,7 +215,8 @@ func buildMountPodEnv(pod *k8sCore.Pod) {
 
 func buildMountPod(volumeID string, mountInfo Info) (*k8sCore.Pod, error) {
 	pod := csiconfig.GeneratePodTemplate()
-	pod.Name = GeneratePodNameByVolumeID(volumeID)
+	pod.Name = GenerateMountPodName(volumeID, mountInfo)
+	mountDir := mountDirOfPodName(pod.Name)
 	// annotate mount point & modified time
 	err := buildAnnotation(pod, mountInfo.TargetPath)
 	if err != nil {
@@ -213,7 +224,7 @@ func buildMountPod(volumeID string, mountInfo Info) (*k8sCore.Pod, error) {
 	}
 	// build volumes & containers
 	pod.Spec.Volumes = generatePodVolumes(mountInfo.CacheConfig.CacheDir)
-	pod.Spec.Containers[0] = buildMountContainer(baseContainer(pod.Name, mountInfo.PodResource), mountInfo)
+	pod.Spec.Containers[0] = buildMountContainer(baseContainer(pod.Name, mountInfo.PodResource), mountInfo, mountDir)
 	pod.Spec.Containers[1] = buildCacheWorkerContainer(baseContainer(pod.Name, mountInfo.PodResource), mountInfo)
 
 	buildMountPodEnv(pod)
@@ -226,6 +237,9 @@ func buildMountPod(volumeID string, mountInfo Info) (*k8sCore.Pod, error) {
 		csiconfig.NodeName, mountInfo.CacheConfig.CacheDir, mountInfo.FS.ID)
 	// cache dir has "/" and is not allowed in label
 	pod.Annotations[schema.AnnotationKeyCacheDir] = mountInfo.CacheConfig.CacheDir
+	// mount key and dir are too long for labels
+	pod.Annotations[schema.AnnotationKeyMountKey] = MountKey(volumeID, mountInfo)
+	pod.Annotations[schema.AnnotationKeyMountDir] = mountDir
 	return pod, nil
 }
 
@@ -241,8 +255,20 @@ func buildAnnotation(pod *k8sCore.Pod, targetPath string) error {
 	return nil
 }
 
-func GeneratePodNameByVolumeID(volumeID string) string {
-	return fmt.Sprintf("pfs-%s-%s", csiconfig.NodeName, volumeID)
+// CheckMountPod returns the reason why the mount pod serving target path is abnormal, or empty if it is ready.
+// The volume mounted by process has no mount pod, which is treated as normal
+func CheckMountPod(k8sClient utils.Client, targetPath string) (string, error) {
+	pod, err := GetMountPodByTargetPath(k8sClient, targetPath)
+	if err != nil || pod == nil {
+		return "", err
+	}
+	if pod.DeletionTimestamp != nil {
+		return fmt.Sprintf("mount pod %s is terminating", pod.Name), nil
+	}
+	if !isPodReady(pod) {
+		return fmt.Sprintf("mount pod %s is %s but not ready", pod.Name, pod.Status.Phase), nil
+	}
+	return "", nil
 }
 
 func waitUtilPodReady(k8sClient utils.Client, podName string) error {
@@ -317,7 +343,7 @@ func baseContainer(podName string, podResource k8sCore.ResourceRequirements) k8s
 	}
 }
 
-func buildMountContainer(mountContainer k8sCore.Container, mountInfo Info) k8sCore.Container {
+func buildMountContainer(mountContainer k8sCore.Container, mountInfo Info, mountDir string) k8sCore.Container {
 	mountContainer.Name = ContainerNamePfsMount
 	mkdir := "mkdir -p " + FusePodMountPoint + ";"
 
@@ -345,7 +371,7 @@ func buildMountContainer(mountContainer k8sCore.Container, mountInfo Info) k8sCo
 		{
 			Name:             VolumesKeyMount,
 			MountPath:        schema.FusePodMntDir,
-			SubPath:          mountInfo.FS.ID,
+			SubPath:          mountDir,
 			MountPropagation: &mountPropagationBidirectional,
 		},
 	}
@@ -149,9 +149,10 @@ func TestPFSMountWithCache(t *testing.T) {
 			if err := PFSMount(tt.args.volumeID, tt.args.mountInfo); (err != nil) != tt.wantErr {
 				t.Errorf("PodMount() error = %v, wantErr %v", err, tt.wantErr)
 			}
-			newPod, errGetpod := tt.args.mountInfo.K8sClient.GetPod(csiconfig.Namespace, GeneratePodNameByVolumeID(tt.args.volumeID))
+			podName := GenerateMountPodName(tt.args.volumeID, tt.args.mountInfo)
+			newPod, errGetpod := tt.args.mountInfo.K8sClient.GetPod(csiconfig.Namespace, podName)
 			assert.Nil(t, errGetpod)
-			assert.Equal(t, GeneratePodNameByVolumeID(tt.args.volumeID), newPod.Name)
+			assert.Equal(t, podName, newPod.Name)
 			assert.Equal(t, csiconfig.Namespace, newPod.Namespace)
 			assert.Equal(t, testTargetPath, newPod.Annotations[schema.AnnotationKeyMountPrefix+utils.GetPodUIDFromTargetPath(testTargetPath)])
 			assert.Equal(t, "mkdir -p /home/paddleflow/mnt/storage;"+
@@ -0,0 +1,208 @@
+/*
+Copyright (c) 2021 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mount
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	k8sCore "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/csiplugin/csiconfig"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/utils"
+)
+
+const mountConfigHashLen = 8
+
+// MountKey returns the sharing key of the volume, volumes with the same key share one mount pod on the node
+func MountKey(volumeID string, mountInfo Info) string {
+	switch csiconfig.MountShareMode {
+	case csiconfig.MountShareModeFS:
+		return mountInfo.FS.ID
+	case csiconfig.MountShareModePod:
+		return volumeID + "-" + utils.GetPodUIDFromTargetPath(mountInfo.TargetPath)
+	default:
+		return volumeID
+	}
+}
+
+// mountConfigHash digests the image, command and cache config of the mount pod,
+// so that a changed cache config or an upgraded image leads to a new mount pod
+func mountConfigHash(mountInfo Info) string {
+	h := sha256.New()
+	for _, item := range []string{csiconfig.MountImage, mountInfo.Cmd, strings.Join(mountInfo.Args, " "),
+		mountInfo.CacheConfig.CacheDir, mountInfo.PodResource.String()} {
+		h.Write([]byte(item))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:mountConfigHashLen]
+}
+
+// GenerateMountPodName returns the name of mount pod serving the volume with current mount config
+func GenerateMountPodName(volumeID string, mountInfo Info) string {
+	return fmt.Sprintf("pfs-%s-%s-%s", csiconfig.NodeName, MountKey(volumeID, mountInfo), mountConfigHash(mountInfo))
+}
+
+// mountDirOfPodName returns the dir under host mnt dir for the mount pod, which is unique on the node
+func mountDirOfPodName(podName string) string {
+	return strings.TrimPrefix(podName, fmt.Sprintf("pfs-%s-", csiconfig.NodeName))
+}
+
+// PodBindSource returns the bind source of the volume served by mount pod with current mount config
+func PodBindSource(volumeID string, mountInfo Info) string {
+	return schema.GetBindSource(mountDirOfPodName(GenerateMountPodName(volumeID, mountInfo)))
+}
+
+// BindSourceOfPod returns the bind source of an existing mount pod, pods created before mount sharing
+// was configurable mount in the dir named by fs id
+func BindSourceOfPod(pod *k8sCore.Pod) string {
+	if dir := pod.Annotations[schema.AnnotationKeyMountDir]; dir != "" {
+		return schema.GetBindSource(dir)
+	}
+	return schema.GetBindSource(pod.Labels[schema.LabelKeyFsID])
+}
+
+// IsMountPodOutdated returns whether the mount pod is built with an outdated image or cache config
+func IsMountPodOutdated(pod *k8sCore.Pod, volumeID string, mountInfo Info) bool {
+	return pod.Name != GenerateMountPodName(volumeID, mountInfo)
+}
+
+func listMountPods(c utils.Client) ([]k8sCore.Pod, error) {
+	selector := fmt.Sprintf("%s=%s,%s=%s", csiconfig.PodTypeKey, csiconfig.PodMount,
+		schema.LabelKeyNodeName, csiconfig.NodeName)
+	pods, err := c.ListPods(csiconfig.Namespace, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		log.Errorf("list mount pods on node[%s] err: %v", csiconfig.NodeName, err)
+		return nil, err
+	}
+	return pods.Items, nil
+}
+
+// GetMountPodByTargetPath returns the mount pod serving the target path, or nil if not found, as the volume may
+// be mounted by process. The pod not draining is preferred, since the target path moves to it after upgraded.
+func GetMountPodByTargetPath(c utils.Client, targetPath string) (*k8sCore.Pod, error) {
+	pods, err := getMountPodsByTargetPath(c, targetPath)
+	if err != nil || len(pods) == 0 {
+		return nil, err
+	}
+	for i := range pods {
+		if pods[i].Annotations[schema.AnnotationKeyDraining] != "true" {
+			return &pods[i], nil
+		}
+	}
+	return &pods[0], nil
+}
+
+// getMountPodsByTargetPath returns all the mount pods referenced by the target path. The outdated mount pod
+// keeps the reference after the volume is upgraded, as the containers of work pod still use its mount.
+func getMountPodsByTargetPath(c utils.Client, targetPath string) ([]k8sCore.Pod, error) {
+	workPodUID := utils.GetPodUIDFromTargetPath(targetPath)
+	if workPodUID == "" {
+		return nil, nil
+	}
+	pods, err := listMountPods(c)
+	if err != nil {
+		return nil, err
+	}
+	var refPods []k8sCore.Pod
+	for i := range pods {
+		if pods[i].Annotations[schema.AnnotationKeyMountPrefix+workPodUID] == targetPath {
+			refPods = append(refPods, pods[i])
+		}
+	}
+	return refPods, nil
+}
+
+func refCount(pod *k8sCore.Pod) int {
+	count := 0
+	for key := range pod.Annotations {
+		if strings.HasPrefix(key, schema.AnnotationKeyMountPrefix) {
+			count++
+		}
+	}
+	return count
+}
+
+// drainOutdatedPods marks the other mount pods with the same key draining, so that they serve existing mounts only.
+// The ones not referenced any more are deleted at once.
+func drainOutdatedPods(c utils.Client, mountKey, podName string) error {
+	pods, err := listMountPods(c)
+	if err != nil {
+		return err
+	}
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Name == podName || pod.DeletionTimestamp != nil || !isSameMountKey(pod, mountKey) {
+			continue
+		}
+		if refCount(pod) == 0 {
+			log.Infof("delete outdated mount pod[%s] without reference", pod.Name)
+			if err := c.DeletePod(pod); err != nil && !k8sErrors.IsNotFound(err) {
+				return err
+			}
+			continue
+		}
+		if pod.Annotations[schema.AnnotationKeyDraining] == "true" {
+			continue
+		}
+		log.Infof("mount pod[%s] is outdated by [%s], draining", pod.Name, podName)
+		pod.Annotations[schema.AnnotationKeyDraining] = "true"
+		if err := c.PatchPodAnnotation(pod); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isSameMountKey matches mount pods by key, pods created before mount sharing was configurable are matched by fs id
+func isSameMountKey(pod *k8sCore.Pod, mountKey string) bool {
+	if key, ok := pod.Annotations[schema.AnnotationKeyMountKey]; ok {
+		return key == mountKey
+	}
+	return strings.HasSuffix(pod.Name, "-"+mountKey) || pod.Labels[schema.LabelKeyFsID] == mountKey
+}
+
+// ReleaseMountPod removes the reference of work pod from the mount pod, and deletes the mount pod
+// when it is no longer referenced and is draining or dedicated to the work pod
+func ReleaseMountPod(c utils.Client, podName, workPodUID string) error {
+	pod, err := c.GetPod(csiconfig.Namespace, podName)
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if err = removeRef(c, pod, workPodUID); err != nil {
+		return err
+	}
+	if refCount(pod) > 0 {
+		return nil
+	}
+	if pod.Annotations[schema.AnnotationKeyDraining] == "true" || csiconfig.MountShareMode == csiconfig.MountShareModePod {
+		log.Infof("delete mount pod[%s] as it is no longer referenced", pod.Name)
+		if err = c.DeletePod(pod); err != nil && !k8sErrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+/*
+Copyright (c) 2021 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mount
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	k8sCore "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/csiplugin/csiconfig"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/utils"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
+)
+
+func TestMountKey(t *testing.T) {
+	defer func() { csiconfig.MountShareMode = csiconfig.MountShareModePVC }()
+	csiconfig.NodeName = "node1"
+	volumeID := "pfs-fs-root-share-default-pv"
+	info := Info{
+		FS:         model.FileSystem{Model: model.Model{ID: "fs-root-share"}},
+		TargetPath: testTargetPath,
+		Cmd:        pfsFuseMountPodCMDName,
+		Args:       []string{"--fs-id=fs-root-share"},
+	}
+
+	csiconfig.MountShareMode = csiconfig.MountShareModeFS
+	assert.Equal(t, "fs-root-share", MountKey(volumeID, info))
+	csiconfig.MountShareMode = csiconfig.MountShareModePod
+	assert.Equal(t, volumeID+"-abc", MountKey(volumeID, info))
+	csiconfig.MountShareMode = csiconfig.MountShareModePVC
+	assert.Equal(t, volumeID, MountKey(volumeID, info))
+
+	// changed cache config leads to a new mount pod in a separate dir
+	name := GenerateMountPodName(volumeID, info)
+	assert.Equal(t, name, GenerateMountPodName(volumeID, info))
+	info.CacheConfig.CacheDir = "/data/cache"
+	assert.NotEqual(t, name, GenerateMountPodName(volumeID, info))
+	assert.Equal(t, schema.GetBindSource(volumeID+"-"+mountConfigHash(info)), PodBindSource(volumeID, info))
+}
+
+func TestDrainAndReleaseMountPod(t *testing.T) {
+	csiconfig.Namespace = "default"
+	csiconfig.NodeName = "node1"
+	c := utils.GetFakeK8sClient()
+	mountPod := func(name string, annotations map[string]string) *k8sCore.Pod {
+		return &k8sCore.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: csiconfig.Namespace,
+				Labels: map[string]string{
+					csiconfig.PodTypeKey:    csiconfig.PodMount,
+					schema.LabelKeyNodeName: csiconfig.NodeName,
+					schema.LabelKeyFsID:     "fs-root-drain",
+				},
+				Annotations: annotations,
+			},
+		}
+	}
+	key := "pfs-fs-root-drain-default-pv"
+	targetPath := "/var/lib/kubelet/pods/drain/volumes/kubernetes.io~csi/" + key + "/mount"
+	// legacy mount pod referenced by a work pod, outdated pod without reference and the current one
+	_, err := c.CreatePod(mountPod("pfs-node1-"+key, map[string]string{
+		schema.AnnotationKeyMountPrefix + "drain": targetPath,
+	}))
+	assert.Nil(t, err)
+	_, err = c.CreatePod(mountPod("pfs-node1-"+key+"-aaaaaaaa", map[string]string{
+		schema.AnnotationKeyMountKey: key,
+	}))
+	assert.Nil(t, err)
+	_, err = c.CreatePod(mountPod("pfs-node1-"+key+"-bbbbbbbb", map[string]string{
+		schema.AnnotationKeyMountKey: key,
+		schema.AnnotationKeyMountDir: key + "-bbbbbbbb",
+	}))
+	assert.Nil(t, err)
+
+	assert.Nil(t, drainOutdatedPods(c, key, "pfs-node1-"+key+"-bbbbbbbb"))
+	_, err = c.GetPod(csiconfig.Namespace, "pfs-node1-"+key+"-aaaaaaaa")
+	assert.NotNil(t, err)
+	legacy, err := c.GetPod(csiconfig.Namespace, "pfs-node1-"+key)
+	assert.Nil(t, err)
+	assert.Equal(t, "true", legacy.Annotations[schema.AnnotationKeyDraining])
+	assert.Equal(t, schema.GetBindSource("fs-root-drain"), BindSourceOfPod(legacy))
+
+	found, err := GetMountPodByTargetPath(c, targetPath)
+	assert.Nil(t, err)
+	assert.Equal(t, legacy.Name, found.Name)
+
+	// draining pod is deleted once the last reference is released
+	assert.Nil(t, ReleaseMountPod(c, legacy.Name, "drain"))
+	_, err = c.GetPod(csiconfig.Namespace, legacy.Name)
+	assert.NotNil(t, err)
+	found, err = GetMountPodByTargetPath(c, targetPath)
+	assert.Nil(t, err)
+	assert.Nil(t, found)
+
+	current, err := c.GetPod(csiconfig.Namespace, "pfs-node1-"+key+"-bbbbbbbb")
+	assert.Nil(t, err)
+	assert.Equal(t, schema.GetBindSource(key+"-bbbbbbbb"), BindSourceOfPod(current))
+}
+
+func TestPodUnmountUpgradedVolume(t *testing.T) {
+	csiconfig.Namespace = "default"
+	csiconfig.NodeName = "node1"
+	c := utils.GetFakeK8sClient()
+	key := "pfs-fs-root-upgrade-default-pv"
+	targetPath := "/var/lib/kubelet/pods/upgrade/volumes/kubernetes.io~csi/" + key + "/mount"
+	for _, suffix := range []string{"-aaaaaaaa", "-bbbbbbbb"} {
+		annotations := map[string]string{
+			schema.AnnotationKeyMountKey:                key,
+			schema.AnnotationKeyMountPrefix + "upgrade": targetPath,
+		}
+		if suffix == "-aaaaaaaa" {
+			annotations[schema.AnnotationKeyDraining] = "true"
+		}
+		_, err := c.CreatePod(&k8sCore.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "pfs-node1-" + key + suffix,
+				Namespace: csiconfig.Namespace,
+				Labels: map[string]string{
+					csiconfig.PodTypeKey:    csiconfig.PodMount,
+					schema.LabelKeyNodeName: csiconfig.NodeName,
+				},
+				Annotations: annotations,
+			},
+		})
+		assert.Nil(t, err)
+	}
+
+	// the volume is served by the current mount pod, and the outdated one is kept for running containers
+	found, err := GetMountPodByTargetPath(c, targetPath)
+	assert.Nil(t, err)
+	assert.Equal(t, "pfs-node1-"+key+"-bbbbbbbb", found.Name)
+
+	// both are released when the work pod is gone
+	assert.Nil(t, PodUnmount(key, Info{TargetPath: targetPath}))
+	_, err = c.GetPod(csiconfig.Namespace, "pfs-node1-"+key+"-aaaaaaaa")
+	assert.NotNil(t, err)
+	current, err := c.GetPod(csiconfig.Namespace, "pfs-node1-"+key+"-bbbbbbbb")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, refCount(current))
+}
@@ -0,0 +1,181 @@
+/*
+Copyright (c) 2021 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package location_awareness
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+)
+
+// MaxExtenderPriority is the highest score a scheduler extender may give, same as k8s.io/kube-scheduler/extender/v1
+const MaxExtenderPriority int64 = 10
+
+// ExtenderArgs is the request of scheduler extender filter and prioritize calls
+type ExtenderArgs struct {
+	Pod *corev1.Pod `json:"pod"`
+	// Nodes is set if the extender is not node cache capable, otherwise NodeNames is set
+	Nodes     *corev1.NodeList `json:"nodes,omitempty"`
+	NodeNames *[]string        `json:"nodenames,omitempty"`
+}
+
+// ExtenderFilterResult is the response of scheduler extender filter call
+type ExtenderFilterResult struct {
+	Nodes       *corev1.NodeList  `json:"nodes,omitempty"`
+	NodeNames   *[]string         `json:"nodenames,omitempty"`
+	FailedNodes map[string]string `json:"failedNodes,omitempty"`
+	Error       string            `json:"error,omitempty"`
+}
+
+// HostPriority is the score of a node in scheduler extender prioritize response
+type HostPriority struct {
+	Host  string `json:"host"`
+	Score int64  `json:"score"`
+}
+
+// FsIDsOfPod returns ids of filesystems mounted by the pod through paddleflow pvc
+func FsIDsOfPod(pod *corev1.Pod) []string {
+	fsIDs := make([]string, 0)
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim == nil {
+			continue
+		}
+		if fsID := schema.FsIDOfPVCName(volume.PersistentVolumeClaim.ClaimName); fsID != "" {
+			fsIDs = append(fsIDs, fsID)
+		}
+	}
+	return fsIDs
+}
+
+// LocalityModeOfPod returns the locality mode set by job env, which is passed to containers of job pods
+func LocalityModeOfPod(pod *corev1.Pod) string {
+	for _, container := range pod.Spec.Containers {
+		for _, env := range container.Env {
+			if env.Name == schema.EnvJobFsLocality && env.Value != "" {
+				return env.Value
+			}
+		}
+	}
+	return ""
+}
+
+func (args *ExtenderArgs) nodeNames() []string {
+	if args.NodeNames != nil {
+		return *args.NodeNames
+	}
+	nodeNames := make([]string, 0)
+	if args.Nodes != nil {
+		for _, node := range args.Nodes.Items {
+			nodeNames = append(nodeNames, node.Name)
+		}
+	}
+	return nodeNames
+}
+
+// podNodeScores returns locality scores of nodes for the pod, nil if the pod has no filesystem or disables locality
+func podNodeScores(pod *corev1.Pod) (map[string]int64, string, error) {
+	if pod == nil {
+		return nil, "", nil
+	}
+	mode, err := ParseLocalityMode(LocalityModeOfPod(pod))
+	if err != nil {
+		return nil, "", err
+	}
+	fsIDs := FsIDsOfPod(pod)
+	if mode == LocalityModeNone || len(fsIDs) == 0 {
+		return nil, mode, nil
+	}
+	nodeScores, err := ScoreNodes(fsIDs)
+	if err != nil {
+		return nil, mode, err
+	}
+	scores := make(map[string]int64, len(nodeScores))
+	for _, nodeScore := range nodeScores {
+		scores[nodeScore.NodeName] = nodeScore.Score
+	}
+	return scores, mode, nil
+}
+
+// Prioritize scores the candidate nodes of pod by cache locality of its filesystems
+func Prioritize(args ExtenderArgs) ([]HostPriority, error) {
+	scores, _, err := podNodeScores(args.Pod)
+	if err != nil {
+		return nil, err
+	}
+	nodeNames := args.nodeNames()
+	priorities := make([]HostPriority, 0, len(nodeNames))
+	for _, nodeName := range nodeNames {
+		priorities = append(priorities, HostPriority{
+			Host:  nodeName,
+			Score: extenderPriority(scores, nodeName),
+		})
+	}
+	return priorities, nil
+}
+
+// extenderPriority scales the node score to the priority of extender. It is rounded up and at least 1 for the
+// nodes with cache, so that they are still preferred to the nodes without cache after scaling.
+func extenderPriority(scores map[string]int64, nodeName string) int64 {
+	score, ok := scores[nodeName]
+	if !ok {
+		return 0
+	}
+	priority := (score*MaxExtenderPriority + MaxNodeScore - 1) / MaxNodeScore
+	if priority < 1 {
+		priority = 1
+	}
+	return priority
+}
+
+// Filter keeps the candidate nodes holding cache of the pod's filesystems if the pod requires locality.
+// Nothing is filtered if no node has cache yet, otherwise the pod can never be scheduled.
+func Filter(args ExtenderArgs) ExtenderFilterResult {
+	result := ExtenderFilterResult{Nodes: args.Nodes, NodeNames: args.NodeNames}
+	scores, mode, err := podNodeScores(args.Pod)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if mode != LocalityModeRequired || len(scores) == 0 {
+		return result
+	}
+
+	result.FailedNodes = make(map[string]string)
+	if args.Nodes != nil {
+		nodes := &corev1.NodeList{}
+		for _, node := range args.Nodes.Items {
+			if _, ok := scores[node.Name]; ok {
+				nodes.Items = append(nodes.Items, node)
+			} else {
+				result.FailedNodes[node.Name] = "node has no cache of the pod's filesystems"
+			}
+		}
+		result.Nodes = nodes
+	}
+	if args.NodeNames != nil {
+		nodeNames := make([]string, 0)
+		for _, nodeName := range *args.NodeNames {
+			if _, ok := scores[nodeName]; ok {
+				nodeNames = append(nodeNames, nodeName)
+			} else {
+				result.FailedNodes[nodeName] = "node has no cache of the pod's filesystems"
+			}
+		}
+		result.NodeNames = &nodeNames
+	}
+	return result
+}
@@ -0,0 +1,103 @@
+/*
+Copyright (c) 2021 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package location_awareness
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage/driver"
+)
+
+func mockExtenderPod(mode string, fsIDs ...string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "job-1"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "main"}},
+		},
+	}
+	if mode != "" {
+		pod.Spec.Containers[0].Env = []corev1.EnvVar{{Name: schema.EnvJobFsLocality, Value: mode}}
+	}
+	for _, fsID := range fsIDs {
+		pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+			Name: fsID,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: schema.ConcatenatePVCName(fsID),
+				},
+			},
+		})
+	}
+	return pod
+}
+
+func TestExtender(t *testing.T) {
+	driver.InitMockDB()
+	fsID := "fs-root-extender"
+	for _, cache := range []model.FSCache{
+		{FsID: fsID, CacheDir: "/mnt/cache", NodeName: "node1", UsedSize: 200},
+		{FsID: fsID, CacheDir: "/mnt/cache", NodeName: "node2", UsedSize: 100},
+		{FsID: fsID, CacheDir: "/mnt/cache", NodeName: "node4", UsedSize: 1},
+	} {
+		c := cache
+		assert.Nil(t, storage.FsCache.Add(&c))
+	}
+	nodeNames := []string{"node1", "node2", "node3", "node4"}
+
+	pod := mockExtenderPod("", fsID)
+	assert.Equal(t, []string{fsID}, FsIDsOfPod(pod))
+	priorities, err := Prioritize(ExtenderArgs{Pod: pod, NodeNames: &nodeNames})
+	assert.Nil(t, err)
+	// node4 with little cache is still preferred to node3 without cache
+	assert.Equal(t, []HostPriority{{"node1", 10}, {"node2", 5}, {"node3", 0}, {"node4", 1}}, priorities)
+	// preferred mode filters nothing
+	result := Filter(ExtenderArgs{Pod: pod, NodeNames: &nodeNames})
+	assert.Equal(t, nodeNames, *result.NodeNames)
+	assert.Empty(t, result.FailedNodes)
+
+	pod = mockExtenderPod(LocalityModeRequired, fsID)
+	nodes := &corev1.NodeList{}
+	for _, name := range nodeNames {
+		nodes.Items = append(nodes.Items, corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}})
+	}
+	result = Filter(ExtenderArgs{Pod: pod, Nodes: nodes})
+	assert.Equal(t, 3, len(result.Nodes.Items))
+	assert.Contains(t, result.FailedNodes, "node3")
+
+	// required mode without any cache filters nothing
+	pod = mockExtenderPod(LocalityModeRequired, "fs-root-nocache")
+	result = Filter(ExtenderArgs{Pod: pod, NodeNames: &nodeNames})
+	assert.Equal(t, nodeNames, *result.NodeNames)
+
+	pod = mockExtenderPod(LocalityModeNone, fsID)
+	priorities, err = Prioritize(ExtenderArgs{Pod: pod, NodeNames: &nodeNames})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), priorities[0].Score)
+
+	pod = mockExtenderPod("invalid", fsID)
+	_, err = Prioritize(ExtenderArgs{Pod: pod, NodeNames: &nodeNames})
+	assert.NotNil(t, err)
+	result = Filter(ExtenderArgs{Pod: pod, NodeNames: &nodeNames})
+	assert.NotEmpty(t, result.Error)
+}
@@ -26,34 +26,41 @@ import (
 )
 
 const (
-	fsLocationAwarenessKey    = "kubernetes.io/hostname"
-	fsLocationAwarenessWeight = 100
+	fsLocationAwarenessKey = "kubernetes.io/hostname"
 )
 
-// FsNodeAffinity if no node affinity, return nil, nil
-func FsNodeAffinity(fsIDs []string) (*corev1.Affinity, error) {
+// FsNodeAffinity if no node affinity, return nil, nil. Nodes caching the filesystems are preferred with weights
+// of their locality scores, or required in LocalityModeRequired.
+func FsNodeAffinity(fsIDs []string, mode string) (*corev1.Affinity, error) {
 	nodeAffinity := &corev1.NodeAffinity{}
-	// cached node preferred
 	preferred := make([]corev1.PreferredSchedulingTerm, 0)
-	nodes, err := storage.FsCache.ListNodes(fsIDs)
-	if err != nil {
-		err := fmt.Errorf("FsNodeAffinity %v ListNodes err:%v", fsIDs, err)
-		log.Errorf(err.Error())
-		return nil, err
-	}
-	if len(nodes) > 0 {
-		matchExpression := corev1.NodeSelectorRequirement{
-			Key:      fsLocationAwarenessKey,
-			Operator: corev1.NodeSelectorOpIn,
-			Values:   nodes,
+	required := make([]corev1.NodeSelectorTerm, 0)
+	var localityRequirement *corev1.NodeSelectorRequirement
+	if mode != LocalityModeNone {
+		nodeScores, err := ScoreNodes(fsIDs)
+		if err != nil {
+			err := fmt.Errorf("FsNodeAffinity %v ScoreNodes err:%v", fsIDs, err)
+			log.Errorf(err.Error())
+			return nil, err
 		}
-		preferredSchedulingTerm := corev1.PreferredSchedulingTerm{
-			Weight: fsLocationAwarenessWeight,
-			Preference: corev1.NodeSelectorTerm{
-				MatchExpressions: []corev1.NodeSelectorRequirement{matchExpression},
-			},
+		// cached node preferred, nodes with the same score share one term
+		nodes := make([]string, 0, len(nodeScores))
+		for i, nodeScore := range nodeScores {
+			nodes = append(nodes, nodeScore.NodeName)
+			if i == 0 || nodeScores[i-1].Score != nodeScore.Score {
+				preferred = append(preferred, hostnamePreferredTerm(int32(nodeScore.Score)))
+			}
+			term := &preferred[len(preferred)-1].Preference.MatchExpressions[0]
+			term.Values = append(term.Values, nodeScore.NodeName)
+		}
+		if mode == LocalityModeRequired {
+			if len(nodes) > 0 {
+				requirement := hostnameRequirement(nodes)
+				localityRequirement = &requirement
+			} else {
+				log.Warnf("FsNodeAffinity %v requires locality but no node has cache", fsIDs)
+			}
 		}
-		preferred = append(preferred, preferredSchedulingTerm)
 	}
 
 	// user set affinity
@@ -63,7 +70,6 @@ func FsNodeAffinity(fsIDs []string) (*corev1.Affinity, error) {
 		log.Errorf(err.Error())
 		return nil, err
 	}
-	required := make([]corev1.NodeSelectorTerm, 0)
 	for _, conf := range cacheConfs {
 		preferredTerms := conf.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution
 		if len(preferredTerms) > 0 {
@@ -75,6 +81,18 @@ func FsNodeAffinity(fsIDs []string) (*corev1.Affinity, error) {
 		}
 	}
 
+	// node selector terms are ORed, so locality is ANDed into every term to narrow rather than loosen them
+	if localityRequirement != nil {
+		if len(required) == 0 {
+			required = append(required, corev1.NodeSelectorTerm{})
+		}
+		for i := range required {
+			expressions := make([]corev1.NodeSelectorRequirement, 0, len(required[i].MatchExpressions)+1)
+			expressions = append(expressions, required[i].MatchExpressions...)
+			required[i].MatchExpressions = append(expressions, *localityRequirement)
+		}
+	}
+
 	if len(required) == 0 && len(preferred) == 0 {
 		log.Warnf("FsNodeAffinity %v has no node affinity", fsIDs)
 		return nil, nil
@@ -88,3 +106,20 @@ func FsNodeAffinity(fsIDs []string) (*corev1.Affinity, error) {
 	}
 	return &corev1.Affinity{NodeAffinity: nodeAffinity}, nil
 }
+
+func hostnameRequirement(nodes []string) corev1.NodeSelectorRequirement {
+	return corev1.NodeSelectorRequirement{
+		Key:      fsLocationAwarenessKey,
+		Operator: corev1.NodeSelectorOpIn,
+		Values:   nodes,
+	}
+}
+
+func hostnamePreferredTerm(weight int32) corev1.PreferredSchedulingTerm {
+	return corev1.PreferredSchedulingTerm{
+		Weight: weight,
+		Preference: corev1.NodeSelectorTerm{
+			MatchExpressions: []corev1.NodeSelectorRequirement{hostnameRequirement(nil)},
+		},
+	}
+}
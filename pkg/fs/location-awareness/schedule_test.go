@@ -61,13 +61,28 @@ func TestFsNodeAffinity(t *testing.T) {
 	assert.Nil(t, err)
 
 	fsIDs := []string{fsID1, fsID2, "fs-non-exist"}
-	affinity, err := FsNodeAffinity(fsIDs)
+	affinity, err := FsNodeAffinity(fsIDs, LocalityModePreferred)
 	assert.Nil(t, err)
 	pref := affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution
-	assert.Equal(t, 1, len(pref))
+	// node1 caches both filesystems and node2 caches one of them
+	assert.Equal(t, 2, len(pref))
 	exp := pref[0].Preference.MatchExpressions
 	assert.Equal(t, 1, len(exp))
-	assert.Equal(t, 2, len(exp[0].Values))
+	assert.Equal(t, []string{nodeName1}, exp[0].Values)
+	assert.Equal(t, int32(66), pref[0].Weight)
+	assert.Equal(t, []string{nodeName2}, pref[1].Preference.MatchExpressions[0].Values)
+	assert.Equal(t, int32(33), pref[1].Weight)
+	assert.Nil(t, affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution)
+
+	affinity, err = FsNodeAffinity(fsIDs, LocalityModeRequired)
+	assert.Nil(t, err)
+	required := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	assert.Equal(t, 1, len(required))
+	assert.Equal(t, []string{nodeName1, nodeName2}, required[0].MatchExpressions[0].Values)
+
+	affinity, err = FsNodeAffinity(fsIDs, LocalityModeNone)
+	assert.Nil(t, err)
+	assert.Nil(t, affinity)
 
 	cacheConf := &model.FSCacheConfig{
 		FsID:         fsID1,
@@ -76,18 +91,30 @@ func TestFsNodeAffinity(t *testing.T) {
 	err = storage.Filesystem.CreateFSCacheConfig(cacheConf)
 	assert.Nil(t, err)
 
-	affinity, err = FsNodeAffinity(fsIDs)
+	affinity, err = FsNodeAffinity(fsIDs, LocalityModePreferred)
 	assert.Nil(t, err)
 	pref = affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution
-	assert.Equal(t, 2, len(pref))
-	exp = pref[1].Preference.MatchExpressions
+	assert.Equal(t, 3, len(pref))
+	exp = pref[2].Preference.MatchExpressions
 	assert.Equal(t, 1, len(exp))
 	assert.Equal(t, 2, len(exp[0].Values))
-	required := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	required = affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
 	assert.Equal(t, 1, len(required))
 	exp = pref[0].Preference.MatchExpressions
 	assert.Equal(t, 1, len(exp))
-	assert.Equal(t, 2, len(exp[0].Values))
+	assert.Equal(t, 1, len(exp[0].Values))
+
+	// required locality narrows the required terms of cache config rather than adding an alternative
+	affinity, err = FsNodeAffinity(fsIDs, LocalityModeRequired)
+	assert.Nil(t, err)
+	required = affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	assert.Equal(t, 1, len(required))
+	exp = required[0].MatchExpressions
+	assert.Equal(t, 2, len(exp))
+	assert.Equal(t, "MatchFields", exp[0].Key)
+	assert.Equal(t, fsLocationAwarenessKey, exp[1].Key)
+	assert.Equal(t, []string{nodeName1, nodeName2}, exp[1].Values)
+	assert.Equal(t, 1, len(required[0].MatchFields))
 	aff := nodeAffinity()
 	fmt.Printf("%+v", aff)
 }
@@ -108,7 +135,7 @@ func nodeAffinity() v1.NodeAffinity {
 		},
 		PreferredDuringSchedulingIgnoredDuringExecution: []v1.PreferredSchedulingTerm{
 			{
-				Weight:     fsLocationAwarenessWeight,
+				Weight:     int32(MaxNodeScore),
 				Preference: nodeSelectorTerm,
 			},
 		},
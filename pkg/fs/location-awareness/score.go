@@ -0,0 +1,121 @@
+/*
+Copyright (c) 2021 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package location_awareness
+
+import (
+	"fmt"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
+)
+
+const (
+	// LocalityModePreferred prefers nodes holding more cache of the job's filesystems
+	LocalityModePreferred = "preferred"
+	// LocalityModeRequired schedules the job only to nodes holding cache of its filesystems, if there are any
+	LocalityModeRequired = "required"
+	// LocalityModeNone disables cache locality of the job
+	LocalityModeNone = "none"
+
+	// MaxNodeScore is the score of a node holding the most cache of all the job's filesystems
+	MaxNodeScore int64 = 100
+)
+
+// ParseLocalityMode returns the locality mode, which is preferred if not set
+func ParseLocalityMode(mode string) (string, error) {
+	switch mode {
+	case "":
+		return LocalityModePreferred, nil
+	case LocalityModePreferred, LocalityModeRequired, LocalityModeNone:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("fs locality mode[%s] is invalid, must be one of %s, %s and %s",
+			mode, LocalityModePreferred, LocalityModeRequired, LocalityModeNone)
+	}
+}
+
+// NodeScore is the locality score of a node, higher means more cache of the job's filesystems
+type NodeScore struct {
+	NodeName string
+	Score    int64
+}
+
+// ScoreNodes weights the nodes by cached size of each filesystem reported by mount pods. Every filesystem
+// shares MaxNodeScore equally, which is divided among nodes in proportion to the node's cached size to the
+// largest one. Nodes without any cache are not returned, and the result is sorted by score descending.
+func ScoreNodes(fsIDs []string) ([]NodeScore, error) {
+	scores := make(map[string]float64)
+	fsCount := 0
+	for _, fsID := range uniqueFsIDs(fsIDs) {
+		caches, err := storage.FsCache.List(fsID, "")
+		if err != nil {
+			err := fmt.Errorf("ScoreNodes list cache of fs[%s] err: %v", fsID, err)
+			log.Errorf(err.Error())
+			return nil, err
+		}
+		fsCount++
+		// a node may cache the fs in several dirs
+		usedSizes := make(map[string]int)
+		maxUsedSize := 0
+		for _, cache := range caches {
+			usedSizes[cache.NodeName] += cache.UsedSize
+			if usedSizes[cache.NodeName] > maxUsedSize {
+				maxUsedSize = usedSizes[cache.NodeName]
+			}
+		}
+		for nodeName, usedSize := range usedSizes {
+			ratio := 1.0
+			// cache stats not reported yet, treat nodes equally
+			if maxUsedSize > 0 {
+				ratio = float64(usedSize) / float64(maxUsedSize)
+			}
+			scores[nodeName] += ratio
+		}
+	}
+
+	nodeScores := make([]NodeScore, 0, len(scores))
+	for nodeName, score := range scores {
+		nodeScore := int64(score / float64(fsCount) * float64(MaxNodeScore))
+		// any cache is better than none
+		if nodeScore < 1 {
+			nodeScore = 1
+		}
+		nodeScores = append(nodeScores, NodeScore{NodeName: nodeName, Score: nodeScore})
+	}
+	sort.Slice(nodeScores, func(i, j int) bool {
+		if nodeScores[i].Score != nodeScores[j].Score {
+			return nodeScores[i].Score > nodeScores[j].Score
+		}
+		return nodeScores[i].NodeName < nodeScores[j].NodeName
+	})
+	return nodeScores, nil
+}
+
+func uniqueFsIDs(fsIDs []string) []string {
+	seen := make(map[string]bool, len(fsIDs))
+	unique := make([]string, 0, len(fsIDs))
+	for _, fsID := range fsIDs {
+		if fsID == "" || seen[fsID] {
+			continue
+		}
+		seen[fsID] = true
+		unique = append(unique, fsID)
+	}
+	return unique
+}
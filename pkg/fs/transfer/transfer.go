@@ -0,0 +1,525 @@
+/*
+Copyright (c) 2021 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transfer
+
+import (
+	"context"
+	"crypto/md5"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+
+	fuse "github.com/PaddlePaddle/PaddleFlow/pkg/fs/client/fs"
+)
+
+const (
+	// ModeCopy copies the new and changed files to destination
+	ModeCopy = "copy"
+	// ModeSync copies like ModeCopy, and deletes the files in destination which are not in source
+	ModeSync = "sync"
+
+	// CompareSizeMtime treats files as same if size equals and destination is not older than source
+	CompareSizeMtime = "size-mtime"
+	// CompareChecksum treats files as same if size and md5 equal
+	CompareChecksum = "checksum"
+
+	DefaultWorkers = 4
+	MaxWorkers     = 64
+	// MaxDiffLines is the max number of differences recorded in progress
+	MaxDiffLines = 1000
+
+	DefaultReportInterval = 5 * time.Second
+	copyBufferSize        = 1 << 20
+)
+
+// Options defines how files are transferred from source to destination
+type Options struct {
+	Mode    string
+	Compare string
+	Workers int
+	// BandwidthLimit is the max bytes read per second, 0 means no limit
+	BandwidthLimit int64
+	// DryRun only records the differences between source and destination, nothing is changed
+	DryRun         bool
+	ReportInterval time.Duration
+}
+
+// Progress is the statistics of transfer, in dry run the counters are what would be done
+type Progress struct {
+	Total       int64
+	Copied      int64
+	Skipped     int64
+	Deleted     int64
+	Failed      int64
+	CopiedBytes int64
+	// Checkpoint is the last path relative to source, before which all paths in walk order are done successfully
+	Checkpoint string
+	// Diff is the list of differences, prefixed with "+" for new, "~" for changed and "-" for deleted
+	Diff      []string
+	LastError string
+}
+
+type entry struct {
+	seq  int64
+	rel  string
+	info os.FileInfo
+}
+
+// Syncer transfers files between two file systems with parallel workers, and can be resumed from checkpoint
+type Syncer struct {
+	src, dst         fuse.FSClient
+	srcPath, dstPath string
+	opts             Options
+	limiter          *rate.Limiter
+	// checkpoint is the checkpoint of progress when started
+	checkpoint string
+
+	mu       sync.Mutex
+	progress Progress
+	seq      int64
+	doneSeq  int64
+	done     map[int64]string
+	// failed and failedSeq record the first failed entry, checkpoint never moves past it
+	failed    bool
+	failedSeq int64
+}
+
+func NewSyncer(src fuse.FSClient, srcPath string, dst fuse.FSClient, dstPath string, opts Options) *Syncer {
+	if opts.Mode == "" {
+		opts.Mode = ModeCopy
+	}
+	if opts.Compare == "" {
+		opts.Compare = CompareSizeMtime
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultWorkers
+	}
+	if opts.ReportInterval <= 0 {
+		opts.ReportInterval = DefaultReportInterval
+	}
+	s := &Syncer{
+		src:     src,
+		dst:     dst,
+		srcPath: path.Clean("/" + srcPath),
+		dstPath: path.Clean("/" + dstPath),
+		opts:    opts,
+		done:    make(map[int64]string),
+	}
+	if opts.BandwidthLimit > 0 {
+		burst := opts.BandwidthLimit
+		if burst > copyBufferSize {
+			burst = copyBufferSize
+		}
+		s.limiter = rate.NewLimiter(rate.Limit(opts.BandwidthLimit), int(burst))
+	}
+	return s
+}
+
+// Resume continues the transfer from the progress of last run, the failed paths are after checkpoint and retried
+func (s *Syncer) Resume(progress Progress) {
+	s.progress = progress
+	s.progress.Diff = append([]string(nil), progress.Diff...)
+	s.progress.Failed, s.progress.LastError = 0, ""
+	s.checkpoint = progress.Checkpoint
+}
+
+func (s *Syncer) Progress() Progress {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	progress := s.progress
+	progress.Diff = append([]string(nil), s.progress.Diff...)
+	return progress
+}
+
+// Run transfers the files until done or ctx is canceled, report is called with the progress periodically
+func (s *Syncer) Run(ctx context.Context, report func(Progress)) (Progress, error) {
+	srcInfo, err := s.src.Stat(s.srcPath)
+	if err != nil {
+		return s.Progress(), fmt.Errorf("stat source path[%s] failed: %v", s.srcPath, err)
+	}
+
+	stopReport := make(chan struct{})
+	reportDone := make(chan struct{})
+	go func() {
+		defer close(reportDone)
+		ticker := time.NewTicker(s.opts.ReportInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if report != nil {
+					report(s.Progress())
+				}
+			case <-stopReport:
+				return
+			}
+		}
+	}()
+	defer func() {
+		close(stopReport)
+		<-reportDone
+	}()
+
+	if !srcInfo.IsDir() {
+		if !s.opts.DryRun {
+			if err = s.dst.MkdirAll(path.Dir(s.dstPath), 0755); err != nil {
+				return s.Progress(), fmt.Errorf("create destination dir of [%s] failed: %v", s.dstPath, err)
+			}
+		}
+		s.syncFile(ctx, entry{seq: s.nextSeq(), info: srcInfo})
+		return s.Progress(), ctx.Err()
+	}
+
+	if !s.opts.DryRun {
+		if err = s.dst.MkdirAll(s.dstPath, 0755); err != nil {
+			return s.Progress(), fmt.Errorf("create destination path[%s] failed: %v", s.dstPath, err)
+		}
+	}
+	jobs := make(chan entry)
+	var wg sync.WaitGroup
+	for i := 0; i < s.opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for e := range jobs {
+				s.syncFile(ctx, e)
+			}
+		}()
+	}
+	s.walkSource(ctx, "", jobs)
+	close(jobs)
+	wg.Wait()
+
+	if s.opts.Mode == ModeSync && ctx.Err() == nil {
+		s.deleteExtra(ctx, "")
+	}
+	return s.Progress(), ctx.Err()
+}
+
+// walkSource walks the source in depth-first order with sorted names, which is the order compared by walkOrder
+func (s *Syncer) walkSource(ctx context.Context, rel string, jobs chan<- entry) {
+	infos, err := s.src.ListDir(path.Join(s.srcPath, rel))
+	if err != nil {
+		// the paths in dir are unknown, so checkpoint must not move past it
+		s.finish(ctx, entry{seq: s.nextSeq(), rel: rel}, err)
+		return
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name() < infos[j].Name() })
+	for _, info := range infos {
+		if ctx.Err() != nil {
+			return
+		}
+		child := path.Join(rel, info.Name())
+		if s.checkpoint != "" && walkOrder(child, s.checkpoint) <= 0 {
+			// files after checkpoint may be in the dir of checkpoint, or in the dir which is checkpoint
+			if info.IsDir() && (child == s.checkpoint || strings.HasPrefix(s.checkpoint, child+"/")) {
+				s.walkSource(ctx, child, jobs)
+			}
+			continue
+		}
+		e := entry{seq: s.nextSeq(), rel: child, info: info}
+		if info.IsDir() {
+			s.finish(ctx, e, s.syncDir(e))
+			s.walkSource(ctx, child, jobs)
+			continue
+		}
+		select {
+		case jobs <- e:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *Syncer) syncDir(e entry) error {
+	dst := path.Join(s.dstPath, e.rel)
+	dstInfo, err := s.dst.Stat(dst)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err == nil && dstInfo.IsDir() {
+		return nil
+	}
+	if s.opts.DryRun {
+		s.addDiff("+ " + e.rel + "/")
+		return nil
+	}
+	if err == nil && s.opts.Mode == ModeSync {
+		if err = s.dst.Remove(dst); err != nil {
+			return err
+		}
+	}
+	return s.dst.MkdirAll(dst, 0755)
+}
+
+func (s *Syncer) syncFile(ctx context.Context, e entry) {
+	s.mu.Lock()
+	s.progress.Total++
+	s.mu.Unlock()
+	s.finish(ctx, e, s.transferFile(ctx, e))
+}
+
+func (s *Syncer) transferFile(ctx context.Context, e entry) error {
+	src, dst := path.Join(s.srcPath, e.rel), path.Join(s.dstPath, e.rel)
+	dstInfo, err := s.dst.Stat(dst)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	exist := err == nil
+	if exist && !dstInfo.IsDir() && dstInfo.Size() == e.info.Size() {
+		same, err := s.same(ctx, src, e.info, dst, dstInfo)
+		if err != nil {
+			return err
+		}
+		if same {
+			s.mu.Lock()
+			s.progress.Skipped++
+			s.mu.Unlock()
+			return nil
+		}
+	}
+
+	if s.opts.DryRun {
+		if exist {
+			s.addDiff("~ " + e.rel)
+		} else {
+			s.addDiff("+ " + e.rel)
+		}
+		s.copied(e.info.Size())
+		return nil
+	}
+	// files are not truncated by create in fs client, so remove it first
+	if exist && (!dstInfo.IsDir() || s.opts.Mode == ModeSync) {
+		if err = s.dst.RemoveAll(dst); err != nil {
+			return err
+		}
+	}
+	n, err := s.copyFile(ctx, src, dst)
+	if err != nil {
+		// partial file is removed, so that it is not taken as same with source by size-mtime
+		if rmErr := s.dst.RemoveAll(dst); rmErr != nil && !os.IsNotExist(rmErr) {
+			log.Errorf("remove partial file[%s] failed: %v", dst, rmErr)
+		}
+		return err
+	}
+	s.copied(n)
+	return nil
+}
+
+func (s *Syncer) same(ctx context.Context, src string, srcInfo os.FileInfo, dst string,
+	dstInfo os.FileInfo) (bool, error) {
+	if s.opts.Compare != CompareChecksum {
+		return !dstInfo.ModTime().Truncate(time.Second).Before(srcInfo.ModTime().Truncate(time.Second)), nil
+	}
+	srcSum, err := s.checksum(ctx, s.src, src)
+	if err != nil {
+		return false, err
+	}
+	dstSum, err := s.checksum(ctx, s.dst, dst)
+	if err != nil {
+		return false, err
+	}
+	return srcSum == dstSum, nil
+}
+
+func (s *Syncer) checksum(ctx context.Context, client fuse.FSClient, name string) (string, error) {
+	reader, err := client.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+	hash := md5.New()
+	if _, err = io.CopyBuffer(hash, s.limit(ctx, reader), make([]byte, copyBufferSize)); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
+
+func (s *Syncer) copyFile(ctx context.Context, src, dst string) (int64, error) {
+	reader, err := s.src.Open(src)
+	if err != nil {
+		return 0, err
+	}
+	defer reader.Close()
+	writer, err := s.dst.Create(dst)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.CopyBuffer(writer, s.limit(ctx, reader), make([]byte, copyBufferSize))
+	if err == nil && ctx.Err() != nil {
+		err = ctx.Err()
+	}
+	if closeErr := writer.Close(); err == nil {
+		err = closeErr
+	}
+	return n, err
+}
+
+// deleteExtra deletes the paths in destination which are not in source
+func (s *Syncer) deleteExtra(ctx context.Context, rel string) {
+	infos, err := s.dst.ListDir(path.Join(s.dstPath, rel))
+	if err != nil {
+		s.fail(rel, err)
+		return
+	}
+	for _, info := range infos {
+		if ctx.Err() != nil {
+			return
+		}
+		child := path.Join(rel, info.Name())
+		exist, err := s.src.Exist(path.Join(s.srcPath, child))
+		if err != nil {
+			s.fail(child, err)
+			continue
+		}
+		if exist {
+			if info.IsDir() {
+				s.deleteExtra(ctx, child)
+			}
+			continue
+		}
+		if s.opts.DryRun {
+			s.addDiff("- " + child)
+		} else if err = s.dst.RemoveAll(path.Join(s.dstPath, child)); err != nil {
+			s.fail(child, err)
+			continue
+		}
+		s.mu.Lock()
+		s.progress.Deleted++
+		s.mu.Unlock()
+	}
+}
+
+func (s *Syncer) limit(ctx context.Context, reader io.Reader) io.Reader {
+	if s.limiter == nil {
+		return &contextReader{ctx: ctx, reader: reader}
+	}
+	return &limitedReader{ctx: ctx, reader: reader, limiter: s.limiter}
+}
+
+func (s *Syncer) nextSeq() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seq := s.seq
+	s.seq++
+	return seq
+}
+
+// finish records the error of entry, the entry canceled is not counted as failed since it is retried on resume
+func (s *Syncer) finish(ctx context.Context, e entry, err error) {
+	if err != nil && ctx.Err() == nil {
+		s.fail(e.rel, err)
+	}
+	s.complete(e.seq, e.rel, err == nil)
+}
+
+// complete marks the entry done, and moves checkpoint forward if all entries before it are done successfully.
+// The checkpoint stops before the first failed entry, so that it is transferred again on resume.
+func (s *Syncer) complete(seq int64, rel string, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failed && seq > s.failedSeq {
+		return
+	}
+	if !ok {
+		s.failed, s.failedSeq = true, seq
+		return
+	}
+	s.done[seq] = rel
+	for {
+		done, found := s.done[s.doneSeq]
+		if !found {
+			return
+		}
+		delete(s.done, s.doneSeq)
+		s.progress.Checkpoint = done
+		s.doneSeq++
+	}
+}
+
+func (s *Syncer) copied(size int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.progress.Copied++
+	s.progress.CopiedBytes += size
+}
+
+func (s *Syncer) fail(rel string, err error) {
+	log.Errorf("transfer path[%s] from [%s] to [%s] failed: %v", rel, s.srcPath, s.dstPath, err)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.progress.Failed++
+	s.progress.LastError = fmt.Sprintf("%s: %v", rel, err)
+}
+
+func (s *Syncer) addDiff(line string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.progress.Diff) < MaxDiffLines {
+		s.progress.Diff = append(s.progress.Diff, line)
+	}
+}
+
+// walkOrder compares two relative paths by components, in the same order as walkSource visits them
+func walkOrder(a, b string) int {
+	as, bs := strings.Split(a, "/"), strings.Split(b, "/")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if c := strings.Compare(as[i], bs[i]); c != 0 {
+			return c
+		}
+	}
+	return len(as) - len(bs)
+}
+
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.reader.Read(p)
+}
+
+type limitedReader struct {
+	ctx     context.Context
+	reader  io.Reader
+	limiter *rate.Limiter
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if burst := r.limiter.Burst(); len(p) > burst {
+		p = p[:burst]
+	}
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		if waitErr := r.limiter.WaitN(r.ctx, n); waitErr != nil && err == nil {
+			err = waitErr
+		}
+	}
+	return n, err
+}
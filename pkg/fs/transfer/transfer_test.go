@@ -0,0 +1,191 @@
+/*
+Copyright (c) 2021 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transfer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	fuse "github.com/PaddlePaddle/PaddleFlow/pkg/fs/client/fs"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/common"
+)
+
+func newTestClient(t *testing.T, dir string) fuse.FSClient {
+	client, err := fuse.NewFSClientForTest(common.FSMeta{
+		UfsType:    common.LocalType,
+		SubPath:    dir,
+		Properties: map[string]string{common.RootKey: dir},
+	})
+	assert.NoError(t, err)
+	return client
+}
+
+func writeTestFile(t *testing.T, name, content string, mtime time.Time) {
+	assert.NoError(t, os.MkdirAll(filepath.Dir(name), 0755))
+	assert.NoError(t, os.WriteFile(name, []byte(content), 0644))
+	assert.NoError(t, os.Chtimes(name, mtime, mtime))
+}
+
+func readTestFile(t *testing.T, name string) string {
+	content, err := os.ReadFile(name)
+	assert.NoError(t, err)
+	return string(content)
+}
+
+func TestSyncer(t *testing.T) {
+	srcDir, dstDir := t.TempDir(), t.TempDir()
+	old, now := time.Now().Add(-time.Hour), time.Now()
+	writeTestFile(t, filepath.Join(srcDir, "data/a.txt"), "aaa", now)
+	writeTestFile(t, filepath.Join(srcDir, "data/d/b.txt"), "bbbb", now)
+	writeTestFile(t, filepath.Join(srcDir, "data/d/e/c.txt"), "cc", now)
+	writeTestFile(t, filepath.Join(dstDir, "backup/d/b.txt"), "old", old)
+	writeTestFile(t, filepath.Join(dstDir, "backup/x/y.txt"), "extra", old)
+
+	run := func(opts Options, from *Progress) Progress {
+		syncer := NewSyncer(newTestClient(t, srcDir), "/data", newTestClient(t, dstDir), "/backup", opts)
+		if from != nil {
+			syncer.Resume(*from)
+		}
+		progress, err := syncer.Run(context.Background(), nil)
+		assert.NoError(t, err)
+		return progress
+	}
+
+	// dry run changes nothing
+	progress := run(Options{Mode: ModeSync, DryRun: true}, nil)
+	assert.ElementsMatch(t, []string{"+ a.txt", "~ d/b.txt", "+ d/e/", "+ d/e/c.txt", "- x"}, progress.Diff)
+	assert.Equal(t, int64(3), progress.Total)
+	assert.Equal(t, int64(3), progress.Copied)
+	assert.Equal(t, int64(1), progress.Deleted)
+	assert.Equal(t, "d/e/c.txt", progress.Checkpoint)
+	assert.Equal(t, "old", readTestFile(t, filepath.Join(dstDir, "backup/d/b.txt")))
+
+	// copy keeps extra files
+	progress = run(Options{Mode: ModeCopy, Workers: 2}, nil)
+	assert.Equal(t, int64(3), progress.Copied)
+	assert.Equal(t, int64(9), progress.CopiedBytes)
+	assert.Equal(t, int64(0), progress.Failed)
+	assert.Equal(t, "bbbb", readTestFile(t, filepath.Join(dstDir, "backup/d/b.txt")))
+	assert.Equal(t, "cc", readTestFile(t, filepath.Join(dstDir, "backup/d/e/c.txt")))
+	assert.Equal(t, "extra", readTestFile(t, filepath.Join(dstDir, "backup/x/y.txt")))
+
+	// same size and newer mtime is skipped, unless compared by checksum
+	writeTestFile(t, filepath.Join(dstDir, "backup/a.txt"), "xxx", now.Add(time.Hour))
+	progress = run(Options{Mode: ModeSync, BandwidthLimit: 2}, nil)
+	assert.Equal(t, int64(3), progress.Skipped)
+	assert.Equal(t, int64(1), progress.Deleted)
+	assert.Equal(t, "xxx", readTestFile(t, filepath.Join(dstDir, "backup/a.txt")))
+	_, err := os.Stat(filepath.Join(dstDir, "backup/x"))
+	assert.True(t, os.IsNotExist(err))
+
+	progress = run(Options{Compare: CompareChecksum}, nil)
+	assert.Equal(t, int64(1), progress.Copied)
+	assert.Equal(t, int64(2), progress.Skipped)
+	assert.Equal(t, "aaa", readTestFile(t, filepath.Join(dstDir, "backup/a.txt")))
+
+	// resume skips the paths before checkpoint
+	progress = run(Options{DryRun: true, Compare: CompareChecksum}, &Progress{Checkpoint: "d/b.txt", Total: 2})
+	assert.Equal(t, int64(3), progress.Total)
+	assert.Equal(t, int64(1), progress.Skipped)
+
+	// single file
+	syncer := NewSyncer(newTestClient(t, srcDir), "/data/d/b.txt", newTestClient(t, dstDir), "/file/b.txt",
+		Options{})
+	progress, err = syncer.Run(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), progress.Copied)
+	assert.Equal(t, "bbbb", readTestFile(t, filepath.Join(dstDir, "file/b.txt")))
+
+	syncer = NewSyncer(newTestClient(t, srcDir), "/notExist", newTestClient(t, dstDir), "/", Options{})
+	_, err = syncer.Run(context.Background(), nil)
+	assert.Error(t, err)
+}
+
+// failingClient fails the writes of file after part of content is written
+type failingClient struct {
+	fuse.FSClient
+	name string
+}
+
+func (c *failingClient) Create(name string) (io.WriteCloser, error) {
+	writer, err := c.FSClient.Create(name)
+	if err != nil || name != c.name {
+		return writer, err
+	}
+	return &failingWriter{WriteCloser: writer}, nil
+}
+
+type failingWriter struct {
+	io.WriteCloser
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	n, _ := w.WriteCloser.Write(p[:1])
+	return n, errors.New("write failed")
+}
+
+func TestSyncerCheckpointWithFailure(t *testing.T) {
+	srcDir, dstDir := t.TempDir(), t.TempDir()
+	now := time.Now()
+	writeTestFile(t, filepath.Join(srcDir, "a.txt"), "aaa", now)
+	writeTestFile(t, filepath.Join(srcDir, "b.txt"), "bbb", now)
+	writeTestFile(t, filepath.Join(srcDir, "c.txt"), "ccc", now)
+	writeTestFile(t, filepath.Join(srcDir, "d/e.txt"), "eee", now)
+
+	// checkpoint does not move past the failed file, and the partial file is removed
+	dst := &failingClient{FSClient: newTestClient(t, dstDir), name: "/b.txt"}
+	syncer := NewSyncer(newTestClient(t, srcDir), "/", dst, "/", Options{Workers: 1})
+	progress, err := syncer.Run(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), progress.Failed)
+	assert.Equal(t, int64(3), progress.Copied)
+	assert.Equal(t, "a.txt", progress.Checkpoint)
+	_, err = os.Stat(filepath.Join(dstDir, "b.txt"))
+	assert.True(t, os.IsNotExist(err))
+
+	// failed file is retried on resume, and failure counters are reset
+	syncer = NewSyncer(newTestClient(t, srcDir), "/", newTestClient(t, dstDir), "/", Options{Workers: 1})
+	syncer.Resume(progress)
+	progress, err = syncer.Run(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), progress.Failed)
+	assert.Equal(t, "", progress.LastError)
+	assert.Equal(t, "d/e.txt", progress.Checkpoint)
+	assert.Equal(t, "bbb", readTestFile(t, filepath.Join(dstDir, "b.txt")))
+
+	// files in the dir which is checkpoint are not skipped
+	syncer = NewSyncer(newTestClient(t, srcDir), "/", newTestClient(t, dstDir), "/", Options{DryRun: true})
+	syncer.Resume(Progress{Checkpoint: "d"})
+	progress, err = syncer.Run(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), progress.Total)
+	assert.Equal(t, int64(1), progress.Skipped)
+}
+
+func TestWalkOrder(t *testing.T) {
+	assert.True(t, walkOrder("a", "a/b") < 0)
+	assert.True(t, walkOrder("a/b", "a-c") < 0)
+	assert.True(t, walkOrder("b", "a/z") > 0)
+	assert.Equal(t, 0, walkOrder("a/b", "a/b"))
+}
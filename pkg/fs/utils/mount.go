@@ -118,6 +118,14 @@ func IsMountPoint(path string) (bool, error) {
 	return true, nil
 }
 
+// IsStaleMountPoint returns whether path is a mount point which can not be accessed, such as the fuse process
+// serving it has exited and "Transport endpoint is not connected" is returned
+func IsStaleMountPoint(path string) bool {
+	isMountPoint, err := IsMountPoint(path)
+	log.Tracef("mountpoint path[%s] : isMountPoint[%t], err:%v", path, isMountPoint, err)
+	return err != nil && isMountPoint
+}
+
 func CleanUpMountPoint(path string) error {
 	// If extensiveMountPointCheck=false, IsLikelyNotMountPoint method will be used,
 	// which cannot recognize a mount point generated by linux mount bind command.
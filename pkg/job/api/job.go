@@ -70,6 +70,8 @@ type PFJob struct {
 	// Labels for job to update
 	Labels      map[string]string
 	Annotations map[string]string
+	// Replicas of members to scale, the key is role of member
+	Replicas map[schema.MemberRole]int
 
 	// extend field
 	Tags   []string
@@ -126,6 +128,13 @@ func (pfj *PFJob) UpdateAnnotations(annotations map[string]string) {
 	pfj.Annotations = annotations
 }
 
+func (pfj *PFJob) UpdateReplicas(replicas map[schema.MemberRole]int) {
+	if replicas == nil {
+		return
+	}
+	pfj.Replicas = replicas
+}
+
 func (pfj *PFJob) UpdateJobPriority(priorityClassName string) {
 	pfj.PriorityClassName = priorityClassName
 }
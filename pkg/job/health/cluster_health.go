@@ -0,0 +1,196 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/config"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	runtime "github.com/PaddlePaddle/PaddleFlow/pkg/job/runtime_v2"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
+)
+
+const (
+	DefaultPeriodSeconds    = 30
+	DefaultTimeoutSeconds   = 10
+	DefaultFailureThreshold = 3
+	DefaultSuccessThreshold = 2
+	DefaultHistorySize      = 20
+)
+
+// Checker is the cluster health checker of server, which is shared by job manager and api server
+var Checker = NewClusterHealthChecker()
+
+// ProbeFunc probes the health of cluster
+type ProbeFunc func(cluster model.ClusterInfo, timeout time.Duration) schema.ClusterHealth
+
+// clusterHealthState contains the probe history and consecutive results of cluster
+type clusterHealthState struct {
+	history              []schema.ClusterHealth
+	consecutiveFailures  int
+	consecutiveSuccesses int
+}
+
+// ClusterHealthChecker probes clusters periodically, and switches cluster status between online and offline
+type ClusterHealthChecker struct {
+	sync.RWMutex
+	conf   config.ClusterHealthConfig
+	states map[string]*clusterHealthState
+	probe  ProbeFunc
+}
+
+func NewClusterHealthChecker() *ClusterHealthChecker {
+	return &ClusterHealthChecker{
+		states: make(map[string]*clusterHealthState),
+		probe:  probeRuntime,
+	}
+}
+
+// Initialize sets config of health checker, and the default values are used for unset fields
+func (c *ClusterHealthChecker) Initialize(conf config.ClusterHealthConfig) {
+	if conf.PeriodSeconds <= 0 {
+		conf.PeriodSeconds = DefaultPeriodSeconds
+	}
+	if conf.TimeoutSeconds <= 0 {
+		conf.TimeoutSeconds = DefaultTimeoutSeconds
+	}
+	if conf.FailureThreshold <= 0 {
+		conf.FailureThreshold = DefaultFailureThreshold
+	}
+	if conf.SuccessThreshold <= 0 {
+		conf.SuccessThreshold = DefaultSuccessThreshold
+	}
+	if conf.HistorySize <= 0 {
+		conf.HistorySize = DefaultHistorySize
+	}
+	c.conf = conf
+}
+
+func (c *ClusterHealthChecker) Run(stopCh <-chan struct{}) {
+	log.Infof("start cluster health checker, period: %ds", c.conf.PeriodSeconds)
+	wait.Until(c.CheckClusters, time.Duration(c.conf.PeriodSeconds)*time.Second, stopCh)
+}
+
+// CheckClusters probes all active clusters except the clusters set offline by administrator. Only the clusters
+// set offline by health checker, whose status reason is kept in storage across restarts, are set online automatically.
+func (c *ClusterHealthChecker) CheckClusters() {
+	for _, cluster := range storage.Cluster.ActiveClusters() {
+		if cluster.Status == model.ClusterStatusOffLine && !isAutoOffline(cluster) {
+			continue
+		}
+		health := c.probe(cluster, time.Duration(c.conf.TimeoutSeconds)*time.Second)
+		c.record(cluster, health)
+	}
+}
+
+// record appends probe result to history, and switches cluster status when threshold is reached
+func (c *ClusterHealthChecker) record(cluster model.ClusterInfo, health schema.ClusterHealth) {
+	c.Lock()
+	state, find := c.states[cluster.ID]
+	if !find {
+		state = &clusterHealthState{}
+		c.states[cluster.ID] = state
+	}
+	state.history = append(state.history, health)
+	if len(state.history) > c.conf.HistorySize {
+		state.history = state.history[len(state.history)-c.conf.HistorySize:]
+	}
+	if health.Healthy {
+		state.consecutiveSuccesses++
+		state.consecutiveFailures = 0
+	} else {
+		state.consecutiveFailures++
+		state.consecutiveSuccesses = 0
+		log.Warningf("cluster %s is unhealthy, %s", cluster.Name, health.Message)
+	}
+
+	var newStatus, reason string
+	switch {
+	case cluster.Status == model.ClusterStatusOnLine && state.consecutiveFailures >= c.conf.FailureThreshold:
+		newStatus, reason = model.ClusterStatusOffLine, model.ClusterStatusReasonUnhealthy
+	case isAutoOffline(cluster) && state.consecutiveSuccesses >= c.conf.SuccessThreshold:
+		newStatus = model.ClusterStatusOnLine
+	}
+	c.Unlock()
+
+	if newStatus == "" {
+		return
+	}
+	msg := fmt.Sprintf("cluster %s status is switched from %s to %s by health checker", cluster.Name, cluster.Status, newStatus)
+	if err := storage.Cluster.UpdateClusterStatus(cluster.ID, newStatus, reason); err != nil {
+		log.Errorf("%s failed, err: %v", msg, err)
+		return
+	}
+	log.Infof(msg)
+}
+
+// isAutoOffline returns whether the cluster is set offline by health checker
+func isAutoOffline(cluster model.ClusterInfo) bool {
+	return cluster.Status == model.ClusterStatusOffLine && cluster.StatusReason == model.ClusterStatusReasonUnhealthy
+}
+
+// IsHealthy returns false if the consecutive failed probes of cluster reach the failure threshold, so a single
+// failure does not stop jobs being submitted. Cluster without probe is regarded as healthy.
+func (c *ClusterHealthChecker) IsHealthy(clusterID string) bool {
+	c.RLock()
+	defer c.RUnlock()
+	state, find := c.states[clusterID]
+	if !find {
+		return true
+	}
+	return state.consecutiveFailures < c.conf.FailureThreshold
+}
+
+// History returns the probe results of cluster, the latest one is the last
+func (c *ClusterHealthChecker) History(clusterID string) []schema.ClusterHealth {
+	c.RLock()
+	defer c.RUnlock()
+	state, find := c.states[clusterID]
+	if !find {
+		return []schema.ClusterHealth{}
+	}
+	history := make([]schema.ClusterHealth, len(state.history))
+	copy(history, state.history)
+	return history
+}
+
+// SetProbe sets the probe function, which is used by unit test
+func (c *ClusterHealthChecker) SetProbe(probe ProbeFunc) {
+	c.probe = probe
+}
+
+// probeRuntime probes cluster by the client of its runtime
+func probeRuntime(cluster model.ClusterInfo, timeout time.Duration) schema.ClusterHealth {
+	runtimeSvc, err := runtime.GetOrCreateRuntime(cluster)
+	if err != nil {
+		return schema.ClusterHealth{
+			ProbeTime: time.Now(),
+			Message:   fmt.Sprintf("get runtime of cluster failed, err: %v", err),
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return runtimeSvc.Client().HealthCheck(ctx)
+}
@@ -0,0 +1,115 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/config"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage/driver"
+)
+
+func newTestChecker(healthy map[string]bool) *ClusterHealthChecker {
+	checker := NewClusterHealthChecker()
+	checker.Initialize(config.ClusterHealthConfig{
+		FailureThreshold: 2,
+		SuccessThreshold: 2,
+		HistorySize:      3,
+	})
+	checker.SetProbe(func(cluster model.ClusterInfo, timeout time.Duration) schema.ClusterHealth {
+		return schema.ClusterHealth{ProbeTime: time.Now(), Healthy: healthy[cluster.Name]}
+	})
+	return checker
+}
+
+func clusterStatus(t *testing.T, name string) string {
+	cluster, err := storage.Cluster.GetClusterByName(name)
+	assert.NoError(t, err)
+	return cluster.Status
+}
+
+func TestClusterHealthChecker(t *testing.T) {
+	driver.InitMockDB()
+	clusters := []model.ClusterInfo{
+		{Name: "cluster-a", ClusterType: schema.KubernetesType, Status: model.ClusterStatusOnLine},
+		{Name: "cluster-b", ClusterType: schema.KubernetesType, Status: model.ClusterStatusOnLine},
+	}
+	for idx := range clusters {
+		clusters[idx].ID = clusters[idx].Name
+		err := storage.Cluster.CreateCluster(&clusters[idx])
+		assert.NoError(t, err)
+	}
+
+	healthy := map[string]bool{"cluster-a": true, "cluster-b": false}
+	checker := newTestChecker(healthy)
+
+	// cluster-b is set offline after failure threshold is reached
+	checker.CheckClusters()
+	assert.Equal(t, model.ClusterStatusOnLine, clusterStatus(t, "cluster-b"))
+	assert.True(t, checker.IsHealthy("cluster-b"))
+	assert.True(t, checker.IsHealthy("cluster-a"))
+	checker.CheckClusters()
+	assert.False(t, checker.IsHealthy("cluster-b"))
+	assert.Equal(t, model.ClusterStatusOffLine, clusterStatus(t, "cluster-b"))
+	assert.Equal(t, model.ClusterStatusOnLine, clusterStatus(t, "cluster-a"))
+
+	// cluster-b set offline before server restarts is set online after success threshold is reached
+	checker = newTestChecker(healthy)
+	healthy["cluster-b"] = true
+	checker.CheckClusters()
+	assert.Equal(t, model.ClusterStatusOffLine, clusterStatus(t, "cluster-b"))
+	checker.CheckClusters()
+	assert.Equal(t, model.ClusterStatusOnLine, clusterStatus(t, "cluster-b"))
+	cluster, err := storage.Cluster.GetClusterByName("cluster-b")
+	assert.NoError(t, err)
+	assert.Equal(t, "", cluster.StatusReason)
+
+	// history is limited by history size
+	assert.Equal(t, 2, len(checker.History("cluster-b")))
+	for i := 0; i < 2; i++ {
+		checker.CheckClusters()
+	}
+	assert.Equal(t, 3, len(checker.History("cluster-b")))
+	assert.True(t, checker.History("cluster-b")[2].Healthy)
+	assert.Equal(t, 0, len(checker.History("unknown")))
+	assert.True(t, checker.IsHealthy("unknown"))
+}
+
+func TestClusterHealthCheckerSkipManualOffline(t *testing.T) {
+	driver.InitMockDB()
+	cluster := model.ClusterInfo{
+		Name:        "cluster-c",
+		ClusterType: schema.KubernetesType,
+		Status:      model.ClusterStatusOffLine,
+	}
+	cluster.ID = cluster.Name
+	assert.NoError(t, storage.Cluster.CreateCluster(&cluster))
+
+	checker := newTestChecker(map[string]bool{"cluster-c": true})
+	for i := 0; i < 3; i++ {
+		checker.CheckClusters()
+	}
+	// cluster set offline by administrator is not probed, and it keeps offline
+	assert.Equal(t, model.ClusterStatusOffLine, clusterStatus(t, "cluster-c"))
+	assert.Equal(t, 0, len(checker.History("cluster-c")))
+}
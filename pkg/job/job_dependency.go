@@ -0,0 +1,90 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/trace_logger"
+)
+
+// checkJobDependencies returns true if all prerequisite jobs of job meet the dependency condition.
+// The job is held in init status while any prerequisite job is not finished, and it is cancelled
+// when any prerequisite job can never meet the condition. Because cancelled status does not meet the
+// succeeded condition, the cancellation cascades to the dependents of cancelled job in next job loop.
+func checkJobDependencies(job *model.Job) bool {
+	if job.Config == nil || len(job.Config.GetDependsOn()) == 0 {
+		return true
+	}
+	dependsOn := job.Config.GetDependsOn()
+	condition := job.Config.GetDependencyCondition()
+	prerequisites, err := storage.Job.ListUnscopedJobByIDs(dependsOn)
+	if err != nil {
+		log.Errorf("list prerequisite jobs of job %s failed, err: %v", job.ID, err)
+		return false
+	}
+	statuses := make(map[string]schema.JobStatus, len(prerequisites))
+	for _, prerequisite := range prerequisites {
+		statuses[prerequisite.ID] = prerequisite.Status
+	}
+
+	var waitingJobs []string
+	for _, jobID := range dependsOn {
+		status, found := statuses[jobID]
+		if !found {
+			cancelDependentJob(job, fmt.Sprintf("prerequisite job %s is not found", jobID))
+			return false
+		}
+		if schema.IsDependencyMet(condition, status) {
+			continue
+		}
+		if schema.IsImmutableJobStatus(status) {
+			cancelDependentJob(job, fmt.Sprintf("prerequisite job %s is %s, which is not %s", jobID, status, condition))
+			return false
+		}
+		waitingJobs = append(waitingJobs, jobID)
+	}
+	if len(waitingJobs) == 0 {
+		return true
+	}
+	sort.Strings(waitingJobs)
+	msg := fmt.Sprintf("waiting for prerequisite jobs [%s] to be %s", strings.Join(waitingJobs, ","), condition)
+	if job.Message != msg {
+		log.Infof("job %s is held, %s", job.ID, msg)
+		if err = storage.Job.UpdateJobStatus(job.ID, msg, schema.StatusJobInit); err != nil {
+			log.Errorf("update message of job %s failed, err: %v", job.ID, err)
+		}
+	}
+	return false
+}
+
+// cancelDependentJob cancels job which is not submitted to cluster, as its dependencies can not be met
+func cancelDependentJob(job *model.Job, reason string) {
+	msg := fmt.Sprintf("job is cancelled, because %s", reason)
+	log.Infof("job %s: %s", job.ID, msg)
+	trace_logger.KeyWithUpdate(job.ID).Infof(msg)
+	if err := storage.Job.UpdateJobStatus(job.ID, msg, schema.StatusJobCancelled); err != nil {
+		log.Errorf("cancel job %s failed, err: %v", job.ID, err)
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage/driver"
+)
+
+func TestCheckJobDependencies(t *testing.T) {
+	driver.InitMockDB()
+
+	prerequisites := []model.Job{
+		{ID: "job-pre-succeeded", Status: schema.StatusJobSucceeded},
+		{ID: "job-pre-failed", Status: schema.StatusJobFailed},
+		{ID: "job-pre-running", Status: schema.StatusJobRunning},
+	}
+	for idx := range prerequisites {
+		prerequisites[idx].Config = &schema.Conf{}
+		assert.NoError(t, storage.Job.CreateJob(&prerequisites[idx]))
+	}
+
+	testCases := []struct {
+		name       string
+		dependsOn  []string
+		condition  schema.DependencyCondition
+		ready      bool
+		wantStatus schema.JobStatus
+	}{
+		{
+			name:       "no dependencies",
+			ready:      true,
+			wantStatus: schema.StatusJobInit,
+		},
+		{
+			name:       "prerequisite succeeded",
+			dependsOn:  []string{"job-pre-succeeded"},
+			ready:      true,
+			wantStatus: schema.StatusJobInit,
+		},
+		{
+			name:       "prerequisite is running",
+			dependsOn:  []string{"job-pre-succeeded", "job-pre-running"},
+			ready:      false,
+			wantStatus: schema.StatusJobInit,
+		},
+		{
+			name:       "prerequisite failed",
+			dependsOn:  []string{"job-pre-running", "job-pre-failed"},
+			ready:      false,
+			wantStatus: schema.StatusJobCancelled,
+		},
+		{
+			name:       "prerequisite failed with finished condition",
+			dependsOn:  []string{"job-pre-succeeded", "job-pre-failed"},
+			condition:  schema.DependencyFinished,
+			ready:      true,
+			wantStatus: schema.StatusJobInit,
+		},
+		{
+			name:       "prerequisite not found",
+			dependsOn:  []string{"job-pre-not-exist"},
+			ready:      false,
+			wantStatus: schema.StatusJobCancelled,
+		},
+	}
+
+	for idx, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			job := &model.Job{
+				ID:     "job-dependent-" + string(rune('a'+idx)),
+				Status: schema.StatusJobInit,
+				Config: &schema.Conf{
+					DependsOn:           tc.dependsOn,
+					DependencyCondition: tc.condition,
+				},
+			}
+			assert.NoError(t, storage.Job.CreateJob(job))
+
+			assert.Equal(t, tc.ready, checkJobDependencies(job))
+			status, err := storage.Job.GetJobStatusByID(job.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantStatus, status)
+		})
+	}
+}
+
+func TestCascadeCancel(t *testing.T) {
+	driver.InitMockDB()
+
+	jobs := []*model.Job{
+		{ID: "job-a", Status: schema.StatusJobFailed, Config: &schema.Conf{}},
+		{ID: "job-b", Status: schema.StatusJobInit, Config: &schema.Conf{DependsOn: []string{"job-a"}}},
+		{ID: "job-c", Status: schema.StatusJobInit, Config: &schema.Conf{DependsOn: []string{"job-b"}}},
+	}
+	for _, job := range jobs {
+		assert.NoError(t, storage.Job.CreateJob(job))
+	}
+
+	// job-c is held while job-b is in init status
+	assert.False(t, checkJobDependencies(jobs[2]))
+	job, err := storage.Job.GetJobByID("job-c")
+	assert.NoError(t, err)
+	assert.Equal(t, schema.StatusJobInit, job.Status)
+	assert.Contains(t, job.Message, "waiting for prerequisite jobs [job-b]")
+
+	// job-b is cancelled as job-a failed, and then job-c is cancelled
+	assert.False(t, checkJobDependencies(jobs[1]))
+	assert.False(t, checkJobDependencies(jobs[2]))
+	for _, jobID := range []string{"job-b", "job-c"} {
+		status, err := storage.Job.GetJobStatusByID(jobID)
+		assert.NoError(t, err)
+		assert.Equal(t, schema.StatusJobCancelled, status)
+	}
+}
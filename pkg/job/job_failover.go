@@ -0,0 +1,163 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"fmt"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/api"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/placement"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/runtime_v2"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/trace_logger"
+)
+
+// failoverClusterJobs resubmits the jobs stuck on offline cluster to other member queues of their virtual queue.
+// The stuck job is terminated, and a new job with the same config is created on the picked member queue. The job
+// submitted to the cluster is deleted from it first, otherwise it runs along with the resubmitted one when the
+// cluster is back, so it is left pending if the deletion fails.
+func failoverClusterJobs(clusterID string) {
+	queues := storage.Queue.ListQueuesByCluster(clusterID)
+	if len(queues) == 0 {
+		return
+	}
+	queueIDs := make([]string, 0, len(queues))
+	for _, q := range queues {
+		queueIDs = append(queueIDs, q.ID)
+	}
+	for _, status := range []schema.JobStatus{schema.StatusJobInit, schema.StatusJobPending} {
+		jobs := storage.Job.ListJobsByQueueIDsAndStatus(queueIDs, status)
+		for idx := range jobs {
+			if err := failoverJob(&jobs[idx], clusterID); err != nil {
+				log.Errorf("failover job %s on offline cluster %s failed, err: %v", jobs[idx].ID, clusterID, err)
+			}
+		}
+	}
+}
+
+// offlineRuntimes caches the runtimes of offline clusters by cluster id, which are used to delete the jobs submitted
+// to them. Runtimes are not created for offline clusters, so that informers and clients are not rebuilt in every loop.
+var offlineRuntimes sync.Map
+
+// failoverJob resubmits job to other member queue of its virtual queue, when failover of virtual queue is enabled
+func failoverJob(job *model.Job, clusterID string) error {
+	if job.Config == nil {
+		return nil
+	}
+	vq, find := placement.GetVirtualQueue(job.Config.GetAnnotations()[placement.VirtualQueueAnnotation])
+	if !find || !vq.Failover {
+		return nil
+	}
+	var fsIDs []string
+	for _, fs := range append([]schema.FileSystem{job.Config.GetFileSystem()}, job.Config.GetExtraFS()...) {
+		if fs.ID != "" {
+			fsIDs = append(fsIDs, fs.ID)
+		}
+	}
+	queue, err := placement.SelectQueue(vq, fsIDs, clusterID)
+	if err != nil {
+		return err
+	}
+	if job.Status != schema.StatusJobInit {
+		if err = deleteClusterJob(job, clusterID); err != nil {
+			msg := fmt.Sprintf("cluster of job is offline, and job is not resubmitted since deleting it from the cluster failed: %v", err)
+			if job.Message != msg {
+				if dbErr := storage.Job.UpdateJobStatus(job.ID, msg, job.Status); dbErr != nil {
+					log.Errorf("update message of job %s failed, err: %v", job.ID, dbErr)
+				}
+			}
+			return err
+		}
+	}
+	failoverJob, err := newFailoverJob(*job, queue)
+	if err != nil {
+		return err
+	}
+	if err = storage.Job.CreateJob(failoverJob); err != nil {
+		return err
+	}
+	msg := fmt.Sprintf("cluster of job is offline, and job is resubmitted to queue %s as job %s", queue.Name, failoverJob.ID)
+	if _, err = storage.Job.UpdateJob(job.ID, schema.StatusJobTerminated, nil, nil, msg); err != nil {
+		log.Errorf("update status of job %s failed, err: %v", job.ID, err)
+		return err
+	}
+	log.Infof("job %s: %s", job.ID, msg)
+	trace_logger.KeyWithUpdate(job.ID).Infof(msg)
+	return nil
+}
+
+// deleteClusterJob deletes the submitted job from cluster by the cached runtime, the cluster may be unreachable since
+// it is offline
+var deleteClusterJob = func(job *model.Job, clusterID string) error {
+	value, find := offlineRuntimes.Load(clusterID)
+	if !find {
+		return fmt.Errorf("runtime of offline cluster %s is not found", clusterID)
+	}
+	runtimeSvc := value.(runtime_v2.RuntimeService)
+	pfjob, err := api.NewJobInfo(job)
+	if err != nil {
+		return err
+	}
+	return runtimeSvc.DeleteJob(pfjob)
+}
+
+// newFailoverJob returns a job in init status with a copy of the config of stuck job, which is placed into queue
+func newFailoverJob(job model.Job, queue *model.Queue) (*model.Job, error) {
+	setQueue := func(conf *schema.Conf) {
+		conf.SetQueueID(queue.ID)
+		conf.SetQueueName(queue.Name)
+		conf.SetClusterID(queue.ClusterId)
+		conf.SetNamespace(queue.Namespace)
+		conf.SetAnnotations(placement.FailoverJobAnnotation, job.ID)
+	}
+	conf, err := job.Config.DeepCopy()
+	if err != nil {
+		log.Errorf("copy config of job %s failed, err: %v", job.ID, err)
+		return nil, err
+	}
+	setQueue(conf)
+	members := make([]schema.Member, len(job.Members))
+	for idx := range job.Members {
+		memberConf, err := job.Members[idx].Conf.DeepCopy()
+		if err != nil {
+			log.Errorf("copy config of member %s of job %s failed, err: %v", job.Members[idx].ID, job.ID, err)
+			return nil, err
+		}
+		members[idx] = job.Members[idx]
+		members[idx].Conf = *memberConf
+		setQueue(&members[idx].Conf)
+	}
+	return &model.Job{
+		Name:              job.Name,
+		UserName:          job.UserName,
+		QueueID:           queue.ID,
+		Type:              job.Type,
+		Config:            conf,
+		Status:            schema.StatusJobInit,
+		Message:           fmt.Sprintf("job is resubmitted by failover of job %s", job.ID),
+		Resource:          job.Resource,
+		Framework:         job.Framework,
+		Members:           members,
+		ExtensionTemplate: job.ExtensionTemplate,
+		ParentJob:         job.ParentJob,
+	}, nil
+}
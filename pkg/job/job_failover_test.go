@@ -0,0 +1,150 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/config"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/resources"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/placement"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage/driver"
+)
+
+func TestFailoverClusterJobs(t *testing.T) {
+	config.GlobalServerConfig = &config.ServerConfig{
+		Job: config.JobConfig{
+			VirtualQueues: []config.VirtualQueueConfig{
+				{Name: "vq-failover", Queues: []string{"queue-offline", "queue-online"}, Failover: true},
+			},
+		},
+	}
+	driver.InitMockDB()
+	placement.ClusterIdleResource = func(cluster model.ClusterInfo) (*resources.Resource, error) {
+		return resources.NewResourceFromMap(map[string]string{"cpu": "8", "memory": "16Gi"})
+	}
+	clusters := []model.ClusterInfo{
+		{Model: model.Model{ID: "cluster-offline"}, Name: "cluster-offline", Status: model.ClusterStatusOffLine},
+		{Model: model.Model{ID: "cluster-online"}, Name: "cluster-online", Status: model.ClusterStatusOnLine},
+	}
+	for idx := range clusters {
+		assert.NoError(t, storage.Cluster.CreateCluster(&clusters[idx]))
+	}
+	queues := []model.Queue{
+		{Model: model.Model{ID: "queue-offline"}, Name: "queue-offline", ClusterId: "cluster-offline", Namespace: "ns-a", Status: schema.StatusQueueOpen},
+		{Model: model.Model{ID: "queue-online"}, Name: "queue-online", ClusterId: "cluster-online", Namespace: "ns-b", Status: schema.StatusQueueOpen},
+	}
+	for idx := range queues {
+		assert.NoError(t, storage.Queue.CreateQueue(&queues[idx]))
+	}
+
+	jobs := []model.Job{
+		{
+			ID:      "job-virtual",
+			QueueID: "queue-offline",
+			Status:  schema.StatusJobPending,
+			Config: &schema.Conf{
+				QueueID:     "queue-offline",
+				Annotations: map[string]string{placement.VirtualQueueAnnotation: "vq-failover"},
+			},
+			Members: []schema.Member{{ID: "job-virtual-worker", Role: schema.RoleWorker, Conf: schema.Conf{QueueID: "queue-offline"}}},
+		},
+		{
+			ID:      "job-normal",
+			QueueID: "queue-offline",
+			Status:  schema.StatusJobPending,
+			Config:  &schema.Conf{QueueID: "queue-offline"},
+		},
+	}
+	for idx := range jobs {
+		assert.NoError(t, storage.Job.CreateJob(&jobs[idx]))
+	}
+
+	// runtime is not created for offline cluster, the submitted job is kept without cached runtime
+	failoverClusterJobs("cluster-offline")
+	job, err := storage.Job.GetJobByID("job-virtual")
+	assert.NoError(t, err)
+	assert.Equal(t, schema.StatusJobPending, job.Status)
+	assert.Contains(t, job.Message, "runtime of offline cluster cluster-offline is not found")
+
+	// the submitted job is not resubmitted if it can not be deleted from the offline cluster
+	defer func(origin func(job *model.Job, clusterID string) error) {
+		deleteClusterJob = origin
+	}(deleteClusterJob)
+	deleteClusterJob = func(job *model.Job, clusterID string) error {
+		return fmt.Errorf("cluster unreachable")
+	}
+	failoverClusterJobs("cluster-offline")
+	job, err = storage.Job.GetJobByID("job-virtual")
+	assert.NoError(t, err)
+	assert.Equal(t, schema.StatusJobPending, job.Status)
+	assert.Contains(t, job.Message, "cluster unreachable")
+	assert.Equal(t, 0, len(storage.Job.ListQueueInitJob("queue-online")))
+
+	deleted := make([]string, 0)
+	deleteClusterJob = func(job *model.Job, clusterID string) error {
+		deleted = append(deleted, job.ID)
+		return nil
+	}
+	failoverClusterJobs("cluster-offline")
+	assert.Equal(t, []string{"job-virtual"}, deleted)
+
+	job, err = storage.Job.GetJobByID("job-virtual")
+	assert.NoError(t, err)
+	assert.Equal(t, schema.StatusJobTerminated, job.Status)
+	job, err = storage.Job.GetJobByID("job-normal")
+	assert.NoError(t, err)
+	assert.Equal(t, schema.StatusJobPending, job.Status)
+
+	failoverJobs := storage.Job.ListQueueInitJob("queue-online")
+	if assert.Equal(t, 1, len(failoverJobs)) {
+		newJob := failoverJobs[0]
+		assert.Equal(t, "job-virtual", newJob.Config.GetAnnotations()[placement.FailoverJobAnnotation])
+		assert.Equal(t, "cluster-online", newJob.Config.GetClusterID())
+		assert.Equal(t, "ns-b", newJob.Config.GetNamespace())
+		assert.Equal(t, "queue-online", newJob.Members[0].GetQueueID())
+	}
+}
+
+func TestNewFailoverJob(t *testing.T) {
+	job := model.Job{
+		ID:      "job-1",
+		QueueID: "queue-a",
+		Config: &schema.Conf{
+			QueueID:     "queue-a",
+			Annotations: map[string]string{placement.VirtualQueueAnnotation: "vq"},
+		},
+		Members: []schema.Member{{ID: "job-1-worker", Conf: schema.Conf{QueueID: "queue-a", Env: map[string]string{}}}},
+	}
+	queue := &model.Queue{Model: model.Model{ID: "queue-b"}, Name: "queue-b", ClusterId: "cluster-b", Namespace: "ns-b"}
+	newJob, err := newFailoverJob(job, queue)
+	assert.NoError(t, err)
+	assert.Equal(t, "queue-b", newJob.Config.GetQueueID())
+	assert.Equal(t, "job-1", newJob.Config.GetAnnotations()[placement.FailoverJobAnnotation])
+	assert.Equal(t, "ns-b", newJob.Members[0].GetNamespace())
+	// config of stuck job is not changed
+	assert.Equal(t, "queue-a", job.Config.GetQueueID())
+	assert.Equal(t, 1, len(job.Config.GetAnnotations()))
+	assert.Equal(t, "queue-a", job.Members[0].GetQueueID())
+	assert.Empty(t, job.Members[0].Env)
+}
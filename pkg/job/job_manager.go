@@ -17,16 +17,21 @@ limitations under the License.
 package job
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
 
 	"github.com/bluele/gcache"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"k8s.io/apimachinery/pkg/util/wait"
 
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/config"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/tracing"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/job/api"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/health"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/job/runtime_v2"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/metrics"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
@@ -102,6 +107,11 @@ func (m *JobManagerImpl) Start(activeClusters ActiveClustersFunc, activeQueueJob
 	m.listQueueInitJobs = storage.Job.ListQueueInitJob
 	/// init config for job manager
 	m.init()
+	// start cluster health checker
+	if config.GlobalServerConfig.Job.ClusterHealth.Enable {
+		health.Checker.Initialize(config.GlobalServerConfig.Job.ClusterHealth)
+		go health.Checker.Run(wait.NeverStop)
+	}
 	// start job manager
 	m.startRuntime()
 }
@@ -112,6 +122,8 @@ func (m *JobManagerImpl) stopClusterRuntime(clusterID api.ClusterID) {
 	cr, ok := m.clusterRuntimes.Get(clusterID)
 	if ok && cr != nil {
 		close(cr.StopCh)
+		// keep the runtime to delete jobs submitted to offline cluster when they fail over
+		offlineRuntimes.Store(string(clusterID), cr.RuntimeSvc)
 	}
 	m.clusterRuntimes.Delete(clusterID)
 	runtime_v2.PFRuntimeMap.Delete(clusterID)
@@ -132,6 +144,10 @@ func (m *JobManagerImpl) pJobProcessLoop() {
 				log.Warnf("get queue from cache failed, stop queue submit")
 				continue
 			}
+			// hold job until its prerequisite jobs are completed
+			if !checkJobDependencies(&jobs[idx]) {
+				continue
+			}
 			// add more metric
 			qInfo := cQueue.Queue
 			pfJob, err := api.NewJobInfo(&jobs[idx])
@@ -172,6 +188,11 @@ func (m *JobManagerImpl) pSubmitQueueJob(jobQueue *api.JobQueue, clusterRuntime
 			log.Infof("exit submit job loop for queue %s ...", name)
 			return
 		default:
+			// stop submitting jobs to unhealthy cluster, and jobs are kept in queue until cluster recovers
+			if !health.Checker.IsHealthy(clusterRuntime.ID) {
+				time.Sleep(200 * time.Millisecond)
+				continue
+			}
 			startTime := time.Now()
 			// dequeue job
 			job, ok := jobQueue.GetJob()
@@ -209,7 +230,16 @@ func (m *JobManagerImpl) submitJob(clusterRuntime *ClusterRuntimeInfo, jobInfo *
 	if job.Status == schema.StatusJobInit {
 		var jobStatus schema.JobStatus
 		var msg string
+		// the trace context of job creation is passed by job env, and then trace context of submission
+		// is injected into job env, which will be the parent of spans in runtime and containers of job
+		ctx := tracing.ContextFromEnv(context.Background(), jobInfo.Conf.GetEnv())
+		ctx = tracing.StartKeySpan(ctx, jobInfo.ID, "JobManager.submitJob",
+			attribute.String("paddleflow.job_id", jobInfo.ID),
+			attribute.String("paddleflow.queue_id", string(jobInfo.QueueID)),
+			attribute.String("paddleflow.cluster_name", clusterRuntime.Name))
+		injectTraceEnv(ctx, jobInfo)
 		err = clusterRuntime.RuntimeSvc.SubmitJob(jobInfo)
+		tracing.EndKeySpan(jobInfo.ID, attribute.Bool("paddleflow.submit_success", err == nil))
 		if err != nil {
 			// new job failed, update db and skip this job
 			msg = fmt.Sprintf("submit job to cluster failed, err: %s", err)
@@ -233,6 +263,16 @@ func (m *JobManagerImpl) submitJob(clusterRuntime *ClusterRuntimeInfo, jobInfo *
 	}
 }
 
+// injectTraceEnv sets trace envs of ctx into job and its tasks
+func injectTraceEnv(ctx context.Context, jobInfo *api.PFJob) {
+	for key, value := range tracing.EnvFromContext(ctx) {
+		jobInfo.Conf.SetEnv(key, value)
+		for i := range jobInfo.Tasks {
+			jobInfo.Tasks[i].Conf.SetEnv(key, value)
+		}
+	}
+}
+
 func (m *JobManagerImpl) stopClusterQueueSubmit(clusterID api.ClusterID) {
 	clusterQueues := storage.Queue.ListQueuesByCluster(string(clusterID))
 	for _, q := range clusterQueues {
@@ -268,6 +308,8 @@ func (m *JobManagerImpl) startRuntime() {
 			if cluster.Status == model.ClusterStatusOffLine {
 				log.Warnf("cluster[%s] status is %s, skip it", cluster.ID, model.ClusterStatusOffLine)
 				m.stopClusterRuntime(clusterID)
+				// resubmit jobs stuck on offline cluster to other clusters of their virtual queue
+				failoverClusterJobs(cluster.ID)
 				continue
 			}
 
@@ -279,8 +321,9 @@ func (m *JobManagerImpl) startRuntime() {
 					continue
 				}
 				log.Infof("Create new runtime with cluster <%s>", cluster.ID)
+				offlineRuntimes.Delete(cluster.ID)
 
-				cr := NewClusterRuntimeInfo(cluster.Name, runtimeSvc)
+				cr := NewClusterRuntimeInfo(cluster.ID, cluster.Name, runtimeSvc)
 				m.clusterRuntimes.Store(clusterID, cr)
 				// start runtime for new cluster
 				go runtimeSvc.SyncController(cr.StopCh)
@@ -339,13 +382,15 @@ func (m *JobManagerImpl) GetQueue(queueID api.QueueID) (*clusterQueue, bool) {
 
 // ClusterRuntimeInfo defines cluster runtime
 type ClusterRuntimeInfo struct {
+	ID         string
 	Name       string
 	StopCh     chan struct{}
 	RuntimeSvc runtime_v2.RuntimeService
 }
 
-func NewClusterRuntimeInfo(name string, r runtime_v2.RuntimeService) *ClusterRuntimeInfo {
+func NewClusterRuntimeInfo(id, name string, r runtime_v2.RuntimeService) *ClusterRuntimeInfo {
 	return &ClusterRuntimeInfo{
+		ID:         id,
 		Name:       name,
 		StopCh:     make(chan struct{}),
 		RuntimeSvc: r,
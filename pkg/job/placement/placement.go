@@ -0,0 +1,208 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package placement
+
+import (
+	"fmt"
+	"math/rand"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/config"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/resources"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	runtime "github.com/PaddlePaddle/PaddleFlow/pkg/job/runtime_v2"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
+)
+
+const (
+	// PolicyIdle picks the member queue whose cluster has the most idle resources
+	PolicyIdle = "idle"
+	// PolicyWeight picks the member queue randomly by the weight of its cluster
+	PolicyWeight = "weight"
+	// PolicyLocality picks the member queue whose cluster caches the most data of job's filesystems
+	PolicyLocality = "locality"
+
+	// VirtualQueueAnnotation records the virtual queue which job is submitted to
+	VirtualQueueAnnotation = "paddleflow/virtual-queue"
+	// FailoverJobAnnotation records the original job id of the job resubmitted by failover
+	FailoverJobAnnotation = "paddleflow/failover-job"
+)
+
+// ClusterIdleResource returns the idle resources of cluster, which can be replaced in unit test
+var ClusterIdleResource = func(cluster model.ClusterInfo) (*resources.Resource, error) {
+	runtimeSvc, err := runtime.GetOrCreateRuntime(cluster)
+	if err != nil {
+		return nil, err
+	}
+	summary, _, err := runtimeSvc.ListNodeQuota()
+	if err != nil {
+		return nil, err
+	}
+	return &summary.IdleQuota, nil
+}
+
+// candidate is a member queue of virtual queue which can accept jobs
+type candidate struct {
+	queue   model.Queue
+	cluster model.ClusterInfo
+}
+
+// GetVirtualQueue returns the config of virtual queue by name
+func GetVirtualQueue(name string) (*config.VirtualQueueConfig, bool) {
+	if config.GlobalServerConfig == nil || name == "" {
+		return nil, false
+	}
+	virtualQueues := config.GlobalServerConfig.Job.VirtualQueues
+	for idx := range virtualQueues {
+		if virtualQueues[idx].Name == name {
+			return &virtualQueues[idx], true
+		}
+	}
+	return nil, false
+}
+
+// SelectQueue picks a member queue of virtual queue by its placement policy, fsIDs is the filesystems used by job,
+// and member queues on excluded clusters are skipped.
+func SelectQueue(vq *config.VirtualQueueConfig, fsIDs []string, excludedClusters ...string) (*model.Queue, error) {
+	if vq == nil {
+		return nil, fmt.Errorf("virtual queue is nil")
+	}
+	candidates := listCandidates(vq, excludedClusters)
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no available member queue in virtual queue %s", vq.Name)
+	}
+
+	var selected *candidate
+	switch vq.Policy {
+	case PolicyWeight:
+		selected = selectByWeight(candidates, vq.ClusterWeights)
+	case PolicyLocality:
+		selected = selectByLocality(candidates, fsIDs)
+	case PolicyIdle, "":
+		selected = selectByIdle(candidates)
+	default:
+		return nil, fmt.Errorf("placement policy %s of virtual queue %s is not supported", vq.Policy, vq.Name)
+	}
+	log.Infof("virtual queue %s places job to queue %s on cluster %s by policy %s",
+		vq.Name, selected.queue.Name, selected.cluster.Name, vq.Policy)
+	return &selected.queue, nil
+}
+
+// listCandidates returns the open member queues whose cluster is online
+func listCandidates(vq *config.VirtualQueueConfig, excludedClusters []string) []candidate {
+	excluded := make(map[string]bool, len(excludedClusters))
+	for _, clusterID := range excludedClusters {
+		excluded[clusterID] = true
+	}
+	var candidates []candidate
+	for _, queueName := range vq.Queues {
+		q, err := storage.Queue.GetQueueByName(queueName)
+		if err != nil {
+			log.Warningf("get member queue %s of virtual queue %s failed, err: %v", queueName, vq.Name, err)
+			continue
+		}
+		if q.Status != schema.StatusQueueOpen || excluded[q.ClusterId] {
+			continue
+		}
+		cluster, err := storage.Cluster.GetClusterById(q.ClusterId)
+		if err != nil {
+			log.Warningf("get cluster %s of queue %s failed, err: %v", q.ClusterId, queueName, err)
+			continue
+		}
+		if cluster.Status != model.ClusterStatusOnLine {
+			continue
+		}
+		candidates = append(candidates, candidate{queue: q, cluster: cluster})
+	}
+	return candidates
+}
+
+// selectByIdle picks the candidate with the most idle cpu, and then the most idle memory
+func selectByIdle(candidates []candidate) *candidate {
+	var selected *candidate
+	var maxIdle *resources.Resource
+	for idx := range candidates {
+		idle, err := ClusterIdleResource(candidates[idx].cluster)
+		if err != nil {
+			log.Warningf("get idle resources of cluster %s failed, err: %v", candidates[idx].cluster.Name, err)
+			continue
+		}
+		if maxIdle == nil || idle.CPU() > maxIdle.CPU() ||
+			(idle.CPU() == maxIdle.CPU() && idle.Memory() > maxIdle.Memory()) {
+			selected, maxIdle = &candidates[idx], idle
+		}
+	}
+	if selected == nil {
+		return &candidates[0]
+	}
+	return selected
+}
+
+// selectByWeight picks the candidate randomly in proportion to the weight of its cluster,
+// the cluster without weight is never picked unless no cluster has weight
+func selectByWeight(candidates []candidate, weights map[string]int) *candidate {
+	total := 0
+	for _, c := range candidates {
+		if weights[c.cluster.Name] > 0 {
+			total += weights[c.cluster.Name]
+		}
+	}
+	if total == 0 {
+		return &candidates[rand.Intn(len(candidates))]
+	}
+	n := rand.Intn(total)
+	for idx, c := range candidates {
+		weight := weights[c.cluster.Name]
+		if weight <= 0 {
+			continue
+		}
+		if n < weight {
+			return &candidates[idx]
+		}
+		n -= weight
+	}
+	return &candidates[len(candidates)-1]
+}
+
+// selectByLocality picks the candidate whose cluster has the most cache of filesystems,
+// and falls back to idle policy when no data is cached
+func selectByLocality(candidates []candidate, fsIDs []string) *candidate {
+	cacheCount := make(map[string]int)
+	for _, fsID := range fsIDs {
+		caches, err := storage.FsCache.List(fsID, "")
+		if err != nil {
+			log.Warningf("list cache of filesystem %s failed, err: %v", fsID, err)
+			continue
+		}
+		for _, cache := range caches {
+			cacheCount[cache.ClusterID]++
+		}
+	}
+	var selected *candidate
+	maxCount := 0
+	for idx, c := range candidates {
+		if cacheCount[c.cluster.ID] > maxCount {
+			selected, maxCount = &candidates[idx], cacheCount[c.cluster.ID]
+		}
+	}
+	if selected == nil {
+		return selectByIdle(candidates)
+	}
+	return selected
+}
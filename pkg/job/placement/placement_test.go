@@ -0,0 +1,137 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package placement
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/config"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/resources"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage/driver"
+)
+
+// initPlacementTestData creates clusters and member queues for virtual queue test
+func initPlacementTestData(t *testing.T) {
+	driver.InitMockDB()
+	clusters := []model.ClusterInfo{
+		{Model: model.Model{ID: "cluster-a"}, Name: "cluster-a", Status: model.ClusterStatusOnLine},
+		{Model: model.Model{ID: "cluster-b"}, Name: "cluster-b", Status: model.ClusterStatusOnLine},
+		{Model: model.Model{ID: "cluster-c"}, Name: "cluster-c", Status: model.ClusterStatusOffLine},
+	}
+	for idx := range clusters {
+		assert.NoError(t, storage.Cluster.CreateCluster(&clusters[idx]))
+	}
+	queues := []model.Queue{
+		{Model: model.Model{ID: "queue-a"}, Name: "queue-a", ClusterId: "cluster-a", Status: schema.StatusQueueOpen},
+		{Model: model.Model{ID: "queue-b"}, Name: "queue-b", ClusterId: "cluster-b", Status: schema.StatusQueueOpen},
+		{Model: model.Model{ID: "queue-c"}, Name: "queue-c", ClusterId: "cluster-c", Status: schema.StatusQueueOpen},
+	}
+	for idx := range queues {
+		assert.NoError(t, storage.Queue.CreateQueue(&queues[idx]))
+	}
+	ClusterIdleResource = func(cluster model.ClusterInfo) (*resources.Resource, error) {
+		idle := map[string]string{"cpu": "8", "memory": "16Gi"}
+		if cluster.Name == "cluster-b" {
+			idle = map[string]string{"cpu": "8", "memory": "32Gi"}
+		}
+		return resources.NewResourceFromMap(idle)
+	}
+}
+
+func TestSelectQueue(t *testing.T) {
+	initPlacementTestData(t)
+	assert.NoError(t, storage.FsCache.Add(&model.FSCache{FsID: "fs-root-data", ClusterID: "cluster-a", NodeName: "node1"}))
+
+	testCases := []struct {
+		name      string
+		vq        *config.VirtualQueueConfig
+		fsIDs     []string
+		excluded  []string
+		wantQueue string
+		wantErr   bool
+	}{
+		{
+			name:      "idle policy",
+			vq:        &config.VirtualQueueConfig{Name: "vq", Queues: []string{"queue-a", "queue-b", "queue-c"}},
+			wantQueue: "queue-b",
+		},
+		{
+			name: "weight policy",
+			vq: &config.VirtualQueueConfig{Name: "vq", Queues: []string{"queue-a", "queue-b"}, Policy: PolicyWeight,
+				ClusterWeights: map[string]int{"cluster-a": 1}},
+			wantQueue: "queue-a",
+		},
+		{
+			name:      "locality policy",
+			vq:        &config.VirtualQueueConfig{Name: "vq", Queues: []string{"queue-a", "queue-b"}, Policy: PolicyLocality},
+			fsIDs:     []string{"fs-root-data"},
+			wantQueue: "queue-a",
+		},
+		{
+			name:      "locality policy without cache",
+			vq:        &config.VirtualQueueConfig{Name: "vq", Queues: []string{"queue-a", "queue-b"}, Policy: PolicyLocality},
+			fsIDs:     []string{"fs-root-other"},
+			wantQueue: "queue-b",
+		},
+		{
+			name:      "exclude cluster",
+			vq:        &config.VirtualQueueConfig{Name: "vq", Queues: []string{"queue-a", "queue-b"}},
+			excluded:  []string{"cluster-b"},
+			wantQueue: "queue-a",
+		},
+		{
+			name:    "no online member queue",
+			vq:      &config.VirtualQueueConfig{Name: "vq", Queues: []string{"queue-c", "queue-none"}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown policy",
+			vq:      &config.VirtualQueueConfig{Name: "vq", Queues: []string{"queue-a"}, Policy: "random"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q, err := SelectQueue(tc.vq, tc.fsIDs, tc.excluded...)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantQueue, q.Name)
+		})
+	}
+}
+
+func TestGetVirtualQueue(t *testing.T) {
+	config.GlobalServerConfig = &config.ServerConfig{
+		Job: config.JobConfig{
+			VirtualQueues: []config.VirtualQueueConfig{{Name: "vq", Queues: []string{"queue-a"}}},
+		},
+	}
+	vq, find := GetVirtualQueue("vq")
+	assert.True(t, find)
+	assert.Equal(t, []string{"queue-a"}, vq.Queues)
+	_, find = GetVirtualQueue("queue-a")
+	assert.False(t, find)
+}
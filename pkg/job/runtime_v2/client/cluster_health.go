@@ -0,0 +1,113 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	infov1 "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	pfschema "github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+)
+
+// informerTracker records the started informers, which are checked when probing cluster health
+type informerTracker struct {
+	sync.RWMutex
+	synced []cache.InformerSynced
+}
+
+func (t *informerTracker) add(synced ...cache.InformerSynced) {
+	t.Lock()
+	defer t.Unlock()
+	t.synced = append(t.synced, synced...)
+}
+
+func (t *informerTracker) allSynced() bool {
+	t.RLock()
+	defer t.RUnlock()
+	for _, hasSynced := range t.synced {
+		if !hasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
+// probeKubeHealth probes api server reachability, informer sync lag and node readiness of kubernetes cluster
+func probeKubeHealth(ctx context.Context, client kubernetes.Interface, nodeInformer infov1.NodeInformer,
+	informers *informerTracker) pfschema.ClusterHealth {
+	health := pfschema.ClusterHealth{ProbeTime: time.Now()}
+	startTime := time.Now()
+	nodes, err := client.CoreV1().Nodes().List(ctx, v1.ListOptions{})
+	health.APIServerLatencyMs = time.Since(startTime).Milliseconds()
+	if err != nil {
+		health.Message = fmt.Sprintf("api server is unreachable, err: %v", err)
+		return health
+	}
+	health.APIServerReachable = true
+	health.TotalNodes = len(nodes.Items)
+	for idx := range nodes.Items {
+		if isNodeReady(&nodes.Items[idx]) {
+			health.ReadyNodes++
+		}
+	}
+	health.InformersSynced = informers.allSynced()
+	if nodeInformer != nil && health.InformersSynced {
+		health.InformerSyncLag = resourceVersionLag(nodes.ResourceVersion, nodeInformer.Informer().LastSyncResourceVersion())
+	}
+
+	switch {
+	case !health.InformersSynced:
+		health.Message = "informers have not synced"
+	case health.ReadyNodes == 0:
+		health.Message = fmt.Sprintf("no ready node in %d nodes", health.TotalNodes)
+	default:
+		health.Healthy = true
+	}
+	return health
+}
+
+func isNodeReady(node *corev1.Node) bool {
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// resourceVersionLag returns how many resource versions the informer is behind api server,
+// and it returns 0 if resource versions are not comparable
+func resourceVersionLag(serverVersion, informerVersion string) int64 {
+	server, err := strconv.ParseInt(serverVersion, 10, 64)
+	if err != nil {
+		return 0
+	}
+	informer, err := strconv.ParseInt(informerVersion, 10, 64)
+	if err != nil || informer >= server {
+		return 0
+	}
+	return server - informer
+}
@@ -0,0 +1,94 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakedclient "k8s.io/client-go/kubernetes/fake"
+)
+
+func mockNode(name string, ready corev1.ConditionStatus) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: ready},
+			},
+		},
+	}
+}
+
+func TestProbeKubeHealth(t *testing.T) {
+	testCases := []struct {
+		name       string
+		nodes      []*corev1.Node
+		synced     bool
+		healthy    bool
+		readyNodes int
+	}{
+		{
+			name:       "cluster is healthy",
+			nodes:      []*corev1.Node{mockNode("node1", corev1.ConditionTrue), mockNode("node2", corev1.ConditionFalse)},
+			synced:     true,
+			healthy:    true,
+			readyNodes: 1,
+		},
+		{
+			name:       "no ready node",
+			nodes:      []*corev1.Node{mockNode("node1", corev1.ConditionUnknown)},
+			synced:     true,
+			healthy:    false,
+			readyNodes: 0,
+		},
+		{
+			name:       "informers have not synced",
+			nodes:      []*corev1.Node{mockNode("node1", corev1.ConditionTrue)},
+			synced:     false,
+			healthy:    false,
+			readyNodes: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := fakedclient.NewSimpleClientset()
+			for _, node := range tc.nodes {
+				_, err := client.CoreV1().Nodes().Create(context.TODO(), node, metav1.CreateOptions{})
+				assert.NoError(t, err)
+			}
+			tracker := &informerTracker{}
+			tracker.add(func() bool { return tc.synced })
+
+			health := probeKubeHealth(context.TODO(), client, nil, tracker)
+			assert.True(t, health.APIServerReachable)
+			assert.Equal(t, tc.healthy, health.Healthy)
+			assert.Equal(t, tc.readyNodes, health.ReadyNodes)
+			assert.Equal(t, len(tc.nodes), health.TotalNodes)
+		})
+	}
+}
+
+func TestResourceVersionLag(t *testing.T) {
+	assert.Equal(t, int64(5), resourceVersionLag("15", "10"))
+	assert.Equal(t, int64(0), resourceVersionLag("10", "15"))
+	assert.Equal(t, int64(0), resourceVersionLag("", "10"))
+}
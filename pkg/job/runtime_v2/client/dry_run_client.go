@@ -0,0 +1,99 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	pfschema "github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/runtime_v2/framework"
+)
+
+// DryRunClient wraps a runtime client, and records the resources created by job plugins instead of creating them on cluster
+type DryRunClient struct {
+	framework.RuntimeClientInterface
+	objects []interface{}
+}
+
+func NewDryRunClient(runtimeClient framework.RuntimeClientInterface) *DryRunClient {
+	return &DryRunClient{
+		RuntimeClientInterface: runtimeClient,
+		objects:                make([]interface{}, 0),
+	}
+}
+
+// Create records the rendered resource, and kubernetes resources are converted to unstructured with kind and apiVersion
+func (drc *DryRunClient) Create(resource interface{}, fv pfschema.FrameworkVersion) error {
+	log.Debugf("dry run to create resource[%s]", fv.String())
+	drc.objects = append(drc.objects, toDryRunObject(resource, fv))
+	return nil
+}
+
+func (drc *DryRunClient) Update(resource interface{}, fv pfschema.FrameworkVersion) error {
+	log.Debugf("dry run to update resource[%s]", fv.String())
+	drc.objects = append(drc.objects, toDryRunObject(resource, fv))
+	return nil
+}
+
+// Get returns the resource recorded in dry run, or gets it from cluster if it is not found
+func (drc *DryRunClient) Get(namespace string, name string, fv pfschema.FrameworkVersion) (interface{}, error) {
+	gvk := frameworkVersionToGVK(fv)
+	for _, object := range drc.objects {
+		content, ok := object.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		obj := &unstructured.Unstructured{Object: content}
+		if obj.GroupVersionKind() == gvk && obj.GetNamespace() == namespace && obj.GetName() == name {
+			return obj, nil
+		}
+	}
+	return drc.RuntimeClientInterface.Get(namespace, name, fv)
+}
+
+func (drc *DryRunClient) Patch(namespace, name string, fv pfschema.FrameworkVersion, data []byte) error {
+	log.Debugf("dry run to patch resource[%s] %s/%s, skip it", fv.String(), namespace, name)
+	return nil
+}
+
+func (drc *DryRunClient) Delete(namespace string, name string, fv pfschema.FrameworkVersion) error {
+	log.Debugf("dry run to delete resource[%s] %s/%s, skip it", fv.String(), namespace, name)
+	return nil
+}
+
+// Objects returns the resources recorded in dry run
+func (drc *DryRunClient) Objects() []interface{} {
+	return drc.objects
+}
+
+func toDryRunObject(resource interface{}, fv pfschema.FrameworkVersion) interface{} {
+	if _, ok := resource.(runtime.Object); !ok {
+		return resource
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(resource)
+	if err != nil {
+		log.Warnf("convert resource[%s] to unstructured failed, err: %v", fv.String(), err)
+		return resource
+	}
+	obj := &unstructured.Unstructured{Object: content}
+	gvk := frameworkVersionToGVK(fv)
+	obj.SetKind(gvk.Kind)
+	obj.SetAPIVersion(gvk.GroupVersion().String())
+	return obj.Object
+}
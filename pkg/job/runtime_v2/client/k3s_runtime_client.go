@@ -60,6 +60,8 @@ type K3SRuntimeClient struct {
 	// podInformer contains the informer of task
 	podInformer cache.SharedIndexInformer
 	taskClient  framework.JobInterface
+	// startedInformers records the informers started by listeners
+	startedInformers informerTracker
 }
 
 func (k3s *K3SRuntimeClient) Cluster() string {
@@ -284,6 +286,11 @@ func (k3s *K3SRuntimeClient) StartListener(listenerType string, stopCh <-chan st
 				break
 			}
 		}
+		if listenerType == pfschema.ListenerTypeNode && k3s.nodeInformer != nil {
+			k3s.startedInformers.add(k3s.nodeInformer.Informer().HasSynced)
+		} else if listenerType == pfschema.ListenerTypeNodeTask && k3s.nodeTaskInformer != nil {
+			k3s.startedInformers.add(k3s.nodeTaskInformer.Informer().HasSynced)
+		}
 	default:
 		err = k3s.startDynamicListener(listenerType, stopCh)
 	}
@@ -307,6 +314,7 @@ func (k3s *K3SRuntimeClient) startDynamicListener(listenerType string, stopCh <-
 	// start dynamic factory and wait for cache sync
 	k3s.DynamicFactory.Start(stopCh)
 	for _, informer := range informerMap {
+		k3s.startedInformers.add(informer.HasSynced)
 		if !cache.WaitForCacheSync(stopCh, informer.HasSynced) {
 			err = fmt.Errorf("timed out waiting for caches to %s", k3s.Cluster())
 			log.Errorf("on %s, start %s listener failed, err: %v", k3s.Cluster(), listenerType, err)
@@ -321,6 +329,16 @@ func (k3s *K3SRuntimeClient) ListNodeQuota(ctx context.Context) (pfschema.QuotaS
 	return pfschema.QuotaSummary{}, nil, nil
 }
 
+// HealthCheck probes api server reachability, informer sync lag and node readiness
+func (k3s *K3SRuntimeClient) HealthCheck(ctx context.Context) pfschema.ClusterHealth {
+	return probeKubeHealth(ctx, k3s.Client, k3s.nodeInformer, &k3s.startedInformers)
+}
+
+// AnnotateJobTasks add annotations to all tasks of job
+func (k3s *K3SRuntimeClient) AnnotateJobTasks(namespace, jobID string, annotations map[string]string) error {
+	return annotateJobTasks(k3s.Client, namespace, jobID, annotations)
+}
+
 func (k3s *K3SRuntimeClient) GetJobTypeFramework(fv pfschema.FrameworkVersion) (pfschema.JobType, pfschema.Framework) {
 	gvk := frameworkVersionToGVK(fv)
 	return k8s.GetJobTypeAndFramework(gvk)
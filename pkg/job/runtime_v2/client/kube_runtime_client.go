@@ -18,6 +18,7 @@ package client
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -96,6 +97,8 @@ type KubeRuntimeClient struct {
 	taskClient  framework.JobInterface
 	// QueueInformerMap
 	QueueInformerMap map[schema.GroupVersionKind]cache.SharedIndexInformer
+	// startedInformers records the informers started by listeners
+	startedInformers informerTracker
 }
 
 func CreateKubeRuntimeClient(config *rest.Config, cluster *pfschema.Cluster) (framework.RuntimeClientInterface, error) {
@@ -587,6 +590,11 @@ func (krc *KubeRuntimeClient) StartListener(listenerType string, stopCh <-chan s
 				break
 			}
 		}
+		if listenerType == pfschema.ListenerTypeNode && krc.nodeInformer != nil {
+			krc.startedInformers.add(krc.nodeInformer.Informer().HasSynced)
+		} else if listenerType == pfschema.ListenerTypeNodeTask && krc.nodeTaskInformer != nil {
+			krc.startedInformers.add(krc.nodeTaskInformer.Informer().HasSynced)
+		}
 	default:
 		err = krc.startDynamicListener(listenerType, stopCh)
 	}
@@ -613,6 +621,7 @@ func (krc *KubeRuntimeClient) startDynamicListener(listenerType string, stopCh <
 	// start dynamic factory and wait for cache sync
 	krc.DynamicFactory.Start(stopCh)
 	for _, informer := range informerMap {
+		krc.startedInformers.add(informer.HasSynced)
 		if !cache.WaitForCacheSync(stopCh, informer.HasSynced) {
 			err = fmt.Errorf("timed out waiting for caches to %s", krc.Cluster())
 			log.Errorf("on %s, start %s listener failed, err: %v", krc.Cluster(), listenerType, err)
@@ -826,54 +835,100 @@ func getTaskLog(client kubernetes.Interface, namespace, name, logFilePosition st
 		if err != nil {
 			return []pfschema.TaskLogInfo{}, err
 		}
-		startIndex := -1
-		endIndex := -1
-		hasNextPage := false
-		truncated := false
-		limitFlag := utils.IsReadLimitReached(int64(len(logContent)), int64(length), logFilePosition)
-		overFlag := false
-		// 判断开始位置是否已超过日志总行数，若超过overFlag为true；
-		// 如果是logFilePPosition为end，则看下startIndex是否已经超过0，若超过则置startIndex为-1（从最开始获取），并检查日志是否被截断
-		// 如果是logFilePPosition为begin，则判断末尾index是否超过总长度，若超过endIndex为-1（直到末尾），并检查日志是否被截断
-		if (pageNo-1)*pageSize+1 <= length {
-			switch logFilePosition {
-			case common.EndFilePosition:
-				startIndex = length - pageSize*pageNo
-				endIndex = length - (pageNo-1)*pageSize
-				if startIndex <= 0 {
-					startIndex = -1
-					truncated = limitFlag
-				} else {
-					hasNextPage = true
-				}
-				if endIndex == length {
-					endIndex = -1
-				}
-			case common.BeginFilePosition:
-				startIndex = (pageNo - 1) * pageSize
-				if pageNo*pageSize < length {
-					endIndex = pageNo * pageSize
-					hasNextPage = true
-				} else {
-					truncated = limitFlag
-				}
-			}
-		} else {
-			overFlag = true
-		}
-
 		taskLogInfo := pfschema.TaskLogInfo{
 			TaskID: fmt.Sprintf("%s_%s", pod.GetUID(), c.Name),
-			Info: pfschema.LogInfo{
-				LogContent:  utils.SplitLog(logContent, startIndex, endIndex, overFlag),
-				HasNextPage: hasNextPage,
-				Truncated:   truncated,
-			},
+			Info:   pagingLog(logContent, length, logFilePosition, pageSize, pageNo),
 		}
 		taskLogInfoList = append(taskLogInfoList, taskLogInfo)
 	}
 	return taskLogInfoList, nil
 }
+
+// pagingLog returns the page of log content which has length lines, the page is counted from logFilePosition
+func pagingLog(logContent string, length int, logFilePosition string, pageSize, pageNo int) pfschema.LogInfo {
+	startIndex := -1
+	endIndex := -1
+	hasNextPage := false
+	truncated := false
+	limitFlag := utils.IsReadLimitReached(int64(len(logContent)), int64(length), logFilePosition)
+	overFlag := false
+	// 判断开始位置是否已超过日志总行数，若超过overFlag为true；
+	// 如果是logFilePPosition为end，则看下startIndex是否已经超过0，若超过则置startIndex为-1（从最开始获取），并检查日志是否被截断
+	// 如果是logFilePPosition为begin，则判断末尾index是否超过总长度，若超过endIndex为-1（直到末尾），并检查日志是否被截断
+	if (pageNo-1)*pageSize+1 <= length {
+		switch logFilePosition {
+		case common.EndFilePosition:
+			startIndex = length - pageSize*pageNo
+			endIndex = length - (pageNo-1)*pageSize
+			if startIndex <= 0 {
+				startIndex = -1
+				truncated = limitFlag
+			} else {
+				hasNextPage = true
+			}
+			if endIndex == length {
+				endIndex = -1
+			}
+		case common.BeginFilePosition:
+			startIndex = (pageNo - 1) * pageSize
+			if pageNo*pageSize < length {
+				endIndex = pageNo * pageSize
+				hasNextPage = true
+			} else {
+				truncated = limitFlag
+			}
+		}
+	} else {
+		overFlag = true
+	}
+	return pfschema.LogInfo{
+		LogContent:  utils.SplitLog(logContent, startIndex, endIndex, overFlag),
+		HasNextPage: hasNextPage,
+		Truncated:   truncated,
+	}
+}
+
+// HealthCheck probes api server reachability, informer sync lag and node readiness
+func (krc *KubeRuntimeClient) HealthCheck(ctx context.Context) pfschema.ClusterHealth {
+	return probeKubeHealth(ctx, krc.Client, krc.nodeInformer, &krc.startedInformers)
+}
+
+// AnnotateJobTasks add annotations to all tasks of job
+func (krc *KubeRuntimeClient) AnnotateJobTasks(namespace, jobID string, annotations map[string]string) error {
+	return annotateJobTasks(krc.Client, namespace, jobID, annotations)
+}
+
+func annotateJobTasks(client kubernetes.Interface, namespace, jobID string, annotations map[string]string) error {
+	if client == nil {
+		return fmt.Errorf("kubernetes client is nil")
+	}
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": annotations,
+		},
+	}
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+	listOptions := v1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", pfschema.JobIDLabel, jobID),
+	}
+	podList, err := client.CoreV1().Pods(namespace).List(context.TODO(), listOptions)
+	if err != nil {
+		log.Errorf("list tasks for job %s/%s failed, err: %v", namespace, jobID, err)
+		return err
+	}
+	for _, pod := range podList.Items {
+		_, err = client.CoreV1().Pods(namespace).Patch(context.TODO(), pod.Name, types.MergePatchType, data, v1.PatchOptions{})
+		if err != nil {
+			log.Errorf("annotate task %s/%s of job %s failed, err: %v", namespace, pod.Name, jobID, err)
+			return err
+		}
+	}
+	return nil
+}
+
 func (krc *KubeRuntimeClient) GetTaskLog(namespace, name, logFilePosition string, pageSize, pageNo int) ([]pfschema.TaskLogInfo, error) {
 	return getTaskLog(krc.Client, namespace, name, logFilePosition, pageSize, pageNo)
 }
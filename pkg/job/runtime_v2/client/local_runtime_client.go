@@ -0,0 +1,414 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
+	pfschema "github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/api"
+)
+
+const (
+	localLogFileName = "job.log"
+	// localProcessResource is the resource name of local process, used by not found error
+	localProcessResource = "processes"
+)
+
+var (
+	// LocalFrameworkVersion is the framework version of jobs running on local runtime
+	LocalFrameworkVersion = pfschema.NewFrameworkVersion("Process", "local/v1")
+	// DefaultLocalRuntimeDir is the default directory which stores the work dirs and logs of jobs
+	DefaultLocalRuntimeDir = filepath.Join(os.TempDir(), "paddleflow", "local-runtime")
+)
+
+// LocalProcess is a job running as a local process, or in a container when it has image and docker is available
+type LocalProcess struct {
+	ID         string
+	Namespace  string
+	Command    string
+	Image      string
+	Env        map[string]string
+	WorkDir    string
+	LogFile    string
+	Container  bool
+	Status     pfschema.JobStatus
+	Message    string
+	ExitCode   int
+	StartTime  time.Time
+	FinishTime time.Time
+
+	cmd     *exec.Cmd
+	stopped bool
+}
+
+// RuntimeInfo returns the runtime info of process, which is stored in job
+func (p *LocalProcess) RuntimeInfo() map[string]interface{} {
+	info := map[string]interface{}{
+		"workDir":   p.WorkDir,
+		"logFile":   p.LogFile,
+		"container": p.Container,
+		"startTime": p.StartTime.Format(time.RFC3339),
+	}
+	if p.cmd != nil && p.cmd.Process != nil {
+		info["pid"] = p.cmd.Process.Pid
+		// process is the leader of its process group, the group can be killed manually after server restarts
+		info["pgid"] = p.cmd.Process.Pid
+	}
+	if !p.FinishTime.IsZero() {
+		info["finishTime"] = p.FinishTime.Format(time.RFC3339)
+		info["exitCode"] = p.ExitCode
+	}
+	return info
+}
+
+// LocalRuntimeClient runs jobs as processes on the host of PaddleFlow server, which is used for development and test.
+// Processes are only tracked in memory, so jobs running on local runtime do not survive the restart of server, and
+// can not be stopped by PaddleFlow after that, the pgid in runtime info of job is used to kill them manually.
+type LocalRuntimeClient struct {
+	ClusterInfo *pfschema.Cluster
+	// RootDir stores the work dirs and logs of jobs
+	RootDir string
+	// UseContainer defines whether run jobs with image in containers
+	UseContainer bool
+
+	mutex sync.RWMutex
+	// processes contains the local processes, key is namespace/name
+	processes map[string]*LocalProcess
+	// jobQueue receives job status updates of local processes
+	jobQueue workqueue.RateLimitingInterface
+}
+
+func NewLocalRuntimeClient(cluster *pfschema.Cluster, rootDir string, useContainer bool) *LocalRuntimeClient {
+	if rootDir == "" {
+		rootDir = DefaultLocalRuntimeDir
+	}
+	return &LocalRuntimeClient{
+		ClusterInfo:  cluster,
+		RootDir:      rootDir,
+		UseContainer: useContainer,
+		processes:    make(map[string]*LocalProcess),
+	}
+}
+
+func (lrc *LocalRuntimeClient) Cluster() string {
+	if lrc.ClusterInfo != nil {
+		return fmt.Sprintf("cluster %s with type %s", lrc.ClusterInfo.Name, lrc.ClusterInfo.Type)
+	}
+	return fmt.Sprintf("cluster %s with type %s", "local", pfschema.LocalType)
+}
+
+func (lrc *LocalRuntimeClient) ClusterID() string {
+	if lrc.ClusterInfo != nil {
+		return lrc.ClusterInfo.ID
+	}
+	return ""
+}
+
+func (lrc *LocalRuntimeClient) ClusterName() string {
+	if lrc.ClusterInfo != nil {
+		return lrc.ClusterInfo.Name
+	}
+	return ""
+}
+
+func processKey(namespace, name string) string {
+	return fmt.Sprintf("%s/%s", namespace, name)
+}
+
+// NewLocalProcess returns a process of job, whose work dir and log file are under RootDir
+func (lrc *LocalRuntimeClient) NewLocalProcess(namespace, name, command, image string, env map[string]string) *LocalProcess {
+	workDir := filepath.Join(lrc.RootDir, namespace, name)
+	return &LocalProcess{
+		ID:        name,
+		Namespace: namespace,
+		Command:   command,
+		Image:     image,
+		Env:       env,
+		WorkDir:   workDir,
+		LogFile:   filepath.Join(workDir, localLogFileName),
+		Container: lrc.UseContainer && image != "",
+	}
+}
+
+func (lrc *LocalRuntimeClient) Get(namespace string, name string, fv pfschema.FrameworkVersion) (interface{}, error) {
+	lrc.mutex.RLock()
+	defer lrc.mutex.RUnlock()
+	p, found := lrc.processes[processKey(namespace, name)]
+	if !found {
+		return nil, k8serrors.NewNotFound(schema.GroupResource{Resource: localProcessResource}, name)
+	}
+	process := *p
+	return &process, nil
+}
+
+// Create starts the local process, and status updates of process are sent to job listener
+func (lrc *LocalRuntimeClient) Create(resource interface{}, fv pfschema.FrameworkVersion) error {
+	p, ok := resource.(*LocalProcess)
+	if !ok || p == nil {
+		return fmt.Errorf("resource %T is not a local process", resource)
+	}
+	key := processKey(p.Namespace, p.ID)
+	lrc.mutex.Lock()
+	defer lrc.mutex.Unlock()
+	if _, found := lrc.processes[key]; found {
+		return k8serrors.NewAlreadyExists(schema.GroupResource{Resource: localProcessResource}, p.ID)
+	}
+
+	if err := os.MkdirAll(p.WorkDir, 0755); err != nil {
+		return err
+	}
+	logFile, err := os.OpenFile(p.LogFile, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	cmd := p.command()
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	if err = cmd.Start(); err != nil {
+		logFile.Close()
+		log.Errorf("on %s, start process %s failed, err: %v", lrc.Cluster(), key, err)
+		return err
+	}
+	log.Infof("on %s, process %s is started, pid: %d", lrc.Cluster(), key, cmd.Process.Pid)
+	p.cmd = cmd
+	p.Status = pfschema.StatusJobRunning
+	p.Message = "job is running"
+	p.StartTime = time.Now()
+	lrc.processes[key] = p
+	lrc.enqueue(p, pfschema.Create)
+
+	go lrc.wait(p, logFile)
+	return nil
+}
+
+// command runs the job command by sh -c as a whole, so commands like bash -c "..." are parsed by shell with quotes
+func (p *LocalProcess) command() *exec.Cmd {
+	command := p.Command
+	if !p.Container {
+		cmd := exec.Command("sh", "-c", command)
+		// run in a new process group, so that the command and its children are killed together
+		cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+		cmd.Dir = p.WorkDir
+		cmd.Env = os.Environ()
+		for key, value := range p.Env {
+			cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", key, value))
+		}
+		return cmd
+	}
+	args := []string{"run", "--rm", "--name", p.ID, "-v", fmt.Sprintf("%s:%s", p.WorkDir, p.WorkDir), "-w", p.WorkDir}
+	for key, value := range p.Env {
+		args = append(args, "-e", fmt.Sprintf("%s=%s", key, value))
+	}
+	args = append(args, p.Image, "sh", "-c", command)
+	return exec.Command("docker", args...)
+}
+
+// wait waits the process to exit, and maps the exit code to job status
+func (lrc *LocalRuntimeClient) wait(p *LocalProcess, logFile io.Closer) {
+	err := p.cmd.Wait()
+	logFile.Close()
+
+	lrc.mutex.Lock()
+	p.FinishTime = time.Now()
+	p.ExitCode = p.cmd.ProcessState.ExitCode()
+	action := pfschema.Update
+	switch {
+	case p.stopped:
+		// the stopped process is terminated by delete action, same as the job deleted from kubernetes
+		action = pfschema.Delete
+		p.Status = pfschema.StatusJobTerminated
+		p.Message = "job is terminated"
+	case err == nil:
+		p.Status = pfschema.StatusJobSucceeded
+		p.Message = "job is succeeded"
+	default:
+		p.Status = pfschema.StatusJobFailed
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && p.ExitCode >= 0 {
+			p.Message = fmt.Sprintf("job is failed, process exited with code %d", p.ExitCode)
+		} else {
+			p.Message = fmt.Sprintf("job is failed, err: %v", err)
+		}
+	}
+	log.Infof("on %s, process %s/%s exited with code %d, status: %s", lrc.Cluster(), p.Namespace, p.ID, p.ExitCode, p.Status)
+	lrc.enqueue(p, action)
+	lrc.mutex.Unlock()
+}
+
+func (lrc *LocalRuntimeClient) enqueue(p *LocalProcess, action pfschema.ActionType) {
+	if lrc.jobQueue == nil {
+		return
+	}
+	lrc.jobQueue.Add(&api.JobSyncInfo{
+		ID:               p.ID,
+		Namespace:        p.Namespace,
+		FrameworkVersion: LocalFrameworkVersion,
+		Status:           p.Status,
+		Message:          p.Message,
+		RuntimeInfo:      p.RuntimeInfo(),
+		Action:           action,
+	})
+}
+
+// Delete kills the running process, or cleans the finished process
+func (lrc *LocalRuntimeClient) Delete(namespace string, name string, fv pfschema.FrameworkVersion) error {
+	key := processKey(namespace, name)
+	lrc.mutex.Lock()
+	defer lrc.mutex.Unlock()
+	p, found := lrc.processes[key]
+	if !found {
+		return k8serrors.NewNotFound(schema.GroupResource{Resource: localProcessResource}, name)
+	}
+	if !p.FinishTime.IsZero() {
+		// the log of finished process is kept in work dir
+		delete(lrc.processes, key)
+		return nil
+	}
+	log.Infof("on %s, kill process %s", lrc.Cluster(), key)
+	p.stopped = true
+	if p.Container {
+		if err := exec.Command("docker", "kill", p.ID).Run(); err != nil {
+			log.Warningf("kill container %s failed, err: %v", p.ID, err)
+		}
+	}
+	return p.kill()
+}
+
+// kill kills the process group of process, which contains the shell and the processes started by command
+func (p *LocalProcess) kill() error {
+	if p.cmd.SysProcAttr == nil || !p.cmd.SysProcAttr.Setpgid {
+		if err := p.cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+			return err
+		}
+		return nil
+	}
+	if err := syscall.Kill(-p.cmd.Process.Pid, syscall.SIGKILL); err != nil && !errors.Is(err, syscall.ESRCH) {
+		return err
+	}
+	return nil
+}
+
+func (lrc *LocalRuntimeClient) Patch(namespace, name string, fv pfschema.FrameworkVersion, data []byte) error {
+	return fmt.Errorf("patch is not supported on %s", lrc.Cluster())
+}
+
+func (lrc *LocalRuntimeClient) Update(resource interface{}, fv pfschema.FrameworkVersion) error {
+	return fmt.Errorf("update is not supported on %s", lrc.Cluster())
+}
+
+// RegisterListener register job listener, task listener is accepted but no task event is sent
+func (lrc *LocalRuntimeClient) RegisterListener(listenerType string, workQueue workqueue.RateLimitingInterface) error {
+	switch listenerType {
+	case pfschema.ListenerTypeJob:
+		lrc.mutex.Lock()
+		lrc.jobQueue = workQueue
+		lrc.mutex.Unlock()
+	case pfschema.ListenerTypeTask:
+	default:
+		return fmt.Errorf("listener type %s is not supported", listenerType)
+	}
+	return nil
+}
+
+func (lrc *LocalRuntimeClient) StartListener(listenerType string, stopCh <-chan struct{}) error {
+	switch listenerType {
+	case pfschema.ListenerTypeJob, pfschema.ListenerTypeTask:
+		return nil
+	default:
+		return fmt.Errorf("listener type %s is not supported", listenerType)
+	}
+}
+
+func (lrc *LocalRuntimeClient) ListNodeQuota(ctx context.Context) (pfschema.QuotaSummary, []pfschema.NodeQuotaInfo, error) {
+	return pfschema.QuotaSummary{}, []pfschema.NodeQuotaInfo{}, nil
+}
+
+// HealthCheck always reports local runtime is healthy, as jobs run on the host of PaddleFlow server
+func (lrc *LocalRuntimeClient) HealthCheck(ctx context.Context) pfschema.ClusterHealth {
+	return pfschema.ClusterHealth{
+		ProbeTime:          time.Now(),
+		Healthy:            true,
+		APIServerReachable: true,
+		InformersSynced:    true,
+		ReadyNodes:         1,
+		TotalNodes:         1,
+	}
+}
+
+func (lrc *LocalRuntimeClient) AnnotateJobTasks(namespace, jobID string, annotations map[string]string) error {
+	return fmt.Errorf("annotate tasks is not supported on %s", lrc.Cluster())
+}
+
+func (lrc *LocalRuntimeClient) GetJobTypeFramework(fv pfschema.FrameworkVersion) (pfschema.JobType, pfschema.Framework) {
+	return pfschema.TypeSingle, pfschema.FrameworkStandalone
+}
+
+func (lrc *LocalRuntimeClient) JobFrameworkVersion(jobType pfschema.JobType, fw pfschema.Framework) pfschema.FrameworkVersion {
+	return LocalFrameworkVersion
+}
+
+// GetTaskLog returns the stdout and stderr of local process
+func (lrc *LocalRuntimeClient) GetTaskLog(namespace, name, logFilePosition string, pageSize, pageNo int) ([]pfschema.TaskLogInfo, error) {
+	logFile := filepath.Join(lrc.RootDir, namespace, name, localLogFileName)
+	logContent, length, err := readLocalLog(logFile, logFilePosition)
+	if os.IsNotExist(err) {
+		return []pfschema.TaskLogInfo{}, nil
+	} else if err != nil {
+		return []pfschema.TaskLogInfo{}, err
+	}
+	taskLogInfo := pfschema.TaskLogInfo{
+		TaskID: name,
+		Info:   pagingLog(logContent, length, logFilePosition, pageSize, pageNo),
+	}
+	return []pfschema.TaskLogInfo{taskLogInfo}, nil
+}
+
+// readLocalLog reads log file with the same limits as reading logs from kubernetes
+func readLocalLog(logFile, logFilePosition string) (string, int, error) {
+	content, err := os.ReadFile(logFile)
+	if err != nil {
+		return "", 0, err
+	}
+	if logFilePosition == common.BeginFilePosition {
+		if int64(len(content)) > byteReadLimit {
+			content = content[:byteReadLimit]
+		}
+	} else {
+		lines := strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+		if int64(len(lines)) > lineReadLimit {
+			content = []byte(strings.Join(lines[int64(len(lines))-lineReadLimit:], "\n"))
+		}
+	}
+	return string(content), len(strings.Split(strings.TrimRight(string(content), "\n"), "\n")), nil
+}
@@ -0,0 +1,96 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalProcessCommand(t *testing.T) {
+	testCases := []struct {
+		name    string
+		command string
+		env     map[string]string
+		output  string
+	}{
+		{
+			name:    "plain command",
+			command: "echo hello",
+			output:  "hello\n",
+		},
+		{
+			name:    "bash -c with quotes",
+			command: `bash -c "echo 'a  b'; echo c"`,
+			output:  "a  b\nc\n",
+		},
+		{
+			name:    "sh -c with env",
+			command: `sh -c 'echo $NAME'`,
+			env:     map[string]string{"NAME": "pf"},
+			output:  "pf\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &LocalProcess{Command: tc.command, Env: tc.env, WorkDir: t.TempDir()}
+			output, err := p.command().Output()
+			assert.NoError(t, err)
+			assert.Equal(t, tc.output, string(output))
+		})
+	}
+}
+
+// processExited returns true if process is gone or is a zombie
+func processExited(pid int) bool {
+	stat, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return syscall.Kill(pid, 0) == syscall.ESRCH
+	}
+	fields := strings.Fields(string(stat))
+	return len(fields) > 2 && fields[2] == "Z"
+}
+
+func TestLocalProcessDelete(t *testing.T) {
+	lrc := NewLocalRuntimeClient(nil, t.TempDir(), false)
+	// the child is started in background, and should be killed with the shell
+	p := lrc.NewLocalProcess("default", "job-1", "sleep 60 & echo $! > child.pid; wait", "", nil)
+	assert.NoError(t, lrc.Create(p, LocalFrameworkVersion))
+
+	var childPid int
+	assert.Eventually(t, func() bool {
+		data, err := os.ReadFile(filepath.Join(p.WorkDir, "child.pid"))
+		if err != nil {
+			return false
+		}
+		childPid, err = strconv.Atoi(strings.TrimSpace(string(data)))
+		return err == nil
+	}, 5*time.Second, 50*time.Millisecond)
+	assert.Equal(t, p.cmd.Process.Pid, p.RuntimeInfo()["pgid"])
+
+	assert.NoError(t, lrc.Delete("default", "job-1", LocalFrameworkVersion))
+	assert.Eventually(t, func() bool {
+		return processExited(childPid)
+	}, 5*time.Second, 50*time.Millisecond)
+}
@@ -0,0 +1,353 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/config"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/resources"
+	pfschema "github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/api"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/runtime_v2/framework"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/trace_logger"
+)
+
+const (
+	JobPreemptionControllerName = "JobPreemption"
+
+	DefaultPreemptionPeriodSeconds      = 30
+	DefaultPreemptionPendingSeconds     = 60
+	DefaultPreemptionGracePeriodSeconds = 60
+	DefaultPreemptionSignalAnnotation   = "paddleflow/preemption-deadline"
+	// PreemptedJobAnnotation records the original job id of the requeued job
+	PreemptedJobAnnotation = "paddleflow/preempted-job"
+)
+
+// preemptionVictim is a running job which has been signaled, and will be stopped after deadline
+type preemptionVictim struct {
+	jobID     string
+	preemptor string
+	deadline  time.Time
+}
+
+// JobPreemption preempts running jobs with low priority for pending jobs with high priority in the same queue.
+// The victims are notified by a pod annotation with the deadline first, so that training code can checkpoint,
+// and then they are stopped and requeued with their original config after the grace period.
+type JobPreemption struct {
+	runtimeClient framework.RuntimeClientInterface
+	jobGetter     framework.JobGetter
+	conf          config.PreemptionConfig
+	// victims contains the signaled jobs, key is job id
+	victims map[string]*preemptionVictim
+}
+
+func NewJobPreemption(jobGetter framework.JobGetter) *JobPreemption {
+	return &JobPreemption{
+		jobGetter: jobGetter,
+		victims:   make(map[string]*preemptionVictim),
+	}
+}
+
+func (jp *JobPreemption) Name() string {
+	return fmt.Sprintf("%s controller for %s", JobPreemptionControllerName, jp.runtimeClient.Cluster())
+}
+
+func (jp *JobPreemption) Initialize(runtimeClient framework.RuntimeClientInterface) error {
+	if runtimeClient == nil || (reflect.ValueOf(runtimeClient).Kind() == reflect.Ptr && reflect.ValueOf(runtimeClient).IsNil()) {
+		return fmt.Errorf("init %s failed, err: runtimeClient is nil", JobPreemptionControllerName)
+	}
+	if jp.jobGetter == nil {
+		return fmt.Errorf("init %s failed, err: jobGetter is nil", JobPreemptionControllerName)
+	}
+	jp.runtimeClient = runtimeClient
+	jp.conf = config.GlobalServerConfig.Job.Preemption
+	if jp.conf.PeriodSeconds <= 0 {
+		jp.conf.PeriodSeconds = DefaultPreemptionPeriodSeconds
+	}
+	if jp.conf.PendingSeconds <= 0 {
+		jp.conf.PendingSeconds = DefaultPreemptionPendingSeconds
+	}
+	if jp.conf.GracePeriodSeconds <= 0 {
+		jp.conf.GracePeriodSeconds = DefaultPreemptionGracePeriodSeconds
+	}
+	if jp.conf.SignalAnnotation == "" {
+		jp.conf.SignalAnnotation = DefaultPreemptionSignalAnnotation
+	}
+	if jp.conf.MinPreemptorPriority == "" {
+		jp.conf.MinPreemptorPriority = pfschema.EnvJobHighPriority
+	}
+	log.Infof("initialize %s successfully!", jp.Name())
+	return nil
+}
+
+func (jp *JobPreemption) Run(stopCh <-chan struct{}) {
+	log.Infof("Start %s ...", jp.Name())
+	period := time.Duration(jp.conf.PeriodSeconds) * time.Second
+	go wait.Until(jp.preempt, period, stopCh)
+}
+
+func (jp *JobPreemption) preempt() {
+	jp.evictVictims(time.Now())
+	queues := storage.Queue.ListQueuesByCluster(jp.runtimeClient.ClusterID())
+	for idx := range queues {
+		jp.preemptQueue(&queues[idx], time.Now())
+	}
+}
+
+// evictVictims stops the victims whose deadline is exceeded, and requeue them
+func (jp *JobPreemption) evictVictims(now time.Time) {
+	for jobID, victim := range jp.victims {
+		job, err := storage.Job.GetJobByID(jobID)
+		if err != nil || job.Status != pfschema.StatusJobRunning {
+			log.Infof("victim job %s is not running, skip preemption", jobID)
+			delete(jp.victims, jobID)
+			continue
+		}
+		if now.Before(victim.deadline) {
+			continue
+		}
+		if err = jp.evict(&job, victim.preemptor); err != nil {
+			log.Errorf("evict job %s for preemptor %s failed, err: %v", jobID, victim.preemptor, err)
+			continue
+		}
+		delete(jp.victims, jobID)
+	}
+}
+
+// evict creates a new job with the original config of victim, and stops the victim job. The new job is persisted
+// first, so that the victim is not lost when requeue fails
+func (jp *JobPreemption) evict(job *model.Job, preemptor string) error {
+	pfJob, err := api.NewJobInfo(job)
+	if err != nil {
+		return err
+	}
+	requeuedJob, err := newRequeuedJob(*job)
+	if err != nil {
+		return err
+	}
+	if err = storage.Job.CreateJob(requeuedJob); err != nil {
+		log.Errorf("requeue preempted job %s failed, err: %v", job.ID, err)
+		return err
+	}
+	fwVersion := jp.runtimeClient.JobFrameworkVersion(pfJob.JobType, pfJob.Framework)
+	if err = jp.jobGetter.Job(fwVersion).Stop(context.TODO(), pfJob); err != nil {
+		// victim keeps running, roll back the requeued job and evict again in next period
+		if delErr := storage.Job.DeleteJob(requeuedJob.ID); delErr != nil {
+			log.Errorf("roll back requeued job %s of job %s failed, err: %v", requeuedJob.ID, job.ID, delErr)
+		}
+		return err
+	}
+	msg := fmt.Sprintf("job is preempted by job %s, and requeued as job %s", preemptor, requeuedJob.ID)
+	if _, err = storage.Job.UpdateJob(job.ID, pfschema.StatusJobTerminated, nil, nil, msg); err != nil {
+		log.Errorf("update status of preempted job %s failed, err: %v", job.ID, err)
+		return err
+	}
+	log.Infof("%s, %s", jp.Name(), msg)
+	trace_logger.KeyWithUpdate(job.ID).Infof(msg)
+	return nil
+}
+
+// newRequeuedJob returns a job in init status with a copy of the original config of preempted job
+func newRequeuedJob(job model.Job) (*model.Job, error) {
+	conf, err := job.Config.DeepCopy()
+	if err != nil {
+		log.Errorf("copy config of preempted job %s failed, err: %v", job.ID, err)
+		return nil, err
+	}
+	if conf != nil {
+		conf.SetAnnotations(PreemptedJobAnnotation, job.ID)
+	}
+	return &model.Job{
+		Name:              job.Name,
+		UserName:          job.UserName,
+		QueueID:           job.QueueID,
+		Type:              job.Type,
+		Config:            conf,
+		Status:            pfschema.StatusJobInit,
+		Message:           fmt.Sprintf("job is requeued after preemption of job %s", job.ID),
+		Resource:          job.Resource,
+		Framework:         job.Framework,
+		Members:           job.Members,
+		ExtensionTemplate: job.ExtensionTemplate,
+		ParentJob:         job.ParentJob,
+	}, nil
+}
+
+// preemptQueue selects victims for the pending jobs with high priority in queue, and signals them
+func (jp *JobPreemption) preemptQueue(q *model.Queue, now time.Time) {
+	if q.MaxResources == nil {
+		return
+	}
+	jobs := storage.Job.ListQueueJob(q.ID, []pfschema.JobStatus{pfschema.StatusJobPending, pfschema.StatusJobRunning})
+	minRank := pfschema.PriorityRank(jp.conf.MinPreemptorPriority)
+	pendingDuration := time.Duration(jp.conf.PendingSeconds) * time.Second
+
+	var preemptors, candidates []model.Job
+	// requests contains the request resources of jobs, which are limited by queue quota
+	requests := make(map[string]*resources.Resource)
+	used := resources.EmptyResource()
+	releasing := resources.EmptyResource()
+	for _, job := range jobs {
+		res, err := storage.JobRequestResource(job)
+		if err != nil {
+			log.Warningf("get request resource of job %s failed, err: %v", job.ID, err)
+			continue
+		}
+		requests[job.ID] = quotaResource(res, q.MaxResources)
+		switch job.Status {
+		case pfschema.StatusJobPending:
+			if jobPriorityRank(job) >= minRank && now.Sub(job.CreatedAt) >= pendingDuration {
+				preemptors = append(preemptors, job)
+			}
+		case pfschema.StatusJobRunning:
+			used.Add(requests[job.ID])
+			if _, signaled := jp.victims[job.ID]; signaled {
+				releasing.Add(requests[job.ID])
+			} else {
+				candidates = append(candidates, job)
+			}
+		}
+	}
+	if len(preemptors) == 0 {
+		return
+	}
+	// the preemptor with higher priority and earlier creation time goes first
+	sort.SliceStable(preemptors, func(i, j int) bool {
+		ri, rj := jobPriorityRank(preemptors[i]), jobPriorityRank(preemptors[j])
+		if ri != rj {
+			return ri > rj
+		}
+		return preemptors[i].CreatedAt.Before(preemptors[j].CreatedAt)
+	})
+	// the victim with lower priority and shorter runtime goes first, so that less work is lost
+	sort.SliceStable(candidates, func(i, j int) bool {
+		ri, rj := jobPriorityRank(candidates[i]), jobPriorityRank(candidates[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return candidates[i].ActivatedAt.Time.After(candidates[j].ActivatedAt.Time)
+	})
+
+	free := q.MaxResources.Clone()
+	free.Sub(used)
+	free.Add(releasing)
+	for _, preemptor := range preemptors {
+		request := requests[preemptor.ID]
+		if request.LessEqual(free) {
+			// resources are enough or will be released by signaled victims
+			free.Sub(request)
+			continue
+		}
+		victims, released := selectVictims(candidates, requests, jobPriorityRank(preemptor), request, free)
+		if len(victims) == 0 {
+			log.Debugf("no enough resources can be preempted in queue %s for job %s", q.Name, preemptor.ID)
+			continue
+		}
+		for _, victim := range victims {
+			jp.signal(victim, preemptor.ID, now)
+		}
+		candidates = excludeJobs(candidates, victims)
+		free.Add(released)
+		free.Sub(request)
+	}
+}
+
+// selectVictims returns the victims with lower priority than rank, which release enough resources for request
+func selectVictims(candidates []model.Job, requests map[string]*resources.Resource, rank int,
+	request, free *resources.Resource) ([]model.Job, *resources.Resource) {
+	var victims []model.Job
+	released := resources.EmptyResource()
+	available := free.Clone()
+	for _, candidate := range candidates {
+		if jobPriorityRank(candidate) >= rank {
+			continue
+		}
+		victims = append(victims, candidate)
+		released.Add(requests[candidate.ID])
+		available.Add(requests[candidate.ID])
+		if request.LessEqual(available) {
+			return victims, released
+		}
+	}
+	return nil, nil
+}
+
+// signal notifies the victim the deadline of preemption by annotation, so that it can checkpoint in time
+func (jp *JobPreemption) signal(job model.Job, preemptor string, now time.Time) {
+	deadline := now.Add(time.Duration(jp.conf.GracePeriodSeconds) * time.Second)
+	namespace := ""
+	if job.Config != nil {
+		namespace = job.Config.GetNamespace()
+	}
+	annotations := map[string]string{
+		jp.conf.SignalAnnotation: deadline.Format(time.RFC3339),
+	}
+	if err := jp.runtimeClient.AnnotateJobTasks(namespace, job.ID, annotations); err != nil {
+		// the victim is still evicted after deadline, even if it is not notified
+		log.Warningf("signal preemption to job %s failed, err: %v", job.ID, err)
+	}
+	jp.victims[job.ID] = &preemptionVictim{
+		jobID:     job.ID,
+		preemptor: preemptor,
+		deadline:  deadline,
+	}
+	msg := fmt.Sprintf("job will be preempted by job %s at %s", preemptor, deadline.Format(time.RFC3339))
+	log.Infof("%s, job %s %s", jp.Name(), job.ID, msg)
+	trace_logger.KeyWithUpdate(job.ID).Infof(msg)
+}
+
+// quotaResource returns the resource with types which are limited by quota
+func quotaResource(res, quota *resources.Resource) *resources.Resource {
+	limited := resources.EmptyResource()
+	for name, quantity := range res.Resources {
+		if _, found := quota.Resources[name]; found {
+			limited.Resources[name] = quantity
+		}
+	}
+	return limited
+}
+
+func jobPriorityRank(job model.Job) int {
+	if job.Config == nil {
+		return pfschema.PriorityRank("")
+	}
+	return pfschema.PriorityRank(job.Config.GetPriority())
+}
+
+func excludeJobs(jobs, excluded []model.Job) []model.Job {
+	excludedIDs := make(map[string]bool, len(excluded))
+	for _, job := range excluded {
+		excludedIDs[job.ID] = true
+	}
+	var result []model.Job
+	for _, job := range jobs {
+		if !excludedIDs[job.ID] {
+			result = append(result, job)
+		}
+	}
+	return result
+}
@@ -0,0 +1,218 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/config"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/k8s"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/resources"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/api"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/runtime_v2/client"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/runtime_v2/framework"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage/driver"
+)
+
+type fakeJobGetter struct {
+	stopped []string
+	stopErr error
+}
+
+func (g *fakeJobGetter) Job(fwVersion schema.FrameworkVersion) framework.JobInterface {
+	return &fakeStopJob{getter: g}
+}
+
+type fakeStopJob struct {
+	framework.JobSample
+	getter *fakeJobGetter
+}
+
+func (j *fakeStopJob) Stop(ctx context.Context, job *api.PFJob) error {
+	if j.getter.stopErr != nil {
+		return j.getter.stopErr
+	}
+	j.getter.stopped = append(j.getter.stopped, job.ID)
+	return nil
+}
+
+func newPreemptionJob(id, queueID, priority string, status schema.JobStatus, cpu string, age time.Duration) *model.Job {
+	now := time.Now()
+	return &model.Job{
+		ID:          id,
+		Name:        id,
+		UserName:    "root",
+		QueueID:     queueID,
+		Type:        string(schema.TypeSingle),
+		Framework:   schema.FrameworkStandalone,
+		Status:      status,
+		CreatedAt:   now.Add(-age),
+		ActivatedAt: sql.NullTime{Time: now.Add(-age), Valid: status == schema.StatusJobRunning},
+		Config: &schema.Conf{
+			Env:      map[string]string{schema.EnvJobNamespace: "default"},
+			Priority: priority,
+			Flavour:  schema.Flavour{ResourceInfo: schema.ResourceInfo{CPU: cpu, Mem: "1Gi"}},
+		},
+	}
+}
+
+func TestJobPreemption(t *testing.T) {
+	config.GlobalServerConfig = &config.ServerConfig{
+		Job: config.JobConfig{
+			Preemption: config.PreemptionConfig{
+				Enable:             true,
+				GracePeriodSeconds: 30,
+			},
+		},
+	}
+	driver.InitMockDB()
+	var server = httptest.NewServer(k8s.DiscoveryHandlerFunc)
+	defer server.Close()
+	runtimeClient := client.NewFakeKubeRuntimeClient(server)
+
+	jobGetter := &fakeJobGetter{}
+	jp := NewJobPreemption(jobGetter)
+	err := jp.Initialize(runtimeClient)
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultPreemptionSignalAnnotation, jp.conf.SignalAnnotation)
+	assert.Equal(t, schema.EnvJobHighPriority, jp.conf.MinPreemptorPriority)
+
+	maxRes, err := resources.NewResourceFromMap(map[string]string{"cpu": "8", "memory": "16Gi"})
+	assert.NoError(t, err)
+	queue := &model.Queue{Name: "preemption-queue", ClusterId: runtimeClient.ClusterID(), MaxResources: maxRes}
+	assert.NoError(t, storage.Queue.CreateQueue(queue))
+
+	jobs := []*model.Job{
+		// running jobs use all resources of queue
+		newPreemptionJob("job-low-old", queue.ID, schema.EnvJobLowPriority, schema.StatusJobRunning, "4", time.Hour),
+		newPreemptionJob("job-low-new", queue.ID, schema.EnvJobLowPriority, schema.StatusJobRunning, "2", time.Minute*10),
+		newPreemptionJob("job-high-running", queue.ID, schema.EnvJobVeryHighPriority, schema.StatusJobRunning, "2", time.Hour),
+		// pending job with normal priority can not preempt others
+		newPreemptionJob("job-normal-pending", queue.ID, schema.EnvJobNormalPriority, schema.StatusJobPending, "2", time.Hour),
+		// pending job which is not pending long enough
+		newPreemptionJob("job-high-fresh", queue.ID, schema.EnvJobHighPriority, schema.StatusJobPending, "2", time.Second),
+		newPreemptionJob("job-high-pending", queue.ID, schema.EnvJobHighPriority, schema.StatusJobPending, "2", time.Hour),
+	}
+	for _, job := range jobs {
+		assert.NoError(t, storage.Job.CreateJob(job))
+	}
+	_, err = runtimeClient.Client.CoreV1().Pods("default").Create(context.TODO(), &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "job-low-new",
+			Namespace: "default",
+			Labels:    map[string]string{schema.JobIDLabel: "job-low-new"},
+		},
+	}, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	// 1. job-low-new with the lowest priority and shortest runtime is signaled
+	now := time.Now()
+	jp.preemptQueue(queue, now)
+	assert.Equal(t, 1, len(jp.victims))
+	victim, found := jp.victims["job-low-new"]
+	assert.True(t, found)
+	assert.Equal(t, "job-high-pending", victim.preemptor)
+	pod, err := runtimeClient.Client.CoreV1().Pods("default").Get(context.TODO(), "job-low-new", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, victim.deadline.Format(time.RFC3339), pod.Annotations[DefaultPreemptionSignalAnnotation])
+
+	// 2. resources to be released by signaled victims are counted, no more victims are selected
+	jp.preemptQueue(queue, now)
+	assert.Equal(t, 1, len(jp.victims))
+
+	// 3. victim is not evicted before deadline
+	jp.evictVictims(now)
+	assert.Equal(t, 0, len(jobGetter.stopped))
+
+	// 4. requeued job is rolled back when victim fails to stop, and victim is evicted again later
+	jobGetter.stopErr = fmt.Errorf("stop failed")
+	jp.evictVictims(victim.deadline)
+	assert.Equal(t, 1, len(jp.victims))
+	assert.Equal(t, 0, len(storage.Job.ListQueueInitJob(queue.ID)))
+	jobGetter.stopErr = nil
+
+	// 5. victim is stopped and requeued after deadline
+	jp.evictVictims(victim.deadline)
+	assert.Equal(t, []string{"job-low-new"}, jobGetter.stopped)
+	assert.Equal(t, 0, len(jp.victims))
+	preempted, err := storage.Job.GetJobByID("job-low-new")
+	assert.NoError(t, err)
+	assert.Equal(t, schema.StatusJobTerminated, preempted.Status)
+	assert.Contains(t, preempted.Message, "preempted by job job-high-pending")
+
+	initJobs := storage.Job.ListQueueInitJob(queue.ID)
+	assert.Equal(t, 1, len(initJobs))
+	requeued := initJobs[0]
+	assert.Equal(t, "job-low-new", requeued.Name)
+	assert.Equal(t, "job-low-new", requeued.Config.GetAnnotations()[PreemptedJobAnnotation])
+	assert.Equal(t, schema.EnvJobLowPriority, requeued.Config.GetPriority())
+	assert.Equal(t, "2", requeued.Config.Flavour.CPU)
+	// config of victim is not changed by requeue
+	_, found = preempted.Config.GetAnnotations()[PreemptedJobAnnotation]
+	assert.False(t, found)
+}
+
+func TestNewRequeuedJob(t *testing.T) {
+	job := newPreemptionJob("job-1", "queue-1", schema.EnvJobLowPriority, schema.StatusJobRunning, "2", time.Hour)
+	job.Config.Flavour.ScalarResources = schema.ScalarResourcesType{"nvidia.com/gpu": "1"}
+	requeued, err := newRequeuedJob(*job)
+	assert.NoError(t, err)
+	assert.Equal(t, "job-1", requeued.Config.GetAnnotations()[PreemptedJobAnnotation])
+	assert.Empty(t, job.Config.GetAnnotations())
+
+	requeued.Config.Env["key"] = "value"
+	requeued.Config.Flavour.ScalarResources["nvidia.com/gpu"] = "2"
+	assert.Equal(t, 1, len(job.Config.Env))
+	assert.Equal(t, "1", job.Config.Flavour.ScalarResources["nvidia.com/gpu"])
+}
+
+func TestSelectVictims(t *testing.T) {
+	newRes := func(cpu string) *resources.Resource {
+		res, _ := resources.NewResourceFromMap(map[string]string{"cpu": cpu})
+		return res
+	}
+	candidates := []model.Job{
+		*newPreemptionJob("job-1", "q", schema.EnvJobVeryLowPriority, schema.StatusJobRunning, "1", time.Minute),
+		*newPreemptionJob("job-2", "q", schema.EnvJobLowPriority, schema.StatusJobRunning, "2", time.Minute),
+		*newPreemptionJob("job-3", "q", schema.EnvJobHighPriority, schema.StatusJobRunning, "4", time.Minute),
+	}
+	requests := map[string]*resources.Resource{
+		"job-1": newRes("1"),
+		"job-2": newRes("2"),
+		"job-3": newRes("4"),
+	}
+
+	victims, released := selectVictims(candidates, requests, schema.PriorityRank(schema.EnvJobHighPriority), newRes("3"), newRes("0"))
+	assert.Equal(t, 2, len(victims))
+	assert.Equal(t, newRes("3").String(), released.String())
+
+	// jobs with the same priority can not be preempted
+	victims, _ = selectVictims(candidates, requests, schema.PriorityRank(schema.EnvJobHighPriority), newRes("5"), newRes("0"))
+	assert.Equal(t, 0, len(victims))
+}
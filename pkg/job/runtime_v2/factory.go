@@ -37,6 +37,8 @@ type RuntimeService interface {
 
 	// SubmitJob submit job to cluster
 	SubmitJob(job *api.PFJob) error
+	// RenderJob render job to the objects which would be submitted to cluster, nothing is created on cluster
+	RenderJob(job *api.PFJob) ([]interface{}, error)
 	// StopJob stop job on cluster
 	StopJob(job *api.PFJob) error
 	// UpdateJob update job on cluster
@@ -95,7 +97,7 @@ func CreateRuntime(clusterInfo model.ClusterInfo) (RuntimeService, error) {
 	cluster := newClusterConfig(clusterInfo)
 	switch cluster.Type {
 	case schema.LocalType:
-		// runtimeSvc = NewLocalRuntime(cluster)
+		runtimeSvc = NewLocalRuntime(cluster)
 	case schema.KubernetesType:
 		runtimeSvc = NewKubeRuntime(cluster)
 	case schema.K3SType:
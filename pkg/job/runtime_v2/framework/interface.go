@@ -89,6 +89,12 @@ type RuntimeClientInterface interface {
 	// ListNodeQuota resource api for cluster nodes
 	ListNodeQuota(ctx context.Context) (pfschema.QuotaSummary, []pfschema.NodeQuotaInfo, error)
 
+	// HealthCheck probes the health of cluster, such as api server reachability and node readiness
+	HealthCheck(ctx context.Context) pfschema.ClusterHealth
+
+	// AnnotateJobTasks add annotations to all tasks of job, such as the signal of preemption
+	AnnotateJobTasks(namespace, jobID string, annotations map[string]string) error
+
 	GetJobTypeFramework(fv pfschema.FrameworkVersion) (pfschema.JobType, pfschema.Framework)
 
 	JobFrameworkVersion(jobType pfschema.JobType, fw pfschema.Framework) pfschema.FrameworkVersion
@@ -21,9 +21,11 @@ import (
 	"github.com/PaddlePaddle/PaddleFlow/pkg/job/runtime_v2/framework"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/job/runtime_v2/job/argoworkflow"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/job/runtime_v2/job/mpi"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/runtime_v2/job/notebook"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/job/runtime_v2/job/paddle"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/job/runtime_v2/job/pytorch"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/job/runtime_v2/job/ray"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/runtime_v2/job/serving"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/job/runtime_v2/job/single"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/job/runtime_v2/job/spark"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/job/runtime_v2/job/tensorflow"
@@ -39,5 +41,8 @@ func init() {
 	framework.RegisterJobPlugin(pfschema.KubernetesType, spark.KubeSparkFwVersion, spark.New)
 	framework.RegisterJobPlugin(pfschema.KubernetesType, ray.KubeRayFwVersion, ray.New)
 	framework.RegisterJobPlugin(pfschema.KubernetesType, argoworkflow.KubeArgoWorkflowFwVersion, argoworkflow.New)
+	framework.RegisterJobPlugin(pfschema.KubernetesType, notebook.KubeNotebookFwVersion, notebook.New)
+	framework.RegisterJobPlugin(pfschema.KubernetesType, serving.KubeServingFwVersion, serving.New)
+	framework.RegisterJobPlugin(pfschema.KubernetesType, serving.KubeKServeFwVersion, serving.NewKServeJob)
 	// TODO: add more plugins
 }
@@ -0,0 +1,343 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notebook
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/config"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/k8s"
+	pfschema "github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/api"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/runtime_v2/client"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/runtime_v2/framework"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/runtime_v2/job/util/kuberuntime"
+)
+
+var (
+	JobGVK                = k8s.BatchJobGVK
+	KubeNotebookFwVersion = client.KubeFrameworkVersion(JobGVK)
+
+	serviceFwVersion = client.KubeFrameworkVersion(k8s.ServiceGVK)
+	ingressFwVersion = client.KubeFrameworkVersion(k8s.IngressGVK)
+)
+
+const (
+	// EnvNotebookToken is the env of token, which is used to login notebook server
+	EnvNotebookToken = "PF_NOTEBOOK_TOKEN"
+
+	notebookPortName = "notebook"
+	// vscodeDataDir is the user data dir of code-server, and the heartbeat file in it is touched when it is active
+	vscodeDataDir = "/tmp/code-server"
+	// idleCheckSeconds is the interval to check whether code-server is idle
+	idleCheckSeconds = 60
+)
+
+// KubeNotebookJob runs interactive development server, such as jupyter and vscode, with kubernetes Job,
+// and exposes it through service and ingress, which are owned by the Job and deleted with it.
+// The server exits after it is idle, so that the notebook job is stopped automatically.
+type KubeNotebookJob struct {
+	kuberuntime.KubeBaseJob
+}
+
+func New(kubeClient framework.RuntimeClientInterface) framework.JobInterface {
+	return &KubeNotebookJob{
+		KubeBaseJob: kuberuntime.NewKubeBaseJob(JobGVK, KubeNotebookFwVersion, kubeClient),
+	}
+}
+
+func (nj *KubeNotebookJob) Submit(ctx context.Context, job *api.PFJob) error {
+	if job == nil {
+		return fmt.Errorf("job is nil")
+	}
+	jobName := job.NamespacedName()
+	if len(job.Tasks) != 1 {
+		return fmt.Errorf("create %s failed, notebook must have only one member", nj.String(jobName))
+	}
+	notebookJob := &batchv1.Job{}
+	if err := kuberuntime.CreateKubeJobFromYaml(notebookJob, nj.GVK, job); err != nil {
+		log.Errorf("create %s failed, err %v", nj.String(jobName), err)
+		return err
+	}
+	// set metadata field
+	kuberuntime.BuildJobMetadata(&notebookJob.ObjectMeta, job)
+
+	conf := notebookConf(job)
+	task := job.Tasks[0]
+	if task.Name == "" {
+		task.Name = job.ID
+	}
+	if task.Port == 0 {
+		task.Port = conf.DefaultPort()
+	}
+	token := strings.ReplaceAll(uuid.NewString(), "-", "")
+	task.Env = make(map[string]string, len(job.Tasks[0].Env)+1)
+	for key, value := range job.Tasks[0].Env {
+		task.Env[key] = value
+	}
+	task.Env[EnvNotebookToken] = token
+	if task.Command == "" {
+		task.Command = notebookCommand(conf, task.Port)
+	}
+	if err := nj.buildNotebookSpec(&notebookJob.Spec, job, &task); err != nil {
+		log.Errorf("build %s spec failed, err %v", nj.String(jobName), err)
+		return err
+	}
+	useIngress := conf.Ingress && config.GlobalServerConfig.Job.Notebook.IngressDomain != ""
+	notebookJob.Annotations[pfschema.JobConnectURLAnnotation] = connectURL(job, conf, task.Port, token, useIngress)
+	notebookJob.Annotations[pfschema.JobConnectTokenAnnotation] = token
+
+	log.Debugf("begin to create %s, notebook job: %v", nj.String(jobName), notebookJob)
+	if err := nj.RuntimeClient.Create(notebookJob, nj.FrameworkVersion); err != nil {
+		log.Errorf("create %s failed, err %v", nj.String(jobName), err)
+		return err
+	}
+	// expose notebook server through service and ingress
+	ownerRef, err := kuberuntime.GetOwnerReference(nj.RuntimeClient, nj.GVK, job.Namespace, job.ID)
+	if err != nil {
+		log.Errorf("get owner reference of %s failed, err %v", nj.String(jobName), err)
+		return err
+	}
+	if err = nj.RuntimeClient.Create(newService(job, task.Port, ownerRef), serviceFwVersion); err != nil {
+		log.Errorf("create service for %s failed, err %v", nj.String(jobName), err)
+		return err
+	}
+	if useIngress {
+		if err = nj.RuntimeClient.Create(newIngress(job, task.Port, ownerRef), ingressFwVersion); err != nil {
+			log.Errorf("create ingress for %s failed, err %v", nj.String(jobName), err)
+			return err
+		}
+	}
+	return nil
+}
+
+func (nj *KubeNotebookJob) buildNotebookSpec(jobSpec *batchv1.JobSpec, job *api.PFJob, task *pfschema.Member) error {
+	// notebook is never retried, the job is finished when server exits
+	backoffLimit := int32(0)
+	jobSpec.BackoffLimit = &backoffLimit
+	if err := kuberuntime.BuildPodTemplateSpec(&jobSpec.Template, job.ID, task); err != nil {
+		return err
+	}
+	podSpec := &jobSpec.Template.Spec
+	if podSpec.RestartPolicy == corev1.RestartPolicyAlways {
+		podSpec.RestartPolicy = corev1.RestartPolicyNever
+	}
+	// set scheduling policy
+	if jobSpec.Template.Annotations == nil {
+		jobSpec.Template.Annotations = make(map[string]string)
+	}
+	if len(job.QueueName) > 0 {
+		jobSpec.Template.Annotations[pfschema.QueueLabelKey] = job.QueueName
+	}
+	podSpec.PriorityClassName = kuberuntime.KubePriorityClass(job.PriorityClassName)
+	// expose port of notebook server
+	container := &podSpec.Containers[0]
+	container.Ports = append(container.Ports, corev1.ContainerPort{
+		Name:          notebookPortName,
+		ContainerPort: int32(task.Port),
+		Protocol:      corev1.ProtocolTCP,
+	})
+	return nil
+}
+
+func notebookConf(job *api.PFJob) pfschema.NotebookConf {
+	conf := pfschema.NotebookConf{
+		Kind:        pfschema.NotebookJupyter,
+		IdleTimeout: config.GlobalServerConfig.Job.Notebook.IdleTimeoutSeconds,
+	}
+	if job.Conf.Notebook != nil {
+		conf = *job.Conf.Notebook
+		if conf.Kind == "" {
+			conf.Kind = pfschema.NotebookJupyter
+		}
+	}
+	return conf
+}
+
+// notebookCommand returns the command to start notebook server, which exits after it is idle for IdleTimeout seconds
+func notebookCommand(conf pfschema.NotebookConf, port int) string {
+	var command string
+	switch conf.Kind {
+	case pfschema.NotebookVSCode:
+		command = fmt.Sprintf("PASSWORD=$%s code-server --bind-addr 0.0.0.0:%d --auth password "+
+			"--user-data-dir %s --disable-telemetry .", EnvNotebookToken, port, vscodeDataDir)
+		if conf.IdleTimeout > 0 {
+			// code-server touches heartbeat file when it is active, and it is killed if heartbeat is out of date
+			command = fmt.Sprintf("%s & pid=$!; start=$(date +%%s); while kill -0 $pid 2>/dev/null; do "+
+				"sleep %d; last=$(stat -c %%Y %s/heartbeat 2>/dev/null || echo $start); "+
+				"if [ $(($(date +%%s) - last)) -gt %d ]; then kill $pid; exit 0; fi; done; wait $pid",
+				command, idleCheckSeconds, vscodeDataDir, conf.IdleTimeout)
+		}
+	default:
+		command = fmt.Sprintf("jupyter lab --ip=0.0.0.0 --port=%d --no-browser --allow-root "+
+			"--ServerApp.token=$%s --ServerApp.allow_origin='*'", port, EnvNotebookToken)
+		if conf.IdleTimeout > 0 {
+			// idle kernels and terminals are culled, and then server is shutdown when there is no activity
+			command = fmt.Sprintf("%s --MappingKernelManager.cull_idle_timeout=%d --MappingKernelManager.cull_interval=%d "+
+				"--TerminalManager.cull_inactive_timeout=%d --ServerApp.shutdown_no_activity_timeout=%d",
+				command, conf.IdleTimeout, idleCheckSeconds, conf.IdleTimeout, conf.IdleTimeout)
+		}
+	}
+	return command
+}
+
+func connectURL(job *api.PFJob, conf pfschema.NotebookConf, port int, token string, useIngress bool) string {
+	var url string
+	if useIngress {
+		scheme := config.GlobalServerConfig.Job.Notebook.IngressScheme
+		if scheme == "" {
+			scheme = "http"
+		}
+		url = fmt.Sprintf("%s://%s/", scheme, ingressHost(job))
+	} else {
+		url = fmt.Sprintf("http://%s.%s.svc:%d/", job.ID, job.Namespace, port)
+	}
+	if conf.Kind == pfschema.NotebookJupyter {
+		url = fmt.Sprintf("%s?token=%s", url, token)
+	}
+	return url
+}
+
+func ingressHost(job *api.PFJob) string {
+	return fmt.Sprintf("%s.%s", job.ID, config.GlobalServerConfig.Job.Notebook.IngressDomain)
+}
+
+func newObjectMeta(job *api.PFJob, ownerRef metav1.OwnerReference) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      job.ID,
+		Namespace: job.Namespace,
+		Labels: map[string]string{
+			pfschema.JobOwnerLabel: pfschema.JobOwnerValue,
+			pfschema.JobIDLabel:    job.ID,
+		},
+		OwnerReferences: []metav1.OwnerReference{ownerRef},
+	}
+}
+
+func newService(job *api.PFJob, port int, ownerRef metav1.OwnerReference) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: newObjectMeta(job, ownerRef),
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{
+				pfschema.JobIDLabel: job.ID,
+			},
+			Ports: []corev1.ServicePort{
+				{
+					Name:       notebookPortName,
+					Port:       int32(port),
+					TargetPort: intstr.FromInt(port),
+					Protocol:   corev1.ProtocolTCP,
+				},
+			},
+		},
+	}
+}
+
+func newIngress(job *api.PFJob, port int, ownerRef metav1.OwnerReference) *networkingv1.Ingress {
+	pathType := networkingv1.PathTypePrefix
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: newObjectMeta(job, ownerRef),
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{
+				{
+					Host: ingressHost(job),
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{
+								{
+									Path:     "/",
+									PathType: &pathType,
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{
+											Name: job.ID,
+											Port: networkingv1.ServiceBackendPort{Number: int32(port)},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if className := config.GlobalServerConfig.Job.Notebook.IngressClassName; className != "" {
+		ingress.Spec.IngressClassName = &className
+	}
+	return ingress
+}
+
+func (nj *KubeNotebookJob) AddEventListener(ctx context.Context, listenerType string, jobQueue workqueue.RateLimitingInterface, listener interface{}) error {
+	var err error
+	switch listenerType {
+	case pfschema.ListenerTypeJob:
+		err = nj.AddJobEventListener(ctx, jobQueue, listener, nj.JobStatus, nil)
+	default:
+		err = fmt.Errorf("listenerType %s is not supported", listenerType)
+	}
+	return err
+}
+
+// JobStatus get the statusInfo of notebook job, including origin status, pf status and message
+func (nj *KubeNotebookJob) JobStatus(obj interface{}) (api.StatusInfo, error) {
+	unObj := obj.(*unstructured.Unstructured)
+	// convert to Job struct
+	job := &batchv1.Job{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unObj.Object, job); err != nil {
+		log.Errorf("convert unstructured object [%+v] to %s job failed. error: %s", obj, nj.GVK.String(), err)
+		return api.StatusInfo{}, err
+	}
+	originStatus, state, msg := getJobStatus(&job.Status)
+	log.Infof("notebook job status: %s", state)
+	return api.StatusInfo{
+		OriginStatus: originStatus,
+		Status:       state,
+		Message:      msg,
+	}, nil
+}
+
+func getJobStatus(jobStatus *batchv1.JobStatus) (string, pfschema.JobStatus, string) {
+	for _, cond := range jobStatus.Conditions {
+		if cond.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch cond.Type {
+		case batchv1.JobComplete:
+			return string(cond.Type), pfschema.StatusJobSucceeded, "notebook is stopped"
+		case batchv1.JobFailed:
+			return string(cond.Type), pfschema.StatusJobFailed,
+				fmt.Sprintf("notebook is failed, reason: %s, message: %s", cond.Reason, cond.Message)
+		}
+	}
+	if jobStatus.Active > 0 {
+		return "Active", pfschema.StatusJobRunning, "notebook is running"
+	}
+	return "Pending", pfschema.StatusJobPending, "notebook is pending"
+}
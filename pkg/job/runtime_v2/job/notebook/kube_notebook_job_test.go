@@ -0,0 +1,201 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notebook
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/config"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/k8s"
+	pfschema "github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/api"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/runtime_v2/client"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage/driver"
+)
+
+func newMockNotebookJob(id string, conf *pfschema.NotebookConf) *api.PFJob {
+	return &api.PFJob{
+		ID:        id,
+		Name:      id,
+		Namespace: "default",
+		JobType:   pfschema.TypeNotebook,
+		Framework: pfschema.FrameworkStandalone,
+		UserName:  "root",
+		QueueID:   "mockQueueID",
+		QueueName: "mockQueueName",
+		Conf: pfschema.Conf{
+			Notebook: conf,
+		},
+		Tasks: []pfschema.Member{
+			{
+				Replicas: 1,
+				Role:     pfschema.RoleWorker,
+				Conf: pfschema.Conf{
+					Image: "jupyter/base-notebook",
+					Env: map[string]string{
+						"PF_JOB_QUEUE_NAME": "mockQueueName",
+					},
+					Flavour: pfschema.Flavour{ResourceInfo: pfschema.ResourceInfo{CPU: "1", Mem: "2Gi"}},
+				},
+			},
+		},
+	}
+}
+
+func getObject(t *testing.T, kubeClient *client.KubeRuntimeClient, name string, fv pfschema.FrameworkVersion, out interface{}) {
+	obj, err := kubeClient.Get("default", name, fv)
+	assert.NoError(t, err)
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.(*unstructured.Unstructured).Object, out)
+	assert.NoError(t, err)
+}
+
+func TestNotebookJob_Submit(t *testing.T) {
+	config.GlobalServerConfig = &config.ServerConfig{}
+	config.GlobalServerConfig.Job.SchedulerName = "testSchedulerName"
+	config.GlobalServerConfig.Job.Notebook = config.NotebookConfig{
+		IngressDomain:      "notebook.example.com",
+		IngressClassName:   "nginx",
+		IngressScheme:      "https",
+		IdleTimeoutSeconds: 3600,
+	}
+	config.InitJobTemplate("../../../../../config/server/default/job/job_template.yaml")
+
+	var server = httptest.NewServer(k8s.DiscoveryHandlerFunc)
+	defer server.Close()
+	kubeRuntimeClient := client.NewFakeKubeRuntimeClient(server)
+	driver.InitMockDB()
+
+	notebookJob := New(kubeRuntimeClient)
+
+	// jupyter notebook exposed by ingress
+	jupyterJob := newMockNotebookJob("notebook-jupyter", &pfschema.NotebookConf{
+		Kind:        pfschema.NotebookJupyter,
+		IdleTimeout: 600,
+		Ingress:     true,
+	})
+	err := notebookJob.Submit(context.TODO(), jupyterJob)
+	assert.NoError(t, err)
+
+	job := &batchv1.Job{}
+	getObject(t, kubeRuntimeClient, jupyterJob.ID, KubeNotebookFwVersion, job)
+	token := job.Annotations[pfschema.JobConnectTokenAnnotation]
+	assert.NotEmpty(t, token)
+	assert.Equal(t, "https://notebook-jupyter.notebook.example.com/?token="+token,
+		job.Annotations[pfschema.JobConnectURLAnnotation])
+	assert.Equal(t, int32(0), *job.Spec.BackoffLimit)
+	podSpec := job.Spec.Template.Spec
+	assert.Equal(t, corev1.RestartPolicyNever, podSpec.RestartPolicy)
+	assert.Equal(t, "mockQueueName", job.Spec.Template.Annotations[pfschema.QueueLabelKey])
+	container := podSpec.Containers[0]
+	assert.Equal(t, int32(pfschema.DefaultJupyterPort), container.Ports[0].ContainerPort)
+	assert.True(t, strings.Contains(strings.Join(container.Command, " "), "shutdown_no_activity_timeout=600"))
+	var tokenEnv string
+	for _, env := range container.Env {
+		if env.Name == EnvNotebookToken {
+			tokenEnv = env.Value
+		}
+	}
+	assert.Equal(t, token, tokenEnv)
+
+	service := &corev1.Service{}
+	getObject(t, kubeRuntimeClient, jupyterJob.ID, serviceFwVersion, service)
+	assert.Equal(t, jupyterJob.ID, service.Spec.Selector[pfschema.JobIDLabel])
+	assert.Equal(t, int32(pfschema.DefaultJupyterPort), service.Spec.Ports[0].Port)
+	assert.Equal(t, "Job", service.OwnerReferences[0].Kind)
+
+	ingress := &networkingv1.Ingress{}
+	getObject(t, kubeRuntimeClient, jupyterJob.ID, ingressFwVersion, ingress)
+	assert.Equal(t, "notebook-jupyter.notebook.example.com", ingress.Spec.Rules[0].Host)
+	assert.Equal(t, "nginx", *ingress.Spec.IngressClassName)
+
+	// vscode notebook exposed by service only
+	vscodeJob := newMockNotebookJob("notebook-vscode", &pfschema.NotebookConf{
+		Kind:        pfschema.NotebookVSCode,
+		IdleTimeout: 600,
+	})
+	err = notebookJob.Submit(context.TODO(), vscodeJob)
+	assert.NoError(t, err)
+	job = &batchv1.Job{}
+	getObject(t, kubeRuntimeClient, vscodeJob.ID, KubeNotebookFwVersion, job)
+	assert.Equal(t, "http://notebook-vscode.default.svc:8080/", job.Annotations[pfschema.JobConnectURLAnnotation])
+	assert.True(t, strings.Contains(strings.Join(job.Spec.Template.Spec.Containers[0].Command, " "), "code-server"))
+	_, err = kubeRuntimeClient.Get("default", vscodeJob.ID, ingressFwVersion)
+	assert.Error(t, err)
+
+	// invalid notebook job
+	err = notebookJob.Submit(context.TODO(), nil)
+	assert.Error(t, err)
+	invalidJob := newMockNotebookJob("notebook-invalid", nil)
+	invalidJob.Tasks = append(invalidJob.Tasks, invalidJob.Tasks[0])
+	err = notebookJob.Submit(context.TODO(), invalidJob)
+	assert.Error(t, err)
+}
+
+func TestNotebookJob_JobStatus(t *testing.T) {
+	testCases := []struct {
+		name       string
+		status     batchv1.JobStatus
+		wantStatus pfschema.JobStatus
+	}{
+		{
+			name:       "pending",
+			status:     batchv1.JobStatus{},
+			wantStatus: pfschema.StatusJobPending,
+		},
+		{
+			name:       "running",
+			status:     batchv1.JobStatus{Active: 1},
+			wantStatus: pfschema.StatusJobRunning,
+		},
+		{
+			name: "stopped after idle",
+			status: batchv1.JobStatus{
+				Conditions: []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}},
+			},
+			wantStatus: pfschema.StatusJobSucceeded,
+		},
+		{
+			name: "failed",
+			status: batchv1.JobStatus{
+				Conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}},
+			},
+			wantStatus: pfschema.StatusJobFailed,
+		},
+	}
+
+	notebookJob := &KubeNotebookJob{}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			job := &batchv1.Job{Status: tc.status}
+			content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(job)
+			assert.NoError(t, err)
+			statusInfo, err := notebookJob.JobStatus(&unstructured.Unstructured{Object: content})
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantStatus, statusInfo.Status)
+		})
+	}
+}
@@ -198,10 +198,15 @@ func (pj *KubePaddleJob) builtinPaddleJob(pdj *paddlejobv1.PaddleJob, job *api.P
 			log.Errorf("parse resources for %s task failed, err: %v", pj.String(jobName), err)
 			return err
 		}
-		taskResources.Multi(task.Replicas)
+		// elastic task can be scheduled with its min replicas
+		replicas := task.Replicas
+		if task.IsElastic() {
+			replicas = task.ElasticMinReplicas()
+		}
+		taskResources.Multi(replicas)
 		minResources.Add(taskResources)
 		// calculate min available
-		minAvailable += int32(task.Replicas)
+		minAvailable += int32(replicas)
 	}
 	// set minAvailable and minResources for paddle job
 	if pdj.Spec.SchedulingPolicy != nil {
@@ -223,6 +228,12 @@ func (pj *KubePaddleJob) patchPaddleTask(resourceSpec *paddlejobv1.ResourceSpec,
 	if resourceSpec.Replicas <= 0 {
 		resourceSpec.Replicas = kuberuntime.DefaultReplicas
 	}
+	// elastic is enabled by paddle operator when limits is set
+	if task.IsElastic() {
+		minReplicas, maxReplicas := task.ElasticMinReplicas(), task.MaxReplicas
+		resourceSpec.Requests = &minReplicas
+		resourceSpec.Limits = &maxReplicas
+	}
 	// set metadata
 	if task.Name == "" {
 		task.Name = uuid.GenerateIDWithLength(jobID, 3)
@@ -335,10 +346,15 @@ func (pj *KubePaddleJob) patchResource(pdj *paddlejobv1.PaddleJob, job *api.PFJo
 			log.Errorf("parse resources for %s task failed, err: %v", pj.String(job.ID), err)
 			return err
 		}
-		taskResources.Multi(task.Replicas)
+		// elastic task can be scheduled with its min replicas
+		replicas := task.Replicas
+		if task.IsElastic() {
+			replicas = task.ElasticMinReplicas()
+		}
+		taskResources.Multi(replicas)
 		minResources.Add(taskResources)
 		// calculate min available
-		minAvailable += int32(task.Replicas)
+		minAvailable += int32(replicas)
 	}
 	// set minAvailable and minResources for paddle job
 	if pdj.Spec.SchedulingPolicy != nil {
@@ -23,6 +23,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/config"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/k8s"
@@ -575,3 +576,47 @@ func TestPaddleJob_CreateJob(t *testing.T) {
 		})
 	}
 }
+
+func TestPaddleJob_ScaleJob(t *testing.T) {
+	config.GlobalServerConfig = &config.ServerConfig{}
+	config.GlobalServerConfig.Job.SchedulerName = "testSchedulerName"
+	defaultJobYamlPath := "../../../../../config/server/default/job/job_template.yaml"
+	config.InitJobTemplate(defaultJobYamlPath)
+
+	var server = httptest.NewServer(k8s.DiscoveryHandlerFunc)
+	defer server.Close()
+	kubeRuntimeClient := client.NewFakeKubeRuntimeClient(server)
+	driver.InitMockDB()
+
+	elasticJob := mockPaddleJob
+	elasticJob.ID = "job-elastic-0001"
+	elasticJob.ExtensionTemplate = nil
+	elasticJob.Tasks = []schema.Member{mockPaddleJob.Tasks[0]}
+	elasticJob.Tasks[0].MinReplicas = 2
+	elasticJob.Tasks[0].MaxReplicas = 4
+
+	paddleJob := New(kubeRuntimeClient)
+	err := paddleJob.Submit(context.TODO(), &elasticJob)
+	assert.NoError(t, err)
+	obj, err := kubeRuntimeClient.Get(elasticJob.Namespace, elasticJob.ID, KubePaddleFwVersion)
+	assert.NoError(t, err)
+	unObj := obj.(*unstructured.Unstructured)
+	requests, _, _ := unstructured.NestedInt64(unObj.Object, "spec", "worker", "requests")
+	limits, _, _ := unstructured.NestedInt64(unObj.Object, "spec", "worker", "limits")
+	assert.Equal(t, int64(2), requests)
+	assert.Equal(t, int64(4), limits)
+
+	// scale worker replicas
+	elasticJob.UpdateReplicas(map[schema.MemberRole]int{schema.RoleWorker: 4})
+	err = paddleJob.Update(context.TODO(), &elasticJob)
+	assert.NoError(t, err)
+	obj, err = kubeRuntimeClient.Get(elasticJob.Namespace, elasticJob.ID, KubePaddleFwVersion)
+	assert.NoError(t, err)
+	replicas, _, _ := unstructured.NestedInt64(obj.(*unstructured.Unstructured).Object, "spec", "worker", "replicas")
+	assert.Equal(t, int64(4), replicas)
+
+	// master role can not be scaled in paddle job
+	elasticJob.UpdateReplicas(map[schema.MemberRole]int{schema.RoleMaster: 2})
+	err = paddleJob.Update(context.TODO(), &elasticJob)
+	assert.Error(t, err)
+}
@@ -94,7 +94,6 @@ func (pj *KubePyTorchJob) builtinPyTorchJobSpec(torchJobSpec *pytorchv1.PyTorchJ
 	}
 	jobName := job.NamespacedName()
 	log.Debugf("patch %s spec:%#v", pj.String(jobName), torchJobSpec)
-	// TODO: set ElasticPolicy for PyTorchJob
 	// set PyTorchReplicaSpecs
 	minResources := resources.EmptyResource()
 	for _, task := range job.Tasks {
@@ -117,7 +116,17 @@ func (pj *KubePyTorchJob) builtinPyTorchJobSpec(torchJobSpec *pytorchv1.PyTorchJ
 			log.Errorf("parse resources for %s task failed, err: %v", pj.String(jobName), err)
 			return err
 		}
-		taskResources.Multi(task.Replicas)
+		replicas := task.Replicas
+		if task.IsElastic() && replicaType == pytorchv1.PyTorchReplicaTypeWorker {
+			// set ElasticPolicy for elastic workers, and job can be scheduled with min replicas
+			minReplicas, maxReplicas := int32(task.ElasticMinReplicas()), int32(task.MaxReplicas)
+			torchJobSpec.ElasticPolicy = &pytorchv1.ElasticPolicy{
+				MinReplicas: &minReplicas,
+				MaxReplicas: &maxReplicas,
+			}
+			replicas = task.ElasticMinReplicas()
+		}
+		taskResources.Multi(replicas)
 		minResources.Add(taskResources)
 	}
 	// set RunPolicy
@@ -0,0 +1,255 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serving
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/k8s"
+	pfschema "github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/api"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/runtime_v2/client"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/runtime_v2/framework"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/runtime_v2/job/util/kuberuntime"
+)
+
+var (
+	KServeJobGVK        = k8s.InferenceServiceGVK
+	KubeKServeFwVersion = client.KubeFrameworkVersion(KServeJobGVK)
+)
+
+const (
+	kserveScaleMetricCPU = "cpu"
+	kserveConditionReady = "Ready"
+)
+
+// inferenceService is the subset of KServe InferenceService used by serving job
+type inferenceService struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              inferenceServiceSpec   `json:"spec"`
+	Status            inferenceServiceStatus `json:"status,omitempty"`
+}
+
+type inferenceServiceSpec struct {
+	Predictor predictorSpec `json:"predictor"`
+}
+
+// predictorSpec defines the custom model server of predictor, and the pod spec is inlined
+type predictorSpec struct {
+	corev1.PodSpec `json:",inline"`
+	MinReplicas    *int   `json:"minReplicas,omitempty"`
+	MaxReplicas    int    `json:"maxReplicas,omitempty"`
+	ScaleTarget    *int   `json:"scaleTarget,omitempty"`
+	ScaleMetric    string `json:"scaleMetric,omitempty"`
+	// CanaryTrafficPercent is the traffic percent routed to the latest ready revision
+	CanaryTrafficPercent *int `json:"canaryTrafficPercent,omitempty"`
+}
+
+type inferenceServiceStatus struct {
+	URL        string                            `json:"url,omitempty"`
+	Conditions []inferenceServiceStatusCondition `json:"conditions,omitempty"`
+}
+
+type inferenceServiceStatusCondition struct {
+	Type    string                 `json:"type"`
+	Status  corev1.ConditionStatus `json:"status"`
+	Reason  string                 `json:"reason,omitempty"`
+	Message string                 `json:"message,omitempty"`
+}
+
+// KubeKServeJob runs model server with KServe InferenceService, which routes traffic between revisions and
+// scales model server by itself.
+type KubeKServeJob struct {
+	kuberuntime.KubeBaseJob
+}
+
+func NewKServeJob(kubeClient framework.RuntimeClientInterface) framework.JobInterface {
+	return &KubeKServeJob{
+		KubeBaseJob: kuberuntime.NewKubeBaseJob(KServeJobGVK, KubeKServeFwVersion, kubeClient),
+	}
+}
+
+func (kj *KubeKServeJob) Submit(ctx context.Context, job *api.PFJob) error {
+	if job == nil {
+		return fmt.Errorf("job is nil")
+	}
+	jobName := job.NamespacedName()
+	isvc, err := kj.buildInferenceService(job)
+	if err != nil {
+		log.Errorf("build %s failed, err %v", kj.String(jobName), err)
+		return err
+	}
+	// the canary rollout only takes effect on update
+	isvc.Spec.Predictor.CanaryTrafficPercent = nil
+	isvc.Annotations[pfschema.JobConnectURLAnnotation] = fmt.Sprintf("http://%s.%s.svc.cluster.local/",
+		job.ID, job.Namespace)
+	obj, err := toUnstructured(isvc)
+	if err != nil {
+		return err
+	}
+	log.Debugf("begin to create %s, inference service: %v", kj.String(jobName), obj)
+	if err = kj.RuntimeClient.Create(obj, kj.FrameworkVersion); err != nil {
+		log.Errorf("create %s failed, err %v", kj.String(jobName), err)
+		return err
+	}
+	return nil
+}
+
+// Update updates labels and annotations of serving job, and rolls out the latest member of serving job,
+// KServe routes canary traffic percent to the latest revision, and the rest to the previous revision.
+func (kj *KubeKServeJob) Update(ctx context.Context, job *api.PFJob) error {
+	if job == nil {
+		return fmt.Errorf("job is nil")
+	}
+	jobName := job.NamespacedName()
+	log.Infof("begin to update %s", kj.String(jobName))
+	obj, err := kj.RuntimeClient.Get(job.Namespace, job.ID, kj.FrameworkVersion)
+	if err != nil {
+		log.Errorf("get %s failed, err: %v", kj.String(jobName), err)
+		return err
+	}
+	current := &inferenceService{}
+	if err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.(*unstructured.Unstructured).Object, current); err != nil {
+		return err
+	}
+	latest, err := kj.buildInferenceService(job)
+	if err != nil {
+		log.Errorf("build %s failed, err: %v", kj.String(jobName), err)
+		return err
+	}
+	updateObjectMeta(&current.ObjectMeta, job.Labels, job.Annotations)
+	current.Spec = latest.Spec
+	updated, err := toUnstructured(current)
+	if err != nil {
+		return err
+	}
+	if err = kj.RuntimeClient.Update(updated, kj.FrameworkVersion); err != nil {
+		log.Errorf("update %s failed, err: %v", kj.String(jobName), err)
+		return err
+	}
+	return nil
+}
+
+func (kj *KubeKServeJob) buildInferenceService(job *api.PFJob) (*inferenceService, error) {
+	if len(job.Tasks) != 1 {
+		return nil, fmt.Errorf("serving job %s must have only one member", job.ID)
+	}
+	isvc := &inferenceService{}
+	if err := kuberuntime.CreateKubeJobFromYaml(isvc, kj.GVK, job); err != nil {
+		return nil, err
+	}
+	// set metadata field
+	kuberuntime.BuildJobMetadata(&isvc.ObjectMeta, job)
+
+	task := newServingTask(job)
+	template := &corev1.PodTemplateSpec{Spec: isvc.Spec.Predictor.PodSpec}
+	if err := buildServingPodTemplate(template, job, &task); err != nil {
+		return nil, err
+	}
+	predictor := &isvc.Spec.Predictor
+	predictor.PodSpec = template.Spec
+	// KServe manages the restart policy and scheduler of predictor
+	predictor.RestartPolicy = ""
+	predictor.PriorityClassName = ""
+	predictor.SchedulerName = ""
+
+	conf := job.Conf.Serving
+	if conf != nil && conf.Autoscaling != nil {
+		minReplicas := conf.Autoscaling.MinReplicas
+		targetCPU := conf.Autoscaling.TargetCPUUtilization
+		if targetCPU == 0 {
+			targetCPU = pfschema.DefaultTargetCPUUtilization
+		}
+		predictor.MinReplicas = &minReplicas
+		predictor.MaxReplicas = conf.Autoscaling.MaxReplicas
+		predictor.ScaleTarget = &targetCPU
+		predictor.ScaleMetric = kserveScaleMetricCPU
+	} else {
+		replicas := servingReplicas(&task, conf)
+		predictor.MinReplicas = &replicas
+		predictor.MaxReplicas = replicas
+	}
+	if conf.IsCanary() {
+		percent := *conf.CanaryTrafficPercent
+		predictor.CanaryTrafficPercent = &percent
+	}
+	return isvc, nil
+}
+
+func (kj *KubeKServeJob) AddEventListener(ctx context.Context, listenerType string, jobQueue workqueue.RateLimitingInterface, listener interface{}) error {
+	var err error
+	switch listenerType {
+	case pfschema.ListenerTypeJob:
+		err = kj.AddJobEventListener(ctx, jobQueue, listener, kj.JobStatus, nil)
+	default:
+		err = fmt.Errorf("listenerType %s is not supported", listenerType)
+	}
+	return err
+}
+
+// JobStatus get the statusInfo of KServe serving job, including origin status, pf status and message
+func (kj *KubeKServeJob) JobStatus(obj interface{}) (api.StatusInfo, error) {
+	unObj := obj.(*unstructured.Unstructured)
+	isvc := &inferenceService{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unObj.Object, isvc); err != nil {
+		log.Errorf("convert unstructured object [%+v] to %s job failed. error: %s", obj, kj.GVK.String(), err)
+		return api.StatusInfo{}, err
+	}
+	originStatus, state, msg := getInferenceServiceStatus(&isvc.Status)
+	log.Infof("kserve serving job status: %s", state)
+	return api.StatusInfo{
+		OriginStatus: originStatus,
+		Status:       state,
+		Message:      msg,
+	}, nil
+}
+
+func getInferenceServiceStatus(status *inferenceServiceStatus) (string, pfschema.JobStatus, string) {
+	for _, cond := range status.Conditions {
+		if cond.Type != kserveConditionReady {
+			continue
+		}
+		if cond.Status == corev1.ConditionTrue {
+			return kserveConditionReady, pfschema.StatusJobRunning,
+				fmt.Sprintf("model serving is ready, url: %s", status.URL)
+		}
+		return "NotReady", pfschema.StatusJobPending,
+			fmt.Sprintf("model serving is not ready, reason: %s, message: %s", cond.Reason, cond.Message)
+	}
+	return "Unknown", pfschema.StatusJobPending, "model serving is not ready"
+}
+
+func toUnstructured(isvc *inferenceService) (*unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(isvc)
+	if err != nil {
+		return nil, err
+	}
+	obj := &unstructured.Unstructured{Object: content}
+	// status is managed by KServe
+	unstructured.RemoveNestedField(obj.Object, "status")
+	obj.SetGroupVersionKind(KServeJobGVK)
+	return obj, nil
+}
@@ -0,0 +1,532 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serving
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/k8s"
+	pfschema "github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/api"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/runtime_v2/client"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/runtime_v2/framework"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/runtime_v2/job/util/kuberuntime"
+)
+
+var (
+	JobGVK               = k8s.DeploymentGVK
+	KubeServingFwVersion = client.KubeFrameworkVersion(JobGVK)
+
+	serviceFwVersion = client.KubeFrameworkVersion(k8s.ServiceGVK)
+	hpaFwVersion     = client.KubeFrameworkVersion(k8s.HPAGVK)
+)
+
+const (
+	// RevisionLabel distinguishes the pods of stable and canary revision, and the service routes traffic to both of them
+	RevisionLabel  = "paddleflow-serving-revision"
+	RevisionStable = "stable"
+	RevisionCanary = "canary"
+
+	servingPortName = "serving"
+)
+
+// KubeServingJob runs model server with kubernetes Deployment, and exposes it through service.
+// During canary rollout, the latest revision runs in canary Deployment, and the traffic is split between
+// stable and canary revision by the ratio of their replicas, so canary traffic percent must be represented
+// exactly by the replicas. With autoscaling, each revision is scaled by its own autoscaler within its share of
+// the replicas range, and the ratio follows the percent at min and max replicas.
+type KubeServingJob struct {
+	kuberuntime.KubeBaseJob
+}
+
+func New(kubeClient framework.RuntimeClientInterface) framework.JobInterface {
+	return &KubeServingJob{
+		KubeBaseJob: kuberuntime.NewKubeBaseJob(JobGVK, KubeServingFwVersion, kubeClient),
+	}
+}
+
+func (sj *KubeServingJob) Submit(ctx context.Context, job *api.PFJob) error {
+	if job == nil {
+		return fmt.Errorf("job is nil")
+	}
+	jobName := job.NamespacedName()
+	deployment, task, err := sj.buildDeployment(job, RevisionStable)
+	if err != nil {
+		log.Errorf("build %s failed, err %v", sj.String(jobName), err)
+		return err
+	}
+	deployment.Annotations[pfschema.JobConnectURLAnnotation] = fmt.Sprintf("http://%s.%s.svc:%d/",
+		job.ID, job.Namespace, task.Port)
+
+	log.Debugf("begin to create %s, deployment: %v", sj.String(jobName), deployment)
+	if err = sj.RuntimeClient.Create(deployment, sj.FrameworkVersion); err != nil {
+		log.Errorf("create %s failed, err %v", sj.String(jobName), err)
+		return err
+	}
+	// expose model server through service, and scale it with horizontal pod autoscaler
+	ownerRef, err := kuberuntime.GetOwnerReference(sj.RuntimeClient, sj.GVK, job.Namespace, job.ID)
+	if err != nil {
+		log.Errorf("get owner reference of %s failed, err %v", sj.String(jobName), err)
+		return err
+	}
+	if err = sj.RuntimeClient.Create(newService(job, task.Port, ownerRef), serviceFwVersion); err != nil {
+		log.Errorf("create service for %s failed, err %v", sj.String(jobName), err)
+		return err
+	}
+	if conf := job.Conf.Serving; conf != nil && conf.Autoscaling != nil {
+		if err = sj.RuntimeClient.Create(newAutoscaler(job, job.ID, conf.Autoscaling, ownerRef), hpaFwVersion); err != nil {
+			log.Errorf("create autoscaler for %s failed, err %v", sj.String(jobName), err)
+			return err
+		}
+	}
+	return nil
+}
+
+// buildDeployment builds the Deployment of stable or canary revision with the latest member of serving job
+func (sj *KubeServingJob) buildDeployment(job *api.PFJob, revision string) (*appsv1.Deployment, *pfschema.Member, error) {
+	if len(job.Tasks) != 1 {
+		return nil, nil, fmt.Errorf("serving job %s must have only one member", job.ID)
+	}
+	deployment := &appsv1.Deployment{}
+	if err := kuberuntime.CreateKubeJobFromYaml(deployment, sj.GVK, job); err != nil {
+		return nil, nil, err
+	}
+	// set metadata field
+	kuberuntime.BuildJobMetadata(&deployment.ObjectMeta, job)
+	if revision == RevisionCanary {
+		deployment.Name = canaryName(job.ID)
+	}
+	deployment.Labels[RevisionLabel] = revision
+
+	task := newServingTask(job)
+	replicas := int32(servingReplicas(&task, job.Conf.Serving))
+	deployment.Spec.Replicas = &replicas
+	deployment.Spec.Selector = &metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			pfschema.JobIDLabel: job.ID,
+			RevisionLabel:       revision,
+		},
+	}
+	if err := buildServingPodTemplate(&deployment.Spec.Template, job, &task); err != nil {
+		return nil, nil, err
+	}
+	deployment.Spec.Template.Labels[RevisionLabel] = revision
+	// model server is long-running, and it must be restarted after exit
+	deployment.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyAlways
+	return deployment, &task, nil
+}
+
+// Update updates labels and annotations of serving job, and rolls out the latest member of serving job.
+// The latest revision replaces stable revision with rolling update, or runs in canary Deployment when
+// canary traffic percent is set.
+func (sj *KubeServingJob) Update(ctx context.Context, job *api.PFJob) error {
+	if job == nil {
+		return fmt.Errorf("job is nil")
+	}
+	jobName := job.NamespacedName()
+	log.Infof("begin to update %s", sj.String(jobName))
+	if err := sj.rollout(job); err != nil {
+		log.Errorf("update %s failed, err: %v", sj.String(jobName), err)
+		return err
+	}
+	return nil
+}
+
+func (sj *KubeServingJob) rollout(job *api.PFJob) error {
+	stable, err := sj.getDeployment(job.Namespace, job.ID)
+	if err != nil {
+		return err
+	}
+	latest, task, err := sj.buildDeployment(job, RevisionStable)
+	if err != nil {
+		return err
+	}
+	conf := job.Conf.Serving
+	total := servingReplicas(task, conf)
+	autoscaling := conf != nil && conf.Autoscaling != nil
+	ownerRef := *metav1.NewControllerRef(stable, sj.GVK)
+
+	updateObjectMeta(&stable.ObjectMeta, job.Labels, job.Annotations)
+	if conf.IsCanary() {
+		percent := *conf.CanaryTrafficPercent
+		stableReplicas, canaryReplicas, err := splitReplicas(total, percent)
+		if err != nil {
+			return err
+		}
+		if err = sj.syncCanary(job, canaryReplicas, autoscaling, ownerRef); err != nil {
+			return err
+		}
+		if !autoscaling {
+			stable.Spec.Replicas = &stableReplicas
+		}
+		if err = sj.RuntimeClient.Update(stable, sj.FrameworkVersion); err != nil {
+			return err
+		}
+		// both revisions are scaled by their own autoscaler, and the replicas range is split by canary traffic percent
+		stableScaling, canaryScaling, err := splitAutoscaling(conf.Autoscaling, percent)
+		if err != nil {
+			return err
+		}
+		if err = sj.syncAutoscaler(job, job.ID, stableScaling, ownerRef); err != nil {
+			return err
+		}
+		return sj.syncAutoscaler(job, canaryName(job.ID), canaryScaling, ownerRef)
+	}
+	// the latest revision is fully rolled out, and canary revision is not needed anymore
+	stable.Spec.Template = latest.Spec.Template
+	if !autoscaling {
+		stable.Spec.Replicas = latest.Spec.Replicas
+	}
+	if err = sj.deleteIfExist(job.Namespace, canaryName(job.ID), hpaFwVersion); err != nil {
+		return err
+	}
+	if err = sj.deleteIfExist(job.Namespace, canaryName(job.ID), sj.FrameworkVersion); err != nil {
+		return err
+	}
+	if err = sj.RuntimeClient.Update(stable, sj.FrameworkVersion); err != nil {
+		return err
+	}
+	var scaling *pfschema.ServingAutoscaling
+	if autoscaling {
+		scaling = conf.Autoscaling
+	}
+	return sj.syncAutoscaler(job, job.ID, scaling, ownerRef)
+}
+
+// syncCanary creates or updates the canary Deployment, which runs the latest revision of serving job.
+// The replicas of existing canary Deployment are left to its autoscaler when autoscaling is enabled.
+func (sj *KubeServingJob) syncCanary(job *api.PFJob, replicas int32, autoscaling bool, ownerRef metav1.OwnerReference) error {
+	canary, _, err := sj.buildDeployment(job, RevisionCanary)
+	if err != nil {
+		return err
+	}
+	canary.Spec.Replicas = &replicas
+	canary.OwnerReferences = []metav1.OwnerReference{ownerRef}
+	current, err := sj.getDeployment(job.Namespace, canary.Name)
+	if k8serrors.IsNotFound(err) {
+		log.Infof("create canary revision of serving job %s with %d replicas", job.ID, replicas)
+		return sj.RuntimeClient.Create(canary, sj.FrameworkVersion)
+	} else if err != nil {
+		return err
+	}
+	current.Labels = canary.Labels
+	current.Annotations = canary.Annotations
+	if !autoscaling {
+		current.Spec.Replicas = canary.Spec.Replicas
+	}
+	current.Spec.Template = canary.Spec.Template
+	log.Infof("update canary revision of serving job %s with %d replicas", job.ID, replicas)
+	return sj.RuntimeClient.Update(current, sj.FrameworkVersion)
+}
+
+// syncAutoscaler creates, updates or deletes the horizontal pod autoscaler of the Deployment with the same name
+func (sj *KubeServingJob) syncAutoscaler(job *api.PFJob, name string, autoscaling *pfschema.ServingAutoscaling,
+	ownerRef metav1.OwnerReference) error {
+	if autoscaling == nil {
+		return sj.deleteIfExist(job.Namespace, name, hpaFwVersion)
+	}
+	hpa := newAutoscaler(job, name, autoscaling, ownerRef)
+	obj, err := sj.RuntimeClient.Get(job.Namespace, name, hpaFwVersion)
+	if k8serrors.IsNotFound(err) {
+		return sj.RuntimeClient.Create(hpa, hpaFwVersion)
+	} else if err != nil {
+		return err
+	}
+	current := &autoscalingv1.HorizontalPodAutoscaler{}
+	if err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.(*unstructured.Unstructured).Object, current); err != nil {
+		return err
+	}
+	current.Spec = hpa.Spec
+	return sj.RuntimeClient.Update(current, hpaFwVersion)
+}
+
+func (sj *KubeServingJob) getDeployment(namespace, name string) (*appsv1.Deployment, error) {
+	obj, err := sj.RuntimeClient.Get(namespace, name, sj.FrameworkVersion)
+	if err != nil {
+		return nil, err
+	}
+	unObj, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("the type of deployment %s/%s is %T, unstructured is expected", namespace, name, obj)
+	}
+	deployment := &appsv1.Deployment{}
+	if err = runtime.DefaultUnstructuredConverter.FromUnstructured(unObj.Object, deployment); err != nil {
+		return nil, err
+	}
+	return deployment, nil
+}
+
+func (sj *KubeServingJob) deleteIfExist(namespace, name string, fv pfschema.FrameworkVersion) error {
+	if _, err := sj.RuntimeClient.Get(namespace, name, fv); k8serrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return sj.RuntimeClient.Delete(namespace, name, fv)
+}
+
+func (sj *KubeServingJob) AddEventListener(ctx context.Context, listenerType string, jobQueue workqueue.RateLimitingInterface, listener interface{}) error {
+	var err error
+	switch listenerType {
+	case pfschema.ListenerTypeJob:
+		err = sj.AddJobEventListener(ctx, jobQueue, listener, sj.JobStatus, responsibleForServing)
+	default:
+		err = fmt.Errorf("listenerType %s is not supported", listenerType)
+	}
+	return err
+}
+
+// JobStatus get the statusInfo of serving job, including origin status, pf status and message
+func (sj *KubeServingJob) JobStatus(obj interface{}) (api.StatusInfo, error) {
+	unObj := obj.(*unstructured.Unstructured)
+	// convert to Deployment struct
+	deployment := &appsv1.Deployment{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unObj.Object, deployment); err != nil {
+		log.Errorf("convert unstructured object [%+v] to %s job failed. error: %s", obj, sj.GVK.String(), err)
+		return api.StatusInfo{}, err
+	}
+	originStatus, state, msg := getDeploymentStatus(deployment)
+	log.Infof("serving job status: %s", state)
+	return api.StatusInfo{
+		OriginStatus: originStatus,
+		Status:       state,
+		Message:      msg,
+	}, nil
+}
+
+// getDeploymentStatus reports the readiness of model server, serving job is running once any replica is available
+func getDeploymentStatus(deployment *appsv1.Deployment) (string, pfschema.JobStatus, string) {
+	var desired int32 = kuberuntime.DefaultReplicas
+	if deployment.Spec.Replicas != nil {
+		desired = *deployment.Spec.Replicas
+	}
+	available := deployment.Status.AvailableReplicas
+	var progressMsg string
+	for _, cond := range deployment.Status.Conditions {
+		if cond.Type == appsv1.DeploymentProgressing && cond.Status == corev1.ConditionFalse {
+			progressMsg = fmt.Sprintf(", rollout is stuck, reason: %s, message: %s", cond.Reason, cond.Message)
+		}
+	}
+	if available > 0 {
+		return string(appsv1.DeploymentAvailable), pfschema.StatusJobRunning,
+			fmt.Sprintf("model serving is ready, %d/%d replicas are available%s", available, desired, progressMsg)
+	}
+	return string(appsv1.DeploymentProgressing), pfschema.StatusJobPending,
+		fmt.Sprintf("model serving is not ready, 0/%d replicas are available%s", desired, progressMsg)
+}
+
+// responsibleForServing filters the stable Deployment of serving job, and canary Deployment is skipped
+func responsibleForServing(obj interface{}) bool {
+	if !kuberuntime.ResponsibleForJob(obj) {
+		return false
+	}
+	deployment := obj.(*unstructured.Unstructured)
+	return deployment.GetName() == deployment.GetLabels()[pfschema.JobIDLabel]
+}
+
+func canaryName(jobID string) string {
+	return fmt.Sprintf("%s-%s", jobID, RevisionCanary)
+}
+
+// newServingTask returns the member of serving job with default name and port
+func newServingTask(job *api.PFJob) pfschema.Member {
+	task := job.Tasks[0]
+	if task.Name == "" {
+		task.Name = job.ID
+	}
+	if task.Port == 0 {
+		task.Port = pfschema.DefaultServingPort
+	}
+	return task
+}
+
+// servingReplicas returns the desired replicas of model server, min replicas is used when autoscaling is enabled
+func servingReplicas(task *pfschema.Member, conf *pfschema.ServingConf) int {
+	if conf != nil && conf.Autoscaling != nil {
+		return conf.Autoscaling.MinReplicas
+	}
+	if task.Replicas < 1 {
+		return kuberuntime.DefaultReplicas
+	}
+	return task.Replicas
+}
+
+// splitReplicas splits replicas between stable and canary revision by canary traffic percent, since the service
+// routes traffic by the ratio of their replicas, the percent must be represented exactly by the replicas
+func splitReplicas(total, canaryPercent int) (int32, int32, error) {
+	stable, canary, err := pfschema.SplitServingReplicas(total, canaryPercent)
+	return int32(stable), int32(canary), err
+}
+
+// splitAutoscaling splits the replicas range of autoscaling between stable and canary revision, so that the ratio of
+// their replicas keeps canary traffic percent at both min and max replicas. nil is returned when autoscaling is disabled
+func splitAutoscaling(autoscaling *pfschema.ServingAutoscaling, canaryPercent int) (*pfschema.ServingAutoscaling,
+	*pfschema.ServingAutoscaling, error) {
+	if autoscaling == nil {
+		return nil, nil, nil
+	}
+	stableMin, canaryMin, err := pfschema.SplitServingReplicas(autoscaling.MinReplicas, canaryPercent)
+	if err != nil {
+		return nil, nil, err
+	}
+	stableMax, canaryMax, err := pfschema.SplitServingReplicas(autoscaling.MaxReplicas, canaryPercent)
+	if err != nil {
+		return nil, nil, err
+	}
+	stable, canary := *autoscaling, *autoscaling
+	stable.MinReplicas, stable.MaxReplicas = stableMin, stableMax
+	canary.MinReplicas, canary.MaxReplicas = canaryMin, canaryMax
+	return &stable, &canary, nil
+}
+
+// buildServingPodTemplate builds pod template of model server, and the readiness of model server is probed
+func buildServingPodTemplate(template *corev1.PodTemplateSpec, job *api.PFJob, task *pfschema.Member) error {
+	if err := kuberuntime.BuildPodTemplateSpec(template, job.ID, task); err != nil {
+		return err
+	}
+	// set scheduling policy
+	if template.Annotations == nil {
+		template.Annotations = make(map[string]string)
+	}
+	if len(job.QueueName) > 0 {
+		template.Annotations[pfschema.QueueLabelKey] = job.QueueName
+	}
+	template.Spec.PriorityClassName = kuberuntime.KubePriorityClass(job.PriorityClassName)
+	container := &template.Spec.Containers[0]
+	// keep env in order, otherwise the pod template is changed and model server is rolled out on every update
+	sort.Slice(container.Env, func(i, j int) bool {
+		return container.Env[i].Name < container.Env[j].Name
+	})
+	container.Ports = append(container.Ports, corev1.ContainerPort{
+		Name:          servingPortName,
+		ContainerPort: int32(task.Port),
+		Protocol:      corev1.ProtocolTCP,
+	})
+	container.ReadinessProbe = newReadinessProbe(job.Conf.Serving, task.Port)
+	return nil
+}
+
+func newReadinessProbe(conf *pfschema.ServingConf, port int) *corev1.Probe {
+	probe := &corev1.Probe{
+		InitialDelaySeconds: 5,
+		PeriodSeconds:       10,
+	}
+	if conf != nil && conf.ReadinessPath != "" {
+		probe.HTTPGet = &corev1.HTTPGetAction{
+			Path: conf.ReadinessPath,
+			Port: intstr.FromInt(port),
+		}
+	} else {
+		probe.TCPSocket = &corev1.TCPSocketAction{
+			Port: intstr.FromInt(port),
+		}
+	}
+	return probe
+}
+
+// updateObjectMeta sets labels and annotations to be updated, and the ones with empty value are removed
+func updateObjectMeta(metadata *metav1.ObjectMeta, labels, annotations map[string]string) {
+	metadata.Labels = updateMap(metadata.Labels, labels)
+	metadata.Annotations = updateMap(metadata.Annotations, annotations)
+}
+
+func updateMap(current, update map[string]string) map[string]string {
+	if len(update) == 0 {
+		return current
+	}
+	if current == nil {
+		current = make(map[string]string)
+	}
+	for key, value := range update {
+		if value == "" {
+			delete(current, key)
+		} else {
+			current[key] = value
+		}
+	}
+	return current
+}
+
+func newObjectMeta(job *api.PFJob, ownerRef metav1.OwnerReference) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      job.ID,
+		Namespace: job.Namespace,
+		Labels: map[string]string{
+			pfschema.JobOwnerLabel: pfschema.JobOwnerValue,
+			pfschema.JobIDLabel:    job.ID,
+		},
+		OwnerReferences: []metav1.OwnerReference{ownerRef},
+	}
+}
+
+// newService routes traffic to the pods of both stable and canary revision
+func newService(job *api.PFJob, port int, ownerRef metav1.OwnerReference) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: newObjectMeta(job, ownerRef),
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{
+				pfschema.JobIDLabel: job.ID,
+			},
+			Ports: []corev1.ServicePort{
+				{
+					Name:       servingPortName,
+					Port:       int32(port),
+					TargetPort: intstr.FromInt(port),
+					Protocol:   corev1.ProtocolTCP,
+				},
+			},
+		},
+	}
+}
+
+// newAutoscaler scales the Deployment of stable or canary revision with the same name
+func newAutoscaler(job *api.PFJob, name string, autoscaling *pfschema.ServingAutoscaling,
+	ownerRef metav1.OwnerReference) *autoscalingv1.HorizontalPodAutoscaler {
+	metadata := newObjectMeta(job, ownerRef)
+	metadata.Name = name
+	minReplicas := int32(autoscaling.MinReplicas)
+	targetCPU := int32(autoscaling.TargetCPUUtilization)
+	if targetCPU == 0 {
+		targetCPU = pfschema.DefaultTargetCPUUtilization
+	}
+	return &autoscalingv1.HorizontalPodAutoscaler{
+		ObjectMeta: metadata,
+		Spec: autoscalingv1.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv1.CrossVersionObjectReference{
+				APIVersion: JobGVK.GroupVersion().String(),
+				Kind:       JobGVK.Kind,
+				Name:       name,
+			},
+			MinReplicas:                    &minReplicas,
+			MaxReplicas:                    int32(autoscaling.MaxReplicas),
+			TargetCPUUtilizationPercentage: &targetCPU,
+		},
+	}
+}
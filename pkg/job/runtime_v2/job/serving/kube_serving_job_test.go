@@ -0,0 +1,277 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serving
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/config"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/k8s"
+	pfschema "github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/api"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/runtime_v2/client"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage/driver"
+)
+
+func newMockServingJob(id string, framework pfschema.Framework, conf *pfschema.ServingConf) *api.PFJob {
+	return &api.PFJob{
+		ID:        id,
+		Name:      id,
+		Namespace: "default",
+		JobType:   pfschema.TypeServing,
+		Framework: framework,
+		UserName:  "root",
+		QueueID:   "mockQueueID",
+		QueueName: "mockQueueName",
+		Conf: pfschema.Conf{
+			Serving: conf,
+		},
+		Tasks: []pfschema.Member{
+			{
+				Replicas: 4,
+				Role:     pfschema.RoleWorker,
+				Conf: pfschema.Conf{
+					Image: "model-server:v1",
+					Env: map[string]string{
+						"PF_JOB_QUEUE_NAME": "mockQueueName",
+					},
+					Flavour: pfschema.Flavour{ResourceInfo: pfschema.ResourceInfo{CPU: "1", Mem: "2Gi"}},
+				},
+			},
+		},
+	}
+}
+
+func initServingConfig() {
+	config.GlobalServerConfig = &config.ServerConfig{}
+	config.GlobalServerConfig.Job.SchedulerName = "testSchedulerName"
+	config.InitJobTemplate("../../../../../config/server/default/job/job_template.yaml")
+}
+
+func getObject(t *testing.T, kubeClient *client.KubeRuntimeClient, name string, fv pfschema.FrameworkVersion, out interface{}) {
+	obj, err := kubeClient.Get("default", name, fv)
+	assert.NoError(t, err)
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.(*unstructured.Unstructured).Object, out)
+	assert.NoError(t, err)
+}
+
+func TestServingJob_SubmitAndRollout(t *testing.T) {
+	initServingConfig()
+	var server = httptest.NewServer(k8s.DiscoveryHandlerFunc)
+	defer server.Close()
+	kubeRuntimeClient := client.NewFakeKubeRuntimeClient(server)
+	driver.InitMockDB()
+
+	servingJob := New(kubeRuntimeClient)
+	job := newMockServingJob("serving-test", pfschema.FrameworkStandalone, &pfschema.ServingConf{
+		ReadinessPath: "/health",
+	})
+	err := servingJob.Submit(context.TODO(), job)
+	assert.NoError(t, err)
+
+	deployment := &appsv1.Deployment{}
+	getObject(t, kubeRuntimeClient, job.ID, KubeServingFwVersion, deployment)
+	assert.Equal(t, int32(4), *deployment.Spec.Replicas)
+	assert.Equal(t, "http://serving-test.default.svc:8080/", deployment.Annotations[pfschema.JobConnectURLAnnotation])
+	assert.Equal(t, RevisionStable, deployment.Spec.Template.Labels[RevisionLabel])
+	podSpec := deployment.Spec.Template.Spec
+	assert.Equal(t, corev1.RestartPolicyAlways, podSpec.RestartPolicy)
+	container := podSpec.Containers[0]
+	assert.Equal(t, int32(pfschema.DefaultServingPort), container.Ports[0].ContainerPort)
+	assert.Equal(t, "/health", container.ReadinessProbe.HTTPGet.Path)
+
+	service := &corev1.Service{}
+	getObject(t, kubeRuntimeClient, job.ID, serviceFwVersion, service)
+	assert.Equal(t, map[string]string{pfschema.JobIDLabel: job.ID}, service.Spec.Selector)
+	assert.Equal(t, "Deployment", service.OwnerReferences[0].Kind)
+	_, err = kubeRuntimeClient.Get("default", job.ID, hpaFwVersion)
+	assert.Error(t, err)
+
+	// roll out new image with 25 percent canary traffic
+	percent := 25
+	job.Tasks[0].Image = "model-server:v2"
+	job.Conf.Serving.CanaryTrafficPercent = &percent
+	err = servingJob.Update(context.TODO(), job)
+	assert.NoError(t, err)
+	canary := &appsv1.Deployment{}
+	getObject(t, kubeRuntimeClient, canaryName(job.ID), KubeServingFwVersion, canary)
+	assert.Equal(t, int32(1), *canary.Spec.Replicas)
+	assert.Equal(t, RevisionCanary, canary.Spec.Template.Labels[RevisionLabel])
+	assert.Equal(t, "model-server:v2", canary.Spec.Template.Spec.Containers[0].Image)
+	deployment = &appsv1.Deployment{}
+	getObject(t, kubeRuntimeClient, job.ID, KubeServingFwVersion, deployment)
+	assert.Equal(t, int32(3), *deployment.Spec.Replicas)
+	assert.Equal(t, "model-server:v1", deployment.Spec.Template.Spec.Containers[0].Image)
+
+	// the percent cannot be represented by the replicas
+	invalidPercent := 30
+	job.Conf.Serving.CanaryTrafficPercent = &invalidPercent
+	err = servingJob.Update(context.TODO(), job)
+	assert.Error(t, err)
+
+	// canary revision is scaled with its share of the replicas range
+	job.Conf.Serving.CanaryTrafficPercent = &percent
+	job.Conf.Serving.Autoscaling = &pfschema.ServingAutoscaling{MinReplicas: 4, MaxReplicas: 8}
+	err = servingJob.Update(context.TODO(), job)
+	assert.NoError(t, err)
+	canaryHPA := &autoscalingv1.HorizontalPodAutoscaler{}
+	getObject(t, kubeRuntimeClient, canaryName(job.ID), hpaFwVersion, canaryHPA)
+	assert.Equal(t, canaryName(job.ID), canaryHPA.Spec.ScaleTargetRef.Name)
+	assert.Equal(t, int32(1), *canaryHPA.Spec.MinReplicas)
+	assert.Equal(t, int32(2), canaryHPA.Spec.MaxReplicas)
+	stableHPA := &autoscalingv1.HorizontalPodAutoscaler{}
+	getObject(t, kubeRuntimeClient, job.ID, hpaFwVersion, stableHPA)
+	assert.Equal(t, int32(3), *stableHPA.Spec.MinReplicas)
+	assert.Equal(t, int32(6), stableHPA.Spec.MaxReplicas)
+
+	// promote the latest revision and enable autoscaling
+	job.Conf.Serving.CanaryTrafficPercent = nil
+	job.Conf.Serving.Autoscaling = &pfschema.ServingAutoscaling{MinReplicas: 2, MaxReplicas: 6}
+	err = servingJob.Update(context.TODO(), job)
+	assert.NoError(t, err)
+	_, err = kubeRuntimeClient.Get("default", canaryName(job.ID), KubeServingFwVersion)
+	assert.Error(t, err)
+	_, err = kubeRuntimeClient.Get("default", canaryName(job.ID), hpaFwVersion)
+	assert.Error(t, err)
+	deployment = &appsv1.Deployment{}
+	getObject(t, kubeRuntimeClient, job.ID, KubeServingFwVersion, deployment)
+	assert.Equal(t, "model-server:v2", deployment.Spec.Template.Spec.Containers[0].Image)
+	hpa := &autoscalingv1.HorizontalPodAutoscaler{}
+	getObject(t, kubeRuntimeClient, job.ID, hpaFwVersion, hpa)
+	assert.Equal(t, int32(2), *hpa.Spec.MinReplicas)
+	assert.Equal(t, int32(6), hpa.Spec.MaxReplicas)
+	assert.Equal(t, int32(pfschema.DefaultTargetCPUUtilization), *hpa.Spec.TargetCPUUtilizationPercentage)
+
+	// invalid serving job
+	err = servingJob.Submit(context.TODO(), nil)
+	assert.Error(t, err)
+	invalidJob := newMockServingJob("serving-invalid", pfschema.FrameworkStandalone, nil)
+	invalidJob.Tasks = append(invalidJob.Tasks, invalidJob.Tasks[0])
+	err = servingJob.Submit(context.TODO(), invalidJob)
+	assert.Error(t, err)
+}
+
+func TestSplitReplicas(t *testing.T) {
+	testCases := []struct {
+		total, percent         int
+		wantStable, wantCanary int32
+		wantErr                bool
+	}{
+		{total: 4, percent: 0, wantStable: 4, wantCanary: 0},
+		{total: 4, percent: 25, wantStable: 3, wantCanary: 1},
+		{total: 10, percent: 10, wantStable: 9, wantCanary: 1},
+		{total: 4, percent: 30, wantErr: true},
+		{total: 1, percent: 50, wantErr: true},
+	}
+	for _, tc := range testCases {
+		stable, canary, err := splitReplicas(tc.total, tc.percent)
+		if tc.wantErr {
+			assert.Error(t, err)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, tc.wantStable, stable)
+		assert.Equal(t, tc.wantCanary, canary)
+	}
+}
+
+func TestServingJob_JobStatus(t *testing.T) {
+	testCases := []struct {
+		name       string
+		status     appsv1.DeploymentStatus
+		wantStatus pfschema.JobStatus
+	}{
+		{
+			name:       "pending",
+			status:     appsv1.DeploymentStatus{Replicas: 2},
+			wantStatus: pfschema.StatusJobPending,
+		},
+		{
+			name:       "running",
+			status:     appsv1.DeploymentStatus{Replicas: 2, AvailableReplicas: 1},
+			wantStatus: pfschema.StatusJobRunning,
+		},
+	}
+
+	servingJob := &KubeServingJob{}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			deployment := &appsv1.Deployment{Status: tc.status}
+			content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(deployment)
+			assert.NoError(t, err)
+			statusInfo, err := servingJob.JobStatus(&unstructured.Unstructured{Object: content})
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantStatus, statusInfo.Status)
+		})
+	}
+}
+
+func TestKServeJob_SubmitAndUpdate(t *testing.T) {
+	initServingConfig()
+	var server = httptest.NewServer(k8s.DiscoveryHandlerFunc)
+	defer server.Close()
+	kubeRuntimeClient := client.NewFakeKubeRuntimeClient(server)
+	driver.InitMockDB()
+
+	kserveJob := NewKServeJob(kubeRuntimeClient)
+	job := newMockServingJob("kserve-test", pfschema.FrameworkKServe, &pfschema.ServingConf{
+		Autoscaling: &pfschema.ServingAutoscaling{MinReplicas: 1, MaxReplicas: 3, TargetCPUUtilization: 60},
+	})
+	err := kserveJob.Submit(context.TODO(), job)
+	assert.NoError(t, err)
+
+	isvc := &inferenceService{}
+	getObject(t, kubeRuntimeClient, job.ID, KubeKServeFwVersion, isvc)
+	predictor := isvc.Spec.Predictor
+	assert.Equal(t, 1, *predictor.MinReplicas)
+	assert.Equal(t, 3, predictor.MaxReplicas)
+	assert.Equal(t, 60, *predictor.ScaleTarget)
+	assert.Nil(t, predictor.CanaryTrafficPercent)
+	assert.Equal(t, corev1.RestartPolicy(""), predictor.RestartPolicy)
+	assert.Equal(t, "model-server:v1", predictor.Containers[0].Image)
+	assert.Equal(t, "http://kserve-test.default.svc.cluster.local/", isvc.Annotations[pfschema.JobConnectURLAnnotation])
+
+	percent := 20
+	job.Tasks[0].Image = "model-server:v2"
+	job.Conf.Serving.CanaryTrafficPercent = &percent
+	err = kserveJob.Update(context.TODO(), job)
+	assert.NoError(t, err)
+	isvc = &inferenceService{}
+	getObject(t, kubeRuntimeClient, job.ID, KubeKServeFwVersion, isvc)
+	assert.Equal(t, 20, *isvc.Spec.Predictor.CanaryTrafficPercent)
+	assert.Equal(t, "model-server:v2", isvc.Spec.Predictor.Containers[0].Image)
+
+	// status of inference service
+	isvc.Status = inferenceServiceStatus{
+		URL:        "http://kserve-test.default.example.com",
+		Conditions: []inferenceServiceStatusCondition{{Type: kserveConditionReady, Status: corev1.ConditionTrue}},
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(isvc)
+	assert.NoError(t, err)
+	statusInfo, err := (&KubeKServeJob{}).JobStatus(&unstructured.Unstructured{Object: content})
+	assert.NoError(t, err)
+	assert.Equal(t, pfschema.StatusJobRunning, statusInfo.Status)
+}
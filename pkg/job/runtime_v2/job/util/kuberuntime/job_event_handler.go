@@ -79,6 +79,9 @@ func JobAddFunc(obj interface{}, getStatusFunc api.GetStatusFunc) (*api.JobSyncI
 	runtimeStatus := jobObj.Object[RuntimeStatusKey]
 	runtimeInfo := jobObj.DeepCopy().Object
 	delete(runtimeInfo, RuntimeStatusKey)
+	if connectURL := jobObj.GetAnnotations()[schema.JobConnectURLAnnotation]; connectURL != "" {
+		runtimeInfo[schema.JobRuntimeConnectURLKey] = connectURL
+	}
 	// get framework version
 	frameworkVersion := schema.NewFrameworkVersion(gvk.Kind, gvk.GroupVersion().String())
 	jobInfo := &api.JobSyncInfo{
@@ -182,15 +182,21 @@ func getDefaultTemplate(framework schema.Framework, jobType schema.JobType, jobM
 	jobTemplateName := ""
 
 	// the footer comment of all type job as the follow:
-	//  single -> single-job, workflow -> workflow-job,
+	//  single -> single-job, workflow -> workflow-job, notebook -> notebook-job
+	//  serving with standalone -> serving-job, serving with kserve -> kserve-serving-job
 	//  spark -> spark-job, ray -> ray-job
 	//  paddle with ps mode -> paddle-ps-job
 	//  paddle with collective mode -> paddle-collective-job
 	//  tensorflow with ps mode -> tensorflow-ps-job
 	//  pytorch with ps mode -> pytorch-ps-job
 	switch jobType {
-	case schema.TypeSingle, schema.TypeWorkflow:
+	case schema.TypeSingle, schema.TypeWorkflow, schema.TypeNotebook:
 		jobTemplateName = fmt.Sprintf("%s-job", jobType)
+	case schema.TypeServing:
+		jobTemplateName = fmt.Sprintf("%s-job", jobType)
+		if framework == schema.FrameworkKServe {
+			jobTemplateName = fmt.Sprintf("%s-%s-job", framework, jobType)
+		}
 	case schema.TypeDistributed:
 		if framework == schema.FrameworkSpark || framework == schema.FrameworkRay || framework == schema.FrameworkMPI {
 			jobTemplateName = fmt.Sprintf("%s-job", framework)
@@ -285,6 +291,30 @@ func BuildJobMetadata(metadata *metav1.ObjectMeta, job *api.PFJob) {
 	}
 }
 
+// GetOwnerReference returns the owner reference of kubernetes job, which is set to the resources created along with job,
+// such as service and ingress, so that they are deleted by garbage collector when job is deleted
+func GetOwnerReference(runtimeClient framework.RuntimeClientInterface, gvk kubeschema.GroupVersionKind,
+	namespace, name string) (metav1.OwnerReference, error) {
+	fv := schema.NewFrameworkVersion(gvk.Kind, gvk.GroupVersion().String())
+	obj, err := runtimeClient.Get(namespace, name, fv)
+	if err != nil {
+		return metav1.OwnerReference{}, err
+	}
+	unObj, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return metav1.OwnerReference{}, fmt.Errorf("the type of %s %s/%s is %T, unstructured is expected",
+			gvk.Kind, namespace, name, obj)
+	}
+	isController := true
+	return metav1.OwnerReference{
+		APIVersion: gvk.GroupVersion().String(),
+		Kind:       gvk.Kind,
+		Name:       unObj.GetName(),
+		UID:        unObj.GetUID(),
+		Controller: &isController,
+	}, nil
+}
+
 func BuildTaskMetadata(metadata *metav1.ObjectMeta, jobID string, taskConf *schema.Conf) {
 	if metadata == nil || taskConf == nil {
 		return
@@ -334,7 +364,7 @@ func BuildPodSpec(podSpec *corev1.PodSpec, task schema.Member) error {
 		for _, fs := range fileSystems {
 			fsIDs = append(fsIDs, fs.ID)
 		}
-		podSpec.Affinity, err = generateAffinity(podSpec.Affinity, fsIDs)
+		podSpec.Affinity, err = generateAffinity(podSpec.Affinity, fsIDs, task.Env[schema.EnvJobFsLocality])
 		if err != nil {
 			return err
 		}
@@ -387,7 +417,7 @@ func BuildPod(pod *corev1.Pod, task schema.Member) error {
 		for _, fs := range fileSystems {
 			fsIDs = append(fsIDs, fs.ID)
 		}
-		pod.Spec.Affinity, err = generateAffinity(pod.Spec.Affinity, fsIDs)
+		pod.Spec.Affinity, err = generateAffinity(pod.Spec.Affinity, fsIDs, task.Env[schema.EnvJobFsLocality])
 		if err != nil {
 			return err
 		}
@@ -426,8 +456,13 @@ func patchRestartPolicy(podSpec *corev1.PodSpec, task schema.Member) {
 	}
 }
 
-func generateAffinity(affinity *corev1.Affinity, fsIDs []string) (*corev1.Affinity, error) {
-	nodeAffinity, err := locationAwareness.FsNodeAffinity(fsIDs)
+func generateAffinity(affinity *corev1.Affinity, fsIDs []string, localityMode string) (*corev1.Affinity, error) {
+	mode, err := locationAwareness.ParseLocalityMode(localityMode)
+	if err != nil {
+		log.Errorf("KubeJob generateAffinity err: %v", err)
+		return nil, err
+	}
+	nodeAffinity, err := locationAwareness.FsNodeAffinity(fsIDs, mode)
 	if err != nil {
 		err = fmt.Errorf("KubeJob generateAffinity err: %v", err)
 		log.Errorf(err.Error())
@@ -1052,5 +1087,63 @@ func UpdateKubeJob(job *api.PFJob, runtimeClient framework.RuntimeClientInterfac
 			return err
 		}
 	}
+	// 3. scale replicas of job members
+	if len(job.Replicas) != 0 {
+		log.Infof("begin to scale %s, replicas: %v", jobmsg, job.Replicas)
+		if err := scaleKubeJob(job, runtimeClient, fv); err != nil {
+			log.Errorf("scale %s failed, err: %v", jobmsg, err)
+			return err
+		}
+	}
 	return nil
 }
+
+// scaleKubeJob updates replicas of job members on cluster, only PaddleJob and PyTorchJob are supported
+func scaleKubeJob(job *api.PFJob, runtimeClient framework.RuntimeClientInterface, fv schema.FrameworkVersion) error {
+	fieldPaths := make(map[schema.MemberRole][]string)
+	for role := range job.Replicas {
+		fieldPath, err := replicasFieldPath(fv, role)
+		if err != nil {
+			return err
+		}
+		fieldPaths[role] = fieldPath
+	}
+	obj, err := runtimeClient.Get(job.Namespace, job.ID, fv)
+	if err != nil {
+		return err
+	}
+	unObj, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("the type of %s job %s is %T, unstructured is expected", fv.String(), job.ID, obj)
+	}
+	for role, replicas := range job.Replicas {
+		if err = unstructured.SetNestedField(unObj.Object, int64(replicas), fieldPaths[role]...); err != nil {
+			return err
+		}
+	}
+	return runtimeClient.Update(unObj, fv)
+}
+
+// replicasFieldPath returns the field path of replicas for member role in kubernetes job
+func replicasFieldPath(fv schema.FrameworkVersion, role schema.MemberRole) ([]string, error) {
+	gvk := kubeschema.FromAPIVersionAndKind(fv.APIVersion, fv.Framework)
+	switch gvk {
+	case k8s.PaddleJobGVK:
+		switch role {
+		case schema.RolePServer:
+			return []string{"spec", "ps", "replicas"}, nil
+		case schema.RoleWorker, schema.RolePWorker:
+			return []string{"spec", "worker", "replicas"}, nil
+		}
+	case k8s.PyTorchJobGVK:
+		switch role {
+		case schema.RoleMaster:
+			return []string{"spec", "pytorchReplicaSpecs", "Master", "replicas"}, nil
+		case schema.RoleWorker, schema.RolePWorker:
+			return []string{"spec", "pytorchReplicaSpecs", "Worker", "replicas"}, nil
+		}
+	default:
+		return nil, fmt.Errorf("scaling replicas of %s is not supported", gvk.Kind)
+	}
+	return nil, fmt.Errorf("member role %s of %s cannot be scaled", role, gvk.Kind)
+}
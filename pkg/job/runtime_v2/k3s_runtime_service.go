@@ -237,6 +237,24 @@ func (k3srs *K3SRuntimeService) SubmitJob(job *api.PFJob) error {
 	return nil
 }
 
+// RenderJob renders job by pod plugin with dry run client, node name of k3s is not set in dry run
+func (k3srs *K3SRuntimeService) RenderJob(job *api.PFJob) ([]interface{}, error) {
+	if job == nil {
+		return nil, fmt.Errorf("render job failed, job is nil")
+	}
+	fv := pfschema.NewFrameworkVersion(k8s.PodGVK.Kind, k8s.PodGVK.GroupVersion().String())
+	jobPlugin, found := framework.GetJobPlugin(pfschema.K3SType, fv)
+	if !found {
+		return nil, fmt.Errorf("get job plugin on %s failed, err: %s job is not implemented", k3srs.String(), fv.String())
+	}
+	dryRunClient := client.NewDryRunClient(k3srs.client)
+	if err := jobPlugin(dryRunClient).Submit(context.TODO(), job); err != nil {
+		log.Warnf("render k3s job[%s] failed, err: %v", job.Name, err)
+		return nil, err
+	}
+	return dryRunClient.Objects(), nil
+}
+
 func (k3srs *K3SRuntimeService) Job(fwVersion pfschema.FrameworkVersion) framework.JobInterface {
 	// default use pod gvk
 	gvk := k8s.PodGVK
@@ -28,6 +28,8 @@ import (
 
 	"github.com/jinzhu/copier"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
@@ -46,6 +48,7 @@ import (
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/k8s"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/resources"
 	pfschema "github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/tracing"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/utils"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/job/api"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/job/runtime_v2/client"
@@ -53,6 +56,7 @@ import (
 	"github.com/PaddlePaddle/PaddleFlow/pkg/job/runtime_v2/framework"
 	_ "github.com/PaddlePaddle/PaddleFlow/pkg/job/runtime_v2/job"
 	_ "github.com/PaddlePaddle/PaddleFlow/pkg/job/runtime_v2/queue"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/monitor"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/trace_logger"
 )
@@ -125,10 +129,12 @@ func (kr *KubeRuntime) Init() error {
 	return nil
 }
 
-func (kr *KubeRuntime) SubmitJob(job *api.PFJob) error {
+func (kr *KubeRuntime) SubmitJob(job *api.PFJob) (err error) {
 	if job == nil {
 		return fmt.Errorf("submit job failed, job is nil")
 	}
+	ctx, span := kr.startJobSpan(job, "SubmitJob")
+	defer func() { tracing.End(span, err) }()
 	// add trace log point
 	jobID := job.ID
 	traceLogger := trace_logger.KeyWithUpdate(jobID)
@@ -146,7 +152,7 @@ func (kr *KubeRuntime) SubmitJob(job *api.PFJob) error {
 			log.Infof("skip create pv/pvc, fs type is local")
 			continue
 		}
-		fsID := common.ID(job.UserName, fs.Name)
+		fsID := common.FsOrSnapshotID(job.UserName, fs.Name)
 		pvName, err := kr.CreatePV(job.Namespace, fsID)
 		if err != nil {
 			log.Errorf("create pv for job[%s] failed, err: %v", job.ID, err)
@@ -164,7 +170,7 @@ func (kr *KubeRuntime) SubmitJob(job *api.PFJob) error {
 	// submit job
 	traceLogger.Infof("submit kubernetes job")
 	fwVersion := kr.Client().JobFrameworkVersion(job.JobType, job.Framework)
-	err := kr.Job(fwVersion).Submit(context.TODO(), job)
+	err = kr.Job(fwVersion).Submit(ctx, job)
 	if err != nil {
 		log.Warnf("create kubernetes job[%s] failed, err: %v", job.Name, err)
 		return err
@@ -174,28 +180,61 @@ func (kr *KubeRuntime) SubmitJob(job *api.PFJob) error {
 	return nil
 }
 
-func (kr *KubeRuntime) StopJob(job *api.PFJob) error {
+// RenderJob renders job by job plugin with dry run client, and the pv/pvc of filesystems are not created
+func (kr *KubeRuntime) RenderJob(job *api.PFJob) ([]interface{}, error) {
+	if job == nil {
+		return nil, fmt.Errorf("render job failed, job is nil")
+	}
+	fwVersion := kr.Client().JobFrameworkVersion(job.JobType, job.Framework)
+	jobPlugin, found := framework.GetJobPlugin(kr.cluster.Type, fwVersion)
+	if !found {
+		return nil, fmt.Errorf("get job plugin on %s failed, err: %s job is not implemented", kr.String(), fwVersion.String())
+	}
+	dryRunClient := client.NewDryRunClient(kr.kubeClient)
+	if err := jobPlugin(dryRunClient).Submit(context.TODO(), job); err != nil {
+		log.Warnf("render kubernetes job[%s] failed, err: %v", job.Name, err)
+		return nil, err
+	}
+	return dryRunClient.Objects(), nil
+}
+
+func (kr *KubeRuntime) StopJob(job *api.PFJob) (err error) {
 	if job == nil {
 		return fmt.Errorf("stop job failed, job is nil")
 	}
+	ctx, span := kr.startJobSpan(job, "StopJob")
+	defer func() { tracing.End(span, err) }()
 	fwVersion := kr.Client().JobFrameworkVersion(job.JobType, job.Framework)
-	return kr.Job(fwVersion).Stop(context.TODO(), job)
+	return kr.Job(fwVersion).Stop(ctx, job)
 }
 
-func (kr *KubeRuntime) UpdateJob(job *api.PFJob) error {
+func (kr *KubeRuntime) UpdateJob(job *api.PFJob) (err error) {
 	if job == nil {
 		return fmt.Errorf("update job failed, job is nil")
 	}
+	ctx, span := kr.startJobSpan(job, "UpdateJob")
+	defer func() { tracing.End(span, err) }()
 	fwVersion := kr.Client().JobFrameworkVersion(job.JobType, job.Framework)
-	return kr.Job(fwVersion).Update(context.TODO(), job)
+	return kr.Job(fwVersion).Update(ctx, job)
 }
 
-func (kr *KubeRuntime) DeleteJob(job *api.PFJob) error {
+func (kr *KubeRuntime) DeleteJob(job *api.PFJob) (err error) {
 	if job == nil {
 		return fmt.Errorf("delete job failed, job is nil")
 	}
+	ctx, span := kr.startJobSpan(job, "DeleteJob")
+	defer func() { tracing.End(span, err) }()
 	fwVersion := kr.Client().JobFrameworkVersion(job.JobType, job.Framework)
-	return kr.Job(fwVersion).Delete(context.TODO(), job)
+	return kr.Job(fwVersion).Delete(ctx, job)
+}
+
+// startJobSpan starts a runtime span of job, the parent of span is the trace context in job env
+func (kr *KubeRuntime) startJobSpan(job *api.PFJob, operation string) (context.Context, trace.Span) {
+	ctx := tracing.ContextFromEnv(context.Background(), job.Conf.GetEnv())
+	return tracing.Start(ctx, "KubeRuntime."+operation,
+		attribute.String("paddleflow.job_id", job.ID),
+		attribute.String("paddleflow.cluster_id", kr.cluster.ID),
+		attribute.String("paddleflow.framework", string(job.Framework)))
 }
 
 func (kr *KubeRuntime) Job(fwVersion pfschema.FrameworkVersion) framework.JobInterface {
@@ -281,6 +320,22 @@ func (kr *KubeRuntime) SyncController(stopCh <-chan struct{}) {
 		return
 	}
 	go nodeResourceController.Run(stopCh)
+
+	if monitor.MetricBackend() == monitor.MetricBackendMetricsServer {
+		if kubeClient, ok := kr.kubeClient.(*client.KubeRuntimeClient); ok {
+			monitor.StartMetricsServerMetric(kr.cluster.ID, kubeClient.Client, stopCh)
+		}
+	}
+
+	if config.GlobalServerConfig.Job.Preemption.Enable {
+		preemptionController := controller.NewJobPreemption(kr)
+		err = preemptionController.Initialize(kr.kubeClient)
+		if err != nil {
+			log.Errorf("init job preemption controller on %s failed, err: %v", kr.String(), err)
+			return
+		}
+		go preemptionController.Run(stopCh)
+	}
 }
 
 func (kr *KubeRuntime) Client() framework.RuntimeClientInterface {
@@ -417,8 +472,8 @@ func (kr *KubeRuntime) CreatePV(namespace, fsID string) (string, error) {
 }
 
 func (kr *KubeRuntime) buildPV(pv *corev1.PersistentVolume, fsID string) error {
-	// filesystem
-	fs, err := storage.Filesystem.GetFileSystemWithFsID(fsID)
+	// filesystem, or the read-only file system of snapshot
+	fs, err := storage.Filesystem.GetFileSystemOrSnapshot(fsID)
 	if err != nil {
 		retErr := fmt.Errorf("create PV get fs[%s] err: %v", fsID, err)
 		log.Errorf(retErr.Error())
@@ -430,8 +485,9 @@ func (kr *KubeRuntime) buildPV(pv *corev1.PersistentVolume, fsID string) error {
 		log.Errorf(retErr.Error())
 		return retErr
 	}
-	// fs_cache_config
-	fsCacheConfig, err := storage.Filesystem.GetFSCacheConfig(fsID)
+	// fs_cache_config, snapshots share the cache config of fs
+	baseFsID, _ := pfschema.ParseFsSnapshotID(fsID)
+	fsCacheConfig, err := storage.Filesystem.GetFSCacheConfig(baseFsID)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		retErr := fmt.Errorf("create PV get fsCacheConfig[%s] err: %v", fsID, err)
 		log.Errorf(retErr.Error())
@@ -501,7 +557,7 @@ func (kr *KubeRuntime) GetJobLog(jobLogRequest pfschema.JobLogRequest) (pfschema
 	}
 	labelSelector := metav1.LabelSelector{}
 	switch pfschema.JobType(jobLogRequest.JobType) {
-	case pfschema.TypeSingle, pfschema.TypeDistributed, pfschema.TypeWorkflow:
+	case pfschema.TypeSingle, pfschema.TypeDistributed, pfschema.TypeWorkflow, pfschema.TypeNotebook, pfschema.TypeServing:
 		labelSelector.MatchLabels = map[string]string{
 			pfschema.JobIDLabel: jobLogRequest.JobID,
 		}
@@ -163,6 +163,57 @@ func TestKubeRuntimeJob(t *testing.T) {
 	t.SkipNow()
 }
 
+func TestKubeRuntime_RenderJob(t *testing.T) {
+	var server = httptest.NewServer(k8s.DiscoveryHandlerFunc)
+	defer server.Close()
+
+	kubeClient := client.NewFakeKubeRuntimeClient(server)
+	kubeRuntime := &KubeRuntime{
+		cluster:    schema.Cluster{Name: "test-cluster", Type: schema.KubernetesType},
+		kubeClient: kubeClient,
+	}
+	pfJob := &api.PFJob{
+		ID:                "test-render-job",
+		Namespace:         "default",
+		JobType:           schema.TypeSingle,
+		Framework:         schema.FrameworkStandalone,
+		ExtensionTemplate: []byte(jobManifest),
+		Conf: schema.Conf{
+			Env: map[string]string{
+				schema.EnvJobQueueName: "default",
+			},
+		},
+		Tasks: []schema.Member{
+			{
+				Replicas: 1,
+				Conf: schema.Conf{
+					Name:    "normal",
+					Command: "sleep 200",
+					Image:   "busybox:v1",
+					Flavour: schema.Flavour{Name: "mockFlavourName", ResourceInfo: schema.ResourceInfo{CPU: "2", Mem: "2"}},
+				},
+			},
+		},
+	}
+	driver.InitMockDB()
+	config.GlobalServerConfig = &config.ServerConfig{}
+
+	objects, err := kubeRuntime.RenderJob(pfJob)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(objects))
+	pod, ok := objects[0].(map[string]interface{})
+	assert.True(t, ok)
+	assert.Equal(t, "Pod", pod["kind"])
+	assert.Equal(t, "v1", pod["apiVersion"])
+	assert.Equal(t, "test-render-job", pod["metadata"].(map[string]interface{})["name"])
+	// nothing is created on cluster
+	_, err = kubeClient.Get("default", "test-render-job", client.KubeFrameworkVersion(k8s.PodGVK))
+	assert.Error(t, err)
+
+	_, err = kubeRuntime.RenderJob(nil)
+	assert.Error(t, err)
+}
+
 func TestKubeRuntimePVAndPVC(t *testing.T) {
 	var server = httptest.NewServer(k8s.DiscoveryHandlerFunc)
 	defer server.Close()
@@ -0,0 +1,279 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime_v2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+
+	log "github.com/sirupsen/logrus"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/workqueue"
+
+	pfschema "github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/api"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/runtime_v2/client"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/runtime_v2/controller"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/runtime_v2/framework"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/trace_logger"
+)
+
+const defaultLocalNamespace = "default"
+
+// LocalRuntimeService runs single jobs as processes on the host of PaddleFlow server, so that pipelines and
+// job api can be used without kubernetes.
+type LocalRuntimeService struct {
+	cluster *pfschema.Cluster
+	client  *client.LocalRuntimeClient
+}
+
+// localJob is the job plugin of local runtime, which implements framework.JobInterface
+type localJob struct {
+	client *client.LocalRuntimeClient
+}
+
+func NewLocalRuntime(cluster pfschema.Cluster) RuntimeService {
+	cluster.Type = pfschema.LocalType
+	return &LocalRuntimeService{
+		cluster: &cluster,
+	}
+}
+
+func (lrs *LocalRuntimeService) String() string {
+	msg := "local runtime service"
+	if lrs.client != nil {
+		msg = lrs.client.Cluster()
+	}
+	return msg
+}
+
+func (lrs *LocalRuntimeService) Name() string {
+	return fmt.Sprintf("local process runtime: %s", lrs.cluster.Name)
+}
+
+func (lrs *LocalRuntimeService) Init() error {
+	rootDir := os.Getenv(pfschema.EnvLocalRuntimeDir)
+	// run jobs with image in containers when docker is available
+	useContainer := false
+	if os.Getenv(pfschema.EnvLocalRuntimeContainer) != "false" {
+		if _, err := exec.LookPath("docker"); err == nil {
+			useContainer = true
+		}
+	}
+	lrs.client = client.NewLocalRuntimeClient(lrs.cluster, rootDir, useContainer)
+	if err := os.MkdirAll(lrs.client.RootDir, 0755); err != nil {
+		log.Errorf("create root dir %s for %s failed, err: %v", lrs.client.RootDir, lrs.String(), err)
+		return err
+	}
+	log.Infof("init %s with root dir %s, use container: %v", lrs.String(), lrs.client.RootDir, useContainer)
+	return nil
+}
+
+func (lrs *LocalRuntimeService) Client() framework.RuntimeClientInterface {
+	return lrs.client
+}
+
+func (lrs *LocalRuntimeService) SyncController(stopCh <-chan struct{}) {
+	log.Infof("start job controller on %s", lrs.String())
+	jobQueueSync := os.Getenv(pfschema.EnvEnableJobQueueSync)
+	if jobQueueSync == "false" {
+		log.Warnf("skip job syn controller on %s", lrs.String())
+		return
+	}
+	jobController := controller.NewJobSync()
+	if err := jobController.Initialize(lrs.client); err != nil {
+		log.Errorf("init job controller on %s failed, err: %v", lrs.String(), err)
+		return
+	}
+	go jobController.Run(stopCh)
+}
+
+func (lrs *LocalRuntimeService) SubmitJob(job *api.PFJob) error {
+	if job == nil {
+		return fmt.Errorf("submit job failed, job is nil")
+	}
+	traceLogger := trace_logger.KeyWithUpdate(job.ID)
+	msg := fmt.Sprintf("submit job[%s] to cluster[%s] queue[%s]", job.ID, lrs.cluster.ID, job.QueueID)
+	log.Infof(msg)
+	traceLogger.Infof(msg)
+	if err := lrs.Job(client.LocalFrameworkVersion).Submit(context.TODO(), job); err != nil {
+		errMsg := fmt.Sprintf("create local job[%s] failed, err: %v", job.ID, err)
+		log.Warnf(errMsg)
+		traceLogger.Infof(errMsg)
+		return err
+	}
+	traceLogger.Infof("submit local job[%s] success", job.ID)
+	return nil
+}
+
+// RenderJob returns the local process which would be started for job
+func (lrs *LocalRuntimeService) RenderJob(job *api.PFJob) ([]interface{}, error) {
+	process, err := (&localJob{client: lrs.client}).buildProcess(job)
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{process}, nil
+}
+
+func (lrs *LocalRuntimeService) StopJob(job *api.PFJob) error {
+	if job == nil {
+		return fmt.Errorf("stop job failed, job is nil")
+	}
+	return lrs.Job(client.LocalFrameworkVersion).Stop(context.TODO(), job)
+}
+
+func (lrs *LocalRuntimeService) UpdateJob(job *api.PFJob) error {
+	if job == nil {
+		return fmt.Errorf("update job failed, job is nil")
+	}
+	return lrs.Job(client.LocalFrameworkVersion).Update(context.TODO(), job)
+}
+
+func (lrs *LocalRuntimeService) DeleteJob(job *api.PFJob) error {
+	if job == nil {
+		return fmt.Errorf("delete job failed, job is nil")
+	}
+	return lrs.Job(client.LocalFrameworkVersion).Delete(context.TODO(), job)
+}
+
+func (lrs *LocalRuntimeService) GetLog(jobLogRequest pfschema.JobLogRequest, mixedLogRequest pfschema.MixedLogRequest) (pfschema.JobLogInfo, error) {
+	if jobLogRequest.JobID == "" {
+		log.Errorf("must set job id, skip get log for job log request[%v]", jobLogRequest)
+		return pfschema.JobLogInfo{}, errors.New("job id is not set, mixed log is not supported on local runtime")
+	}
+	return lrs.Job(client.LocalFrameworkVersion).GetLog(context.TODO(), jobLogRequest)
+}
+
+// Job returns the local job plugin for all framework versions, because all jobs are run as local processes
+func (lrs *LocalRuntimeService) Job(fwVersion pfschema.FrameworkVersion) framework.JobInterface {
+	return &localJob{client: lrs.client}
+}
+
+func (lrs *LocalRuntimeService) Queue(quotaType pfschema.FrameworkVersion) framework.QueueInterface {
+	log.Infof("local runtime not support queue info, so skip it, queue info:%v", quotaType)
+	return nil
+}
+
+func (lrs *LocalRuntimeService) CreateQueue(queue *api.QueueInfo) error {
+	log.Infof("local runtime not support queue created, so skip it, queue info:%v", queue)
+	return nil
+}
+
+func (lrs *LocalRuntimeService) DeleteQueue(queue *api.QueueInfo) error {
+	log.Infof("local runtime not support queue deleted, so skip it, queue info:%v", queue)
+	return nil
+}
+
+func (lrs *LocalRuntimeService) UpdateQueue(queue *api.QueueInfo) error {
+	log.Infof("local runtime not support queue updated, so skip it, queue info:%v", queue)
+	return nil
+}
+
+func (lrs *LocalRuntimeService) ListNodeQuota() (pfschema.QuotaSummary, []pfschema.NodeQuotaInfo, error) {
+	return lrs.client.ListNodeQuota(context.TODO())
+}
+
+// Submit runs single job as local process, the command, image and env of job are taken from its task if exists
+func (lj *localJob) Submit(ctx context.Context, job *api.PFJob) error {
+	process, err := lj.buildProcess(job)
+	if err != nil {
+		return err
+	}
+	log.Infof("begin to submit job %s/%s on %s, run in container: %v", process.Namespace, job.ID, lj.client.Cluster(), process.Container)
+	return lj.client.Create(process, client.LocalFrameworkVersion)
+}
+
+func (lj *localJob) buildProcess(job *api.PFJob) (*client.LocalProcess, error) {
+	if job == nil {
+		return nil, fmt.Errorf("job is nil")
+	}
+	if job.JobType != pfschema.TypeSingle {
+		return nil, fmt.Errorf("job type %s is not supported on local runtime, only single job is supported", job.JobType)
+	}
+	conf := job.Conf
+	env := make(map[string]string)
+	for key, value := range job.Conf.GetEnv() {
+		env[key] = value
+	}
+	if len(job.Tasks) > 0 {
+		conf = job.Tasks[0].Conf
+		for key, value := range conf.GetEnv() {
+			env[key] = value
+		}
+	}
+	if conf.GetCommand() == "" {
+		return nil, fmt.Errorf("command of job %s is empty", job.ID)
+	}
+	return lj.client.NewLocalProcess(localNamespace(job), job.ID, conf.GetCommand(), conf.GetImage(), env), nil
+}
+
+func (lj *localJob) Stop(ctx context.Context, job *api.PFJob) error {
+	if job == nil {
+		return fmt.Errorf("job is nil")
+	}
+	log.Infof("begin to stop job %s/%s on %s", localNamespace(job), job.ID, lj.client.Cluster())
+	return lj.client.Delete(localNamespace(job), job.ID, client.LocalFrameworkVersion)
+}
+
+func (lj *localJob) Update(ctx context.Context, job *api.PFJob) error {
+	return fmt.Errorf("update job is not supported on local runtime")
+}
+
+func (lj *localJob) Delete(ctx context.Context, job *api.PFJob) error {
+	if job == nil {
+		return fmt.Errorf("job is nil")
+	}
+	err := lj.client.Delete(localNamespace(job), job.ID, client.LocalFrameworkVersion)
+	if err != nil && k8serrors.IsNotFound(err) {
+		log.Infof("job %s/%s is not found on %s, skip delete", localNamespace(job), job.ID, lj.client.Cluster())
+		return nil
+	}
+	return err
+}
+
+func (lj *localJob) GetLog(ctx context.Context, jobLogRequest pfschema.JobLogRequest) (pfschema.JobLogInfo, error) {
+	namespace := jobLogRequest.Namespace
+	if namespace == "" {
+		namespace = defaultLocalNamespace
+	}
+	taskLogs, err := lj.client.GetTaskLog(namespace, jobLogRequest.JobID, jobLogRequest.LogFilePosition,
+		jobLogRequest.LogPageSize, jobLogRequest.LogPageNo)
+	if err != nil {
+		log.Errorf("get log of job %s failed, err: %v", jobLogRequest.JobID, err)
+		return pfschema.JobLogInfo{}, err
+	}
+	return pfschema.JobLogInfo{
+		JobID:    jobLogRequest.JobID,
+		TaskList: taskLogs,
+	}, nil
+}
+
+func (lj *localJob) AddEventListener(ctx context.Context, listenerType string,
+	eventQueue workqueue.RateLimitingInterface, informer interface{}) error {
+	// status updates of local processes are sent by local runtime client
+	return nil
+}
+
+func localNamespace(job *api.PFJob) string {
+	if job.Namespace == "" {
+		return defaultLocalNamespace
+	}
+	return job.Namespace
+}
@@ -0,0 +1,159 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime_v2
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/job/api"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
+)
+
+func newTestLocalRuntime(t *testing.T) (RuntimeService, workqueue.RateLimitingInterface) {
+	t.Setenv(schema.EnvLocalRuntimeDir, t.TempDir())
+	t.Setenv(schema.EnvLocalRuntimeContainer, "false")
+	runtimeSvc, err := CreateRuntime(model.ClusterInfo{
+		Name:        "local-cluster",
+		ClusterType: schema.LocalType,
+	})
+	assert.NoError(t, err)
+	jobQueue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	err = runtimeSvc.Client().RegisterListener(schema.ListenerTypeJob, jobQueue)
+	assert.NoError(t, err)
+	return runtimeSvc, jobQueue
+}
+
+func waitJobSyncInfo(t *testing.T, jobQueue workqueue.RateLimitingInterface, action schema.ActionType) *api.JobSyncInfo {
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case <-timeout:
+			t.Fatalf("wait job sync info with action %s timeout", action)
+		default:
+		}
+		obj, _ := jobQueue.Get()
+		jobQueue.Done(obj)
+		jobSyncInfo := obj.(*api.JobSyncInfo)
+		if jobSyncInfo.Action == action {
+			return jobSyncInfo
+		}
+	}
+}
+
+func newLocalTestJob(id, command string) *api.PFJob {
+	return &api.PFJob{
+		ID:        id,
+		Namespace: "default",
+		JobType:   schema.TypeSingle,
+		Conf: schema.Conf{
+			Env: map[string]string{"PF_TEST_ENV": "local"},
+		},
+		Tasks: []schema.Member{
+			{
+				ID:   "task-0",
+				Conf: schema.Conf{Command: command},
+			},
+		},
+	}
+}
+
+func TestLocalRuntimeJob(t *testing.T) {
+	runtimeSvc, jobQueue := newTestLocalRuntime(t)
+
+	testCases := []struct {
+		name          string
+		job           *api.PFJob
+		expectStatus  schema.JobStatus
+		expectMessage string
+		expectLog     string
+	}{
+		{
+			name:         "job succeeded",
+			job:          newLocalTestJob("job-local-succeeded", "echo hello $PF_TEST_ENV"),
+			expectStatus: schema.StatusJobSucceeded,
+			expectLog:    "hello local",
+		},
+		{
+			name:          "job failed with exit code",
+			job:           newLocalTestJob("job-local-failed", "echo error message >&2; exit 3"),
+			expectStatus:  schema.StatusJobFailed,
+			expectMessage: "process exited with code 3",
+			expectLog:     "error message",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := runtimeSvc.SubmitJob(tc.job)
+			assert.NoError(t, err)
+			created := waitJobSyncInfo(t, jobQueue, schema.Create)
+			assert.Equal(t, schema.StatusJobRunning, created.Status)
+
+			updated := waitJobSyncInfo(t, jobQueue, schema.Update)
+			assert.Equal(t, tc.job.ID, updated.ID)
+			assert.Equal(t, tc.expectStatus, updated.Status)
+			assert.Contains(t, updated.Message, tc.expectMessage)
+
+			jobLog, err := runtimeSvc.GetLog(schema.JobLogRequest{
+				JobID:           tc.job.ID,
+				Namespace:       tc.job.Namespace,
+				LogFilePosition: common.EndFilePosition,
+				LogPageSize:     100,
+				LogPageNo:       1,
+			}, schema.MixedLogRequest{})
+			assert.NoError(t, err)
+			assert.Equal(t, 1, len(jobLog.TaskList))
+			assert.Equal(t, tc.expectLog, strings.TrimSpace(jobLog.TaskList[0].Info.LogContent))
+
+			// clean finished job
+			err = runtimeSvc.Client().Delete(tc.job.Namespace, tc.job.ID, runtimeSvc.Client().JobFrameworkVersion(tc.job.JobType, tc.job.Framework))
+			assert.NoError(t, err)
+			_, err = runtimeSvc.Client().Get(tc.job.Namespace, tc.job.ID, schema.FrameworkVersion{})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLocalRuntimeStopJob(t *testing.T) {
+	runtimeSvc, jobQueue := newTestLocalRuntime(t)
+
+	job := newLocalTestJob("job-local-stop", "sleep 30")
+	err := runtimeSvc.SubmitJob(job)
+	assert.NoError(t, err)
+	waitJobSyncInfo(t, jobQueue, schema.Create)
+
+	err = runtimeSvc.StopJob(job)
+	assert.NoError(t, err)
+	deleted := waitJobSyncInfo(t, jobQueue, schema.Delete)
+	assert.Equal(t, schema.StatusJobTerminated, deleted.Status)
+
+	// job with unsupported type or empty command
+	err = runtimeSvc.SubmitJob(&api.PFJob{ID: "job-local-dist", JobType: schema.TypeDistributed})
+	assert.Error(t, err)
+	err = runtimeSvc.SubmitJob(newLocalTestJob("job-local-empty", ""))
+	assert.Error(t, err)
+	// delete job which is not exist
+	err = runtimeSvc.DeleteJob(newLocalTestJob("job-local-not-exist", ""))
+	assert.NoError(t, err)
+}
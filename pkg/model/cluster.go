@@ -21,6 +21,8 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage/secret"
 )
 
 const (
@@ -28,23 +30,26 @@ const (
 	ClusterStatusOnLine  = "online"
 	ClusterStatusOffLine = "offline"
 	DefaultClusterStatus = ClusterStatusOnLine
+	// ClusterStatusReasonUnhealthy means the cluster is set offline by health checker
+	ClusterStatusReasonUnhealthy = "unhealthy"
 )
 
 type ClusterInfo struct {
 	Model            `gorm:"embedded"  json:",inline"`
-	Pk               int64    `gorm:"primaryKey;autoIncrement" json:"-"`      // 自增主键
-	Name             string   `gorm:"column:name" json:"clusterName"`         // 集群名字
-	Description      string   `gorm:"column:description" json:"description"`  // 集群描述
-	Endpoint         string   `gorm:"column:endpoint" json:"endpoint"`        // 集群endpoint, 比如 http://10.11.11.47:8080
-	Source           string   `gorm:"column:source" json:"source"`            // 来源, 比如 OnPremise （内部部署）、AWS、CCE
-	ClusterType      string   `gorm:"column:cluster_type" json:"clusterType"` // 集群类型，比如Kubernetes/Local
-	Version          string   `gorm:"column:version" json:"version"`          // 集群版本，比如v1.16
-	Status           string   `gorm:"column:status" json:"status"`            // 集群状态，可选值为online, offline
-	Credential       string   `gorm:"column:credential" json:"credential"`    // 用于存储集群的凭证信息，比如k8s的kube_config配置
-	Setting          string   `gorm:"column:setting" json:"setting"`          // 存储额外配置信息
-	RawNamespaceList string   `gorm:"column:namespace_list" json:"-"`         // 命名空间列表，json类型，如["ns1", "ns2"]
-	NamespaceList    []string `gorm:"-" json:"namespaceList"`                 // 命名空间列表，json类型，如["ns1", "ns2"]
-	DeletedAt        string   `gorm:"column:deleted_at" json:"-"`             // 删除标识，非空表示软删除
+	Pk               int64    `gorm:"primaryKey;autoIncrement" json:"-"`        // 自增主键
+	Name             string   `gorm:"column:name" json:"clusterName"`           // 集群名字
+	Description      string   `gorm:"column:description" json:"description"`    // 集群描述
+	Endpoint         string   `gorm:"column:endpoint" json:"endpoint"`          // 集群endpoint, 比如 http://10.11.11.47:8080
+	Source           string   `gorm:"column:source" json:"source"`              // 来源, 比如 OnPremise （内部部署）、AWS、CCE
+	ClusterType      string   `gorm:"column:cluster_type" json:"clusterType"`   // 集群类型，比如Kubernetes/Local
+	Version          string   `gorm:"column:version" json:"version"`            // 集群版本，比如v1.16
+	Status           string   `gorm:"column:status" json:"status"`              // 集群状态，可选值为online, offline
+	StatusReason     string   `gorm:"column:status_reason" json:"statusReason"` // 集群状态原因，unhealthy表示被健康检查自动下线
+	Credential       string   `gorm:"column:credential" json:"credential"`      // 用于存储集群的凭证信息，比如k8s的kube_config配置
+	Setting          string   `gorm:"column:setting" json:"setting"`            // 存储额外配置信息
+	RawNamespaceList string   `gorm:"column:namespace_list" json:"-"`           // 命名空间列表，json类型，如["ns1", "ns2"]
+	NamespaceList    []string `gorm:"-" json:"namespaceList"`                   // 命名空间列表，json类型，如["ns1", "ns2"]
+	DeletedAt        string   `gorm:"column:deleted_at" json:"-"`               // 删除标识，非空表示软删除
 }
 
 func (ClusterInfo) TableName() string {
@@ -73,9 +78,20 @@ func (clusterInfo *ClusterInfo) BeforeSave(*gorm.DB) error {
 		}
 		clusterInfo.RawNamespaceList = string(namespaceList)
 	}
+	credential, err := secret.Encrypt(clusterInfo.Credential)
+	if err != nil {
+		log.Errorf("encrypt credential of cluster[%s] failed: %v", clusterInfo.Name, err)
+		return err
+	}
+	clusterInfo.Credential = credential
 	return nil
 }
 
+// AfterSave restores the plain credential, which is encrypted in BeforeSave
+func (clusterInfo *ClusterInfo) AfterSave(*gorm.DB) error {
+	return clusterInfo.decryptCredential()
+}
+
 func (clusterInfo *ClusterInfo) AfterFind(*gorm.DB) error {
 	if clusterInfo.RawNamespaceList != "" {
 		if err := json.Unmarshal([]byte(clusterInfo.RawNamespaceList), &clusterInfo.NamespaceList); err != nil {
@@ -83,5 +99,15 @@ func (clusterInfo *ClusterInfo) AfterFind(*gorm.DB) error {
 			return err
 		}
 	}
+	return clusterInfo.decryptCredential()
+}
+
+func (clusterInfo *ClusterInfo) decryptCredential() error {
+	credential, err := secret.Decrypt(clusterInfo.Credential)
+	if err != nil {
+		log.Errorf("decrypt credential of cluster[%s] failed: %v", clusterInfo.Name, err)
+		return err
+	}
+	clusterInfo.Credential = credential
 	return nil
 }
@@ -18,15 +18,29 @@ package model
 
 import (
 	"encoding/json"
+	"fmt"
 
 	log "github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/common"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage/secret"
 )
 
 const (
 	FileSystemTableName = "filesystem"
 )
 
+// SensitiveProperties are the properties of file system and link which are encrypted in database
+var SensitiveProperties = []string{
+	common.AccessKey,
+	common.SecretKey,
+	common.Password,
+	common.Token,
+	common.BosSessionToken,
+	common.KeyTabData,
+}
+
 // FileSystem defined file system model, which can be used to create file system
 type FileSystem struct {
 	Model
@@ -52,13 +66,23 @@ func (s *FileSystem) AfterFind(*gorm.DB) error {
 			log.Errorf("json Unmarshal propertiesJson[%s] failed: %v", s.PropertiesJson, err)
 			return err
 		}
+		if err := decryptProperties(s.PropertiesMap); err != nil {
+			log.Errorf("decrypt properties of file system[%s] failed: %v", s.ID, err)
+			return err
+		}
 	}
 	return nil
 }
 
-// BeforeSave is the callback methods for saving file system
+// BeforeSave is the callback methods for saving file system, sensitive properties are encrypted in database,
+// while PropertiesMap is kept in plain text
 func (s *FileSystem) BeforeSave(*gorm.DB) error {
-	propertiesJson, err := json.Marshal(&s.PropertiesMap)
+	properties, err := encryptProperties(s.PropertiesMap)
+	if err != nil {
+		log.Errorf("encrypt properties of file system[%s] failed: %v", s.ID, err)
+		return err
+	}
+	propertiesJson, err := json.Marshal(&properties)
 	if err != nil {
 		log.Errorf("json Marshal propertiesMap[%v] failed: %v", s.PropertiesMap, err)
 		return err
@@ -66,3 +90,38 @@ func (s *FileSystem) BeforeSave(*gorm.DB) error {
 	s.PropertiesJson = string(propertiesJson)
 	return nil
 }
+
+// decryptProperties decrypts the sensitive properties in place
+func decryptProperties(properties map[string]string) error {
+	for _, key := range SensitiveProperties {
+		value, err := secret.Decrypt(properties[key])
+		if err != nil {
+			return fmt.Errorf("decrypt property %s failed: %v", key, err)
+		}
+		if value != "" {
+			properties[key] = value
+		}
+	}
+	return nil
+}
+
+// encryptProperties returns a copy of properties whose sensitive properties are encrypted
+func encryptProperties(properties map[string]string) (map[string]string, error) {
+	if properties == nil {
+		return nil, nil
+	}
+	encrypted := make(map[string]string, len(properties))
+	for key, value := range properties {
+		encrypted[key] = value
+	}
+	for _, key := range SensitiveProperties {
+		value, err := secret.Encrypt(encrypted[key])
+		if err != nil {
+			return nil, fmt.Errorf("encrypt property %s failed: %v", key, err)
+		}
+		if value != "" {
+			encrypted[key] = value
+		}
+	}
+	return encrypted, nil
+}
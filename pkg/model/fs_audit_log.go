@@ -0,0 +1,69 @@
+/*
+Copyright (c) 2021 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const FsAuditLogTableName = "fs_audit_log"
+
+// FsAuditLog is an append-only record of who operated a file system, which is never updated or deleted
+type FsAuditLog struct {
+	Pk       int64  `json:"-"        gorm:"primaryKey;autoIncrement;not null"`
+	FsID     string `json:"fsID"     gorm:"type:varchar(200);not null"`
+	UserName string `json:"userName" gorm:"type:varchar(60);not null"`
+	// JobID is the job whose process accessed files through fuse client, empty for server operations
+	JobID     string `json:"jobID"     gorm:"type:varchar(60)"`
+	Operation string `json:"operation" gorm:"type:varchar(32);not null"`
+	// Path is the file accessed or the link path, empty for operations on the whole file system
+	Path string `json:"path" gorm:"type:varchar(4096)"`
+	// Detail is extra info like the grantee of grant or the uid of fuse caller
+	Detail     string    `json:"detail"     gorm:"type:varchar(1024)"`
+	Source     string    `json:"source"     gorm:"type:varchar(32);not null"`
+	Host       string    `json:"host"       gorm:"type:varchar(256)"`
+	CreateTime string    `json:"createTime" gorm:"-"`
+	CreatedAt  time.Time `json:"-"`
+	// OccurredAt is the time reported by fuse client when the access happened, which depends on the clock of
+	// client and is only for reference, nil for server operations. Logs are ordered and filtered by CreatedAt.
+	OccurredAt *time.Time `json:"-"`
+	OccurTime  string     `json:"occurTime,omitempty" gorm:"-"`
+}
+
+func (FsAuditLog) TableName() string {
+	return FsAuditLogTableName
+}
+
+func (l *FsAuditLog) AfterFind(*gorm.DB) error {
+	l.CreateTime = l.CreatedAt.Format("2006-01-02 15:04:05")
+	if l.OccurredAt != nil {
+		l.OccurTime = l.OccurredAt.Format("2006-01-02 15:04:05")
+	}
+	return nil
+}
+
+// FsAuditLogFilter is the conditions of listing audit logs, empty fields match all
+type FsAuditLogFilter struct {
+	FsID       string
+	UserName   string
+	JobID      string
+	Operations []string
+	StartTime  time.Time
+	EndTime    time.Time
+}
@@ -0,0 +1,49 @@
+/*
+Copyright (c) 2021 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/common"
+)
+
+const FsQuotaTableName = "fs_quota"
+
+// FsQuota defined the limits of bytes and inodes under a directory of file system, 0 means no limit
+type FsQuota struct {
+	Model
+	FsID      string `json:"fsID"`
+	Path      string `json:"path"`
+	MaxBytes  int64  `json:"maxBytes"`
+	MaxInodes int64  `json:"maxInodes"`
+}
+
+func (FsQuota) TableName() string {
+	return FsQuotaTableName
+}
+
+// FsQuotasMeta returns the quotas persisted in file system for clients
+func FsQuotasMeta(quotas []FsQuota) []common.FsQuota {
+	quotasMeta := make([]common.FsQuota, 0, len(quotas))
+	for _, quota := range quotas {
+		quotasMeta = append(quotasMeta, common.FsQuota{
+			Path:      quota.Path,
+			MaxBytes:  quota.MaxBytes,
+			MaxInodes: quota.MaxInodes,
+		})
+	}
+	return quotasMeta
+}
@@ -0,0 +1,63 @@
+/*
+Copyright (c) 2021 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/fs/common"
+)
+
+const (
+	FsSnapshotTableName = "fs_snapshot"
+
+	FsSnapshotStatusCreating = "creating"
+	FsSnapshotStatusReady    = "ready"
+	FsSnapshotStatusFailed   = "failed"
+)
+
+// FsSnapshot defined the point-in-time snapshot of a file system
+type FsSnapshot struct {
+	Model
+	Name     string `json:"name"`
+	FsID     string `json:"fsID"`
+	FsType   string `json:"fsType"`
+	UserName string `json:"userName"`
+	// Mode is how the snapshot is taken, such as manifest, copy or native
+	Mode      string `json:"mode"`
+	Status    string `json:"status"`
+	Message   string `json:"message" gorm:"type:text"`
+	FileCount int64  `json:"fileCount"`
+	Size      int64  `json:"size"`
+}
+
+func (FsSnapshot) TableName() string {
+	return FsSnapshotTableName
+}
+
+// SnapshotFileSystem returns the read-only file system of snapshot, which is mounted as a file system with the
+// id of snapshot
+func SnapshotFileSystem(fs FileSystem, snapshot FsSnapshot) FileSystem {
+	snapshotFs := fs
+	snapshotFs.ID = snapshot.ID
+	snapshotFs.Name = schema.FsSnapshotName(fs.Name, snapshot.Name)
+	snapshotFs.PropertiesMap = make(map[string]string, len(fs.PropertiesMap)+1)
+	for k, v := range fs.PropertiesMap {
+		snapshotFs.PropertiesMap[k] = v
+	}
+	snapshotFs.PropertiesMap[common.Snapshot] = snapshot.Name
+	return snapshotFs
+}
@@ -0,0 +1,65 @@
+/*
+Copyright (c) 2021 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+const (
+	FsSyncTaskTableName = "fs_sync_task"
+
+	FsSyncTaskStatusPending   = "pending"
+	FsSyncTaskStatusRunning   = "running"
+	FsSyncTaskStatusSucceeded = "succeeded"
+	FsSyncTaskStatusFailed    = "failed"
+	FsSyncTaskStatusStopped   = "stopped"
+)
+
+// FsSyncTask defined the task which transfers data from a path of file system to another, the progress is saved
+// periodically so that it can be resumed from checkpoint
+type FsSyncTask struct {
+	Model
+	UserName string `json:"userName"`
+	SrcFsID  string `json:"srcFsID"`
+	SrcPath  string `json:"srcPath"`
+	DstFsID  string `json:"dstFsID"`
+	DstPath  string `json:"dstPath"`
+	// Mode is copy or sync, sync deletes the files in destination which are not in source
+	Mode string `json:"mode"`
+	// Compare is how files are compared, size-mtime or checksum
+	Compare        string `json:"compare"`
+	Workers        int    `json:"workers"`
+	BandwidthLimit int64  `json:"bandwidthLimit"`
+	DryRun         bool   `json:"dryRun"`
+	Status         string `json:"status"`
+	Message        string `json:"message" gorm:"type:text"`
+	Total          int64  `json:"total"`
+	Copied         int64  `json:"copied"`
+	Skipped        int64  `json:"skipped"`
+	Deleted        int64  `json:"deleted"`
+	Failed         int64  `json:"failed"`
+	CopiedBytes    int64  `json:"copiedBytes"`
+	Checkpoint     string `json:"checkpoint" gorm:"type:text"`
+	Diff           string `json:"diff" gorm:"type:mediumtext"`
+}
+
+func (FsSyncTask) TableName() string {
+	return FsSyncTaskTableName
+}
+
+// IsFinal returns whether the task is not running or waiting to run
+func (t FsSyncTask) IsFinal() bool {
+	return t.Status == FsSyncTaskStatusSucceeded || t.Status == FsSyncTaskStatusFailed ||
+		t.Status == FsSyncTaskStatusStopped
+}
@@ -0,0 +1,85 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+)
+
+type JobTemplate struct {
+	Pk        int64          `json:"-"                    gorm:"primaryKey;autoIncrement;not null"`
+	ID        string         `json:"templateID"           gorm:"type:varchar(60);not null;index"`
+	Name      string         `json:"name"                 gorm:"type:varchar(128);not null;index:idx_template_name"`
+	Desc      string         `json:"desc"                 gorm:"type:varchar(256);not null"`
+	UserName  string         `json:"username"             gorm:"type:varchar(60);not null;index:idx_template_name"`
+	CreatedAt time.Time      `json:"-"`
+	UpdatedAt time.Time      `json:"-"`
+	DeletedAt gorm.DeletedAt `json:"-"`
+}
+
+func (JobTemplate) TableName() string {
+	return "job_template"
+}
+
+// JobTemplateVersion is an immutable version of job template, and a new version is created when template is updated
+type JobTemplateVersion struct {
+	Pk         int64  `json:"-"                    gorm:"primaryKey;autoIncrement;not null"`
+	ID         string `json:"templateVersionID"    gorm:"type:varchar(60);not null"`
+	TemplateID string `json:"templateID"           gorm:"type:varchar(60);not null;index"`
+	// JobType is the type of job created by template, such as single, distributed and workflow
+	JobType        string                        `json:"jobType"              gorm:"type:varchar(20);not null"`
+	ParametersJson string                        `json:"-"                    gorm:"column:parameters;type:text"`
+	Parameters     []schema.JobTemplateParameter `json:"parameters"           gorm:"-"`
+	// Template is the job request in json format, which references parameters as {{name}}
+	Template    string         `json:"template"             gorm:"type:text;size:65535;not null"`
+	TemplateMd5 string         `json:"templateMd5"          gorm:"type:varchar(32);not null"`
+	UserName    string         `json:"username"             gorm:"type:varchar(60);not null"`
+	CreatedAt   time.Time      `json:"-"`
+	UpdatedAt   time.Time      `json:"-"`
+	DeletedAt   gorm.DeletedAt `json:"-"`
+}
+
+func (JobTemplateVersion) TableName() string {
+	return "job_template_version"
+}
+
+func (tv *JobTemplateVersion) BeforeSave(tx *gorm.DB) error {
+	if tv.Parameters != nil {
+		parametersJson, err := json.Marshal(tv.Parameters)
+		if err != nil {
+			return err
+		}
+		tv.ParametersJson = string(parametersJson)
+	}
+	return nil
+}
+
+func (tv *JobTemplateVersion) AfterFind(tx *gorm.DB) error {
+	if len(tv.ParametersJson) > 0 {
+		var parameters []schema.JobTemplateParameter
+		if err := json.Unmarshal([]byte(tv.ParametersJson), &parameters); err != nil {
+			return err
+		}
+		tv.Parameters = parameters
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"time"
+)
+
+const JobUsageTableName = "job_usage"
+
+// JobUsage is the resources consumed by a finished job in one day, the usage of job running across days is split
+// into one record per day. CPU is measured in core-seconds, memory in GiB-seconds and GPU in card-seconds.
+type JobUsage struct {
+	Pk            int64     `json:"-" gorm:"primaryKey;autoIncrement"`
+	JobID         string    `json:"jobID" gorm:"type:varchar(60);uniqueIndex:idx_job_date;NOT NULL"`
+	Date          string    `json:"date" gorm:"type:varchar(10);uniqueIndex:idx_job_date;index:idx_date;NOT NULL"`
+	UserName      string    `json:"userName" gorm:"type:varchar(60);NOT NULL"`
+	QueueID       string    `json:"queueID" gorm:"type:varchar(60);NOT NULL"`
+	QueueName     string    `json:"queueName" gorm:"type:varchar(255);default:''"`
+	ClusterID     string    `json:"clusterID" gorm:"type:varchar(60);default:''"`
+	ClusterName   string    `json:"clusterName" gorm:"type:varchar(255);default:''"`
+	JobType       string    `json:"jobType" gorm:"type:varchar(20);default:''"`
+	Status        string    `json:"status" gorm:"type:varchar(32);default:''"`
+	Duration      float64   `json:"duration"`
+	CPUSeconds    float64   `json:"cpuSeconds"`
+	MemorySeconds float64   `json:"memorySeconds"`
+	GPUSeconds    float64   `json:"gpuSeconds"`
+	CreatedAt     time.Time `json:"createTime"`
+}
+
+func (JobUsage) TableName() string {
+	return JobUsageTableName
+}
+
+// UsageSummary is the usage of jobs rolled up by day and user, queue or cluster
+type UsageSummary struct {
+	Date          string  `json:"date"`
+	Name          string  `json:"name"`
+	JobCount      int64   `json:"jobCount"`
+	Duration      float64 `json:"duration"`
+	CPUSeconds    float64 `json:"cpuSeconds"`
+	MemorySeconds float64 `json:"memorySeconds"`
+	GPUSeconds    float64 `json:"gpuSeconds"`
+}
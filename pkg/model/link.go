@@ -18,6 +18,7 @@ package model
 
 import (
 	"encoding/json"
+
 	log "github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
@@ -51,13 +52,22 @@ func (s *Link) AfterFind(*gorm.DB) error {
 			log.Errorf("json Unmarshal propertiesJson[%s] failed: %v", s.PropertiesJson, err)
 			return err
 		}
+		if err := decryptProperties(s.PropertiesMap); err != nil {
+			log.Errorf("decrypt properties of link[%s] failed: %v", s.ID, err)
+			return err
+		}
 	}
 	return nil
 }
 
-// BeforeSave is the callback methods for saving file system
+// BeforeSave is the callback methods for saving link, sensitive properties are encrypted in database as file system
 func (s *Link) BeforeSave(*gorm.DB) error {
-	propertiesJson, err := json.Marshal(&s.PropertiesMap)
+	properties, err := encryptProperties(s.PropertiesMap)
+	if err != nil {
+		log.Errorf("encrypt properties of link[%s] failed: %v", s.ID, err)
+		return err
+	}
+	propertiesJson, err := json.Marshal(&properties)
 	if err != nil {
 		log.Errorf("json Marshal propertiesMap[%v] failed: %v", s.PropertiesMap, err)
 		return err
@@ -17,15 +17,28 @@ limitations under the License.
 package monitor
 
 const (
-	QueryCPUUsageRateQl = "sum(rate(container_cpu_usage_seconds_total{image!=\"\", pod=~\"%s\"}[1m])) by (pod) / sum(container_spec_cpu_quota{image!=\"\", pod=~\"%s\"} / 100000) by (pod)"
-	QueryMEMUsageRateQl = "sum(container_memory_working_set_bytes{image!=\"\", pod=~\"%s\"}) by (pod) / sum(container_spec_memory_limit_bytes{image!=\"\", pod=~\"%s\"}) by (pod)"
-	QueryMEMUsageQl     = "sum(container_memory_working_set_bytes{image!=\"\", pod=~\"%s\"}) by (pod)"
-	QueryNetReceiveQl   = "sum(rate(container_network_receive_bytes_total{image!=\"\", pod=~\"%s\"}[1m])) by (pod)"
-	QueryNetTransmitQl  = "sum(rate(container_network_transmit_bytes_total{image!=\"\", pod=~\"%s\"}[1m])) by (pod)"
-	QueryDiskUsageQl    = "sum(container_fs_usage_bytes{image!=\"\", pod=~\"%s\"}) by (pod)"
-	QueryDiskReadQl     = "sum(rate(container_fs_reads_bytes_total{image!=\"\", pod=~\"%s\"}[1m])) by (pod)"
-	QueryDiskWriteQl    = "sum(rate(container_fs_writes_bytes_total{image!=\"\", pod=~\"%s\"}[1m])) by (pod)"
-	QueryGpuUtilQl      = "sum(rate(container_accelerator_duty_cycle{image!=\"\", pod=~\"%s\"}[1m])) by (pod)"
-	QueryGpuMemUtilQl   = "sum(container_accelerator_memory_used_bytes{image!=\"\", pod=~\"%s\"}) by (pod) / sum(container_accelerator_memory_total_bytes{image!=\"\", pod=~\"%s\"}) by (pod)"
-	QueryGpuMemUsageQl  = "sum(container_accelerator_memory_used_bytes{image!=\"\", pod=~\"%s\"}) by (pod)"
+	QueryCPUUsageRateQl = "sum(rate(container_cpu_usage_seconds_total{image!=\"\", pod=~\"{{.PodNames}}\"}[{{.RateWindow}}])) by (pod) / sum(container_spec_cpu_quota{image!=\"\", pod=~\"{{.PodNames}}\"} / 100000) by (pod)"
+	QueryMEMUsageRateQl = "sum(container_memory_working_set_bytes{image!=\"\", pod=~\"{{.PodNames}}\"}) by (pod) / sum(container_spec_memory_limit_bytes{image!=\"\", pod=~\"{{.PodNames}}\"}) by (pod)"
+	QueryMEMUsageQl     = "sum(container_memory_working_set_bytes{image!=\"\", pod=~\"{{.PodNames}}\"}) by (pod)"
+	QueryNetReceiveQl   = "sum(rate(container_network_receive_bytes_total{image!=\"\", pod=~\"{{.PodNames}}\"}[{{.RateWindow}}])) by (pod)"
+	QueryNetTransmitQl  = "sum(rate(container_network_transmit_bytes_total{image!=\"\", pod=~\"{{.PodNames}}\"}[{{.RateWindow}}])) by (pod)"
+	QueryDiskUsageQl    = "sum(container_fs_usage_bytes{image!=\"\", pod=~\"{{.PodNames}}\"}) by (pod)"
+	QueryDiskReadQl     = "sum(rate(container_fs_reads_bytes_total{image!=\"\", pod=~\"{{.PodNames}}\"}[{{.RateWindow}}])) by (pod)"
+	QueryDiskWriteQl    = "sum(rate(container_fs_writes_bytes_total{image!=\"\", pod=~\"{{.PodNames}}\"}[{{.RateWindow}}])) by (pod)"
+	QueryGpuUtilQl      = "sum(rate(container_accelerator_duty_cycle{image!=\"\", pod=~\"{{.PodNames}}\"}[{{.RateWindow}}])) by (pod)"
+	QueryGpuMemUtilQl   = "sum(container_accelerator_memory_used_bytes{image!=\"\", pod=~\"{{.PodNames}}\"}) by (pod) / sum(container_accelerator_memory_total_bytes{image!=\"\", pod=~\"{{.PodNames}}\"}) by (pod)"
+	QueryGpuMemUsageQl  = "sum(container_accelerator_memory_used_bytes{image!=\"\", pod=~\"{{.PodNames}}\"}) by (pod)"
+)
+
+const (
+	// MetricBackendPrometheus query job metrics from prometheus, which is the default backend
+	MetricBackendPrometheus = "prometheus"
+	// MetricBackendMetricsServer query job metrics from kubernetes metrics.k8s.io api
+	MetricBackendMetricsServer = "metrics-server"
+
+	DefaultRateWindow = "1m"
+	// DefaultSampleInterval is the interval of sampling pod metrics from metrics-server, in seconds
+	DefaultSampleInterval = 30
+	// DefaultSampleRetention is the retention of sampled pod metrics, in seconds
+	DefaultSampleRetention = 24 * 3600
 )
@@ -17,10 +17,40 @@ limitations under the License.
 package monitor
 
 import (
+	"fmt"
+
 	"github.com/prometheus/common/model"
 )
 
 type MetricInterface interface {
-	GetJobAvgMetrics(metricName, jobID string) (float64, error)
+	// GetJobAvgMetrics get the average value of metric over time range [start, end], if start or end is zero,
+	// the running time of job is used instead
+	GetJobAvgMetrics(metricName, jobID string, start, end int64) (float64, error)
 	GetJobSequenceMetrics(metricName, jobID string, start, end, step int64) (model.Value, error)
+	// GetQueueAvgMetrics get the average value of metric over jobs which were running in queue during [start, end]
+	GetQueueAvgMetrics(metricName, queueID string, start, end int64) (float64, error)
+	GetQueueSequenceMetrics(metricName, queueID string, start, end, step int64) (model.Value, error)
+}
+
+var metricBackend = MetricBackendPrometheus
+
+// InitMetricBackend set the backend used by job statistics, and the queries which override the built-in templates
+func InitMetricBackend(backend string, queries map[string]string) error {
+	switch backend {
+	case "":
+		backend = MetricBackendPrometheus
+	case MetricBackendPrometheus, MetricBackendMetricsServer:
+	default:
+		return fmt.Errorf("metric backend[%s] is not support", backend)
+	}
+	if err := SetQueryTemplates(queries); err != nil {
+		return err
+	}
+	metricBackend = backend
+	return nil
+}
+
+// MetricBackend return the backend used by job statistics
+func MetricBackend() string {
+	return metricBackend
 }
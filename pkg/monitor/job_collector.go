@@ -18,6 +18,7 @@ package monitor
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -75,7 +76,7 @@ func (j *JobCollector) CollectPodMetrics(metricName string) error {
 			log.Errorf("job[%s] get pod name list error %s", value.ID, err.Error())
 			return err
 		}
-		result, err := callPrometheusAPI(metricName, value.ID)
+		result, err := callPrometheusAPI(metricName, value.ID, podNameList)
 		if err != nil {
 			log.Errorf("call prometheus query api error %s", err.Error())
 			return err
@@ -96,10 +97,14 @@ func (j *JobCollector) CollectPodMetrics(metricName string) error {
 	return nil
 }
 
-func callPrometheusAPI(metricName, jobID string) (prometheusModel.Value, error) {
+func callPrometheusAPI(metricName, jobID string, podNameList []string) (prometheusModel.Value, error) {
 	ctxP, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-	query := getQuerySql(metricName)
+	query, err := RenderQuery(metricName, QueryParams{PodNames: strings.Join(podNameList, "|")})
+	if err != nil {
+		log.Errorf("job[%s] render query of metric[%s] failed, error %s", jobID, metricName, err.Error())
+		return nil, err
+	}
 	result, _, err := PrometheusClientAPI.Query(ctxP, query, time.Now())
 	if err != nil {
 		log.Errorf("job[%s] prometheus query range api error %s", jobID, err.Error())
@@ -108,17 +113,6 @@ func callPrometheusAPI(metricName, jobID string) (prometheusModel.Value, error)
 	return result, nil
 }
 
-func getQuerySql(metricName string) string {
-	switch metricName {
-	case consts.MetricCpuUsageRate:
-		querySql := QueryCPUUsageRateQl
-		return querySql
-		// TODO add more metric sql
-	default:
-		return ""
-	}
-}
-
 func getPodNameList(podNameList *[]string, job model.Job) error {
 	names, err := getTaskName(job.ID)
 	if err != nil {
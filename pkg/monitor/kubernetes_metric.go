@@ -18,7 +18,6 @@ package monitor
 
 import (
 	"context"
-	"fmt"
 	"strings"
 	"time"
 
@@ -26,11 +25,10 @@ import (
 	"github.com/prometheus/common/model"
 	log "github.com/sirupsen/logrus"
 
-	"github.com/PaddlePaddle/PaddleFlow/pkg/common/consts"
-	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
 )
 
+// KubernetesMetric query metrics of kubernetes jobs from prometheus
 type KubernetesMetric struct {
 	PrometheusClientAPI v1.API
 }
@@ -41,60 +39,70 @@ func NewKubernetesMetric(clientAPI v1.API) MetricInterface {
 	}
 }
 
-func (km *KubernetesMetric) GetJobAvgMetrics(metricName, jobID string) (float64, error) {
+func (km *KubernetesMetric) GetJobAvgMetrics(metricName, jobID string, start, end int64) (float64, error) {
 	job, err := storage.Job.GetJobByID(jobID)
 	if err != nil {
 		log.Errorf("job[%s] find error %s", jobID, err.Error())
 		return 0.0, err
 	}
-	var start, end int64
-	if job.ActivatedAt.Valid {
-		start = job.ActivatedAt.Time.Unix()
-	} else {
+	start, end, ok := jobTimeRange(job, start, end)
+	if !ok {
 		return 0.0, nil
 	}
-	if schema.IsImmutableJobStatus(job.Status) {
-		end = job.UpdatedAt.Unix()
-	} else {
-		end = time.Now().Unix()
-	}
 	result, err := km.GetJobSequenceMetrics(metricName, jobID, start, end, 30)
 	if err != nil {
 		log.Errorf("job[%s] get prometheus sequence data error %s", jobID, err.Error())
 		return 0.0, err
 	}
-	data, ok := result.(model.Matrix)
-	if !ok {
-		return 0.0, fmt.Errorf("convert result to matrix failed")
+	return averageOfValue(result)
+}
+
+func (km *KubernetesMetric) GetJobSequenceMetrics(metricName, jobID string, start, end, step int64) (model.Value, error) {
+	podNameList, err := listJobPodNames(jobID)
+	if err != nil {
+		return nil, err
 	}
-	sum := 0.0
-	count := 0
-	for _, value := range data {
-		for _, rangeValue := range value.Values {
-			sum += float64(rangeValue.Value)
-			count += 1
-		}
+	result, err := km.queryRange(metricName, podNameList, start, end, step)
+	if err != nil {
+		log.Errorf("job[%s] prometheus query range api error %s", jobID, err.Error())
+		return nil, err
 	}
-	if count != 0 {
-		return sum / float64(count), nil
+	return result, nil
+}
+
+func (km *KubernetesMetric) GetQueueAvgMetrics(metricName, queueID string, start, end int64) (float64, error) {
+	result, err := km.GetQueueSequenceMetrics(metricName, queueID, start, end, 30)
+	if err != nil {
+		log.Errorf("queue[%s] get prometheus sequence data error %s", queueID, err.Error())
+		return 0.0, err
 	}
-	return sum, nil
+	return averageOfValue(result)
 }
 
-func (km *KubernetesMetric) GetJobSequenceMetrics(metricName, jobID string, start, end, step int64) (model.Value, error) {
-	tasks, err := storage.Job.ListByJobID(jobID)
+func (km *KubernetesMetric) GetQueueSequenceMetrics(metricName, queueID string, start, end, step int64) (model.Value, error) {
+	podNameList, err := listQueuePodNames(queueID, start, end)
 	if err != nil {
-		log.Errorf("job[%s] get task error %s", jobID, err.Error())
+		log.Errorf("queue[%s] list pods error %s", queueID, err.Error())
 		return nil, err
 	}
-	podNameList := make([]string, 0)
-	for _, task := range tasks {
-		podNameList = append(podNameList, task.Name)
+	if len(podNameList) == 0 {
+		return model.Matrix{}, nil
+	}
+	result, err := km.queryRange(metricName, podNameList, start, end, step)
+	if err != nil {
+		log.Errorf("queue[%s] prometheus query range api error %s", queueID, err.Error())
+		return nil, err
+	}
+	return result, nil
+}
+
+func (km *KubernetesMetric) queryRange(metricName string, podNameList []string, start, end, step int64) (model.Value, error) {
+	queryPromql, err := RenderQuery(metricName, QueryParams{PodNames: strings.Join(podNameList, "|")})
+	if err != nil {
+		return nil, err
 	}
-	podNames := strings.Join(podNameList, "|")
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-	queryPromql := getQuerySqlByMetric(metricName, podNames)
 	r := v1.Range{
 		Start: time.Unix(start, 0),
 		End:   time.Unix(end, 0),
@@ -102,37 +110,7 @@ func (km *KubernetesMetric) GetJobSequenceMetrics(metricName, jobID string, star
 	}
 	result, _, err := km.PrometheusClientAPI.QueryRange(ctx, queryPromql, r)
 	if err != nil {
-		log.Errorf("job[%s] prometheus query range api error %s", jobID, err.Error())
 		return nil, err
 	}
 	return result, nil
 }
-
-func getQuerySqlByMetric(metricName, podNames string) string {
-	switch metricName {
-	case consts.MetricCpuUsageRate:
-		return fmt.Sprintf(QueryCPUUsageRateQl, podNames, podNames)
-	case consts.MetricMemoryUsageRate:
-		return fmt.Sprintf(QueryMEMUsageRateQl, podNames, podNames)
-	case consts.MetricMemoryUsage:
-		return fmt.Sprintf(QueryMEMUsageQl, podNames)
-	case consts.MetricDiskUsage:
-		return fmt.Sprintf(QueryDiskUsageQl, podNames)
-	case consts.MetricNetReceiveBytes:
-		return fmt.Sprintf(QueryNetReceiveQl, podNames)
-	case consts.MetricNetSendBytes:
-		return fmt.Sprintf(QueryNetTransmitQl, podNames)
-	case consts.MetricDiskReadRate:
-		return fmt.Sprintf(QueryDiskReadQl, podNames)
-	case consts.MetricDiskWriteRate:
-		return fmt.Sprintf(QueryDiskWriteQl, podNames)
-	case consts.MetricGpuUtil:
-		return fmt.Sprintf(QueryGpuUtilQl, podNames)
-	case consts.MetricGpuMemoryUtil:
-		return fmt.Sprintf(QueryGpuMemUtilQl, podNames, podNames)
-	case consts.MetricGpuMemoryUsage:
-		return fmt.Sprintf(QueryGpuMemUsageQl, podNames)
-	default:
-		return ""
-	}
-}
@@ -0,0 +1,143 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package monitor
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/prometheus/common/model"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/consts"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	pfmodel "github.com/PaddlePaddle/PaddleFlow/pkg/model"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
+)
+
+const queueLabel = "queue"
+
+// jobTimeRange fill the zero start or end with the running time of job, ok is false when job is not activated
+func jobTimeRange(job pfmodel.Job, start, end int64) (int64, int64, bool) {
+	if start == 0 {
+		if !job.ActivatedAt.Valid {
+			return 0, 0, false
+		}
+		start = job.ActivatedAt.Time.Unix()
+	}
+	if end == 0 {
+		if schema.IsImmutableJobStatus(job.Status) {
+			end = job.UpdatedAt.Unix()
+		} else {
+			end = time.Now().Unix()
+		}
+	}
+	return start, end, true
+}
+
+func listJobPodNames(jobIDs ...string) ([]string, error) {
+	podNameList := make([]string, 0)
+	for _, jobID := range jobIDs {
+		tasks, err := storage.Job.ListByJobID(jobID)
+		if err != nil {
+			log.Errorf("job[%s] get task error %s", jobID, err.Error())
+			return nil, err
+		}
+		for _, task := range tasks {
+			podNameList = append(podNameList, task.Name)
+		}
+	}
+	return podNameList, nil
+}
+
+func listQueuePodNames(queueID string, start, end int64) ([]string, error) {
+	jobs, err := storage.Job.ListQueueJobByTimeRange(queueID, time.Unix(start, 0), time.Unix(end, 0))
+	if err != nil {
+		return nil, err
+	}
+	jobIDs := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		jobIDs = append(jobIDs, job.ID)
+	}
+	return listJobPodNames(jobIDs...)
+}
+
+// averageOfValue calculate the average of all sample values in matrix
+func averageOfValue(value model.Value) (float64, error) {
+	data, ok := value.(model.Matrix)
+	if !ok {
+		return 0.0, fmt.Errorf("convert result to matrix failed")
+	}
+	sum := 0.0
+	count := 0
+	for _, stream := range data {
+		for _, rangeValue := range stream.Values {
+			sum += float64(rangeValue.Value)
+			count += 1
+		}
+	}
+	if count != 0 {
+		return sum / float64(count), nil
+	}
+	return sum, nil
+}
+
+// isRateMetric return true if metric is a utilization rate, which is averaged rather than summed among pods
+func isRateMetric(metricName string) bool {
+	switch metricName {
+	case consts.MetricCpuUsageRate, consts.MetricMemoryUsageRate, consts.MetricGpuUtil, consts.MetricGpuMemoryUtil:
+		return true
+	default:
+		return false
+	}
+}
+
+// AggregateQueueMatrix merge the series of pods into one series labeled with queue, the values at the same
+// timestamp are averaged for utilization rate metrics and summed for others
+func AggregateQueueMatrix(value model.Value, metricName, queueName string) (model.Matrix, error) {
+	data, ok := value.(model.Matrix)
+	if !ok {
+		return nil, fmt.Errorf("convert result to matrix failed")
+	}
+	sums := make(map[model.Time]float64)
+	counts := make(map[model.Time]int)
+	for _, stream := range data {
+		for _, pair := range stream.Values {
+			sums[pair.Timestamp] += float64(pair.Value)
+			counts[pair.Timestamp] += 1
+		}
+	}
+	values := make([]model.SamplePair, 0, len(sums))
+	for ts, sum := range sums {
+		if isRateMetric(metricName) {
+			sum = sum / float64(counts[ts])
+		}
+		values = append(values, model.SamplePair{Timestamp: ts, Value: model.SampleValue(sum)})
+	}
+	sort.Slice(values, func(i, j int) bool {
+		return values[i].Timestamp < values[j].Timestamp
+	})
+	result := model.Matrix{}
+	if len(values) != 0 {
+		result = append(result, &model.SampleStream{
+			Metric: model.Metric{queueLabel: model.LabelValue(queueName)},
+			Values: values,
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,365 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/common/model"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/consts"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
+)
+
+const podMetricsPathFmt = "/apis/metrics.k8s.io/v1beta1/namespaces/%s/pods"
+
+// podMetricsList is the subset of metrics.k8s.io/v1beta1 PodMetricsList used by PaddleFlow
+type podMetricsList struct {
+	Items []struct {
+		Metadata struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"metadata"`
+		Containers []struct {
+			Name  string              `json:"name"`
+			Usage corev1.ResourceList `json:"usage"`
+		} `json:"containers"`
+	} `json:"items"`
+}
+
+// podUsage is the resource usage of pod at a point of time
+type podUsage struct {
+	Timestamp   int64
+	CPU         float64
+	Memory      float64
+	CPULimit    float64
+	MemoryLimit float64
+}
+
+func (u podUsage) value(metricName string) (float64, bool) {
+	switch metricName {
+	case consts.MetricCpuUsageRate:
+		if u.CPULimit == 0 {
+			return 0, false
+		}
+		return u.CPU / u.CPULimit, true
+	case consts.MetricMemoryUsageRate:
+		if u.MemoryLimit == 0 {
+			return 0, false
+		}
+		return u.Memory / u.MemoryLimit, true
+	case consts.MetricMemoryUsage:
+		return u.Memory, true
+	default:
+		return 0, false
+	}
+}
+
+// fetchUsageFunc fetch the current usage of pods in namespace, keyed by pod name
+type fetchUsageFunc func(namespace string) (map[string]podUsage, error)
+
+// MetricsServerMetric query metrics of kubernetes jobs from metrics.k8s.io api, for clusters without prometheus.
+// metrics.k8s.io only serves the current usage of pods, so pods of running jobs are sampled periodically and
+// kept in memory for the retention, which serves the sequence and average queries.
+// Only cpu_usage_rate, memory_usage_rate and memory_usage are supported, other metrics return empty results.
+type MetricsServerMetric struct {
+	clusterID  string
+	interval   time.Duration
+	retention  time.Duration
+	fetchUsage fetchUsageFunc
+
+	lock sync.RWMutex
+	// samples contains the sampled usage of pods, keyed by pod name
+	samples map[string][]podUsage
+}
+
+var (
+	metricsServerLock sync.RWMutex
+	// metricsServerMetrics contains the metrics-server backends of running cluster runtimes, keyed by cluster id
+	metricsServerMetrics = make(map[string]*MetricsServerMetric)
+)
+
+// StartMetricsServerMetric starts sampling pods of cluster when the cluster runtime starts, and the sampling is
+// stopped and the samples are dropped when stopCh is closed
+func StartMetricsServerMetric(clusterID string, client kubernetes.Interface, stopCh <-chan struct{}) {
+	m := newMetricsServerMetric(clusterID, newKubeUsageFetcher(client))
+	metricsServerLock.Lock()
+	metricsServerMetrics[clusterID] = m
+	metricsServerLock.Unlock()
+	go func() {
+		m.Run(stopCh)
+		metricsServerLock.Lock()
+		defer metricsServerLock.Unlock()
+		if metricsServerMetrics[clusterID] == m {
+			delete(metricsServerMetrics, clusterID)
+		}
+	}()
+}
+
+// GetMetricsServerMetric return the metrics-server backend of cluster, which exists only if the cluster runtime is running
+func GetMetricsServerMetric(clusterID string) (MetricInterface, error) {
+	metricsServerLock.RLock()
+	defer metricsServerLock.RUnlock()
+	m, ok := metricsServerMetrics[clusterID]
+	if !ok {
+		return nil, fmt.Errorf("pods of cluster[%s] are not sampled, as the cluster runtime is not running", clusterID)
+	}
+	return m, nil
+}
+
+func newMetricsServerMetric(clusterID string, fetchUsage fetchUsageFunc) *MetricsServerMetric {
+	return &MetricsServerMetric{
+		clusterID:  clusterID,
+		interval:   DefaultSampleInterval * time.Second,
+		retention:  DefaultSampleRetention * time.Second,
+		fetchUsage: fetchUsage,
+		samples:    make(map[string][]podUsage),
+	}
+}
+
+func newKubeUsageFetcher(client kubernetes.Interface) fetchUsageFunc {
+	return func(namespace string) (map[string]podUsage, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		data, err := client.Discovery().RESTClient().Get().
+			AbsPath(fmt.Sprintf(podMetricsPathFmt, namespace)).DoRaw(ctx)
+		if err != nil {
+			return nil, err
+		}
+		metricsList := &podMetricsList{}
+		if err = json.Unmarshal(data, metricsList); err != nil {
+			return nil, err
+		}
+		pods, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		limits := make(map[string]corev1.ResourceList)
+		for _, pod := range pods.Items {
+			limit := corev1.ResourceList{}
+			for _, c := range pod.Spec.Containers {
+				addResourceList(limit, c.Resources.Limits)
+			}
+			limits[pod.Name] = limit
+		}
+		now := time.Now().Unix()
+		usages := make(map[string]podUsage)
+		for _, item := range metricsList.Items {
+			usage := corev1.ResourceList{}
+			for _, c := range item.Containers {
+				addResourceList(usage, c.Usage)
+			}
+			limit := limits[item.Metadata.Name]
+			usages[item.Metadata.Name] = podUsage{
+				Timestamp:   now,
+				CPU:         float64(usage.Cpu().MilliValue()) / 1000,
+				Memory:      float64(usage.Memory().Value()),
+				CPULimit:    float64(limit.Cpu().MilliValue()) / 1000,
+				MemoryLimit: float64(limit.Memory().Value()),
+			}
+		}
+		return usages, nil
+	}
+}
+
+func addResourceList(list, add corev1.ResourceList) {
+	for name, quantity := range add {
+		if value, ok := list[name]; !ok {
+			list[name] = quantity.DeepCopy()
+		} else {
+			value.Add(quantity)
+			list[name] = value
+		}
+	}
+}
+
+// Run sample the usage of pods periodically until stopCh is closed
+func (m *MetricsServerMetric) Run(stopCh <-chan struct{}) {
+	log.Infof("start sampling pod metrics of cluster[%s] from metrics-server", m.clusterID)
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		m.sample()
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *MetricsServerMetric) sample() {
+	namespaces := make(map[string]map[string]bool)
+	queueClusters := make(map[string]string)
+	for _, job := range storage.Job.ListJobByStatus(schema.StatusJobRunning) {
+		clusterID, ok := queueClusters[job.QueueID]
+		if !ok {
+			queue, err := storage.Queue.GetQueueByID(job.QueueID)
+			if err != nil {
+				log.Warningf("get queue[%s] of job[%s] failed, err: %v", job.QueueID, job.ID, err)
+				continue
+			}
+			clusterID = queue.ClusterId
+			queueClusters[job.QueueID] = clusterID
+		}
+		if clusterID != m.clusterID {
+			continue
+		}
+		tasks, err := storage.Job.ListByJobID(job.ID)
+		if err != nil {
+			log.Warningf("list tasks of job[%s] failed, err: %v", job.ID, err)
+			continue
+		}
+		for _, task := range tasks {
+			if _, ok = namespaces[task.Namespace]; !ok {
+				namespaces[task.Namespace] = make(map[string]bool)
+			}
+			namespaces[task.Namespace][task.Name] = true
+		}
+	}
+	for namespace, podNames := range namespaces {
+		usages, err := m.fetchUsage(namespace)
+		if err != nil {
+			log.Warningf("fetch pod metrics of cluster[%s] namespace[%s] failed, err: %v", m.clusterID, namespace, err)
+			continue
+		}
+		for podName, usage := range usages {
+			if podNames[podName] {
+				m.addSample(podName, usage)
+			}
+		}
+	}
+	m.expire(time.Now().Add(-m.retention).Unix())
+}
+
+func (m *MetricsServerMetric) addSample(podName string, usage podUsage) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.samples[podName] = append(m.samples[podName], usage)
+}
+
+// expire remove the samples older than deadline
+func (m *MetricsServerMetric) expire(deadline int64) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for podName, usages := range m.samples {
+		idx := sort.Search(len(usages), func(i int) bool {
+			return usages[i].Timestamp >= deadline
+		})
+		if idx == len(usages) {
+			delete(m.samples, podName)
+		} else if idx > 0 {
+			m.samples[podName] = append([]podUsage{}, usages[idx:]...)
+		}
+	}
+}
+
+func (m *MetricsServerMetric) GetJobAvgMetrics(metricName, jobID string, start, end int64) (float64, error) {
+	job, err := storage.Job.GetJobByID(jobID)
+	if err != nil {
+		log.Errorf("job[%s] find error %s", jobID, err.Error())
+		return 0.0, err
+	}
+	start, end, ok := jobTimeRange(job, start, end)
+	if !ok {
+		return 0.0, nil
+	}
+	result, err := m.GetJobSequenceMetrics(metricName, jobID, start, end, int64(m.interval.Seconds()))
+	if err != nil {
+		return 0.0, err
+	}
+	return averageOfValue(result)
+}
+
+func (m *MetricsServerMetric) GetJobSequenceMetrics(metricName, jobID string, start, end, step int64) (model.Value, error) {
+	podNameList, err := listJobPodNames(jobID)
+	if err != nil {
+		return nil, err
+	}
+	return m.queryRange(metricName, podNameList, start, end, step), nil
+}
+
+func (m *MetricsServerMetric) GetQueueAvgMetrics(metricName, queueID string, start, end int64) (float64, error) {
+	result, err := m.GetQueueSequenceMetrics(metricName, queueID, start, end, int64(m.interval.Seconds()))
+	if err != nil {
+		return 0.0, err
+	}
+	return averageOfValue(result)
+}
+
+func (m *MetricsServerMetric) GetQueueSequenceMetrics(metricName, queueID string, start, end, step int64) (model.Value, error) {
+	podNameList, err := listQueuePodNames(queueID, start, end)
+	if err != nil {
+		log.Errorf("queue[%s] list pods error %s", queueID, err.Error())
+		return nil, err
+	}
+	return m.queryRange(metricName, podNameList, start, end, step), nil
+}
+
+// queryRange return the samples of pods in [start, end], samples in the same step are averaged
+func (m *MetricsServerMetric) queryRange(metricName string, podNameList []string, start, end, step int64) model.Matrix {
+	if step <= 0 {
+		step = int64(m.interval.Seconds())
+	}
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	result := model.Matrix{}
+	for _, podName := range podNameList {
+		sums := make(map[int64]float64)
+		counts := make(map[int64]int)
+		for _, usage := range m.samples[podName] {
+			if usage.Timestamp < start || usage.Timestamp > end {
+				continue
+			}
+			value, ok := usage.value(metricName)
+			if !ok {
+				continue
+			}
+			ts := start + (usage.Timestamp-start)/step*step
+			sums[ts] += value
+			counts[ts] += 1
+		}
+		if len(sums) == 0 {
+			continue
+		}
+		values := make([]model.SamplePair, 0, len(sums))
+		for ts, sum := range sums {
+			values = append(values, model.SamplePair{
+				Timestamp: model.TimeFromUnix(ts),
+				Value:     model.SampleValue(sum / float64(counts[ts])),
+			})
+		}
+		sort.Slice(values, func(i, j int) bool {
+			return values[i].Timestamp < values[j].Timestamp
+		})
+		result = append(result, &model.SampleStream{
+			Metric: model.Metric{"pod": model.LabelValue(podName)},
+			Values: values,
+		})
+	}
+	return result
+}
@@ -0,0 +1,143 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package monitor
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/consts"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	pfmodel "github.com/PaddlePaddle/PaddleFlow/pkg/model"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage/driver"
+)
+
+func TestRenderQuery(t *testing.T) {
+	defer func() {
+		_ = SetQueryTemplates(nil)
+	}()
+	query, err := RenderQuery(consts.MetricMemoryUsage, QueryParams{PodNames: "pod1|pod2"})
+	assert.NoError(t, err)
+	assert.Equal(t, "sum(container_memory_working_set_bytes{image!=\"\", pod=~\"pod1|pod2\"}) by (pod)", query)
+
+	query, err = RenderQuery(consts.MetricNetReceiveBytes, QueryParams{PodNames: "pod1"})
+	assert.NoError(t, err)
+	assert.Contains(t, query, "[1m]")
+
+	_, err = RenderQuery("unknown", QueryParams{})
+	assert.Error(t, err)
+
+	err = SetQueryTemplates(map[string]string{consts.MetricMemoryUsage: "mem{pod=~\"{{.PodNames}}\"}"})
+	assert.NoError(t, err)
+	query, err = RenderQuery(consts.MetricMemoryUsage, QueryParams{PodNames: "pod1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "mem{pod=~\"pod1\"}", query)
+
+	err = SetQueryTemplates(map[string]string{consts.MetricMemoryUsage: "{{.PodNames"})
+	assert.Error(t, err)
+	err = SetQueryTemplates(map[string]string{"unknown": "up"})
+	assert.Error(t, err)
+}
+
+func TestInitMetricBackend(t *testing.T) {
+	assert.NoError(t, InitMetricBackend("", nil))
+	assert.Equal(t, MetricBackendPrometheus, MetricBackend())
+	assert.Error(t, InitMetricBackend("unknown", nil))
+	assert.NoError(t, InitMetricBackend(MetricBackendMetricsServer, nil))
+	assert.Equal(t, MetricBackendMetricsServer, MetricBackend())
+	assert.NoError(t, InitMetricBackend(MetricBackendPrometheus, nil))
+}
+
+func TestMetricsServerMetric(t *testing.T) {
+	driver.InitMockDB()
+	cluster := &pfmodel.ClusterInfo{Name: "cluster-1", ClusterType: schema.KubernetesType}
+	assert.NoError(t, storage.Cluster.CreateCluster(cluster))
+	queue := &pfmodel.Queue{Name: "q1", ClusterId: cluster.ID}
+	assert.NoError(t, storage.Queue.CreateQueue(queue))
+	job := &pfmodel.Job{
+		ID:          "job-1",
+		QueueID:     queue.ID,
+		Status:      schema.StatusJobRunning,
+		ActivatedAt: sql.NullTime{Time: time.Now().Add(-time.Hour), Valid: true},
+	}
+	assert.NoError(t, storage.Job.CreateJob(job))
+	for _, name := range []string{"pod-1", "pod-2"} {
+		assert.NoError(t, storage.Job.UpdateTask(&pfmodel.JobTask{ID: name, JobID: job.ID, Namespace: "default", Name: name}))
+	}
+
+	now := time.Now().Unix()
+	m := newMetricsServerMetric(cluster.ID, func(namespace string) (map[string]podUsage, error) {
+		return map[string]podUsage{
+			"pod-1":     {Timestamp: now, CPU: 1, CPULimit: 4, Memory: 100, MemoryLimit: 400},
+			"pod-2":     {Timestamp: now, CPU: 3, CPULimit: 4, Memory: 300, MemoryLimit: 400},
+			"other-pod": {Timestamp: now, CPU: 4, CPULimit: 4, Memory: 400, MemoryLimit: 400},
+		}, nil
+	})
+	m.sample()
+	assert.Len(t, m.samples, 2)
+
+	avg, err := m.GetJobAvgMetrics(consts.MetricCpuUsageRate, job.ID, 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.5, avg)
+
+	value, err := m.GetJobSequenceMetrics(consts.MetricMemoryUsage, job.ID, now-60, now+60, 30)
+	assert.NoError(t, err)
+	assert.Len(t, value.(model.Matrix), 2)
+
+	value, err = m.GetJobSequenceMetrics(consts.MetricGpuUtil, job.ID, now-60, now+60, 30)
+	assert.NoError(t, err)
+	assert.Len(t, value.(model.Matrix), 0)
+
+	avg, err = m.GetQueueAvgMetrics(consts.MetricMemoryUsageRate, queue.ID, now-60, now+60)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.5, avg)
+
+	value, err = m.GetQueueSequenceMetrics(consts.MetricMemoryUsage, queue.ID, now-60, now+60, 30)
+	assert.NoError(t, err)
+	matrix, err := AggregateQueueMatrix(value, consts.MetricMemoryUsage, queue.Name)
+	assert.NoError(t, err)
+	assert.Len(t, matrix, 1)
+	assert.Equal(t, model.SampleValue(400), matrix[0].Values[0].Value)
+
+	m.expire(now + 1)
+	assert.Len(t, m.samples, 0)
+}
+
+func TestStartMetricsServerMetric(t *testing.T) {
+	driver.InitMockDB()
+	_, err := GetMetricsServerMetric("cluster-1")
+	assert.Error(t, err)
+
+	stopCh := make(chan struct{})
+	StartMetricsServerMetric("cluster-1", fake.NewSimpleClientset(), stopCh)
+	m, err := GetMetricsServerMetric("cluster-1")
+	assert.NoError(t, err)
+	assert.NotNil(t, m)
+
+	// samples are dropped when the cluster runtime stops
+	close(stopCh)
+	assert.Eventually(t, func() bool {
+		_, err := GetMetricsServerMetric("cluster-1")
+		return err != nil
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,101 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package monitor
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"text/template"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/consts"
+)
+
+// QueryParams is the data used to render a query template
+type QueryParams struct {
+	// PodNames is a regex of pod names, joined by '|'
+	PodNames string
+	// RateWindow is the range vector selector used by rate(), such as 1m
+	RateWindow string
+}
+
+var defaultQueryTemplates = map[string]string{
+	consts.MetricCpuUsageRate:    QueryCPUUsageRateQl,
+	consts.MetricMemoryUsageRate: QueryMEMUsageRateQl,
+	consts.MetricMemoryUsage:     QueryMEMUsageQl,
+	consts.MetricDiskUsage:       QueryDiskUsageQl,
+	consts.MetricNetReceiveBytes: QueryNetReceiveQl,
+	consts.MetricNetSendBytes:    QueryNetTransmitQl,
+	consts.MetricDiskReadRate:    QueryDiskReadQl,
+	consts.MetricDiskWriteRate:   QueryDiskWriteQl,
+	consts.MetricGpuUtil:         QueryGpuUtilQl,
+	consts.MetricGpuMemoryUtil:   QueryGpuMemUtilQl,
+	consts.MetricGpuMemoryUsage:  QueryGpuMemUsageQl,
+}
+
+var (
+	queryTemplates    map[string]*template.Template
+	queryTemplateLock sync.RWMutex
+)
+
+func init() {
+	if err := SetQueryTemplates(nil); err != nil {
+		panic(err)
+	}
+}
+
+// SetQueryTemplates parses the built-in query templates, and the overrides keyed by metric name replace them
+func SetQueryTemplates(overrides map[string]string) error {
+	templates := make(map[string]*template.Template, len(defaultQueryTemplates))
+	for metricName, text := range defaultQueryTemplates {
+		if override, ok := overrides[metricName]; ok {
+			text = override
+		}
+		tmpl, err := template.New(metricName).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return fmt.Errorf("parse query template of metric[%s] failed, err: %v", metricName, err)
+		}
+		templates[metricName] = tmpl
+	}
+	for metricName := range overrides {
+		if _, ok := defaultQueryTemplates[metricName]; !ok {
+			return fmt.Errorf("metric[%s] is not support", metricName)
+		}
+	}
+	queryTemplateLock.Lock()
+	defer queryTemplateLock.Unlock()
+	queryTemplates = templates
+	return nil
+}
+
+// RenderQuery render the query of metric with params
+func RenderQuery(metricName string, params QueryParams) (string, error) {
+	queryTemplateLock.RLock()
+	tmpl, ok := queryTemplates[metricName]
+	queryTemplateLock.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("metric[%s] is not support", metricName)
+	}
+	if params.RateWindow == "" {
+		params.RateWindow = DefaultRateWindow
+	}
+	buf := bytes.Buffer{}
+	if err := tmpl.Execute(&buf, params); err != nil {
+		return "", fmt.Errorf("render query of metric[%s] failed, err: %v", metricName, err)
+	}
+	return buf.String(), nil
+}
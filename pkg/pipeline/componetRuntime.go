@@ -21,11 +21,14 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/tracing"
 	. "github.com/PaddlePaddle/PaddleFlow/pkg/pipeline/common"
 )
 
@@ -256,6 +259,11 @@ func (crt *baseComponentRuntime) updateStatus(status RuntimeStatus) error {
 		return err
 	}
 
+	tracing.AddKeyEvent(crt.runID, "component status transition", time.Now(),
+		attribute.String("paddleflow.component", crt.getFullName()),
+		attribute.String("paddleflow.component_type", crt.getComponent().GetType()),
+		attribute.String("paddleflow.status_from", string(crt.status)),
+		attribute.String("paddleflow.status_to", string(status)))
 	crt.status = status
 
 	if isRuntimeFinallyStatus(crt.status) {
@@ -26,6 +26,7 @@ import (
 	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/config"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/tracing"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/metrics"
 	. "github.com/PaddlePaddle/PaddleFlow/pkg/pipeline/common"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/trace_logger"
@@ -350,6 +351,9 @@ func (srt *StepRuntime) updateJob(forCacheFingerprint bool) error {
 		for atfName, atfValue := range srt.GetArtifacts().Output {
 			newEnvs[GetOutputArtifactEnvName(atfName)] = GetArtifactMountPath(srt.runConfig.mainFS, atfValue)
 		}
+
+		// job of step is traced as child of the run span
+		tracing.InjectEnv(tracing.ContextForKey(context.Background(), srt.runID), newEnvs)
 	}
 
 	srt.job.Update(srt.getWorkFlowStep().Command, params, newEnvs, &artifacts)
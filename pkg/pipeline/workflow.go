@@ -338,6 +338,10 @@ func (bwf *BaseWorkflow) checkFS() error {
 		return fmt.Errorf("[sub_path] in [main_fs] should not start with '/'")
 	}
 
+	if _, snapshot := schema.ParseFsSnapshotName(bwf.Source.FsOptions.MainFS.Name); snapshot != "" {
+		return fmt.Errorf("[main_fs] can not be a snapshot, as artifacts are written to it")
+	}
+
 	if bwf.Source.FsOptions.MainFS.Name != "" {
 		bwf.Source.FsOptions.MainFS.ID = common.ID(bwf.Extra[WfExtraInfoKeyFSUserName], bwf.Source.FsOptions.MainFS.Name)
 	}
@@ -392,7 +396,11 @@ func (bwf *BaseWorkflow) processFsByUserName(compMap map[string]schema.Component
 				if strings.HasPrefix(mount.SubPath, "/") {
 					return fmt.Errorf("[sub_path] in [extra_fs] should not start with '/'")
 				}
-				mount.ID = common.ID(userName, mount.Name)
+				mount.ID = common.FsOrSnapshotID(userName, mount.Name)
+				// fs_name@snapshot references the snapshot of fs, which is read-only
+				if _, snapshot := schema.ParseFsSnapshotName(mount.Name); snapshot != "" {
+					mount.ReadOnly = true
+				}
 
 				fsNameChecker[mount.Name] = 1
 				step.ExtraFS[i] = mount
@@ -401,7 +409,7 @@ func (bwf *BaseWorkflow) processFsByUserName(compMap map[string]schema.Component
 				if scope.Name == "" {
 					return fmt.Errorf("[fs_name] in fs_scope must not be empty")
 				}
-				scope.ID = common.ID(userName, scope.Name)
+				scope.ID = common.FsOrSnapshotID(userName, scope.Name)
 
 				// 检查FsScope中的FsName是否都在FsMount中
 				if _, ok := fsNameChecker[scope.Name]; !ok {
@@ -22,9 +22,12 @@ import (
 	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+
 	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/config"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/tracing"
 	mr "github.com/PaddlePaddle/PaddleFlow/pkg/metrics"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/trace_logger"
 )
@@ -114,6 +117,7 @@ func (wfr *WorkflowRuntime) Start() {
 	} else {
 		wfr.status = common.StatusRunRunning
 		wfr.startTime = time.Now().Format("2006-01-02 15:04:05")
+		wfr.startRunSpan("start")
 
 		wfr.callback("begin to running, update status to running")
 
@@ -132,6 +136,7 @@ func (wfr *WorkflowRuntime) Resume(entryPointView *schema.DagView, postProcessVi
 	wfr.status = runStatus
 
 	wfr.startTime = entryPointView.StartTime
+	wfr.startRunSpan("resume")
 
 	// 1、如果 ep 未处于终态， 则需要重启ep
 	if !isRuntimeFinallyStatus(entryPointView.Status) {
@@ -206,6 +211,7 @@ func (wfr *WorkflowRuntime) Restart(entryPointView *schema.DagView,
 
 	wfr.status = common.StatusRunRunning
 	wfr.startTime = time.Now().Format("2006-01-02 15:04:05")
+	wfr.startRunSpan("restart")
 	msg := fmt.Sprintf("restart run[%s], and update status to [%s]", wfr.runID, wfr.status)
 	wfr.logger.Infof(msg)
 	wfr.callback(msg)
@@ -287,11 +293,21 @@ func (wfr *WorkflowRuntime) Listen() {
 		}
 
 		if wfr.IsCompleted() {
+			tracing.EndKeySpan(wfr.runID, attribute.String("paddleflow.run_status", wfr.status))
 			return
 		}
 	}
 }
 
+// startRunSpan starts the span of run, which is bound to run id, so that status transitions and trace logs
+// of run are recorded as span events, and jobs of steps are traced as children of it
+func (wfr *WorkflowRuntime) startRunSpan(operation string) {
+	tracing.StartKeySpan(context.Background(), wfr.runID, "WorkflowRuntime."+operation,
+		attribute.String("paddleflow.run_id", wfr.runID),
+		attribute.String("paddleflow.workflow", wfr.WorkflowSource.Name),
+		attribute.String("paddleflow.user_name", wfr.userName))
+}
+
 func (wfr *WorkflowRuntime) IsCompleted() bool {
 	return wfr.status == common.StatusRunSucceeded ||
 		wfr.status == common.StatusRunFailed ||
@@ -466,6 +482,8 @@ func (wfr *WorkflowRuntime) updateStatusAccordingComponentStatus() string {
 }
 
 func (wfr *WorkflowRuntime) callback(msg string) {
+	tracing.AddKeyEvent(wfr.runID, "run status update", time.Now(),
+		attribute.String("paddleflow.run_status", wfr.status), attribute.String("message", msg))
 	extra := map[string]interface{}{
 		common.WfEventKeyRunID:     wfr.runID,
 		common.WfEventKeyStatus:    wfr.status,
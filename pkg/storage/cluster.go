@@ -148,6 +148,18 @@ func (cs *ClusterStore) UpdateCluster(clusterId string, clusterInfo *model.Clust
 	return nil
 }
 
+// UpdateClusterStatus updates status and its reason of cluster, the empty reason is updated too
+func (cs *ClusterStore) UpdateClusterStatus(clusterId, status, statusReason string) error {
+	log.Debugf("start to update cluster status. clusterId:%s, status:%s, reason:%s", clusterId, status, statusReason)
+	err := cs.db.Table("cluster_info").Where("id = ?", clusterId).
+		Updates(map[string]interface{}{"status": status, "status_reason": statusReason}).Error
+	if err != nil {
+		log.Errorf("update cluster status failed. clusterId:%s, error:%s", clusterId, err.Error())
+		return err
+	}
+	return nil
+}
+
 func (cs *ClusterStore) ActiveClusters() []model.ClusterInfo {
 	tx := cs.db.Table("cluster_info").Where("deleted_at = '' ")
 
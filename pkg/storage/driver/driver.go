@@ -31,6 +31,7 @@ import (
 
 	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/models"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/common/config"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/tracing"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/storage"
 )
@@ -66,6 +67,14 @@ func InitStorage(conf *config.StorageConfig, logLevel string) error {
 	if err := setSqlDBConns(conf); err != nil {
 		return err
 	}
+	dbSystem := driver
+	if dbSystem != Mysql {
+		dbSystem = Sqlite
+	}
+	if err := storage.DB.Use(tracing.NewGormPlugin(dbSystem)); err != nil {
+		log.Errorf("register tracing plugin for database failed, err: %v", err)
+		return err
+	}
 
 	log.Debugf("InitStorage success.dbConf:%v", conf)
 	storage.InitStores(storage.DB)
@@ -241,6 +250,8 @@ func createDatabaseTables(db *gorm.DB) error {
 	return db.AutoMigrate(
 		&model.Pipeline{},
 		&model.PipelineVersion{},
+		&model.JobTemplate{},
+		&model.JobTemplateVersion{},
 		&models.Schedule{},
 		&models.RunCache{},
 		&model.ArtifactEvent{},
@@ -254,10 +265,15 @@ func createDatabaseTables(db *gorm.DB) error {
 		&model.Job{},
 		&model.JobTask{},
 		&model.JobLabel{},
+		&model.JobUsage{},
 		&model.ClusterInfo{},
 		&model.Image{},
 		&model.FileSystem{},
 		&model.Link{},
+		&model.FsSnapshot{},
+		&model.FsQuota{},
+		&model.FsSyncTask{},
+		&model.FsAuditLog{},
 		&model.FSCacheConfig{},
 		&model.FSCache{},
 	)
@@ -19,9 +19,11 @@ package storage
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"gorm.io/gorm"
 
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
 	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
 )
 
@@ -50,6 +52,23 @@ func (fss *FilesystemStore) GetFileSystemWithFsID(fsID string) (model.FileSystem
 	return fileSystem, result.Error
 }
 
+// GetFileSystemOrSnapshot gets file system with id, the id of a ready snapshot returns the read-only file system of it
+func (fss *FilesystemStore) GetFileSystemOrSnapshot(id string) (model.FileSystem, error) {
+	fsID, snapshotName := schema.ParseFsSnapshotID(id)
+	fs, err := fss.GetFileSystemWithFsID(fsID)
+	if err != nil || snapshotName == "" {
+		return fs, err
+	}
+	snapshot, err := fss.GetFsSnapshot(id)
+	if err != nil {
+		return model.FileSystem{}, err
+	}
+	if snapshot.Status != model.FsSnapshotStatusReady {
+		return model.FileSystem{}, fmt.Errorf("snapshot[%s] is %s", id, snapshot.Status)
+	}
+	return model.SnapshotFileSystem(fs, snapshot), nil
+}
+
 func (fss *FilesystemStore) DeleteFileSystem(tx *gorm.DB, id string) error {
 	if tx == nil {
 		tx = fss.db
@@ -131,6 +150,183 @@ func (fss *FilesystemStore) GetLinkWithFsIDAndPath(fsID, fsPath string) ([]model
 	return links, result.Error
 }
 
+// ============================================================= table fs_snapshot ============================================================= //
+
+func (fss *FilesystemStore) CreateFsSnapshot(snapshot *model.FsSnapshot) error {
+	return fss.db.Create(snapshot).Error
+}
+
+func (fss *FilesystemStore) GetFsSnapshot(id string) (model.FsSnapshot, error) {
+	var snapshot model.FsSnapshot
+	result := fss.db.Where(&model.FsSnapshot{Model: model.Model{ID: id}}).First(&snapshot)
+	return snapshot, result.Error
+}
+
+func (fss *FilesystemStore) UpdateFsSnapshot(snapshot *model.FsSnapshot) error {
+	return fss.db.Model(&model.FsSnapshot{}).Where(fmt.Sprintf(QueryEqualWithParam, ID), snapshot.ID).
+		Select("status", "message", "file_count", "size").Updates(snapshot).Error
+}
+
+// TouchFsSnapshot updates the update time of the snapshot being created, which shows it is still being taken
+func (fss *FilesystemStore) TouchFsSnapshot(id string) error {
+	return fss.db.Model(&model.FsSnapshot{}).Where(fmt.Sprintf(QueryEqualWithParam, ID), id).
+		Where("status = ?", model.FsSnapshotStatusCreating).Update(UpdatedAt, time.Now()).Error
+}
+
+// FailStaleFsSnapshots marks the creating snapshots which are not updated since staleBefore as failed
+func (fss *FilesystemStore) FailStaleFsSnapshots(staleBefore time.Time, message string) (int64, error) {
+	result := fss.db.Model(&model.FsSnapshot{}).Where("status = ?", model.FsSnapshotStatusCreating).
+		Where(fmt.Sprintf("%s < ?", UpdatedAt), staleBefore).
+		Updates(map[string]interface{}{"status": model.FsSnapshotStatusFailed, "message": message})
+	return result.RowsAffected, result.Error
+}
+
+func (fss *FilesystemStore) DeleteFsSnapshot(id string) error {
+	return fss.db.Where(fmt.Sprintf(QueryEqualWithParam, ID), id).Delete(&model.FsSnapshot{}).Error
+}
+
+// DeleteFsSnapshotWithFsID delete all snapshot records of the file system
+func (fss *FilesystemStore) DeleteFsSnapshotWithFsID(tx *gorm.DB, fsID string) error {
+	if tx == nil {
+		tx = fss.db
+	}
+	return tx.Where(fmt.Sprintf(QueryEqualWithParam, FsID), fsID).Delete(&model.FsSnapshot{}).Error
+}
+
+// ListFsSnapshot get snapshots of the file system sort by create_at desc
+func (fss *FilesystemStore) ListFsSnapshot(fsID string) ([]model.FsSnapshot, error) {
+	var snapshots []model.FsSnapshot
+	result := fss.db.Where(&model.FsSnapshot{FsID: fsID}).Order(fmt.Sprintf(" %s %s ", CreatedAt, DESC)).Find(&snapshots)
+	return snapshots, result.Error
+}
+
+// ============================================================= table fs_quota ============================================================= //
+
+func (fss *FilesystemStore) CreateFsQuota(quota *model.FsQuota) error {
+	return fss.db.Create(quota).Error
+}
+
+func (fss *FilesystemStore) UpdateFsQuota(quota *model.FsQuota) error {
+	return fss.db.Model(&model.FsQuota{}).Where(fmt.Sprintf(QueryEqualWithParam, ID), quota.ID).
+		Select("max_bytes", "max_inodes").Updates(quota).Error
+}
+
+func (fss *FilesystemStore) GetFsQuota(fsID, path string) (model.FsQuota, error) {
+	var quota model.FsQuota
+	result := fss.db.Where(&model.FsQuota{FsID: fsID, Path: path}).First(&quota)
+	return quota, result.Error
+}
+
+func (fss *FilesystemStore) DeleteFsQuota(fsID, path string) error {
+	return fss.db.Where(&model.FsQuota{FsID: fsID, Path: path}).Delete(&model.FsQuota{}).Error
+}
+
+// DeleteFsQuotaWithFsID delete all quota records of the file system
+func (fss *FilesystemStore) DeleteFsQuotaWithFsID(tx *gorm.DB, fsID string) error {
+	if tx == nil {
+		tx = fss.db
+	}
+	return tx.Where(fmt.Sprintf(QueryEqualWithParam, FsID), fsID).Delete(&model.FsQuota{}).Error
+}
+
+// ListFsQuota get quotas of the file system sort by path
+func (fss *FilesystemStore) ListFsQuota(fsID string) ([]model.FsQuota, error) {
+	var quotas []model.FsQuota
+	result := fss.db.Where(&model.FsQuota{FsID: fsID}).Order("path").Find(&quotas)
+	return quotas, result.Error
+}
+
+// ============================================================= table fs_sync_task ============================================================= //
+
+func (fss *FilesystemStore) CreateFsSyncTask(task *model.FsSyncTask) error {
+	return fss.db.Create(task).Error
+}
+
+func (fss *FilesystemStore) GetFsSyncTask(id string) (model.FsSyncTask, error) {
+	var task model.FsSyncTask
+	result := fss.db.Where(&model.FsSyncTask{Model: model.Model{ID: id}}).First(&task)
+	return task, result.Error
+}
+
+// UpdateFsSyncTask updates the status and progress of task
+func (fss *FilesystemStore) UpdateFsSyncTask(task *model.FsSyncTask) error {
+	return fss.db.Model(&model.FsSyncTask{}).Where(fmt.Sprintf(QueryEqualWithParam, ID), task.ID).
+		Select("status", "message", "total", "copied", "skipped", "deleted", "failed", "copied_bytes",
+			"checkpoint", "diff").Updates(task).Error
+}
+
+// UpdateFsSyncTaskProgress updates the progress of task, and the status is kept since it may be changed by others
+func (fss *FilesystemStore) UpdateFsSyncTaskProgress(task *model.FsSyncTask) error {
+	return fss.db.Model(&model.FsSyncTask{}).Where(fmt.Sprintf(QueryEqualWithParam, ID), task.ID).
+		Select("total", "copied", "skipped", "deleted", "failed", "copied_bytes", "checkpoint", "diff").
+		Updates(task).Error
+}
+
+// FinishFsSyncTask updates the status and progress of the running task, returns false if the task is not running
+// in db, such as stopped through other servers
+func (fss *FilesystemStore) FinishFsSyncTask(task *model.FsSyncTask) (bool, error) {
+	result := fss.db.Model(&model.FsSyncTask{}).Where(fmt.Sprintf(QueryEqualWithParam, ID), task.ID).
+		Where("status = ?", model.FsSyncTaskStatusRunning).
+		Select("status", "message", "total", "copied", "skipped", "deleted", "failed", "copied_bytes",
+			"checkpoint", "diff").Updates(task)
+	return result.RowsAffected == 1, result.Error
+}
+
+// ClaimFsSyncTask marks the unfinished task running if it is not updated since staleBefore, returns false if the
+// task is updated by others, so that the task is claimed by only one server
+func (fss *FilesystemStore) ClaimFsSyncTask(id string, staleBefore time.Time) (bool, error) {
+	result := fss.db.Model(&model.FsSyncTask{}).Where(fmt.Sprintf(QueryEqualWithParam, ID), id).
+		Where("status IN ?", []string{model.FsSyncTaskStatusPending, model.FsSyncTaskStatusRunning}).
+		Where(fmt.Sprintf("%s < ?", UpdatedAt), staleBefore).
+		Updates(map[string]interface{}{"status": model.FsSyncTaskStatusRunning, "message": ""})
+	return result.RowsAffected == 1, result.Error
+}
+
+func (fss *FilesystemStore) DeleteFsSyncTask(id string) error {
+	return fss.db.Where(fmt.Sprintf(QueryEqualWithParam, ID), id).Delete(&model.FsSyncTask{}).Error
+}
+
+// ListFsSyncTask get sync tasks sort by create_at desc, empty userName or status means all
+func (fss *FilesystemStore) ListFsSyncTask(userName string, status []string) ([]model.FsSyncTask, error) {
+	var tasks []model.FsSyncTask
+	tx := fss.db.Where(&model.FsSyncTask{UserName: userName})
+	if len(status) != 0 {
+		tx = tx.Where("status IN ?", status)
+	}
+	result := tx.Order(fmt.Sprintf(" %s %s ", CreatedAt, DESC)).Find(&tasks)
+	return tasks, result.Error
+}
+
+// ============================================================= table fs_audit_log ============================================================= //
+
+// CreateFsAuditLogs appends audit logs in one batch, audit logs are never updated or deleted
+func (fss *FilesystemStore) CreateFsAuditLogs(logs []model.FsAuditLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	return fss.db.Create(&logs).Error
+}
+
+// ListFsAuditLog get at most maxKeys audit logs after pk sort by pk
+func (fss *FilesystemStore) ListFsAuditLog(pk int64, maxKeys int, filter model.FsAuditLogFilter) ([]model.FsAuditLog, error) {
+	var logs []model.FsAuditLog
+	tx := fss.db.Where("pk > ?", pk).Where(&model.FsAuditLog{FsID: filter.FsID, UserName: filter.UserName, JobID: filter.JobID})
+	if len(filter.Operations) != 0 {
+		tx = tx.Where("operation IN ?", filter.Operations)
+	}
+	if !filter.StartTime.IsZero() {
+		tx = tx.Where("created_at >= ?", filter.StartTime)
+	}
+	if !filter.EndTime.IsZero() {
+		tx = tx.Where("created_at < ?", filter.EndTime)
+	}
+	if maxKeys > 0 {
+		tx = tx.Limit(maxKeys)
+	}
+	result := tx.Order("pk").Find(&logs)
+	return logs, result.Error
+}
+
 // ============================================================= table fs_cache_config ============================================================= //
 
 func (fss *FilesystemStore) CreateFSCacheConfig(fsCacheConfig *model.FSCacheConfig) error {
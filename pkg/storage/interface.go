@@ -17,6 +17,8 @@ limitations under the License.
 package storage
 
 import (
+	"time"
+
 	log "github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 
@@ -39,6 +41,9 @@ var (
 	Job        JobStoreInterface
 	Image      ImageStoreInterface
 	Artifact   ArtifactStoreInterface
+	JobUsage   JobUsageStoreInterface
+
+	JobTemplate JobTemplateStoreInterface
 )
 
 func InitStores(db *gorm.DB) {
@@ -53,6 +58,8 @@ func InitStores(db *gorm.DB) {
 	Queue = newQueueStore(db)
 	Image = newImageStore(db)
 	Artifact = newRunArtifactStore(db)
+	JobUsage = newJobUsageStore(db)
+	JobTemplate = newJobTemplateStore(db)
 }
 
 type ArtifactStoreInterface interface {
@@ -90,6 +97,7 @@ type ClusterStoreInterface interface {
 	GetClusterById(clusterId string) (model.ClusterInfo, error)
 	DeleteCluster(clusterName string) error
 	UpdateCluster(clusterId string, clusterInfo *model.ClusterInfo) error
+	UpdateClusterStatus(clusterId, status, statusReason string) error
 	ActiveClusters() []model.ClusterInfo
 }
 
@@ -121,10 +129,28 @@ type PipelineStoreInterface interface {
 	DeletePipelineVersion(logEntry *log.Entry, pipelineID string, pipelineVersionID string) error
 }
 
+type JobTemplateStoreInterface interface {
+	// job_template
+	CreateJobTemplate(logEntry *log.Entry, template *model.JobTemplate, version *model.JobTemplateVersion) (string, string, error)
+	UpdateJobTemplate(logEntry *log.Entry, template *model.JobTemplate, version *model.JobTemplateVersion) (string, string, error)
+	GetJobTemplateByID(id string) (model.JobTemplate, error)
+	GetJobTemplate(name, userName string) (model.JobTemplate, error)
+	ListJobTemplate(pk int64, maxKeys int, userFilter, nameFilter []string) ([]model.JobTemplate, error)
+	IsLastJobTemplatePk(pk int64, userFilter, nameFilter []string) (bool, error)
+	DeleteJobTemplate(logEntry *log.Entry, id string) error
+	// job_template_version
+	ListJobTemplateVersion(templateID string) ([]model.JobTemplateVersion, error)
+	GetJobTemplateVersion(templateID, versionID string) (model.JobTemplateVersion, error)
+	GetLastJobTemplateVersion(templateID string) (model.JobTemplateVersion, error)
+	CountJobTemplateVersion(templateID string) (int64, error)
+	DeleteJobTemplateVersion(logEntry *log.Entry, templateID, versionID string) error
+}
+
 type FileSystemStoreInterface interface {
 	// filesystem
 	CreatFileSystem(fs *model.FileSystem) error
 	GetFileSystemWithFsID(fsID string) (model.FileSystem, error)
+	GetFileSystemOrSnapshot(id string) (model.FileSystem, error)
 	DeleteFileSystem(tx *gorm.DB, id string) error
 	ListFileSystem(limit int, userName, marker, fsName string) ([]model.FileSystem, error)
 	GetSimilarityAddressList(fsType string, ips []string) ([]model.FileSystem, error)
@@ -136,6 +162,33 @@ type FileSystemStoreInterface interface {
 	DeleteLinkWithFsIDAndFsPath(fsID, fsPath string) error
 	ListLink(limit int, marker, fsID string) ([]model.Link, error)
 	GetLinkWithFsIDAndPath(fsID, fsPath string) ([]model.Link, error)
+	// fs_snapshot
+	CreateFsSnapshot(snapshot *model.FsSnapshot) error
+	GetFsSnapshot(id string) (model.FsSnapshot, error)
+	UpdateFsSnapshot(snapshot *model.FsSnapshot) error
+	TouchFsSnapshot(id string) error
+	FailStaleFsSnapshots(staleBefore time.Time, message string) (int64, error)
+	DeleteFsSnapshot(id string) error
+	DeleteFsSnapshotWithFsID(tx *gorm.DB, fsID string) error
+	ListFsSnapshot(fsID string) ([]model.FsSnapshot, error)
+	CreateFsQuota(quota *model.FsQuota) error
+	UpdateFsQuota(quota *model.FsQuota) error
+	GetFsQuota(fsID, path string) (model.FsQuota, error)
+	DeleteFsQuota(fsID, path string) error
+	DeleteFsQuotaWithFsID(tx *gorm.DB, fsID string) error
+	ListFsQuota(fsID string) ([]model.FsQuota, error)
+	// fs_sync_task
+	CreateFsSyncTask(task *model.FsSyncTask) error
+	GetFsSyncTask(id string) (model.FsSyncTask, error)
+	UpdateFsSyncTask(task *model.FsSyncTask) error
+	UpdateFsSyncTaskProgress(task *model.FsSyncTask) error
+	FinishFsSyncTask(task *model.FsSyncTask) (bool, error)
+	ClaimFsSyncTask(id string, staleBefore time.Time) (bool, error)
+	DeleteFsSyncTask(id string) error
+	ListFsSyncTask(userName string, status []string) ([]model.FsSyncTask, error)
+	// fs_audit_log
+	CreateFsAuditLogs(logs []model.FsAuditLog) error
+	ListFsAuditLog(pk int64, maxKeys int, filter model.FsAuditLogFilter) ([]model.FsAuditLog, error)
 	// fs_cache_config
 	CreateFSCacheConfig(fsCacheConfig *model.FSCacheConfig) error
 	UpdateFSCacheConfig(fsCacheConfig *model.FSCacheConfig) error
@@ -183,11 +236,14 @@ type JobStoreInterface interface {
 	DeleteJob(jobID string) error
 	UpdateJobStatus(jobId, errMessage string, newStatus schema.JobStatus) error
 	UpdateJobConfig(jobId string, conf *schema.Conf) error
+	UpdateJobMembers(jobID string, members []schema.Member) error
 	UpdateJob(jobID string, status schema.JobStatus, runtimeInfo, runtimeStatus interface{}, message string) (schema.JobStatus, error)
 	ListQueueJob(queueID string, status []schema.JobStatus) []model.Job
 	ListQueueInitJob(queueID string) []model.Job
 	ListJobsByQueueIDsAndStatus(queueIDs []string, status schema.JobStatus) []model.Job
 	ListJobByStatus(status schema.JobStatus) []model.Job
+	ListUnscopedJobByIDs(jobIDs []string) ([]model.Job, error)
+	ListQueueJobByTimeRange(queueID string, start, end time.Time) ([]model.Job, error)
 	GetJobsByRunID(runID string, jobID string) ([]model.Job, error)
 	ListJobByUpdateTime(updateTime string) ([]model.Job, error)
 	ListJobByParentID(parentID string) ([]model.Job, error)
@@ -201,6 +257,12 @@ type JobStoreInterface interface {
 	ListByJobID(jobID string) ([]model.JobTask, error)
 }
 
+type JobUsageStoreInterface interface {
+	RecordJobUsage(job model.Job, status schema.JobStatus, finishedAt time.Time) error
+	ListJobUsage(jobID string) ([]model.JobUsage, error)
+	SummarizeUsage(groupBy, startDate, endDate string, filter UsageFilter) ([]model.UsageSummary, error)
+}
+
 type ImageStoreInterface interface {
 	CreateImage(logEntry *log.Entry, image *model.Image) error
 	ListImageIDsByFsID(logEntry *log.Entry, fsID string) ([]string, error)
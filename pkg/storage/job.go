@@ -133,6 +133,19 @@ func (js *JobStore) UpdateJobConfig(jobId string, conf *schema.Conf) error {
 	return nil
 }
 
+func (js *JobStore) UpdateJobMembers(jobID string, members []schema.Member) error {
+	membersJSON, err := json.Marshal(members)
+	if err != nil {
+		return err
+	}
+	log.Infof("update job %s members [%v]", jobID, members)
+	tx := js.db.Model(&model.Job{}).Where("id = ?", jobID).Where("deleted_at = ''").UpdateColumn("members", membersJSON)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
 func jobStatusTransition(jobID string, preStatus, newStatus schema.JobStatus, msg string) (schema.JobStatus, string) {
 	if schema.IsImmutableJobStatus(preStatus) {
 		return preStatus, ""
@@ -188,6 +201,15 @@ func (js *JobStore) UpdateJob(jobID string, status schema.JobStatus, runtimeInfo
 		log.Errorf("update job failed, err %v", tx.Error)
 		return "", tx.Error
 	}
+	if !schema.IsImmutableJobStatus(job.Status) && schema.IsImmutableJobStatus(updatedJob.Status) {
+		if updatedJob.ActivatedAt.Valid {
+			job.ActivatedAt = updatedJob.ActivatedAt
+		}
+		// usage accounting should not block the update of job status
+		if err = JobUsage.RecordJobUsage(job, updatedJob.Status, time.Now()); err != nil {
+			log.Errorf("record usage of job %s failed, err %v", jobID, err)
+		}
+	}
 	return updatedJob.Status, nil
 }
 
@@ -236,6 +258,33 @@ func (js *JobStore) ListJobByStatus(status schema.JobStatus) []model.Job {
 	return jobs
 }
 
+// ListUnscopedJobByIDs list jobs by ids, including the deleted jobs
+func (js *JobStore) ListUnscopedJobByIDs(jobIDs []string) ([]model.Job, error) {
+	var jobs []model.Job
+	if len(jobIDs) == 0 {
+		return jobs, nil
+	}
+	if err := js.db.Table("job").Where("id IN (?)", jobIDs).Find(&jobs).Error; err != nil {
+		log.Errorf("list jobs %v failed, err: %s", jobIDs, err.Error())
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// ListQueueJobByTimeRange list the jobs of queue which were running in time range [start, end]
+func (js *JobStore) ListQueueJobByTimeRange(queueID string, start, end time.Time) ([]model.Job, error) {
+	var jobs []model.Job
+	err := js.db.Table("job").Where("queue_id = ?", queueID).
+		Where("activated_at IS NOT NULL").Where("activated_at <= ?", end).
+		Where("status = ? OR updated_at >= ?", schema.StatusJobRunning, start).
+		Where("deleted_at = ''").Find(&jobs).Error
+	if err != nil {
+		log.Errorf("list jobs in queue %s from %s to %s failed, err: %s", queueID, start, end, err.Error())
+		return nil, err
+	}
+	return jobs, nil
+}
+
 func (js *JobStore) GetJobsByRunID(runID string, jobID string) ([]model.Job, error) {
 	var jobList []model.Job
 	query := js.db.Table("job").Where("id like ?", "job-"+runID+"-%").Where("deleted_at = ''")
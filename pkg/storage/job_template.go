@@ -0,0 +1,175 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"fmt"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/apiserver/common"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
+)
+
+type JobTemplateStore struct {
+	db *gorm.DB
+}
+
+func newJobTemplateStore(db *gorm.DB) *JobTemplateStore {
+	return &JobTemplateStore{db: db}
+}
+
+func (ts *JobTemplateStore) CreateJobTemplate(logEntry *log.Entry, template *model.JobTemplate,
+	version *model.JobTemplateVersion) (string, string, error) {
+	logEntry.Debugf("begin create job template: %+v & version: %+v", template, version)
+	err := ts.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.JobTemplate{}).Create(template).Error; err != nil {
+			logEntry.Errorf("create job template failed. template:%+v, error:%v", template, err)
+			return err
+		}
+		// update ID by pk
+		template.ID = common.PrefixTemplate + fmt.Sprintf("%06d", template.Pk)
+		if err := tx.Model(&model.JobTemplate{}).Where("pk = ?", template.Pk).Update("id", template.ID).Error; err != nil {
+			logEntry.Errorf("backfilling templateID to job template[%d] failed. error:%v", template.Pk, err)
+			return err
+		}
+		return createJobTemplateVersion(logEntry, tx, template.ID, version)
+	})
+	return template.ID, version.ID, err
+}
+
+func (ts *JobTemplateStore) UpdateJobTemplate(logEntry *log.Entry, template *model.JobTemplate,
+	version *model.JobTemplateVersion) (string, string, error) {
+	logEntry.Debugf("begin update job template: %+v & version: %+v", template, version)
+	err := ts.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.JobTemplate{}).Where("pk = ?", template.Pk).Update("desc", template.Desc).Error; err != nil {
+			logEntry.Errorf("update desc to job template[%d] failed. error:%v", template.Pk, err)
+			return err
+		}
+		return createJobTemplateVersion(logEntry, tx, template.ID, version)
+	})
+	return template.ID, version.ID, err
+}
+
+// createJobTemplateVersion creates version with increasing id, and the ids of deleted versions are not reused
+func createJobTemplateVersion(logEntry *log.Entry, tx *gorm.DB, templateID string, version *model.JobTemplateVersion) error {
+	var versionCount int64
+	if err := tx.Unscoped().Model(&model.JobTemplateVersion{}).Where("template_id = ?", templateID).
+		Count(&versionCount).Error; err != nil {
+		logEntry.Errorf("count job template version failed. templateID[%s]. error:%v", templateID, err)
+		return err
+	}
+	version.ID = strconv.FormatInt(versionCount+1, 10)
+	version.TemplateID = templateID
+	if err := tx.Model(&model.JobTemplateVersion{}).Create(version).Error; err != nil {
+		logEntry.Errorf("create job template version failed. version:%+v, error:%v", version, err)
+		return err
+	}
+	logEntry.Infof("created job template version, templateID[%s], versionID[%s]", templateID, version.ID)
+	return nil
+}
+
+func (ts *JobTemplateStore) GetJobTemplateByID(id string) (model.JobTemplate, error) {
+	var template model.JobTemplate
+	result := ts.db.Model(&model.JobTemplate{}).Where("id = ?", id).Last(&template)
+	return template, result.Error
+}
+
+func (ts *JobTemplateStore) GetJobTemplate(name, userName string) (model.JobTemplate, error) {
+	var template model.JobTemplate
+	result := ts.db.Model(&model.JobTemplate{}).Where(&model.JobTemplate{Name: name, UserName: userName}).Last(&template)
+	return template, result.Error
+}
+
+func (ts *JobTemplateStore) ListJobTemplate(pk int64, maxKeys int, userFilter, nameFilter []string) ([]model.JobTemplate, error) {
+	tx := ts.db.Model(&model.JobTemplate{}).Where("pk > ?", pk)
+	if len(userFilter) > 0 {
+		tx = tx.Where("user_name IN (?)", userFilter)
+	}
+	if len(nameFilter) > 0 {
+		tx = tx.Where("name IN (?)", nameFilter)
+	}
+	if maxKeys > 0 {
+		tx = tx.Limit(maxKeys)
+	}
+	var templates []model.JobTemplate
+	if err := tx.Find(&templates).Error; err != nil {
+		log.Errorf("list job template failed. pk:%d, maxKeys:%d, Filters: user{%v}, name{%v}. error:%v",
+			pk, maxKeys, userFilter, nameFilter, err)
+		return []model.JobTemplate{}, err
+	}
+	return templates, nil
+}
+
+func (ts *JobTemplateStore) IsLastJobTemplatePk(pk int64, userFilter, nameFilter []string) (bool, error) {
+	tx := ts.db.Model(&model.JobTemplate{})
+	if len(userFilter) > 0 {
+		tx = tx.Where("user_name IN (?)", userFilter)
+	}
+	if len(nameFilter) > 0 {
+		tx = tx.Where("name IN (?)", nameFilter)
+	}
+	template := model.JobTemplate{}
+	if err := tx.Last(&template).Error; err != nil {
+		return false, err
+	}
+	return pk == template.Pk, nil
+}
+
+// DeleteJobTemplate deletes job template and all of its versions
+func (ts *JobTemplateStore) DeleteJobTemplate(logEntry *log.Entry, id string) error {
+	logEntry.Debugf("delete job template: %s", id)
+	return ts.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("template_id = ?", id).Delete(&model.JobTemplateVersion{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&model.JobTemplate{}).Error
+	})
+}
+
+func (ts *JobTemplateStore) ListJobTemplateVersion(templateID string) ([]model.JobTemplateVersion, error) {
+	var versions []model.JobTemplateVersion
+	result := ts.db.Model(&model.JobTemplateVersion{}).Where("template_id = ?", templateID).Find(&versions)
+	return versions, result.Error
+}
+
+func (ts *JobTemplateStore) GetJobTemplateVersion(templateID, versionID string) (model.JobTemplateVersion, error) {
+	version := model.JobTemplateVersion{}
+	result := ts.db.Model(&model.JobTemplateVersion{}).Where("template_id = ?", templateID).
+		Where("id = ?", versionID).Last(&version)
+	return version, result.Error
+}
+
+func (ts *JobTemplateStore) GetLastJobTemplateVersion(templateID string) (model.JobTemplateVersion, error) {
+	version := model.JobTemplateVersion{}
+	result := ts.db.Model(&model.JobTemplateVersion{}).Where("template_id = ?", templateID).Last(&version)
+	return version, result.Error
+}
+
+func (ts *JobTemplateStore) CountJobTemplateVersion(templateID string) (int64, error) {
+	var count int64
+	result := ts.db.Model(&model.JobTemplateVersion{}).Where("template_id = ?", templateID).Count(&count)
+	return count, result.Error
+}
+
+func (ts *JobTemplateStore) DeleteJobTemplateVersion(logEntry *log.Entry, templateID, versionID string) error {
+	logEntry.Debugf("delete job template[%s] version[%s]", templateID, versionID)
+	return ts.db.Where("template_id = ?", templateID).Where("id = ?", versionID).
+		Delete(&model.JobTemplateVersion{}).Error
+}
@@ -0,0 +1,196 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/resources"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
+)
+
+const (
+	UsageGroupByUser    = "user"
+	UsageGroupByQueue   = "queue"
+	UsageGroupByCluster = "cluster"
+
+	// UsageDateLayout is the layout of date in usage records
+	UsageDateLayout = "2006-01-02"
+
+	gibibyte = 1024 * 1024 * 1024
+)
+
+// GPUResourceNames are names of scalar resources accounted as gpu, which are matched exactly
+var GPUResourceNames = []string{"nvidia.com/gpu"}
+
+var usageGroupByColumns = map[string]string{
+	UsageGroupByUser:    "user_name",
+	UsageGroupByQueue:   "queue_name",
+	UsageGroupByCluster: "cluster_name",
+}
+
+// UsageFilter filters the usage records, empty fields are ignored
+type UsageFilter struct {
+	UserName    string
+	QueueName   string
+	ClusterName string
+}
+
+type JobUsageStore struct {
+	db *gorm.DB
+}
+
+func newJobUsageStore(db *gorm.DB) *JobUsageStore {
+	return &JobUsageStore{db: db}
+}
+
+// RecordJobUsage calculate the resources consumed by job from its activated time to finishedAt, and save them by day
+func (us *JobUsageStore) RecordJobUsage(job model.Job, status schema.JobStatus, finishedAt time.Time) error {
+	if !job.ActivatedAt.Valid {
+		log.Debugf("job[%s] is not activated, skip recording usage", job.ID)
+		return nil
+	}
+	res, err := JobRequestResource(job)
+	if err != nil {
+		log.Errorf("get request resource of job[%s] failed, err: %v", job.ID, err)
+		return err
+	}
+	usages := buildJobUsages(job, res, job.ActivatedAt.Time, finishedAt)
+	if len(usages) == 0 {
+		return nil
+	}
+
+	queue, err := Queue.GetQueueByID(job.QueueID)
+	if err != nil {
+		log.Warningf("get queue[%s] of job[%s] failed, err: %v", job.QueueID, job.ID, err)
+	}
+	for idx := range usages {
+		usages[idx].Status = string(status)
+		usages[idx].QueueName = queue.Name
+		usages[idx].ClusterID = queue.ClusterId
+		usages[idx].ClusterName = queue.ClusterName
+	}
+	tx := us.db.Table(model.JobUsageTableName).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "job_id"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"status", "duration", "cpu_seconds", "memory_seconds",
+			"gpu_seconds"}),
+	}).Create(&usages)
+	if tx.Error != nil {
+		log.Errorf("record usage of job[%s] failed, err: %v", job.ID, tx.Error)
+		return tx.Error
+	}
+	return nil
+}
+
+// ListJobUsage list the daily usage records of job
+func (us *JobUsageStore) ListJobUsage(jobID string) ([]model.JobUsage, error) {
+	var usages []model.JobUsage
+	err := us.db.Table(model.JobUsageTableName).Where("job_id = ?", jobID).Order("date").Find(&usages).Error
+	if err != nil {
+		log.Errorf("list usage of job[%s] failed, err: %v", jobID, err)
+		return nil, err
+	}
+	return usages, nil
+}
+
+// SummarizeUsage roll up the usage records in date range [startDate, endDate] by day and groupBy
+func (us *JobUsageStore) SummarizeUsage(groupBy, startDate, endDate string, filter UsageFilter) ([]model.UsageSummary, error) {
+	column, ok := usageGroupByColumns[groupBy]
+	if !ok {
+		return nil, fmt.Errorf("group by[%s] is not support, only support %s, %s and %s", groupBy,
+			UsageGroupByUser, UsageGroupByQueue, UsageGroupByCluster)
+	}
+	tx := us.db.Table(model.JobUsageTableName).
+		Select(fmt.Sprintf("date, %s as name, count(distinct job_id) as job_count, sum(duration) as duration, "+
+			"sum(cpu_seconds) as cpu_seconds, sum(memory_seconds) as memory_seconds, sum(gpu_seconds) as gpu_seconds", column)).
+		Where("date >= ?", startDate).Where("date <= ?", endDate)
+	if filter.UserName != "" {
+		tx = tx.Where("user_name = ?", filter.UserName)
+	}
+	if filter.QueueName != "" {
+		tx = tx.Where("queue_name = ?", filter.QueueName)
+	}
+	if filter.ClusterName != "" {
+		tx = tx.Where("cluster_name = ?", filter.ClusterName)
+	}
+	var summaries []model.UsageSummary
+	err := tx.Group(fmt.Sprintf("date, %s", column)).Order(fmt.Sprintf("date, %s", column)).Scan(&summaries).Error
+	if err != nil {
+		log.Errorf("summarize usage by %s from %s to %s failed, err: %v", groupBy, startDate, endDate, err)
+		return nil, err
+	}
+	return summaries, nil
+}
+
+// JobRequestResource sum the flavour of members multiplied by replicas, and fall back to the flavour of job
+func JobRequestResource(job model.Job) (*resources.Resource, error) {
+	res := resources.EmptyResource()
+	for _, member := range job.Members {
+		memberRes, err := resources.NewResourceFromMap(member.Flavour.ToMap())
+		if err != nil {
+			return nil, err
+		}
+		if member.Replicas > 1 {
+			memberRes.Multi(member.Replicas)
+		}
+		res.Add(memberRes)
+	}
+	if len(job.Members) == 0 && job.Config != nil {
+		return resources.NewResourceFromMap(job.Config.Flavour.ToMap())
+	}
+	return res, nil
+}
+
+// buildJobUsages split the running time [start, end] of job by day, and calculate the usage of each day
+func buildJobUsages(job model.Job, res *resources.Resource, start, end time.Time) []model.JobUsage {
+	cpu := float64(res.CPU()) / 1000
+	memory := float64(res.Memory()) / gibibyte
+	gpu := 0.0
+	for _, name := range GPUResourceNames {
+		gpu += float64(res.Resource()[name])
+	}
+
+	usages := make([]model.JobUsage, 0)
+	for segStart := start; segStart.Before(end); {
+		year, month, day := segStart.Date()
+		nextDay := time.Date(year, month, day+1, 0, 0, 0, 0, segStart.Location())
+		segEnd := end
+		if nextDay.Before(end) {
+			segEnd = nextDay
+		}
+		duration := segEnd.Sub(segStart).Seconds()
+		usages = append(usages, model.JobUsage{
+			JobID:         job.ID,
+			Date:          segStart.Format(UsageDateLayout),
+			UserName:      job.UserName,
+			QueueID:       job.QueueID,
+			JobType:       job.Type,
+			Duration:      duration,
+			CPUSeconds:    cpu * duration,
+			MemorySeconds: memory * duration,
+			GPUSeconds:    gpu * duration,
+		})
+		segStart = segEnd
+	}
+	return usages
+}
@@ -0,0 +1,125 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/resources"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/schema"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
+)
+
+func TestBuildJobUsages(t *testing.T) {
+	res, err := resources.NewResourceFromMap(map[string]string{
+		resources.ResCPU:        "2",
+		resources.ResMemory:     "4Gi",
+		"nvidia.com/gpu":        "1",
+		"nvidia.com/gpu-memory": "16",
+	})
+	assert.NoError(t, err)
+	job := model.Job{ID: "job-1", UserName: "user1", QueueID: "queue-1"}
+
+	start := time.Date(2022, 10, 1, 23, 0, 0, 0, time.Local)
+	end := time.Date(2022, 10, 2, 1, 0, 0, 0, time.Local)
+	usages := buildJobUsages(job, res, start, end)
+	assert.Len(t, usages, 2)
+	assert.Equal(t, "2022-10-01", usages[0].Date)
+	assert.Equal(t, 3600.0, usages[0].Duration)
+	assert.Equal(t, 7200.0, usages[0].CPUSeconds)
+	assert.Equal(t, 4*3600.0, usages[0].MemorySeconds)
+	assert.Equal(t, 3600.0, usages[0].GPUSeconds)
+	assert.Equal(t, "2022-10-02", usages[1].Date)
+
+	usages = buildJobUsages(job, res, end, end)
+	assert.Len(t, usages, 0)
+}
+
+func TestJobRequestResource(t *testing.T) {
+	job := model.Job{
+		Members: []schema.Member{
+			{Replicas: 2, Conf: schema.Conf{Flavour: schema.Flavour{ResourceInfo: schema.ResourceInfo{CPU: "1", Mem: "1Gi"}}}},
+			{Replicas: 1, Conf: schema.Conf{Flavour: schema.Flavour{ResourceInfo: schema.ResourceInfo{CPU: "2", Mem: "2Gi"}}}},
+		},
+	}
+	res, err := JobRequestResource(job)
+	assert.NoError(t, err)
+	assert.Equal(t, resources.Quantity(4000), res.CPU())
+
+	job = model.Job{Config: &schema.Conf{Flavour: schema.Flavour{ResourceInfo: schema.ResourceInfo{CPU: "3", Mem: "1Gi"}}}}
+	res, err = JobRequestResource(job)
+	assert.NoError(t, err)
+	assert.Equal(t, resources.Quantity(3000), res.CPU())
+}
+
+func initMockJobUsageDB(t *testing.T) {
+	initMockDB()
+	assert.NoError(t, DB.AutoMigrate(&model.Job{}, &model.JobUsage{}))
+}
+
+func TestJobUsage(t *testing.T) {
+	initMockJobUsageDB(t)
+	cluster := &model.ClusterInfo{Name: "cluster1", ClusterType: schema.KubernetesType}
+	assert.NoError(t, Cluster.CreateCluster(cluster))
+	queue := &model.Queue{Name: "queue1", ClusterId: cluster.ID}
+	assert.NoError(t, Queue.CreateQueue(queue))
+
+	finishedAt := time.Now()
+	job := model.Job{
+		ID:          "job-1",
+		UserName:    "user1",
+		QueueID:     queue.ID,
+		Type:        string(schema.TypeSingle),
+		Status:      schema.StatusJobRunning,
+		ActivatedAt: sql.NullTime{Time: finishedAt.Add(-time.Minute), Valid: true},
+		Config:      &schema.Conf{Flavour: schema.Flavour{ResourceInfo: schema.ResourceInfo{CPU: "2", Mem: "1Gi"}}},
+	}
+	assert.NoError(t, Job.CreateJob(&job))
+	_, err := Job.UpdateJob(job.ID, schema.StatusJobSucceeded, nil, nil, "")
+	assert.NoError(t, err)
+
+	usages, err := JobUsage.ListJobUsage(job.ID)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, usages)
+	assert.Equal(t, "queue1", usages[0].QueueName)
+	assert.Equal(t, "cluster1", usages[0].ClusterName)
+	assert.Equal(t, string(schema.StatusJobSucceeded), usages[0].Status)
+
+	// record again should update the existing records
+	assert.NoError(t, JobUsage.RecordJobUsage(job, schema.StatusJobFailed, finishedAt))
+	usages2, err := JobUsage.ListJobUsage(job.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, len(usages), len(usages2))
+
+	today := finishedAt.Format(UsageDateLayout)
+	summaries, err := JobUsage.SummarizeUsage(UsageGroupByQueue, "2000-01-01", today, UsageFilter{UserName: "user1"})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, summaries)
+	assert.Equal(t, "queue1", summaries[0].Name)
+	assert.Equal(t, int64(1), summaries[0].JobCount)
+
+	summaries, err = JobUsage.SummarizeUsage(UsageGroupByUser, "2000-01-01", today, UsageFilter{UserName: "user2"})
+	assert.NoError(t, err)
+	assert.Empty(t, summaries)
+
+	_, err = JobUsage.SummarizeUsage("unknown", "2000-01-01", today, UsageFilter{})
+	assert.Error(t, err)
+}
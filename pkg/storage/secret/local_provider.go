@@ -0,0 +1,121 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const LocalProviderName = "local"
+
+// LocalProvider encrypts data keys by the master keys from config or local file
+type LocalProvider struct {
+	currentKeyID string
+	masterKeys   map[string][]byte
+}
+
+// NewLocalProvider creates local provider, keys in MasterKeyFile override the keys in MasterKeys with the same id
+func NewLocalProvider(conf *Config) (KeyProvider, error) {
+	encodedKeys := make(map[string]string)
+	for keyID, key := range conf.MasterKeys {
+		encodedKeys[keyID] = key
+	}
+	if conf.MasterKeyFile != "" {
+		fileKeys, err := readMasterKeyFile(conf.MasterKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		for keyID, key := range fileKeys {
+			encodedKeys[keyID] = key
+		}
+	}
+
+	lp := &LocalProvider{
+		currentKeyID: conf.CurrentKeyID,
+		masterKeys:   make(map[string][]byte, len(encodedKeys)),
+	}
+	for keyID, encodedKey := range encodedKeys {
+		key, err := base64.StdEncoding.DecodeString(encodedKey)
+		if err != nil {
+			return nil, fmt.Errorf("decode master key %s failed, err: %v", keyID, err)
+		}
+		if len(key) != dataKeySize {
+			return nil, fmt.Errorf("the size of master key %s is %d, and it must be %d", keyID, len(key), dataKeySize)
+		}
+		lp.masterKeys[keyID] = key
+	}
+	if _, find := lp.masterKeys[lp.currentKeyID]; !find {
+		return nil, fmt.Errorf("current master key %s is not found", lp.currentKeyID)
+	}
+	return lp, nil
+}
+
+// readMasterKeyFile reads master keys from file, each line is formatted as <keyID>:<base64 key>
+func readMasterKeyFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read master key file %s failed, err: %v", path, err)
+	}
+	keys := make(map[string]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		items := strings.SplitN(line, ":", 2)
+		if len(items) != 2 {
+			return nil, fmt.Errorf("invalid line in master key file %s, it should be <keyID>:<base64 key>", path)
+		}
+		keys[strings.TrimSpace(items[0])] = strings.TrimSpace(items[1])
+	}
+	return keys, nil
+}
+
+func (lp *LocalProvider) CurrentKeyID() string {
+	return lp.currentKeyID
+}
+
+func (lp *LocalProvider) EncryptKey(keyID string, dataKey []byte) ([]byte, error) {
+	masterKey, find := lp.masterKeys[keyID]
+	if !find {
+		return nil, fmt.Errorf("master key %s is not found", keyID)
+	}
+	nonce, ciphertext, err := seal(masterKey, dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return append(nonce, ciphertext...), nil
+}
+
+func (lp *LocalProvider) DecryptKey(keyID string, encryptedKey []byte) ([]byte, error) {
+	masterKey, find := lp.masterKeys[keyID]
+	if !find {
+		return nil, fmt.Errorf("master key %s is not found", keyID)
+	}
+	gcm, err := newGCM(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(encryptedKey) < gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid encrypted data key")
+	}
+	nonceSize := gcm.NonceSize()
+	return open(masterKey, encryptedKey[:nonceSize], encryptedKey[nonceSize:])
+}
@@ -0,0 +1,255 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package secret provides envelope encryption for secrets stored in database, such as cluster credentials
+// and filesystem properties. Each secret is encrypted by a random data key, and the data key is encrypted
+// by the master key of a KeyProvider, which can be the local provider or a KMS plug-in.
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// EnvelopePrefix is the prefix of encrypted secrets, secrets without it are regarded as plain text
+	EnvelopePrefix = "pfenc:v1:"
+
+	dataKeySize = 32
+)
+
+type Config struct {
+	// Provider is the name of key provider, such as local. Secrets are stored in plain text if it is empty
+	Provider string `yaml:"provider"`
+	// CurrentKeyID is the id of master key to encrypt secrets, and the other keys are only used to decrypt
+	CurrentKeyID string `yaml:"currentKeyID"`
+	// MasterKeys is the base64 encoded 32 bytes master keys of local provider, keyed by key id
+	MasterKeys map[string]string `yaml:"masterKeys"`
+	// MasterKeyFile is the file of master keys for local provider, each line is formatted as <keyID>:<base64 key>
+	MasterKeyFile string `yaml:"masterKeyFile"`
+	// Options is the options of KMS plug-in, such as endpoint and credentials
+	Options map[string]string `yaml:"options"`
+}
+
+// KeyProvider protects data keys with master keys, it is implemented by local provider or KMS plug-ins
+type KeyProvider interface {
+	// CurrentKeyID returns the id of master key used to encrypt new data keys
+	CurrentKeyID() string
+	// EncryptKey encrypts data key by master key
+	EncryptKey(keyID string, dataKey []byte) ([]byte, error)
+	// DecryptKey decrypts data key by master key
+	DecryptKey(keyID string, encryptedKey []byte) ([]byte, error)
+}
+
+// ProviderFactory creates key provider with config
+type ProviderFactory func(conf *Config) (KeyProvider, error)
+
+var (
+	factoryLock sync.RWMutex
+	factories   = map[string]ProviderFactory{
+		LocalProviderName: NewLocalProvider,
+	}
+
+	// provider is the key provider of server, secrets are not encrypted when it is nil
+	provider KeyProvider
+)
+
+// RegisterProvider registers KMS plug-in by name, which can be used in Config.Provider
+func RegisterProvider(name string, factory ProviderFactory) {
+	factoryLock.Lock()
+	defer factoryLock.Unlock()
+	factories[name] = factory
+}
+
+// Init creates key provider by config, and secrets are stored in plain text when provider is not set
+func Init(conf *Config) error {
+	if conf == nil || conf.Provider == "" {
+		log.Warningf("secret provider is not set, secrets are stored in plain text")
+		provider = nil
+		return nil
+	}
+	factoryLock.RLock()
+	factory, find := factories[conf.Provider]
+	factoryLock.RUnlock()
+	if !find {
+		return fmt.Errorf("secret provider %s is not registered", conf.Provider)
+	}
+	p, err := factory(conf)
+	if err != nil {
+		log.Errorf("create secret provider %s failed, err: %v", conf.Provider, err)
+		return err
+	}
+	provider = p
+	log.Infof("init secret provider %s with key %s", conf.Provider, p.CurrentKeyID())
+	return nil
+}
+
+// SetProvider sets key provider directly, which is used by unit test
+func SetProvider(p KeyProvider) {
+	provider = p
+}
+
+// envelope is the encrypted secret and its encrypted data key
+type envelope struct {
+	KeyID        string `json:"kid"`
+	EncryptedKey []byte `json:"ek"`
+	Nonce        []byte `json:"n"`
+	Ciphertext   []byte `json:"c"`
+}
+
+// IsEncrypted returns true if value is an encrypted envelope
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, EnvelopePrefix)
+}
+
+// Encrypt encrypts value with a new data key, value is returned as it is when provider is not set,
+// or value is empty or already encrypted
+func Encrypt(value string) (string, error) {
+	if provider == nil || value == "" || IsEncrypted(value) {
+		return value, nil
+	}
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+	keyID := provider.CurrentKeyID()
+	encryptedKey, err := provider.EncryptKey(keyID, dataKey)
+	if err != nil {
+		return "", fmt.Errorf("encrypt data key by master key %s failed, err: %v", keyID, err)
+	}
+	nonce, ciphertext, err := seal(dataKey, []byte(value))
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(envelope{
+		KeyID:        keyID,
+		EncryptedKey: encryptedKey,
+		Nonce:        nonce,
+		Ciphertext:   ciphertext,
+	})
+	if err != nil {
+		return "", err
+	}
+	return EnvelopePrefix + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// Decrypt decrypts the encrypted envelope, and plain text value is returned as it is
+func Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	env, err := parseEnvelope(value)
+	if err != nil {
+		return "", err
+	}
+	if provider == nil {
+		return "", fmt.Errorf("secret provider is not set, cannot decrypt secret by key %s", env.KeyID)
+	}
+	dataKey, err := provider.DecryptKey(env.KeyID, env.EncryptedKey)
+	if err != nil {
+		return "", fmt.Errorf("decrypt data key by master key %s failed, err: %v", env.KeyID, err)
+	}
+	plaintext, err := open(dataKey, env.Nonce, env.Ciphertext)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// NeedReEncrypt returns true if value is not encrypted by current master key, which should be re-encrypted
+// after master key is rotated
+func NeedReEncrypt(value string) bool {
+	if provider == nil || value == "" {
+		return false
+	}
+	if !IsEncrypted(value) {
+		return true
+	}
+	env, err := parseEnvelope(value)
+	if err != nil {
+		return false
+	}
+	return env.KeyID != provider.CurrentKeyID()
+}
+
+func parseEnvelope(value string) (*envelope, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, EnvelopePrefix))
+	if err != nil {
+		return nil, fmt.Errorf("decode secret envelope failed, err: %v", err)
+	}
+	env := &envelope{}
+	if err = json.Unmarshal(data, env); err != nil {
+		return nil, fmt.Errorf("unmarshal secret envelope failed, err: %v", err)
+	}
+	return env, nil
+}
+
+// seal encrypts plaintext by AES-GCM
+func seal(key, plaintext []byte) ([]byte, []byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, nil, err
+	}
+	return nonce, gcm.Seal(nil, nonce, plaintext, nil), nil
+}
+
+// open decrypts ciphertext by AES-GCM
+func open(key, nonce, ciphertext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce size %d", len(nonce))
+	}
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// ReEncrypt decrypts value and encrypts it by current master key, ok is false if value need not be re-encrypted
+func ReEncrypt(value string) (newValue string, ok bool, err error) {
+	if !NeedReEncrypt(value) {
+		return value, false, nil
+	}
+	plaintext, err := Decrypt(value)
+	if err != nil {
+		return "", false, err
+	}
+	newValue, err = Encrypt(plaintext)
+	if err != nil {
+		return "", false, err
+	}
+	return newValue, true, nil
+}
@@ -0,0 +1,142 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testMasterKey(b byte) string {
+	return base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(b), dataKeySize)))
+}
+
+func TestEncryptAndDecrypt(t *testing.T) {
+	defer SetProvider(nil)
+	// secrets are kept in plain text without provider
+	assert.NoError(t, Init(&Config{}))
+	value, err := Encrypt("kubeconfig")
+	assert.NoError(t, err)
+	assert.Equal(t, "kubeconfig", value)
+
+	assert.NoError(t, Init(&Config{
+		Provider:     LocalProviderName,
+		CurrentKeyID: "key-1",
+		MasterKeys:   map[string]string{"key-1": testMasterKey('a')},
+	}))
+	encrypted, err := Encrypt("kubeconfig")
+	assert.NoError(t, err)
+	assert.True(t, IsEncrypted(encrypted))
+	assert.NotContains(t, encrypted, "kubeconfig")
+	assert.False(t, NeedReEncrypt(encrypted))
+	// encrypted value is not encrypted twice
+	again, err := Encrypt(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, encrypted, again)
+
+	plaintext, err := Decrypt(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, "kubeconfig", plaintext)
+	// plain text value is returned as it is
+	plaintext, err = Decrypt("legacy")
+	assert.NoError(t, err)
+	assert.Equal(t, "legacy", plaintext)
+	assert.True(t, NeedReEncrypt("legacy"))
+
+	// rotate master key with local file, the old key is still used to decrypt
+	keyFile := filepath.Join(t.TempDir(), "master.key")
+	content := "# master keys\nkey-2:" + testMasterKey('b') + "\n"
+	assert.NoError(t, os.WriteFile(keyFile, []byte(content), 0600))
+	assert.NoError(t, Init(&Config{
+		Provider:      LocalProviderName,
+		CurrentKeyID:  "key-2",
+		MasterKeys:    map[string]string{"key-1": testMasterKey('a')},
+		MasterKeyFile: keyFile,
+	}))
+	assert.True(t, NeedReEncrypt(encrypted))
+	rotated, ok, err := ReEncrypt(encrypted)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.False(t, NeedReEncrypt(rotated))
+	plaintext, err = Decrypt(rotated)
+	assert.NoError(t, err)
+	assert.Equal(t, "kubeconfig", plaintext)
+
+	// the removed master key cannot decrypt
+	assert.NoError(t, Init(&Config{
+		Provider:      LocalProviderName,
+		CurrentKeyID:  "key-2",
+		MasterKeyFile: keyFile,
+	}))
+	_, err = Decrypt(encrypted)
+	assert.Error(t, err)
+}
+
+func TestInitProvider(t *testing.T) {
+	defer SetProvider(nil)
+	testCases := []struct {
+		name    string
+		conf    *Config
+		wantErr bool
+	}{
+		{
+			name:    "provider not registered",
+			conf:    &Config{Provider: "kms"},
+			wantErr: true,
+		},
+		{
+			name:    "current key not found",
+			conf:    &Config{Provider: LocalProviderName, CurrentKeyID: "key-1"},
+			wantErr: true,
+		},
+		{
+			name: "invalid key size",
+			conf: &Config{Provider: LocalProviderName, CurrentKeyID: "key-1",
+				MasterKeys: map[string]string{"key-1": base64.StdEncoding.EncodeToString([]byte("short"))}},
+			wantErr: true,
+		},
+		{
+			name:    "master key file not found",
+			conf:    &Config{Provider: LocalProviderName, CurrentKeyID: "key-1", MasterKeyFile: "/not/exist"},
+			wantErr: true,
+		},
+		{
+			name: "registered plug-in",
+			conf: &Config{Provider: "fake-kms", CurrentKeyID: "key-1",
+				Options: map[string]string{"key-1": testMasterKey('c')}},
+		},
+	}
+	RegisterProvider("fake-kms", func(conf *Config) (KeyProvider, error) {
+		return NewLocalProvider(&Config{CurrentKeyID: conf.CurrentKeyID, MasterKeys: conf.Options})
+	})
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Init(tc.conf)
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
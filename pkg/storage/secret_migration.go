@@ -0,0 +1,122 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"encoding/json"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage/secret"
+)
+
+// rawSecretRecord is the raw column of secrets, which is scanned without model hooks
+type rawSecretRecord struct {
+	ID    string
+	Value string
+}
+
+// ReEncryptSecrets re-encrypts cluster credentials and sensitive properties of file systems and links by current
+// master key, which is used after master key is rotated, or encryption is enabled on existing database.
+// It returns the number of updated records.
+func ReEncryptSecrets(db *gorm.DB) (int, error) {
+	clusterCount, err := reEncryptClusterCredentials(db)
+	if err != nil {
+		return clusterCount, err
+	}
+	fsCount, err := reEncryptProperties(db, model.FileSystemTableName)
+	if err != nil {
+		return clusterCount + fsCount, err
+	}
+	linkCount, err := reEncryptProperties(db, model.LinkTableName)
+	return clusterCount + fsCount + linkCount, err
+}
+
+func reEncryptClusterCredentials(db *gorm.DB) (int, error) {
+	var records []rawSecretRecord
+	if err := db.Table("cluster_info").Select("id, credential as value").Scan(&records).Error; err != nil {
+		log.Errorf("list cluster credentials failed, err: %v", err)
+		return 0, err
+	}
+	count := 0
+	for _, record := range records {
+		credential, ok, err := secret.ReEncrypt(record.Value)
+		if err != nil {
+			log.Errorf("re-encrypt credential of cluster %s failed, err: %v", record.ID, err)
+			return count, err
+		}
+		if !ok {
+			continue
+		}
+		if err = db.Table("cluster_info").Where("id = ?", record.ID).UpdateColumn("credential", credential).Error; err != nil {
+			log.Errorf("update credential of cluster %s failed, err: %v", record.ID, err)
+			return count, err
+		}
+		count++
+	}
+	log.Infof("re-encrypt credentials of %d clusters", count)
+	return count, nil
+}
+
+// reEncryptProperties re-encrypts the sensitive properties in table of file system or link
+func reEncryptProperties(db *gorm.DB, table string) (int, error) {
+	var records []rawSecretRecord
+	if err := db.Table(table).Select("id, properties as value").Scan(&records).Error; err != nil {
+		log.Errorf("list properties of %s failed, err: %v", table, err)
+		return 0, err
+	}
+	count := 0
+	for _, record := range records {
+		if record.Value == "" {
+			continue
+		}
+		properties := make(map[string]string)
+		if err := json.Unmarshal([]byte(record.Value), &properties); err != nil {
+			log.Errorf("unmarshal properties of %s %s failed, err: %v", table, record.ID, err)
+			return count, err
+		}
+		updated := false
+		for _, key := range model.SensitiveProperties {
+			value, ok, err := secret.ReEncrypt(properties[key])
+			if err != nil {
+				log.Errorf("re-encrypt property %s of %s %s failed, err: %v", key, table, record.ID, err)
+				return count, err
+			}
+			if ok {
+				properties[key] = value
+				updated = true
+			}
+		}
+		if !updated {
+			continue
+		}
+		propertiesJson, err := json.Marshal(properties)
+		if err != nil {
+			return count, err
+		}
+		if err = db.Table(table).Where("id = ?", record.ID).
+			UpdateColumn("properties", string(propertiesJson)).Error; err != nil {
+			log.Errorf("update properties of %s %s failed, err: %v", table, record.ID, err)
+			return count, err
+		}
+		count++
+	}
+	log.Infof("re-encrypt properties of %d records in %s", count, table)
+	return count, nil
+}
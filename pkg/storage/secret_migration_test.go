@@ -0,0 +1,119 @@
+/*
+Copyright (c) 2022 PaddlePaddle Authors. All Rights Reserve.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/model"
+	"github.com/PaddlePaddle/PaddleFlow/pkg/storage/secret"
+)
+
+func initSecretProvider(t *testing.T, currentKeyID string) {
+	masterKeys := map[string]string{
+		"key-1": base64.StdEncoding.EncodeToString([]byte(strings.Repeat("a", 32))),
+		"key-2": base64.StdEncoding.EncodeToString([]byte(strings.Repeat("b", 32))),
+	}
+	err := secret.Init(&secret.Config{
+		Provider:     secret.LocalProviderName,
+		CurrentKeyID: currentKeyID,
+		MasterKeys:   masterKeys,
+	})
+	assert.NoError(t, err)
+}
+
+func rawColumn(t *testing.T, table, column, id string) string {
+	var value string
+	err := DB.Table(table).Select(column).Where("id = ?", id).Row().Scan(&value)
+	assert.NoError(t, err)
+	return value
+}
+
+func TestReEncryptSecrets(t *testing.T) {
+	initMockDB()
+	defer secret.SetProvider(nil)
+
+	// secrets created before encryption is enabled are in plain text
+	cluster := &model.ClusterInfo{Model: model.Model{ID: "cluster-1"}, Name: "cluster-1", Credential: "kubeconfig"}
+	assert.NoError(t, Cluster.CreateCluster(cluster))
+	fs := &model.FileSystem{Model: model.Model{ID: "fs-root-s3"}, Name: "s3", UserName: "root",
+		PropertiesMap: map[string]string{"accessKey": "ak", "secretKey": "sk", "bucket": "data"}}
+	assert.NoError(t, Filesystem.CreatFileSystem(fs))
+	link := &model.Link{Model: model.Model{ID: "link-1"}, FsID: "fs-root-s3", FsPath: "/link", UserName: "root",
+		PropertiesMap: map[string]string{"accessKey": "link-ak", "secretKey": "link-sk", "bucket": "link-data"}}
+	assert.NoError(t, Filesystem.CreateLink(link))
+	assert.Equal(t, "kubeconfig", rawColumn(t, "cluster_info", "credential", "cluster-1"))
+
+	// secrets are encrypted on save, and decrypted on find
+	initSecretProvider(t, "key-1")
+	cluster2 := &model.ClusterInfo{Model: model.Model{ID: "cluster-2"}, Name: "cluster-2", Credential: "kubeconfig2"}
+	assert.NoError(t, Cluster.CreateCluster(cluster2))
+	assert.Equal(t, "kubeconfig2", cluster2.Credential)
+	assert.True(t, secret.IsEncrypted(rawColumn(t, "cluster_info", "credential", "cluster-2")))
+	clusterInfo, err := Cluster.GetClusterById("cluster-2")
+	assert.NoError(t, err)
+	assert.Equal(t, "kubeconfig2", clusterInfo.Credential)
+	clusterInfo.Credential = "kubeconfig3"
+	assert.NoError(t, Cluster.UpdateCluster(clusterInfo.ID, &clusterInfo))
+	assert.True(t, secret.IsEncrypted(rawColumn(t, "cluster_info", "credential", "cluster-2")))
+	clusterInfo, err = Cluster.GetClusterById("cluster-2")
+	assert.NoError(t, err)
+	assert.Equal(t, "kubeconfig3", clusterInfo.Credential)
+
+	// migrate plain text secrets
+	count, err := ReEncryptSecrets(DB)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+	assert.True(t, secret.IsEncrypted(rawColumn(t, "cluster_info", "credential", "cluster-1")))
+	properties := rawColumn(t, "filesystem", "properties", "fs-root-s3")
+	assert.NotContains(t, properties, "\"sk\"")
+	assert.Contains(t, properties, "\"data\"")
+	fsInfo, err := Filesystem.GetFileSystemWithFsID("fs-root-s3")
+	assert.NoError(t, err)
+	assert.Equal(t, "sk", fsInfo.PropertiesMap["secretKey"])
+	linkProperties := rawColumn(t, "link", "properties", "link-1")
+	assert.NotContains(t, linkProperties, "\"link-sk\"")
+	assert.Contains(t, linkProperties, "\"link-data\"")
+	links, err := Filesystem.FsNameLinks("fs-root-s3")
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(links)) {
+		assert.Equal(t, "link-sk", links[0].PropertiesMap["secretKey"])
+	}
+
+	// links created after encryption is enabled are encrypted on save
+	link2 := &model.Link{Model: model.Model{ID: "link-2"}, FsID: "fs-root-s3", FsPath: "/link2", UserName: "root",
+		PropertiesMap: map[string]string{"secretKey": "link-sk2"}}
+	assert.NoError(t, Filesystem.CreateLink(link2))
+	assert.Equal(t, "link-sk2", link2.PropertiesMap["secretKey"])
+	assert.NotContains(t, rawColumn(t, "link", "properties", "link-2"), "link-sk2")
+
+	// rotate master key
+	initSecretProvider(t, "key-2")
+	count, err = ReEncryptSecrets(DB)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, count)
+	count, err = ReEncryptSecrets(DB)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+	clusterInfo, err = Cluster.GetClusterById("cluster-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "kubeconfig", clusterInfo.Credential)
+}
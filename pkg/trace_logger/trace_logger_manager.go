@@ -32,6 +32,9 @@ import (
 	"github.com/orcaman/concurrent-map"
 	"github.com/sirupsen/logrus"
 	"github.com/viney-shih/go-lock"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/PaddlePaddle/PaddleFlow/pkg/common/tracing"
 )
 
 // define errors
@@ -163,6 +166,9 @@ func (d *defaultTraceLogger) saveOneLog(level logrus.Level, format string, args
 	}
 
 	d.trace.Logs = append(d.trace.Logs, log)
+	// bridge log into event of the span bound to key, if any
+	tracing.AddKeyEvent(log.Key, "trace log", log.Time,
+		attribute.String("log.severity", level.String()), attribute.String("log.message", log.Msg))
 
 	// call update trace after every log
 	_ = d.UpdateTrace()